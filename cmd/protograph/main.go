@@ -3,23 +3,41 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hanpama/protograph/internal/audit"
+	"github.com/hanpama/protograph/internal/cache"
+	"github.com/hanpama/protograph/internal/conformance"
+	"github.com/hanpama/protograph/internal/debugstats"
 	"github.com/hanpama/protograph/internal/eventbus"
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/gateway"
+	"github.com/hanpama/protograph/internal/golden"
+	"github.com/hanpama/protograph/internal/gqlgrpc"
 	"github.com/hanpama/protograph/internal/grpcrt"
 	"github.com/hanpama/protograph/internal/grpctp"
 	"github.com/hanpama/protograph/internal/introspection"
 	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/msgpack"
 	"github.com/hanpama/protograph/internal/otel"
 	"github.com/hanpama/protograph/internal/protoreg"
+	"github.com/hanpama/protograph/internal/publish"
 	"github.com/hanpama/protograph/internal/schema"
 	"github.com/hanpama/protograph/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
 )
 
 const rootUsage = `protograph — GraphQL ↔ gRPC bridge & tools
@@ -31,40 +49,246 @@ COMMANDS:
   serve            Run the HTTP GraphQL gateway backed by gRPC services
   compile-sdl      Merge & validate GraphQL SDL into a single schema
   compile-proto    Generate .proto files from the GraphQL project
+  publish          Push the compiled SDL with a version/tag to a schema registry
+  introspect       Write the introspection JSON of the compiled schema
+  verify-golden    Compare the compiled SDL and .proto files with committed golden files
+  conformance      Run the GraphQL spec conformance suite against a running gateway
   help             Show help for any command
 `
 
 const serveUsage = `serve FLAGS:
   -graphql.root <dir>                 GraphQL schema root (default: .)
   -graphql.rootpkg <name>             GraphQL root package (required)
+  -graphql.ext <ext>                  SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>             Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>             Skip the SDL files and directories matching the pattern,
+                                      e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>                Load exactly the listed SDL files, relative to the root. Repeatable
+  -graphql.cache-dir <dir>            Keep the compiled IR and descriptors in dir and reuse them while
+                                      no SDL file changes, for faster restarts
+  -graphql.archive <file|url>         Load the SDL files from a tar archive, gzip-compressed or not,
+                                      or fetch it from an http(s) URL such as a schema registry
+                                      snapshot, instead of -graphql.root
+  -graphql.archive-header "Name: value"
+                                      Request header of the -graphql.archive URL. Repeatable;
+                                      $VARS in values are expanded from the environment
   -graphql.introspection <bool>       Enable GraphQL introspection (default: true)
+  -graphql.introspection-max-depth N  Fail introspection fields nesting more than N types along
+                                      one path, e.g. through fields and ofType (default: 0, unlimited)
+  -graphql.introspection-disable <f>  Disable an introspection field, e.g. __Type.fields or
+                                      __schema; __typename stays enabled. Repeatable
   -server.addr <addr>                 HTTP listen address (default: :8080)
+  -server.grpc-addr <addr>            Also serve the protograph.v1.GraphQL/Execute gRPC method
+                                      on this address, for internal gRPC clients
   -server.pretty                      Pretty-print JSON responses
-  -server.timeout <duration>          Per-request timeout, e.g. 10s (default: 10s)
+  -server.json-number                 Keep every digit of numbers in variables, such as 64-bit IDs,
+                                      instead of decoding them as float64
+  -server.msgpack                     Answer clients accepting application/msgpack in MessagePack
+  -server.timeout <duration>          Per-operation timeout, e.g. 10s (default: 10s)
+  -server.explain                     Return the execution plan instead of data for requests
+                                      sending X-Protograph-Explain: 1 or extensions.explain
   -server.metadata-header <name>      Forward HTTP header to gRPC metadata. Repeatable
+  -server.response-header <key>       Copy backend response metadata to an HTTP response header. Repeatable
+  -server.batch-timeout <duration>    Return partial data when one depth of async fields takes
+                                      longer, e.g. 200ms (default: 0, disabled)
+  -server.max-result-nodes N          Fail operations completing more than N values (default: 0, unlimited)
+  -server.max-list-items N            Fail operations materializing more than N list items
+  -server.max-response-bytes N        Fail operations whose response would exceed N bytes
+  -server.max-fragment-spreads N      Reject operations spreading more than N fragments once
+                                      every fragment is inlined
+  -server.max-aliases N               Reject operations aliasing more than N fields once every
+                                      fragment is inlined
+  -server.max-root-fields N           Reject operations selecting more than N top-level fields
+  -server.query-cache N               Parsed operations kept in an LRU cache; 0 disables
+                                      (default: 1000)
+  -server.max-in-flight N             Execute at most N requests concurrently, answering the
+                                      rest with 503 and Retry-After (default: 0, unlimited)
+  -server.max-queue N                 Requests waiting for a -server.max-in-flight slot before
+                                      new ones are shed (default: 0)
+  -server.queue-timeout <duration>    Shed queued requests waiting longer (default: 1s)
+  -server.operations <file>           JSON manifest pinning the timeout, response cache TTL,
+                                      limits and allowed roles of known operations
+  -server.tenant-header <name>        HTTP header naming the tenant whose
+                                      -transport.tenant-backend endpoints serve the request
+  -server.role-header <name>          HTTP header listing the caller's roles, comma-separated,
+                                      checked against the roles of -server.operations entries
+  -server.redact-variable <pattern>   Redact variables and input fields named like the pattern,
+                                      e.g. *password*, in GraphQL events. Repeatable
+  -graphiql.subscription-url <url>    ws:// or wss:// URL GraphiQL uses for subscriptions
+  -graphiql.header "Name: value"      Prefill a GraphiQL request header. Repeatable
+  -graphiql.dark                      Force the dark GraphiQL theme
   -transport.backend <Svc=host:port>  Map gRPC service to endpoint. Repeatable; at least
                                       one mapping required. Use wildcard to set default:
                                         -transport.backend *=host:port
                                       Specific mappings override the wildcard.
+                                      Split a service between versions by percentage with
+                                        -transport.backend Svc=v1:90%,v2:10%
+                                      where v1 and v2 are -transport.group names or host:port
+  -transport.group <name=host:port,...>
+                                      Name a group of endpoints for weighted splits. Repeatable
+  -transport.tenant-backend <tenant/Svc=host:port>
+                                      Map a service to an endpoint for one tenant, taking
+                                      precedence over -transport.backend. Repeatable
   -transport.max-conns-per-endpoint N Max TCP conns per endpoint (default: 2)
+  -transport.max-streams-per-conn N   Share each conn among up to N concurrent calls, calls
+                                      beyond every conn's cap waiting for a stream
+                                      (default: 0, one call per conn)
+  -transport.conns-on-demand          With -transport.max-streams-per-conn, dial conns
+                                      only as the open ones fill up
   -transport.rpc-timeout <duration>   RPC timeout, e.g. 3s (default: 3s)
+  -transport.slow-call <duration>     Log gRPC calls taking at least this long with their
+                                      method, endpoint and batch size (default: 0, disabled)
+  -transport.warm-up <duration>       Pre-dial every endpoint at startup, waiting up to this
+                                      long for connections to be ready (default: 0, disabled)
+  -transport.keepalive <duration>     Ping idle backend connections this often, e.g. 30s
+                                      (default: 0, disabled)
+  -transport.keepalive-timeout <d>    Close connections not acknowledging a ping within this
+                                      long (default: 20s)
+  -transport.idle-timeout <duration>  Close the transports of connections without calls for
+                                      this long (default: gRPC's 30m)
+  -transport.mirror <Svc=host:port>   Also send calls of a service to a shadow endpoint,
+                                      discarding its responses. Repeatable; * is a wildcard
+  -transport.mirror-percent P         Percent of calls mirrored, 0 to 100 (default: 0)
+  -transport.fault <Method=settings>  Inject chaos into calls of a method, service or *, e.g.
+                                      blog.PostService=latency:200ms,error:0.1,code:UNAVAILABLE.
+                                      For testing only. Repeatable; the first match applies
+  -transport.lb <[Svc=]policy>        Pick endpoints of services with several: random,
+                                      round_robin, least_outstanding, weighted or
+                                      consistent_hash (default: random). Svc=policy sets
+                                      the policy of one service. Repeatable
+  -transport.weight <host:port=N>     Weight of an endpoint for -transport.lb weighted
+                                      (default: 1). Repeatable
   -otel.endpoint <addr>               OTLP collector endpoint
   -otel.service <name>                OpenTelemetry service name (default: protograph)
+  -audit.file <file>                  Append a JSON line to file for every executed mutation
+  -audit.grpc <host:port>             Send a google.protobuf.Struct per executed mutation to
+                                      -audit.grpc-method (default: /audit.v1.AuditLog/Write)
+  -debug.stats                        Serve runtime statistics as JSON at /debug/protograph:
+                                      schema hash, backend health and pools, cache hit rates,
+                                      operations in flight and recent slow operations
+  -debug.slow-operation <d>           Operations listed as slow by -debug.stats and the admin
+                                      listener (default: 1s)
+  -admin.addr <addr>                  Listen on addr, e.g. localhost:6060, for /debug/pprof/
+                                      (profiles and execution traces), /debug/vars (expvar)
+                                      and /debug/protograph. Keep it away from clients
+  -runtime.record <file>              Record every runtime call and result to file (JSON lines)
+  -runtime.leaf-objects               Serialize objects selecting only scalar fields straight
+                                      from the gRPC message to JSON
+  -runtime.completion-workers N       Complete large async batches on N goroutines
+                                      (default: 0, sequential)
+  -runtime.coalesce-window <d>        Merge batch loader calls of concurrent requests arriving
+                                      within this window into one call, e.g. 2ms (default: 0)
+  -runtime.coalesce-max-batch N       Send a merged batch once it holds N entries
+                                      (default: 0, unlimited)
+  -runtime.replay <file>              Answer from a recording instead of calling backends;
+                                      -transport.* flags are ignored
+  -runtime.stubs                      Serve placeholder values, with a warning extension, for
+                                      fields of services without a -transport.backend mapping
+                                      instead of calling them. For development only
+  -runtime.strict <bool>              Panic on registry misconfigurations; false fails the
+                                      affected field with INTERNAL_SERVER_ERROR (default: true)
+  -runtime.proto-defaults             Serve unset proto3 scalar and enum fields as 0, "", false
+                                      or the first enum value instead of null
+  -runtime.method-quota <Method=N>    Cap the calls of a backend method in flight, queuing
+                                      the others, e.g. search.SearchService/Search=4; with
+                                      ",entries" batch calls weigh their entries. Repeatable
+  -runtime.field-cache N              Results of @cache fields kept in an in-memory LRU;
+                                      0 disables (default: 10000)
+  -cache.redis <host:port>            Keep @cache field results in Redis, shared by every
+                                      gateway pointing at it, instead of in memory
+  -cache.redis-username <name>        Redis ACL username
+  -cache.redis-password <password>    Redis password
+  -cache.redis-db N                   Redis database number (default: 0)
+  -cache.redis-prefix <prefix>        Prefix of every Redis key, e.g. "staging:"
+  -cache.redis-timeout <duration>     Redis dial and command timeout; slower commands count
+                                      as misses (default: 100ms)
 `
 
 const compileSDLUsage = `compile-sdl FLAGS:
   -graphql.root <dir>      GraphQL project root (default: .)
   -graphql.rootpkg <name>  GraphQL root package (required)
+  -graphql.ext <ext>       SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>  Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>  Skip the SDL files and directories matching the pattern,
+                           e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>     Load exactly the listed SDL files, relative to the root. Repeatable
   -out  <file>             Write compiled SDL to file (default: stdout)
+  -sdl.order name|source   Order types and directives by name or by source position (default: name)
+  -sdl.descriptions        Include descriptions (default: true)
+  -sdl.inline-descriptions Render single-line descriptions as quoted strings
+  -sdl.async               Annotate RPC-resolved fields with @async
+  -sdl.annotated           Keep protograph directives (@loader, @load, @resolve, @id, ...) so the output loads back as a project
   (Validation always runs; exits non-zero on errors)
 `
 
 const compileProtoUsage = `compile-proto FLAGS:
   -graphql.root <dir>      GraphQL project root (default: .)
   -graphql.rootpkg <name>  GraphQL root package (required)
+  -graphql.ext <ext>       SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>  Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>  Skip the SDL files and directories matching the pattern,
+                           e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>     Load exactly the listed SDL files, relative to the root. Repeatable
   -out  <dir>              Output directory for generated .proto files (required)
 `
 
+const publishUsage = `publish FLAGS:
+  -graphql.root <dir>            GraphQL project root (default: .)
+  -graphql.rootpkg <name>        GraphQL root package (required)
+  -graphql.ext <ext>             SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>        Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>        Skip the SDL files and directories matching the pattern,
+                                 e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>           Load exactly the listed SDL files, relative to the root. Repeatable
+  -registry.url <url>            Registry endpoint (required)
+  -registry.format <format>      Request format: json, hive or apollo (default: json)
+  -registry.method <method>      HTTP method for the json format (default: POST)
+  -registry.header "Name: value" Request header, e.g. authentication. Repeatable;
+                                 $VARS in values are expanded from the environment
+  -registry.timeout <duration>   Request timeout (default: 30s)
+  -schema.version <version>      Schema version, e.g. a commit SHA
+  -schema.tag <tag>              Schema tag, e.g. production (Apollo variant)
+  -schema.service <name>         Service name (Hive) or graph ref graph@variant (Apollo)
+  -dry-run                       Print the request body instead of sending it
+`
+
+const introspectUsage = `introspect FLAGS:
+  -graphql.root <dir>      GraphQL project root (default: .)
+  -graphql.rootpkg <name>  GraphQL root package (required)
+  -graphql.ext <ext>       SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>  Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>  Skip the SDL files and directories matching the pattern,
+                           e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>     Load exactly the listed SDL files, relative to the root. Repeatable
+  -out  <file>             Write introspection JSON to file (default: stdout)
+  -pretty                  Indent the JSON output (default: true)
+  (Output has the {"data": {"__schema": ...}} shape of an introspection query response)
+`
+
+const verifyGoldenUsage = `verify-golden FLAGS:
+  -graphql.root <dir>      GraphQL project root (default: .)
+  -graphql.rootpkg <name>  GraphQL root package (required)
+  -graphql.ext <ext>       SDL file extension, e.g. .graphqls (default: .graphql). Repeatable
+  -graphql.include <glob>  Only load the SDL files matching the pattern. Repeatable
+  -graphql.exclude <glob>  Skip the SDL files and directories matching the pattern,
+                           e.g. testdata or legacy/**/*.graphql. Repeatable
+  -graphql.file <path>     Load exactly the listed SDL files, relative to the root. Repeatable
+  -golden <dir>            Golden directory holding schema.graphql and proto/ (required)
+  -update                  Write the compiled files to the golden directory instead
+  (Prints a unified diff per differing file and exits non-zero when any differs)
+`
+
+const conformanceUsage = `conformance FLAGS:
+  -endpoint <url>          GraphQL endpoint, e.g. http://localhost:8080/graphql (required)
+  -header "Name: value"    Request header, e.g. authentication. Repeatable;
+                           $VARS in values are expanded from the environment
+  -run <regexp>            Only run scenarios whose category/name matches
+  -timeout <duration>      Timeout for the whole suite (default: 30s)
+  -list                    Print the scenario names and exit
+  (Exits non-zero when any scenario fails; introspection scenarios are skipped
+   when the endpoint disables introspection)
+`
+
 func main() {
 	if err := run(os.Args[1:]); err != nil {
 		log.Fatal(err)
@@ -94,6 +318,14 @@ func run(args []string) error {
 		return cmdCompileSDL(cmdArgs)
 	case "compile-proto":
 		return cmdCompileProto(cmdArgs)
+	case "publish":
+		return cmdPublish(cmdArgs)
+	case "introspect":
+		return cmdIntrospect(cmdArgs)
+	case "verify-golden":
+		return cmdVerifyGolden(cmdArgs)
+	case "conformance":
+		return cmdConformance(cmdArgs)
 	case "help":
 		return cmdHelp(cmdArgs)
 	default:
@@ -114,6 +346,14 @@ func cmdHelp(args []string) error {
 		fmt.Print(compileSDLUsage)
 	case "compile-proto":
 		fmt.Print(compileProtoUsage)
+	case "publish":
+		fmt.Print(publishUsage)
+	case "introspect":
+		fmt.Print(introspectUsage)
+	case "verify-golden":
+		fmt.Print(verifyGoldenUsage)
+	case "conformance":
+		fmt.Print(conformanceUsage)
 	default:
 		return fmt.Errorf("unknown help topic %q", args[0])
 	}
@@ -143,6 +383,31 @@ func (b *backendFlag) Set(v string) error {
 	return nil
 }
 
+// tenantBackendFlag collects -transport.tenant-backend mappings by tenant.
+type tenantBackendFlag struct {
+	m map[string]map[string][]string
+}
+
+func (b *tenantBackendFlag) String() string { return "" }
+
+func (b *tenantBackendFlag) Set(v string) error {
+	tenant, mapping, ok := strings.Cut(v, "/")
+	tenant = strings.TrimSpace(tenant)
+	if !ok || tenant == "" {
+		return fmt.Errorf("invalid tenant backend %q (want tenant/Svc=host:port)", v)
+	}
+	if b.m == nil {
+		b.m = map[string]map[string][]string{}
+	}
+	var bf backendFlag
+	bf.m = b.m[tenant]
+	if err := bf.Set(mapping); err != nil {
+		return err
+	}
+	b.m[tenant] = bf.m
+	return nil
+}
+
 type stringListFlag []string
 
 func (s *stringListFlag) String() string { return "" }
@@ -152,36 +417,197 @@ func (s *stringListFlag) Set(v string) error {
 	return nil
 }
 
+// archiveDiscovery lists the SDL files of the archive at a path or an http(s)
+// URL fetched with headers.
+func archiveDiscovery(archive string, headers []string, rootPkg string, opts ir.LoadOptions) (ir.Discovery, error) {
+	if !strings.HasPrefix(archive, "http://") && !strings.HasPrefix(archive, "https://") {
+		f, err := os.Open(archive)
+		if err != nil {
+			return nil, fmt.Errorf("open archive: %w", err)
+		}
+		defer f.Close()
+		disc, err := ir.NewArchiveDiscovery(f, rootPkg, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", archive, err)
+		}
+		return disc, nil
+	}
+	header := http.Header{}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid -graphql.archive-header %q (want \"Name: value\")", h)
+		}
+		header.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return ir.NewURLDiscovery(ctx, archive, header, rootPkg, opts)
+}
+
+// projectFileFlags registers the flags selecting the SDL files of the project.
+func projectFileFlags(fs *flag.FlagSet) *ir.LoadOptions {
+	opts := new(ir.LoadOptions)
+	fs.Var((*stringListFlag)(&opts.Extensions), "graphql.ext", "SDL file extension")
+	fs.Var((*stringListFlag)(&opts.Include), "graphql.include", "Only load SDL files matching the pattern")
+	fs.Var((*stringListFlag)(&opts.Exclude), "graphql.exclude", "Skip SDL files and directories matching the pattern")
+	fs.Var((*stringListFlag)(&opts.Files), "graphql.file", "Load exactly the listed SDL files")
+	return opts
+}
+
+// auditQueue is the number of audit records pending before mutations wait for
+// the sinks.
+const auditQueue = 1024
+
+// slowOperations is the number of recent slow operations -debug.stats lists.
+const slowOperations = 50
+
 func cmdServe(args []string) error {
 	// Defaults mirror the old config defaults for consistency
 	rootDir := "."
 	rootPkg := ""
+	buildCacheDir := ""
+	archive := ""
+	var archiveHeaders stringListFlag
 	addr := ":8080"
+	grpcAddr := ""
 	pretty := false
+	jsonNumber := false
+	msgpackResponses := false
+	explain := false
+	leafObjects := false
+	queryCache := 1000
+	var shedding server.LoadSheddingOptions
+	completionWorkers := 0
+	var coalesceWindow time.Duration
+	coalesceMaxBatch := 0
+	fieldCache := 10000
+	var redis cache.RedisOptions
+	var limits executor.Limits
+	operationsFile := ""
+	roleHeader := ""
+	tenantHeader := ""
+	var batchTimeout time.Duration
 	timeout := 10 * time.Second
 	maxConns := 2
+	maxStreams := 0
+	connsOnDemand := false
 	rpcTimeout := 3 * time.Second
+	var slowCall time.Duration
+	var warmUp, keepalive, idleTimeout time.Duration
+	keepaliveTimeout := 20 * time.Second
+	var mirrorPercent float64
+	var faultSpecs stringListFlag
+	var quotaSpecs stringListFlag
+	var lbPolicies stringListFlag
+	var lbWeights stringListFlag
 	enableIntrospection := true
 	otelEndpoint := ""
 	otelService := "protograph"
+	auditFile := ""
+	auditGRPC := ""
+	auditMethod := "/audit.v1.AuditLog/Write"
+	debugStats := false
+	slowOperation := time.Second
+	adminAddr := ""
+	recordFile := ""
+	replayFile := ""
+	stubs := false
+	strict := true
+	protoDefaults := false
 	backends := map[string][]string{}
 	var metadataHeaders stringListFlag
+	var responseHeaders stringListFlag
+	var graphiqlHeaders stringListFlag
+	var introspectionDisabled stringListFlag
+	var redactedVariables stringListFlag
+	introspectionMaxDepth := 0
+	var graphiql server.GraphiQLOptions
 
 	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
 	fs.SetOutput(new(bytes.Buffer))
 	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL schema root")
 	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
+	fs.StringVar(&buildCacheDir, "graphql.cache-dir", buildCacheDir, "Build cache directory")
+	fs.StringVar(&archive, "graphql.archive", archive, "Tar archive file or URL of the SDL files")
+	fs.Var(&archiveHeaders, "graphql.archive-header", "Request header of the archive URL")
 	fs.BoolVar(&enableIntrospection, "graphql.introspection", enableIntrospection, "Enable GraphQL introspection")
+	fs.IntVar(&introspectionMaxDepth, "graphql.introspection-max-depth", introspectionMaxDepth, "Max types nested along one introspection path")
+	fs.Var(&introspectionDisabled, "graphql.introspection-disable", "Disable an introspection field")
 	fs.StringVar(&addr, "server.addr", addr, "HTTP listen address")
+	fs.StringVar(&grpcAddr, "server.grpc-addr", grpcAddr, "gRPC listen address of the Execute method")
 	fs.BoolVar(&pretty, "server.pretty", pretty, "Pretty-print JSON responses")
-	fs.DurationVar(&timeout, "server.timeout", timeout, "Per-request timeout")
+	fs.BoolVar(&jsonNumber, "server.json-number", jsonNumber, "Decode numbers in variables without losing precision")
+	fs.BoolVar(&msgpackResponses, "server.msgpack", msgpackResponses, "Encode responses in MessagePack when accepted")
+	fs.DurationVar(&timeout, "server.timeout", timeout, "Per-operation timeout")
+	fs.BoolVar(&explain, "server.explain", explain, "Allow clients to request the execution plan")
+	fs.DurationVar(&batchTimeout, "server.batch-timeout", batchTimeout, "Per-depth async batch timeout")
+	fs.Int64Var(&limits.MaxResultNodes, "server.max-result-nodes", 0, "Max completed values per operation")
+	fs.Int64Var(&limits.MaxListItems, "server.max-list-items", 0, "Max list items per operation")
+	fs.Int64Var(&limits.MaxResponseBytes, "server.max-response-bytes", 0, "Max estimated response bytes per operation")
+	fs.Int64Var(&limits.MaxFragmentSpreads, "server.max-fragment-spreads", 0, "Max fragment spreads per operation once inlined")
+	fs.Int64Var(&limits.MaxAliases, "server.max-aliases", 0, "Max aliased fields per operation once inlined")
+	fs.Int64Var(&limits.MaxRootFields, "server.max-root-fields", 0, "Max top-level fields per operation")
+	fs.IntVar(&queryCache, "server.query-cache", queryCache, "Parsed operations kept in an LRU cache")
+	fs.IntVar(&shedding.MaxInFlight, "server.max-in-flight", 0, "Max GraphQL requests executing concurrently")
+	fs.IntVar(&shedding.MaxQueue, "server.max-queue", 0, "Max requests waiting for -server.max-in-flight")
+	fs.DurationVar(&shedding.QueueTimeout, "server.queue-timeout", time.Second, "Shed requests waiting longer")
+	fs.StringVar(&operationsFile, "server.operations", operationsFile, "JSON manifest of per-operation settings")
+	fs.StringVar(&roleHeader, "server.role-header", roleHeader, "HTTP header listing the caller's roles")
+	fs.StringVar(&tenantHeader, "server.tenant-header", tenantHeader, "HTTP header naming the tenant of a request")
 	fs.Var(&metadataHeaders, "server.metadata-header", "Forward HTTP header to gRPC metadata")
+	fs.Var(&responseHeaders, "server.response-header", "Copy backend response metadata to an HTTP response header")
+	fs.Var(&redactedVariables, "server.redact-variable", "Redact variables named like the pattern in events")
+	fs.StringVar(&graphiql.SubscriptionURL, "graphiql.subscription-url", "", "GraphiQL subscriptions URL")
+	fs.Var(&graphiqlHeaders, "graphiql.header", "Prefill a GraphiQL request header")
+	fs.BoolVar(&graphiql.DarkMode, "graphiql.dark", false, "Force the dark GraphiQL theme")
 	var bf backendFlag
 	fs.Var(&bf, "transport.backend", "Map gRPC service to endpoint")
+	var tbf tenantBackendFlag
+	fs.Var(&tbf, "transport.tenant-backend", "Map gRPC service to endpoint for one tenant")
 	fs.IntVar(&maxConns, "transport.max-conns-per-endpoint", maxConns, "Max conns per endpoint")
+	fs.IntVar(&maxStreams, "transport.max-streams-per-conn", maxStreams, "Max concurrent calls per conn")
+	fs.BoolVar(&connsOnDemand, "transport.conns-on-demand", connsOnDemand, "Dial conns only as the open ones fill up")
 	fs.DurationVar(&rpcTimeout, "transport.rpc-timeout", rpcTimeout, "RPC timeout")
+	fs.DurationVar(&slowCall, "transport.slow-call", slowCall, "Log gRPC calls taking at least this long")
+	fs.DurationVar(&warmUp, "transport.warm-up", warmUp, "Pre-dial every endpoint at startup, waiting up to this long")
+	fs.DurationVar(&keepalive, "transport.keepalive", keepalive, "Ping idle backend connections this often")
+	fs.DurationVar(&keepaliveTimeout, "transport.keepalive-timeout", keepaliveTimeout, "Close connections not acknowledging a ping within this long")
+	fs.DurationVar(&idleTimeout, "transport.idle-timeout", idleTimeout, "Close the transports of connections without calls for this long")
+	var gf backendFlag
+	fs.Var(&gf, "transport.group", "Name a group of endpoints for weighted splits")
+	var mf backendFlag
+	fs.Var(&mf, "transport.mirror", "Mirror calls of a gRPC service to a shadow endpoint")
+	fs.Float64Var(&mirrorPercent, "transport.mirror-percent", mirrorPercent, "Percent of calls mirrored")
+	fs.Var(&faultSpecs, "transport.fault", "Inject latency and errors into backend calls, for chaos testing")
+	fs.Var(&lbPolicies, "transport.lb", "Endpoint balancing policy, of all services or of one as Svc=policy")
+	fs.Var(&lbWeights, "transport.weight", "Weight of an endpoint for -transport.lb weighted")
 	fs.StringVar(&otelEndpoint, "otel.endpoint", otelEndpoint, "OTLP collector endpoint")
 	fs.StringVar(&otelService, "otel.service", otelService, "OpenTelemetry service name")
+	fs.StringVar(&auditFile, "audit.file", auditFile, "Audit log file of executed mutations")
+	fs.StringVar(&auditGRPC, "audit.grpc", auditGRPC, "gRPC endpoint receiving audit records")
+	fs.StringVar(&auditMethod, "audit.grpc-method", auditMethod, "gRPC method receiving audit records")
+	fs.BoolVar(&debugStats, "debug.stats", debugStats, "Serve runtime statistics at /debug/protograph")
+	fs.DurationVar(&slowOperation, "debug.slow-operation", slowOperation, "Operations listed as slow by -debug.stats")
+	fs.StringVar(&adminAddr, "admin.addr", adminAddr, "Admin listen address of pprof, expvar and /debug/protograph")
+	fs.StringVar(&recordFile, "runtime.record", recordFile, "Record runtime interactions to file")
+	fs.BoolVar(&leafObjects, "runtime.leaf-objects", leafObjects, "Serialize leaf-only objects straight to JSON")
+	fs.IntVar(&completionWorkers, "runtime.completion-workers", completionWorkers, "Goroutines completing one async batch")
+	fs.DurationVar(&coalesceWindow, "runtime.coalesce-window", coalesceWindow, "Merge batch loader calls of concurrent requests within this window")
+	fs.IntVar(&coalesceMaxBatch, "runtime.coalesce-max-batch", coalesceMaxBatch, "Send a merged batch once it holds this many entries")
+	fs.StringVar(&replayFile, "runtime.replay", replayFile, "Serve recorded runtime interactions instead of backends")
+	fs.BoolVar(&stubs, "runtime.stubs", stubs, "Serve placeholders for fields of unmapped services")
+	fs.BoolVar(&strict, "runtime.strict", strict, "Panic on registry misconfigurations")
+	fs.BoolVar(&protoDefaults, "runtime.proto-defaults", protoDefaults, "Serve unset proto3 scalars as their default instead of null")
+	fs.Var(&quotaSpecs, "runtime.method-quota", "Cap the calls of a backend method in flight")
+	fs.IntVar(&fieldCache, "runtime.field-cache", fieldCache, "Results of @cache fields kept in an LRU cache")
+	fs.StringVar(&redis.Addr, "cache.redis", "", "Redis address shared by the gateway caches")
+	fs.StringVar(&redis.Username, "cache.redis-username", "", "Redis ACL username")
+	fs.StringVar(&redis.Password, "cache.redis-password", "", "Redis password")
+	fs.IntVar(&redis.DB, "cache.redis-db", 0, "Redis database number")
+	fs.StringVar(&redis.KeyPrefix, "cache.redis-prefix", "", "Prefix of the Redis keys")
+	fs.DurationVar(&redis.Timeout, "cache.redis-timeout", 100*time.Millisecond, "Redis dial and command timeout")
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprint(os.Stderr, serveUsage)
 		return err
@@ -193,83 +619,259 @@ func cmdServe(args []string) error {
 	for svc, eps := range bf.m {
 		backends[svc] = eps
 	}
-
-	proj, err := ir.Load(rootDir, rootPkg)
-	if err != nil {
-		return fmt.Errorf("load project: %w", err)
+	weights := map[string]int{}
+	for _, w := range lbWeights {
+		ep, n, ok := strings.Cut(w, "=")
+		weight, err := strconv.Atoi(n)
+		if !ok || ep == "" || err != nil || weight <= 0 {
+			return fmt.Errorf("invalid -transport.weight %q (want host:port=N)", w)
+		}
+		weights[ep] = weight
 	}
-	reg, err := protoreg.Build(proj)
+	balancer, err := grpctp.NewBalancer(grpctp.PolicyRandom, weights)
 	if err != nil {
-		return fmt.Errorf("protoreg build: %w", err)
+		return err
 	}
-
-	wildcard := backends["*"]
-	providers := map[string][]string{}
-	for _, fd := range reg.GetAllServiceFiles() {
-		for i := range fd.Services().Len() {
-			svc := fd.Services().Get(i)
-			fn := string(svc.FullName())
-
-			eps := backends[fn]
-			if len(eps) == 0 {
-				eps = wildcard
-			}
-			if len(eps) == 0 {
-				return fmt.Errorf("no backend mapping for %s", svc)
-			}
-			providers[fn] = eps
+	serviceBalancers := map[string]grpctp.Balancer{}
+	for _, spec := range lbPolicies {
+		svc, policy, perService := strings.Cut(spec, "=")
+		if !perService {
+			policy = spec
+		} else if svc == "" {
+			return fmt.Errorf("invalid -transport.lb %q (want policy or Svc=policy)", spec)
+		}
+		b, err := grpctp.NewBalancer(policy, weights)
+		if err != nil {
+			return err
+		}
+		if perService {
+			serviceBalancers[svc] = b
+		} else {
+			balancer = b
 		}
 	}
-	if len(providers) == 0 {
-		return fmt.Errorf("no backend mappings provided")
+	for _, h := range graphiqlHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid -graphiql.header %q (want \"Name: value\")", h)
+		}
+		if graphiql.Headers == nil {
+			graphiql.Headers = map[string]string{}
+		}
+		graphiql.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
 	}
-	provider := grpctp.NewStaticEndpoints(providers)
 
-	eventbus.Use(eventbus.New())
-	shutdown, err := otel.Setup(otelEndpoint, otelService)
-	if err != nil {
-		return fmt.Errorf("otel setup: %w", err)
+	cfg := gateway.Config{
+		Root:             rootDir,
+		RootPackage:      rootPkg,
+		Load:             *loadOpts,
+		CacheDir:         buildCacheDir,
+		Backends:         backends,
+		Stubs:            stubs,
+		Lenient:          !strict,
+		ProtoDefaults:    protoDefaults,
+		FieldCacheSize:   fieldCache,
+		Redis:            redis,
+		CoalesceWindow:   coalesceWindow,
+		CoalesceMaxBatch: coalesceMaxBatch,
+		Introspection:    enableIntrospection,
+	}
+	if archive != "" {
+		if cfg.Discovery, err = archiveDiscovery(archive, archiveHeaders, rootPkg, *loadOpts); err != nil {
+			return err
+		}
+	}
+	trOpts := []grpctp.Option{grpctp.WithMaxConnsPerEndpoint(maxConns), grpctp.WithBalancer(balancer)}
+	for svc, b := range serviceBalancers {
+		trOpts = append(trOpts, grpctp.WithServiceBalancer(svc, b))
+	}
+	if maxStreams > 0 {
+		trOpts = append(trOpts, grpctp.WithMaxStreamsPerConn(maxStreams))
+	}
+	if connsOnDemand {
+		trOpts = append(trOpts, grpctp.WithConnsOnDemand())
 	}
-	defer func() { _ = shutdown(context.Background()) }()
-	trOpts := []grpctp.Option{grpctp.WithProvider(provider), grpctp.WithMaxConnsPerEndpoint(maxConns)}
 	if rpcTimeout > 0 {
 		trOpts = append(trOpts, grpctp.WithRPCTimeout(rpcTimeout))
 	}
-	transport := grpctp.New(trOpts...)
-	runtime := grpcrt.NewRuntime(reg, transport)
-
-	sch, err := schema.BuildFromIR(proj)
-	if err != nil {
-		return fmt.Errorf("build schema: %w", err)
+	if slowCall > 0 {
+		trOpts = append(trOpts, grpctp.WithSlowCallLog(slowCall, nil))
 	}
-
-	// Only wrap with introspection if enabled
-	if enableIntrospection {
-		var wrapper *introspection.IntrospectionWrapper = introspection.Wrap(runtime, sch)
-		runtime = wrapper.Runtime
-		sch = wrapper.Schema
+	if keepalive > 0 {
+		trOpts = append(trOpts, grpctp.WithKeepalive(keepalive, keepaliveTimeout))
+	}
+	if idleTimeout > 0 {
+		trOpts = append(trOpts, grpctp.WithIdleTimeout(idleTimeout))
+	}
+	cfg.Transport = gateway.TransportConfig{Options: trOpts, WarmUp: warmUp, Mirror: mf.m, MirrorPercent: mirrorPercent, Tenants: tbf.m, Groups: map[string][]string{}}
+	for name, values := range gf.m {
+		for _, v := range values {
+			for _, ep := range strings.Split(v, ",") {
+				if ep = strings.TrimSpace(ep); ep != "" {
+					cfg.Transport.Groups[name] = append(cfg.Transport.Groups[name], ep)
+				}
+			}
+		}
+	}
+	for _, spec := range faultSpecs {
+		f, err := grpctp.ParseFault(spec)
+		if err != nil {
+			return err
+		}
+		cfg.Transport.Faults = append(cfg.Transport.Faults, f)
+	}
+	for _, spec := range quotaSpecs {
+		q, err := grpcrt.ParseMethodQuota(spec)
+		if err != nil {
+			return err
+		}
+		cfg.MethodQuotas = append(cfg.MethodQuotas, q)
+	}
+	if replayFile != "" {
+		f, err := os.Open(replayFile)
+		if err != nil {
+			return fmt.Errorf("open replay: %w", err)
+		}
+		defer f.Close()
+		cfg.Replay = f
+	} else if recordFile != "" {
+		f, err := os.Create(recordFile)
+		if err != nil {
+			return fmt.Errorf("create recording: %w", err)
+		}
+		defer f.Close()
+		cfg.Record = f
+	}
+	if introspectionMaxDepth > 0 {
+		cfg.IntrospectionOptions = append(cfg.IntrospectionOptions, introspection.WithMaxDepth(introspectionMaxDepth))
+	}
+	if len(introspectionDisabled) > 0 {
+		cfg.IntrospectionOptions = append(cfg.IntrospectionOptions, introspection.WithDisabledFields(introspectionDisabled...))
+	}
+	if operationsFile != "" {
+		f, err := os.Open(operationsFile)
+		if err != nil {
+			return fmt.Errorf("open operations: %w", err)
+		}
+		cfg.Operations, err = server.LoadOperationManifest(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", operationsFile, err)
+		}
 	}
 
 	var sopts []server.Option
 	if pretty {
 		sopts = append(sopts, server.WithPretty())
 	}
+	if jsonNumber {
+		sopts = append(sopts, server.WithJSONNumber(true))
+	}
+	if msgpackResponses {
+		sopts = append(sopts, server.WithResponseEncoder(msgpack.ContentType, msgpack.Encode))
+	}
 	if timeout > 0 {
 		sopts = append(sopts, server.WithTimeout(timeout))
 	}
+	if explain {
+		sopts = append(sopts, server.WithExplain(true))
+	}
+	if shedding.MaxInFlight > 0 {
+		sopts = append(sopts, server.WithLoadShedding(shedding))
+	}
+	if queryCache > 0 {
+		sopts = append(sopts, server.WithQueryCache(queryCache))
+	}
+	if batchTimeout > 0 {
+		sopts = append(sopts, server.WithBatchTimeout(batchTimeout))
+	}
+	if limits != (executor.Limits{}) {
+		sopts = append(sopts, server.WithLimits(limits))
+	}
+	if completionWorkers > 1 {
+		sopts = append(sopts, server.WithCompletionWorkers(completionWorkers))
+	}
+	if leafObjects {
+		sopts = append(sopts, server.WithLeafObjects(true))
+	}
 	if len(metadataHeaders) > 0 {
 		sopts = append(sopts, server.WithMetadataHeaders(metadataHeaders...))
 	}
-	h, err := server.New(runtime, sch, sopts...)
+	if len(responseHeaders) > 0 {
+		sopts = append(sopts, server.WithResponseHeaders(responseHeaders...))
+	}
+	if roleHeader != "" {
+		sopts = append(sopts, server.WithRoleHeader(roleHeader))
+	}
+	if tenantHeader != "" {
+		sopts = append(sopts, server.WithTenantHeader(tenantHeader))
+	}
+	if len(redactedVariables) > 0 {
+		sopts = append(sopts, server.WithRedactedVariables(redactedVariables...))
+	}
+	cfg.ServerOptions = append(sopts, server.WithGraphiQLConfig(graphiql))
+
+	gw, err := gateway.New(cfg)
 	if err != nil {
-		return fmt.Errorf("server init: %w", err)
+		return err
 	}
+	defer gw.Close()
+	h := gw.Handler
 
+	eventbus.Use(eventbus.New())
+	shutdown, err := otel.Setup(otelEndpoint, otelService)
+	if err != nil {
+		return fmt.Errorf("otel setup: %w", err)
+	}
+	defer func() { _ = shutdown(context.Background()) }()
+	if auditFile != "" {
+		f, err := os.OpenFile(auditFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("open audit log: %w", err)
+		}
+		defer f.Close()
+		audit.Register(audit.NewFileSink(f), auditQueue)
+	}
+	if auditGRPC != "" {
+		conn, err := grpc.NewClient(auditGRPC, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		defer conn.Close()
+		audit.Register(audit.NewGRPCSink(conn, auditMethod, 5*time.Second), auditQueue)
+	}
+
+	var slow *debugstats.SlowLog
+	if debugStats || adminAddr != "" {
+		slow = debugstats.NewSlowLog(slowOperation, slowOperations)
+		slow.Register()
+	}
 	mux := http.NewServeMux()
 	mux.Handle("/graphql", h)
+	if debugStats {
+		mux.Handle(debugstats.Path, debugstats.Handler(gw, slow))
+	}
 
+	errc := make(chan error, 3)
+	if adminAddr != "" {
+		admin := debugstats.NewAdminMux(gw, slow)
+		log.Printf("Admin server listening on %s", adminAddr)
+		go func() { errc <- http.ListenAndServe(adminAddr, admin) }()
+	}
+	if grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		gs := grpc.NewServer()
+		gqlgrpc.Register(gs, h)
+		reflection.Register(gs)
+		log.Printf("GraphQL gRPC service listening on %s", grpcAddr)
+		go func() { errc <- gs.Serve(lis) }()
+	}
 	log.Printf("GraphQL server listening on %s", addr)
-	return http.ListenAndServe(addr, mux)
+	go func() { errc <- http.ListenAndServe(addr, mux) }()
+	return <-errc
 }
 
 func cmdCompileSDL(args []string) error {
@@ -280,7 +882,13 @@ func cmdCompileSDL(args []string) error {
 	fs.SetOutput(new(bytes.Buffer))
 	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL project root")
 	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
 	fs.StringVar(&outFile, "out", outFile, "Write compiled SDL to file")
+	order := fs.String("sdl.order", "name", "Type and directive order: name or source")
+	descriptions := fs.Bool("sdl.descriptions", true, "Include descriptions")
+	inlineDescriptions := fs.Bool("sdl.inline-descriptions", false, "Render single-line descriptions as quoted strings")
+	annotateAsync := fs.Bool("sdl.async", false, "Annotate RPC-resolved fields with @async")
+	annotated := fs.Bool("sdl.annotated", false, "Keep protograph directives in the output")
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprint(os.Stderr, compileSDLUsage)
 		return err
@@ -289,23 +897,278 @@ func cmdCompileSDL(args []string) error {
 		fmt.Fprint(os.Stderr, compileSDLUsage)
 		return fmt.Errorf("-graphql.rootpkg is required")
 	}
+	var renderOpts []schema.RenderOption
+	switch *order {
+	case "name":
+	case "source":
+		renderOpts = append(renderOpts, schema.WithSourceOrder())
+	default:
+		fmt.Fprint(os.Stderr, compileSDLUsage)
+		return fmt.Errorf("invalid -sdl.order %q (want name or source)", *order)
+	}
+	if !*descriptions {
+		renderOpts = append(renderOpts, schema.WithoutDescriptions())
+	}
+	if *inlineDescriptions {
+		renderOpts = append(renderOpts, schema.WithInlineDescriptions())
+	}
+	if *annotateAsync {
+		renderOpts = append(renderOpts, schema.WithAsyncAnnotations())
+	}
 
-	proj, err := ir.Load(rootDir, rootPkg)
+	sdl, err := compileSDL(rootDir, rootPkg, *loadOpts, *annotated, renderOpts...)
 	if err != nil {
-		return fmt.Errorf("load project: %w", err)
+		return err
+	}
+	if outFile == "" {
+		fmt.Print(sdl)
+		return nil
+	}
+	if err := os.WriteFile(outFile, []byte(sdl), 0644); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compileSDL loads, builds and validates the project and renders its SDL.
+func compileSDL(rootDir, rootPkg string, loadOpts ir.LoadOptions, annotated bool, opts ...schema.RenderOption) (string, error) {
+	proj, sch, err := buildSchema(rootDir, rootPkg, loadOpts)
+	if err != nil {
+		return "", err
+	}
+	if annotated {
+		return schema.RenderAnnotated(proj, opts...), nil
+	}
+	return schema.Render(sch, opts...), nil
+}
+
+// buildSchema loads the project and builds and validates its schema.
+func buildSchema(rootDir, rootPkg string, loadOpts ir.LoadOptions) (*ir.Project, *schema.Schema, error) {
+	proj, err := ir.LoadWithOptions(rootDir, rootPkg, loadOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load project: %w", err)
 	}
 	sch, err := schema.BuildFromIR(proj)
 	if err != nil {
-		return fmt.Errorf("build schema: %w", err)
+		return nil, nil, fmt.Errorf("build schema: %w", err)
+	}
+	return proj, sch, nil
+}
+
+func cmdIntrospect(args []string) error {
+	rootDir := "."
+	rootPkg := ""
+	outFile := ""
+	pretty := true
+	fs := flag.NewFlagSet("introspect", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL project root")
+	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
+	fs.StringVar(&outFile, "out", outFile, "Write introspection JSON to file")
+	fs.BoolVar(&pretty, "pretty", pretty, "Indent the JSON output")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprint(os.Stderr, introspectUsage)
+		return err
 	}
-	sdl := schema.Render(sch)
+	if rootPkg == "" {
+		fmt.Fprint(os.Stderr, introspectUsage)
+		return fmt.Errorf("-graphql.rootpkg is required")
+	}
+	_, sch, err := buildSchema(rootDir, rootPkg, *loadOpts)
+	if err != nil {
+		return err
+	}
+	res, err := introspection.Introspect(context.Background(), sch)
+	if err != nil {
+		return err
+	}
+	var out []byte
+	if pretty {
+		out, err = json.MarshalIndent(res, "", "  ")
+	} else {
+		out, err = json.Marshal(res)
+	}
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
 	if outFile == "" {
-		fmt.Print(sdl)
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(outFile, out, 0644)
+}
+
+func cmdPublish(args []string) error {
+	rootDir := "."
+	rootPkg := ""
+	registryURL := ""
+	format := string(publish.FormatJSON)
+	method := http.MethodPost
+	timeout := 30 * time.Second
+	var headers stringListFlag
+	var sch publish.Schema
+	dryRun := false
+
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL project root")
+	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
+	fs.StringVar(&registryURL, "registry.url", registryURL, "Registry endpoint")
+	fs.StringVar(&format, "registry.format", format, "Request format")
+	fs.StringVar(&method, "registry.method", method, "HTTP method for the json format")
+	fs.Var(&headers, "registry.header", "Request header")
+	fs.DurationVar(&timeout, "registry.timeout", timeout, "Request timeout")
+	fs.StringVar(&sch.Version, "schema.version", "", "Schema version")
+	fs.StringVar(&sch.Tag, "schema.tag", "", "Schema tag")
+	fs.StringVar(&sch.Service, "schema.service", "", "Service name or graph ref")
+	fs.BoolVar(&dryRun, "dry-run", dryRun, "Print the request body instead of sending it")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprint(os.Stderr, publishUsage)
+		return err
+	}
+	if rootPkg == "" {
+		fmt.Fprint(os.Stderr, publishUsage)
+		return fmt.Errorf("-graphql.rootpkg is required")
+	}
+	if registryURL == "" && !dryRun {
+		fmt.Fprint(os.Stderr, publishUsage)
+		return fmt.Errorf("-registry.url is required")
+	}
+	f, err := publish.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	opts := []publish.Option{publish.WithMethod(method)}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid -registry.header %q (want \"Name: value\")", h)
+		}
+		opts = append(opts, publish.WithHeader(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value))))
+	}
+
+	sch.SDL, err = compileSDL(rootDir, rootPkg, *loadOpts, false)
+	if err != nil {
+		return err
+	}
+	client := publish.NewClient(registryURL, f, opts...)
+	if dryRun {
+		body, err := client.Body(sch)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(body))
 		return nil
 	}
-	if err := os.WriteFile(outFile, []byte(sdl), 0644); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := client.Publish(ctx, sch); err != nil {
+		return err
+	}
+	log.Printf("published schema to %s (version %q, tag %q)", registryURL, sch.Version, sch.Tag)
+	return nil
+}
+
+func cmdVerifyGolden(args []string) error {
+	rootDir := "."
+	rootPkg := ""
+	goldenDir := ""
+	update := false
+	fs := flag.NewFlagSet("verify-golden", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL project root")
+	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
+	fs.StringVar(&goldenDir, "golden", goldenDir, "Golden directory")
+	fs.BoolVar(&update, "update", update, "Write the golden files")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprint(os.Stderr, verifyGoldenUsage)
+		return err
+	}
+	if rootPkg == "" || goldenDir == "" {
+		fmt.Fprint(os.Stderr, verifyGoldenUsage)
+		return fmt.Errorf("-graphql.rootpkg and -golden are required")
+	}
+	proj, err := ir.LoadWithOptions(rootDir, rootPkg, *loadOpts)
+	if err != nil {
+		return fmt.Errorf("load project: %w", err)
+	}
+	artifacts, err := golden.Artifacts(proj)
+	if err != nil {
+		return err
+	}
+	if update {
+		return golden.Update(goldenDir, artifacts)
+	}
+	mismatches, err := golden.Compare(goldenDir, artifacts)
+	if err != nil {
 		return err
 	}
+	if len(mismatches) > 0 {
+		fmt.Print(golden.Summary(mismatches))
+		return fmt.Errorf("%d golden files differ from the compiled contract; run with -update to accept the changes", len(mismatches))
+	}
+	return nil
+}
+
+func cmdConformance(args []string) error {
+	endpoint := ""
+	var headers stringListFlag
+	pattern := ""
+	timeout := 30 * time.Second
+	list := false
+
+	fs := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	fs.SetOutput(new(bytes.Buffer))
+	fs.StringVar(&endpoint, "endpoint", endpoint, "GraphQL endpoint")
+	fs.Var(&headers, "header", "Request header")
+	fs.StringVar(&pattern, "run", pattern, "Only run matching scenarios")
+	fs.DurationVar(&timeout, "timeout", timeout, "Timeout for the whole suite")
+	fs.BoolVar(&list, "list", list, "Print the scenario names and exit")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprint(os.Stderr, conformanceUsage)
+		return err
+	}
+
+	scenarios := conformance.Scenarios()
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid -run: %w", err)
+		}
+		scenarios = slices.DeleteFunc(scenarios, func(s conformance.Scenario) bool { return !re.MatchString(s.ID()) })
+	}
+	if list {
+		for _, s := range scenarios {
+			fmt.Println(s.ID())
+		}
+		return nil
+	}
+	if endpoint == "" {
+		fmt.Fprint(os.Stderr, conformanceUsage)
+		return fmt.Errorf("-endpoint is required")
+	}
+	var opts []conformance.Option
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid -header %q (want \"Name: value\")", h)
+		}
+		opts = append(opts, conformance.WithHeader(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value))))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	report := conformance.Run(ctx, endpoint, scenarios, opts...)
+	if err := report.Write(os.Stdout); err != nil {
+		return err
+	}
+	if n := report.Failed(); n > 0 {
+		return fmt.Errorf("%d conformance scenario(s) failed", n)
+	}
 	return nil
 }
 
@@ -317,6 +1180,7 @@ func cmdCompileProto(args []string) error {
 	fs.SetOutput(new(bytes.Buffer))
 	fs.StringVar(&rootDir, "graphql.root", rootDir, "GraphQL project root")
 	fs.StringVar(&rootPkg, "graphql.rootpkg", rootPkg, "GraphQL root package")
+	loadOpts := projectFileFlags(fs)
 	fs.StringVar(&outDir, "out", outDir, "Output directory for generated .proto files")
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprint(os.Stderr, compileProtoUsage)
@@ -329,7 +1193,7 @@ func cmdCompileProto(args []string) error {
 		fmt.Fprint(os.Stderr, compileProtoUsage)
 		return fmt.Errorf("-graphql.rootpkg is required")
 	}
-	proj, err := ir.Load(rootDir, rootPkg)
+	proj, err := ir.LoadWithOptions(rootDir, rootPkg, *loadOpts)
 	if err != nil {
 		return fmt.Errorf("load project: %w", err)
 	}
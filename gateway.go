@@ -0,0 +1,179 @@
+// Package protograph embeds the protograph GraphQL gateway in Go programs.
+//
+// NewGateway builds the same stack as the serve command and returns it as an
+// http.Handler, which can be mounted on any mux and wrapped with middleware:
+//
+//	h, err := protograph.NewGateway(protograph.Config{
+//		RootPackage: "app",
+//		Root:        "./graphql",
+//		Backends:    map[string][]string{"*": {"localhost:9090"}},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	mux.Handle("/graphql", auth(h))
+//
+// Middleware passes the principal and tenant of a request to the runtime with
+// the protographctx package.
+package protograph
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/hanpama/protograph/internal/gateway"
+	"github.com/hanpama/protograph/internal/grpctp"
+	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/msgpack"
+	"github.com/hanpama/protograph/internal/server"
+)
+
+// Config configures a gateway. Numeric fields left at zero take the defaults
+// of the matching serve flags; negative values disable what they configure.
+type Config struct {
+	// Root is the directory of the GraphQL project (default: the working
+	// directory) and RootPackage the package of its root files.
+	Root        string
+	RootPackage string
+	// FS, when set, holds the SDL files of the project instead of Root, e.g. an
+	// embed.FS shipping the schema in the binary.
+	FS fs.FS
+	// SchemaURL, when set, is fetched at startup for a tar archive of the SDL
+	// files, gzip-compressed or not, such as a snapshot of a schema registry.
+	// Its request carries SchemaHeader, and SchemaTimeout bounds the fetch
+	// (default: 30s).
+	SchemaURL     string
+	SchemaHeader  http.Header
+	SchemaTimeout time.Duration
+	// CacheDir keeps the compiled project and reuses it while no SDL file
+	// changes, for faster starts.
+	CacheDir string
+
+	// Backends maps gRPC services, by full name, or "*" for every other
+	// service, to their host:port endpoints.
+	Backends map[string][]string
+	// Stubs serves placeholders for the fields of services without a backend
+	// instead of failing, for development against a partial set of backends.
+	Stubs bool
+	// MaxConnsPerEndpoint bounds the connections to each endpoint (default: 2).
+	MaxConnsPerEndpoint int
+	// RPCTimeout bounds each backend call (default: 3s).
+	RPCTimeout time.Duration
+
+	// Timeout bounds each operation whose request has no deadline (default: 10s).
+	Timeout time.Duration
+	// QueryCacheSize is the number of parsed operations kept (default: 1000).
+	QueryCacheSize int
+	// FieldCacheSize is the number of @cache field results kept (default: 10000).
+	FieldCacheSize int
+	// DisableIntrospection turns off __schema and __type.
+	DisableIntrospection bool
+	// DisableGraphiQL stops serving the IDE to browsers.
+	DisableGraphiQL bool
+
+	// MetadataHeaders lists HTTP headers forwarded to the backends as gRPC
+	// metadata, and ResponseHeaders the backend response metadata keys copied
+	// to HTTP response headers.
+	MetadataHeaders []string
+	ResponseHeaders []string
+	// RoleHeader and TenantHeader name the HTTP headers carrying the roles and
+	// the tenant of the caller, when set by a trusted proxy.
+	RoleHeader   string
+	TenantHeader string
+
+	// Pretty indents JSON responses.
+	Pretty bool
+	// JSONNumber keeps every digit of numbers in variables.
+	JSONNumber bool
+	// MessagePack answers clients accepting application/msgpack in MessagePack.
+	MessagePack bool
+}
+
+// NewGateway loads the GraphQL project of config and returns the handler
+// serving it, connected to the configured backends.
+func NewGateway(config Config) (http.Handler, error) {
+	root := config.Root
+	if root == "" {
+		root = "."
+	}
+	transport := []grpctp.Option{grpctp.WithMaxConnsPerEndpoint(orDefault(config.MaxConnsPerEndpoint, 2))}
+	if d := orDefault(config.RPCTimeout, 3*time.Second); d > 0 {
+		transport = append(transport, grpctp.WithRPCTimeout(d))
+	}
+
+	sopts := []server.Option{
+		server.WithGraphiQL(!config.DisableGraphiQL),
+		server.WithTimeout(max(orDefault(config.Timeout, 10*time.Second), 0)),
+		server.WithQueryCache(max(orDefault(config.QueryCacheSize, 1000), 0)),
+	}
+	if config.Pretty {
+		sopts = append(sopts, server.WithPretty())
+	}
+	if config.JSONNumber {
+		sopts = append(sopts, server.WithJSONNumber(true))
+	}
+	if config.MessagePack {
+		sopts = append(sopts, server.WithResponseEncoder(msgpack.ContentType, msgpack.Encode))
+	}
+	if len(config.MetadataHeaders) > 0 {
+		sopts = append(sopts, server.WithMetadataHeaders(config.MetadataHeaders...))
+	}
+	if len(config.ResponseHeaders) > 0 {
+		sopts = append(sopts, server.WithResponseHeaders(config.ResponseHeaders...))
+	}
+	if config.RoleHeader != "" {
+		sopts = append(sopts, server.WithRoleHeader(config.RoleHeader))
+	}
+	if config.TenantHeader != "" {
+		sopts = append(sopts, server.WithTenantHeader(config.TenantHeader))
+	}
+
+	var disc ir.Discovery
+	switch {
+	case config.FS != nil:
+		d, err := ir.NewFSDiscovery(config.FS, config.RootPackage, ir.LoadOptions{})
+		if err != nil {
+			return nil, err
+		}
+		disc = d
+	case config.SchemaURL != "":
+		ctx := context.Background()
+		if d := orDefault(config.SchemaTimeout, 30*time.Second); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		d, err := ir.NewURLDiscovery(ctx, config.SchemaURL, config.SchemaHeader, config.RootPackage, ir.LoadOptions{})
+		if err != nil {
+			return nil, err
+		}
+		disc = d
+	}
+
+	gw, err := gateway.New(gateway.Config{
+		Root:           root,
+		RootPackage:    config.RootPackage,
+		Discovery:      disc,
+		CacheDir:       config.CacheDir,
+		Backends:       config.Backends,
+		Transport:      gateway.TransportConfig{Options: transport},
+		Stubs:          config.Stubs,
+		FieldCacheSize: orDefault(config.FieldCacheSize, 10000),
+		Introspection:  !config.DisableIntrospection,
+		ServerOptions:  sopts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gw.Handler, nil
+}
+
+// orDefault returns def for the zero value.
+func orDefault[T int | time.Duration](v, def T) T {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,83 @@
+package protograph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNewGateway(t *testing.T) {
+	root := t.TempDir()
+	sdl := `schema { query: Query }
+
+type Query {
+    user(id: ID!): User @resolve
+}
+
+type User @loader {
+    id: ID!
+    name: String!
+}
+`
+	if err := os.WriteFile(filepath.Join(root, "users.graphql"), []byte(sdl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGateway(Config{Root: root}); err == nil {
+		t.Fatalf("gateway without a root package")
+	}
+	if _, err := NewGateway(Config{Root: root, RootPackage: "app"}); err == nil || !strings.Contains(err.Error(), "no backend mapping") {
+		t.Fatalf("gateway without backends: %v", err)
+	}
+
+	h, err := NewGateway(Config{Root: root, RootPackage: "app", Stubs: true})
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	embedded, err := NewGateway(Config{FS: fstest.MapFS{"users.graphql": {Data: []byte(sdl)}}, RootPackage: "app", Stubs: true})
+	if err != nil {
+		t.Fatalf("gateway of an fs.FS: %v", err)
+	}
+	for query, want := range map[string]string{
+		`{ __typename }`:                      `{"data":{"__typename":"Query"}}`,
+		`{ __type(name: \"User\") { name } }`: `{"data":{"__type":{"name":"User"}}}`,
+	} {
+		for _, h := range []http.Handler{h, embedded} {
+			req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"`+query+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if got := strings.TrimSpace(w.Body.String()); got != want {
+				t.Errorf("%s: got %s, want %s", query, got, want)
+			}
+		}
+	}
+}
+
+func TestNewGateway_SchemaTimeout(t *testing.T) {
+	// A registry that never answers
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := NewGateway(Config{SchemaURL: srv.URL, SchemaTimeout: 50 * time.Millisecond, RootPackage: "app", Stubs: true})
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("fetch from an unresponsive registry: %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("fetch gave up after %v", d)
+	}
+}
@@ -0,0 +1,153 @@
+// Package audit records the mutations executed by the gateway, for compliance
+// trails kept at the gateway layer rather than in every backend.
+//
+// Register subscribes to the GraphQL events of the global bus and writes a
+// Record of each executed mutation to a Sink: a JSON lines file, a gRPC method
+// or a Kafka producer. Variables are recorded as the events carry them, with
+// the values of sensitive variables redacted.
+package audit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	reqid "github.com/hanpama/protograph/internal/reqid"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+// Status is the outcome of a mutation.
+type Status string
+
+const (
+	// StatusOK is recorded for mutations completed without errors.
+	StatusOK Status = "ok"
+	// StatusError is recorded for mutations with errors, which may have
+	// completed some of their root fields.
+	StatusError Status = "error"
+)
+
+// Record describes one executed mutation.
+type Record struct {
+	// Time is when the mutation finished.
+	Time          time.Time `json:"time"`
+	RequestID     int64     `json:"requestId"`
+	OperationName string    `json:"operationName,omitempty"`
+	// RootFields are the mutation fields selected, in selection order.
+	RootFields []string       `json:"rootFields"`
+	Variables  map[string]any `json:"variables,omitempty"`
+	// Principal and Tenant are those attached to the request by middleware;
+	// the principal must encode to JSON for the built-in sinks.
+	Principal  any      `json:"principal,omitempty"`
+	Tenant     string   `json:"tenant,omitempty"`
+	Status     Status   `json:"status"`
+	Errors     []string `json:"errors,omitempty"`
+	DurationMS float64  `json:"durationMs"`
+}
+
+// Sink stores records. Write is called from one goroutine at a time.
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// Register writes a record of every mutation finishing from now on to sink,
+// until unsubscribe is called. With queue > 0, records are written on a
+// goroutine of their own, and mutations wait for room once queue records are
+// pending, even past their deadline, so that none is dropped; unsubscribe then
+// waits for the pending records to be written. Otherwise they are written
+// before the response. Sinks receive the context of the request without its
+// cancellation, as a mutation that ran is recorded even if its caller left.
+// Write errors are logged.
+func Register(sink Sink, queue int) (unsubscribe func()) {
+	write := func(ctx context.Context, r Record) {
+		if err := sink.Write(ctx, r); err != nil {
+			log.Printf("audit: %v", err)
+		}
+	}
+	if queue <= 0 {
+		return eventbus.Subscribe(func(ctx context.Context, e events.GraphQLFinish) {
+			if e.OperationType == "mutation" {
+				write(context.WithoutCancel(ctx), newRecord(ctx, e))
+			}
+		})
+	}
+	// Not an eventbus.Buffered queue, whose Block policy gives up once the
+	// publisher's context is done
+	type pending struct {
+		ctx context.Context
+		r   Record
+	}
+	records := make(chan pending, queue)
+	done, drained := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case p := <-records:
+				write(p.ctx, p.r)
+			case <-done:
+				for {
+					select {
+					case p := <-records:
+						write(p.ctx, p.r)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+	// Handlers may still run after remove returns, as the bus calls them outside
+	// its lock: unsubscribe waits for those sending under mu before done closes
+	var mu sync.RWMutex
+	closed := false
+	remove := eventbus.Subscribe(func(ctx context.Context, e events.GraphQLFinish) {
+		if e.OperationType != "mutation" {
+			return
+		}
+		mu.RLock()
+		defer mu.RUnlock()
+		if !closed {
+			records <- pending{context.WithoutCancel(ctx), newRecord(ctx, e)}
+		}
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			remove()
+			mu.Lock()
+			closed = true
+			mu.Unlock()
+			close(done)
+			<-drained
+		})
+	}
+}
+
+func newRecord(ctx context.Context, e events.GraphQLFinish) Record {
+	r := Record{
+		Time:          time.Now(),
+		OperationName: e.OperationName,
+		RootFields:    e.RootFields,
+		Variables:     e.Variables,
+		Status:        StatusOK,
+		DurationMS:    float64(e.Duration) / float64(time.Millisecond),
+	}
+	r.RequestID, _ = reqid.FromContext(ctx)
+	r.Principal, _ = protographctx.Principal(ctx)
+	r.Tenant, _ = protographctx.Tenant(ctx)
+	if op, ok := protographctx.OperationFromContext(ctx); ok && r.OperationName == "" {
+		// The name of the only operation of a request naming none
+		r.OperationName = op.Name
+	}
+	if len(e.Errors) > 0 {
+		r.Status = StatusError
+		for _, err := range e.Errors {
+			r.Errors = append(r.Errors, err.Error())
+		}
+	}
+	return r
+}
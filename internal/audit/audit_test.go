@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+	server "github.com/hanpama/protograph/internal/server"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+const auditSDL = `schema { query: Query mutation: Mutation }
+type Query { hello: String }
+type Mutation {
+  login(user: String!, password: String!): String
+  logout: String
+}
+`
+
+func TestRegister(t *testing.T) {
+	sch, err := schema.BuildFromSDL(auditSDL)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello":     func(context.Context, any, map[string]any) (any, error) { return "world", nil },
+		"Mutation.login":  func(context.Context, any, map[string]any) (any, error) { return "token", nil },
+		"Mutation.logout": func(context.Context, any, map[string]any) (any, error) { return nil, errors.New("no session") },
+	})
+	h, err := server.New(rt, sch, server.WithRedactedVariables("password"))
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	withPrincipal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(protographctx.WithPrincipal(r.Context(), "ann")))
+	})
+
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	var buf bytes.Buffer
+	unsubscribe := Register(NewFileSink(&buf), 0)
+
+	for _, body := range []string{
+		`{"query":"{ hello }"}`,
+		`{"query":"mutation Login($u: String!, $p: String!) { login(user: $u, password: $p) ...F } fragment F on Mutation { login(user: $u, password: $p) logout }","variables":{"u":"ann","p":"hunter2"}}`,
+		`{"query":"mutation { logout }"}`,
+	} {
+		withPrincipal.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	}
+	unsubscribe()
+	withPrincipal.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"mutation { logout }"}`)))
+
+	var got []Record
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r Record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		got = append(got, r)
+	}
+	want := []Record{
+		{
+			OperationName: "Login",
+			RootFields:    []string{"login", "logout"},
+			Variables:     map[string]any{"u": "ann", "p": server.RedactedValue},
+			Principal:     "ann",
+			Status:        StatusError,
+			Errors:        []string{"no session"},
+		},
+		{
+			RootFields: []string{"logout"},
+			Principal:  "ann",
+			Status:     StatusError,
+			Errors:     []string{"no session"},
+		},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Record{}, "Time", "RequestID", "DurationMS")); diff != "" {
+		t.Fatalf("records (-want +got):\n%s", diff)
+	}
+	if got[0].RequestID == got[1].RequestID || got[0].Time.IsZero() {
+		t.Errorf("request IDs and times not recorded: %+v", got)
+	}
+}
+
+// blockingSink holds writes until release is closed, and records the error of
+// the context of each write.
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	errs    []error
+}
+
+func (s *blockingSink) Write(ctx context.Context, r Record) error {
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, ctx.Err())
+	return nil
+}
+
+func TestRegister_CanceledRequests(t *testing.T) {
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finish := events.GraphQLFinish{OperationType: "mutation", RootFields: []string{"logout"}}
+
+	for _, queue := range []int{0, 1} {
+		sink := &blockingSink{release: make(chan struct{})}
+		unsubscribe := Register(sink, queue)
+		published := make(chan struct{})
+		go func() {
+			defer close(published)
+			// With a queue, the first record is being written, the second
+			// queued and the third waits for room past its deadline
+			for range 3 {
+				eventbus.Publish(ctx, finish)
+			}
+		}()
+		time.Sleep(10 * time.Millisecond)
+		close(sink.release)
+		<-published
+		unsubscribe()
+		if want := []error{nil, nil, nil}; !cmp.Equal(want, sink.errs, cmpopts.EquateErrors()) {
+			t.Errorf("queue %d: write context errors = %v, want %v", queue, sink.errs, want)
+		}
+	}
+}
+
+func TestRegister_UnsubscribeWhilePublishing(t *testing.T) {
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	finish := events.GraphQLFinish{OperationType: "mutation", RootFields: []string{"logout"}}
+
+	sink := &blockingSink{release: make(chan struct{})}
+	unsubscribe := Register(sink, 1)
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		// The first record is being written, the second queued and the third
+		// publisher waits for room inside the handler as unsubscribe is called
+		for range 3 {
+			eventbus.Publish(context.Background(), finish)
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	unsubscribed := make(chan struct{})
+	go func() {
+		defer close(unsubscribed)
+		unsubscribe()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(sink.release)
+	<-published
+	<-unsubscribed
+	if len(sink.errs) != 3 {
+		t.Errorf("wrote %d records, want 3", len(sink.errs))
+	}
+}
+
+type recordingConn struct {
+	grpc.ClientConnInterface
+	method string
+	msg    any
+}
+
+func (c *recordingConn) Invoke(_ context.Context, method string, args, _ any, _ ...grpc.CallOption) error {
+	c.method, c.msg = method, args
+	return nil
+}
+
+type producerFunc func(ctx context.Context, key, value []byte) error
+
+func (f producerFunc) Produce(ctx context.Context, key, value []byte) error {
+	return f(ctx, key, value)
+}
+
+func TestSinks(t *testing.T) {
+	r := Record{RequestID: 7, OperationName: "Login", RootFields: []string{"login"}, Status: StatusOK}
+
+	conn := &recordingConn{}
+	if err := NewGRPCSink(conn, "/audit.v1.AuditLog/Write", 0).Write(context.Background(), r); err != nil {
+		t.Fatalf("grpc: %v", err)
+	}
+	msg := conn.msg.(*structpb.Struct).AsMap()
+	if conn.method != "/audit.v1.AuditLog/Write" || msg["operationName"] != "Login" || msg["requestId"] != 7.0 {
+		t.Errorf("grpc: %s %v", conn.method, msg)
+	}
+
+	var key, value []byte
+	sink := NewKafkaSink(producerFunc(func(_ context.Context, k, v []byte) error {
+		key, value = k, v
+		return nil
+	}))
+	if err := sink.Write(context.Background(), r); err != nil {
+		t.Fatalf("kafka: %v", err)
+	}
+	if string(key) != "7" || !strings.Contains(string(value), `"rootFields":["login"]`) {
+		t.Errorf("kafka: %s %s", key, value)
+	}
+}
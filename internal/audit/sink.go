@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// FileSink writes records to w as JSON lines.
+type FileSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewFileSink returns a sink writing to w, typically a file opened for
+// appending.
+func NewFileSink(w io.Writer) *FileSink {
+	return &FileSink{enc: json.NewEncoder(w)}
+}
+
+func (s *FileSink) Write(_ context.Context, r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// GRPCSink sends each record as a google.protobuf.Struct, shaped as the JSON
+// of the record, to a unary method returning google.protobuf.Empty.
+type GRPCSink struct {
+	conn    grpc.ClientConnInterface
+	method  string
+	timeout time.Duration
+}
+
+// NewGRPCSink returns a sink calling method, e.g. "/audit.v1.AuditLog/Write",
+// on conn, waiting at most timeout for each call when positive. Calls do not
+// carry the metadata forwarded to the backends.
+func NewGRPCSink(conn grpc.ClientConnInterface, method string, timeout time.Duration) *GRPCSink {
+	return &GRPCSink{conn: conn, method: method, timeout: timeout}
+}
+
+func (s *GRPCSink) Write(_ context.Context, r Record) error {
+	msg, err := recordStruct(r)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	if err := s.conn.Invoke(ctx, s.method, msg, &emptypb.Empty{}); err != nil {
+		return fmt.Errorf("%s: %w", s.method, err)
+	}
+	return nil
+}
+
+// recordStruct converts r through its JSON encoding.
+func recordStruct(r Record) (*structpb.Struct, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := &structpb.Struct{}
+	if err := msg.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Producer publishes messages to a Kafka topic. It is implemented by thin
+// wrappers of the producers of Kafka client libraries.
+type Producer interface {
+	Produce(ctx context.Context, key, value []byte) error
+}
+
+// KafkaSink publishes records as JSON, keyed by request ID so that the records
+// of a batched request land on one partition in order.
+type KafkaSink struct {
+	producer Producer
+}
+
+// NewKafkaSink returns a sink publishing with producer.
+func NewKafkaSink(producer Producer) *KafkaSink {
+	return &KafkaSink{producer: producer}
+}
+
+func (s *KafkaSink) Write(ctx context.Context, r Record) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(context.WithoutCancel(ctx), []byte(strconv.FormatInt(r.RequestID, 10)), value)
+}
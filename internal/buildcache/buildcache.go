@@ -0,0 +1,229 @@
+// Package buildcache persists what a project compiles to, its IR and the
+// descriptors of its .proto files, keyed by the content of its SDL files, so
+// that restarts with unchanged SDL skip parsing the SDL and generating the
+// descriptors.
+package buildcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/protoreg"
+)
+
+// formatVersion is part of every key, to be bumped when the entry format
+// changes.
+const formatVersion = 1
+
+// Cache is a directory of build results. An entry is named after the root
+// package and a hash of the SDL files and of the running binary, so a new
+// protograph version never reads the entries of an older one, and replaces the
+// older entries of its root package.
+type Cache struct {
+	dir string
+}
+
+// New creates dir if needed.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("buildcache: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Load loads and builds the project like ir.LoadWithOptions and
+// protoreg.Build, from the cache when no selected SDL file changed since the
+// last Load. Unreadable entries are rebuilt, and failing to write an entry only
+// costs the next Load its hit.
+func (c *Cache) Load(rootDir, rootPkg string, opts ir.LoadOptions) (*ir.Project, *protoreg.Registry, error) {
+	disc, err := ir.NewFileSystemDiscoveryWithOptions(context.Background(), rootDir, rootPkg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.LoadDiscovery(disc, rootPkg)
+}
+
+// LoadDiscovery is Load for the SDL files listed by disc, such as an archive
+// of the project.
+func (c *Cache) LoadDiscovery(disc ir.Discovery, rootPkg string) (*ir.Project, *protoreg.Registry, error) {
+	ctx := context.Background()
+	snap, err := newSnapshot(ctx, disc)
+	if err != nil {
+		return nil, nil, err
+	}
+	stamp, stamped := binaryStamp()
+	name := entryName(rootPkg, snap.key(rootPkg, stamp))
+
+	if stamped {
+		if proj, reg, err := c.read(name); err == nil {
+			return proj, reg, nil
+		}
+	}
+	proj, err := ir.Build(ctx, snap)
+	if err != nil {
+		return nil, nil, err
+	}
+	reg, err := protoreg.Build(proj)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stamped {
+		c.write(rootPkg, name, proj, reg)
+	}
+	return proj, reg, nil
+}
+
+func (c *Cache) read(name string) (*ir.Project, *protoreg.Registry, error) {
+	irData, err := os.ReadFile(filepath.Join(c.dir, name+".json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	setData, err := os.ReadFile(filepath.Join(c.dir, name+".binpb"))
+	if err != nil {
+		return nil, nil, err
+	}
+	proj := new(ir.Project)
+	if err := json.Unmarshal(irData, proj); err != nil {
+		return nil, nil, err
+	}
+	set := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(setData, set); err != nil {
+		return nil, nil, err
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	reg, err := protoreg.BuildWithFiles(proj, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proj, reg, nil
+}
+
+func (c *Cache) write(rootPkg, name string, proj *ir.Project, reg *protoreg.Registry) {
+	irData, err := json.Marshal(proj)
+	if err != nil {
+		return
+	}
+	setData, err := proto.Marshal(reg.FileDescriptorSet())
+	if err != nil {
+		return
+	}
+	// The descriptors go last, as read needs both files
+	if writeFile(filepath.Join(c.dir, name+".json"), irData) != nil ||
+		writeFile(filepath.Join(c.dir, name+".binpb"), setData) != nil {
+		return
+	}
+	stale, _ := filepath.Glob(filepath.Join(c.dir, entryPrefix(rootPkg)+"*"))
+	for _, fp := range stale {
+		if !strings.HasPrefix(filepath.Base(fp), name+".") {
+			os.Remove(fp)
+		}
+	}
+}
+
+// writeFile replaces fp atomically, so concurrent Loads never read a partial
+// entry.
+func writeFile(fp string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fp), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fp)
+}
+
+func entryPrefix(rootPkg string) string {
+	return rootPkg + "-"
+}
+
+func entryName(rootPkg, key string) string {
+	return entryPrefix(rootPkg) + key
+}
+
+// binaryStamp identifies the running binary by its size and modification time.
+// Without it the cache is not used, as the output of another build could differ.
+func binaryStamp() (string, bool) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()), true
+}
+
+// snapshot is the SDL of a project read once, so that the build sees the
+// content the key was computed from.
+type snapshot struct {
+	metas    []*ir.ServiceMetadata
+	contents map[ir.ServiceID]string
+}
+
+func newSnapshot(ctx context.Context, disc ir.Discovery) (*snapshot, error) {
+	metas, err := disc.ListMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].ID < metas[j].ID })
+	s := &snapshot{metas: metas, contents: make(map[ir.ServiceID]string, len(metas))}
+	for _, m := range metas {
+		sdl, err := disc.ReadServiceSDL(ctx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.contents[m.ID] = sdl
+	}
+	return s, nil
+}
+
+func (s *snapshot) ListMetadata(ctx context.Context) ([]*ir.ServiceMetadata, error) {
+	return s.metas, nil
+}
+
+func (s *snapshot) ReadServiceSDL(ctx context.Context, id ir.ServiceID) (string, error) {
+	sdl, ok := s.contents[id]
+	if !ok {
+		return "", fmt.Errorf("service %q not found", id)
+	}
+	return sdl, nil
+}
+
+// key hashes everything the build depends on.
+func (s *snapshot) key(rootPkg, stamp string) string {
+	h := sha256.New()
+	write := func(parts ...string) {
+		for _, p := range parts {
+			binary.Write(h, binary.LittleEndian, uint64(len(p)))
+			h.Write([]byte(p))
+		}
+	}
+	write(fmt.Sprint(formatVersion), stamp, rootPkg)
+	for _, m := range s.metas {
+		write(string(m.ID), m.Name, strings.Join(m.PkgPath, "."), m.FilePath, s.contents[m.ID])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,135 @@
+package buildcache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/protoreg"
+)
+
+const usersSDL = `schema {
+    query: Query
+}
+
+type Query {
+    user(id: ID!): User @resolve
+}
+
+type User @loader {
+    id: ID!
+    name: String!
+    posts: [Post!]! @resolve(with: {authorId: "id"})
+}
+`
+
+const postsSDL = `type Post @loader {
+    id: ID!
+    title: String!
+}
+`
+
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		fp := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, n := range names {
+		names[i] = filepath.Ext(n)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func rendered(t *testing.T, reg *protoreg.Registry) map[string]string {
+	t.Helper()
+	files, err := protoreg.RenderFiles(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestCache_Load(t *testing.T) {
+	root := writeProject(t, map[string]string{"users.graphql": usersSDL, "blog/posts.graphql": postsSDL})
+	c, err := New(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj, reg, err := c.Load(root, "app", ir.LoadOptions{})
+	if err != nil {
+		t.Fatalf("miss: %v", err)
+	}
+	if diff := cmp.Diff([]string{".binpb", ".json"}, entries(t, c.dir)); diff != "" {
+		t.Fatalf("entries (-want +got):\n%s", diff)
+	}
+
+	cachedProj, cachedReg, err := c.Load(root, "app", ir.LoadOptions{})
+	if err != nil {
+		t.Fatalf("hit: %v", err)
+	}
+	if diff := cmp.Diff(proj, cachedProj); diff != "" {
+		t.Errorf("cached project (-built +cached):\n%s", diff)
+	}
+	if !proto.Equal(reg.FileDescriptorSet(), cachedReg.FileDescriptorSet()) {
+		t.Errorf("cached descriptors differ")
+	}
+	if diff := cmp.Diff(rendered(t, reg), rendered(t, cachedReg)); diff != "" {
+		t.Errorf("cached protos (-built +cached):\n%s", diff)
+	}
+	for _, field := range [][2]string{{"Query", "user"}, {"User", "posts"}} {
+		if cachedReg.GetSingleResolverDescriptor(field[0], field[1]) == nil && cachedReg.GetBatchResolverDescriptor(field[0], field[1]) == nil {
+			t.Errorf("no resolver of %s.%s in cached registry", field[0], field[1])
+		}
+	}
+
+	// A changed file is a miss, replacing the entry
+	if err := os.WriteFile(filepath.Join(root, "blog/posts.graphql"), []byte(postsSDL[:len(postsSDL)-2]+"    body: String\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, changedReg, err := c.Load(root, "app", ir.LoadOptions{})
+	if err != nil {
+		t.Fatalf("changed: %v", err)
+	}
+	if proto.Equal(reg.FileDescriptorSet(), changedReg.FileDescriptorSet()) {
+		t.Errorf("changed SDL served from the cache")
+	}
+	if got := len(entries(t, c.dir)); got != 2 {
+		t.Errorf("%d files in cache, want the 2 of the latest entry", got)
+	}
+
+	// Unreadable entries are rebuilt
+	corrupt, _ := filepath.Glob(filepath.Join(c.dir, "*.binpb"))
+	if err := os.WriteFile(corrupt[0], []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Load(root, "app", ir.LoadOptions{}); err != nil {
+		t.Fatalf("corrupt entry: %v", err)
+	}
+	if _, _, err := c.read(strings.TrimSuffix(filepath.Base(corrupt[0]), ".binpb")); err != nil {
+		t.Errorf("corrupt entry not rewritten: %v", err)
+	}
+}
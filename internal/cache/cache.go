@@ -0,0 +1,123 @@
+// Package cache provides the byte stores shared by the gateway's caches, such as
+// @cache field results. Memory keeps entries in the process; Redis shares them
+// between gateway replicas.
+//
+// Caching is best effort: stores report failures as misses and drop failed
+// writes, so an unavailable cache slows requests down but never fails them.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store holds opaque values under string keys. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value stored under key, reporting false on a miss or failure.
+	Get(ctx context.Context, key string) ([]byte, bool)
+	// Set stores value under key; the store drops it once ttl has passed.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// Memory is an in-process LRU Store.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory returns an LRU keeping at most capacity entries.
+func NewMemory(capacity int) *Memory {
+	return &Memory{capacity: capacity, entries: make(map[string]*list.Element), order: list.New(), now: time.Now}
+}
+
+// Get implements Store.
+func (c *Memory) Get(_ context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !c.now().Before(e.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Set implements Store.
+func (c *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &memoryEntry{key: key, value: value, expiresAt: c.now().Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+var _ Store = (*Memory)(nil)
+
+// Stats reports the lookups of a Store.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRate returns the fraction of lookups served from the store.
+func (s Stats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Counting counts the hits and misses of the Store it wraps.
+type Counting struct {
+	Store
+	hits, misses atomic.Uint64
+}
+
+// NewCounting returns s counting its lookups.
+func NewCounting(s Store) *Counting {
+	return &Counting{Store: s}
+}
+
+// Get implements Store.
+func (c *Counting) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok := c.Store.Get(ctx, key)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+// Stats returns the lookups counted so far.
+func (c *Counting) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_EvictsAndExpires(t *testing.T) {
+	c := NewMemory(2)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Hour)
+	_, _ = c.Get(ctx, "a") // a is now the most recently used
+	c.Set(ctx, "c", []byte("3"), time.Hour)
+
+	_, ok := c.Get(ctx, "b")
+	require.False(t, ok, "least recently used entry is evicted")
+	v, ok := c.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get(ctx, "a")
+	require.False(t, ok, "expired entry is dropped")
+	_, ok = c.Get(ctx, "c")
+	require.True(t, ok)
+}
+
+func TestCounting(t *testing.T) {
+	c := NewCounting(NewMemory(10))
+	ctx := context.Background()
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+
+	require.Equal(t, Stats{Hits: 2, Misses: 1}, c.Stats())
+	require.InDelta(t, 2.0/3, c.Stats().HitRate(), 1e-9)
+	require.Zero(t, Stats{}.HitRate())
+}
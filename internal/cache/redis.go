@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisOptions configures a Redis store.
+type RedisOptions struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+	// Username and Password authenticate with AUTH when Password is set.
+	Username string
+	Password string
+	// DB is selected on every new connection when non-zero.
+	DB int
+	// KeyPrefix is prepended to every key, separating gateways sharing a server.
+	KeyPrefix string
+	// PoolSize bounds the idle connections kept for reuse (default: 8).
+	PoolSize int
+	// Timeout bounds dialing and each command when ctx has no earlier deadline
+	// (default: 100ms).
+	Timeout time.Duration
+}
+
+// Redis is a Store backed by a Redis server, shared by every gateway replica
+// pointing at it. It speaks RESP over a small connection pool and needs no
+// client library.
+type Redis struct {
+	opts RedisOptions
+	idle chan *redisConn
+}
+
+// NewRedis returns a store for the server at opts.Addr. Connections are dialed
+// lazily; use Ping to check the server at startup.
+func NewRedis(opts RedisOptions) *Redis {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 8
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 100 * time.Millisecond
+	}
+	return &Redis{opts: opts, idle: make(chan *redisConn, opts.PoolSize)}
+}
+
+// Get implements Store.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool) {
+	v, err := r.do(ctx, "GET", r.opts.KeyPrefix+key)
+	if err != nil || v == nil {
+		return nil, false
+	}
+	b, ok := v.([]byte)
+	return b, ok
+}
+
+// Set implements Store.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		return
+	}
+	_, _ = r.do(ctx, "SET", r.opts.KeyPrefix+key, value, "PX", strconv.FormatInt(ms, 10))
+}
+
+// Ping checks that the server is reachable and accepts the credentials.
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.do(ctx, "PING")
+	return err
+}
+
+// Close closes the idle connections.
+func (r *Redis) Close() error {
+	for {
+		select {
+		case c := <-r.idle:
+			c.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+var _ Store = (*Redis)(nil)
+
+type redisConn struct {
+	net.Conn
+	rd *bufio.Reader
+	wr *bufio.Writer
+}
+
+// redisError is an error reply of the server; the connection stays usable.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// do runs one command on a pooled connection. Connections failing with anything
+// but an error reply are closed instead of being returned to the pool.
+func (r *Redis) do(ctx context.Context, args ...any) (any, error) {
+	c, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.roundTrip(r.deadline(ctx), args...)
+	var reply redisError
+	if err != nil && !errors.As(err, &reply) {
+		c.Close()
+		return nil, err
+	}
+	select {
+	case r.idle <- c:
+	default:
+		c.Close()
+	}
+	return v, err
+}
+
+func (r *Redis) deadline(ctx context.Context) time.Time {
+	d := time.Now().Add(r.opts.Timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(d) {
+		return dl
+	}
+	return d
+}
+
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+	dialer := net.Dialer{Deadline: r.deadline(ctx)}
+	nc, err := dialer.DialContext(ctx, "tcp", r.opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: nc, rd: bufio.NewReader(nc), wr: bufio.NewWriter(nc)}
+	if r.opts.Password != "" {
+		args := []any{"AUTH", r.opts.Password}
+		if r.opts.Username != "" {
+			args = []any{"AUTH", r.opts.Username, r.opts.Password}
+		}
+		if _, err := c.roundTrip(r.deadline(ctx), args...); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if r.opts.DB != 0 {
+		if _, err := c.roundTrip(r.deadline(ctx), "SELECT", strconv.Itoa(r.opts.DB)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// roundTrip writes a command as a RESP array of bulk strings and reads its reply.
+func (c *redisConn) roundTrip(deadline time.Time, args ...any) (any, error) {
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(c.wr, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch arg := arg.(type) {
+		case string:
+			b = []byte(arg)
+		case []byte:
+			b = arg
+		}
+		fmt.Fprintf(c.wr, "$%d\r\n", len(b))
+		c.wr.Write(b)
+		c.wr.WriteString("\r\n")
+	}
+	if err := c.wr.Flush(); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads a simple string, error, integer or bulk string reply. A nil
+// bulk string is returned as nil.
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rd, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+	return nil, fmt.Errorf("redis: unsupported reply type %q", kind)
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis answers AUTH, SELECT, PING, GET and SET on a local listener and
+// records every command it receives.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string][]byte
+	commands []string
+}
+
+func startFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{ln: ln, password: password, data: map[string][]byte{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	rd := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		switch {
+		case args[0] == "AUTH":
+			if args[len(args)-1] == f.password {
+				authed = true
+				fmt.Fprint(c, "+OK\r\n")
+			} else {
+				fmt.Fprint(c, "-WRONGPASS invalid password\r\n")
+			}
+		case !authed:
+			fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "PING":
+			fmt.Fprint(c, "+PONG\r\n")
+		case args[0] == "SELECT":
+			fmt.Fprint(c, "+OK\r\n")
+		case args[0] == "SET":
+			f.data[args[1]] = []byte(args[2])
+			fmt.Fprint(c, "+OK\r\n")
+		case args[0] == "GET":
+			if v, ok := f.data[args[1]]; ok {
+				fmt.Fprintf(c, "$%d\r\n%s\r\n", len(v), v)
+			} else {
+				fmt.Fprint(c, "$-1\r\n")
+			}
+		default:
+			fmt.Fprintf(c, "-ERR unknown command '%s'\r\n", args[0])
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeRedis) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...)
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func TestRedis_GetSet(t *testing.T) {
+	srv := startFakeRedis(t, "secret")
+	r := NewRedis(RedisOptions{Addr: srv.ln.Addr().String(), Password: "secret", DB: 2, KeyPrefix: "gw:"})
+	defer r.Close()
+	ctx := context.Background()
+
+	require.NoError(t, r.Ping(ctx))
+	_, ok := r.Get(ctx, "k")
+	require.False(t, ok)
+
+	r.Set(ctx, "k", []byte("binary\r\n\x00value"), 1500*time.Millisecond)
+	v, ok := r.Get(ctx, "k")
+	require.True(t, ok)
+	require.Equal(t, []byte("binary\r\n\x00value"), v)
+
+	// One connection is dialed, authenticated and reused
+	require.Equal(t, []string{
+		"AUTH secret", "SELECT 2", "PING",
+		"GET gw:k",
+		"SET gw:k binary\r\n\x00value PX 1500",
+		"GET gw:k",
+	}, srv.Commands())
+}
+
+func TestRedis_FailuresAreMisses(t *testing.T) {
+	srv := startFakeRedis(t, "secret")
+	r := NewRedis(RedisOptions{Addr: srv.ln.Addr().String(), Password: "wrong"})
+	ctx := context.Background()
+
+	require.EqualError(t, r.Ping(ctx), "redis: WRONGPASS invalid password")
+	r.Set(ctx, "k", []byte("v"), time.Minute)
+	_, ok := r.Get(ctx, "k")
+	require.False(t, ok)
+
+	srv.ln.Close()
+	down := NewRedis(RedisOptions{Addr: srv.ln.Addr().String()})
+	require.Error(t, down.Ping(ctx))
+	_, ok = down.Get(ctx, "k")
+	require.False(t, ok)
+}
@@ -0,0 +1,472 @@
+// Package compute parses and evaluates @compute field expressions.
+//
+// An expression is a pure function of sibling source fields of the same object:
+//
+//	firstName + " " + lastName
+//	(price - discount) * quantity
+//	coalesce(nickname, name, "anonymous")
+//
+// Supported forms are string/number/null literals, field references, parentheses,
+// unary minus, + - * / %, and coalesce(...). `+` concatenates when either operand
+// is a string; otherwise it adds. Any null operand makes an operator yield null,
+// while coalesce returns its first non-null argument.
+package compute
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed expression. It is immutable and safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses src into an expression.
+func Parse(src string) (*Expr, error) {
+	p := &parser{lex: lexer{src: src}}
+	p.next()
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source text of the expression.
+func (e *Expr) String() string { return e.src }
+
+// Refs returns the sorted, de-duplicated field names referenced by the expression.
+func (e *Expr) Refs() []string {
+	set := map[string]struct{}{}
+	e.root.refs(set)
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Eval evaluates the expression. lookup returns the value of a referenced field,
+// or nil when the field is absent. Integers evaluate to int64 and fractional
+// numbers to float64.
+func (e *Expr) Eval(lookup func(field string) any) (any, error) {
+	return e.root.eval(lookup)
+}
+
+// ---------------- AST ----------------
+
+type node interface {
+	eval(lookup func(string) any) (any, error)
+	refs(set map[string]struct{})
+}
+
+type literal struct{ value any }
+
+type fieldRef struct{ name string }
+
+type unary struct {
+	op      byte
+	operand node
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+type coalesce struct{ args []node }
+
+func (n literal) eval(func(string) any) (any, error) { return n.value, nil }
+func (n literal) refs(map[string]struct{})           {}
+
+func (n fieldRef) eval(lookup func(string) any) (any, error) { return normalize(lookup(n.name)) }
+func (n fieldRef) refs(set map[string]struct{})              { set[n.name] = struct{}{} }
+
+func (n unary) eval(lookup func(string) any) (any, error) {
+	v, err := n.operand.eval(lookup)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case int64:
+		return -x, nil
+	case float64:
+		return -x, nil
+	}
+	return nil, fmt.Errorf("compute: cannot negate %T", v)
+}
+func (n unary) refs(set map[string]struct{}) { n.operand.refs(set) }
+
+func (n binary) eval(lookup func(string) any) (any, error) {
+	l, err := n.left.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	if n.op == '+' {
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if lok || rok {
+			if !lok {
+				ls = format(l)
+			}
+			if !rok {
+				rs = format(r)
+			}
+			return ls + rs, nil
+		}
+	}
+	return arithmetic(n.op, l, r)
+}
+func (n binary) refs(set map[string]struct{}) {
+	n.left.refs(set)
+	n.right.refs(set)
+}
+
+func (n coalesce) eval(lookup func(string) any) (any, error) {
+	for _, arg := range n.args {
+		v, err := arg.eval(lookup)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+func (n coalesce) refs(set map[string]struct{}) {
+	for _, arg := range n.args {
+		arg.refs(set)
+	}
+}
+
+// normalize widens numeric values so operators only deal with int64 and float64.
+func normalize(v any) (any, error) {
+	switch x := v.(type) {
+	case nil, string, bool, int64, float64:
+		return x, nil
+	case int:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint64:
+		return int64(x), nil
+	case float32:
+		return float64(x), nil
+	}
+	return nil, fmt.Errorf("compute: unsupported operand type %T", v)
+}
+
+func format(v any) string {
+	switch x := v.(type) {
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
+
+func arithmetic(op byte, l, r any) (any, error) {
+	li, lInt := l.(int64)
+	ri, rInt := r.(int64)
+	if lInt && rInt {
+		switch op {
+		case '+':
+			return li + ri, nil
+		case '-':
+			return li - ri, nil
+		case '*':
+			return li * ri, nil
+		case '/', '%':
+			if ri == 0 {
+				return nil, fmt.Errorf("compute: division by zero")
+			}
+			if op == '/' {
+				return li / ri, nil
+			}
+			return li % ri, nil
+		}
+	}
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("compute: invalid operands %T %c %T", l, op, r)
+	}
+	switch op {
+	case '+':
+		return lf + rf, nil
+	case '-':
+		return lf - rf, nil
+	case '*':
+		return lf * rf, nil
+	case '/':
+		if rf == 0 {
+			return nil, fmt.Errorf("compute: division by zero")
+		}
+		return lf / rf, nil
+	}
+	return nil, fmt.Errorf("compute: operator %c requires integer operands", op)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// ---------------- parser ----------------
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("compute: %s at offset %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+// parseExpr parses additive expressions: term (('+' | '-') term)*
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseTerm parses multiplicative expressions: unary (('*' | '/' | '%') unary)*
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && strings.Contains("*/%", p.tok.text) {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind == tokOp && p.tok.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: '-', operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokString:
+		p.next()
+		return literal{value: tok.text}, nil
+	case tokNumber:
+		p.next()
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return literal{value: i}, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok.text)
+		}
+		return literal{value: f}, nil
+	case tokIdent:
+		p.next()
+		if tok.text == "null" {
+			return literal{value: nil}, nil
+		}
+		if p.tok.kind == tokLParen {
+			return p.parseCall(tok)
+		}
+		return fieldRef{name: tok.text}, nil
+	case tokLParen:
+		p.next()
+		inner, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ')'")
+		}
+		p.next()
+		return inner, nil
+	case tokError:
+		return nil, p.errorf("%s", tok.text)
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	if name.text != "coalesce" {
+		return nil, fmt.Errorf("compute: unknown function %q at offset %d", name.text, name.pos)
+	}
+	p.next() // '('
+	var args []node
+	for p.tok.kind != tokRParen {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.tok.kind == tokComma {
+			p.next()
+			continue
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ',' or ')'")
+		}
+	}
+	p.next() // ')'
+	if len(args) == 0 {
+		return nil, fmt.Errorf("compute: coalesce requires at least one argument at offset %d", name.pos)
+	}
+	return coalesce{args: args}, nil
+}
+
+// ---------------- lexer ----------------
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokError
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.src) && (l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\n') {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}
+	case strings.IndexByte("+-*/%", c) >= 0:
+		l.pos++
+		return token{kind: tokOp, text: string(c), pos: start}
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c >= '0' && c <= '9':
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos], pos: start}
+	case isIdentStart(c):
+		for l.pos < len(l.src) && (isIdentStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}
+	}
+	l.pos = len(l.src)
+	return token{kind: tokError, text: fmt.Sprintf("unexpected character %q", c), pos: start}
+}
+
+func (l *lexer) lexString(quote byte) token {
+	start := l.pos
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case quote:
+			l.pos++
+			return token{kind: tokString, text: sb.String(), pos: start}
+		case '\\':
+			if l.pos+1 < len(l.src) {
+				l.pos++
+				c = l.src[l.pos]
+			}
+		}
+		sb.WriteByte(c)
+		l.pos++
+	}
+	return token{kind: tokError, text: "unterminated string", pos: start}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
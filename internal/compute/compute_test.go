@@ -0,0 +1,86 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEval(t *testing.T) {
+	fields := map[string]any{
+		"firstName": "Ada",
+		"lastName":  "Lovelace",
+		"nickname":  nil,
+		"price":     int32(1200),
+		"discount":  int64(200),
+		"quantity":  int32(3),
+		"rate":      float32(0.5),
+	}
+	lookup := func(name string) any { return fields[name] }
+
+	for _, tc := range []struct {
+		expr string
+		want any
+	}{
+		{`firstName + " " + lastName`, "Ada Lovelace"},
+		{`'#' + quantity`, "#3"},
+		{`(price - discount) * quantity`, int64(3000)},
+		{`price / 7`, int64(171)},
+		{`price % 7`, int64(3)},
+		{`price * rate`, float64(600)},
+		{`-quantity + 1`, int64(-2)},
+		{`coalesce(nickname, firstName, "anonymous")`, "Ada"},
+		{`coalesce(nickname, null)`, nil},
+		{`nickname + lastName`, nil},
+		{`missing * 2`, nil},
+		{`1.5 + 1`, float64(2.5)},
+		{`"it\'s"`, "it's"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := e.Eval(lookup)
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	lookup := func(name string) any {
+		return map[string]any{"n": int64(1), "b": true}[name]
+	}
+	for _, src := range []string{`n / 0`, `n % 0.5`, `b * 2`, `-b`} {
+		e, err := Parse(src)
+		if err != nil {
+			t.Fatalf("parse %q: %v", src, err)
+		}
+		if _, err := e.Eval(lookup); err == nil {
+			t.Errorf("expected eval error for %q", src)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{``, `a +`, `(a`, `upper(a)`, `coalesce()`, `a b`, `"open`, `a $ b`, `1.2.3`} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("expected parse error for %q", src)
+		}
+	}
+}
+
+func TestRefs(t *testing.T) {
+	e, err := Parse(`coalesce(nickname, firstName) + " " + lastName + firstName`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"firstName", "lastName", "nickname"}, e.Refs()); diff != "" {
+		t.Errorf("refs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,190 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func expectStatus(resp *Response, want int) error {
+	if resp.Status != want {
+		return fmt.Errorf("status %d, want %d: %s", resp.Status, want, truncate(resp.Raw))
+	}
+	return nil
+}
+
+// expectTypename requires a successful response whose data.__typename is a
+// non-empty string.
+func expectTypename(resp *Response) error {
+	if err := expectNoErrors(resp); err != nil {
+		return err
+	}
+	v, err := lookup(resp.Body, "data", "__typename")
+	if err != nil {
+		return err
+	}
+	if s, ok := v.(string); !ok || s == "" {
+		return fmt.Errorf("data.__typename is %s, want the query type name", jsonString(v))
+	}
+	return nil
+}
+
+func expectNoErrors(resp *Response) error {
+	if err := expectResponse(resp); err != nil {
+		return err
+	}
+	if errs, ok := resp.Body["errors"]; ok {
+		return fmt.Errorf("unexpected errors: %s", jsonString(errs))
+	}
+	return nil
+}
+
+// expectErrors requires a non-empty errors list of well-formed errors.
+func expectErrors(resp *Response) error {
+	if err := expectResponse(resp); err != nil {
+		return err
+	}
+	errs, ok := resp.Body["errors"].([]any)
+	if !ok || len(errs) == 0 {
+		return fmt.Errorf("errors is %s, want a non-empty list", jsonString(resp.Body["errors"]))
+	}
+	for i, e := range errs {
+		if err := checkError(e); err != nil {
+			return fmt.Errorf("errors[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// expectRequestError requires errors raised before execution: no data entry,
+// or a null one.
+func expectRequestError(resp *Response) error {
+	if err := expectErrors(resp); err != nil {
+		return err
+	}
+	if data := resp.Body["data"]; data != nil {
+		return fmt.Errorf("data is %s, want absent or null for a request error", jsonString(data))
+	}
+	return nil
+}
+
+// expectResponse checks the top-level shape of a GraphQL response.
+func expectResponse(resp *Response) error {
+	if resp.Body == nil {
+		return fmt.Errorf("status %d: body is not a JSON object: %s", resp.Status, truncate(resp.Raw))
+	}
+	for k := range resp.Body {
+		switch k {
+		case "data", "errors", "extensions":
+		default:
+			return fmt.Errorf("unexpected top-level entry %q", k)
+		}
+	}
+	if _, hasData := resp.Body["data"]; !hasData {
+		if _, hasErrors := resp.Body["errors"]; !hasErrors {
+			return fmt.Errorf("response has neither data nor errors")
+		}
+	}
+	return nil
+}
+
+func checkError(e any) error {
+	obj, ok := e.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s is not an object", jsonString(e))
+	}
+	if msg, ok := obj["message"].(string); !ok || msg == "" {
+		return fmt.Errorf("message is %s, want a non-empty string", jsonString(obj["message"]))
+	}
+	if locs, ok := obj["locations"]; ok {
+		list, ok := locs.([]any)
+		if !ok {
+			return fmt.Errorf("locations is %s, want a list", jsonString(locs))
+		}
+		for _, l := range list {
+			loc, _ := l.(map[string]any)
+			line, _ := loc["line"].(float64)
+			column, _ := loc["column"].(float64)
+			if line < 1 || column < 1 {
+				return fmt.Errorf("location %s, want positive line and column", jsonString(l))
+			}
+		}
+	}
+	if path, ok := obj["path"]; ok {
+		list, ok := path.([]any)
+		if !ok {
+			return fmt.Errorf("path is %s, want a list", jsonString(path))
+		}
+		for _, p := range list {
+			switch p.(type) {
+			case string, float64:
+			default:
+				return fmt.Errorf("path %s has segment %s, want strings and integers", jsonString(path), jsonString(p))
+			}
+		}
+	}
+	if ext, ok := obj["extensions"]; ok {
+		if _, ok := ext.(map[string]any); !ok {
+			return fmt.Errorf("extensions is %s, want an object", jsonString(ext))
+		}
+	}
+	return nil
+}
+
+// lookup follows keys through nested objects.
+func lookup(v any, keys ...string) (any, error) {
+	for i, k := range keys {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is %s, want an object", strings.Join(keys[:i], "."), jsonString(v))
+		}
+		if v, ok = obj[k]; !ok {
+			return nil, fmt.Errorf("%s is missing", strings.Join(keys[:i+1], "."))
+		}
+	}
+	return v, nil
+}
+
+// responseKeys returns the keys of the data object in the order they were sent.
+func responseKeys(raw []byte) ([]string, error) {
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(resp.Data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("data is %s, want an object", truncate(resp.Data))
+	}
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return truncate(b)
+}
+
+func truncate(b []byte) string {
+	const max = 200
+	if len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,242 @@
+// Package conformance checks a running GraphQL endpoint against the GraphQL over
+// HTTP and execution specifications: transport behavior, operation selection,
+// error shapes, variable coercion and null propagation.
+//
+// Scenarios only rely on meta fields (__typename and, when introspection is
+// enabled, __schema and __type), so the suite runs against any deployed schema:
+//
+//	report := conformance.Run(ctx, "http://localhost:8080/graphql", conformance.Scenarios())
+//	report.Write(os.Stdout)
+//	if report.Failed() > 0 { ... }
+//
+// Scenarios that need introspection are skipped when the endpoint disables it.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scenario is one conformance check.
+type Scenario struct {
+	Category string // e.g. "http", "operation", "errors"
+	Name     string
+	// Introspection marks scenarios that query __schema or __type.
+	Introspection bool
+	// Check returns an error describing the first violation.
+	Check func(ctx context.Context, c *Client) error
+}
+
+// ID returns "category/name".
+func (s Scenario) ID() string { return s.Category + "/" + s.Name }
+
+// Client sends GraphQL requests to one endpoint.
+type Client struct {
+	endpoint string
+	header   http.Header
+	http     *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHeader adds a header to every request, typically for authentication.
+func WithHeader(name, value string) Option {
+	return func(c *Client) { c.header.Add(name, value) }
+}
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// NewClient returns a client for the GraphQL endpoint URL.
+func NewClient(endpoint string, opts ...Option) *Client {
+	c := &Client{endpoint: endpoint, header: http.Header{}, http: http.DefaultClient}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Request is a GraphQL request body.
+type Request struct {
+	Query         string         `json:"query,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is a decoded endpoint response. Body is nil when the response is not
+// a JSON object.
+type Response struct {
+	Status int
+	Header http.Header
+	Raw    []byte
+	Body   map[string]any
+}
+
+// Post sends req as a JSON POST request.
+func (c *Client) Post(ctx context.Context, req Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(ctx, http.MethodPost, "application/json", body)
+}
+
+// Get sends req as a GET request with query parameters.
+func (c *Client) Get(ctx context.Context, req Request) (*Response, error) {
+	q := url.Values{}
+	q.Set("query", req.Query)
+	if req.OperationName != "" {
+		q.Set("operationName", req.OperationName)
+	}
+	if req.Variables != nil {
+		vars, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("variables", string(vars))
+	}
+	sep := "?"
+	if strings.Contains(c.endpoint, "?") {
+		sep = "&"
+	}
+	return c.send(ctx, http.MethodGet, c.endpoint+sep+q.Encode(), "", nil)
+}
+
+// Do sends a raw body with the given method and content type.
+func (c *Client) Do(ctx context.Context, method, contentType string, body []byte) (*Response, error) {
+	return c.send(ctx, method, c.endpoint, contentType, body)
+}
+
+func (c *Client) send(ctx context.Context, method, target, contentType string, body []byte) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	out := &Response{Status: resp.StatusCode, Header: resp.Header, Raw: raw}
+	_ = json.Unmarshal(raw, &out.Body)
+	return out, nil
+}
+
+// Result is the outcome of one scenario.
+type Result struct {
+	Scenario Scenario
+	Err      error // nil when the scenario passed or was skipped
+	Skipped  bool
+	Duration time.Duration
+}
+
+// Report holds the results of a run in scenario order.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the number of failed scenarios.
+func (r *Report) Failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err joins the failures into one error, or returns nil when every scenario
+// passed or was skipped.
+func (r *Report) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Scenario.ID(), res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write prints one PASS, FAIL or SKIP line per scenario followed by a summary.
+func (r *Report) Write(w io.Writer) error {
+	var passed, skipped int
+	for _, res := range r.Results {
+		var err error
+		switch {
+		case res.Err != nil:
+			_, err = fmt.Fprintf(w, "FAIL %s (%s): %v\n", res.Scenario.ID(), res.Duration.Round(time.Millisecond), res.Err)
+		case res.Skipped:
+			skipped++
+			_, err = fmt.Fprintf(w, "SKIP %s: introspection disabled\n", res.Scenario.ID())
+		default:
+			passed++
+			_, err = fmt.Fprintf(w, "PASS %s (%s)\n", res.Scenario.ID(), res.Duration.Round(time.Millisecond))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d passed, %d failed, %d skipped\n", passed, r.Failed(), skipped)
+	return err
+}
+
+// Run executes scenarios in order against the endpoint. Introspection scenarios
+// are skipped when the endpoint does not answer { __schema { queryType { name } } }.
+func Run(ctx context.Context, endpoint string, scenarios []Scenario, opts ...Option) *Report {
+	c := NewClient(endpoint, opts...)
+	introspection := -1 // unknown until an introspection scenario runs
+	report := &Report{}
+	for _, s := range scenarios {
+		res := Result{Scenario: s}
+		if s.Introspection {
+			if introspection < 0 {
+				introspection = 0
+				if supportsIntrospection(ctx, c) {
+					introspection = 1
+				}
+			}
+			if introspection == 0 {
+				res.Skipped = true
+				report.Results = append(report.Results, res)
+				continue
+			}
+		}
+		start := time.Now()
+		res.Err = s.Check(ctx, c)
+		res.Duration = time.Since(start)
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+func supportsIntrospection(ctx context.Context, c *Client) bool {
+	resp, err := c.Post(ctx, Request{Query: "{ __schema { queryType { name } } }"})
+	if err != nil || resp.Body == nil || resp.Body["errors"] != nil {
+		return false
+	}
+	_, err = lookup(resp.Body, "data", "__schema", "queryType", "name")
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	introspection "github.com/hanpama/protograph/internal/introspection"
+	schema "github.com/hanpama/protograph/internal/schema"
+	server "github.com/hanpama/protograph/internal/server"
+)
+
+func newTestServer(t *testing.T, withIntrospection bool) *httptest.Server {
+	t.Helper()
+	sch, err := schema.BuildFromSDL(`type Query { hello: String }`)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	var rt executor.Runtime = executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	if withIntrospection {
+		w, err := introspection.Wrap(rt, sch)
+		if err != nil {
+			t.Fatalf("introspection: %v", err)
+		}
+		rt, sch = w.Runtime, w.Schema
+	}
+	h, err := server.New(rt, sch, server.WithGraphiQL(false))
+	if err != nil {
+		t.Fatalf("server: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGatewayConformance(t *testing.T) {
+	srv := newTestServer(t, true)
+	report := Run(context.Background(), srv.URL, Scenarios())
+	for _, res := range report.Results {
+		if res.Skipped {
+			t.Errorf("%s skipped", res.Scenario.ID())
+		}
+	}
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkipsIntrospectionScenarios(t *testing.T) {
+	srv := newTestServer(t, false)
+	report := Run(context.Background(), srv.URL, Scenarios())
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "SKIP null-propagation/nullable-field") || !strings.Contains(buf.String(), "0 failed, 2 skipped") {
+		t.Fatalf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestReportsFailures(t *testing.T) {
+	srv := newTestServer(t, false)
+	failing := Scenario{Category: "http", Name: "wrong-status", Check: func(ctx context.Context, c *Client) error {
+		resp, err := c.Post(ctx, Request{Query: "{ __typename }"})
+		if err != nil {
+			return err
+		}
+		return expectStatus(resp, 201)
+	}}
+	report := Run(context.Background(), srv.URL, []Scenario{failing})
+	if report.Failed() != 1 {
+		t.Fatalf("failed = %d, want 1", report.Failed())
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "http/wrong-status: status 200, want 201") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Scenarios returns the built-in suite.
+func Scenarios() []Scenario {
+	return []Scenario{
+		// GraphQL over HTTP
+		{Category: "http", Name: "post-json", Check: checkPostJSON},
+		{Category: "http", Name: "get-query", Check: checkGetQuery},
+		{Category: "http", Name: "invalid-json", Check: checkInvalidJSON},
+		{Category: "http", Name: "missing-query", Check: checkMissingQuery},
+		{Category: "http", Name: "method-not-allowed", Check: checkMethodNotAllowed},
+
+		// Operation selection
+		{Category: "operation", Name: "select-by-name", Check: checkSelectByName},
+		{Category: "operation", Name: "ambiguous-without-name", Check: checkAmbiguousOperation},
+		{Category: "operation", Name: "unknown-name", Check: checkUnknownOperation},
+		{Category: "operation", Name: "aliases", Check: checkAliases},
+
+		// Error shapes
+		{Category: "errors", Name: "syntax-error", Check: checkSyntaxError},
+		{Category: "errors", Name: "unknown-field", Check: checkUnknownField},
+
+		// Variable coercion
+		{Category: "variables", Name: "provided", Check: checkVariableProvided},
+		{Category: "variables", Name: "default-value", Check: checkVariableDefault},
+		{Category: "variables", Name: "missing-required", Check: checkVariableMissing},
+		{Category: "variables", Name: "wrong-type", Check: checkVariableWrongType},
+		{Category: "variables", Name: "null-for-non-null", Check: checkVariableNull},
+
+		// Null propagation
+		{Category: "null-propagation", Name: "nullable-field", Introspection: true, Check: checkNullableField},
+		{Category: "null-propagation", Name: "non-null-list-items", Introspection: true, Check: checkNonNullListItems},
+	}
+}
+
+const typenameQuery = "{ __typename }"
+
+func checkPostJSON(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: typenameQuery})
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "json") {
+		return fmt.Errorf("Content-Type is %q, want a JSON media type", ct)
+	}
+	return expectTypename(resp)
+}
+
+func checkGetQuery(ctx context.Context, c *Client) error {
+	resp, err := c.Get(ctx, Request{Query: typenameQuery})
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	return expectTypename(resp)
+}
+
+func checkInvalidJSON(ctx context.Context, c *Client) error {
+	resp, err := c.Do(ctx, http.MethodPost, "application/json", []byte(`{"query":`))
+	if err != nil {
+		return err
+	}
+	if resp.Status < 400 || resp.Status >= 500 {
+		return fmt.Errorf("status %d, want 4xx", resp.Status)
+	}
+	return expectRequestError(resp)
+}
+
+func checkMissingQuery(ctx context.Context, c *Client) error {
+	resp, err := c.Do(ctx, http.MethodPost, "application/json", []byte(`{}`))
+	if err != nil {
+		return err
+	}
+	if resp.Status < 400 || resp.Status >= 500 {
+		return fmt.Errorf("status %d, want 4xx", resp.Status)
+	}
+	return expectRequestError(resp)
+}
+
+func checkMethodNotAllowed(ctx context.Context, c *Client) error {
+	resp, err := c.Do(ctx, http.MethodPut, "application/json", []byte(`{"query":"{ __typename }"}`))
+	if err != nil {
+		return err
+	}
+	return expectStatus(resp, http.StatusMethodNotAllowed)
+}
+
+const twoOperations = "query A { a: __typename } query B { b: __typename }"
+
+func checkSelectByName(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: twoOperations, OperationName: "B"})
+	if err != nil {
+		return err
+	}
+	if err := expectNoErrors(resp); err != nil {
+		return err
+	}
+	data, err := lookup(resp.Body, "data")
+	if err != nil {
+		return err
+	}
+	obj, _ := data.(map[string]any)
+	if _, ok := obj["b"]; !ok || len(obj) != 1 {
+		return fmt.Errorf("data is %s, want only the fields of operation B", jsonString(data))
+	}
+	return nil
+}
+
+func checkAmbiguousOperation(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: twoOperations})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkUnknownOperation(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: twoOperations, OperationName: "C"})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkAliases(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: "{ first: __typename second: __typename __typename }"})
+	if err != nil {
+		return err
+	}
+	if err := expectNoErrors(resp); err != nil {
+		return err
+	}
+	keys, err := responseKeys(resp.Raw)
+	if err != nil {
+		return err
+	}
+	slices.Sort(keys)
+	if want := []string{"__typename", "first", "second"}; !slices.Equal(keys, want) {
+		return fmt.Errorf("data keys are %v, want %v", keys, want)
+	}
+	return nil
+}
+
+func checkSyntaxError(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: "{ __typename"})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkUnknownField(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: "{ __typename noSuchFieldForConformance }"})
+	if err != nil {
+		return err
+	}
+	return expectErrors(resp)
+}
+
+const variableQuery = "query ($v: String!) { __typename }"
+
+func checkVariableProvided(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: variableQuery, Variables: map[string]any{"v": "x"}})
+	if err != nil {
+		return err
+	}
+	return expectTypename(resp)
+}
+
+func checkVariableDefault(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: `query ($v: String! = "x") { __typename }`})
+	if err != nil {
+		return err
+	}
+	return expectTypename(resp)
+}
+
+func checkVariableMissing(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: variableQuery})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkVariableWrongType(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: "query ($v: Boolean!) { __typename }", Variables: map[string]any{"v": "yes"}})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkVariableNull(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: variableQuery, Variables: map[string]any{"v": nil}})
+	if err != nil {
+		return err
+	}
+	return expectRequestError(resp)
+}
+
+func checkNullableField(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: `{ missing: __type(name: "NoSuchTypeForConformance") { name } __typename }`})
+	if err != nil {
+		return err
+	}
+	if err := expectNoErrors(resp); err != nil {
+		return err
+	}
+	missing, err := lookup(resp.Body, "data", "missing")
+	if err != nil {
+		return err
+	}
+	if missing != nil {
+		return fmt.Errorf("data.missing is %s, want null", jsonString(missing))
+	}
+	return expectTypename(resp)
+}
+
+func checkNonNullListItems(ctx context.Context, c *Client) error {
+	resp, err := c.Post(ctx, Request{Query: "{ __schema { types { name kind } } }"})
+	if err != nil {
+		return err
+	}
+	if err := expectNoErrors(resp); err != nil {
+		return err
+	}
+	types, err := lookup(resp.Body, "data", "__schema", "types")
+	if err != nil {
+		return err
+	}
+	list, ok := types.([]any)
+	if !ok || len(list) == 0 {
+		return fmt.Errorf("data.__schema.types is %s, want a non-empty list", jsonString(types))
+	}
+	for i, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("data.__schema.types[%d] is %s, want an object", i, jsonString(item))
+		}
+		if _, ok := obj["kind"].(string); !ok {
+			return fmt.Errorf("data.__schema.types[%d].kind is %s, want a string", i, jsonString(obj["kind"]))
+		}
+	}
+	return nil
+}
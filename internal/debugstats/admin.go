@@ -0,0 +1,30 @@
+package debugstats
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/hanpama/protograph/internal/gateway"
+)
+
+// NewAdminMux returns the handler of an admin listener, serving next to the
+// statistics of gw at Path:
+//   - /debug/pprof/: the net/http/pprof profiles, including CPU profiles at
+//     /debug/pprof/profile?seconds=N and execution traces at
+//     /debug/pprof/trace?seconds=N, for go tool pprof and go tool trace
+//   - /debug/vars: the expvar variables, such as memstats and cmdline
+//
+// Profiling slows the process down and exposes its internals, so the admin
+// listener belongs on an address only operators reach.
+func NewAdminMux(gw *gateway.Gateway, slow *SlowLog) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler(gw, slow))
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
@@ -0,0 +1,202 @@
+// Package debugstats serves the runtime statistics of a gateway as JSON, for
+// operators looking into a running instance: the schema served, the health
+// and connection pools of the backends, cache hit rates, the operations in
+// flight and the recent slow operations.
+//
+// The statistics describe the deployment, so the handler is meant for an
+// endpoint kept away from untrusted clients, such as the admin listener of
+// NewAdminMux, which also serves the profiling endpoints.
+package debugstats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	"github.com/hanpama/protograph/internal/gateway"
+	reqid "github.com/hanpama/protograph/internal/reqid"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+// Path is where the serve command mounts the handler.
+const Path = "/debug/protograph"
+
+// SlowOperation is an operation that took at least the threshold of a SlowLog.
+type SlowOperation struct {
+	Time          time.Time `json:"time"`
+	RequestID     int64     `json:"requestId"`
+	OperationName string    `json:"operationName,omitempty"`
+	OperationType string    `json:"operationType,omitempty"`
+	DurationMS    float64   `json:"durationMs"`
+	Errors        int       `json:"errors"`
+}
+
+// SlowLog keeps the last operations taking at least a threshold.
+type SlowLog struct {
+	threshold time.Duration
+
+	mu   sync.Mutex
+	ring []SlowOperation
+	next int
+	full bool
+}
+
+// NewSlowLog returns a log of the last size operations taking at least
+// threshold.
+func NewSlowLog(threshold time.Duration, size int) *SlowLog {
+	return &SlowLog{threshold: threshold, ring: make([]SlowOperation, size)}
+}
+
+// Register records the operations finishing from now on, until unsubscribe is
+// called.
+func (l *SlowLog) Register() (unsubscribe func()) {
+	return eventbus.Subscribe(func(ctx context.Context, e events.GraphQLFinish) {
+		if e.Duration < l.threshold || len(l.ring) == 0 {
+			return
+		}
+		op := SlowOperation{
+			Time:          time.Now(),
+			OperationName: e.OperationName,
+			OperationType: e.OperationType,
+			DurationMS:    float64(e.Duration) / float64(time.Millisecond),
+			Errors:        len(e.Errors),
+		}
+		op.RequestID, _ = reqid.FromContext(ctx)
+		if o, ok := protographctx.OperationFromContext(ctx); ok && op.OperationName == "" {
+			// The name of the only operation of a request naming none
+			op.OperationName = o.Name
+		}
+		l.add(op)
+	})
+}
+
+func (l *SlowLog) add(op SlowOperation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ring[l.next] = op
+	l.next = (l.next + 1) % len(l.ring)
+	l.full = l.full || l.next == 0
+}
+
+// Operations returns the recorded operations, most recent first.
+func (l *SlowLog) Operations() []SlowOperation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.ring)
+	}
+	out := make([]SlowOperation, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, l.ring[(l.next-i+len(l.ring))%len(l.ring)])
+	}
+	return out
+}
+
+// Snapshot is the document served by Handler.
+type Snapshot struct {
+	Time           time.Time        `json:"time"`
+	Schema         Schema           `json:"schema"`
+	Backends       []Backend        `json:"backends"`
+	Caches         map[string]Cache `json:"caches"`
+	InFlight       int64            `json:"inFlight"`
+	SlowOperations []SlowOperation  `json:"slowOperations"`
+}
+
+// Schema identifies the schema served.
+type Schema struct {
+	Hash     string    `json:"hash"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+// Backend reports the connection pool of an endpoint for one tenant.
+type Backend struct {
+	Endpoint            string     `json:"endpoint"`
+	Tenant              string     `json:"tenant,omitempty"`
+	Healthy             bool       `json:"healthy"`
+	ActiveCalls         int64      `json:"activeCalls"`
+	ActiveStreams       int64      `json:"activeStreams"`
+	WaitingCalls        int64      `json:"waitingCalls"`
+	IdleConns           int        `json:"idleConns"`
+	OpenConns           int64      `json:"openConns"`
+	MaxConns            int        `json:"maxConns"`
+	Calls               uint64     `json:"calls"`
+	Errors              uint64     `json:"errors"`
+	DialFailures        uint64     `json:"dialFailures"`
+	Reconnects          uint64     `json:"reconnects"`
+	ConsecutiveFailures uint64     `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastErrorAt         *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// Cache reports the lookups of a cache.
+type Cache struct {
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
+// Handler serves the Snapshot of gw, with the operations of slow when not nil.
+func Handler(gw *gateway.Gateway, slow *SlowLog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(NewSnapshot(gw, slow))
+	})
+}
+
+// NewSnapshot returns the current Snapshot of gw.
+func NewSnapshot(gw *gateway.Gateway, slow *SlowLog) Snapshot {
+	stats := gw.Stats()
+	s := Snapshot{
+		Time:     time.Now(),
+		Schema:   Schema{Hash: stats.SchemaHash, LoadedAt: stats.LoadedAt},
+		Backends: []Backend{},
+		Caches: map[string]Cache{
+			"query":      {Hits: stats.QueryCache.Hits, Misses: stats.QueryCache.Misses, HitRate: stats.QueryCache.HitRate()},
+			"field":      {Hits: stats.FieldCache.Hits, Misses: stats.FieldCache.Misses, HitRate: stats.FieldCache.HitRate()},
+			"operation":  {Hits: stats.OperationCache.Hits, Misses: stats.OperationCache.Misses, HitRate: stats.OperationCache.HitRate()},
+			"projection": {Hits: stats.ProjectionCache.Hits, Misses: stats.ProjectionCache.Misses, HitRate: stats.ProjectionCache.HitRate()},
+		},
+		InFlight:       stats.InFlight,
+		SlowOperations: []SlowOperation{},
+	}
+	for _, b := range stats.Backends {
+		backend := Backend{
+			Endpoint:            b.Endpoint,
+			Tenant:              b.Tenant,
+			Healthy:             b.Healthy(),
+			ActiveCalls:         b.ActiveCalls,
+			ActiveStreams:       b.ActiveStreams,
+			WaitingCalls:        b.WaitingCalls,
+			IdleConns:           b.IdleConns,
+			OpenConns:           b.OpenConns,
+			MaxConns:            b.MaxConns,
+			Calls:               b.Calls,
+			Errors:              b.Errors,
+			DialFailures:        b.DialFailures,
+			Reconnects:          b.Reconnects,
+			ConsecutiveFailures: b.ConsecutiveFailures,
+			LastError:           b.LastError,
+		}
+		if !b.LastErrorAt.IsZero() {
+			backend.LastErrorAt = &b.LastErrorAt
+		}
+		s.Backends = append(s.Backends, backend)
+	}
+	if slow != nil {
+		s.SlowOperations = slow.Operations()
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+package debugstats
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	"github.com/hanpama/protograph/internal/gateway"
+	"github.com/hanpama/protograph/internal/grpctp"
+	"github.com/hanpama/protograph/internal/server"
+)
+
+const usersSDL = `schema { query: Query }
+
+type Query {
+    user(id: ID!): User @resolve
+}
+
+type User @loader {
+    id: ID!
+    name: String!
+}
+`
+
+// closedAddr returns the address of a listener closed at once, refusing
+// connections.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+// newGateway serves usersSDL with the backend at an address refusing
+// connections.
+func newGateway(t *testing.T) (gw *gateway.Gateway, backend string) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "users.graphql"), []byte(usersSDL), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backend = closedAddr(t)
+	gw, err := gateway.New(gateway.Config{
+		Root:          root,
+		RootPackage:   "app",
+		Backends:      map[string][]string{"*": {backend}},
+		Transport:     gateway.TransportConfig{Options: []grpctp.Option{grpctp.WithRPCTimeout(time.Second)}},
+		ServerOptions: []server.Option{server.WithQueryCache(10)},
+	})
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	return gw, backend
+}
+
+func TestHandler(t *testing.T) {
+	gw, backend := newGateway(t)
+
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	slow := NewSlowLog(0, 1)
+	defer slow.Register()()
+
+	for _, name := range []string{"A", "A", "B"} {
+		body := `{"query":"query ` + name + ` { user(id: \"1\") { name } }"}`
+		gw.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	}
+
+	// The connection reports failing to connect in the background
+	for deadline := time.Now().Add(5 * time.Second); gw.Stats().Backends[0].DialFailures == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	Handler(gw, slow).ServeHTTP(w, httptest.NewRequest("GET", Path, nil))
+	var got Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+
+	if len(got.Schema.Hash) != 64 || got.Schema.LoadedAt.IsZero() {
+		t.Errorf("schema = %+v", got.Schema)
+	}
+	if len(got.Backends) != 1 || got.Backends[0].LastError == "" || got.Backends[0].LastErrorAt == nil {
+		t.Fatalf("backends = %+v", got.Backends)
+	}
+	if got.Backends[0].DialFailures == 0 {
+		t.Errorf("dial failures = 0")
+	}
+	got.Backends[0].LastError, got.Backends[0].LastErrorAt, got.Backends[0].DialFailures = "", nil, 0
+	if diff := cmp.Diff([]Backend{{Endpoint: backend, MaxConns: 2, IdleConns: 1, OpenConns: 1, Calls: 3, Errors: 3, ConsecutiveFailures: 3}}, got.Backends); diff != "" {
+		t.Errorf("backends (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(Cache{Hits: 1, Misses: 2, HitRate: 1.0 / 3}, got.Caches["query"]); diff != "" {
+		t.Errorf("query cache (-want +got):\n%s", diff)
+	}
+	if p := got.Caches["projection"]; p.Misses == 0 || p.Hits == 0 {
+		t.Errorf("projection cache = %+v", p)
+	}
+	if len(got.SlowOperations) != 1 || got.SlowOperations[0].OperationName != "B" || got.SlowOperations[0].Errors != 1 {
+		t.Errorf("slow operations = %+v", got.SlowOperations)
+	}
+	if got.InFlight != 0 {
+		t.Errorf("in flight = %d", got.InFlight)
+	}
+
+	w = httptest.NewRecorder()
+	Handler(gw, nil).ServeHTTP(w, httptest.NewRequest("POST", Path, nil))
+	if w.Code != 405 {
+		t.Errorf("POST: %d", w.Code)
+	}
+}
+
+func TestSlowLog(t *testing.T) {
+	l := NewSlowLog(time.Second, 3)
+	for i := range 5 {
+		l.add(SlowOperation{RequestID: int64(i)})
+	}
+	var ids []int64
+	for _, op := range l.Operations() {
+		ids = append(ids, op.RequestID)
+	}
+	if diff := cmp.Diff([]int64{4, 3, 2}, ids); diff != "" {
+		t.Errorf("operations (-want +got):\n%s", diff)
+	}
+	if ops := NewSlowLog(time.Second, 3).Operations(); len(ops) != 0 {
+		t.Errorf("empty log: %+v", ops)
+	}
+}
+
+func TestNewAdminMux(t *testing.T) {
+	gw, _ := newGateway(t)
+	mux := NewAdminMux(gw, nil)
+	for path, want := range map[string]string{
+		Path:                             `"schema"`,
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile",
+		"/debug/vars":                    `"memstats"`,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 200 || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: %d %.200s", path, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package errcode defines the machine-readable codes reported in the
+// extensions.code entry of every GraphQL error the gateway returns.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code classifies an error for clients.
+type Code string
+
+const (
+	// ParseFailed reports a query document that is not syntactically valid.
+	ParseFailed Code = "GRAPHQL_PARSE_FAILED"
+	// ValidationFailed reports a query that does not fit the schema.
+	ValidationFailed Code = "GRAPHQL_VALIDATION_FAILED"
+	// BadUserInput reports invalid variables, arguments or request envelopes.
+	BadUserInput Code = "BAD_USER_INPUT"
+	// InternalServerError reports failures inside the gateway; it is also the
+	// code of errors that carry none.
+	InternalServerError Code = "INTERNAL_SERVER_ERROR"
+	// Unauthenticated reports a backend rejecting missing or invalid credentials.
+	Unauthenticated Code = "UNAUTHENTICATED"
+	// Forbidden reports a backend denying access to an authenticated caller.
+	Forbidden Code = "FORBIDDEN"
+	// DownstreamServiceError reports a failing or unreachable backend.
+	DownstreamServiceError Code = "DOWNSTREAM_SERVICE_ERROR"
+	// Overloaded reports a request shed because the gateway is at capacity.
+	Overloaded Code = "OVERLOADED"
+)
+
+// Key is the extensions entry holding the code.
+const Key = "code"
+
+// Error attaches a Code to an error.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap attaches code to err. It returns nil when err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error carrying code.
+func Errorf(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Of returns the code of the outermost *Error in err's chain, or
+// InternalServerError when there is none.
+func Of(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return InternalServerError
+}
+
+// Extensions returns a fresh extensions map holding code.
+func Extensions(code Code) map[string]any {
+	return map[string]any{Key: string(code)}
+}
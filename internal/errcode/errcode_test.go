@@ -0,0 +1,27 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	base := errors.New("boom")
+	coded := Wrap(Forbidden, base)
+	if Of(coded) != Forbidden || !errors.Is(coded, base) || coded.Error() != "boom" {
+		t.Fatalf("Wrap: code %s, error %v", Of(coded), coded)
+	}
+	if got := Of(fmt.Errorf("resolve: %w", coded)); got != Forbidden {
+		t.Fatalf("wrapped code = %s", got)
+	}
+	if got := Of(base); got != InternalServerError {
+		t.Fatalf("uncoded code = %s", got)
+	}
+	if Wrap(BadUserInput, nil) != nil {
+		t.Fatalf("Wrap(nil) is not nil")
+	}
+	if got := Extensions(ParseFailed); got[Key] != "GRAPHQL_PARSE_FAILED" {
+		t.Fatalf("Extensions = %v", got)
+	}
+}
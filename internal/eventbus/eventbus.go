@@ -10,27 +10,59 @@ import (
 // Handler processes events of type T.
 type Handler[T any] func(context.Context, T)
 
+// Policy decides what Publish does when a buffered subscriber's queue is full.
+type Policy int
+
+const (
+	// Block makes Publish wait for room, or for the publisher's context to be
+	// done, applying backpressure to the request path.
+	Block Policy = iota
+	// DropNewest discards the event being published.
+	DropNewest
+	// DropOldest discards the oldest queued event to make room.
+	DropOldest
+)
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscribeConfig)
+
+type subscribeConfig struct {
+	size   int
+	policy Policy
+}
+
+// Buffered delivers events on a goroutine of the subscriber, queueing up to size
+// of them; policy applies when the queue is full. Handlers then receive the
+// publisher's context without its cancellation. Unbuffered handlers run inside
+// Publish.
+func Buffered(size int, policy Policy) SubscribeOption {
+	return func(c *subscribeConfig) { c.size, c.policy = size, policy }
+}
+
 // Bus is a simple in-process event dispatcher.
 type Bus struct {
 	mu       sync.RWMutex
-	handlers map[reflect.Type][]any // Handler[T] stored without type
+	handlers map[reflect.Type][]*subscription
+}
+
+type subscription struct {
+	deliver func(context.Context, any)
 }
 
 // New creates a new Bus.
-func New() *Bus { return &Bus{handlers: make(map[reflect.Type][]any)} }
+func New() *Bus { return &Bus{handlers: make(map[reflect.Type][]*subscription)} }
 
-func (b *Bus) subscribe(t reflect.Type, h any) (unsubscribe func()) {
+func (b *Bus) subscribe(t reflect.Type, s *subscription) (unsubscribe func()) {
 	b.mu.Lock()
-	hs := b.handlers[t]
-	b.handlers[t] = append(hs, h)
+	b.handlers[t] = append(b.handlers[t], s)
 	b.mu.Unlock()
 	return func() {
 		b.mu.Lock()
 		defer b.mu.Unlock()
 		hs := b.handlers[t]
-		for i, fn := range hs {
-			if reflect.ValueOf(fn).Pointer() == reflect.ValueOf(h).Pointer() {
-				hs = append(hs[:i], hs[i+1:]...)
+		for i, other := range hs {
+			if other == s {
+				hs = append(hs[:i:i], hs[i+1:]...)
 				break
 			}
 		}
@@ -50,30 +82,100 @@ func (b *Bus) emit(ctx context.Context, e any) {
 	t := reflect.TypeOf(e)
 	b.mu.RLock()
 	hs := b.handlers[t]
-	if len(hs) == 0 {
-		b.mu.RUnlock()
-		return
-	}
-	copied := append([]any(nil), hs...)
 	b.mu.RUnlock()
-	for _, fn := range copied {
-		fn.(func(context.Context, any))(ctx, e)
+	// Unsubscribing copies the slice, so hs is never modified in place
+	for _, s := range hs {
+		s.deliver(ctx, e)
+	}
+}
+
+// queue is the buffer of a Buffered subscription, drained by its own goroutine.
+type queue struct {
+	items  chan queued
+	policy Policy
+	done   chan struct{}
+	once   sync.Once
+}
+
+type queued struct {
+	ctx context.Context
+	e   any
+}
+
+func newQueue(size int, policy Policy, h func(context.Context, any)) *queue {
+	q := &queue{items: make(chan queued, size), policy: policy, done: make(chan struct{})}
+	go func() {
+		for {
+			select {
+			case it := <-q.items:
+				h(it.ctx, it.e)
+			case <-q.done:
+				return
+			}
+		}
+	}()
+	return q
+}
+
+func (q *queue) push(ctx context.Context, e any) {
+	it := queued{ctx: context.WithoutCancel(ctx), e: e}
+	switch q.policy {
+	case DropNewest:
+		select {
+		case q.items <- it:
+		default:
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.items <- it:
+				return
+			default:
+			}
+			select {
+			case <-q.items:
+			default:
+			}
+		}
+	default:
+		select {
+		case q.items <- it:
+		case <-ctx.Done():
+		case <-q.done:
+		}
 	}
 }
 
+// stop ends delivery; queued events are discarded.
+func (q *queue) stop() { q.once.Do(func() { close(q.done) }) }
+
 var global atomic.Pointer[Bus]
 
 // Use sets the global bus. Passing nil disables event publishing.
 func Use(b *Bus) { global.Store(b) }
 
-// Subscribe registers h with the global bus.
-func Subscribe[T any](h Handler[T]) (unsubscribe func()) {
-	if b := global.Load(); b != nil {
-		t := reflect.TypeOf((*T)(nil)).Elem()
-		wrapped := func(ctx context.Context, v any) { h(ctx, v.(T)) }
-		return b.subscribe(t, wrapped)
+// Subscribe registers h with the global bus. By default h runs synchronously
+// within Publish; use Buffered to keep slow subscribers off the request path.
+func Subscribe[T any](h Handler[T], opts ...SubscribeOption) (unsubscribe func()) {
+	b := global.Load()
+	if b == nil {
+		return func() {}
+	}
+	var cfg subscribeConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	deliver := func(ctx context.Context, v any) { h(ctx, v.(T)) }
+	if cfg.size <= 0 {
+		return b.subscribe(t, &subscription{deliver: deliver})
+	}
+	q := newQueue(cfg.size, cfg.policy, deliver)
+	remove := b.subscribe(t, &subscription{deliver: q.push})
+	return func() {
+		remove()
+		q.stop()
 	}
-	return func() {}
 }
 
 // Publish sends e through the global bus.
@@ -0,0 +1,106 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct{ N int }
+
+// collector records the events its handler receives.
+type collector struct {
+	mu   sync.Mutex
+	seen []int
+}
+
+func (c *collector) handle(_ context.Context, e testEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = append(c.seen, e.N)
+}
+
+func (c *collector) Seen() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.seen...)
+}
+
+func TestSubscribe_SynchronousAndUnsubscribe(t *testing.T) {
+	Use(New())
+	defer Use(nil)
+	var a, b collector
+	unsubA := Subscribe(a.handle)
+	unsubB := Subscribe(b.handle)
+	defer unsubB()
+
+	Publish(context.Background(), testEvent{1})
+	unsubA()
+	Publish(context.Background(), testEvent{2})
+
+	require.Equal(t, []int{1}, a.Seen())
+	require.Equal(t, []int{1, 2}, b.Seen())
+}
+
+func TestSubscribe_BufferedPolicies(t *testing.T) {
+	Use(New())
+	defer Use(nil)
+	ctx := context.Background()
+	release := make(chan struct{})
+
+	// Each subscriber is stuck on its first event until released, so the
+	// following events queue up behind it.
+	subscribe := func(policy Policy) (*collector, func()) {
+		c := &collector{}
+		unsub := Subscribe(func(ctx context.Context, e testEvent) {
+			<-release
+			c.handle(ctx, e)
+		}, Buffered(2, policy))
+		return c, unsub
+	}
+	newest, unsubNewest := subscribe(DropNewest)
+	defer unsubNewest()
+	oldest, unsubOldest := subscribe(DropOldest)
+	defer unsubOldest()
+
+	Publish(ctx, testEvent{1})
+	time.Sleep(10 * time.Millisecond) // let both goroutines pick up event 1
+	for n := 2; n <= 5; n++ {
+		Publish(ctx, testEvent{n}) // never blocks
+	}
+	close(release)
+
+	require.Eventually(t, func() bool { return len(newest.Seen()) == 3 && len(oldest.Seen()) == 3 }, time.Second, time.Millisecond)
+	require.Equal(t, []int{1, 2, 3}, newest.Seen())
+	require.Equal(t, []int{1, 4, 5}, oldest.Seen())
+}
+
+func TestSubscribe_BufferedBlockAppliesBackpressure(t *testing.T) {
+	Use(New())
+	defer Use(nil)
+	release := make(chan struct{})
+	var c collector
+	unsub := Subscribe(func(ctx context.Context, e testEvent) {
+		<-release
+		c.handle(ctx, e)
+	}, Buffered(1, Block))
+	defer unsub()
+
+	Publish(context.Background(), testEvent{1})
+	time.Sleep(10 * time.Millisecond)
+	Publish(context.Background(), testEvent{2}) // fills the queue
+
+	// A full queue blocks the publisher until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	Publish(ctx, testEvent{3})
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	close(release)
+	require.Eventually(t, func() bool { return len(c.Seen()) == 2 }, time.Second, time.Millisecond)
+	require.Equal(t, []int{1, 2}, c.Seen())
+}
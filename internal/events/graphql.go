@@ -3,17 +3,24 @@ package events
 import "time"
 
 // GraphQLStart is emitted before executing a GraphQL operation.
+// Variables are the request variables with sensitive values redacted (see
+// server.Options.RedactVariables).
 type GraphQLStart struct {
 	Query         string
 	OperationName string
 	OperationType string
+	Variables     map[string]any
 }
 
 // GraphQLFinish is emitted after executing a GraphQL operation.
+// RootFields are the names of the top-level fields selected by the operation,
+// in selection order.
 type GraphQLFinish struct {
 	Query         string
 	OperationName string
 	OperationType string
+	Variables     map[string]any
+	RootFields    []string
 	Errors        []error
 	Duration      time.Duration
 }
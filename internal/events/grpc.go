@@ -7,18 +7,22 @@ import (
 )
 
 // GRPCClientStart is emitted before a gRPC client call.
+// BatchSize is the number of batch entries of the request, or 1 for a single
+// request.
 type GRPCClientStart struct {
-	Service string
-	Method  string
-	Target  string
+	Service   string
+	Method    string
+	Target    string
+	BatchSize int
 }
 
 // GRPCClientFinish is emitted after a gRPC client call completes.
 type GRPCClientFinish struct {
-	Service  string
-	Method   string
-	Target   string
-	Code     codes.Code
-	Err      error
-	Duration time.Duration
+	Service   string
+	Method    string
+	Target    string
+	BatchSize int
+	Code      codes.Code
+	Err       error
+	Duration  time.Duration
 }
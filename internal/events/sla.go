@@ -0,0 +1,18 @@
+package events
+
+import "time"
+
+// SLAExceeded is emitted when resolving a field took longer than its @sla
+// budget, or an operation longer than the sla of its operation manifest entry.
+// For a field, Method is the method it was resolved by ("node" for @node
+// fields) and Tasks the number of its tasks the call served; ObjectType, Field
+// and Method are empty for an operation.
+type SLAExceeded struct {
+	OperationName string
+	ObjectType    string
+	Field         string
+	Method        string
+	Tasks         int
+	Budget        time.Duration
+	Duration      time.Duration
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"github.com/hanpama/protograph/internal/errcode"
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// checkAliases rejects an operation selecting more than l.MaxRootFields distinct
+// top-level response names, or more than l.MaxAliases aliased fields once every
+// fragment is inlined. Aliases let one document repeat a field with different
+// arguments, multiplying the tasks of a backend batch. It runs after
+// checkFragments, so the spreads it follows form no cycle.
+func checkAliases(document *language.QueryDocument, operation *language.OperationDefinition, l Limits) error {
+	if l.MaxRootFields > 0 {
+		names := map[string]struct{}{}
+		rootResponseNames(document, operation.SelectionSet, names, map[string]bool{})
+		if int64(len(names)) > l.MaxRootFields {
+			return errcode.Errorf(errcode.BadUserInput, "execution limit exceeded: more than %d root fields", l.MaxRootFields)
+		}
+	}
+	if l.MaxAliases > 0 {
+		c := &aliasCounter{document: document, fragments: map[string]int64{}, limit: l.MaxAliases}
+		if c.selectionSet(operation.SelectionSet) > l.MaxAliases {
+			return errcode.Errorf(errcode.BadUserInput, "execution limit exceeded: more than %d aliases", l.MaxAliases)
+		}
+	}
+	return nil
+}
+
+// rootResponseNames adds the response names set selects at its own level, through
+// inline fragments and fragment spreads, to names. Fields sharing a response name
+// are merged by field collection, so they count once.
+func rootResponseNames(document *language.QueryDocument, set language.SelectionSet, names map[string]struct{}, visited map[string]bool) {
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *language.Field:
+			name := sel.Alias
+			if name == "" {
+				name = sel.Name
+			}
+			names[name] = struct{}{}
+		case *language.InlineFragment:
+			rootResponseNames(document, sel.SelectionSet, names, visited)
+		case *language.FragmentSpread:
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			if def := getFragmentDefinition(document, sel.Name); def != nil {
+				rootResponseNames(document, def.SelectionSet, names, visited)
+			}
+		}
+	}
+}
+
+type aliasCounter struct {
+	document  *language.QueryDocument
+	fragments map[string]int64 // aliases each visited fragment expands to
+	limit     int64
+}
+
+// selectionSet returns the aliased fields set expands to, counting a fragment
+// once per spread. Counts saturate past the limit.
+func (c *aliasCounter) selectionSet(set language.SelectionSet) int64 {
+	var total int64
+	for _, selection := range set {
+		var n int64
+		switch sel := selection.(type) {
+		case *language.Field:
+			n = c.selectionSet(sel.SelectionSet)
+			if sel.Alias != "" && sel.Alias != sel.Name {
+				n++
+			}
+		case *language.InlineFragment:
+			n = c.selectionSet(sel.SelectionSet)
+		case *language.FragmentSpread:
+			n = c.fragment(sel.Name)
+		}
+		total = min(total+n, c.limit+1)
+	}
+	return total
+}
+
+func (c *aliasCounter) fragment(name string) int64 {
+	if n, ok := c.fragments[name]; ok {
+		return n
+	}
+	var n int64
+	if def := getFragmentDefinition(c.document, name); def != nil {
+		n = c.selectionSet(def.SelectionSet)
+	}
+	c.fragments[name] = n
+	return n
+}
@@ -109,7 +109,8 @@
 // Errors are accumulated as located GraphQL errors (message + path). For a
 // Non-Null field, a null result or error triggers propagation to the nearest
 // nullable ancestor; otherwise, the field value is set to null and execution
-// continues. Batch results are independent, enabling partial success within a
+// continues. Non-Null fields marked schema.Field.Optional are set to null in
+// place, without propagation. Batch results are independent, enabling partial success within a
 // single batch call.
 //
 // # Runtime Contract
@@ -124,6 +125,14 @@
 // See runtime.go for detailed method contracts and guidance (ordering, partial
 // success, cancellation via context, batching strategies).
 //
+// # Planning
+//
+// Executor.Plan walks an operation with the same field collection and depth
+// rules but without resolving anything, returning the async task graph. Since
+// no values exist, list lengths are estimated and every possible type of an
+// abstract field is visited. Executor.Explain summarizes a plan per batch and
+// (type, field) group for debugging N+1-style schema designs.
+//
 // Notes and Alignment
 //
 //   - Async detection: This package relies on schema.Field.Async to indicate
@@ -134,10 +143,51 @@
 //   - Mutation: While the specification note suggests mutations may be modeled
 //     as all-sync, the executor does not enforce this; async mutation fields are
 //     supported if the schema marks them Async=true.
-//   - Fragments on abstract types: field collection currently matches inline
-//     fragment and fragment spread type conditions only when they equal the
-//     concrete object type name. Support for interface/union subtype matching is
-//     a known gap to address at collection time.
+//   - Fragments on abstract types: fields are collected per concrete object
+//     type. A fragment applies when its type condition names the object type,
+//     an interface it implements or a union it belongs to, so the items of a
+//     heterogeneous list select different fields, and their async fields are
+//     queued as tasks of their concrete type (see GroupTasks).
 //   - Cancellation: The executor prunes queued tasks under paths nullified by
-//     Non-Null propagation to avoid unnecessary runtime work.
+//     Non-Null propagation to avoid unnecessary runtime work. Tombstones are
+//     recorded at every object and list nulled while completing a value, down
+//     to single list items, so tasks queued for the earlier siblings of a
+//     failing item are dropped too.
+//   - Allocation: collected subfields and coerced arguments are computed once
+//     per field group and shared by every list item, and sibling paths share one
+//     backing array. Runtimes must therefore not mutate args or retain and
+//     modify paths. Tombstoned paths are kept in a trie rather than as
+//     formatted strings. ExecuteRequestPooled recycles response objects once the
+//     caller has encoded the result. executor_bench_test.go tracks the hot
+//     paths; compare runs with benchstat.
+//   - Prepared operations: Prepare compiles an operation once for repeated
+//     ExecutePrepared calls. Collected selections are shared per combination
+//     of @skip/@include condition variables, and literal arguments are coerced
+//     once into templates with slots for variables, so a request only coerces
+//     its variables before executing.
+//   - Parallel completion: with WithParallelCompletion, large batches are
+//     completed by several workers with private errors and task queues, merged
+//     in batch order afterwards so the result matches sequential completion.
+//   - Limits: WithLimits bounds result nodes, list items and estimated response
+//     bytes. Exceeding one stops the execution with a single error and no data.
+//     ContextWithLimits overrides them for the executions of one context.
+//   - Fragments: operations whose fragment spreads form a cycle are rejected
+//     before execution, as is, with Limits.MaxFragmentSpreads, an operation
+//     expanding to too many spreads once its fragments are inlined.
+//   - Aliases: with Limits.MaxAliases and Limits.MaxRootFields, operations
+//     aliasing too many fields or selecting too many top-level response names
+//     are rejected before execution.
+//   - Batch timeout: WithBatchTimeout fails the fields of a batch that
+//     outlives its deadline, keeping data completed by earlier batches.
+//   - Leaf objects: with WithLeafObjects and a runtime implementing
+//     LeafObjectCompleter, an object selecting only sync, argument-free scalar
+//     or enum fields is completed in one call that returns its JSON. Such
+//     objects appear in Data as json.RawMessage.
+//   - Observer: WithObserver reports each batch's start and finish, every
+//     response error and each Non-Null propagation, for insight tooling.
+//   - Key order: response objects are maps, which encoding/json writes with
+//     sorted keys. With WithKeyOrder, ExecutionResult.KeyOrder yields the
+//     entries of each object in selection order, per concrete type below
+//     abstract fields, for encoders following the spec's ordering; leaf
+//     objects are then completed in selection order as well.
 package executor
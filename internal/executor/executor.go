@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
+	"github.com/hanpama/protograph/internal/errcode"
 	language "github.com/hanpama/protograph/internal/language"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
@@ -24,12 +26,45 @@ type executionState struct {
 	context        context.Context
 	asyncTaskGroup []asyncTask
 	errors         []GraphQLError
-	// Store async tasks by ID for completion
-	asyncTaskInfo map[NodeID]asyncTask
 	// simple incremental id generator
 	nextID uint64
 	// prefixes of paths that have been nullified (tombstoned)
-	nullifiedPrefix map[string]struct{}
+	nullified pathTrie
+	// objects holds the response maps taken from objectPool when pooling
+	pool    bool
+	objects []map[string]any
+	// subfields caches collected sub-selections; see collectSubfields
+	subfields map[subfieldKey]*collectedFieldMap
+	// prepared is set when executing a shared PreparedOperation, and shared is
+	// its collection for the condition values of this execution
+	prepared *PreparedOperation
+	shared   *sharedCollection
+	// arguments caches coerced argument values per field node
+	arguments map[*language.Field]map[string]any
+	// leafObjects is set when the LeafObjectCompleter fast path is enabled
+	leafObjects LeafObjectCompleter
+	leaves      map[*collectedFieldMap][]LeafField
+	// workers and workerStates support parallel completion; see parallel.go
+	workers      int
+	workerStates []*executionState
+	// budget enforces the executor's Limits; nil when unlimited
+	budget       *budget
+	batchTimeout time.Duration
+	// observer receives execution events; batches counts the BatchResolveAsync
+	// calls and observedErrors the errors already reported
+	observer       Observer
+	batches        int
+	observedErrors int
+	// order records response key order when the executor has WithKeyOrder
+	order *KeyOrder
+}
+
+// subfieldKey identifies a field group by its backing array, which is shared by
+// every value completed for the group.
+type subfieldKey struct {
+	objectType *schema.Type
+	head       **language.Field
+	n          int
 }
 
 // asyncTask represents a pending async field resolution
@@ -39,17 +74,29 @@ type asyncTask struct {
 	ResponsePath Path
 	FieldType    *schema.TypeRef
 	Fields       []*language.Field
+	Optional     bool // null does not propagate to the parent
 }
 
 type asyncPending struct{}
 
 type Executor struct {
-	runtime Runtime
-	schema  *schema.Schema
+	runtime     Runtime
+	schema      *schema.Schema
+	leafObjects LeafObjectCompleter
+	// workers bounds the goroutines completing one batch; see WithParallelCompletion
+	workers      int
+	limits       Limits
+	batchTimeout time.Duration
+	observer     Observer
+	keyOrder     bool
 }
 
-func NewExecutor(runtime Runtime, schema *schema.Schema) *Executor {
-	return &Executor{runtime: runtime, schema: schema}
+func NewExecutor(runtime Runtime, schema *schema.Schema, opts ...Option) *Executor {
+	e := &Executor{runtime: runtime, schema: schema}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
 }
 
 func (e *Executor) ExecuteRequest(
@@ -59,62 +106,134 @@ func (e *Executor) ExecuteRequest(
 	variableValues map[string]any,
 	initialValue any,
 ) *ExecutionResult {
-	operation := getOperation(document, operationName)
-	if operation == nil {
-		return &ExecutionResult{Errors: []GraphQLError{{Message: "operation not found"}}}
-	}
-
-	coercedVariableValues, err := coerceVariableValues(e.schema, operation, variableValues)
-	if err != nil {
-		return &ExecutionResult{Errors: []GraphQLError{{Message: err.Error()}}}
-	}
+	res, _ := e.execute(ctx, document, operationName, variableValues, initialValue, false)
+	return res
+}
 
-	var rootType *schema.Type
-	switch operation.Operation {
-	case language.Query:
-		rootType = e.schema.GetQueryType()
-	case language.Mutation:
-		rootType = e.schema.GetMutationType()
-	case language.Subscription:
-		rootType = e.schema.GetSubscriptionType()
-	default:
-		return &ExecutionResult{Errors: []GraphQLError{{Message: fmt.Sprintf("unsupported operation type: %s", operation.Operation)}}}
+// ExecuteRequestPooled is ExecuteRequest with response objects taken from a
+// pool. The caller must call release once the result has been encoded; the
+// result's data must not be used afterwards.
+func (e *Executor) ExecuteRequestPooled(
+	ctx context.Context,
+	document *language.QueryDocument,
+	operationName string,
+	variableValues map[string]any,
+	initialValue any,
+) (result *ExecutionResult, release func()) {
+	res, state := e.execute(ctx, document, operationName, variableValues, initialValue, true)
+	if state == nil || len(state.objects) == 0 {
+		return res, func() {}
 	}
+	objects := state.objects
+	return res, func() { releaseObjects(objects) }
+}
 
-	if rootType == nil {
-		return &ExecutionResult{Errors: []GraphQLError{{Message: fmt.Sprintf("root type not found for %s operation", operation.Operation)}}}
+func (e *Executor) execute(
+	ctx context.Context,
+	document *language.QueryDocument,
+	operationName string,
+	variableValues map[string]any,
+	initialValue any,
+	pool bool,
+) (*ExecutionResult, *executionState) {
+	op, err := e.prepareOperation(document, operationName, false)
+	if err != nil {
+		return &ExecutionResult{Errors: []GraphQLError{errorFrom(err, nil)}}, nil
 	}
+	return e.executePrepared(ctx, op, variableValues, initialValue, pool)
+}
 
-	state := &executionState{
-		runtime:         e.runtime,
-		schema:          e.schema,
-		document:        document,
-		variableValues:  coercedVariableValues,
-		context:         ctx,
-		asyncTaskGroup:  []asyncTask{},
-		errors:          []GraphQLError{},
-		asyncTaskInfo:   make(map[NodeID]asyncTask),
-		nextID:          1,
-		nullifiedPrefix: make(map[string]struct{}),
+func (e *Executor) executePrepared(
+	ctx context.Context,
+	op *PreparedOperation,
+	variableValues map[string]any,
+	initialValue any,
+	pool bool,
+) (*ExecutionResult, *executionState) {
+	extensions := &ResponseExtensions{}
+	ctx = ContextWithExtensions(ctx, extensions)
+	state, err := e.newState(ctx, op, variableValues)
+	if err != nil {
+		return &ExecutionResult{Errors: []GraphQLError{errorFrom(err, nil)}}, nil
 	}
-
-	responseRoot := make(map[string]any)
+	state.pool = pool
 
 	// Root selection set: sync immediate expansion, async queued
-	rootResult := executeSelectionSet(state, rootType, operation.SelectionSet, initialValue, Path{})
-	for k, v := range rootResult {
-		responseRoot[k] = v
-	}
+	responseRoot := executeCollectedFields(state, op.rootType, state.collectRootFields(op), initialValue, Path{})
+	state.observeErrors()
 
 	// Depth-wise batch loop
-	for len(state.asyncTaskGroup) > 0 {
+	for len(state.asyncTaskGroup) > 0 && state.budget.err() == nil {
 		filtered, results := flushAsyncTasks(state)
-		for i, r := range results {
-			completeAsyncField(state, filtered[i], r, responseRoot)
+		if state.parallelWorkers(len(filtered)) > 1 {
+			completeAsyncFieldsParallel(state, filtered, results, responseRoot)
+		} else {
+			for i, r := range results {
+				completeAsyncField(state, filtered[i], r, responseRoot)
+			}
 		}
+		state.observeErrors()
 	}
 
-	return &ExecutionResult{Data: responseRoot, Errors: state.errors}
+	if err := state.budget.err(); err != nil {
+		return &ExecutionResult{Errors: []GraphQLError{*err}, Extensions: extensions.finish()}, state
+	}
+	return &ExecutionResult{Data: responseRoot, Errors: state.errors, Extensions: extensions.finish(), KeyOrder: state.order}, state
+}
+
+// prepare selects the operation, coerces its variables and resolves the root type.
+func (e *Executor) prepare(
+	ctx context.Context,
+	document *language.QueryDocument,
+	operationName string,
+	variableValues map[string]any,
+) (*executionState, *language.OperationDefinition, *schema.Type, error) {
+	op, err := e.prepareOperation(document, operationName, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	state, err := e.newState(ctx, op, variableValues)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return state, op.operation, op.rootType, nil
+}
+
+// newState coerces the variables of op and returns the state of one execution.
+func (e *Executor) newState(ctx context.Context, op *PreparedOperation, variableValues map[string]any) (*executionState, error) {
+	coercedVariableValues, err := coerceVariableValues(e.schema, op.operation, variableValues)
+	if err != nil {
+		return nil, err
+	}
+	if op.rootErr != nil {
+		return nil, op.rootErr
+	}
+	state := &executionState{
+		runtime:        e.runtime,
+		schema:         e.schema,
+		document:       op.document,
+		variableValues: coercedVariableValues,
+		context:        ctx,
+		asyncTaskGroup: []asyncTask{},
+		errors:         []GraphQLError{},
+		nextID:         1,
+		subfields:      make(map[subfieldKey]*collectedFieldMap),
+		shared:         op.collection(coercedVariableValues),
+		arguments:      make(map[*language.Field]map[string]any),
+		leafObjects:    e.leafObjects,
+		leaves:         make(map[*collectedFieldMap][]LeafField),
+		workers:        e.workers,
+		budget:         newBudget(e.executionLimits(ctx)),
+		batchTimeout:   e.batchTimeout,
+		observer:       e.observer,
+	}
+	if e.keyOrder {
+		state.order = newKeyOrder()
+	}
+	if op.shared {
+		state.prepared = op
+	}
+	return state, nil
 }
 
 type Node struct {
@@ -124,15 +243,28 @@ type Node struct {
 	ResponsePath Path
 }
 
-// executeSelectionSet executes a selection set without flushing
-func executeSelectionSet(state *executionState, objectType *schema.Type, selectionSet language.SelectionSet, objectValue any, path Path) map[string]any {
-	groupedFields := collectFields(state, objectType, selectionSet)
-	resultMap := make(map[string]any)
+// executeCollectedFields executes already collected fields without flushing
+func executeCollectedFields(state *executionState, objectType *schema.Type, groupedFields *collectedFieldMap, objectValue any, path Path) map[string]any {
+	ordered := groupedFields.orderedFields()
+	if state.budget != nil {
+		keys := int64(2)
+		for _, f := range ordered {
+			keys += int64(len(f.ResponseName)) + 4
+		}
+		if !state.budget.charge(1, 0, keys) {
+			return nil
+		}
+	}
+	resultMap := state.newObject(len(ordered))
+	if state.order != nil {
+		state.order.record(resultMap, ordered)
+	}
+	paths := newPathBlock(path, len(ordered))
 
-	for _, collectedField := range groupedFields.orderedFields() {
+	for i, collectedField := range ordered {
 		responseName := collectedField.ResponseName
 		fields := collectedField.Fields
-		fieldPath := appendPath(path, responseName)
+		fieldPath := paths.at(i, responseName)
 
 		fieldResult := executeFieldGroup(state, objectType, objectValue, fields, fieldPath)
 
@@ -148,9 +280,13 @@ func executeSelectionSet(state *executionState, objectType *schema.Type, selecti
 			continue
 		}
 
-		// Handle non-null child behavior with nullish detection
-		if schema.IsNonNull(fieldDef.Type) && isNullish(fieldResult) {
+		// Handle non-null child behavior with nullish detection; @optional fields
+		// degrade to null in place
+		if schema.IsNonNull(fieldDef.Type) && !fieldDef.Optional && isNullish(fieldResult) {
 			if len(path) > 0 {
+				state.observe(NonNullPropagation{Path: fieldPath, Nulled: path})
+				// Tasks already queued for earlier fields are under path
+				state.nullified.insert(path)
 				return nil
 			}
 			// Root level: keep going but write nil
@@ -180,14 +316,11 @@ func executeFieldGroup(state *executionState, objectType *schema.Type, objectVal
 
 	fieldDef := getFieldDefinition(objectType, fieldName)
 	if fieldDef == nil {
-		state.errors = append(state.errors, GraphQLError{
-			Message: fmt.Sprintf("Cannot query field '%s' on type '%s'", fieldName, objectType.Name),
-			Path:    path,
-		})
+		state.addError(errcode.ValidationFailed, fmt.Sprintf("Cannot query field '%s' on type '%s'", fieldName, objectType.Name), path)
 		return nil
 	}
 
-	argumentValues := coerceArgumentValues(fieldDef, field.Arguments, state.variableValues, state, path)
+	argumentValues := state.argumentValues(fieldDef, field, path)
 
 	async := fieldDef.Async
 	if !async {
@@ -208,9 +341,9 @@ func executeFieldGroup(state *executionState, objectType *schema.Type, objectVal
 			ResponsePath: path,
 			FieldType:    fieldDef.Type,
 			Fields:       fields,
+			Optional:     fieldDef.Optional,
 		}
 		state.asyncTaskGroup = append(state.asyncTaskGroup, at)
-		state.asyncTaskInfo[id] = at
 		return asyncPending{}
 	}
 }
@@ -220,9 +353,8 @@ func flushAsyncTasks(state *executionState) ([]asyncTask, []AsyncResolveResult)
 	// Filter out tasks under nullified prefixes
 	filtered := make([]asyncTask, 0, len(state.asyncTaskGroup))
 	for _, at := range state.asyncTaskGroup {
-		if state.hasNullifiedPrefix(at.ResponsePath) {
-			// Drop this task; also forget it for completion
-			delete(state.asyncTaskInfo, at.ID)
+		if state.nullified.hasPrefixOf(at.ResponsePath) {
+			// Drop this task
 			continue
 		}
 		filtered = append(filtered, at)
@@ -236,60 +368,83 @@ func flushAsyncTasks(state *executionState) ([]asyncTask, []AsyncResolveResult)
 
 	// Clear group before executing
 	state.asyncTaskGroup = nil
+	if len(filtered) == 0 {
+		// Every task was pruned; the runtime is never called with an empty batch
+		return nil, nil
+	}
 
 	// Execute batch
-	results := state.runtime.BatchResolveAsync(state.context, tasks)
+	batch := state.batches
+	state.batches++
+	if state.observer != nil {
+		state.observe(BatchStart{Batch: batch, Tasks: len(tasks), Groups: GroupTasks(tasks)})
+	}
+	start := time.Now()
+	results := resolveBatch(state, tasks)
+	if state.observer != nil {
+		failed := 0
+		for _, r := range results {
+			if r.Error != nil {
+				failed++
+			}
+		}
+		state.observe(BatchFinish{Batch: batch, Tasks: len(tasks), Errors: failed, Duration: time.Since(start)})
+	}
 	return filtered, results
 }
 
 // completeAsyncField completes a single async result, with non-null propagation and pruning
 func completeAsyncField(state *executionState, at asyncTask, res AsyncResolveResult, responseRoot map[string]any) {
-	delete(state.asyncTaskInfo, at.ID)
-
-	path := at.ResponsePath
 	// If this path is already nullified by an ancestor, ignore
-	if state.hasNullifiedPrefix(path) {
+	if state.nullified.hasPrefixOf(at.ResponsePath) {
 		return
 	}
+	completed, propagate := completeAsyncValue(state, at, res)
+	writeAsyncValue(state, at, completed, propagate, responseRoot)
+}
 
+// completeAsyncValue completes an async result without touching the response
+// tree. propagate reports that a Non-Null violation nulls the top-level field.
+func completeAsyncValue(state *executionState, at asyncTask, res AsyncResolveResult) (completed any, propagate bool) {
 	// Handle error case first
 	if res.Error != nil {
-		state.errors = append(state.errors, GraphQLError{Message: res.Error.Error(), Path: path})
-		// If non-null field, propagate to top-level field
-		if schema.IsNonNull(at.FieldType) {
-			top := topLevelFieldPath(path)
-			setValueAtPath(responseRoot, top, nil)
-			state.markNullifiedPrefix(top)
-			return
-		}
-		setValueAtPath(responseRoot, path, nil)
-		return
+		state.errors = append(state.errors, errorFrom(res.Error, at.ResponsePath))
+		return nil, schema.IsNonNull(at.FieldType) && !at.Optional
 	}
 
-	completed := completeValue(state, at.FieldType, at.Fields, res.Value, path)
+	completed = completeValue(state, at.FieldType, at.Fields, res.Value, at.ResponsePath)
 
 	// If non-null type but completion yielded nullish → propagate
-	if schema.IsNonNull(at.FieldType) && isNullish(completed) {
-		top := topLevelFieldPath(path)
-		setValueAtPath(responseRoot, top, nil)
-		state.markNullifiedPrefix(top)
-		return
+	if isNullish(completed) {
+		return nil, schema.IsNonNull(at.FieldType) && !at.Optional
 	}
+	return completed, false
+}
 
-	// Normal write; coerce typed-nil to interface nil
-	if isNullish(completed) {
-		setValueAtPath(responseRoot, path, nil)
-	} else {
-		setValueAtPath(responseRoot, path, completed)
+// writeAsyncValue stores a completed async value, or tombstones the top-level
+// field when propagate is set.
+func writeAsyncValue(state *executionState, at asyncTask, completed any, propagate bool, responseRoot map[string]any) {
+	if propagate {
+		top := topLevelFieldPath(at.ResponsePath)
+		state.observe(NonNullPropagation{Path: at.ResponsePath, Nulled: top})
+		setValueAtPath(responseRoot, top, nil)
+		state.nullified.insert(top)
+		return
 	}
+	// completed is interface nil for nullish values
+	setValueAtPath(responseRoot, at.ResponsePath, completed)
 }
 
 // completeValue completes a value
 func completeValue(state *executionState, fieldType *schema.TypeRef, fields []*language.Field, result any, path Path) any {
+	if state.budget.err() != nil {
+		// The result is discarded; stop completing
+		return nil
+	}
 	if schema.IsNonNull(fieldType) {
 		if isNullish(result) {
 			if !state.hasErrorAtPath(path) {
-				state.errors = append(state.errors, GraphQLError{Message: fmt.Sprintf("Cannot return null for non-nullable field %s", pathToString(path)), Path: path})
+				state.addError(errcode.InternalServerError, fmt.Sprintf("Cannot return null for non-nullable field %s", pathToString(path)), path)
 			}
 			return nil
 		}
@@ -312,7 +467,7 @@ func completeValue(state *executionState, fieldType *schema.TypeRef, fields []*l
 	namedType := schema.GetNamedType(fieldType)
 	typeObj := state.schema.Types[namedType]
 	if typeObj == nil {
-		state.errors = append(state.errors, GraphQLError{Message: fmt.Sprintf("Unknown type: %s", namedType), Path: path})
+		state.addError(errcode.InternalServerError, fmt.Sprintf("Unknown type: %s", namedType), path)
 		return nil
 	}
 
@@ -320,7 +475,10 @@ func completeValue(state *executionState, fieldType *schema.TypeRef, fields []*l
 	case schema.TypeKindScalar, schema.TypeKindEnum:
 		serialized, err := state.runtime.SerializeLeafValue(state.context, namedType, result)
 		if err != nil {
-			state.errors = append(state.errors, GraphQLError{Message: err.Error(), Path: path})
+			state.errors = append(state.errors, errorFrom(err, path))
+			return nil
+		}
+		if !state.budget.charge(1, 0, leafSize(serialized)) {
 			return nil
 		}
 		return serialized
@@ -329,7 +487,7 @@ func completeValue(state *executionState, fieldType *schema.TypeRef, fields []*l
 	case schema.TypeKindInterface, schema.TypeKindUnion:
 		return completeAbstractValue(state, namedType, fields, result, path)
 	default:
-		state.errors = append(state.errors, GraphQLError{Message: fmt.Sprintf("Cannot complete value of unexpected type: %s", typeObj.Kind), Path: path})
+		state.addError(errcode.InternalServerError, fmt.Sprintf("Cannot complete value of unexpected type: %s", typeObj.Kind), path)
 		return nil
 	}
 }
@@ -342,7 +500,7 @@ func completeListValue(state *executionState, listType *schema.TypeRef, fields [
 	} else {
 		rv := reflect.ValueOf(result)
 		if rv.Kind() != reflect.Slice {
-			state.errors = append(state.errors, GraphQLError{Message: fmt.Sprintf("Expected list value, got %T", result), Path: path})
+			state.addError(errcode.InternalServerError, fmt.Sprintf("Expected list value, got %T", result), path)
 			return nil
 		}
 		items = make([]any, rv.Len())
@@ -351,29 +509,156 @@ func completeListValue(state *executionState, listType *schema.TypeRef, fields [
 		}
 	}
 
+	if !state.budget.charge(1, int64(len(items)), int64(len(items))+2) {
+		return nil
+	}
 	inner := schema.Unwrap(listType)
 	completed := make([]any, len(items))
+	paths := newPathBlock(path, len(items))
 	for i, item := range items {
-		p := appendPath(path, i)
+		p := paths.at(i, i)
 		v := completeValue(state, inner, fields, item, p)
 		if schema.IsNonNull(inner) && isNullish(v) {
-			// Propagate null to the list field; error already recorded by inner completion
+			// Propagate null to the list field; error already recorded by inner
+			// completion. Tasks queued for earlier items are under path.
+			state.nullified.insert(path)
 			return nil
 		}
+		if isNullish(v) {
+			completed[i] = nil // typed-nil objects become null items
+			continue
+		}
 		completed[i] = v
 	}
 	return completed
 }
 
 func completeObjectValue(state *executionState, objectType *schema.Type, fields []*language.Field, result any, path Path) any {
-	sub := mergeSelectionSets(fields)
-	return executeSelectionSet(state, objectType, sub, result, path)
+	collected := state.collectSubfields(objectType, fields)
+	if onlyTypename(collected) {
+		return completeTypenameObject(state, objectType, collected)
+	}
+	if state.leafObjects != nil {
+		if leaves := state.leafFields(objectType, collected); leaves != nil {
+			if raw, ok := state.leafObjects.CompleteLeafObject(state.context, objectType.Name, result, leaves); ok {
+				if !state.budget.charge(int64(1+len(leaves)), 0, int64(len(raw))) {
+					return nil
+				}
+				return raw
+			}
+		}
+	}
+	return executeCollectedFields(state, objectType, collected, result, path)
+}
+
+// onlyTypename reports whether every collected field is __typename, as in
+// "{ node { __typename } }" for an abstract node.
+func onlyTypename(collected *collectedFieldMap) bool {
+	ordered := collected.orderedFields()
+	if len(ordered) == 0 {
+		return false
+	}
+	for _, f := range ordered {
+		if f.Fields[0].Name != "__typename" {
+			return false
+		}
+	}
+	return true
+}
+
+// completeTypenameObject completes a selection of __typename fields to the
+// concrete object type name without executing field groups. For abstract types
+// objectType is the type ResolveType returned, so the concrete name is used.
+func completeTypenameObject(state *executionState, objectType *schema.Type, collected *collectedFieldMap) map[string]any {
+	ordered := collected.orderedFields()
+	if state.budget != nil {
+		keys := int64(2)
+		for _, f := range ordered {
+			keys += int64(len(f.ResponseName)) + 4
+		}
+		if !state.budget.charge(1, 0, keys) {
+			return nil
+		}
+	}
+	resultMap := state.newObject(len(ordered))
+	if state.order != nil {
+		state.order.record(resultMap, ordered)
+	}
+	for _, f := range ordered {
+		resultMap[f.ResponseName] = objectType.Name
+	}
+	return resultMap
+}
+
+// collectSubfields merges and collects the sub-selections of a field group for
+// objectType. Collection only depends on the type, the group and the variables,
+// so the result is computed once per execution and shared by every item of a list.
+func (s *executionState) collectSubfields(objectType *schema.Type, fields []*language.Field) *collectedFieldMap {
+	key := subfieldKey{objectType: objectType, head: &fields[0], n: len(fields)}
+	if cached, ok := s.subfields[key]; ok {
+		return cached
+	}
+	if s.shared != nil {
+		if cached, ok := s.shared.subfields.Load(key); ok {
+			s.subfields[key] = cached.(*collectedFieldMap)
+			return cached.(*collectedFieldMap)
+		}
+	}
+	collected := collectFields(s, objectType, mergeSelectionSets(fields))
+	if s.shared != nil {
+		actual, _ := s.shared.subfields.LoadOrStore(key, collected)
+		collected = actual.(*collectedFieldMap)
+	}
+	s.subfields[key] = collected
+	return collected
+}
+
+// collectRootFields collects the root selection set of op.
+func (s *executionState) collectRootFields(op *PreparedOperation) *collectedFieldMap {
+	if s.shared == nil {
+		return collectFields(s, op.rootType, op.operation.SelectionSet)
+	}
+	if cached := s.shared.root.Load(); cached != nil {
+		return cached
+	}
+	collected := collectFields(s, op.rootType, op.operation.SelectionSet)
+	if !s.shared.root.CompareAndSwap(nil, collected) {
+		return s.shared.root.Load()
+	}
+	return collected
+}
+
+func (s *executionState) argTemplate(fieldDef *schema.Field, field *language.Field) *argTemplate {
+	if s.prepared == nil {
+		return nil
+	}
+	return s.prepared.argTemplate(s.schema, fieldDef, field)
+}
+
+// argumentValues coerces the arguments of a field node. Values that coerce
+// without errors are cached per node, as they only depend on the variables; the
+// runtime must not mutate them.
+func (s *executionState) argumentValues(fieldDef *schema.Field, field *language.Field, path Path) map[string]any {
+	if cached, ok := s.arguments[field]; ok {
+		return cached
+	}
+	errs := len(s.errors)
+	var args map[string]any
+	if t := s.argTemplate(fieldDef, field); t != nil {
+		args = t.instantiate(s, fieldDef, field, path)
+	} else {
+		args = coerceArgumentValues(fieldDef, field.Arguments, s.variableValues, s, path)
+	}
+	if len(s.errors) == errs {
+		s.arguments[field] = args
+	}
+	return args
 }
 
 func completeAbstractValue(state *executionState, abstractTypeName string, fields []*language.Field, result any, path Path) any {
 	abstractType := state.schema.Types[abstractTypeName]
 	if abstractType == nil {
-		state.addError(fmt.Sprintf("Unknown abstract type: %s", abstractTypeName), path)
+		state.addError(errcode.InternalServerError, fmt.Sprintf("Unknown abstract type: %s", abstractTypeName), path)
 		return nil
 	}
 
@@ -387,11 +672,11 @@ func completeAbstractValue(state *executionState, abstractTypeName string, field
 	case schema.TypeKindInterface:
 		concrete, err = state.runtime.ResolveInterfaceConcreteValue(state.context, abstractTypeName, result)
 	default:
-		state.addError(fmt.Sprintf("Type %s is not an abstract type", abstractTypeName), path)
+		state.addError(errcode.InternalServerError, fmt.Sprintf("Type %s is not an abstract type", abstractTypeName), path)
 		return nil
 	}
 	if err != nil {
-		state.addError(err.Error(), path)
+		state.addErrorFrom(err, path)
 		return nil
 	}
 	if isNullish(concrete) {
@@ -400,73 +685,17 @@ func completeAbstractValue(state *executionState, abstractTypeName string, field
 
 	typeName, err := state.runtime.ResolveType(state.context, abstractTypeName, concrete)
 	if err != nil {
-		state.addError(err.Error(), path)
+		state.addErrorFrom(err, path)
 		return nil
 	}
 	objectType := state.schema.Types[typeName]
 	if objectType == nil || objectType.Kind != schema.TypeKindObject {
-		state.addError(fmt.Sprintf("Abstract type %s must resolve to an Object type at runtime. Got: %s", abstractTypeName, typeName), path)
+		state.addError(errcode.InternalServerError, fmt.Sprintf("Abstract type %s must resolve to an Object type at runtime. Got: %s", abstractTypeName, typeName), path)
 		return nil
 	}
 	return completeObjectValue(state, objectType, fields, concrete, path)
 }
 
-func pathToString(path Path) string {
-	result := ""
-	for i, elem := range path {
-		if i > 0 {
-			result += "."
-		}
-		switch v := elem.(type) {
-		case string:
-			result += v
-		case int:
-			result += fmt.Sprintf("[%d]", v)
-		}
-	}
-	return result
-}
-
-func appendPath(path Path, elem PathElement) Path {
-	newPath := make(Path, len(path)+1)
-	copy(newPath, path)
-	newPath[len(path)] = elem
-	return newPath
-}
-
-// Prefix tombstone helpers
-func (s *executionState) markNullifiedPrefix(p Path) {
-	key := pathToString(p)
-	if key != "" {
-		s.nullifiedPrefix[key] = struct{}{}
-	}
-}
-
-func (s *executionState) hasNullifiedPrefix(p Path) bool {
-	if len(s.nullifiedPrefix) == 0 {
-		return false
-	}
-	// Build prefixes progressively
-	cur := Path{}
-	for _, elem := range p {
-		cur = append(cur, elem)
-		key := pathToString(cur)
-		if _, ok := s.nullifiedPrefix[key]; ok {
-			return true
-		}
-	}
-	return false
-}
-
-func topLevelFieldPath(p Path) Path {
-	for _, elem := range p {
-		if name, ok := elem.(string); ok {
-			return Path{name}
-		}
-	}
-	return Path{}
-}
-
 // getOperation retrieves the operation from the document
 func getOperation(document *language.QueryDocument, operationName string) *language.OperationDefinition {
 	if operationName == "" && len(document.Operations) == 1 {
@@ -498,15 +727,20 @@ func typeRefFromAST(t *language.Type) *schema.TypeRef {
 	return nil
 }
 
-// Helper function to add an error to the execution state
-func (state *executionState) addError(message string, path Path) {
-	state.errors = append(state.errors, GraphQLError{Message: message, Path: path})
+// addError records an error with the given code in extensions.code.
+func (state *executionState) addError(code errcode.Code, message string, path Path) {
+	state.errors = append(state.errors, GraphQLError{Message: message, Path: path, Extensions: errcode.Extensions(code)})
+}
+
+// addErrorFrom records err, coded by the errcode it carries.
+func (state *executionState) addErrorFrom(err error, path Path) {
+	state.errors = append(state.errors, errorFrom(err, path))
 }
 
 // hasErrorAtPath reports whether an error with the given path already exists.
 func (state *executionState) hasErrorAtPath(path Path) bool {
 	for _, err := range state.errors {
-		if reflect.DeepEqual(err.Path, path) {
+		if pathsEqual(err.Path, path) {
 			return true
 		}
 	}
@@ -517,7 +751,7 @@ func (state *executionState) hasErrorAtPath(path Path) bool {
 func resolveSyncField(state *executionState, objectType string, fieldName string, source any, args map[string]any, path Path) any {
 	value, err := state.runtime.ResolveSync(state.context, objectType, fieldName, source, args)
 	if err != nil {
-		state.addError(err.Error(), path)
+		state.addErrorFrom(err, path)
 		return nil
 	}
 	return value
@@ -543,6 +777,10 @@ func setValueAtPath(responseRoot map[string]any, path Path, value any) {
 				return
 			}
 			next, exists := m[e]
+			if exists && isNullish(next) {
+				// An ancestor was nulled after this task was queued
+				return
+			}
 			if !exists {
 				if i+1 < len(path)-1 {
 					next = make(map[string]any)
@@ -560,8 +798,9 @@ func setValueAtPath(responseRoot map[string]any, path Path, value any) {
 			for len(slice) <= e {
 				slice = append(slice, nil)
 			}
-			if slice[e] == nil {
-				slice[e] = make(map[string]any)
+			if isNullish(slice[e]) {
+				// Null list items stay null; never resurrect them as partial objects
+				return
 			}
 			current = slice[e]
 		}
@@ -593,8 +832,16 @@ func mergeSelectionSets(fields []*language.Field) language.SelectionSet {
 
 // isNullish returns true for nil interfaces and typed nils (map, slice, ptr, interface)
 func isNullish(v any) bool {
-	if v == nil {
+	// Common values are checked without reflection
+	switch v := v.(type) {
+	case nil:
 		return true
+	case string, bool, int, int32, int64, float32, float64, asyncPending:
+		return false
+	case map[string]any:
+		return v == nil
+	case []any:
+		return v == nil
 	}
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// newPetSchema builds
+//
+//	type Query  { pets: [Pet] @async  search: [SearchResult] }
+//	interface Pet { name: String }
+//	type Cat implements Pet { name: String  lives: Int  owner: Person @async }
+//	type Dog implements Pet { name: String  owner: Person @async }
+//	type Person { name: String }
+//	union SearchResult = Cat | Dog | Person
+func newPetSchema() *schema.Schema {
+	return newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("pets", "", schema.ListType(schema.NamedType("Pet"))).SetAsync(true),
+			schema.NewField("search", "", schema.ListType(schema.NamedType("SearchResult"))),
+		),
+		schema.NewType("Pet", schema.TypeKindInterface, "").
+			AddField(schema.NewField("name", "", schema.NamedType("String"))).
+			AddPossibleType("Cat").AddPossibleType("Dog"),
+		newObjectType("Cat",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("lives", "", schema.NamedType("Int")),
+			schema.NewField("owner", "", schema.NamedType("Person")).SetAsync(true),
+		).AddInterface("Pet"),
+		newObjectType("Dog",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("owner", "", schema.NamedType("Person")).SetAsync(true),
+		).AddInterface("Pet"),
+		newObjectType("Person", schema.NewField("name", "", schema.NamedType("String"))),
+		schema.NewType("SearchResult", schema.TypeKindUnion, "").
+			AddPossibleType("Cat").AddPossibleType("Dog").AddPossibleType("Person"),
+		newScalarType("String"), newScalarType("Int"),
+	)
+}
+
+func newPetRuntime() *MockRuntime {
+	field := func(name string) MockResolver {
+		return func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(map[string]any)[name], nil
+		}
+	}
+	pets := []any{
+		map[string]any{"kind": "Cat", "name": "Tom", "lives": 9},
+		map[string]any{"kind": "Dog", "name": "Rex"},
+		map[string]any{"kind": "Cat", "name": "Felix", "lives": 7},
+	}
+	owner := func(_ context.Context, source any, _ map[string]any) (any, error) {
+		return map[string]any{"kind": "Person", "name": "owner of " + source.(map[string]any)["name"].(string)}, nil
+	}
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.pets":   NewMockValueResolver(pets),
+		"Query.search": NewMockValueResolver(append(pets[:2:2], map[string]any{"kind": "Person", "name": "Ann"})),
+		"Cat.name":     field("name"),
+		"Cat.lives":    field("lives"),
+		"Cat.owner":    owner,
+		"Dog.name":     field("name"),
+		"Dog.owner":    owner,
+		"Person.name":  field("name"),
+	})
+	SetTypeResolver(rt, func(value any) (string, error) { return value.(map[string]any)["kind"].(string), nil })
+	return rt
+}
+
+// Pattern: Result comparison
+func TestAbstract_HeterogeneousList_GroupsTasksByConcreteType_Result(t *testing.T) {
+	rt := newPetRuntime()
+	rec := &eventRecorder{}
+	exec := NewExecutor(rt, newPetSchema(), WithObserver(rec))
+	doc := mustParseQuery(t, `{
+		pets {
+			...PetName
+			... on Cat { lives owner { name } }
+			... on Dog { owner { name } }
+		}
+	}
+	fragment PetName on Pet { name }`)
+
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"pets": []any{
+		map[string]any{"name": "Tom", "lives": 9, "owner": map[string]any{"name": "owner of Tom"}},
+		map[string]any{"name": "Rex", "owner": map[string]any{"name": "owner of Rex"}},
+		map[string]any{"name": "Felix", "lives": 7, "owner": map[string]any{"name": "owner of Felix"}},
+	}}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+
+	var groups [][]TaskGroup
+	for _, e := range rec.events {
+		if s, ok := e.(BatchStart); ok {
+			groups = append(groups, s.Groups)
+		}
+	}
+	wantGroups := [][]TaskGroup{
+		{{ObjectType: "Query", Field: "pets", Indices: []int{0}}},
+		{
+			{ObjectType: "Cat", Field: "owner", Indices: []int{0, 2}},
+			{ObjectType: "Dog", Field: "owner", Indices: []int{1}},
+		},
+	}
+	if diff := cmp.Diff(wantGroups, groups); diff != "" {
+		t.Fatalf("task groups mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestAbstract_UnionMembers_InterfaceFragment_Result(t *testing.T) {
+	exec := NewExecutor(newPetRuntime(), newPetSchema())
+	doc := mustParseQuery(t, "{ search { __typename ... on Pet { name } ... on Person { who: name } } }")
+
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"search": []any{
+		map[string]any{"__typename": "Cat", "name": "Tom"},
+		map[string]any{"__typename": "Dog", "name": "Rex"},
+		map[string]any{"__typename": "Person", "who": "Ann"},
+	}}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupTasks(t *testing.T) {
+	tasks := []AsyncResolveTask{
+		{ObjectType: "Cat", Field: "owner"},
+		{ObjectType: "Dog", Field: "owner"},
+		{ObjectType: "Cat", Field: "owner"},
+		{ObjectType: "Cat", Field: "vet"},
+	}
+	want := []TaskGroup{
+		{ObjectType: "Cat", Field: "owner", Indices: []int{0, 2}},
+		{ObjectType: "Dog", Field: "owner", Indices: []int{1}},
+		{ObjectType: "Cat", Field: "vet", Indices: []int{3}},
+	}
+	if diff := cmp.Diff(want, GroupTasks(tasks)); diff != "" {
+		t.Fatalf("groups mismatch (-want +got):\n%s", diff)
+	}
+	if groups := GroupTasks(nil); groups != nil {
+		t.Fatalf("GroupTasks(nil) = %v", groups)
+	}
+}
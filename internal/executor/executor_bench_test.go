@@ -0,0 +1,264 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Benchmarks cover the executor's hot paths with a runtime that only reads map
+// sources, so allocations are the executor's own. Compare runs with benchstat:
+//
+//	go test ./internal/executor -run '^$' -bench . -benchmem -count 10 > old.txt
+//	go test ./internal/executor -run '^$' -bench . -benchmem -count 10 > new.txt
+//	benchstat old.txt new.txt
+
+// mapRuntime resolves every field by reading it from the source map; root fields
+// read from root.
+type mapRuntime struct {
+	root map[string]any
+}
+
+func (r mapRuntime) resolve(source any, field string) any {
+	if source == nil {
+		return r.root[field]
+	}
+	return source.(map[string]any)[field]
+}
+
+func (r mapRuntime) ResolveSync(_ context.Context, _, field string, source any, _ map[string]any) (any, error) {
+	return r.resolve(source, field), nil
+}
+
+func (r mapRuntime) BatchResolveAsync(_ context.Context, tasks []AsyncResolveTask) []AsyncResolveResult {
+	results := make([]AsyncResolveResult, len(tasks))
+	for i, t := range tasks {
+		results[i].Value = r.resolve(t.Source, t.Field)
+	}
+	return results
+}
+
+func (r mapRuntime) ResolveType(_ context.Context, _ string, value any) (string, error) {
+	return value.(map[string]any)["__typename"].(string), nil
+}
+
+func (r mapRuntime) ResolveUnionConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (r mapRuntime) ResolveInterfaceConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (r mapRuntime) SerializeLeafValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func benchmarkExecute(b *testing.B, sch *schema.Schema, root map[string]any, query string) {
+	b.Helper()
+	benchmarkExecuteWith(b, sch, root, query, false)
+}
+
+func benchmarkExecuteWith(b *testing.B, sch *schema.Schema, root map[string]any, query string, pooled bool, opts ...Option) {
+	b.Helper()
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		b.Fatalf("parse error: %v", err)
+	}
+	exec := NewExecutor(mapRuntime{root: root}, sch, opts...)
+	ctx := context.Background()
+	if res := exec.ExecuteRequest(ctx, doc, "", nil, nil); len(res.Errors) > 0 {
+		b.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if pooled {
+			_, release := exec.ExecuteRequestPooled(ctx, doc, "", nil, nil)
+			release()
+		} else {
+			exec.ExecuteRequest(ctx, doc, "", nil, nil)
+		}
+	}
+}
+
+// deepTree builds a tree of the given depth where every node has fanout children.
+func deepTree(depth, fanout int) map[string]any {
+	node := map[string]any{"id": fmt.Sprint(depth), "name": "node", "score": depth}
+	if depth > 0 {
+		children := make([]any, fanout)
+		for i := range children {
+			children[i] = deepTree(depth-1, fanout)
+		}
+		node["children"] = children
+	}
+	return node
+}
+
+// BenchmarkExecute_DeepSyncTree resolves a sync tree of 3^7 nodes.
+func BenchmarkExecute_DeepSyncTree(b *testing.B) {
+	const depth = 7
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("tree", "", schema.NamedType("Node"))),
+		newObjectType("Node",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("score", "", schema.NamedType("Int")),
+			schema.NewField("children", "", schema.ListType(schema.NonNullType(schema.NamedType("Node")))),
+		),
+		newScalarType("ID"), newScalarType("String"), newScalarType("Int"),
+	)
+	selection := "id name score"
+	for range depth {
+		selection = "id name score children { " + selection + " }"
+	}
+	benchmarkExecute(b, sch, map[string]any{"tree": deepTree(depth, 3)}, "{ tree { "+selection+" } }")
+}
+
+// BenchmarkExecute_WideAsyncFanOut resolves an async field on each of 2000 list
+// items in one batch.
+func BenchmarkExecute_WideAsyncFanOut(b *testing.B) { benchmarkWideAsyncFanOut(b) }
+
+// BenchmarkExecute_WideAsyncFanOutParallel completes each batch of
+// BenchmarkExecute_WideAsyncFanOut on four goroutines.
+func BenchmarkExecute_WideAsyncFanOutParallel(b *testing.B) {
+	benchmarkWideAsyncFanOut(b, WithParallelCompletion(4))
+}
+
+func benchmarkWideAsyncFanOut(b *testing.B, opts ...Option) {
+	const n = 2000
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("items", "", schema.ListType(schema.NamedType("Item")))),
+		newObjectType("Item",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("detail", "", schema.NamedType("Detail")).SetAsync(true),
+			schema.NewField("owner", "", schema.NonNullType(schema.NamedType("Detail"))).SetAsync(true),
+		),
+		newObjectType("Detail",
+			schema.NewField("title", "", schema.NamedType("String")),
+			schema.NewField("body", "", schema.NamedType("String")),
+		),
+		newScalarType("ID"), newScalarType("String"),
+	)
+	items := make([]any, n)
+	for i := range items {
+		detail := map[string]any{"title": "t", "body": "b"}
+		items[i] = map[string]any{"id": fmt.Sprint(i), "detail": detail, "owner": detail}
+	}
+	benchmarkExecuteWith(b, sch, map[string]any{"items": items},
+		"{ items { id detail { title body } owner { title } } }", false, opts...)
+}
+
+// BenchmarkExecute_LargeList completes a 20000-row list of flat objects.
+func BenchmarkExecute_LargeList(b *testing.B) { benchmarkLargeList(b, false) }
+
+// BenchmarkExecute_LargeListPooled is BenchmarkExecute_LargeList with pooled
+// response objects.
+func BenchmarkExecute_LargeListPooled(b *testing.B) { benchmarkLargeList(b, true) }
+
+func benchmarkLargeList(b *testing.B, pooled bool) {
+	const n = 20000
+	fields := []string{"a", "b", "c", "d", "e"}
+	var defs []*schema.Field
+	for _, f := range fields {
+		defs = append(defs, schema.NewField(f, "", schema.NonNullType(schema.NamedType("String"))))
+	}
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("rows", "", schema.NonNullType(schema.ListType(schema.NonNullType(schema.NamedType("Row")))))),
+		newObjectType("Row", defs...),
+		newScalarType("String"),
+	)
+	rows := make([]any, n)
+	for i := range rows {
+		row := map[string]any{}
+		for _, f := range fields {
+			row[f] = f
+		}
+		rows[i] = row
+	}
+	benchmarkExecuteWith(b, sch, map[string]any{"rows": rows}, "{ rows { "+strings.Join(fields, " ")+" } }", pooled)
+}
+
+// BenchmarkExecute_NullPropagation checks 2000 queued tasks against tombstones
+// left by Non-Null violations in sibling root fields.
+func BenchmarkExecute_NullPropagation(b *testing.B) {
+	const n = 2000
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("items", "", schema.ListType(schema.NamedType("Item"))),
+			schema.NewField("broken1", "", schema.NonNullType(schema.NamedType("Item"))).SetAsync(true),
+			schema.NewField("broken2", "", schema.NonNullType(schema.NamedType("Item"))).SetAsync(true),
+		),
+		newObjectType("Item",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("next", "", schema.NamedType("Item")).SetAsync(true),
+		),
+		newScalarType("ID"),
+	)
+	items := make([]any, n)
+	for i := range items {
+		next := map[string]any{"id": "next", "next": map[string]any{"id": "last"}}
+		items[i] = map[string]any{"id": fmt.Sprint(i), "next": next}
+	}
+	doc, err := language.ParseQuery("{ broken1 { id } broken2 { id } items { id next { id next { id } } } }")
+	if err != nil {
+		b.Fatalf("parse error: %v", err)
+	}
+	exec := NewExecutor(mapRuntime{root: map[string]any{"items": items}}, sch)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	}
+}
+
+// BenchmarkExecute_SmallQuery runs a small operation with arguments and
+// fragments, where per-request setup dominates.
+func BenchmarkExecute_SmallQuery(b *testing.B) { benchmarkSmallQuery(b, false) }
+
+// BenchmarkExecute_SmallQueryPrepared is BenchmarkExecute_SmallQuery with a
+// PreparedOperation reused across requests.
+func BenchmarkExecute_SmallQueryPrepared(b *testing.B) { benchmarkSmallQuery(b, true) }
+
+func benchmarkSmallQuery(b *testing.B, prepared bool) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("user", "", schema.NamedType("User")).
+			AddArgument(schema.NewInputValue("id", "", schema.NonNullType(schema.NamedType("ID"))))),
+		newObjectType("User",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("friends", "", schema.ListType(schema.NamedType("User"))).
+				AddArgument(schema.NewInputValue("first", "", schema.NamedType("Int"))),
+		),
+		newScalarType("ID"), newScalarType("String"), newScalarType("Int"), newScalarType("Boolean"),
+	)
+	friend := map[string]any{"id": "2", "name": "b"}
+	root := map[string]any{"user": map[string]any{"id": "1", "name": "a", "friends": []any{friend, friend, friend}}}
+	doc, err := language.ParseQuery(`query Q($id: ID!, $full: Boolean!) {
+		user(id: $id) { ...U friends(first: 3) { ...U name @include(if: $full) } }
+	}
+	fragment U on User { id name }`)
+	if err != nil {
+		b.Fatalf("parse error: %v", err)
+	}
+	exec := NewExecutor(mapRuntime{root: root}, sch)
+	op, err := exec.Prepare(doc, "Q")
+	if err != nil {
+		b.Fatalf("prepare: %v", err)
+	}
+	vars := map[string]any{"id": "1", "full": true}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if prepared {
+			exec.ExecutePrepared(ctx, op, vars, nil)
+		} else {
+			exec.ExecuteRequest(ctx, doc, "Q", vars, nil)
+		}
+	}
+}
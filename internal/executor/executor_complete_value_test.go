@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
 	executor "github.com/hanpama/protograph/internal/executor"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
@@ -125,7 +126,7 @@ func TestCompleteValue_NonNull_Propagation_Result(t *testing.T) {
 				"obj": nil,
 			},
 			Errors: []executor.GraphQLError{
-				{Message: "boom", Path: executor.Path{"obj", "a"}},
+				{Message: "boom", Path: executor.Path{"obj", "a"}, Extensions: errcode.Extensions(errcode.InternalServerError)},
 			},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
@@ -162,7 +163,7 @@ func TestCompleteValue_NonNull_Propagation_Result(t *testing.T) {
 		wantRes := &executor.ExecutionResult{
 			Data: map[string]any{"obj": nil},
 			Errors: []executor.GraphQLError{
-				{Message: "Cannot return null for non-nullable field obj.a", Path: executor.Path{"obj", "a"}},
+				{Message: "Cannot return null for non-nullable field obj.a", Path: executor.Path{"obj", "a"}, Extensions: errcode.Extensions(errcode.InternalServerError)},
 			},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
@@ -250,7 +251,7 @@ func TestCompleteValue_List_Nullability_Result(t *testing.T) {
 		wantRes := &executor.ExecutionResult{
 			Data: map[string]any{"list": nil},
 			Errors: []executor.GraphQLError{
-				{Message: "Cannot return null for non-nullable field list.[1]", Path: executor.Path{"list", 1}},
+				{Message: "Cannot return null for non-nullable field list.[1]", Path: executor.Path{"list", 1}, Extensions: errcode.Extensions(errcode.InternalServerError)},
 			},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
@@ -301,7 +302,7 @@ func TestCompleteValue_Leaf_Serialization_Result(t *testing.T) {
 
 		wantRes := &executor.ExecutionResult{
 			Data:   map[string]any{"a": nil},
-			Errors: []executor.GraphQLError{{Message: "serialize error", Path: executor.Path{"a"}}},
+			Errors: []executor.GraphQLError{{Message: "serialize error", Path: executor.Path{"a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -375,6 +376,32 @@ func TestCompleteValue_Abstract_ResolveType_Result(t *testing.T) {
 		}
 	})
 
+	t.Run("__typename only selection returns concrete name", func(t *testing.T) {
+		sch := newInterfaceSchema()
+		rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+			"Query.iface": executor.NewMockValueResolver(map[string]any{"val": "A"}),
+		})
+		executor.SetTypeResolver(rt, func(value any) (string, error) { return "Obj", nil })
+		exec := executor.NewExecutor(rt, sch)
+		doc := mustParseQuery(t, "{ iface { __typename kind: __typename ... on Obj { __typename } } }")
+
+		gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+		gotCalls := rt.GetCalls()
+
+		wantRes := &executor.ExecutionResult{
+			Data:   map[string]any{"iface": map[string]any{"__typename": "Obj", "kind": "Obj"}},
+			Errors: []executor.GraphQLError{},
+		}
+		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
+			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+		}
+
+		wantCalls := []executor.Call{{Kind: "sync", ObjectType: "Query", Field: "iface", Source: nil, Args: map[string]any{}, BatchID: 0}}
+		if diff := cmp.Diff(wantCalls, gotCalls); diff != "" {
+			t.Fatalf("Runtime calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+
 	t.Run("ResolveType error", func(t *testing.T) {
 		sch := newInterfaceSchema()
 		rt := executor.NewMockRuntime(map[string]executor.MockResolver{
@@ -389,7 +416,7 @@ func TestCompleteValue_Abstract_ResolveType_Result(t *testing.T) {
 
 		wantRes := &executor.ExecutionResult{
 			Data:   map[string]any{"iface": nil},
-			Errors: []executor.GraphQLError{{Message: "boom", Path: executor.Path{"iface"}}},
+			Errors: []executor.GraphQLError{{Message: "boom", Path: executor.Path{"iface"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -415,7 +442,7 @@ func TestCompleteValue_Abstract_ResolveType_Result(t *testing.T) {
 
 		wantRes := &executor.ExecutionResult{
 			Data:   map[string]any{"iface": nil},
-			Errors: []executor.GraphQLError{{Message: "Abstract type Node must resolve to an Object type at runtime. Got: Unknown", Path: executor.Path{"iface"}}},
+			Errors: []executor.GraphQLError{{Message: "Abstract type Node must resolve to an Object type at runtime. Got: Unknown", Path: executor.Path{"iface"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -490,7 +517,7 @@ func TestCompleteValue_Abstract_ConcreteResolution(t *testing.T) {
 		got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
 		want := &executor.ExecutionResult{
 			Data:   map[string]any{"iface": nil},
-			Errors: []executor.GraphQLError{{Message: "decode failed", Path: executor.Path{"iface"}}},
+			Errors: []executor.GraphQLError{{Message: "decode failed", Path: executor.Path{"iface"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -558,7 +585,7 @@ func TestCompleteValue_Abstract_ConcreteResolution(t *testing.T) {
 		got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
 		want := &executor.ExecutionResult{
 			Data:   map[string]any{"union": nil},
-			Errors: []executor.GraphQLError{{Message: "decode failed", Path: executor.Path{"union"}}},
+			Errors: []executor.GraphQLError{{Message: "decode failed", Path: executor.Path{"union"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
 
@@ -74,7 +75,7 @@ func TestContext_OperationSelection_Result(t *testing.T) {
 		doc := mustParseQuery(t, "fragment F on Query { a }")
 
 		gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
-		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found"}}}
+		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found", Extensions: errcode.Extensions(errcode.ValidationFailed)}}}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
@@ -87,7 +88,7 @@ func TestContext_OperationSelection_Result(t *testing.T) {
 		doc := mustParseQuery(t, "query Foo { a } query Bar { b }")
 
 		gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
-		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found"}}}
+		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found", Extensions: errcode.Extensions(errcode.ValidationFailed)}}}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
@@ -100,7 +101,7 @@ func TestContext_OperationSelection_Result(t *testing.T) {
 		doc := mustParseQuery(t, "query Foo { a } query Bar { b }")
 
 		gotRes := exec.ExecuteRequest(context.Background(), doc, "Baz", nil, nil)
-		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found"}}}
+		wantRes := &ExecutionResult{Data: nil, Errors: []GraphQLError{{Message: "operation not found", Extensions: errcode.Extensions(errcode.ValidationFailed)}}}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
@@ -160,7 +161,7 @@ func TestContext_VariableCoercion_Result(t *testing.T) {
 		doc := mustParseQuery(t, "query($v: Int!){ echo(v:$v) }")
 
 		gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
-		wantRes := &ExecutionResult{Errors: []GraphQLError{{Message: "variable $v of required type Int! was not provided"}}}
+		wantRes := &ExecutionResult{Errors: []GraphQLError{{Message: "variable $v of required type Int! was not provided", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
@@ -173,9 +174,37 @@ func TestContext_VariableCoercion_Result(t *testing.T) {
 		doc := mustParseQuery(t, "query($v: Int!){ echo(v:$v) }")
 
 		gotRes := exec.ExecuteRequest(context.Background(), doc, "", map[string]any{"v": nil}, nil)
-		wantRes := &ExecutionResult{Errors: []GraphQLError{{Message: "variable $v of type Int! cannot be null"}}}
+		wantRes := &ExecutionResult{Errors: []GraphQLError{{Message: "variable $v of type Int! cannot be null", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
 	})
 }
+
+// Pattern: Result comparison
+func TestContext_ResponseExtensions_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("a", "", schema.NamedType("String"))),
+		newScalarType("String"),
+	)
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.a": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			ExtensionsFromContext(ctx).Update("cache", func(prev any) any { return "hit" })
+			return "A", nil
+		},
+	})
+	exec := NewExecutor(rt, sch)
+
+	gotRes := exec.ExecuteRequest(context.Background(), mustParseQuery(t, "{ a }"), "", nil, nil)
+	wantRes := &ExecutionResult{
+		Data:       map[string]any{"a": "A"},
+		Errors:     []GraphQLError{},
+		Extensions: map[string]any{"cache": "hit"},
+	}
+	if diff := cmp.Diff(wantRes, gotRes); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+
+	// Outside of an execution updates are ignored
+	ExtensionsFromContext(context.Background()).Update("cache", func(prev any) any { return "hit" })
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
 
@@ -26,7 +27,7 @@ func TestErrors_LocatedPaths_Result(t *testing.T) {
 
 		wantRes := &ExecutionResult{
 			Data:   map[string]any{"a": nil},
-			Errors: []GraphQLError{{Message: "boom", Path: Path{"a"}}},
+			Errors: []GraphQLError{{Message: "boom", Path: Path{"a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -50,7 +51,7 @@ func TestErrors_LocatedPaths_Result(t *testing.T) {
 
 		wantRes := &ExecutionResult{
 			Data:   map[string]any{"obj": map[string]any{"a": nil}},
-			Errors: []GraphQLError{{Message: "boom", Path: Path{"obj", "a"}}},
+			Errors: []GraphQLError{{Message: "boom", Path: Path{"obj", "a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
@@ -79,10 +80,42 @@ func TestErrors_LocatedPaths_Result(t *testing.T) {
 
 		wantRes := &ExecutionResult{
 			Data:   map[string]any{"objs": []any{map[string]any{"a": "A"}, map[string]any{"a": nil}}},
-			Errors: []GraphQLError{{Message: "boom", Path: Path{"objs", 1, "a"}}},
+			Errors: []GraphQLError{{Message: "boom", Path: Path{"objs", 1, "a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
 		}
 		if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 			t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 		}
 	})
 }
+
+func TestErrors_OptionalFieldDoesNotPropagate(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		t.Run(fmt.Sprintf("async=%v", async), func(t *testing.T) {
+			sch := newSchemaWithQueryType(
+				newObjectType("Query", schema.NewField("obj", "", schema.NonNullType(schema.NamedType("Obj")))),
+				newObjectType("Obj",
+					schema.NewField("a", "", schema.NonNullType(schema.NamedType("String"))).SetAsync(async).SetOptional(true),
+					schema.NewField("b", "", schema.NonNullType(schema.NamedType("String"))),
+				),
+				newScalarType("String"),
+			)
+			rt := NewMockRuntime(map[string]MockResolver{
+				"Query.obj": NewMockValueResolver(map[string]any{}),
+				"Obj.a":     NewMockErrorResolver(fmt.Errorf("boom")),
+				"Obj.b":     NewMockValueResolver("B"),
+			})
+			exec := NewExecutor(rt, sch)
+			doc := mustParseQuery(t, "{ obj { a b } }")
+
+			gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+
+			wantRes := &ExecutionResult{
+				Data:   map[string]any{"obj": map[string]any{"a": nil, "b": "B"}},
+				Errors: []GraphQLError{{Message: "boom", Path: Path{"obj", "a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}},
+			}
+			if diff := cmp.Diff(wantRes, gotRes); diff != "" {
+				t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// describingRuntime reports every Post.author call as batched.
+type describingRuntime struct{ *MockRuntime }
+
+func (describingRuntime) DescribeMethod(objectType, field string) (string, bool, bool) {
+	return "svc." + objectType + "." + field, objectType == "Post", true
+}
+
+func explainSchema() *schema.Schema {
+	return schema.NewSchema("").
+		SetQueryType("Query").
+		AddType(newObjectType(
+			"Query",
+			schema.NewField("posts", "", schema.NonNullType(schema.ListType(schema.NonNullType(schema.NamedType("Post"))))).
+				AddArgument(schema.NewInputValue("first", "", schema.NamedType("Int"))).
+				SetAsync(true),
+			schema.NewField("me", "", schema.NamedType("User")).SetAsync(true),
+		)).
+		AddType(newObjectType(
+			"Post",
+			schema.NewField("title", "", schema.NamedType("String")),
+			schema.NewField("author", "", schema.NamedType("User")).SetAsync(true),
+		)).
+		AddType(newObjectType(
+			"User",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("friends", "", schema.ListType(schema.NamedType("User"))),
+			schema.NewField("avatar", "", schema.NamedType("String")).SetAsync(true),
+		)).
+		AddType(newScalarType("String")).
+		AddType(newScalarType("Int"))
+}
+
+// Pattern: Result comparison
+func TestExplain_Batches_Result(t *testing.T) {
+	rt := NewMockRuntime(nil)
+	exec := NewExecutor(rt, explainSchema())
+	doc := mustParseQuery(t, `query($n: Int) {
+		posts(first: $n) { title author { name friends { avatar } } }
+		me { name }
+		alias: me @skip(if: true) { avatar }
+	}`)
+
+	got, err := exec.Explain(context.Background(), doc, "", map[string]any{"n": 5})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	want := &Explanation{
+		Batches: []ExplainBatch{
+			{Depth: 1, Groups: []ExplainGroup{
+				{ObjectType: "Query", Field: "posts", Paths: []string{"posts"}, EstimatedTasks: 1, EstimatedCalls: 1},
+				{ObjectType: "Query", Field: "me", Paths: []string{"me"}, EstimatedTasks: 1, EstimatedCalls: 1},
+			}},
+			{Depth: 2, Groups: []ExplainGroup{
+				{ObjectType: "Post", Field: "author", Paths: []string{"posts[].author"}, EstimatedTasks: 5, EstimatedCalls: 5},
+			}},
+			{Depth: 3, Groups: []ExplainGroup{
+				{ObjectType: "User", Field: "avatar", Paths: []string{"posts[].author.friends[].avatar"}, EstimatedTasks: 5 * DefaultExplainListSize, EstimatedCalls: 5 * DefaultExplainListSize},
+			}},
+		},
+		EstimatedCalls: 2 + 5 + 5*DefaultExplainListSize,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Explanation mismatch (-want +got):\n%s", diff)
+	}
+	if calls := rt.GetCalls(); len(calls) != 0 {
+		t.Fatalf("Explain must not call the runtime, got %v", calls)
+	}
+}
+
+// Pattern: Result comparison
+func TestExplain_MethodDescriber_Result(t *testing.T) {
+	exec := NewExecutor(describingRuntime{NewMockRuntime(nil)}, explainSchema())
+	doc := mustParseQuery(t, `{ posts(first: 3) { author { name } } }`)
+
+	got, err := exec.Explain(context.Background(), doc, "", nil)
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	want := ExplainGroup{ObjectType: "Post", Field: "author", Paths: []string{"posts[].author"}, Method: "svc.Post.author", Batched: true, EstimatedTasks: 3, EstimatedCalls: 1}
+	if diff := cmp.Diff(want, got.Batches[1].Groups[0]); diff != "" {
+		t.Fatalf("ExplainGroup mismatch (-want +got):\n%s", diff)
+	}
+	if got.EstimatedCalls != 2 {
+		t.Fatalf("EstimatedCalls = %d, want 2", got.EstimatedCalls)
+	}
+
+	if _, err := exec.Explain(context.Background(), doc, "Missing", nil); err == nil {
+		t.Fatalf("expected error for unknown operation")
+	}
+}
@@ -0,0 +1,26 @@
+package executor_test
+
+import (
+	"testing"
+
+	"github.com/hanpama/protograph/internal/executor/executortest"
+)
+
+// Pattern: Invariants (go test -fuzz=FuzzExecutor ./internal/executor)
+func FuzzExecutor(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("protograph"))
+	f.Add([]byte{3, 4, 1, 2, 2, 0, 5, 1, 3, 4, 2, 2, 1, 0, 3, 5, 1, 2, 4, 0, 1, 1, 2, 3, 5, 4, 3, 2, 1, 0})
+	for i := range 32 {
+		seed := make([]byte, 64)
+		for j := range seed {
+			seed[j] = byte(i*31 + j*17)
+		}
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := executortest.Run(data, executortest.DefaultConfig); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
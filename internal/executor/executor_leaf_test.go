@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// leafRuntime completes leaf objects from map sources and records the field
+// lists it was asked for. Sources holding "fallback" are left to the executor.
+type leafRuntime struct {
+	mapRuntime
+	calls [][]LeafField
+}
+
+func (r *leafRuntime) CompleteLeafObject(_ context.Context, objectType string, source any, fields []LeafField) (json.RawMessage, bool) {
+	r.calls = append(r.calls, fields)
+	m := source.(map[string]any)
+	if _, ok := m["fallback"]; ok {
+		return nil, false
+	}
+	out := map[string]any{}
+	for _, f := range fields {
+		if f.Field == "__typename" {
+			out[f.ResponseName] = objectType
+		} else {
+			out[f.ResponseName] = m[f.Field]
+		}
+	}
+	raw, _ := json.Marshal(out)
+	return raw, true
+}
+
+func newLeafTestSchema() *schema.Schema {
+	return newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("items", "", schema.ListType(schema.NamedType("Item")))),
+		newObjectType("Item",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("child", "", schema.NamedType("Item")),
+		),
+		newScalarType("ID"), newScalarType("String"),
+	)
+}
+
+// Pattern: Result comparison
+func TestLeaf_LeafOnlySelection_UsesCompleter_Result(t *testing.T) {
+	rt := &leafRuntime{mapRuntime: mapRuntime{root: map[string]any{"items": []any{
+		map[string]any{"id": "1", "name": "a"},
+		map[string]any{"id": "2", "name": "b", "fallback": true},
+	}}}}
+	exec := NewExecutor(rt, newLeafTestSchema(), WithLeafObjects())
+	doc := mustParseQuery(t, "{ items { name k: id __typename } }")
+
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"items": []any{
+		json.RawMessage(`{"__typename":"Item","k":"1","name":"a"}`),
+		map[string]any{"__typename": "Item", "k": "2", "name": "b"},
+	}}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+	wantFields := []LeafField{
+		{ResponseName: "__typename", Field: "__typename", Type: "String", NonNull: true},
+		{ResponseName: "k", Field: "id", Type: "ID", NonNull: true},
+		{ResponseName: "name", Field: "name", Type: "String"},
+	}
+	if diff := cmp.Diff([][]LeafField{wantFields, wantFields}, rt.calls); diff != "" {
+		t.Fatalf("CompleteLeafObject calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestLeaf_ObjectSelection_SkipsCompleter_Result(t *testing.T) {
+	rt := &leafRuntime{mapRuntime: mapRuntime{root: map[string]any{"items": []any{
+		map[string]any{"id": "1", "child": map[string]any{"id": "2"}},
+	}}}}
+	exec := NewExecutor(rt, newLeafTestSchema(), WithLeafObjects())
+	doc := mustParseQuery(t, "{ items { id child { id } } }")
+
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"items": []any{
+		map[string]any{"id": "1", "child": json.RawMessage(`{"id":"2"}`)},
+	}}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+	if len(rt.calls) != 1 {
+		t.Fatalf("expected only the leaf-only child to use the completer, got %v", rt.calls)
+	}
+}
+
+// Pattern: Result comparison
+func TestLeaf_WithoutOption_IgnoresCompleter_Result(t *testing.T) {
+	rt := &leafRuntime{mapRuntime: mapRuntime{root: map[string]any{"items": []any{map[string]any{"id": "1"}}}}}
+	exec := NewExecutor(rt, newLeafTestSchema())
+	got := exec.ExecuteRequest(context.Background(), mustParseQuery(t, "{ items { id } }"), "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"items": []any{map[string]any{"id": "1"}}}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+	if len(rt.calls) != 0 {
+		t.Fatalf("completer used without WithLeafObjects: %v", rt.calls)
+	}
+}
+
+// Pattern: Result comparison
+func TestLeaf_WithKeyOrder_SelectionOrder_Result(t *testing.T) {
+	rt := &leafRuntime{mapRuntime: mapRuntime{root: map[string]any{"items": []any{map[string]any{"id": "1", "name": "a"}}}}}
+	exec := NewExecutor(rt, newLeafTestSchema(), WithLeafObjects(), WithKeyOrder())
+	exec.ExecuteRequest(context.Background(), mustParseQuery(t, "{ items { name k: id __typename } }"), "", nil, nil)
+	want := []LeafField{
+		{ResponseName: "name", Field: "name", Type: "String"},
+		{ResponseName: "k", Field: "id", Type: "ID", NonNull: true},
+		{ResponseName: "__typename", Field: "__typename", Type: "String", NonNull: true},
+	}
+	if diff := cmp.Diff([][]LeafField{want}, rt.calls); diff != "" {
+		t.Fatalf("CompleteLeafObject calls mismatch (-want +got):\n%s", diff)
+	}
+}
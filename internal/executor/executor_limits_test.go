@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+func newLimitsTestExecutor(limits Limits, opts ...Option) *Executor {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("rows", "", schema.ListType(schema.NamedType("Row"))),
+			schema.NewField("more", "", schema.ListType(schema.NamedType("Row"))).SetAsync(true),
+		),
+		newObjectType("Row", schema.NewField("name", "", schema.NamedType("String"))),
+		newScalarType("String"),
+	)
+	rows := make([]any, 100)
+	for i := range rows {
+		rows[i] = map[string]any{"name": fmt.Sprintf("row-%03d", i)}
+	}
+	return NewExecutor(mapRuntime{root: map[string]any{"rows": rows, "more": rows}}, sch, append(opts, WithLimits(limits))...)
+}
+
+// Pattern: Result comparison
+func TestLimits_Exceeded_Result(t *testing.T) {
+	cases := []struct {
+		name   string
+		limits Limits
+		query  string
+		want   string
+	}{
+		{"list items", Limits{MaxListItems: 150}, "{ rows { name } more { name } }", "execution limit exceeded: more than 150 list items"},
+		{"result nodes", Limits{MaxResultNodes: 100}, "{ rows { name } }", "execution limit exceeded: more than 100 result nodes"},
+		{"response bytes", Limits{MaxResponseBytes: 1000}, "{ rows { name } }", "execution limit exceeded: response larger than 1000 bytes"},
+	}
+	for _, tc := range cases {
+		for _, opts := range [][]Option{nil, {WithParallelCompletion(4)}} {
+			exec := newLimitsTestExecutor(tc.limits, opts...)
+			got := exec.ExecuteRequest(context.Background(), mustParseQuery(t, tc.query), "", nil, nil)
+			want := &ExecutionResult{Errors: []GraphQLError{{Message: tc.want, Extensions: errcode.Extensions(errcode.BadUserInput)}}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("%s: ExecutionResult mismatch (-want +got):\n%s", tc.name, diff)
+			}
+		}
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_WithinLimits_Result(t *testing.T) {
+	doc := mustParseQuery(t, "{ rows { name } more { name } }")
+	want := newLimitsTestExecutor(Limits{}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	exec := newLimitsTestExecutor(Limits{MaxResultNodes: 403, MaxListItems: 200, MaxResponseBytes: 1 << 20})
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_ContextOverride_Result(t *testing.T) {
+	doc := mustParseQuery(t, "{ rows { name } }")
+	exec := newLimitsTestExecutor(Limits{MaxResultNodes: 100})
+
+	got := exec.ExecuteRequest(ContextWithLimits(context.Background(), Limits{MaxResultNodes: 1000}), doc, "", nil, nil)
+	if len(got.Errors) != 0 {
+		t.Fatalf("unexpected errors within the overridden limit: %v", got.Errors)
+	}
+
+	ctx := ContextWithLimits(context.Background(), Limits{MaxListItems: 1000})
+	got = exec.ExecuteRequest(ctx, doc, "", nil, nil)
+	want := &ExecutionResult{Errors: []GraphQLError{{Message: "execution limit exceeded: more than 100 result nodes", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_FragmentCycles_Result(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"self", "{ rows { ...A } } fragment A on Row { name ...A }", `Cannot spread fragment "A" within itself`},
+		{"nested", "{ rows { ...A } } fragment A on Row { name ...B } fragment B on Row { name ...C } fragment C on Row { ...A }", `Cannot spread fragment "A" within itself via "B", "C"`},
+	}
+	exec := newLimitsTestExecutor(Limits{})
+	for _, tc := range cases {
+		got := exec.ExecuteRequest(context.Background(), mustParseQuery(t, tc.query), "", nil, nil)
+		want := &ExecutionResult{Errors: []GraphQLError{{Message: tc.want, Extensions: errcode.Extensions(errcode.ValidationFailed)}}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("%s: ExecutionResult mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_MaxFragmentSpreads_Result(t *testing.T) {
+	// Each fragment spreads the next twice: the operation expands to 1+2+4+8 = 15 spreads
+	const query = `{ rows { ...A } }
+fragment A on Row { ...B x: name ...B }
+fragment B on Row { ...C y: name ...C }
+fragment C on Row { ...D z: name ...D }
+fragment D on Row { name }`
+	doc := mustParseQuery(t, query)
+
+	got := newLimitsTestExecutor(Limits{MaxFragmentSpreads: 15}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if len(got.Errors) != 0 {
+		t.Fatalf("unexpected errors within the limit: %v", got.Errors)
+	}
+
+	got = newLimitsTestExecutor(Limits{MaxFragmentSpreads: 14}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Errors: []GraphQLError{{Message: "execution limit exceeded: more than 14 fragment spreads", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_MaxAliases_Result(t *testing.T) {
+	// F holds two aliases and is spread twice: the operation expands to 1+2*2 = 5 aliases
+	const query = `{ first: rows { ...F } rows { ...F name } }
+fragment F on Row { a: name b: name name: name }`
+	doc := mustParseQuery(t, query)
+
+	got := newLimitsTestExecutor(Limits{MaxAliases: 5}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if len(got.Errors) != 0 {
+		t.Fatalf("unexpected errors within the limit: %v", got.Errors)
+	}
+
+	got = newLimitsTestExecutor(Limits{MaxAliases: 4}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Errors: []GraphQLError{{Message: "execution limit exceeded: more than 4 aliases", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestLimits_MaxRootFields_Result(t *testing.T) {
+	// rows is selected three times but merges into one response name: a, rows and more
+	const query = `{ a: rows { name } rows { name } ... on Query { rows { name } more { name } } ...R }
+fragment R on Query { more { name } }`
+	doc := mustParseQuery(t, query)
+
+	got := newLimitsTestExecutor(Limits{MaxRootFields: 3}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if len(got.Errors) != 0 {
+		t.Fatalf("unexpected errors within the limit: %v", got.Errors)
+	}
+
+	got = newLimitsTestExecutor(Limits{MaxRootFields: 2}).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{Errors: []GraphQLError{{Message: "execution limit exceeded: more than 2 root fields", Extensions: errcode.Extensions(errcode.BadUserInput)}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// failRuntime is mapRuntime whose async field fail returns an error.
+type failRuntime struct{ mapRuntime }
+
+func (r failRuntime) BatchResolveAsync(ctx context.Context, tasks []AsyncResolveTask) []AsyncResolveResult {
+	results := r.mapRuntime.BatchResolveAsync(ctx, tasks)
+	for i, t := range tasks {
+		if t.Field == "fail" {
+			results[i] = AsyncResolveResult{Error: errcode.Wrap(errcode.DownstreamServiceError, errors.New("backend down"))}
+		}
+	}
+	return results
+}
+
+// eventRecorder records observed events with their durations zeroed.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) Observe(_ context.Context, e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := e.(BatchFinish); ok {
+		f.Duration = 0
+		e = f
+	}
+	r.events = append(r.events, e)
+}
+
+// Pattern: Result comparison
+func TestObserver_BatchesErrorsAndPropagation_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("a", "", schema.NamedType("Node")).SetAsync(true),
+			schema.NewField("b", "", schema.NamedType("Node")).SetAsync(true),
+		),
+		newObjectType("Node",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("strict", "", schema.NonNullType(schema.NamedType("String"))),
+			schema.NewField("fail", "", schema.NonNullType(schema.NamedType("String"))).SetAsync(true),
+		),
+		newScalarType("String"),
+	)
+	rt := failRuntime{mapRuntime{root: map[string]any{"a": map[string]any{"name": "n"}, "b": map[string]any{}}}}
+	rec := &eventRecorder{}
+	exec := NewExecutor(rt, sch, WithObserver(rec))
+
+	got := exec.ExecuteRequest(context.Background(), mustParseQuery(t, "{ a { name fail } b { strict } }"), "", nil, nil)
+	strictErr := GraphQLError{Message: "Cannot return null for non-nullable field b.strict", Path: Path{"b", "strict"}, Extensions: errcode.Extensions(errcode.InternalServerError)}
+	failErr := GraphQLError{Message: "backend down", Path: Path{"a", "fail"}, Extensions: errcode.Extensions(errcode.DownstreamServiceError)}
+	if diff := cmp.Diff([]GraphQLError{strictErr, failErr}, got.Errors); diff != "" {
+		t.Fatalf("errors mismatch (-want +got):\n%s", diff)
+	}
+	want := []Event{
+		BatchStart{Batch: 0, Tasks: 2, Groups: []TaskGroup{
+			{ObjectType: "Query", Field: "a", Indices: []int{0}},
+			{ObjectType: "Query", Field: "b", Indices: []int{1}},
+		}},
+		BatchFinish{Batch: 0, Tasks: 2},
+		NonNullPropagation{Path: Path{"b", "strict"}, Nulled: Path{"b"}},
+		FieldError{Error: strictErr},
+		BatchStart{Batch: 1, Tasks: 1, Groups: []TaskGroup{{ObjectType: "Node", Field: "fail", Indices: []int{0}}}},
+		BatchFinish{Batch: 1, Tasks: 1, Errors: 1},
+		NonNullPropagation{Path: Path{"a", "fail"}, Nulled: Path{"a"}},
+		FieldError{Error: failErr},
+	}
+	if diff := cmp.Diff(want, rec.events); diff != "" {
+		t.Fatalf("events mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -2,6 +2,9 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -84,3 +87,110 @@ func TestOrdering_FragmentMerge_DuplicateFields_Result(t *testing.T) {
 		t.Fatalf("Runtime calls mismatch (-want +got):\n%s", diff)
 	}
 }
+
+// orderedJSON encodes v compactly, writing objects through o.
+func orderedJSON(o *KeyOrder, v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		var parts []string
+		for k, item := range o.Fields(v) {
+			parts = append(parts, strconv.Quote(k)+":"+orderedJSON(o, item))
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = orderedJSON(o, item)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func TestOrdering_KeyOrder_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType(
+			"Query",
+			schema.NewField("a", "", schema.NamedType("String")),
+			schema.NewField("b", "", schema.NamedType("String")).SetAsync(true),
+			schema.NewField("c", "", schema.NamedType("String")),
+			schema.NewField("pets", "", schema.ListType(schema.NamedType("Pet"))),
+		),
+		schema.NewType("Pet", schema.TypeKindInterface, "").AddPossibleType("Cat").AddPossibleType("Dog"),
+		newObjectType("Cat",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("lives", "", schema.NamedType("String")),
+		).AddInterface("Pet"),
+		newObjectType("Dog",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("barks", "", schema.NamedType("String")).SetAsync(true),
+		).AddInterface("Pet"),
+		newScalarType("String"),
+	)
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.a":    NewMockValueResolver("A"),
+		"Query.b":    NewMockValueResolver("B"),
+		"Query.c":    NewMockValueResolver("C"),
+		"Query.pets": NewMockValueResolver([]any{map[string]any{"kind": "Dog"}, map[string]any{"kind": "Cat"}}),
+		"Cat.name":   NewMockValueResolver("Tom"),
+		"Cat.lives":  NewMockValueResolver("9"),
+		"Dog.name":   NewMockValueResolver("Rex"),
+		"Dog.barks":  NewMockValueResolver("yes"),
+	})
+	SetTypeResolver(rt, func(value any) (string, error) { return value.(map[string]any)["kind"].(string), nil })
+	doc := mustParseQuery(t, `{
+		c
+		pets { ... on Dog { barks name } ... on Cat { name lives } kind: __typename }
+		a
+		b
+	}`)
+
+	got := NewExecutor(rt, sch, WithKeyOrder()).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := `{"c":"C","pets":[{"barks":"yes","name":"Rex","kind":"Dog"},{"name":"Tom","lives":"9","kind":"Cat"}],"a":"A","b":"B"}`
+	if diff := cmp.Diff(want, orderedJSON(got.KeyOrder, got.Data)); diff != "" {
+		t.Errorf("data (-want +got):\n%s", diff)
+	}
+
+	// Without WithKeyOrder, objects are sorted
+	got = NewExecutor(rt, sch).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if got.KeyOrder != nil {
+		t.Fatalf("KeyOrder = %v", got.KeyOrder)
+	}
+	want = `{"a":"A","b":"B","c":"C","pets":[{"barks":"yes","kind":"Dog","name":"Rex"},{"kind":"Cat","lives":"9","name":"Tom"}]}`
+	if diff := cmp.Diff(want, orderedJSON(got.KeyOrder, got.Data)); diff != "" {
+		t.Errorf("sorted data (-want +got):\n%s", diff)
+	}
+}
+
+func TestOrdering_KeyOrder_ParallelCompletion_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("items", "", schema.ListType(schema.NamedType("Item")))),
+		newObjectType("Item", schema.NewField("detail", "", schema.NamedType("Detail")).SetAsync(true)),
+		newObjectType("Detail",
+			schema.NewField("x", "", schema.NamedType("String")),
+			schema.NewField("y", "", schema.NamedType("String")),
+		),
+		newScalarType("String"),
+	)
+	items := make([]any, 4*minTasksPerWorker)
+	for i := range items {
+		items[i] = map[string]any{}
+	}
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.items": NewMockValueResolver(items),
+		"Item.detail": NewMockValueResolver(map[string]any{}),
+		"Detail.x":    NewMockValueResolver("X"),
+		"Detail.y":    NewMockValueResolver("Y"),
+	})
+	exec := NewExecutor(rt, sch, WithKeyOrder(), WithParallelCompletion(4))
+
+	got, release := exec.ExecuteRequestPooled(context.Background(), mustParseQuery(t, "{ items { detail { y x } } }"), "", nil, nil)
+	defer release()
+	item := `{"detail":{"y":"Y","x":"X"}}`
+	want := `{"items":[` + strings.TrimSuffix(strings.Repeat(item+",", len(items)), ",") + `]}`
+	if diff := cmp.Diff(want, orderedJSON(got.KeyOrder, got.Data)); diff != "" {
+		t.Errorf("data (-want +got):\n%s", diff)
+	}
+}
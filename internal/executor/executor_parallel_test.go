@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// errRuntime is mapRuntime where a source value of type error fails resolution.
+type errRuntime struct{ mapRuntime }
+
+func (r errRuntime) BatchResolveAsync(ctx context.Context, tasks []AsyncResolveTask) []AsyncResolveResult {
+	results := r.mapRuntime.BatchResolveAsync(ctx, tasks)
+	for i := range results {
+		if err, ok := results[i].Value.(error); ok {
+			results[i] = AsyncResolveResult{Error: err}
+		}
+	}
+	return results
+}
+
+// Pattern: Result comparison
+func TestParallel_MatchesSequentialCompletion_Result(t *testing.T) {
+	const n = 300
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("items", "", schema.ListType(schema.NamedType("Item"))).SetAsync(true),
+			schema.NewField("strict", "", schema.NonNullType(schema.ListType(schema.NonNullType(schema.NamedType("Item"))))).SetAsync(true),
+		),
+		newObjectType("Item",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("detail", "", schema.NamedType("Detail")).SetAsync(true),
+			schema.NewField("owner", "", schema.NonNullType(schema.NamedType("Detail"))).SetAsync(true),
+		),
+		newObjectType("Detail",
+			schema.NewField("title", "", schema.NonNullType(schema.NamedType("String"))),
+			schema.NewField("next", "", schema.NamedType("Detail")).SetAsync(true),
+		),
+		newScalarType("ID"), newScalarType("String"),
+	)
+	items := make([]any, n)
+	for i := range items {
+		detail := map[string]any{"title": fmt.Sprint("t", i), "next": map[string]any{"title": "n"}}
+		item := map[string]any{"id": fmt.Sprint(i), "detail": detail, "owner": detail}
+		switch i % 13 {
+		case 3:
+			item["detail"] = errors.New("detail failed")
+		case 5:
+			item["owner"] = nil
+		case 7:
+			item["detail"] = map[string]any{"title": nil}
+		case 11:
+			delete(item, "id")
+		}
+		items[i] = item
+	}
+	strict := []any{map[string]any{"id": "s", "owner": nil}}
+	rt := errRuntime{mapRuntime{root: map[string]any{"items": items, "strict": strict}}}
+	doc := mustParseQuery(t, "{ items { id detail { title next { title } } owner { title } } strict { id owner { title } } }")
+
+	want := NewExecutor(rt, sch).ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if len(want.Errors) == 0 {
+		t.Fatal("expected errors in the sequential result")
+	}
+	for _, workers := range []int{2, 4, 16} {
+		exec := NewExecutor(rt, sch, WithParallelCompletion(workers))
+		got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("workers=%d: ExecutionResult mismatch (-want +got):\n%s", workers, diff)
+		}
+		pooled, release := exec.ExecuteRequestPooled(context.Background(), doc, "", nil, nil)
+		if diff := cmp.Diff(want, pooled); diff != "" {
+			t.Fatalf("workers=%d pooled: ExecutionResult mismatch (-want +got):\n%s", workers, diff)
+		}
+		release()
+	}
+}
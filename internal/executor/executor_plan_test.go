@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Pattern: Result comparison
+func TestPlan_TaskGraph_Result(t *testing.T) {
+	rt := NewMockRuntime(nil)
+	exec := NewExecutor(rt, explainSchema())
+	doc := mustParseQuery(t, `{
+		posts(first: 2) { title author { friends { avatar } } }
+		me { avatar }
+	}`)
+
+	got, err := exec.Plan(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	want := &Plan{Tasks: []PlannedTask{
+		{ID: 1, Parent: 0, Depth: 1, ObjectType: "Query", Field: "posts", Path: "posts", Args: map[string]any{"first": 2}, EstimatedCount: 1},
+		{ID: 4, Parent: 0, Depth: 1, ObjectType: "Query", Field: "me", Path: "me", Args: map[string]any{}, EstimatedCount: 1},
+		{ID: 2, Parent: 1, Depth: 2, ObjectType: "Post", Field: "author", Path: "posts[].author", Args: map[string]any{}, EstimatedCount: 2},
+		{ID: 5, Parent: 4, Depth: 2, ObjectType: "User", Field: "avatar", Path: "me.avatar", Args: map[string]any{}, EstimatedCount: 1},
+		{ID: 3, Parent: 2, Depth: 3, ObjectType: "User", Field: "avatar", Path: "posts[].author.friends[].avatar", Args: map[string]any{}, EstimatedCount: 2 * DefaultExplainListSize},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Plan mismatch (-want +got):\n%s", diff)
+	}
+	if calls := rt.GetCalls(); len(calls) != 0 {
+		t.Fatalf("Plan must not call the runtime, got %v", calls)
+	}
+
+	batches := got.Batches()
+	if len(batches) != 3 || len(batches[0]) != 2 || len(batches[1]) != 2 || len(batches[2]) != 1 {
+		t.Fatalf("unexpected batches %v", batches)
+	}
+}
+
+// Pattern: Error
+func TestPlan_OperationSelection_Error(t *testing.T) {
+	exec := NewExecutor(NewMockRuntime(nil), explainSchema())
+	doc := mustParseQuery(t, `query A { me { name } } query B { posts { title } }`)
+
+	if _, err := exec.Plan(context.Background(), doc, nil); err == nil {
+		t.Fatalf("expected error for ambiguous operation")
+	}
+	got, err := exec.PlanOperation(context.Background(), doc, "B", nil)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(got.Tasks) != 1 || got.Tasks[0].Field != "posts" {
+		t.Fatalf("unexpected plan %v", got.Tasks)
+	}
+}
@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Pattern: Result comparison
+func TestPool_PooledResult_MatchesUnpooled_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("user", "", schema.NamedType("User")),
+			schema.NewField("friends", "", schema.ListType(schema.NamedType("User"))).SetAsync(true),
+		),
+		newObjectType("User", schema.NewField("name", "", schema.NamedType("String"))),
+		newScalarType("String"),
+	)
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.user":    NewMockValueResolver(map[string]any{"name": "a"}),
+		"Query.friends": NewMockValueResolver([]any{map[string]any{"name": "b"}, map[string]any{"name": "c"}}),
+		"User.name": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			return source.(map[string]any)["name"], nil
+		},
+	})
+	exec := NewExecutor(rt, sch)
+	doc := mustParseQuery(t, "{ user { name } friends { name } }")
+
+	want := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	got, release := exec.ExecuteRequestPooled(context.Background(), doc, "", nil, nil)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+	root := got.Data.(map[string]any)
+	release()
+	if len(root) != 0 {
+		t.Fatalf("released root still holds %v", root)
+	}
+
+	again, release := exec.ExecuteRequestPooled(context.Background(), doc, "", nil, nil)
+	defer release()
+	if diff := cmp.Diff(want, again); diff != "" {
+		t.Fatalf("ExecutionResult after reuse mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestPool_PathTrie_Prefixes_Result(t *testing.T) {
+	var trie pathTrie
+	trie.insert(Path{})
+	trie.insert(Path{"a", 1, "b"})
+	trie.insert(Path{"c"})
+	trie.insert(Path{"c", "d"})
+
+	cases := []struct {
+		path Path
+		want bool
+	}{
+		{Path{}, false},
+		{Path{"a"}, false},
+		{Path{"a", 1}, false},
+		{Path{"a", 1, "b"}, true},
+		{Path{"a", 1, "b", 0, "x"}, true},
+		{Path{"a", 2, "b"}, false},
+		{Path{"a", "1", "b"}, false},
+		{Path{"c", "e"}, true},
+		{Path{"d"}, false},
+	}
+	for _, c := range cases {
+		if got := trie.hasPrefixOf(c.path); got != c.want {
+			t.Errorf("hasPrefixOf(%v) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
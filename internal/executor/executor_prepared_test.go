@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+func newPreparedTestExecutor() *Executor {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("items", "", schema.ListType(schema.NamedType("Item")))),
+		newObjectType("Item",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("name", "", schema.NamedType("String")),
+		),
+		newScalarType("ID"), newScalarType("String"), newScalarType("Boolean"),
+	)
+	root := map[string]any{"items": []any{
+		map[string]any{"id": "1", "name": "a"},
+		map[string]any{"id": "2", "name": "b"},
+	}}
+	return NewExecutor(mapRuntime{root: root}, sch)
+}
+
+// Pattern: Result comparison
+func TestPrepared_RepeatedExecutions_MatchExecuteRequest_Result(t *testing.T) {
+	exec := newPreparedTestExecutor()
+	for _, query := range []string{
+		"query Q { items { id ...F } } fragment F on Item { name @include(if: true) }",
+		"query Q($v: Boolean!) { items { id name @include(if: $v) } }",
+	} {
+		doc := mustParseQuery(t, query)
+		op, err := exec.Prepare(doc, "Q")
+		if err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+		for _, v := range []bool{true, false, true} {
+			vars := map[string]any{"v": v}
+			want := exec.ExecuteRequest(context.Background(), doc, "Q", vars, nil)
+			got := exec.ExecutePrepared(context.Background(), op, vars, nil)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("%s with v=%v: ExecutionResult mismatch (-want +got):\n%s", query, v, diff)
+			}
+		}
+	}
+}
+
+// Pattern: Result comparison
+func TestPrepared_ConditionSlots_Result(t *testing.T) {
+	exec := newPreparedTestExecutor()
+	cases := map[string][]string{
+		"{ items { id } }":                  nil,
+		"{ items { id @skip(if: false) } }": nil,
+		"query($v: Boolean!) { items { id @skip(if: $v) name @include(if: $v) } }":                                                {"v"},
+		"query($v: Boolean!, $w: Boolean) { items { ...F } } fragment F on Item { id @include(if: $w) ... @skip(if: $v) { id } }": {"v", "w"},
+	}
+	for query, want := range cases {
+		op, err := exec.Prepare(mustParseQuery(t, query), "")
+		if err != nil {
+			t.Fatalf("prepare %s: %v", query, err)
+		}
+		if diff := cmp.Diff(want, op.conditions); diff != "" || !op.shared {
+			t.Errorf("%s: conditions mismatch (-want +got):\n%s", query, diff)
+		}
+	}
+
+	// One collection per combination of condition values
+	op, err := exec.Prepare(mustParseQuery(t, "query($v: Boolean, $w: Boolean) { items { id @skip(if: $v) name @include(if: $w) } }"), "")
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	for _, vars := range []map[string]any{{"v": true}, {"v": true, "w": nil}, {"v": false}, {}, {"w": true}, {"w": false}} {
+		exec.ExecutePrepared(context.Background(), op, vars, nil)
+	}
+	n := 0
+	op.collections.Range(func(_, _ any) bool { n++; return true })
+	if n != 5 {
+		t.Fatalf("collections = %d, want 5", n)
+	}
+
+	if _, err := exec.Prepare(mustParseQuery(t, "mutation { x }"), ""); err == nil || err.Error() != "root type not found for mutation operation" {
+		t.Fatalf("prepare mutation: %v", err)
+	}
+}
+
+// Pattern: Result comparison
+func TestPrepared_ArgumentSlots_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("echo", "", schema.NamedType("String")).
+				AddArgument(schema.NewInputValue("a", "", schema.NonNullType(schema.NamedType("Int")))).
+				AddArgument(schema.NewInputValue("b", "", schema.NamedType("String"))),
+		),
+		newScalarType("Int"), newScalarType("String"),
+	)
+	rt := NewMockRuntime(map[string]MockResolver{
+		"Query.echo": func(_ context.Context, _ any, args map[string]any) (any, error) {
+			return fmt.Sprint(args["a"], ",", args["b"]), nil
+		},
+	})
+	exec := NewExecutor(rt, sch)
+	for _, query := range []string{
+		`query($a: Int!) { echo(a: $a, b: "lit") }`,
+		`{ echo(a: 1) x: echo(a: 2, b: "s") }`,
+		`query($b: String) { echo(a: "bad", b: $b) }`,
+		`{ echo }`,
+	} {
+		doc := mustParseQuery(t, query)
+		op, err := exec.Prepare(doc, "")
+		if err != nil {
+			t.Fatalf("prepare: %v", err)
+		}
+		for _, vars := range []map[string]any{{"a": 7, "b": "v"}, {"a": "x"}, {"a": 8}} {
+			want := exec.ExecuteRequest(context.Background(), doc, "", vars, nil)
+			got := exec.ExecutePrepared(context.Background(), op, vars, nil)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("%s with %v: ExecutionResult mismatch (-want +got):\n%s", query, vars, diff)
+			}
+		}
+	}
+}
+
+// Pattern: Result comparison
+func TestPrepared_ConcurrentExecutions_Result(t *testing.T) {
+	exec := newPreparedTestExecutor()
+	doc := mustParseQuery(t, "{ items { id name } more: items { id } }")
+	op, err := exec.Prepare(doc, "")
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	want := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, release := exec.ExecutePreparedPooled(context.Background(), op, nil, nil)
+			defer release()
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("ExecutionResult mismatch (-want +got):\n%s", diff)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
 
@@ -56,7 +57,7 @@ func TestMutation_Serial_Evaluation_Order_Result(t *testing.T) {
 	gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
 	gotCalls := rt.GetCalls()
 
-	wantRes := &ExecutionResult{Data: map[string]any{"m1": "1", "m2": nil, "m3": "3"}, Errors: []GraphQLError{{Message: "boom", Path: Path{"m2"}}}}
+	wantRes := &ExecutionResult{Data: map[string]any{"m1": "1", "m2": nil, "m3": "3"}, Errors: []GraphQLError{{Message: "boom", Path: Path{"m2"}, Extensions: errcode.Extensions(errcode.InternalServerError)}}}
 	if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
 
@@ -161,7 +162,7 @@ func TestRuntimeContract_CancellationTimeouts_PartialFailure_CallsAndResult(t *t
 	gotRes := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
 	gotCalls := rt.GetCalls()
 
-	wantRes := &ExecutionResult{Data: map[string]any{"a": nil, "b": "B"}, Errors: []GraphQLError{{Message: "boom", Path: Path{"a"}}}}
+	wantRes := &ExecutionResult{Data: map[string]any{"a": nil, "b": "B"}, Errors: []GraphQLError{{Message: "boom", Path: Path{"a"}, Extensions: errcode.Extensions(errcode.InternalServerError)}}}
 	if diff := cmp.Diff(wantRes, gotRes); diff != "" {
 		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
 	}
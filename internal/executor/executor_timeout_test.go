@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/errcode"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// slowRuntime is mapRuntime whose batches containing field slow wait until the
+// context is done.
+type slowRuntime struct{ mapRuntime }
+
+func (r slowRuntime) BatchResolveAsync(ctx context.Context, tasks []AsyncResolveTask) []AsyncResolveResult {
+	for _, t := range tasks {
+		if t.Field == "slow" {
+			<-ctx.Done()
+		}
+	}
+	return r.mapRuntime.BatchResolveAsync(ctx, tasks)
+}
+
+// Pattern: Result comparison
+func TestBatchTimeout_PartialResult_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("a", "", schema.NamedType("Node")).SetAsync(true),
+			schema.NewField("b", "", schema.NamedType("Node")).SetAsync(true),
+		),
+		newObjectType("Node",
+			schema.NewField("name", "", schema.NamedType("String")),
+			schema.NewField("slow", "", schema.NamedType("String")).SetAsync(true),
+			schema.NewField("strict", "", schema.NonNullType(schema.NamedType("String"))).SetAsync(true),
+		),
+		newScalarType("String"),
+	)
+	node := map[string]any{"name": "n", "slow": "s", "strict": "x"}
+	rt := slowRuntime{mapRuntime{root: map[string]any{"a": node, "b": node}}}
+	exec := NewExecutor(rt, sch, WithBatchTimeout(20*time.Millisecond))
+	doc := mustParseQuery(t, "{ a { name slow } b { name strict } }")
+
+	got := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	want := &ExecutionResult{
+		Data: map[string]any{
+			"a": map[string]any{"name": "n", "slow": nil},
+			"b": nil,
+		},
+		Errors: []GraphQLError{
+			{Message: "batch timed out after 20ms", Path: Path{"a", "slow"}, Extensions: errcode.Extensions(errcode.DownstreamServiceError)},
+			{Message: "batch timed out after 20ms", Path: Path{"b", "strict"}, Extensions: errcode.Extensions(errcode.DownstreamServiceError)},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestBatchTimeout_FastBatches_Result(t *testing.T) {
+	sch := newSchemaWithQueryType(
+		newObjectType("Query", schema.NewField("a", "", schema.NamedType("String")).SetAsync(true)),
+		newScalarType("String"),
+	)
+	exec := NewExecutor(mapRuntime{root: map[string]any{"a": "A"}}, sch, WithBatchTimeout(time.Second))
+	got := exec.ExecuteRequest(context.Background(), mustParseQuery(t, "{ a }"), "", nil, nil)
+	want := &ExecutionResult{Data: map[string]any{"a": "A"}, Errors: []GraphQLError{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,200 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// newTombstoneSchema builds
+//
+//	type Query  { items: ITEMS  viewer: Viewer  viewers: [Viewer] }
+//	type Viewer { items: VIEWER_ITEMS @async }
+//	type Obj    { id: ID!  name: String!  detail: Detail @async }
+//	type Detail { v: String }
+//
+// where ITEMS and VIEWER_ITEMS are lists of Obj.
+func newTombstoneSchema(items, viewerItems *schema.TypeRef) *schema.Schema {
+	return newSchemaWithQueryType(
+		newObjectType("Query",
+			schema.NewField("items", "", items),
+			schema.NewField("viewer", "", schema.NamedType("Viewer")),
+			schema.NewField("viewers", "", schema.ListType(schema.NamedType("Viewer"))),
+		),
+		newObjectType("Viewer", schema.NewField("items", "", viewerItems).SetAsync(true)),
+		newObjectType("Obj",
+			schema.NewField("id", "", schema.NonNullType(schema.NamedType("ID"))),
+			schema.NewField("name", "", schema.NonNullType(schema.NamedType("String"))),
+			schema.NewField("detail", "", schema.NamedType("Detail")).SetAsync(true),
+		),
+		newObjectType("Detail", schema.NewField("v", "", schema.NamedType("String"))),
+		newScalarType("ID"), newScalarType("String"),
+	)
+}
+
+// objs returns items with ids prefix0..n-1; the item at bad has no name.
+func objs(prefix string, n, bad int) []any {
+	out := make([]any, n)
+	for i := range out {
+		obj := map[string]any{"id": fmt.Sprint(prefix, i), "name": "n"}
+		if i == bad {
+			delete(obj, "name")
+		}
+		out[i] = obj
+	}
+	return out
+}
+
+func newTombstoneRuntime(resolvers map[string]MockResolver) *MockRuntime {
+	field := func(name string) MockResolver {
+		return func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(map[string]any)[name], nil
+		}
+	}
+	all := map[string]MockResolver{
+		"Obj.id":   field("id"),
+		"Obj.name": field("name"),
+		"Obj.detail": func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return map[string]any{"v": source.(map[string]any)["id"]}, nil
+		},
+		"Detail.v": field("v"),
+	}
+	for k, r := range resolvers {
+		all[k] = r
+	}
+	return NewMockRuntime(all)
+}
+
+// detailSources lists the ids of the objects whose detail the runtime resolved.
+func detailSources(rt *MockRuntime) []any {
+	var ids []any
+	for _, c := range rt.GetCalls() {
+		if c.Kind == CallKindAsync && c.ObjectType == "Obj" && c.Field == "detail" {
+			ids = append(ids, c.Source.(map[string]any)["id"])
+		}
+	}
+	return ids
+}
+
+// Pattern: Result comparison
+func TestTombstone_SyncNonNullList_PrunesQueuedItemTasks_Result(t *testing.T) {
+	// items: [Obj!]!; item 1 violates name: String! after item 0 queued its detail
+	sch := newTombstoneSchema(schema.NonNullType(schema.ListType(schema.NonNullType(schema.NamedType("Obj")))), schema.ListType(schema.NamedType("Obj")))
+	rt := newTombstoneRuntime(map[string]MockResolver{"Query.items": NewMockValueResolver(objs("o", 3, 1))})
+
+	got := NewExecutor(rt, sch).ExecuteRequest(context.Background(), mustParseQuery(t, "{ items { id detail { v } name } }"), "", nil, nil)
+	want := &ExecutionResult{
+		Data: map[string]any{"items": nil},
+		Errors: []GraphQLError{{
+			Message:    "Cannot return null for non-nullable field items.[1].name",
+			Path:       Path{"items", 1, "name"},
+			Extensions: map[string]any{"code": "INTERNAL_SERVER_ERROR"},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("ExecutionResult mismatch (-want +got):\n%s", diff)
+	}
+	if ids := detailSources(rt); len(ids) != 0 {
+		t.Fatalf("details of a nulled list were resolved: %v", ids)
+	}
+}
+
+// Pattern: Result comparison
+func TestTombstone_SyncNullableItem_PrunesOnlyThatItem_Result(t *testing.T) {
+	// items: [Obj]; only item 1 is nulled, so only its queued detail is dropped
+	sch := newTombstoneSchema(schema.ListType(schema.NamedType("Obj")), schema.ListType(schema.NamedType("Obj")))
+	rt := newTombstoneRuntime(map[string]MockResolver{"Query.items": NewMockValueResolver(objs("o", 3, 1))})
+
+	got := NewExecutor(rt, sch).ExecuteRequest(context.Background(), mustParseQuery(t, "{ items { id detail { v } name } }"), "", nil, nil)
+	wantData := map[string]any{"items": []any{
+		map[string]any{"id": "o0", "detail": map[string]any{"v": "o0"}, "name": "n"},
+		nil,
+		map[string]any{"id": "o2", "detail": map[string]any{"v": "o2"}, "name": "n"},
+	}}
+	if diff := cmp.Diff(wantData, got.Data); diff != "" {
+		t.Fatalf("data mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]any{"o0", "o2"}, detailSources(rt)); diff != "" {
+		t.Fatalf("resolved details mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Pattern: Result comparison
+func TestTombstone_AsyncNonNullList_FailingItem_Result(t *testing.T) {
+	for name, tc := range map[string]struct {
+		items    *schema.TypeRef
+		wantData map[string]any
+	}{
+		// The violation propagates through the Non-Null list to the viewer
+		"[Obj!]!": {
+			items:    schema.NonNullType(schema.ListType(schema.NonNullType(schema.NamedType("Obj")))),
+			wantData: map[string]any{"viewer": nil},
+		},
+		// The nullable list absorbs the violation below the viewer
+		"[Obj!]": {
+			items:    schema.ListType(schema.NonNullType(schema.NamedType("Obj"))),
+			wantData: map[string]any{"viewer": map[string]any{"items": nil}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sch := newTombstoneSchema(schema.ListType(schema.NamedType("Obj")), tc.items)
+			rt := newTombstoneRuntime(map[string]MockResolver{
+				"Query.viewer": NewMockValueResolver(map[string]any{}),
+				"Viewer.items": NewMockValueResolver(objs("o", 3, 1)),
+			})
+
+			got := NewExecutor(rt, sch).ExecuteRequest(context.Background(), mustParseQuery(t, "{ viewer { items { id detail { v } name } } }"), "", nil, nil)
+			if diff := cmp.Diff(tc.wantData, got.Data); diff != "" {
+				t.Fatalf("data mismatch (-want +got):\n%s", diff)
+			}
+			wantErrors := []GraphQLError{{
+				Message:    "Cannot return null for non-nullable field viewer.items.[1].name",
+				Path:       Path{"viewer", "items", 1, "name"},
+				Extensions: map[string]any{"code": "INTERNAL_SERVER_ERROR"},
+			}}
+			if diff := cmp.Diff(wantErrors, got.Errors); diff != "" {
+				t.Fatalf("errors mismatch (-want +got):\n%s", diff)
+			}
+			if ids := detailSources(rt); len(ids) != 0 {
+				t.Fatalf("details of a nulled list were resolved: %v", ids)
+			}
+		})
+	}
+}
+
+// Pattern: Result comparison
+func TestTombstone_ParallelCompletion_PrunesQueuedItemTasks_Result(t *testing.T) {
+	sch := newTombstoneSchema(schema.ListType(schema.NamedType("Obj")), schema.ListType(schema.NonNullType(schema.NamedType("Obj"))))
+	viewers := make([]any, 2*minTasksPerWorker)
+	for i := range viewers {
+		viewers[i] = map[string]any{"id": fmt.Sprint("v", i)}
+	}
+	rt := newTombstoneRuntime(map[string]MockResolver{
+		"Query.viewers": NewMockValueResolver(viewers),
+		"Viewer.items": func(_ context.Context, source any, _ map[string]any) (any, error) {
+			// The items of v0 are nulled by their second item
+			id := source.(map[string]any)["id"].(string)
+			if id == "v0" {
+				return objs(id+".", 2, 1), nil
+			}
+			return objs(id+".", 2, -1), nil
+		},
+	})
+
+	got := NewExecutor(rt, sch, WithParallelCompletion(2)).ExecuteRequest(context.Background(), mustParseQuery(t, "{ viewers { items { id detail { v } name } } }"), "", nil, nil)
+	if items := got.Data.(map[string]any)["viewers"].([]any)[0].(map[string]any)["items"]; items != nil {
+		t.Fatalf("items of v0 = %v, want null", items)
+	}
+	ids := detailSources(rt)
+	if len(ids) != 2*(len(viewers)-1) {
+		t.Fatalf("resolved %d details, want %d", len(ids), 2*(len(viewers)-1))
+	}
+	for _, id := range ids {
+		if id == "v0.0" {
+			t.Fatalf("detail of a nulled list was resolved")
+		}
+	}
+}
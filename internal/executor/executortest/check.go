@@ -0,0 +1,225 @@
+package executortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Run generates a schema and a document from data, executes the document against
+// a synthetic Runtime and checks the result. The returned error describes every
+// violated invariant together with the generated SDL and query.
+func Run(data []byte, cfg Config) error {
+	src := NewSource(data)
+	sch := GenSchema(src, cfg)
+	query := GenDocument(src, sch, cfg)
+	if err := run(sch, query, cfg); err != nil {
+		return fmt.Errorf("%w\n\nschema:\n%s\nquery:\n%s", err, schema.Render(sch), query)
+	}
+	return nil
+}
+
+func run(sch *schema.Schema, query string, cfg Config) error {
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("generated query does not parse: %w", err)
+	}
+	rt := NewRuntime(sch, cfg)
+	exec := executor.NewExecutor(rt, sch)
+	plan, err := exec.Plan(context.Background(), doc, nil)
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+	res := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	return Check(sch, doc, plan, rt, res)
+}
+
+// Check verifies a result produced with rt against these invariants:
+//   - ResolveSync is only called for sync fields and BatchResolveAsync only with
+//     async fields, never with an empty batch.
+//   - The tasks of the n-th batch are planned at depth n.
+//   - Below the root, Non-Null fields and list items are never null; a null
+//     Non-Null root field comes with an error at or below its path.
+//   - Every object holds exactly the response names selected for its type, and
+//     __typename names a possible type of the field's type.
+func Check(sch *schema.Schema, doc *language.QueryDocument, plan *executor.Plan, rt *Runtime, res *executor.ExecutionResult) error {
+	c := &checker{sch: sch, doc: doc, res: res}
+	c.checkCalls(plan, rt)
+	data, ok := res.Data.(map[string]any)
+	if !ok {
+		c.failf("data is %T, not an object", res.Data)
+	} else {
+		c.checkObject(sch.GetQueryType(), doc.Operations[0].SelectionSet, data, nil)
+	}
+	return errors.Join(c.errs...)
+}
+
+type checker struct {
+	sch  *schema.Schema
+	doc  *language.QueryDocument
+	res  *executor.ExecutionResult
+	errs []error
+}
+
+func (c *checker) failf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+func (c *checker) field(objectType, field string) *schema.Field {
+	if t := c.sch.Types[objectType]; t != nil {
+		return t.Fields[field]
+	}
+	return nil
+}
+
+func (c *checker) checkCalls(plan *executor.Plan, rt *Runtime) {
+	for _, call := range rt.SyncCalls() {
+		if f := c.field(call.ObjectType, call.Field); f == nil || f.Async {
+			c.failf("ResolveSync called for async field %s.%s", call.ObjectType, call.Field)
+		}
+	}
+	planned := plan.Batches()
+	for i, batch := range rt.Batches() {
+		if len(batch) == 0 {
+			c.failf("batch %d is empty", i+1)
+		}
+		for _, task := range batch {
+			if f := c.field(task.ObjectType, task.Field); f == nil || !f.Async {
+				c.failf("BatchResolveAsync called for sync field %s.%s", task.ObjectType, task.Field)
+			}
+			if i >= len(planned) || !slices.ContainsFunc(planned[i], func(p executor.PlannedTask) bool {
+				return p.ObjectType == task.ObjectType && p.Field == task.Field
+			}) {
+				c.failf("task %s.%s in batch %d is not planned at that depth", task.ObjectType, task.Field, i+1)
+			}
+		}
+	}
+}
+
+func (c *checker) checkObject(t *schema.Type, selectionSet language.SelectionSet, value map[string]any, path executor.Path) {
+	names, fields := c.collect(t, selectionSet)
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	want := slices.Clone(names)
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		c.failf("object at %v has keys %v, selected %v", path, keys, want)
+		return
+	}
+	for _, name := range names {
+		selected := fields[name]
+		fieldPath := append(slices.Clone(path), name)
+		if selected[0].Name == "__typename" {
+			if value[name] != t.Name {
+				c.failf("__typename at %v is %v, want %s", fieldPath, value[name], t.Name)
+			}
+			continue
+		}
+		var sub language.SelectionSet
+		for _, f := range selected {
+			sub = append(sub, f.SelectionSet...)
+		}
+		c.checkValue(t.Fields[selected[0].Name].Type, sub, value[name], fieldPath, len(path) == 0)
+	}
+}
+
+func (c *checker) checkValue(typ *schema.TypeRef, selectionSet language.SelectionSet, value any, path executor.Path, root bool) {
+	if typ.Kind == schema.TypeRefKindNonNull {
+		if value == nil {
+			if !root {
+				c.failf("Non-Null value at %v is null", path)
+			} else if !c.hasErrorAt(path) {
+				c.failf("Non-Null root field %v is null without an error", path)
+			}
+			return
+		}
+		typ = typ.OfType
+	}
+	if value == nil {
+		return
+	}
+	if typ.Kind == schema.TypeRefKindList {
+		items, ok := value.([]any)
+		if !ok {
+			c.failf("list at %v is %T", path, value)
+			return
+		}
+		for i, item := range items {
+			c.checkValue(typ.OfType, selectionSet, item, append(slices.Clone(path), i), false)
+		}
+		return
+	}
+	t := c.sch.Types[typ.Named]
+	if t.Kind == schema.TypeKindScalar {
+		switch value.(type) {
+		case string, int:
+		default:
+			c.failf("scalar at %v is %T", path, value)
+		}
+		return
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		c.failf("object at %v is %T", path, value)
+		return
+	}
+	if t.Kind != schema.TypeKindObject {
+		name, _ := obj["__typename"].(string)
+		if !slices.Contains(t.PossibleTypes, name) {
+			c.failf("__typename at %v is %q, not a possible type of %s", path, name, t.Name)
+			return
+		}
+		t = c.sch.Types[name]
+	}
+	c.checkObject(t, selectionSet, obj, path)
+}
+
+func (c *checker) hasErrorAt(path executor.Path) bool {
+	for _, e := range c.res.Errors {
+		if len(e.Path) >= len(path) && slices.Equal(e.Path[:len(path)], path) {
+			return true
+		}
+	}
+	return false
+}
+
+// collect mirrors the executor's field collection for generated documents:
+// fragments apply when their type condition names the object type.
+func (c *checker) collect(t *schema.Type, selectionSet language.SelectionSet) ([]string, map[string][]*language.Field) {
+	var names []string
+	fields := map[string][]*language.Field{}
+	var visit func(language.SelectionSet)
+	visit = func(set language.SelectionSet) {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *language.Field:
+				name := sel.Alias
+				if name == "" {
+					name = sel.Name
+				}
+				if _, ok := fields[name]; !ok {
+					names = append(names, name)
+				}
+				fields[name] = append(fields[name], sel)
+			case *language.InlineFragment:
+				if sel.TypeCondition == "" || sel.TypeCondition == t.Name {
+					visit(sel.SelectionSet)
+				}
+			case *language.FragmentSpread:
+				if f := c.doc.Fragments.ForName(sel.Name); f != nil && f.TypeCondition == t.Name {
+					visit(f.SelectionSet)
+				}
+			}
+		}
+	}
+	visit(selectionSet)
+	return names, fields
+}
@@ -0,0 +1,206 @@
+// Package executortest supports property and fuzz testing of the executor. It
+// generates random valid schemas and documents from a byte string, runs them
+// against a synthetic runtime that produces deterministic values, nulls and
+// errors, and checks the result against null propagation and batching
+// invariants:
+//
+//	func FuzzExecutor(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if err := executortest.Run(data, executortest.DefaultConfig); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+package executortest
+
+import (
+	"fmt"
+	"strings"
+
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Config bounds the generated schemas, documents and values.
+type Config struct {
+	MaxObjects    int // object types besides Query
+	MaxFields     int // fields per object type
+	MaxDepth      int // selection set nesting
+	MaxSelections int // fields per selection set
+	MaxListSize   int // items per list value
+	NullEvery     int // one in NullEvery values is null; 0 disables
+	ErrorEvery    int // one in ErrorEvery resolutions fails; 0 disables
+}
+
+// DefaultConfig keeps cases small enough for fast fuzzing.
+var DefaultConfig = Config{
+	MaxObjects:    3,
+	MaxFields:     4,
+	MaxDepth:      3,
+	MaxSelections: 3,
+	MaxListSize:   3,
+	NullEvery:     7,
+	ErrorEvery:    11,
+}
+
+// Source turns fuzz input into choices. Once the input is exhausted every choice
+// is 0, so any input yields a valid, finite case.
+type Source struct {
+	data []byte
+	pos  int
+}
+
+// NewSource returns a Source reading choices from data.
+func NewSource(data []byte) *Source { return &Source{data: data} }
+
+// Intn returns a choice in [0, n).
+func (s *Source) Intn(n int) int {
+	if n <= 1 || s.pos >= len(s.data) {
+		return 0
+	}
+	b := s.data[s.pos]
+	s.pos++
+	return int(b) % n
+}
+
+// Bool returns a choice that is true one time in n.
+func (s *Source) Bool(n int) bool { return s.Intn(n) == n-1 }
+
+// Names of the generated abstract types. Every object type is a member of the
+// union; objects implementing the interface declare its id field.
+const (
+	InterfaceName = "Node"
+	UnionName     = "Any"
+)
+
+// GenSchema generates a schema with a Query type, object types T0..Tn, the
+// interface Node, the union Any and the String and Int scalars. Field types wrap
+// any of these in lists and non-nulls, and fields are randomly async.
+func GenSchema(src *Source, cfg Config) *schema.Schema {
+	sch := schema.NewSchema("").SetQueryType("Query")
+	sch.AddType(schema.NewType("String", schema.TypeKindScalar, ""))
+	sch.AddType(schema.NewType("Int", schema.TypeKindScalar, ""))
+
+	n := 1 + src.Intn(cfg.MaxObjects)
+	objects := make([]string, n)
+	for i := range objects {
+		objects[i] = fmt.Sprintf("T%d", i)
+	}
+	named := append([]string{"String", "Int", InterfaceName, UnionName}, objects...)
+
+	iface := schema.NewType(InterfaceName, schema.TypeKindInterface, "")
+	iface.AddField(schema.NewField("id", "", schema.NonNullType(schema.NamedType("String"))))
+	union := schema.NewType(UnionName, schema.TypeKindUnion, "")
+
+	genFields := func(t *schema.Type) {
+		for i := range 1 + src.Intn(cfg.MaxFields) {
+			f := schema.NewField(fmt.Sprintf("f%d", i), "", genTypeRef(src, named[src.Intn(len(named))]))
+			t.AddField(f.SetAsync(src.Bool(2)))
+		}
+	}
+	for i, name := range objects {
+		t := schema.NewType(name, schema.TypeKindObject, "")
+		if i == 0 || src.Bool(2) {
+			t.AddInterface(InterfaceName)
+			iface.AddPossibleType(name)
+			t.AddField(schema.NewField("id", "", schema.NonNullType(schema.NamedType("String"))).SetAsync(src.Bool(2)))
+		}
+		union.AddPossibleType(name)
+		genFields(t)
+		sch.AddType(t)
+	}
+	sch.AddType(iface)
+	sch.AddType(union)
+
+	query := schema.NewType("Query", schema.TypeKindObject, "")
+	genFields(query)
+	sch.AddType(query)
+	return sch
+}
+
+func genTypeRef(src *Source, name string) *schema.TypeRef {
+	t := schema.NamedType(name)
+	if src.Bool(3) {
+		t = schema.NonNullType(t)
+	}
+	if src.Bool(3) {
+		t = schema.ListType(t)
+		if src.Bool(3) {
+			t = schema.NonNullType(t)
+		}
+	}
+	return t
+}
+
+// GenDocument generates a query operation over sch. Selections use aliases,
+// inline fragments and named fragments; abstract selections always include
+// __typename and one inline fragment per possible type.
+func GenDocument(src *Source, sch *schema.Schema, cfg Config) string {
+	g := &docGen{src: src, sch: sch, cfg: cfg}
+	var b strings.Builder
+	b.WriteString("query Fuzz ")
+	b.WriteString(g.selectionSet(sch.GetQueryType(), 0))
+	for _, f := range g.fragments {
+		b.WriteString("\n")
+		b.WriteString(f)
+	}
+	return b.String()
+}
+
+type docGen struct {
+	src       *Source
+	sch       *schema.Schema
+	cfg       Config
+	fragments []string
+	aliases   int
+}
+
+func (g *docGen) selectionSet(t *schema.Type, depth int) string {
+	if t.Kind != schema.TypeKindObject {
+		return g.abstractSelectionSet(t, depth)
+	}
+	fields := t.GetOrderedFields()
+	var parts []string
+	for range 1 + g.src.Intn(g.cfg.MaxSelections) {
+		f := fields[g.src.Intn(len(fields))]
+		sel := g.field(f, depth)
+		switch g.src.Intn(6) {
+		case 4:
+			sel = "... on " + t.Name + " { " + sel + " }"
+		case 5:
+			name := fmt.Sprintf("F%d", len(g.fragments))
+			g.fragments = append(g.fragments, "fragment "+name+" on "+t.Name+" { "+sel+" }")
+			sel = "..." + name
+		}
+		parts = append(parts, sel)
+	}
+	return "{ " + strings.Join(parts, " ") + " }"
+}
+
+func (g *docGen) abstractSelectionSet(t *schema.Type, depth int) string {
+	parts := []string{"__typename"}
+	if t.Kind == schema.TypeKindInterface && g.src.Bool(2) {
+		parts = append(parts, "id")
+	}
+	for _, name := range t.PossibleTypes {
+		if depth < g.cfg.MaxDepth && g.src.Bool(2) {
+			parts = append(parts, "... on "+name+" "+g.selectionSet(g.sch.Types[name], depth+1))
+		}
+	}
+	return "{ " + strings.Join(parts, " ") + " }"
+}
+
+func (g *docGen) field(f *schema.Field, depth int) string {
+	sel := f.Name
+	if g.src.Bool(4) {
+		g.aliases++
+		sel = fmt.Sprintf("a%d: %s", g.aliases, f.Name)
+	}
+	t := g.sch.Types[schema.GetNamedType(f.Type)]
+	if t.Kind == schema.TypeKindScalar {
+		return sel
+	}
+	if depth >= g.cfg.MaxDepth {
+		return sel + " { __typename }"
+	}
+	return sel + " " + g.selectionSet(t, depth+1)
+}
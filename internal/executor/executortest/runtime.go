@@ -0,0 +1,151 @@
+package executortest
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Object is the value the synthetic runtime produces for object types.
+type Object struct {
+	Type string
+	ID   uint64
+}
+
+// SyncCall records one ResolveSync call.
+type SyncCall struct {
+	ObjectType string
+	Field      string
+}
+
+// Runtime is a synthetic executor.Runtime. Field values are derived from a hash
+// of the parent object, type and field, so runs are deterministic. One in
+// NullEvery values is null, whether or not its type is Non-Null, and one in
+// ErrorEvery resolutions fails. Calls are recorded for invariant checks.
+type Runtime struct {
+	schema *schema.Schema
+	cfg    Config
+
+	mu        sync.Mutex
+	batches   [][]executor.AsyncResolveTask
+	syncCalls []SyncCall
+}
+
+var _ executor.Runtime = (*Runtime)(nil)
+
+// NewRuntime returns a synthetic runtime producing values for sch.
+func NewRuntime(sch *schema.Schema, cfg Config) *Runtime {
+	return &Runtime{schema: sch, cfg: cfg}
+}
+
+// Batches returns the tasks of every BatchResolveAsync call so far.
+func (r *Runtime) Batches() [][]executor.AsyncResolveTask {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]executor.AsyncResolveTask(nil), r.batches...)
+}
+
+// SyncCalls returns every ResolveSync call so far.
+func (r *Runtime) SyncCalls() []SyncCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SyncCall(nil), r.syncCalls...)
+}
+
+func (r *Runtime) ResolveSync(_ context.Context, objectType, field string, source any, _ map[string]any) (any, error) {
+	r.mu.Lock()
+	r.syncCalls = append(r.syncCalls, SyncCall{ObjectType: objectType, Field: field})
+	r.mu.Unlock()
+	return r.resolve(objectType, field, source)
+}
+
+func (r *Runtime) BatchResolveAsync(_ context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]executor.AsyncResolveTask(nil), tasks...))
+	r.mu.Unlock()
+	results := make([]executor.AsyncResolveResult, len(tasks))
+	for i, t := range tasks {
+		results[i].Value, results[i].Error = r.resolve(t.ObjectType, t.Field, t.Source)
+	}
+	return results
+}
+
+func (r *Runtime) ResolveType(_ context.Context, abstractType string, value any) (string, error) {
+	obj, ok := value.(*Object)
+	if !ok {
+		return "", fmt.Errorf("cannot resolve %s from %T", abstractType, value)
+	}
+	return obj.Type, nil
+}
+
+func (r *Runtime) ResolveUnionConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (r *Runtime) ResolveInterfaceConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (r *Runtime) SerializeLeafValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (r *Runtime) resolve(objectType, field string, source any) (any, error) {
+	var parent uint64
+	if obj, ok := source.(*Object); ok {
+		parent = obj.ID
+	}
+	h := hash(parent, objectType, field)
+	if r.cfg.ErrorEvery > 0 && h%uint64(r.cfg.ErrorEvery) == 0 {
+		return nil, fmt.Errorf("synthetic error at %s.%s", objectType, field)
+	}
+	t := r.schema.Types[objectType]
+	if t == nil || t.Fields[field] == nil {
+		return nil, fmt.Errorf("unknown field %s.%s", objectType, field)
+	}
+	return r.value(t.Fields[field].Type, h), nil
+}
+
+func (r *Runtime) value(typ *schema.TypeRef, h uint64) any {
+	if r.cfg.NullEvery > 0 && (h>>16)%uint64(r.cfg.NullEvery) == 0 {
+		return nil
+	}
+	for typ.Kind == schema.TypeRefKindNonNull {
+		typ = typ.OfType
+	}
+	if typ.Kind == schema.TypeRefKindList {
+		items := make([]any, int((h>>8)%uint64(r.cfg.MaxListSize+1)))
+		for i := range items {
+			items[i] = r.value(typ.OfType, hash(h, "item", fmt.Sprint(i)))
+		}
+		return items
+	}
+	t := r.schema.Types[typ.Named]
+	switch t.Kind {
+	case schema.TypeKindObject:
+		return &Object{Type: t.Name, ID: h}
+	case schema.TypeKindInterface, schema.TypeKindUnion:
+		if len(t.PossibleTypes) == 0 {
+			return nil
+		}
+		return &Object{Type: t.PossibleTypes[h%uint64(len(t.PossibleTypes))], ID: h}
+	}
+	if t.Name == "Int" {
+		return int(h % 1000)
+	}
+	return fmt.Sprintf("s%d", h%1000)
+}
+
+func hash(seed uint64, parts ...string) uint64 {
+	f := fnv.New64a()
+	fmt.Fprint(f, seed)
+	for _, p := range parts {
+		f.Write([]byte{0})
+		f.Write([]byte(p))
+	}
+	return f.Sum64()
+}
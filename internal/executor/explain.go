@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// DefaultExplainListSize is the number of items Explain assumes for a list field
+// that has no first, last or limit argument.
+const DefaultExplainListSize = 10
+
+// MethodDescriber is implemented by runtimes that can name the backend call an
+// async field is routed to. Explain uses it to annotate each group.
+type MethodDescriber interface {
+	// DescribeMethod returns the method resolving objectType.field and whether one
+	// call serves every task of a batch. ok is false for unknown fields.
+	DescribeMethod(objectType, field string) (method string, batched bool, ok bool)
+}
+
+// Explanation describes how an operation would be executed: the async groups
+// sent to Runtime.BatchResolveAsync at each depth and the calls they cost.
+type Explanation struct {
+	Batches []ExplainBatch `json:"batches"`
+	// EstimatedCalls is the total of EstimatedCalls over all groups.
+	EstimatedCalls int `json:"estimatedCalls"`
+}
+
+// ExplainBatch is one BatchResolveAsync call.
+type ExplainBatch struct {
+	Depth  int            `json:"depth"`
+	Groups []ExplainGroup `json:"groups"`
+}
+
+// ExplainGroup is the set of tasks for one (type, field) in a batch.
+type ExplainGroup struct {
+	ObjectType string `json:"objectType"`
+	Field      string `json:"field"`
+	// Paths are the response paths producing tasks, with [] for list items.
+	Paths   []string `json:"paths"`
+	Method  string   `json:"method,omitempty"`
+	Batched bool     `json:"batched"`
+	// EstimatedTasks multiplies the sizes of the enclosing lists; see
+	// DefaultExplainListSize. Abstract fields count every possible type.
+	EstimatedTasks int `json:"estimatedTasks"`
+	// EstimatedCalls is 1 for batched methods and EstimatedTasks otherwise.
+	EstimatedCalls int `json:"estimatedCalls"`
+}
+
+// Explain summarizes the Plan of the operation: the batches the executor would
+// send at each depth, grouped by (type, field) and annotated with the backend
+// methods when the runtime implements MethodDescriber.
+func (e *Executor) Explain(
+	ctx context.Context,
+	document *language.QueryDocument,
+	operationName string,
+	variableValues map[string]any,
+) (*Explanation, error) {
+	plan, err := e.PlanOperation(ctx, document, operationName, variableValues)
+	if err != nil {
+		return nil, err
+	}
+	describer, _ := e.runtime.(MethodDescriber)
+
+	out := &Explanation{Batches: []ExplainBatch{}}
+	for _, tasks := range plan.Batches() {
+		batch := ExplainBatch{Depth: len(out.Batches) + 1}
+		index := map[[2]string]int{}
+		for _, t := range tasks {
+			k := [2]string{t.ObjectType, t.Field}
+			i, ok := index[k]
+			if !ok {
+				g := ExplainGroup{ObjectType: t.ObjectType, Field: t.Field}
+				if describer != nil {
+					g.Method, g.Batched, _ = describer.DescribeMethod(t.ObjectType, t.Field)
+				}
+				i = len(batch.Groups)
+				index[k] = i
+				batch.Groups = append(batch.Groups, g)
+			}
+			g := &batch.Groups[i]
+			g.Paths = append(g.Paths, t.Path)
+			g.EstimatedTasks += t.EstimatedCount
+		}
+		for i := range batch.Groups {
+			g := &batch.Groups[i]
+			g.EstimatedCalls = g.EstimatedTasks
+			if g.Batched {
+				g.EstimatedCalls = 1
+			}
+			out.EstimatedCalls += g.EstimatedCalls
+		}
+		out.Batches = append(out.Batches, batch)
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// ResponseExtensions collects the entries a runtime contributes to the
+// extensions map of the response, e.g. cache status. It is safe for concurrent
+// use; updates arriving after the execution finished are dropped.
+type ResponseExtensions struct {
+	mu     sync.Mutex
+	m      map[string]any
+	closed bool
+}
+
+type extensionsKey struct{}
+
+// ContextWithExtensions returns a context through which runtimes add entries to x.
+// Executions install their own; it is exported for testing runtimes in isolation.
+func ContextWithExtensions(ctx context.Context, x *ResponseExtensions) context.Context {
+	return context.WithValue(ctx, extensionsKey{}, x)
+}
+
+// ExtensionsFromContext returns the extensions of the execution running with
+// ctx, or nil outside of an execution. A nil *ResponseExtensions ignores updates.
+func ExtensionsFromContext(ctx context.Context) *ResponseExtensions {
+	x, _ := ctx.Value(extensionsKey{}).(*ResponseExtensions)
+	return x
+}
+
+// Update replaces the entry for key with fn(previous). fn must not mutate the
+// previous value in place: it may already be part of an encoded response.
+func (x *ResponseExtensions) Update(key string, fn func(prev any) any) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.closed {
+		return
+	}
+	if x.m == nil {
+		x.m = make(map[string]any)
+	}
+	x.m[key] = fn(x.m[key])
+}
+
+// Get returns the entry for key.
+func (x *ResponseExtensions) Get(key string) any {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.m[key]
+}
+
+// finish stops accepting updates and returns the collected entries, or nil
+// when there are none.
+func (x *ResponseExtensions) finish() map[string]any {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.closed = true
+	return x.m
+}
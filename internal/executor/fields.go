@@ -10,32 +10,53 @@ import (
 // collectedFieldMap preserves field order from the original query
 type collectedFieldMap struct {
 	fields []collectedField
-	index  map[string]int
+	// index is built once there are more than smallFieldMap response names;
+	// smaller sets are searched linearly without allocating a map.
+	index map[string]int
 }
 
+const smallFieldMap = 8
+
 type collectedField struct {
 	ResponseName string
 	Fields       []*language.Field
 }
 
-func newCollectedFieldMap() *collectedFieldMap {
-	return &collectedFieldMap{
-		fields: make([]collectedField, 0),
-		index:  make(map[string]int),
+func newCollectedFieldMap(sizeHint int) *collectedFieldMap {
+	return &collectedFieldMap{fields: make([]collectedField, 0, sizeHint)}
+}
+
+func (cfm *collectedFieldMap) lookup(responseName string) (int, bool) {
+	if cfm.index != nil {
+		idx, ok := cfm.index[responseName]
+		return idx, ok
 	}
+	for i := range cfm.fields {
+		if cfm.fields[i].ResponseName == responseName {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
 func (cfm *collectedFieldMap) add(responseName string, field *language.Field) {
-	if idx, exists := cfm.index[responseName]; exists {
+	if idx, exists := cfm.lookup(responseName); exists {
 		// Append to existing field group
 		cfm.fields[idx].Fields = append(cfm.fields[idx].Fields, field)
-	} else {
-		// Create new field group
-		cfm.index[responseName] = len(cfm.fields)
-		cfm.fields = append(cfm.fields, collectedField{
-			ResponseName: responseName,
-			Fields:       []*language.Field{field},
-		})
+		return
+	}
+	// Create new field group
+	cfm.fields = append(cfm.fields, collectedField{
+		ResponseName: responseName,
+		Fields:       []*language.Field{field},
+	})
+	if cfm.index != nil {
+		cfm.index[responseName] = len(cfm.fields) - 1
+	} else if len(cfm.fields) > smallFieldMap {
+		cfm.index = make(map[string]int, 2*len(cfm.fields))
+		for i, f := range cfm.fields {
+			cfm.index[f.ResponseName] = i
+		}
 	}
 }
 
@@ -45,16 +66,16 @@ func (cfm *collectedFieldMap) orderedFields() []collectedField {
 
 // collectFields collects fields from a selection set
 func collectFields(state *executionState, objectType *schema.Type, selectionSet language.SelectionSet) *collectedFieldMap {
-	groupedFields := newCollectedFieldMap()
-	visitedFragments := make(map[string]bool)
+	groupedFields := newCollectedFieldMap(len(selectionSet))
+	var visitedFragments map[string]bool
 
-	collectFieldsImpl(state, objectType, selectionSet, groupedFields, visitedFragments)
+	collectFieldsImpl(state, objectType, selectionSet, groupedFields, &visitedFragments)
 
 	return groupedFields
 }
 
 // collectFieldsImpl is the recursive implementation of field collection
-func collectFieldsImpl(state *executionState, objectType *schema.Type, selectionSet language.SelectionSet, groupedFields *collectedFieldMap, visitedFragments map[string]bool) {
+func collectFieldsImpl(state *executionState, objectType *schema.Type, selectionSet language.SelectionSet, groupedFields *collectedFieldMap, visitedFragments *map[string]bool) {
 	for _, selection := range selectionSet {
 		switch sel := selection.(type) {
 		case *language.Field:
@@ -77,8 +98,7 @@ func collectFieldsImpl(state *executionState, objectType *schema.Type, selection
 			}
 
 			// Check type condition
-			if sel.TypeCondition != "" && sel.TypeCondition != objectType.Name {
-				// TODO: Check if objectType implements the interface/union
+			if !fragmentTypeApplies(state.schema, objectType, sel.TypeCondition) {
 				continue
 			}
 
@@ -92,10 +112,13 @@ func collectFieldsImpl(state *executionState, objectType *schema.Type, selection
 			}
 
 			// Check if already visited
-			if visitedFragments[sel.Name] {
+			if (*visitedFragments)[sel.Name] {
 				continue
 			}
-			visitedFragments[sel.Name] = true
+			if *visitedFragments == nil {
+				*visitedFragments = make(map[string]bool)
+			}
+			(*visitedFragments)[sel.Name] = true
 
 			// Get fragment definition from document
 			fragmentDef := getFragmentDefinition(state.document, sel.Name)
@@ -104,8 +127,7 @@ func collectFieldsImpl(state *executionState, objectType *schema.Type, selection
 			}
 
 			// Check type condition
-			if fragmentDef.TypeCondition != "" && fragmentDef.TypeCondition != objectType.Name {
-				// TODO: Check if objectType implements the interface/union
+			if !fragmentTypeApplies(state.schema, objectType, fragmentDef.TypeCondition) {
 				continue
 			}
 
@@ -120,6 +142,27 @@ func collectFieldsImpl(state *executionState, objectType *schema.Type, selection
 	}
 }
 
+// fragmentTypeApplies reports whether a fragment with type condition applies to
+// objectType: the condition is empty or names the type itself, an interface it
+// implements or a union it belongs to.
+func fragmentTypeApplies(s *schema.Schema, objectType *schema.Type, condition string) bool {
+	if condition == "" || condition == objectType.Name {
+		return true
+	}
+	t := s.Types[condition]
+	if t == nil {
+		return false
+	}
+	switch t.Kind {
+	case schema.TypeKindInterface:
+		return containsName(objectType.Interfaces, condition) || containsName(t.PossibleTypes, objectType.Name)
+	case schema.TypeKindUnion:
+		return containsName(t.PossibleTypes, objectType.Name)
+	default:
+		return false
+	}
+}
+
 // shouldIncludeNode checks if a node should be included based on directives
 func shouldIncludeNode(state *executionState, directives language.DirectiveList) bool {
 	// Check @skip directive
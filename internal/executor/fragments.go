@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// checkFragments rejects an operation whose fragment spreads form a cycle, which
+// field collection would expand without end, and, when maxSpreads is set, one
+// expanding more than maxSpreads fragment spreads once every fragment is inlined.
+// Fragments are only checked when the operation reaches them.
+func checkFragments(document *language.QueryDocument, operation *language.OperationDefinition, maxSpreads int64) error {
+	c := &fragmentChecker{
+		document: document,
+		state:    map[string]fragmentState{},
+		spreads:  map[string]int64{},
+		limit:    maxSpreads,
+	}
+	total, err := c.selectionSet(operation.SelectionSet)
+	if err != nil {
+		return err
+	}
+	if maxSpreads > 0 && total > maxSpreads {
+		return errcode.Errorf(errcode.BadUserInput, "execution limit exceeded: more than %d fragment spreads", maxSpreads)
+	}
+	return nil
+}
+
+type fragmentState uint8
+
+const (
+	fragmentVisiting fragmentState = iota + 1
+	fragmentDone
+)
+
+type fragmentChecker struct {
+	document *language.QueryDocument
+	state    map[string]fragmentState
+	path     []string         // fragments being visited, outermost first
+	spreads  map[string]int64 // expanded spreads of each done fragment
+	limit    int64
+}
+
+// selectionSet returns the fragment spreads set expands to. Counts saturate past
+// the limit, so fragments spreading each other many times cannot overflow, and
+// are not kept without one.
+func (c *fragmentChecker) selectionSet(set language.SelectionSet) (int64, error) {
+	var total int64
+	for _, selection := range set {
+		var n int64
+		var err error
+		switch sel := selection.(type) {
+		case *language.Field:
+			n, err = c.selectionSet(sel.SelectionSet)
+		case *language.InlineFragment:
+			n, err = c.selectionSet(sel.SelectionSet)
+		case *language.FragmentSpread:
+			n, err = c.fragment(sel.Name)
+			n++
+		}
+		if err != nil {
+			return 0, err
+		}
+		total = c.add(total, n)
+	}
+	return total, nil
+}
+
+func (c *fragmentChecker) fragment(name string) (int64, error) {
+	switch c.state[name] {
+	case fragmentDone:
+		return c.spreads[name], nil
+	case fragmentVisiting:
+		return 0, c.cycleError(name)
+	}
+	def := getFragmentDefinition(c.document, name)
+	if def == nil {
+		// Unknown fragments are skipped during collection
+		c.state[name] = fragmentDone
+		return 0, nil
+	}
+	c.state[name] = fragmentVisiting
+	c.path = append(c.path, name)
+	n, err := c.selectionSet(def.SelectionSet)
+	if err != nil {
+		return 0, err
+	}
+	c.path = c.path[:len(c.path)-1]
+	c.state[name] = fragmentDone
+	c.spreads[name] = n
+	return n, nil
+}
+
+func (c *fragmentChecker) add(a, b int64) int64 {
+	if c.limit <= 0 {
+		return 0
+	}
+	return min(a+b, c.limit+1)
+}
+
+// cycleError reports the spread of name within itself, naming the fragments it
+// goes through.
+func (c *fragmentChecker) cycleError(name string) error {
+	var via []string
+	for i := len(c.path) - 1; i >= 0 && c.path[i] != name; i-- {
+		via = append([]string{strconv.Quote(c.path[i])}, via...)
+	}
+	if len(via) == 0 {
+		return errcode.Errorf(errcode.ValidationFailed, "Cannot spread fragment %q within itself", name)
+	}
+	return errcode.Errorf(errcode.ValidationFailed, "Cannot spread fragment %q within itself via %s", name, strings.Join(via, ", "))
+}
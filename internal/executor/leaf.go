@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// LeafField is one selected field of a leaf-only object.
+type LeafField struct {
+	ResponseName string
+	// Field is the schema field name, or "__typename" for the meta field, whose
+	// value is the object type name.
+	Field string
+	// Type is the scalar or enum type of the field.
+	Type    string
+	NonNull bool
+}
+
+// LeafObjectCompleter is an optional Runtime capability used when the executor is
+// created with WithLeafObjects. For an object whose selected fields are all sync,
+// argument-free, non-list scalar or enum fields, the executor calls
+// CompleteLeafObject once instead of ResolveSync and SerializeLeafValue per field.
+//
+// The returned JSON object must hold exactly fields, in order, with the values
+// ResolveSync followed by SerializeLeafValue would produce. Returning false falls
+// back to per-field resolution; implementations should do so for any field they
+// cannot serialize and whenever a NonNull field would be null, so the executor
+// reports the error.
+type LeafObjectCompleter interface {
+	CompleteLeafObject(ctx context.Context, objectType string, source any, fields []LeafField) (json.RawMessage, bool)
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithLeafObjects enables the LeafObjectCompleter fast path when the runtime
+// implements it. Completed leaf objects appear in ExecutionResult.Data as
+// json.RawMessage values rather than maps.
+func WithLeafObjects() Option {
+	return func(e *Executor) { e.leafObjects, _ = e.runtime.(LeafObjectCompleter) }
+}
+
+// leafFields returns the fields of a leaf-only collected selection, or nil when
+// the selection does not qualify. Fields are in selection order with
+// WithKeyOrder, and otherwise sorted by response name like encoded maps.
+// Results are cached per collected selection, and shared across executions of
+// a prepared operation.
+func (s *executionState) leafFields(objectType *schema.Type, collected *collectedFieldMap) []LeafField {
+	if cached, ok := s.leaves[collected]; ok {
+		return cached
+	}
+	if s.shared != nil {
+		if cached, ok := s.shared.leaves.Load(collected); ok {
+			s.leaves[collected] = cached.([]LeafField)
+			return cached.([]LeafField)
+		}
+	}
+	leaves := collectLeafFields(s, objectType, collected)
+	if s.shared != nil {
+		s.shared.leaves.Store(collected, leaves)
+	}
+	s.leaves[collected] = leaves
+	return leaves
+}
+
+func collectLeafFields(state *executionState, objectType *schema.Type, collected *collectedFieldMap) []LeafField {
+	ordered := collected.orderedFields()
+	leaves := make([]LeafField, 0, len(ordered))
+	for _, cf := range ordered {
+		name := cf.Fields[0].Name
+		if name == "__typename" {
+			leaves = append(leaves, LeafField{ResponseName: cf.ResponseName, Field: name, Type: "String", NonNull: true})
+			continue
+		}
+		def := getFieldDefinition(objectType, name)
+		if def == nil || def.Async || len(def.Arguments) > 0 {
+			return nil
+		}
+		for _, f := range cf.Fields {
+			if len(f.Arguments) > 0 {
+				return nil
+			}
+		}
+		typ := def.Type
+		nonNull := typ.Kind == schema.TypeRefKindNonNull
+		if nonNull {
+			typ = typ.OfType
+		}
+		if typ.Kind != schema.TypeRefKindNamed {
+			return nil
+		}
+		named := state.schema.Types[typ.Named]
+		if named == nil || (named.Kind != schema.TypeKindScalar && named.Kind != schema.TypeKindEnum) {
+			return nil
+		}
+		leaves = append(leaves, LeafField{ResponseName: cf.ResponseName, Field: name, Type: named.Name, NonNull: nonNull})
+	}
+	if state.order == nil {
+		slices.SortFunc(leaves, func(a, b LeafField) int { return strings.Compare(a.ResponseName, b.ResponseName) })
+	}
+	return leaves
+}
@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hanpama/protograph/internal/errcode"
+)
+
+// Limits bounds the response one execution may build, so that an adversarial
+// query over large backend data fails with an error instead of exhausting the
+// gateway's memory. Zero fields are unlimited.
+type Limits struct {
+	// MaxResultNodes bounds the completed values: objects, lists and leaves.
+	MaxResultNodes int64
+	// MaxListItems bounds the list items materialized across all lists.
+	MaxListItems int64
+	// MaxResponseBytes bounds the estimated size of the compact JSON response.
+	// Leaves count their encoded size and object fields their key.
+	MaxResponseBytes int64
+	// MaxFragmentSpreads bounds the fragment spreads of an operation once every
+	// fragment is inlined, so nested fragments cannot multiply the selections the
+	// executor collects. It is checked before execution starts.
+	MaxFragmentSpreads int64
+	// MaxAliases bounds the aliased fields of an operation once every fragment is
+	// inlined, and MaxRootFields its distinct top-level response names, so that
+	// aliases cannot multiply the tasks of backend batches. Both are checked
+	// before execution starts.
+	MaxAliases    int64
+	MaxRootFields int64
+}
+
+// WithLimits enforces l while completing values. When a limit is exceeded the
+// execution stops before the next batch, and the result has no data and a single
+// error naming the limit.
+func WithLimits(l Limits) Option {
+	return func(e *Executor) { e.limits = l }
+}
+
+type limitsKey struct{}
+
+// ContextWithLimits overrides the response limits of executions running with
+// ctx, e.g. for one operation: the non-zero MaxResultNodes, MaxListItems and
+// MaxResponseBytes of l replace those set by WithLimits. The other limits are
+// checked while preparing operations, which executions share, and are ignored.
+func ContextWithLimits(ctx context.Context, l Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, l)
+}
+
+// executionLimits returns the limits of an execution running with ctx.
+func (e *Executor) executionLimits(ctx context.Context) Limits {
+	l := e.limits
+	o, ok := ctx.Value(limitsKey{}).(Limits)
+	if !ok {
+		return l
+	}
+	if o.MaxResultNodes > 0 {
+		l.MaxResultNodes = o.MaxResultNodes
+	}
+	if o.MaxListItems > 0 {
+		l.MaxListItems = o.MaxListItems
+	}
+	if o.MaxResponseBytes > 0 {
+		l.MaxResponseBytes = o.MaxResponseBytes
+	}
+	return l
+}
+
+// budget tracks the usage of one execution against its Limits. Counters are
+// atomic, as parallel completion workers share the budget. A nil budget is
+// unlimited.
+type budget struct {
+	limits              Limits
+	nodes, items, bytes atomic.Int64
+
+	once     sync.Once
+	exceeded atomic.Pointer[GraphQLError]
+}
+
+func newBudget(l Limits) *budget {
+	if l == (Limits{}) {
+		return nil
+	}
+	return &budget{limits: l}
+}
+
+// charge adds usage and reports whether the execution is still within limits.
+func (b *budget) charge(nodes, items, bytes int64) bool {
+	if b == nil {
+		return true
+	}
+	if b.exceeded.Load() != nil {
+		return false
+	}
+	if n := b.nodes.Add(nodes); b.limits.MaxResultNodes > 0 && n > b.limits.MaxResultNodes {
+		b.exceed(fmt.Sprintf("execution limit exceeded: more than %d result nodes", b.limits.MaxResultNodes))
+		return false
+	}
+	if n := b.items.Add(items); b.limits.MaxListItems > 0 && n > b.limits.MaxListItems {
+		b.exceed(fmt.Sprintf("execution limit exceeded: more than %d list items", b.limits.MaxListItems))
+		return false
+	}
+	if n := b.bytes.Add(bytes); b.limits.MaxResponseBytes > 0 && n > b.limits.MaxResponseBytes {
+		b.exceed(fmt.Sprintf("execution limit exceeded: response larger than %d bytes", b.limits.MaxResponseBytes))
+		return false
+	}
+	return true
+}
+
+func (b *budget) exceed(message string) {
+	b.once.Do(func() {
+		b.exceeded.Store(&GraphQLError{Message: message, Extensions: errcode.Extensions(errcode.BadUserInput)})
+	})
+}
+
+// err returns the error of the first exceeded limit, or nil.
+func (b *budget) err() *GraphQLError {
+	if b == nil {
+		return nil
+	}
+	return b.exceeded.Load()
+}
+
+// leafSize estimates the compact JSON size of a serialized leaf value.
+func leafSize(v any) int64 {
+	switch v := v.(type) {
+	case string:
+		return int64(len(v)) + 2
+	case json.RawMessage:
+		return int64(len(v))
+	case bool:
+		return 5
+	}
+	return 8
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"time"
+)
+
+// Observer receives the events of executions, for metrics, logging and tracing
+// beyond the start and finish of an operation. Observe is called on the
+// executing goroutines, concurrently for concurrent executions and for parallel
+// completion workers, so it must be fast and safe for concurrent use.
+type Observer interface {
+	Observe(ctx context.Context, event Event)
+}
+
+// ObserverFunc adapts a function to Observer.
+type ObserverFunc func(ctx context.Context, event Event)
+
+func (f ObserverFunc) Observe(ctx context.Context, event Event) { f(ctx, event) }
+
+// Event is one of BatchStart, BatchFinish, FieldError and NonNullPropagation.
+type Event interface{ executorEvent() }
+
+// BatchStart is observed before a BatchResolveAsync call. Batch numbers the
+// calls of one execution from 0, one per depth of async fields, and Groups are
+// its tasks by concrete type and field; see GroupTasks.
+type BatchStart struct {
+	Batch  int
+	Tasks  int
+	Groups []TaskGroup
+}
+
+// BatchFinish is observed when a BatchResolveAsync call returns. Errors counts
+// the failed results.
+type BatchFinish struct {
+	Batch    int
+	Tasks    int
+	Errors   int
+	Duration time.Duration
+}
+
+// FieldError is observed for every error of the response, after the root
+// fields and after each batch have been completed.
+type FieldError struct {
+	Error GraphQLError
+}
+
+// NonNullPropagation is observed when a null Non-Null field at Path nulls its
+// parent at Nulled instead, as for async fields whose top-level field is nulled.
+type NonNullPropagation struct {
+	Path   Path
+	Nulled Path
+}
+
+func (BatchStart) executorEvent()         {}
+func (BatchFinish) executorEvent()        {}
+func (FieldError) executorEvent()         {}
+func (NonNullPropagation) executorEvent() {}
+
+// WithObserver reports execution events to o.
+func WithObserver(o Observer) Option {
+	return func(e *Executor) { e.observer = o }
+}
+
+// observe reports event when an observer is installed.
+func (s *executionState) observe(event Event) {
+	if s.observer != nil {
+		s.observer.Observe(s.context, event)
+	}
+}
+
+// observeErrors reports the errors recorded since the last call.
+func (s *executionState) observeErrors() {
+	if s.observer == nil {
+		return
+	}
+	for _, err := range s.errors[s.observedErrors:] {
+		s.observer.Observe(s.context, FieldError{Error: err})
+	}
+	s.observedErrors = len(s.errors)
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// KeyOrder records the selection order of the keys of the response objects of
+// an ExecutionResult. Response objects are maps, which lose the order the spec
+// requires responses to follow, so encoders walk them through Fields.
+type KeyOrder struct {
+	// fields holds the collected fields of each response map, by map pointer.
+	// Holding the pointer keeps the map alive, so no other map reuses it.
+	fields map[unsafe.Pointer][]collectedField
+}
+
+// WithKeyOrder records the selection order of response keys in
+// ExecutionResult.KeyOrder.
+func WithKeyOrder() Option {
+	return func(e *Executor) { e.keyOrder = true }
+}
+
+func newKeyOrder() *KeyOrder {
+	return &KeyOrder{fields: make(map[unsafe.Pointer][]collectedField)}
+}
+
+func (o *KeyOrder) record(obj map[string]any, fields []collectedField) {
+	o.fields[reflect.ValueOf(obj).UnsafePointer()] = fields
+}
+
+// Fields yields the entries of obj in selection order when obj is a response
+// object of the result, and sorted by key otherwise, as encoding/json writes
+// maps. A nil KeyOrder sorts every object.
+func (o *KeyOrder) Fields(obj map[string]any) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		if o != nil {
+			if fields, ok := o.fields[reflect.ValueOf(obj).UnsafePointer()]; ok {
+				for _, f := range fields {
+					// Unknown fields have no entry
+					if v, ok := obj[f.ResponseName]; ok && !yield(f.ResponseName, v) {
+						return
+					}
+				}
+				return
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if !yield(k, obj[k]) {
+				return
+			}
+		}
+	}
+}
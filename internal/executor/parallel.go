@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"maps"
+	"sync"
+
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// minTasksPerWorker keeps small batches on the calling goroutine, where
+// completing them is cheaper than coordinating workers.
+const minTasksPerWorker = 32
+
+// WithParallelCompletion completes the results of one BatchResolveAsync call on
+// up to workers goroutines. Values below each async field are completed
+// independently, then merged in batch order, so results, errors and the tasks of
+// the next batch are the same as with sequential completion.
+//
+// The runtime's ResolveSync, SerializeLeafValue, ResolveType and concrete value
+// methods are then called concurrently and must be safe for concurrent use.
+// Fields below a path nulled by an earlier result of the same batch may still be
+// resolved, although their values and errors are discarded.
+func WithParallelCompletion(workers int) Option {
+	return func(e *Executor) { e.workers = workers }
+}
+
+// parallelWorkers returns the number of goroutines to complete n results on.
+func (s *executionState) parallelWorkers(n int) int {
+	return min(s.workers, n/minTasksPerWorker)
+}
+
+// asyncOutcome is the completion of one async result by a worker. The errors
+// and queued tasks it produced are ranges of the worker's slices.
+type asyncOutcome struct {
+	worker     *executionState
+	value      any
+	propagate  bool
+	errors     [2]int
+	asyncTasks [2]int
+}
+
+// completeAsyncFieldsParallel is completeAsyncField for a whole batch. Workers
+// only complete values; errors, queued tasks, tombstones and response writes are
+// applied afterwards in batch order, skipping results below nulled paths.
+func completeAsyncFieldsParallel(state *executionState, tasks []asyncTask, results []AsyncResolveResult, responseRoot map[string]any) {
+	outcomes := make([]asyncOutcome, len(tasks))
+	n := state.parallelWorkers(len(tasks))
+	chunk := (len(tasks) + n - 1) / n
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		lo, hi := w*chunk, min((w+1)*chunk, len(tasks))
+		ws := state.worker(w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				o := asyncOutcome{worker: ws}
+				o.errors[0], o.asyncTasks[0] = len(ws.errors), len(ws.asyncTaskGroup)
+				o.value, o.propagate = completeAsyncValue(ws, tasks[i], results[i])
+				o.errors[1], o.asyncTasks[1] = len(ws.errors), len(ws.asyncTaskGroup)
+				outcomes[i] = o
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, o := range outcomes {
+		at := tasks[i]
+		if state.nullified.hasPrefixOf(at.ResponsePath) {
+			continue
+		}
+		state.errors = append(state.errors, o.worker.errors[o.errors[0]:o.errors[1]]...)
+		for _, queued := range o.worker.asyncTaskGroup[o.asyncTasks[0]:o.asyncTasks[1]] {
+			// Number tasks as sequential completion would
+			queued.ID = NodeID(state.nextID)
+			state.nextID++
+			state.asyncTaskGroup = append(state.asyncTaskGroup, queued)
+		}
+		writeAsyncValue(state, at, o.value, o.propagate, responseRoot)
+	}
+	for _, ws := range state.workerStates[:n] {
+		state.objects = append(state.objects, ws.objects...)
+		ws.objects = nil
+		// Paths nulled within completed values lie below their own task, so
+		// they only prune the tasks queued for the next batch
+		state.nullified.merge(&ws.nullified)
+		ws.nullified = pathTrie{}
+		if state.order != nil {
+			maps.Copy(state.order.fields, ws.order.fields)
+			clear(ws.order.fields)
+		}
+		ws.asyncTaskGroup = ws.asyncTaskGroup[:0]
+	}
+}
+
+// worker returns the state of the i-th worker, ready for a new batch. Worker
+// states keep their caches across batches. Their errors start with a capped
+// copy of the parent's, so hasErrorAtPath sees earlier errors while appends stay
+// private to the worker.
+func (s *executionState) worker(i int) *executionState {
+	for len(s.workerStates) <= i {
+		ws := &executionState{
+			runtime:        s.runtime,
+			schema:         s.schema,
+			document:       s.document,
+			variableValues: s.variableValues,
+			context:        s.context,
+			pool:           s.pool,
+			subfields:      make(map[subfieldKey]*collectedFieldMap),
+			prepared:       s.prepared,
+			shared:         s.shared,
+			arguments:      make(map[*language.Field]map[string]any),
+			leafObjects:    s.leafObjects,
+			leaves:         make(map[*collectedFieldMap][]LeafField),
+			budget:         s.budget,
+			observer:       s.observer,
+		}
+		if s.order != nil {
+			ws.order = newKeyOrder()
+		}
+		s.workerStates = append(s.workerStates, ws)
+	}
+	ws := s.workerStates[i]
+	ws.errors = s.errors[:len(s.errors):len(s.errors)]
+	return ws
+}
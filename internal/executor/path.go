@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func pathToString(path Path) string {
+	var b strings.Builder
+	for i, elem := range path {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		switch v := elem.(type) {
+		case string:
+			b.WriteString(v)
+		case int:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(v))
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// pathBlock allocates the paths of n siblings in one backing array. Each path is
+// capped at its own length, so appending to one copies instead of overwriting
+// the next.
+type pathBlock struct {
+	buf   []PathElement
+	width int
+}
+
+func newPathBlock(parent Path, n int) pathBlock {
+	width := len(parent) + 1
+	buf := make([]PathElement, width*n)
+	for i := 0; i < n; i++ {
+		copy(buf[i*width:], parent)
+	}
+	return pathBlock{buf: buf, width: width}
+}
+
+func (b pathBlock) at(i int, elem PathElement) Path {
+	p := Path(b.buf[i*b.width : (i+1)*b.width : (i+1)*b.width])
+	p[b.width-1] = elem
+	return p
+}
+
+func pathsEqual(a, b Path) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func topLevelFieldPath(p Path) Path {
+	for _, elem := range p {
+		if name, ok := elem.(string); ok {
+			return Path{name}
+		}
+	}
+	return Path{}
+}
+
+// pathTrie is a set of tombstoned response paths keyed element by element, so
+// prefix checks walk the path once without formatting it.
+type pathTrie struct {
+	children map[PathElement]*pathTrie
+	terminal bool
+}
+
+// insert adds p to the set. The empty path is ignored.
+func (t *pathTrie) insert(p Path) {
+	if len(p) == 0 {
+		return
+	}
+	node := t
+	for _, elem := range p {
+		if node.terminal {
+			// An ancestor is already tombstoned
+			return
+		}
+		next := node.children[elem]
+		if next == nil {
+			if node.children == nil {
+				node.children = make(map[PathElement]*pathTrie)
+			}
+			next = &pathTrie{}
+			node.children[elem] = next
+		}
+		node = next
+	}
+	node.terminal = true
+	node.children = nil
+}
+
+// merge adds the paths of other to the set.
+func (t *pathTrie) merge(other *pathTrie) {
+	if t.terminal {
+		return
+	}
+	if other.terminal {
+		t.terminal = true
+		t.children = nil
+		return
+	}
+	for elem, child := range other.children {
+		next := t.children[elem]
+		if next == nil {
+			if t.children == nil {
+				t.children = make(map[PathElement]*pathTrie)
+			}
+			next = &pathTrie{}
+			t.children[elem] = next
+		}
+		next.merge(child)
+	}
+}
+
+// hasPrefixOf reports whether p or one of its prefixes is in the set.
+func (t *pathTrie) hasPrefixOf(p Path) bool {
+	node := t
+	for _, elem := range p {
+		if node.children == nil {
+			return false
+		}
+		if node = node.children[elem]; node == nil {
+			return false
+		}
+		if node.terminal {
+			return true
+		}
+	}
+	return false
+}
+
+// objectPool recycles response maps for ExecuteRequestPooled.
+var objectPool = sync.Pool{New: func() any { return make(map[string]any, 8) }}
+
+// maxPooledObject keeps unusually wide maps out of the pool, since a cleared map
+// keeps its capacity.
+const maxPooledObject = 64
+
+// newObject returns a response map for size fields.
+func (s *executionState) newObject(size int) map[string]any {
+	if !s.pool || size > maxPooledObject {
+		return make(map[string]any, size)
+	}
+	m := objectPool.Get().(map[string]any)
+	s.objects = append(s.objects, m)
+	return m
+}
+
+func releaseObjects(objects []map[string]any) {
+	for _, m := range objects {
+		clear(m)
+		objectPool.Put(m)
+	}
+}
@@ -0,0 +1,187 @@
+package executor
+
+import (
+	"context"
+
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Plan is the async task graph of an operation: every async field the executor
+// would hand to Runtime.BatchResolveAsync, computed without calling the runtime.
+type Plan struct {
+	// Tasks are ordered by depth, then in the order the executor discovers them.
+	Tasks []PlannedTask
+}
+
+// PlannedTask is an async field selected at one response path. At run time it
+// yields one AsyncResolveTask per parent object reaching that path.
+type PlannedTask struct {
+	ID NodeID
+	// Parent is the ID of the nearest async ancestor, or 0 for root batch tasks.
+	Parent NodeID
+	// Depth is the 1-based index of the BatchResolveAsync call carrying the task.
+	Depth      int
+	ObjectType string
+	Field      string
+	// Path is the response path, with [] for list items.
+	Path string
+	// Args are the coerced field arguments.
+	Args map[string]any
+	// EstimatedCount multiplies the sizes of the enclosing lists (see
+	// DefaultExplainListSize). Tasks below abstract types appear once per
+	// possible type.
+	EstimatedCount int
+}
+
+// Batches groups the tasks by depth; Batches()[i] holds the tasks of depth i+1.
+func (p *Plan) Batches() [][]PlannedTask {
+	var out [][]PlannedTask
+	for _, t := range p.Tasks {
+		for len(out) < t.Depth {
+			out = append(out, nil)
+		}
+		out[t.Depth-1] = append(out[t.Depth-1], t)
+	}
+	return out
+}
+
+// Plan walks the operation of document against the schema and returns its async
+// task graph. Sync fields are expanded in place and never resolved, so neither
+// the runtime nor any backend is called. The document must hold one operation;
+// use PlanOperation to select one by name.
+func (e *Executor) Plan(ctx context.Context, document *language.QueryDocument, variableValues map[string]any) (*Plan, error) {
+	return e.PlanOperation(ctx, document, "", variableValues)
+}
+
+// PlanOperation is Plan for the named operation of document.
+func (e *Executor) PlanOperation(
+	ctx context.Context,
+	document *language.QueryDocument,
+	operationName string,
+	variableValues map[string]any,
+) (*Plan, error) {
+	state, operation, rootType, err := e.prepare(ctx, document, operationName, variableValues)
+	if err != nil {
+		return nil, err
+	}
+	p := &planner{state: state}
+	p.walk(rootType, operation.SelectionSet, 0, 1, 1, "")
+
+	// Stable sort by depth keeps discovery order within a batch
+	plan := &Plan{Tasks: make([]PlannedTask, 0, len(p.tasks))}
+	for depth := 1; len(plan.Tasks) < len(p.tasks); depth++ {
+		for _, t := range p.tasks {
+			if t.Depth == depth {
+				plan.Tasks = append(plan.Tasks, t)
+			}
+		}
+	}
+	return plan, nil
+}
+
+type planner struct {
+	state *executionState
+	tasks []PlannedTask
+}
+
+// walk visits a selection set on objectType. Sync fields expand at the same depth
+// and async fields add a task at depth with their children one level deeper.
+func (p *planner) walk(objectType *schema.Type, selectionSet language.SelectionSet, parent NodeID, depth, count int, path string) {
+	for _, cf := range collectFields(p.state, objectType, selectionSet).orderedFields() {
+		field := cf.Fields[0]
+		fieldDef := getFieldDefinition(objectType, field.Name)
+		if fieldDef == nil {
+			continue
+		}
+		fieldPath := cf.ResponseName
+		if path != "" {
+			fieldPath = path + "." + cf.ResponseName
+		}
+		args := coerceArgumentValues(fieldDef, field.Arguments, p.state.variableValues, p.state, nil)
+		childParent, childDepth := parent, depth
+		if fieldDef.Async {
+			id := NodeID(p.state.nextID)
+			p.state.nextID++
+			p.tasks = append(p.tasks, PlannedTask{
+				ID:             id,
+				Parent:         parent,
+				Depth:          depth,
+				ObjectType:     objectType.Name,
+				Field:          field.Name,
+				Path:           fieldPath,
+				Args:           args,
+				EstimatedCount: count,
+			})
+			childParent, childDepth = id, depth+1
+		}
+		p.walkValue(fieldDef.Type, mergeSelectionSets(cf.Fields), args, childParent, childDepth, count, fieldPath)
+	}
+}
+
+// walkValue descends into the value of a field of type typ.
+func (p *planner) walkValue(typ *schema.TypeRef, selectionSet language.SelectionSet, args map[string]any, parent NodeID, depth, count int, path string) {
+	for typ != nil && typ.Kind != schema.TypeRefKindNamed {
+		if typ.Kind == schema.TypeRefKindList {
+			count *= estimatedListSize(args)
+			path += "[]"
+			args = nil // only the outermost list is sized by the arguments
+		}
+		typ = schema.Unwrap(typ)
+	}
+	if len(selectionSet) == 0 {
+		return
+	}
+	t := p.state.schema.Types[schema.GetNamedType(typ)]
+	if t == nil {
+		return
+	}
+	switch t.Kind {
+	case schema.TypeKindObject:
+		p.walk(t, selectionSet, parent, depth, count, path)
+	case schema.TypeKindInterface, schema.TypeKindUnion:
+		for _, name := range p.possibleTypes(t) {
+			p.walk(p.state.schema.Types[name], selectionSet, parent, depth, count, path)
+		}
+	}
+}
+
+// possibleTypes lists the object types an abstract type can resolve to.
+func (p *planner) possibleTypes(t *schema.Type) []string {
+	if len(t.PossibleTypes) > 0 || t.Kind == schema.TypeKindUnion {
+		return t.PossibleTypes
+	}
+	var names []string
+	for _, name := range p.state.schema.GetOrderedTypeNames() {
+		if o := p.state.schema.Types[name]; o.Kind == schema.TypeKindObject && containsName(o.Interfaces, t.Name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// estimatedListSize estimates the length of a list from its pagination arguments.
+func estimatedListSize(args map[string]any) int {
+	for _, name := range []string{"first", "last", "limit"} {
+		switch v := args[name].(type) {
+		case int:
+			return max(v, 0)
+		case int32:
+			return max(int(v), 0)
+		case int64:
+			return max(int(v), 0)
+		case float64:
+			return max(int(v), 0)
+		}
+	}
+	return DefaultExplainListSize
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package executor
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// PreparedOperation is an operation compiled once and executed many times with
+// different variables. Everything that does not depend on variable values is
+// built on first use and shared by later executions:
+//
+//   - collected selections (field order and merged field groups), and the leaf
+//     field lists derived from them, keyed by the values of the variables that
+//     @skip and @include conditions read (the condition slots);
+//   - argument templates holding the coerced literal arguments of each field,
+//     with slots for arguments given by a variable.
+//
+// Per request, only the variables and the slotted arguments are coerced before
+// execution. A PreparedOperation is safe for concurrent use. The document must
+// not be modified after Prepare.
+type PreparedOperation struct {
+	document  *language.QueryDocument
+	operation *language.OperationDefinition
+	rootType  *schema.Type
+	// rootErr is reported after variable coercion errors, as ExecuteRequest does
+	rootErr error
+	// shared is false for one-off executions and for operations with more than
+	// maxConditionSlots condition variables
+	shared bool
+	// conditions are the variables read by @skip and @include, in name order
+	conditions  []string
+	collections sync.Map // condition key -> *sharedCollection
+	arguments   sync.Map // argKey -> *argTemplate, nil when not templatable
+}
+
+// maxConditionSlots bounds the condition variables of a shared operation; each
+// combination of their values gets its own collection.
+const maxConditionSlots = 8
+
+// sharedCollection holds the collected selections of a prepared operation for
+// one combination of condition values. collectedFieldMaps are read-only once
+// built.
+type sharedCollection struct {
+	root      atomic.Pointer[collectedFieldMap]
+	subfields sync.Map // subfieldKey -> *collectedFieldMap
+	leaves    sync.Map // *collectedFieldMap -> []LeafField
+}
+
+// Operation returns the selected operation definition.
+func (p *PreparedOperation) Operation() *language.OperationDefinition { return p.operation }
+
+// Document returns the document the operation was selected from.
+func (p *PreparedOperation) Document() *language.QueryDocument { return p.document }
+
+// Prepare selects the named operation of document and resolves its root type.
+func (e *Executor) Prepare(document *language.QueryDocument, operationName string) (*PreparedOperation, error) {
+	op, err := e.prepareOperation(document, operationName, true)
+	if err != nil {
+		return nil, err
+	}
+	if op.rootErr != nil {
+		return nil, op.rootErr
+	}
+	return op, nil
+}
+
+// ExecutePrepared is ExecuteRequest for a prepared operation.
+func (e *Executor) ExecutePrepared(ctx context.Context, op *PreparedOperation, variableValues map[string]any, initialValue any) *ExecutionResult {
+	res, _ := e.executePrepared(ctx, op, variableValues, initialValue, false)
+	return res
+}
+
+// ExecutePreparedPooled is ExecuteRequestPooled for a prepared operation.
+func (e *Executor) ExecutePreparedPooled(
+	ctx context.Context,
+	op *PreparedOperation,
+	variableValues map[string]any,
+	initialValue any,
+) (result *ExecutionResult, release func()) {
+	res, state := e.executePrepared(ctx, op, variableValues, initialValue, true)
+	if state == nil || len(state.objects) == 0 {
+		return res, func() {}
+	}
+	objects := state.objects
+	return res, func() { releaseObjects(objects) }
+}
+
+func (e *Executor) prepareOperation(document *language.QueryDocument, operationName string, share bool) (*PreparedOperation, error) {
+	operation := getOperation(document, operationName)
+	if operation == nil {
+		return nil, errcode.Errorf(errcode.ValidationFailed, "operation not found")
+	}
+	if err := checkFragments(document, operation, e.limits.MaxFragmentSpreads); err != nil {
+		return nil, err
+	}
+	if err := checkAliases(document, operation, e.limits); err != nil {
+		return nil, err
+	}
+
+	op := &PreparedOperation{document: document, operation: operation}
+	switch operation.Operation {
+	case language.Query:
+		op.rootType = e.schema.GetQueryType()
+	case language.Mutation:
+		op.rootType = e.schema.GetMutationType()
+	case language.Subscription:
+		op.rootType = e.schema.GetSubscriptionType()
+	default:
+		op.rootErr = errcode.Errorf(errcode.ValidationFailed, "unsupported operation type: %s", operation.Operation)
+		return op, nil
+	}
+	if op.rootType == nil {
+		op.rootErr = errcode.Errorf(errcode.ValidationFailed, "root type not found for %s operation", operation.Operation)
+		return op, nil
+	}
+
+	if share {
+		op.conditions = conditionVariables(document, operation)
+		op.shared = len(op.conditions) <= maxConditionSlots
+	}
+	return op, nil
+}
+
+// collection returns the shared collection for the condition values in
+// variableValues, or nil when op is not shared.
+func (op *PreparedOperation) collection(variableValues map[string]any) *sharedCollection {
+	if !op.shared {
+		return nil
+	}
+	// Two bits per condition: true, false, or neither (absent or null)
+	var key uint32
+	for i, name := range op.conditions {
+		switch variableValues[name] {
+		case true:
+			key |= 1 << (2 * i)
+		case false:
+			key |= 2 << (2 * i)
+		}
+	}
+	if c, ok := op.collections.Load(key); ok {
+		return c.(*sharedCollection)
+	}
+	c, _ := op.collections.LoadOrStore(key, &sharedCollection{})
+	return c.(*sharedCollection)
+}
+
+// conditionVariables returns the variables that @skip and @include directives
+// reachable from operation take their condition from.
+func conditionVariables(document *language.QueryDocument, operation *language.OperationDefinition) []string {
+	var names []string
+	visited := map[string]bool{}
+	var walk func(language.SelectionSet)
+	walk = func(set language.SelectionSet) {
+		for _, selection := range set {
+			switch sel := selection.(type) {
+			case *language.Field:
+				names = appendConditionVariables(names, sel.Directives)
+				walk(sel.SelectionSet)
+			case *language.InlineFragment:
+				names = appendConditionVariables(names, sel.Directives)
+				walk(sel.SelectionSet)
+			case *language.FragmentSpread:
+				names = appendConditionVariables(names, sel.Directives)
+				if visited[sel.Name] {
+					continue
+				}
+				visited[sel.Name] = true
+				if def := getFragmentDefinition(document, sel.Name); def != nil {
+					names = appendConditionVariables(names, def.Directives)
+					walk(def.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(operation.SelectionSet)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func appendConditionVariables(names []string, directives language.DirectiveList) []string {
+	for _, name := range []string{"skip", "include"} {
+		if d := directives.ForName(name); d != nil {
+			for _, arg := range d.Arguments {
+				if arg.Name == "if" && arg.Value != nil && arg.Value.Kind == language.Variable {
+					names = append(names, arg.Value.Raw)
+				}
+			}
+		}
+	}
+	return names
+}
+
+type argKey struct {
+	def  *schema.Field
+	node *language.Field
+}
+
+// argTemplate is the variable-independent part of a field's arguments.
+type argTemplate struct {
+	// constant holds coerced literal arguments and defaults
+	constant map[string]any
+	slots    []argSlot
+}
+
+// argSlot is an argument whose value is a variable.
+type argSlot struct {
+	name  string
+	value *language.Value
+	typ   *schema.TypeRef
+}
+
+// argTemplate returns the template of a field node, compiling it on first use.
+// It returns nil when the literal arguments do not coerce; such fields are
+// coerced per request so that errors are reported at their path.
+func (op *PreparedOperation) argTemplate(sch *schema.Schema, fieldDef *schema.Field, field *language.Field) *argTemplate {
+	key := argKey{def: fieldDef, node: field}
+	if t, ok := op.arguments.Load(key); ok {
+		return t.(*argTemplate)
+	}
+	t := compileArgTemplate(sch, fieldDef, field.Arguments)
+	op.arguments.Store(key, t)
+	return t
+}
+
+func compileArgTemplate(sch *schema.Schema, fieldDef *schema.Field, arguments language.ArgumentList) *argTemplate {
+	t := &argTemplate{constant: make(map[string]any, len(fieldDef.Arguments))}
+	given := make(map[string]bool, len(arguments))
+	for _, arg := range arguments {
+		argDef := fieldDef.Argument(arg.Name)
+		if argDef == nil {
+			continue
+		}
+		if given[arg.Name] {
+			// Repeated arguments are left to coerceArgumentValues
+			return nil
+		}
+		given[arg.Name] = true
+		if arg.Value != nil && arg.Value.Kind == language.Variable {
+			t.slots = append(t.slots, argSlot{name: arg.Name, value: arg.Value, typ: argDef.Type})
+			continue
+		}
+		cv, err := coerceValue(sch, astValueToGo(arg.Value), argDef.Type)
+		if err != nil {
+			return nil
+		}
+		t.constant[arg.Name] = cv
+	}
+	for _, argDef := range fieldDef.GetOrderedArguments() {
+		if given[argDef.Name] {
+			continue
+		}
+		if argDef.DefaultValue != nil {
+			t.constant[argDef.Name] = argDef.DefaultValue
+		} else if schema.IsNonNull(argDef.Type) {
+			return nil
+		}
+	}
+	return t
+}
+
+// instantiate fills the slots of t from the variables of state. The constant map
+// is returned as is when there are no slots. If a slotted value does not coerce,
+// the arguments are coerced from scratch to report the errors.
+func (t *argTemplate) instantiate(state *executionState, fieldDef *schema.Field, field *language.Field, path Path) map[string]any {
+	if len(t.slots) == 0 {
+		return t.constant
+	}
+	args := make(map[string]any, len(t.constant)+len(t.slots))
+	maps.Copy(args, t.constant)
+	for _, slot := range t.slots {
+		cv, err := coerceValue(state.schema, valueFromASTWithVars(slot.value, state.variableValues), slot.typ)
+		if err != nil {
+			return coerceArgumentValues(fieldDef, field.Arguments, state.variableValues, state, path)
+		}
+		args[slot.name] = cv
+	}
+	return args
+}
@@ -1,5 +1,7 @@
 package executor
 
+import "github.com/hanpama/protograph/internal/errcode"
+
 // GraphQLError represents an error that occurred during execution
 type GraphQLError struct {
 	Message    string         `json:"message"`
@@ -11,8 +13,18 @@ func (e GraphQLError) Error() string {
 	return e.Message
 }
 
+// errorFrom converts err into a GraphQLError at path. extensions.code is taken
+// from the errcode carried by err, defaulting to INTERNAL_SERVER_ERROR.
+func errorFrom(err error, path Path) GraphQLError {
+	return GraphQLError{Message: err.Error(), Path: path, Extensions: errcode.Extensions(errcode.Of(err))}
+}
+
 // ExecutionResult represents the result of executing a GraphQL query
 type ExecutionResult struct {
 	Data   any            `json:"data"`
 	Errors []GraphQLError `json:"errors,omitempty"`
+	// Extensions holds the entries runtimes added through ExtensionsFromContext
+	Extensions map[string]any `json:"extensions,omitempty"`
+	// KeyOrder is the key order of Data, set by executors with WithKeyOrder
+	KeyOrder *KeyOrder `json:"-"`
 }
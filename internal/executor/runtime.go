@@ -117,6 +117,33 @@ type AsyncResolveTask struct {
 	Args map[string]any
 }
 
+// TaskGroup is the tasks of a batch resolving one field of one concrete object
+// type.
+type TaskGroup struct {
+	ObjectType string
+	Field      string
+	// Indices are the positions of the tasks in the batch, in batch order.
+	Indices []int
+}
+
+// GroupTasks groups a batch by (ObjectType, Field), in order of first
+// appearance. The tasks of an abstract field take the concrete type of each
+// parent, so the items of a heterogeneous list fall into one group per type.
+func GroupTasks(tasks []AsyncResolveTask) []TaskGroup {
+	var groups []TaskGroup
+	index := make(map[[2]string]int)
+	for i, t := range tasks {
+		k := [2]string{t.ObjectType, t.Field}
+		if g, ok := index[k]; ok {
+			groups[g].Indices = append(groups[g].Indices, i)
+			continue
+		}
+		index[k] = len(groups)
+		groups = append(groups, TaskGroup{ObjectType: t.ObjectType, Field: t.Field, Indices: []int{i}})
+	}
+	return groups
+}
+
 type AsyncResolveResult struct {
 	// Value is the resolved raw value prior to completion, or nil on error.
 	Value any
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/hanpama/protograph/internal/errcode"
+)
+
+// WithBatchTimeout bounds each BatchResolveAsync call to d. When a batch takes
+// longer, its fields fail with a timeout error and become null, or null their
+// top-level field when Non-Null, while data completed by earlier batches is
+// still returned. This serves "best effort within d per depth" responses.
+//
+// The runtime receives a context with the deadline and should stop early; the
+// executor does not wait for a batch past its deadline.
+func WithBatchTimeout(d time.Duration) Option {
+	return func(e *Executor) { e.batchTimeout = d }
+}
+
+// resolveBatch calls BatchResolveAsync, bounded by the batch timeout if set.
+func resolveBatch(state *executionState, tasks []AsyncResolveTask) []AsyncResolveResult {
+	if state.batchTimeout <= 0 {
+		return state.runtime.BatchResolveAsync(state.context, tasks)
+	}
+	ctx, cancel := context.WithTimeout(state.context, state.batchTimeout)
+	defer cancel()
+	done := make(chan []AsyncResolveResult, 1)
+	go func() { done <- state.runtime.BatchResolveAsync(ctx, tasks) }()
+	select {
+	case results := <-done:
+		return results
+	case <-ctx.Done():
+	}
+	err := ctx.Err()
+	if state.context.Err() == nil {
+		err = errcode.Errorf(errcode.DownstreamServiceError, "batch timed out after %s", state.batchTimeout)
+	}
+	results := make([]AsyncResolveResult, len(tasks))
+	for i := range results {
+		results[i].Error = err
+	}
+	return results
+}
@@ -1,11 +1,13 @@
 package executor
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
 
+	"github.com/hanpama/protograph/internal/errcode"
 	language "github.com/hanpama/protograph/internal/language"
 	schema "github.com/hanpama/protograph/internal/schema"
 )
@@ -34,17 +36,17 @@ func coerceVariableValues(
 			if varDef.DefaultValue != nil {
 				val = astValueToGo(varDef.DefaultValue)
 			} else if t.NonNull {
-				return nil, fmt.Errorf("variable $%s of required type %s was not provided", name, t.String())
+				return nil, errcode.Errorf(errcode.BadUserInput, "variable $%s of required type %s was not provided", name, t.String())
 			} else {
 				continue
 			}
 		}
 		if val == nil && t.NonNull {
-			return nil, fmt.Errorf("variable $%s of type %s cannot be null", name, t.String())
+			return nil, errcode.Errorf(errcode.BadUserInput, "variable $%s of type %s cannot be null", name, t.String())
 		}
 		cv, err := coerceValue(schema, val, typeRefFromAST(t))
 		if err != nil {
-			return nil, fmt.Errorf("variable $%s of type %s cannot be coerced: %v", name, t.String(), err)
+			return nil, errcode.Errorf(errcode.BadUserInput, "variable $%s of type %s cannot be coerced: %v", name, t.String(), err)
 		}
 		coerced[name] = cv
 	}
@@ -68,7 +70,7 @@ func coerceArgumentValues(
 		val := valueFromASTWithVars(arg.Value, variableValues)
 		cv, err := coerceValue(state.schema, val, argDef.Type)
 		if err != nil {
-			state.addError(fmt.Sprintf("argument '%s' cannot be coerced: %v", arg.Name, err), path)
+			state.addError(errcode.BadUserInput, fmt.Sprintf("argument '%s' cannot be coerced: %v", arg.Name, err), path)
 			continue
 		}
 		coerced[arg.Name] = cv
@@ -79,7 +81,7 @@ func coerceArgumentValues(
 			if argDef.DefaultValue != nil {
 				coerced[name] = argDef.DefaultValue
 			} else if schema.IsNonNull(argDef.Type) {
-				state.addError(fmt.Sprintf("argument '%s' of required type was not provided", name), path)
+				state.addError(errcode.BadUserInput, fmt.Sprintf("argument '%s' of required type was not provided", name), path)
 			}
 		}
 	}
@@ -187,7 +189,8 @@ func coerceValue(sch *schema.Schema, value any, targetType *schema.TypeRef) (any
 				}
 			}
 		}
-		// For custom scalars and other types, return as-is
+		// For custom scalars and other types, return as-is; a json.Number is
+		// converted by the runtime, which knows the width it needs
 		return value, nil
 	}
 }
@@ -272,6 +275,14 @@ func coerceToInt(value any) (any, error) {
 			return nil, fmt.Errorf("cannot coerce %v (%T) to int", value, value)
 		}
 		return int(v), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), nil
+		}
+		// Such as 1e3, within the integers float64 holds exactly
+		if f, err := v.Float64(); err == nil && isIntegralFloat64(f) && math.Abs(f) <= 1<<53 {
+			return int(f), nil
+		}
 	}
 	return nil, fmt.Errorf("cannot coerce %v (%T) to int", value, value)
 }
@@ -288,6 +299,10 @@ func coerceToFloat(value any) (any, error) {
 		return float64(v), nil
 	case int64:
 		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
 	}
 	return nil, fmt.Errorf("cannot coerce %v (%T) to float", value, value)
 }
@@ -316,6 +331,11 @@ func coerceToID(value any) (any, error) {
 		return strconv.FormatInt(int64(v), 10), nil
 	case int64:
 		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		// Integers keep their digits, even beyond the precision of float64
+		if isIntegerLiteral(string(v)) {
+			return string(v), nil
+		}
 	}
 	return nil, fmt.Errorf("cannot coerce %v (%T) to ID", value, value)
 }
@@ -323,3 +343,17 @@ func coerceToID(value any) (any, error) {
 func isIntegralFloat64(v float64) bool {
 	return math.Trunc(v) == v
 }
+
+// isIntegerLiteral reports whether s is an integer in decimal notation.
+func isIntegerLiteral(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
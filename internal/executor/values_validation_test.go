@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -56,3 +57,43 @@ func TestCoerceVariableValues_ScalarTypeMismatch(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "cannot coerce")
 }
+
+func TestCoerceVariableValues_JSONNumber(t *testing.T) {
+	sch := schema.NewSchema("")
+	sch.AddType(schema.NewType("Long", schema.TypeKindScalar, ""))
+
+	variable := func(name, typ string) *ast.VariableDefinition {
+		return &ast.VariableDefinition{Variable: name, Type: &ast.Type{NamedType: typ}}
+	}
+	op := &language.OperationDefinition{
+		Operation: language.Query,
+		VariableDefinitions: ast.VariableDefinitionList{
+			variable("count", "Int"),
+			variable("exp", "Int"),
+			variable("ratio", "Float"),
+			variable("id", "ID"),
+			variable("long", "Long"),
+		},
+	}
+
+	coerced, err := coerceVariableValues(sch, op, map[string]any{
+		"count": json.Number("42"),
+		"exp":   json.Number("1e3"),
+		"ratio": json.Number("0.5"),
+		"id":    json.Number("9007199254740993"),
+		"long":  json.Number("9007199254740993"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"count": 42,
+		"exp":   1000,
+		"ratio": 0.5,
+		"id":    "9007199254740993",
+		"long":  json.Number("9007199254740993"),
+	}, coerced)
+
+	for name, value := range map[string]json.Number{"count": "1.5", "id": "1.5"} {
+		_, err := coerceVariableValues(sch, op, map[string]any{name: value})
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,431 @@
+// Package gateway builds the serving stack of a GraphQL project: it loads the
+// project, connects the gRPC runtime to the backends and wraps it in the HTTP
+// handler, as the serve command and embedding programs do.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hanpama/protograph/internal/buildcache"
+	"github.com/hanpama/protograph/internal/cache"
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/grpcrt"
+	"github.com/hanpama/protograph/internal/grpctp"
+	"github.com/hanpama/protograph/internal/introspection"
+	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/protoreg"
+	"github.com/hanpama/protograph/internal/replay"
+	"github.com/hanpama/protograph/internal/schema"
+	"github.com/hanpama/protograph/internal/server"
+	"github.com/hanpama/protograph/protographctx"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Config configures a gateway. Zero values leave the feature they configure
+// disabled.
+type Config struct {
+	// Root is the directory of the GraphQL project and RootPackage the package
+	// of its root files.
+	Root        string
+	RootPackage string
+	// Load selects the SDL files of the project.
+	Load ir.LoadOptions
+	// Discovery, when set, lists the SDL files of the project instead of Root
+	// and Load, e.g. from an embedded file system or an archive.
+	Discovery ir.Discovery
+	// CacheDir keeps the compiled project and reuses it while no SDL file
+	// changes.
+	CacheDir string
+
+	// Backends maps gRPC services, or "*" for all of them, to their endpoints.
+	Backends  map[string][]string
+	Transport TransportConfig
+	// Stubs serves placeholders for the fields of services without endpoints
+	// instead of failing.
+	Stubs bool
+	// Lenient reports registry misconfigurations as field errors instead of
+	// panicking.
+	Lenient bool
+	// ProtoDefaults serves unset proto3 scalar fields as their default rather
+	// than null; see grpcrt.WithProtoDefaults.
+	ProtoDefaults bool
+	// MethodQuotas cap the calls in flight of backend methods; see
+	// grpcrt.WithMethodQuotas.
+	MethodQuotas []grpcrt.MethodQuota
+
+	// FieldCacheSize keeps that many results of @cache fields in memory.
+	FieldCacheSize int
+	// Redis, when its Addr is set, keeps @cache field results and the cached
+	// responses of Operations in Redis instead.
+	Redis            cache.RedisOptions
+	CoalesceWindow   time.Duration
+	CoalesceMaxBatch int
+	// Record receives the runtime interactions of every request; Replay serves
+	// recorded interactions instead of the backends.
+	Record io.Writer
+	Replay io.Reader
+
+	Introspection        bool
+	IntrospectionOptions []introspection.Option
+
+	Operations    *server.OperationManifest
+	ServerOptions []server.Option
+}
+
+// Gateway is a built serving stack.
+type Gateway struct {
+	Handler *server.Handler
+	// Schema is the schema served, with the introspection types when enabled.
+	Schema *schema.Schema
+
+	redis *cache.Redis
+	// transport, grpcRuntime, fieldCache and operationCache are reported by
+	// Stats when in use
+	transport      *grpctp.Transport
+	grpcRuntime    *grpcrt.Runtime
+	fieldCache     *cache.Counting
+	operationCache *cache.Counting
+	schemaHash     string
+	loadedAt       time.Time
+}
+
+// New loads the project of cfg and builds its serving stack.
+func New(cfg Config) (*Gateway, error) {
+	if cfg.RootPackage == "" {
+		return nil, fmt.Errorf("root package is required")
+	}
+	proj, reg, err := loadRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sch, err := schema.BuildFromIR(proj)
+	if err != nil {
+		return nil, fmt.Errorf("build schema: %w", err)
+	}
+	if err := grpcrt.Verify(reg, sch); err != nil {
+		return nil, fmt.Errorf("verify registry: %w", err)
+	}
+
+	g := &Gateway{schemaHash: schemaHash(sch), loadedAt: time.Now()}
+	if cfg.Redis.Addr != "" {
+		g.redis = cache.NewRedis(cfg.Redis)
+		if err := g.redis.Ping(context.Background()); err != nil {
+			g.Close()
+			return nil, fmt.Errorf("redis %s: %w", cfg.Redis.Addr, err)
+		}
+	}
+	if err := g.build(cfg, reg, sch); err != nil {
+		g.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *Gateway) build(cfg Config, reg *protoreg.Registry, sch *schema.Schema) error {
+	runtime, err := g.runtime(cfg, reg, sch)
+	if err != nil {
+		return err
+	}
+
+	var sopts []server.Option
+	if cfg.Introspection {
+		wrapper, err := introspection.Wrap(runtime, sch, cfg.IntrospectionOptions...)
+		if err != nil {
+			return err
+		}
+		runtime = wrapper.Runtime
+		sch = wrapper.Schema
+		sopts = append(sopts, server.WithResponseCache(wrapper.Responses))
+	}
+	sopts = append(sopts, cfg.ServerOptions...)
+	if cfg.Operations != nil {
+		sopts = append(sopts, server.WithOperations(cfg.Operations))
+		if g.redis != nil {
+			g.operationCache = cache.NewCounting(g.redis)
+			sopts = append(sopts, server.WithOperationCache(g.operationCache))
+		}
+	}
+	h, err := server.New(runtime, sch, sopts...)
+	if err != nil {
+		return fmt.Errorf("server init: %w", err)
+	}
+	g.Handler, g.Schema = h, sch
+	return nil
+}
+
+func (g *Gateway) runtime(cfg Config, reg *protoreg.Registry, sch *schema.Schema) (executor.Runtime, error) {
+	if cfg.Replay != nil {
+		return replay.NewReplayer(cfg.Replay)
+	}
+	opts := []grpcrt.Option{grpcrt.WithPrecompiledRequests(sch)}
+	if g.redis != nil {
+		g.fieldCache = cache.NewCounting(g.redis)
+	} else if cfg.FieldCacheSize > 0 {
+		g.fieldCache = cache.NewCounting(cache.NewMemory(cfg.FieldCacheSize))
+	}
+	if g.fieldCache != nil {
+		opts = append(opts, grpcrt.WithFieldCache(g.fieldCache))
+	}
+	if cfg.CoalesceWindow > 0 {
+		opts = append(opts, grpcrt.WithCoalescing(cfg.CoalesceWindow, cfg.CoalesceMaxBatch))
+	}
+	if cfg.Lenient {
+		opts = append(opts, grpcrt.WithStrict(false))
+	}
+	if cfg.ProtoDefaults {
+		opts = append(opts, grpcrt.WithProtoDefaults())
+	}
+	if len(cfg.MethodQuotas) > 0 {
+		opts = append(opts, grpcrt.WithMethodQuotas(cfg.MethodQuotas...))
+	}
+	var stubs *schema.Schema
+	if cfg.Stubs {
+		stubs = sch
+	}
+	runtime, transport, err := backendRuntime(reg, cfg.Backends, cfg.Transport, stubs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	g.transport = transport
+	g.grpcRuntime, _ = runtime.(*grpcrt.Runtime)
+	if cfg.Record != nil {
+		runtime = replay.NewRecorder(runtime, cfg.Record)
+	}
+	return runtime, nil
+}
+
+// Close releases the connections of the gateway other than those to the
+// backends.
+func (g *Gateway) Close() error {
+	if g.redis != nil {
+		return g.redis.Close()
+	}
+	return nil
+}
+
+// loadRegistry loads the project and builds its proto registry, through the
+// build cache in cfg.CacheDir if set.
+func loadRegistry(cfg Config) (*ir.Project, *protoreg.Registry, error) {
+	disc := cfg.Discovery
+	if disc == nil {
+		fsd, err := ir.NewFileSystemDiscoveryWithOptions(context.Background(), cfg.Root, cfg.RootPackage, cfg.Load)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load project: %w", err)
+		}
+		disc = fsd
+	}
+	if cfg.CacheDir != "" {
+		bc, err := buildcache.New(cfg.CacheDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		proj, reg, err := bc.LoadDiscovery(disc, cfg.RootPackage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load project: %w", err)
+		}
+		return proj, reg, nil
+	}
+	proj, err := ir.Build(context.Background(), disc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load project: %w", err)
+	}
+	reg, err := protoreg.Build(proj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("protoreg build: %w", err)
+	}
+	return proj, reg, nil
+}
+
+// TransportConfig configures the connections to the backends.
+type TransportConfig struct {
+	Options []grpctp.Option
+	// warmUp pre-dials every endpoint, waiting up to that long; endpoints not
+	// ready by then are only logged, as backends may come up after the gateway.
+	WarmUp time.Duration
+	// mirror maps services to shadow endpoints receiving mirrorPercent of their calls.
+	Mirror        map[string][]string
+	MirrorPercent float64
+	// faults are injected into the calls to the backends, not the shadows.
+	Faults []grpctp.Fault
+	// tenants maps tenants to the -transport.backend style mappings routing the
+	// calls of their requests ahead of the shared ones.
+	Tenants map[string]map[string][]string
+	// groups names the endpoint groups of weighted splits.
+	Groups map[string][]string
+}
+
+// backendRuntime connects the gRPC runtime to the mapped backend endpoints,
+// returning the transport of the calls to them. When stubs is set, services are
+// left unmapped and their fields are served with placeholders typed after it.
+func backendRuntime(reg *protoreg.Registry, backends map[string][]string, tc TransportConfig, stubs *schema.Schema, opts ...grpcrt.Option) (executor.Runtime, *grpctp.Transport, error) {
+	providers, err := serviceEndpoints(reg, backends, stubs == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(providers) == 0 && stubs == nil {
+		return nil, nil, fmt.Errorf("no backend mappings provided")
+	}
+	provider, err := endpointProvider(providers, tc.Groups)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tc.Tenants) > 0 {
+		tenants := make(map[string]grpctp.EndpointProvider, len(tc.Tenants))
+		for tenant, mapping := range tc.Tenants {
+			eps, _ := serviceEndpoints(reg, mapping, false)
+			for svc, shared := range providers {
+				if _, ok := eps[svc]; !ok {
+					eps[svc] = shared
+				}
+			}
+			if tenants[tenant], err = endpointProvider(eps, tc.Groups); err != nil {
+				return nil, nil, fmt.Errorf("tenant %s: %w", tenant, err)
+			}
+		}
+		provider = grpctp.NewTenantEndpoints(tenants, provider)
+	}
+
+	transport := grpctp.New(append(tc.Options, grpctp.WithProvider(provider))...)
+	var tr grpcrt.Transport = transport
+	if len(tc.Faults) > 0 {
+		tr = grpctp.NewFaultInjector(tr, tc.Faults...)
+	}
+	if len(tc.Mirror) > 0 && tc.MirrorPercent > 0 {
+		shadows, _ := serviceEndpoints(reg, tc.Mirror, false)
+		shadow := grpctp.New(append(tc.Options, grpctp.WithProvider(grpctp.NewStaticEndpoints(shadows)))...)
+		tr = grpctp.NewMirror(tr, shadow, grpctp.MirrorOptions{Percent: tc.MirrorPercent})
+	}
+	if tc.WarmUp > 0 {
+		services := make([]string, 0, len(providers))
+		for svc := range providers {
+			services = append(services, svc)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), tc.WarmUp)
+		defer cancel()
+		if err := transport.WarmUp(ctx, services...); err != nil {
+			log.Printf("warm-up: %v", err)
+		}
+		for tenant := range tc.Tenants {
+			if err := transport.WarmUp(protographctx.WithTenant(ctx, tenant), services...); err != nil {
+				log.Printf("warm-up of tenant %s: %v", tenant, err)
+			}
+		}
+	}
+	if stubs != nil {
+		opts = append(opts, grpcrt.WithStubs(stubs))
+		return grpcrt.NewRuntime(unmappedRegistry{reg, providers}, tr, opts...), transport, nil
+	}
+	return grpcrt.NewRuntime(reg, tr, opts...), transport, nil
+}
+
+// endpointProvider serves the endpoints of mapped services, splitting the calls
+// of services mapped to weighted groups (Svc=v1:90%,v2:10%) between them.
+func endpointProvider(mapped map[string][]string, groups map[string][]string) (grpctp.EndpointProvider, error) {
+	static := map[string][]string{}
+	splits := map[string][]grpctp.EndpointGroup{}
+	for svc, eps := range mapped {
+		if !slices.ContainsFunc(eps, func(ep string) bool { return strings.Contains(ep, "%") }) {
+			static[svc] = eps
+			continue
+		}
+		if len(eps) > 1 {
+			return nil, fmt.Errorf("backend of %s: a weighted split must be its only mapping", svc)
+		}
+		split, err := parseSplit(eps[0], groups)
+		if err != nil {
+			return nil, fmt.Errorf("backend of %s: %w", svc, err)
+		}
+		splits[svc] = split
+	}
+	var provider grpctp.EndpointProvider = grpctp.NewStaticEndpoints(static)
+	if len(splits) == 0 {
+		return provider, nil
+	}
+	return grpctp.NewSplitEndpoints(provider, splits)
+}
+
+// parseSplit parses "v1:90%,v2:10%", where each name is a group or an endpoint.
+func parseSplit(spec string, groups map[string][]string) ([]grpctp.EndpointGroup, error) {
+	var out []grpctp.EndpointGroup
+	for _, part := range strings.Split(spec, ",") {
+		i := strings.LastIndex(part, ":")
+		weight, err := strconv.Atoi(strings.TrimSuffix(part[i+1:], "%"))
+		name := strings.TrimSpace(part[:max(i, 0)])
+		if i < 0 || name == "" || !strings.HasSuffix(part, "%") || err != nil {
+			return nil, fmt.Errorf("invalid split %q (want group:N%%,...)", part)
+		}
+		endpoints, ok := groups[name]
+		if !ok {
+			if !strings.Contains(name, ":") {
+				return nil, fmt.Errorf("unknown endpoint group %q", name)
+			}
+			endpoints = []string{name}
+		}
+		out = append(out, grpctp.EndpointGroup{Name: name, Endpoints: endpoints, Weight: weight})
+	}
+	return out, nil
+}
+
+// unmappedRegistry hides the resolvers and loaders of services without endpoints,
+// so the runtime stubs their fields instead of calling them.
+type unmappedRegistry struct {
+	*protoreg.Registry
+	endpoints map[string][]string
+}
+
+func (r unmappedRegistry) mapped(md protoreflect.MethodDescriptor) protoreflect.MethodDescriptor {
+	if md == nil || len(r.endpoints[string(md.Parent().FullName())]) == 0 {
+		return nil
+	}
+	return md
+}
+
+func (r unmappedRegistry) GetBatchResolverDescriptor(objectType, field string) protoreflect.MethodDescriptor {
+	return r.mapped(r.Registry.GetBatchResolverDescriptor(objectType, field))
+}
+
+func (r unmappedRegistry) GetSingleResolverDescriptor(objectType, field string) protoreflect.MethodDescriptor {
+	return r.mapped(r.Registry.GetSingleResolverDescriptor(objectType, field))
+}
+
+func (r unmappedRegistry) GetBatchLoaderDescriptor(objectType, field string) protoreflect.MethodDescriptor {
+	return r.mapped(r.Registry.GetBatchLoaderDescriptor(objectType, field))
+}
+
+func (r unmappedRegistry) GetSingleLoaderDescriptor(objectType, field string) protoreflect.MethodDescriptor {
+	return r.mapped(r.Registry.GetSingleLoaderDescriptor(objectType, field))
+}
+
+// serviceEndpoints maps every service of reg to its endpoints in mapping, or to
+// those of the "*" wildcard. Unmapped services are an error when required and
+// left out otherwise.
+func serviceEndpoints(reg *protoreg.Registry, mapping map[string][]string, required bool) (map[string][]string, error) {
+	wildcard := mapping["*"]
+	out := map[string][]string{}
+	for _, fd := range reg.GetAllServiceFiles() {
+		for i := range fd.Services().Len() {
+			svc := fd.Services().Get(i)
+			fn := string(svc.FullName())
+
+			eps := mapping[fn]
+			if len(eps) == 0 {
+				eps = wildcard
+			}
+			if len(eps) == 0 {
+				if required {
+					return nil, fmt.Errorf("no backend mapping for %s", svc)
+				}
+				continue
+			}
+			out[fn] = eps
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/hanpama/protograph/internal/cache"
+	"github.com/hanpama/protograph/internal/grpctp"
+	"github.com/hanpama/protograph/internal/schema"
+	"github.com/hanpama/protograph/internal/server"
+)
+
+// Stats is a snapshot of the state of a gateway.
+type Stats struct {
+	// SchemaHash is the hex SHA-256 of the SDL of the schema served, without
+	// the introspection types, and LoadedAt when it was loaded.
+	SchemaHash string
+	LoadedAt   time.Time
+	// Backends are the connection pools to the backends; empty when replaying.
+	Backends []grpctp.EndpointStats
+	// InFlight is the number of operations executing.
+	InFlight int64
+	// QueryCache reports the parsed operations cache, FieldCache the @cache
+	// field results, OperationCache the responses cached for operations with
+	// a cacheTTL and ProjectionCache the descriptor lookups of the runtime.
+	// Caches not in use report zero values.
+	QueryCache      server.QueryCacheStats
+	FieldCache      cache.Stats
+	OperationCache  cache.Stats
+	ProjectionCache cache.Stats
+}
+
+// Stats returns the current stats of the gateway.
+func (g *Gateway) Stats() Stats {
+	s := Stats{
+		SchemaHash: g.schemaHash,
+		LoadedAt:   g.loadedAt,
+		InFlight:   g.Handler.InFlight(),
+		QueryCache: g.Handler.QueryCacheStats(),
+	}
+	if g.transport != nil {
+		s.Backends = g.transport.Stats()
+	}
+	if g.grpcRuntime != nil {
+		s.ProjectionCache = g.grpcRuntime.ProjectionStats()
+	}
+	if g.fieldCache != nil {
+		s.FieldCache = g.fieldCache.Stats()
+	}
+	if g.operationCache != nil {
+		s.OperationCache = g.operationCache.Stats()
+	}
+	return s
+}
+
+// schemaHash identifies a schema by its SDL.
+func schemaHash(sch *schema.Schema) string {
+	sum := sha256.Sum256([]byte(schema.Render(sch)))
+	return hex.EncodeToString(sum[:])
+}
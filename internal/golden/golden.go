@@ -0,0 +1,171 @@
+// Package golden compares the contract a project compiles to, its SDL and the
+// .proto files of its services, with golden copies committed next to it, so
+// that CI notices unintended changes to the API clients and backends depend on.
+//
+// A golden directory holds schema.graphql, as printed by compile-sdl, and the
+// .proto files printed by compile-proto under proto/.
+package golden
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/protoreg"
+	"github.com/hanpama/protograph/internal/schema"
+)
+
+// SchemaFile is the path of the compiled SDL in a golden directory.
+const SchemaFile = "schema.graphql"
+
+// Artifacts renders the contract of proj keyed by path in a golden directory,
+// with forward slashes.
+func Artifacts(proj *ir.Project) (map[string]string, error) {
+	sch, err := schema.BuildFromIR(proj)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := protoreg.Build(proj)
+	if err != nil {
+		return nil, err
+	}
+	protos, err := protoreg.RenderFiles(reg)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := map[string]string{SchemaFile: schema.Render(sch)}
+	for name, content := range protos {
+		artifacts[path.Join("proto", name)] = content
+	}
+	return artifacts, nil
+}
+
+// Mismatch is an artifact that differs from its golden file.
+type Mismatch struct {
+	Path string
+	// Diff is the unified diff from the golden file to the artifact. A missing
+	// golden file diffs from /dev/null, and a golden file without an artifact
+	// to /dev/null.
+	Diff string
+}
+
+// Compare returns the artifacts differing from their golden files in dir, and
+// the .graphql and .proto files in dir no artifact has, ordered by path.
+func Compare(dir string, artifacts map[string]string) ([]Mismatch, error) {
+	stale, err := staleFiles(dir, artifacts)
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []Mismatch
+	for _, name := range sortedKeys(artifacts) {
+		want, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		from := "golden/" + name
+		if errors.Is(err, fs.ErrNotExist) {
+			from = "/dev/null"
+		} else if err != nil {
+			return nil, err
+		}
+		if string(want) != artifacts[name] {
+			mismatches = append(mismatches, Mismatch{Path: name, Diff: unifiedDiff(from, "compiled/"+name, string(want), artifacts[name])})
+		}
+	}
+	for _, name := range stale {
+		want, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, Mismatch{Path: name, Diff: unifiedDiff("golden/"+name, "/dev/null", string(want), "")})
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Path < mismatches[j].Path })
+	return mismatches, nil
+}
+
+// Summary formats mismatches as one diff per file, for printing.
+func Summary(mismatches []Mismatch) string {
+	var b strings.Builder
+	for _, m := range mismatches {
+		b.WriteString(m.Diff)
+		if !strings.HasSuffix(m.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Update writes the artifacts to dir as its golden files and removes the
+// golden files Compare reports as stale.
+func Update(dir string, artifacts map[string]string) error {
+	stale, err := staleFiles(dir, artifacts)
+	if err != nil {
+		return err
+	}
+	for _, name := range stale {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+	for name, content := range artifacts {
+		fp := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// artifactExts are the extensions of the files Artifacts renders.
+var artifactExts = map[string]bool{".graphql": true, ".proto": true}
+
+// staleFiles returns the .graphql and .proto files in dir no artifact has, so
+// that other files such as a README are left alone.
+func staleFiles(dir string, artifacts map[string]string) ([]string, error) {
+	var stale []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == dir {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if _, ok := artifacts[name]; !ok && artifactExts[path.Ext(name)] {
+			stale = append(stale, name)
+		}
+		return nil
+	})
+	return stale, err
+}
+
+func unifiedDiff(from, to, a, b string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	return diff
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
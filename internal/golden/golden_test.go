@@ -0,0 +1,116 @@
+package golden
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/hanpama/protograph/internal/ir"
+)
+
+const testSDL = `schema {
+    query: Query
+}
+
+type Query {
+    user(id: ID!): User @resolve
+}
+
+type User @loader {
+    id: ID!
+    name: String!
+}
+`
+
+func buildArtifacts(t *testing.T, sdl string) map[string]string {
+	t.Helper()
+	disc := ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "test", Name: "users", Content: sdl}})
+	proj, err := ir.Build(context.Background(), disc)
+	if err != nil {
+		t.Fatalf("build project: %v", err)
+	}
+	artifacts, err := Artifacts(proj)
+	if err != nil {
+		t.Fatalf("artifacts: %v", err)
+	}
+	return artifacts
+}
+
+func paths(mismatches []Mismatch) []string {
+	var out []string
+	for _, m := range mismatches {
+		out = append(out, m.Path)
+	}
+	return out
+}
+
+func TestArtifacts_Deterministic(t *testing.T) {
+	first := buildArtifacts(t, testSDL)
+	if _, ok := first[SchemaFile]; !ok {
+		t.Fatalf("no %s in %v", SchemaFile, first)
+	}
+	for range 5 {
+		if diff := cmp.Diff(first, buildArtifacts(t, testSDL)); diff != "" {
+			t.Fatalf("artifacts differ between builds (-first +got):\n%s", diff)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := buildArtifacts(t, testSDL)
+
+	mismatches, err := Compare(dir, artifacts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != len(artifacts) || !strings.HasPrefix(mismatches[0].Diff, "--- /dev/null\n") {
+		t.Fatalf("missing golden files not reported: %+v", mismatches)
+	}
+
+	if err := Update(dir, artifacts); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if mismatches, err := Compare(dir, artifacts); err != nil || len(mismatches) != 0 {
+		t.Fatalf("golden files just written differ: %v %+v", err, mismatches)
+	}
+
+	changed := buildArtifacts(t, strings.Replace(testSDL, "name: String!", "name: String!\n    email: String!", 1))
+	mismatches, err = Compare(dir, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"proto/test/test/users.proto", SchemaFile}, paths(mismatches)); diff != "" {
+		t.Fatalf("mismatched paths (-want +got):\n%s", diff)
+	}
+	sdlDiff := mismatches[1].Diff
+	if !strings.HasPrefix(sdlDiff, "--- golden/schema.graphql\n+++ compiled/schema.graphql\n") || !strings.Contains(sdlDiff, "\n+  email: String!\n") {
+		t.Fatalf("unexpected diff:\n%s", sdlDiff)
+	}
+
+	// A golden file no artifact has is stale; other files are not
+	delete(changed, "proto/test/test/users.proto")
+	mismatches, err = Compare(dir, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mismatches[0].Diff, "+++ /dev/null\n") {
+		t.Fatalf("stale golden file not reported:\n%s", mismatches[0].Diff)
+	}
+	if err := Update(dir, changed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "proto", "test", "test", "users.proto")); !os.IsNotExist(err) {
+		t.Fatalf("stale golden file not removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err != nil {
+		t.Fatalf("unrelated file removed: %v", err)
+	}
+}
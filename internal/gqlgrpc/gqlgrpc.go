@@ -0,0 +1,270 @@
+// Package gqlgrpc serves GraphQL operations over gRPC, for internal services
+// that call the gateway with their gRPC stack instead of HTTP. The service is
+//
+//	syntax = "proto3";
+//	package protograph.v1;
+//
+//	service GraphQL {
+//	  rpc Execute(ExecuteRequest) returns (ExecuteResponse);
+//	}
+//
+//	message ExecuteRequest {
+//	  string query = 1;
+//	  string operation_name = 2;
+//	  bytes variables = 3; // a JSON object
+//	}
+//
+//	message ExecuteResponse {
+//	  bytes result = 1; // the JSON response, as served over HTTP
+//	}
+//
+// Each call is served by the HTTP handler of the gateway as a POST request, so
+// limits, events and header-based options apply alike: incoming metadata is
+// read as request headers, and response headers are sent back as header
+// metadata.
+package gqlgrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Names of the service and its method.
+const (
+	ServiceName   = "protograph.v1.GraphQL"
+	ExecuteMethod = "/" + ServiceName + "/Execute"
+)
+
+// File describes the service, and is registered in protoregistry.GlobalFiles so
+// that server reflection can list it.
+var File = buildFile()
+
+var (
+	requestDesc  = File.Messages().ByName("ExecuteRequest")
+	responseDesc = File.Messages().ByName("ExecuteResponse")
+)
+
+func init() {
+	if err := protoregistry.GlobalFiles.RegisterFile(File); err != nil {
+		panic(err)
+	}
+}
+
+func buildFile() protoreflect.FileDescriptor {
+	field := func(name, jsonName string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(jsonName),
+			Number:   proto.Int32(n),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("protograph/v1/graphql.proto"),
+		Package: proto.String("protograph.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("ExecuteRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("query", "query", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("operation_name", "operationName", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("variables", "variables", 3, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				},
+			},
+			{
+				Name: proto.String("ExecuteResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("result", "result", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("GraphQL"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Execute"),
+				InputType:  proto.String(".protograph.v1.ExecuteRequest"),
+				OutputType: proto.String(".protograph.v1.ExecuteResponse"),
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		panic(err)
+	}
+	return fd
+}
+
+// NewRequest returns an empty ExecuteRequest.
+func NewRequest() *dynamicpb.Message { return dynamicpb.NewMessage(requestDesc) }
+
+// NewResponse returns an empty ExecuteResponse.
+func NewResponse() *dynamicpb.Message { return dynamicpb.NewMessage(responseDesc) }
+
+// Register registers the service on s, serving calls with h.
+func Register(s grpc.ServiceRegistrar, h http.Handler) {
+	s.RegisterService(&serviceDesc, h)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*http.Handler)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Execute",
+		Handler:    executeHandler,
+	}},
+	Metadata: "protograph/v1/graphql.proto",
+}
+
+func executeHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := NewRequest()
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	h := srv.(http.Handler)
+	if interceptor == nil {
+		return execute(ctx, h, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: ExecuteMethod}
+	return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+		return execute(ctx, h, req.(protoreflect.ProtoMessage).ProtoReflect())
+	})
+}
+
+// execute serves one call with h. Results of requests h rejects before
+// executing them, such as malformed variables or overload, are returned as
+// errors of the matching code; other results are returned as is.
+func execute(ctx context.Context, h http.Handler, in protoreflect.Message) (*dynamicpb.Message, error) {
+	fields := requestDesc.Fields()
+	variables := in.Get(fields.ByName("variables")).Bytes()
+	if len(variables) > 0 && !json.Valid(variables) {
+		return nil, status.Error(codes.InvalidArgument, "variables are not valid JSON")
+	}
+	body, err := json.Marshal(struct {
+		Query         string          `json:"query"`
+		OperationName string          `json:"operationName,omitempty"`
+		Variables     json.RawMessage `json:"variables,omitempty"`
+	}{
+		Query:         in.Get(fields.ByName("query")).String(),
+		OperationName: in.Get(fields.ByName("operation_name")).String(),
+		Variables:     variables,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	r.Header = headerFromMetadata(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+
+	w := &responseWriter{header: http.Header{}, status: http.StatusOK}
+	h.ServeHTTP(w, r)
+
+	if md := metadataFromHeader(w.header); len(md) > 0 {
+		_ = grpc.SetHeader(ctx, md)
+	}
+	if w.status != http.StatusOK {
+		return nil, status.Error(statusCode(w.status), errorMessage(w.body.Bytes()))
+	}
+	out := NewResponse()
+	out.Set(responseDesc.Fields().ByName("result"), protoreflect.ValueOfBytes(w.body.Bytes()))
+	return out, nil
+}
+
+// headerFromMetadata returns the incoming metadata of ctx as request headers,
+// leaving out binary values and the keys of the gRPC protocol itself.
+func headerFromMetadata(ctx context.Context) http.Header {
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, vs := range md {
+		if strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") || strings.HasSuffix(k, "-bin") {
+			continue
+		}
+		switch k {
+		case "content-type", "user-agent", "te":
+			continue
+		}
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+	return header
+}
+
+// metadataFromHeader returns the response headers worth sending back.
+func metadataFromHeader(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, vs := range header {
+		switch k {
+		case "Content-Type", "Content-Length", "Vary":
+			continue
+		}
+		md[strings.ToLower(k)] = vs
+	}
+	return md
+}
+
+func statusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// errorMessage returns the message of the first error of a response body.
+func errorMessage(body []byte) string {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &resp) != nil || len(resp.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+	return resp.Errors[0].Message
+}
+
+// responseWriter records the response of the HTTP handler.
+type responseWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
@@ -0,0 +1,82 @@
+package gqlgrpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+	server "github.com/hanpama/protograph/internal/server"
+)
+
+func TestExecute(t *testing.T) {
+	sch, err := schema.BuildFromSDL(`type Query { greet(name: String!): String }`)
+	require.NoError(t, err)
+	var forwarded metadata.MD
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.greet": func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			forwarded, _ = metadata.FromOutgoingContext(ctx)
+			return "hello " + args["name"].(string), nil
+		},
+	})
+	h, err := server.New(rt, sch, server.WithMetadataHeaders("Authorization"))
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	Register(s, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "test")
+		h.ServeHTTP(w, r)
+	}))
+	go s.Serve(lis)
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	call := func(ctx context.Context, query, operationName, variables string, header *metadata.MD) (string, error) {
+		in, out := NewRequest(), NewResponse()
+		fields := requestDesc.Fields()
+		in.Set(fields.ByName("query"), protoreflect.ValueOfString(query))
+		in.Set(fields.ByName("operation_name"), protoreflect.ValueOfString(operationName))
+		in.Set(fields.ByName("variables"), protoreflect.ValueOfBytes([]byte(variables)))
+		var opts []grpc.CallOption
+		if header != nil {
+			opts = append(opts, grpc.Header(header))
+		}
+		if err := conn.Invoke(ctx, ExecuteMethod, in, out, opts...); err != nil {
+			return "", err
+		}
+		return string(out.Get(responseDesc.Fields().ByName("result")).Bytes()), nil
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer t")
+	var header metadata.MD
+	result, err := call(ctx, `query A { a: greet(name: "a") } query B($n: String!) { greet(name: $n) }`, "B", `{"n":"grpc"}`, &header)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data":{"greet":"hello grpc"}}`, result)
+	require.Equal(t, []string{"Bearer t"}, forwarded.Get("authorization"))
+	require.Equal(t, []string{"test"}, header.Get("x-served-by"))
+
+	result, err = call(context.Background(), `{ nope }`, "", "", nil)
+	require.NoError(t, err)
+	require.Contains(t, result, `"errors"`)
+
+	_, err = call(context.Background(), `{ greet(name: "a") }`, "", `{"n":`, nil)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = call(context.Background(), `{ greet(name: "a") }`, "", `[1]`, nil)
+	require.Equal(t, codes.InvalidArgument, status.Code(err), "%v", err)
+}
@@ -0,0 +1,203 @@
+package grpcrt
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hanpama/protograph/internal/respheader"
+	"github.com/hanpama/protograph/protographctx"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// WithCoalescing merges the batch loader calls of concurrent executions: calls
+// of the same method arriving within window of the first are sent as one batch,
+// or as soon as it holds maxBatch entries (0 for no limit). Only calls with the
+// same tenant and outgoing metadata, apart from graphql-request-id, are merged,
+// so requests with different credentials or backends never share a call. A
+// merged call carries the request IDs of all its callers, and each receives the
+// response headers it collects; it otherwise runs on the context of the first
+// caller, whose request its logs and events name.
+func WithCoalescing(window time.Duration, maxBatch int) Option {
+	return func(r *Runtime) {
+		r.coalescer = &coalescer{window: window, maxBatch: maxBatch, pending: map[string]*pendingBatch{}}
+	}
+}
+
+// coalescer collects the entries of batch loader calls until their window ends.
+type coalescer struct {
+	window   time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+// pendingBatch is a merged call being collected.
+type pendingBatch struct {
+	key      string
+	md       protoreflect.MethodDescriptor
+	keyed    bool            // results carry their keys rather than their position
+	ctx      context.Context // of the first caller, without its cancellation
+	deadline time.Time       // latest caller deadline; zero when a caller has none
+	items    []protoreflect.Value
+	waiters  []*batchWaiter
+	timer    *time.Timer
+}
+
+// batchWaiter is one caller's share of a merged call: items [start, start+n).
+type batchWaiter struct {
+	start, n  int
+	requestID []string              // graphql-request-id of the caller
+	collector *respheader.Collector // of the caller's response headers, if any
+	resp      protoreflect.Message
+	err       error
+	done      chan struct{}
+}
+
+// callBatch sends a batch request, merged with concurrent ones when coalescing.
+func (r *Runtime) callBatch(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	if r.coalescer == nil {
+		return r.transport.Call(ctx, md, req)
+	}
+	return r.coalescer.call(ctx, r.transport, md, req, r.reg.IsKeyedLoader(md.FullName()))
+}
+
+func (c *coalescer) call(ctx context.Context, transport Transport, md protoreflect.MethodDescriptor, req protoreflect.Message, keyed bool) (protoreflect.Message, error) {
+	list := req.Get(md.Input().Fields().ByName("batches")).List()
+	w := &batchWaiter{n: list.Len(), done: make(chan struct{})}
+	if out, ok := metadata.FromOutgoingContext(ctx); ok {
+		w.requestID = out.Get("graphql-request-id")
+	}
+	w.collector, _ = respheader.FromContext(ctx)
+	tenant, _ := protographctx.Tenant(ctx)
+	key := string(md.FullName()) + "\x00" + tenant + "\x00" + metadataKey(ctx)
+
+	c.mu.Lock()
+	pb := c.pending[key]
+	if pb == nil {
+		pb = &pendingBatch{key: key, md: md, keyed: keyed, ctx: context.WithoutCancel(ctx)}
+		pb.deadline, _ = ctx.Deadline()
+		c.pending[key] = pb
+		pb.timer = time.AfterFunc(c.window, func() { c.flush(pb, transport) })
+	} else if dl, ok := ctx.Deadline(); !ok {
+		pb.deadline = time.Time{}
+	} else if !pb.deadline.IsZero() && dl.After(pb.deadline) {
+		pb.deadline = dl
+	}
+	w.start = len(pb.items)
+	for i := 0; i < list.Len(); i++ {
+		pb.items = append(pb.items, list.Get(i))
+	}
+	pb.waiters = append(pb.waiters, w)
+	full := c.maxBatch > 0 && len(pb.items) >= c.maxBatch
+	c.mu.Unlock()
+
+	if full {
+		c.flush(pb, transport)
+	}
+	select {
+	case <-w.done:
+		return w.resp, w.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush sends pb unless another flush took it already.
+func (c *coalescer) flush(pb *pendingBatch, transport Transport) {
+	c.mu.Lock()
+	if c.pending[pb.key] != pb {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pending, pb.key)
+	pb.timer.Stop()
+	c.mu.Unlock()
+
+	// The call collects response headers for every caller, on behalf of all
+	// their requests
+	ctx := respheader.Detach(pb.ctx)
+	var requestIDs []string
+	var collectors []*respheader.Collector
+	for _, w := range pb.waiters {
+		requestIDs = append(requestIDs, w.requestID...)
+		if w.collector != nil {
+			collectors = append(collectors, w.collector)
+		}
+	}
+	if len(requestIDs) > 0 {
+		out, _ := metadata.FromOutgoingContext(ctx)
+		out = out.Copy()
+		out.Set("graphql-request-id", requestIDs...)
+		ctx = metadata.NewOutgoingContext(ctx, out)
+	}
+	var shared *respheader.Collector
+	if len(collectors) > 0 {
+		shared = respheader.Merge(collectors...)
+		ctx = respheader.WithCollector(ctx, shared)
+	}
+	if !pb.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, pb.deadline)
+		defer cancel()
+	}
+	batchesIn := pb.md.Input().Fields().ByName("batches")
+	req := dynamicpb.NewMessage(pb.md.Input())
+	list := req.Mutable(batchesIn).List()
+	for _, item := range pb.items {
+		list.Append(item)
+	}
+	resp, err := transport.Call(ctx, pb.md, req)
+
+	// Each caller receives a response holding its own entries only. The results
+	// of keyed loaders may come in any order, or leave keys out, so each caller
+	// receives them all and joins its own by key.
+	batchesOut := pb.md.Output().Fields().ByName("batches")
+	for _, w := range pb.waiters {
+		if w.collector != nil {
+			shared.CopyTo(w.collector)
+		}
+		switch {
+		case err != nil:
+			w.err = err
+		case resp == nil || batchesOut == nil || pb.keyed:
+			w.resp = resp
+		default:
+			out := dynamicpb.NewMessage(pb.md.Output())
+			dst := out.Mutable(batchesOut).List()
+			src := resp.Get(batchesOut).List()
+			for i := w.start; i < w.start+w.n && i < src.Len(); i++ {
+				dst.Append(src.Get(i))
+			}
+			w.resp = out
+		}
+		close(w.done)
+	}
+}
+
+// metadataKey identifies the outgoing metadata of ctx, ignoring the request ID.
+func metadataKey(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		if k != "graphql-request-id" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		for _, v := range md[k] {
+			b.WriteString("\x00")
+			b.WriteString(v)
+		}
+		b.WriteString("\x01")
+	}
+	return b.String()
+}
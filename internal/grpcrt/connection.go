@@ -0,0 +1,279 @@
+package grpcrt
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// connectionArgs are the Relay pagination arguments of @connection fields. Their
+// resolvers take the window of the list they select as the page arguments
+// instead: the elements from offset on, at most limit of them, all when 0.
+var connectionArgs = []string{"first", "after", "last", "before"}
+
+const (
+	offsetArg = "offset"
+	limitArg  = "limit"
+)
+
+// maxPage bounds cursor offsets and first and last, so that the page arguments
+// fit the int32 fields of resolver requests, a probed element included.
+const maxPage = math.MaxInt32 - 1
+
+// cursorPrefix starts the decoded cursors of connection elements, which carry
+// their offset in the list as Relay's reference implementation does.
+const cursorPrefix = "arrayconnection:"
+
+// encodeCursor returns the opaque cursor of the element at offset.
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the element a cursor produced by
+// encodeCursor points at. ok is false when cursor is not a well-formed cursor,
+// or its offset is past maxPage.
+func decodeCursor(cursor string) (offset int, ok bool) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	s, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, false
+	}
+	offset, err = strconv.Atoi(s)
+	if err != nil || offset < 0 || offset > maxPage {
+		return 0, false
+	}
+	return offset, true
+}
+
+// page is the window of a connection's list selected by the pagination
+// arguments of one task, and what they tell about the elements around it.
+type page struct {
+	// offset and limit are the window asked from the resolver; limit 0 asks for
+	// every element from offset on
+	offset, limit int
+	// probe asks for one element past the window, telling whether more follow
+	probe bool
+	// last keeps only the last elements of the window, when it ends at an offset
+	// the list may not reach; -1 keeps all
+	last int
+	// empty is a window holding no element, served without calling the resolver
+	empty bool
+	// hasPrevious and hasNext are known from the arguments alone
+	hasPrevious, hasNext bool
+}
+
+// parsePage selects the window of a list like Relay's connectionFromArraySlice:
+// after and before bound it, then first keeps the elements at its start and last
+// those at its end. Without the length of the list, last needs first or before
+// to end the window.
+func parsePage(args map[string]any) (page, error) {
+	p := page{last: -1}
+	start, end, bounded := 0, 0, false
+	if cursor, ok := args["after"].(string); ok {
+		offset, ok := decodeCursor(cursor)
+		if !ok {
+			return page{}, errcode.Errorf(errcode.BadUserInput, "invalid cursor %q for after", cursor)
+		}
+		start = offset + 1
+	}
+	if cursor, ok := args["before"].(string); ok {
+		offset, ok := decodeCursor(cursor)
+		if !ok {
+			return page{}, errcode.Errorf(errcode.BadUserInput, "invalid cursor %q for before", cursor)
+		}
+		end, bounded = max(offset, start), true
+	}
+	// The elements up to before exist; the window first asks for may run past
+	// the end of the list
+	exact := bounded
+	if first, ok := args["first"].(int); ok {
+		if first < 0 || first > maxPage {
+			return page{}, errcode.Errorf(errcode.BadUserInput, "first must be between 0 and %d, got %d", maxPage, first)
+		}
+		if !bounded || start+first < end {
+			p.hasNext = bounded
+			p.probe = !bounded
+			end, bounded, exact = start+first, true, false
+		}
+	}
+	if last, ok := args["last"].(int); ok {
+		if last < 0 || last > maxPage {
+			return page{}, errcode.Errorf(errcode.BadUserInput, "last must be between 0 and %d, got %d", maxPage, last)
+		}
+		switch {
+		case !bounded:
+			return page{}, errcode.Errorf(errcode.BadUserInput, "last requires first or before")
+		case exact:
+			if end-last > start {
+				start = end - last
+				p.hasPrevious = true
+			}
+		default:
+			p.last = last
+		}
+	}
+	p.offset = start
+	if bounded {
+		p.limit = end - start
+		if p.probe {
+			p.limit++
+		}
+		p.empty = p.limit == 0
+	}
+	return p, nil
+}
+
+// args returns the request arguments of a task asking for p: its arguments
+// with the pagination ones replaced by the page ones.
+func (p page) args(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	for _, name := range connectionArgs {
+		delete(out, name)
+	}
+	out[offsetArg] = p.offset
+	out[limitArg] = p.limit
+	return out
+}
+
+// dispatchPages dispatches the tasks of a @connection field asking the resolver
+// for the window of the list their pagination arguments select, then pages the
+// lists it returns into connections.
+func (r *Runtime) dispatchPages(ctx context.Context, connType string, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	pageTasks := make([]executor.AsyncResolveTask, len(tasks))
+	copy(pageTasks, tasks)
+	pages := make(map[int]page, len(g.idxs))
+	calls := group{objectType: g.objectType, field: g.field}
+	for _, idx := range g.idxs {
+		p, err := parsePage(tasks[idx].Args)
+		if err != nil {
+			results[idx] = executor.AsyncResolveResult{Error: err}
+			continue
+		}
+		pages[idx] = p
+		if p.empty {
+			results[idx] = executor.AsyncResolveResult{}
+			continue
+		}
+		pageTasks[idx].Args = p.args(tasks[idx].Args)
+		calls.idxs = append(calls.idxs, idx)
+	}
+	if len(calls.idxs) > 0 {
+		r.dispatchMethods(ctx, calls, pageTasks, results)
+	}
+	for idx, p := range pages {
+		if results[idx].Error != nil {
+			continue
+		}
+		conn, err := r.connection(g.objectType, g.field, connType, p, results[idx].Value)
+		results[idx] = executor.AsyncResolveResult{Value: conn, Error: err}
+	}
+}
+
+// connection pages the elements a resolver returned for p into the source
+// message of connType: an edge per element, with the cursor of its offset in
+// the list, and the page info.
+func (r *Runtime) connection(objectType, field, connType string, p page, value any) (any, error) {
+	items, _ := value.([]any)
+	if p.limit > 0 && len(items) >= p.limit {
+		// A probed element past the window means more follow; elements past the
+		// limit are dropped
+		if p.probe {
+			p.hasNext = true
+			items = items[:p.limit-1]
+		} else {
+			items = items[:p.limit]
+		}
+	}
+	offset := p.offset
+	if p.last >= 0 && len(items) > p.last {
+		offset += len(items) - p.last
+		items = items[len(items)-p.last:]
+		p.hasPrevious = true
+	}
+
+	desc := r.reg.GetSourceMessageDescriptor(connType)
+	edgesFd := r.reg.GetSourceFieldDescriptor(connType, "edges")
+	pageInfoFd := r.reg.GetSourceFieldDescriptor(connType, "pageInfo")
+	if desc == nil || edgesFd == nil || edgesFd.Message() == nil || pageInfoFd == nil || pageInfoFd.Message() == nil {
+		return nil, r.fail(misconfiguration(objectType, field, "connection %s has no source message with edges and pageInfo", connType))
+	}
+	edgeType, _ := r.reg.GetMessageObjectType(edgesFd.Message().FullName())
+	nodeFd := r.reg.GetSourceFieldDescriptor(edgeType, "node")
+	cursorFd := r.reg.GetSourceFieldDescriptor(edgeType, "cursor")
+	if nodeFd == nil || cursorFd == nil {
+		return nil, r.fail(misconfiguration(objectType, field, "edge of connection %s has no node and cursor source fields", connType))
+	}
+
+	conn := dynamicpb.NewMessage(desc)
+	edges := conn.Mutable(edgesFd).List()
+	for i, item := range items {
+		edge := dynamicpb.NewMessage(edgesFd.Message())
+		if item != nil {
+			v, ok := r.protoValue(nodeFd, item)
+			if !ok {
+				return nil, errcode.Errorf(errcode.DownstreamServiceError, "%s.%s: element %d does not fit the node of %s", objectType, field, i, connType)
+			}
+			edge.Set(nodeFd, v)
+		}
+		edge.Set(cursorFd, protoreflect.ValueOfString(encodeCursor(offset+i)))
+		edges.Append(protoreflect.ValueOfMessage(edge))
+	}
+
+	pageInfoType, _ := r.reg.GetMessageObjectType(pageInfoFd.Message().FullName())
+	pageInfo := conn.Mutable(pageInfoFd).Message()
+	set := func(field string, v protoreflect.Value) {
+		if fd := r.reg.GetSourceFieldDescriptor(pageInfoType, field); fd != nil {
+			pageInfo.Set(fd, v)
+		}
+	}
+	set("hasNextPage", protoreflect.ValueOfBool(p.hasNext))
+	set("hasPreviousPage", protoreflect.ValueOfBool(p.hasPrevious))
+	if len(items) > 0 {
+		set("startCursor", protoreflect.ValueOfString(encodeCursor(offset)))
+		set("endCursor", protoreflect.ValueOfString(encodeCursor(offset+len(items)-1)))
+	}
+	return conn, nil
+}
+
+// protoValue converts a value produced by handleValue for fd back to the proto
+// value of fd.
+func (r *Runtime) protoValue(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, bool) {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		switch v := v.(type) {
+		case string:
+			if ev := r.protoEnumValue(fd.Enum(), v); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), true
+			}
+		case int32: // unknown number
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), true
+		}
+		return protoreflect.Value{}, false
+	case protoreflect.MessageKind:
+		msg, ok := v.(protoreflect.Message)
+		if !ok || msg.Descriptor().FullName() != fd.Message().FullName() {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfMessage(msg), true
+	}
+	// Scalars are converted to the Go type of their kind
+	if reflect.TypeOf(v) != reflect.TypeOf(r.handleValue(fd, fd.Default())) {
+		return protoreflect.Value{}, false
+	}
+	return protoreflect.ValueOf(v), true
+}
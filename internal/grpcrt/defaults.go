@@ -0,0 +1,40 @@
+package grpcrt
+
+import "google.golang.org/protobuf/reflect/protoreflect"
+
+// WithProtoDefaults serves unset proto3 scalar and enum fields as their proto
+// default (0, "", false, the first enum value) instead of null. Proto3 does not
+// tell such a field set to its default from an unset one, so without it every
+// zero value reads as null. Fields with explicit presence (optional, oneof
+// members, proto2 fields) and unset messages along a @source path still read
+// as null, and @default values take precedence.
+func WithProtoDefaults() Option {
+	return func(r *Runtime) { r.protoDefaults = true }
+}
+
+// zeroValued reports whether fd reads as its proto default when unset: with
+// WithProtoDefaults, a singular scalar or enum field without presence.
+func (r *Runtime) zeroValued(fd protoreflect.FieldDescriptor) bool {
+	return r.protoDefaults && !fd.HasPresence() && !fd.IsList() && !fd.IsMap() &&
+		fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind
+}
+
+// unsetValue is the value of field when its source path is unset: its @default
+// when declared, an empty list for an empty repeated field, then its proto
+// default per zeroValued, otherwise nil.
+func (r *Runtime) unsetValue(objectType, field string, msg protoreflect.Message, path []protoreflect.FieldDescriptor) any {
+	if v, ok := r.reg.GetSourceFieldDefault(objectType, field); ok {
+		return v
+	}
+	msg, fd := sourceField(msg, path)
+	switch {
+	case fd == nil:
+		return nil
+	case fd.IsList():
+		// Repeated fields have no presence; unset is empty
+		return []any{}
+	case r.zeroValued(fd):
+		return r.handleValue(fd, msg.Get(fd))
+	}
+	return nil
+}
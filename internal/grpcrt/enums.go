@@ -0,0 +1,54 @@
+package grpcrt
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// UnknownEnumAction selects how an enum number missing from the schema, e.g. one
+// a backend added before the gateway's schema, is resolved.
+type UnknownEnumAction int
+
+const (
+	// UnknownEnumNull resolves the value to null and reports a warning in the
+	// response extensions.
+	UnknownEnumNull UnknownEnumAction = iota
+	// UnknownEnumString passes the number through as a string.
+	UnknownEnumString
+	// UnknownEnumValue resolves the value to the policy value.
+	UnknownEnumValue
+)
+
+// UnknownEnumPolicy is the @onUnknown policy of an enum.
+type UnknownEnumPolicy struct {
+	Action UnknownEnumAction
+	Value  string // served when Action is UnknownEnumValue
+}
+
+// unknownEnumExtension is the response extensions key of unknown enum warnings.
+const unknownEnumExtension = "warnings"
+
+// apply resolves the unknown number n of enumType according to the policy.
+func (p UnknownEnumPolicy) apply(ctx context.Context, enumType string, n int32) any {
+	switch p.Action {
+	case UnknownEnumString:
+		return strconv.Itoa(int(n))
+	case UnknownEnumValue:
+		return p.Value
+	}
+	warning := map[string]any{
+		"message": fmt.Sprintf("enum %s has no value numbered %d", enumType, n),
+		"enum":    enumType,
+		"number":  n,
+	}
+	executor.ExtensionsFromContext(ctx).Update(unknownEnumExtension, func(prev any) any {
+		prevWarnings, _ := prev.([]any)
+		warnings := make([]any, len(prevWarnings), len(prevWarnings)+1)
+		copy(warnings, prevWarnings)
+		return append(warnings, warning)
+	})
+	return nil
+}
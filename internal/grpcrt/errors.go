@@ -0,0 +1,122 @@
+package grpcrt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// downstreamError codes an error returned by the transport. gRPC statuses that
+// blame the caller map to the matching client-facing codes; anything else is a
+// backend failure. Errors that already carry a code are returned unchanged.
+func downstreamError(err error) error {
+	var coded *errcode.Error
+	if err == nil || errors.As(err, &coded) {
+		return err
+	}
+	code := errcode.DownstreamServiceError
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unauthenticated:
+			code = errcode.Unauthenticated
+		case codes.PermissionDenied:
+			code = errcode.Forbidden
+		case codes.InvalidArgument, codes.OutOfRange:
+			code = errcode.BadUserInput
+		}
+	}
+	return errcode.Wrap(code, err)
+}
+
+// elementError returns the error a backend set on one element of a batch
+// response, coded like a failed call with the same status. A code of OK, or
+// none, counts as Unknown since the element was failed on purpose.
+func elementError(resp protoreflect.Message) error {
+	fd := resp.Descriptor().Fields().ByName("error")
+	if fd == nil || fd.Kind() != protoreflect.MessageKind || !resp.Has(fd) {
+		return nil
+	}
+	e := resp.Get(fd).Message()
+	code := codes.Unknown
+	if f := e.Descriptor().Fields().ByName("code"); f != nil && e.Get(f).Int() != 0 {
+		code = codes.Code(e.Get(f).Int())
+	}
+	var message string
+	if f := e.Descriptor().Fields().ByName("message"); f != nil {
+		message = e.Get(f).String()
+	}
+	return downstreamError(status.Error(code, message))
+}
+
+// ErrorAction selects how a failed field is reported to clients.
+type ErrorAction int
+
+const (
+	// ErrorPropagate reports the error as returned by the backend.
+	ErrorPropagate ErrorAction = iota
+	// ErrorNull resolves the field to null with a generic message that keeps the error code.
+	ErrorNull
+	// ErrorDefault resolves the field to the policy value without an error.
+	ErrorDefault
+)
+
+// ErrorPolicy is the @onError policy of a field.
+type ErrorPolicy struct {
+	Action ErrorAction
+	Value  any // served when Action is ErrorDefault
+}
+
+// apply rewrites a failed result according to the policy.
+func (p ErrorPolicy) apply(objectType, field string, res executor.AsyncResolveResult) executor.AsyncResolveResult {
+	if res.Error == nil {
+		return res
+	}
+	switch p.Action {
+	case ErrorNull:
+		return executor.AsyncResolveResult{Error: errcode.Errorf(errcode.Of(res.Error), "%s.%s is unavailable", objectType, field)}
+	case ErrorDefault:
+		return executor.AsyncResolveResult{Value: p.Value}
+	}
+	return res
+}
+
+// WithStrict(false) fails the fields affected by a misconfigured registry, or a
+// malformed envelope, with an error located at the field instead of panicking,
+// so one misconfigured field can't take the whole process down. Runtimes are
+// strict by default.
+func WithStrict(strict bool) Option {
+	return func(r *Runtime) { r.strict = strict }
+}
+
+// MisconfigurationError reports a field the registry cannot serve, such as one
+// without a source field descriptor or method, or a source of the wrong shape.
+// Field is empty for misconfigured abstract types.
+type MisconfigurationError struct {
+	ObjectType string
+	Field      string
+	Message    string
+}
+
+func (e *MisconfigurationError) Error() string { return e.Message }
+
+// misconfiguration returns the internal error of a misconfigured field.
+func misconfiguration(objectType, field, format string, args ...any) error {
+	return errcode.Wrap(errcode.InternalServerError, &MisconfigurationError{
+		ObjectType: objectType,
+		Field:      field,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// fail panics with err in strict mode and returns it otherwise.
+func (r *Runtime) fail(err error) error {
+	if r.strict {
+		panic(err.Error())
+	}
+	return err
+}
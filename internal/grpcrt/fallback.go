@@ -0,0 +1,84 @@
+package grpcrt
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// fallbackExtension is the response extensions key listing the values served by
+// fallback resolvers.
+const fallbackExtension = "fallbacks"
+
+// runFallbacks retries the tasks of g its resolver failed with each fallback
+// method of the field in turn, until none is left failing. Only the failures
+// retryable reports are retried: a request another service would reject or
+// miss as well is not. Every fallback call serving values is reported in the
+// response extensions.
+func (r *Runtime) runFallbacks(ctx context.Context, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	batch := r.reg.GetBatchResolverDescriptor(g.objectType, g.field) != nil
+	for _, md := range r.reg.GetFallbackResolverDescriptors(g.objectType, g.field) {
+		failed := group{objectType: g.objectType, field: g.field}
+		for _, idx := range g.idxs {
+			if err := results[idx].Error; err != nil && retryable(ctx, err) {
+				failed.idxs = append(failed.idxs, idx)
+			}
+		}
+		if len(failed.idxs) == 0 {
+			return
+		}
+		r.withTimeout(ctx, failed, results, func(ctx context.Context) {
+			if batch {
+				r.runBatchResolverGroup(ctx, md, tasks, failed.idxs, results)
+			} else {
+				r.runSingleResolverGroup(ctx, md, tasks, failed.idxs, results)
+			}
+		})
+		served := 0
+		for _, idx := range failed.idxs {
+			if results[idx].Error == nil {
+				served++
+			}
+		}
+		if served == 0 {
+			continue
+		}
+		entry := map[string]any{
+			"field":  g.objectType + "." + g.field,
+			"method": string(md.FullName()),
+			"served": served,
+		}
+		executor.ExtensionsFromContext(ctx).Update(fallbackExtension, func(prev any) any {
+			prevEntries, _ := prev.([]any)
+			entries := make([]any, len(prevEntries), len(prevEntries)+1)
+			copy(entries, prevEntries)
+			return append(entries, entry)
+		})
+	}
+}
+
+// retryable reports whether a fallback may serve a task its resolver failed with
+// err: the backend was unavailable or overloaded, could not be reached, or ran
+// out of its own time while the request has some left. Statuses such as
+// NotFound, AlreadyExists or FailedPrecondition describe the request rather
+// than the backend, and are not retried. Fallbacks only serve fields that do
+// not write, which the schema compiler enforces, so a timed out call is safe to
+// send again.
+func retryable(ctx context.Context, err error) bool {
+	if errcode.Of(err) != errcode.DownstreamServiceError || ctx.Err() != nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return true // no status: the call failed in transport
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
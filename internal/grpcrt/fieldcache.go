@@ -0,0 +1,276 @@
+package grpcrt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/hanpama/protograph/internal/cache"
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/protographctx"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// CachePolicy is the @cache policy of a field. Cached results are served fresh
+// for TTL, then served stale while a background call refreshes them for another
+// StaleWhileRevalidate.
+type CachePolicy struct {
+	TTL                  time.Duration
+	StaleWhileRevalidate time.Duration
+}
+
+// Cache statuses reported per field coordinate in extensions.cache.
+const (
+	CacheHit   = "hit"
+	CacheStale = "stale"
+	CacheMiss  = "miss"
+)
+
+// cacheExtension is the response extensions key of cache statuses.
+const cacheExtension = "cache"
+
+// WithFieldCache serves @cache fields from c. Entries are encoded responses, so
+// c may be shared between gateways serving the same schema.
+func WithFieldCache(c cache.Store) Option {
+	return func(r *Runtime) { r.fieldCache = c }
+}
+
+// cachedGroup is the cache state of one (objectType, field) group.
+type cachedGroup struct {
+	policy  CachePolicy
+	desc    protoreflect.MessageDescriptor // response message holding the result
+	keys    map[int]string                 // task index -> cache key, for cacheable tasks
+	misses  []int
+	refresh []int
+	counts  map[string]int
+}
+
+// lookupCache serves the tasks of a @cache group from the field cache. It returns
+// nil when the field is not cached, in which case every task must be called.
+func (r *Runtime) lookupCache(ctx context.Context, objectType, field string, tasks []executor.AsyncResolveTask, idxs []int, results []executor.AsyncResolveResult) *cachedGroup {
+	if r.fieldCache == nil {
+		return nil
+	}
+	policy, ok := r.reg.GetCachePolicy(objectType, field)
+	if !ok {
+		return nil
+	}
+	desc := r.responseDescriptor(objectType, field)
+	if desc == nil {
+		return nil
+	}
+	cg := &cachedGroup{policy: policy, desc: desc, keys: make(map[int]string, len(idxs)), counts: map[string]int{}}
+	now := r.now()
+	for _, idx := range idxs {
+		key := r.cacheKey(ctx, tasks[idx])
+		if key == "" {
+			cg.misses = append(cg.misses, idx)
+			continue
+		}
+		cg.keys[idx] = key
+		value, storedAt, ok := r.readCache(ctx, desc, key)
+		age := now.Sub(storedAt)
+		switch {
+		case ok && age < policy.TTL:
+			results[idx] = executor.AsyncResolveResult{Value: value}
+			cg.counts[CacheHit]++
+		case ok && age < policy.TTL+policy.StaleWhileRevalidate:
+			results[idx] = executor.AsyncResolveResult{Value: value}
+			cg.refresh = append(cg.refresh, idx)
+			cg.counts[CacheStale]++
+		default:
+			cg.misses = append(cg.misses, idx)
+			cg.counts[CacheMiss]++
+		}
+	}
+	reportCacheStatus(ctx, objectType+"."+field, cg.counts)
+	return cg
+}
+
+// storeCache writes the successful results of idxs to the field cache.
+func (r *Runtime) storeCache(ctx context.Context, cg *cachedGroup, idxs []int, results []executor.AsyncResolveResult) {
+	for _, idx := range idxs {
+		key, ok := cg.keys[idx]
+		if !ok || results[idx].Error != nil {
+			continue
+		}
+		if entry, ok := r.encodeCacheEntry(cg.desc, r.dataPath(cg.desc), r.now(), results[idx].Value); ok {
+			r.fieldCache.Set(ctx, key, entry, cg.policy.TTL+cg.policy.StaleWhileRevalidate)
+		}
+	}
+}
+
+// revalidate refreshes stale entries in the background. A key already being
+// refreshed by another request is skipped.
+func (r *Runtime) revalidate(ctx context.Context, g group, cg *cachedGroup, tasks []executor.AsyncResolveTask) {
+	var idxs []int
+	for _, idx := range cg.refresh {
+		if _, busy := r.refreshing.LoadOrStore(cg.keys[idx], struct{}{}); !busy {
+			idxs = append(idxs, idx)
+		}
+	}
+	if len(idxs) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() {
+			for _, idx := range idxs {
+				r.refreshing.Delete(cg.keys[idx])
+			}
+		}()
+		results := make([]executor.AsyncResolveResult, len(tasks))
+		r.dispatch(ctx, group{objectType: g.objectType, field: g.field, idxs: idxs}, tasks, results)
+		r.storeCache(ctx, cg, idxs, results)
+	}()
+}
+
+// responseDescriptor returns the message holding the result of one task's response:
+// the response itself for single methods, or an element of `batches`.
+func (r *Runtime) responseDescriptor(objectType, field string) protoreflect.MessageDescriptor {
+	if md := r.reg.GetBatchResolverDescriptor(objectType, field); md != nil {
+		return md.Output().Fields().ByName("batches").Message()
+	}
+	if md := r.reg.GetSingleResolverDescriptor(objectType, field); md != nil {
+		return md.Output()
+	}
+	if md := r.reg.GetBatchLoaderDescriptor(objectType, field); md != nil {
+		return md.Output().Fields().ByName("batches").Message()
+	}
+	if md := r.reg.GetSingleLoaderDescriptor(objectType, field); md != nil {
+		return md.Output()
+	}
+	return nil
+}
+
+// cacheKey identifies a task by its field and the request fields it sends,
+// including those copied from the parent source, and by the tenant of ctx, whose
+// backends may differ. It returns "" when the request cannot be keyed, e.g.
+// because it carries a message.
+func (r *Runtime) cacheKey(ctx context.Context, task executor.AsyncResolveTask) string {
+	req := r.requestBuilder(task.ObjectType, task.Field, nil).request(task)
+	for _, v := range req {
+		if _, ok := v.(protoreflect.Message); ok {
+			return ""
+		}
+	}
+	b, err := json.Marshal(req) // map keys are sorted
+	if err != nil {
+		return ""
+	}
+	key := "protograph:field:"
+	if tenant, ok := protographctx.Tenant(ctx); ok {
+		key += "tenant=" + tenant + ":"
+	}
+	return key + task.ObjectType + "." + task.Field + ":" + string(b)
+}
+
+func (r *Runtime) readCache(ctx context.Context, desc protoreflect.MessageDescriptor, key string) (any, time.Time, bool) {
+	entry, ok := r.fieldCache.Get(ctx, key)
+	if !ok || len(entry) < 8 {
+		return nil, time.Time{}, false
+	}
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(entry[8:], msg); err != nil {
+		return nil, time.Time{}, false
+	}
+	value, err := r.handleResponse(msg)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return value, time.Unix(0, int64(binary.BigEndian.Uint64(entry))), true
+}
+
+// encodeCacheEntry stores value along the data path of a desc message, the inverse
+// of handleResponse, and prefixes the encoding with storedAt in Unix nanoseconds.
+func (r *Runtime) encodeCacheEntry(desc protoreflect.MessageDescriptor, path []protoreflect.FieldDescriptor, storedAt time.Time, value any) ([]byte, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	msg := dynamicpb.NewMessage(desc)
+	holder := protoreflect.Message(msg)
+	last := len(path) - 1
+	for _, fd := range path[:last] {
+		holder = holder.Mutable(fd).Message()
+	}
+	fd := path[last]
+	switch {
+	case value == nil:
+	case fd.Cardinality() == protoreflect.Repeated:
+		items, ok := value.([]any)
+		if !ok {
+			return nil, false
+		}
+		lst := holder.Mutable(fd).List()
+		for _, item := range items {
+			pv, ok := r.responseValue(fd, item)
+			if !ok {
+				return nil, false
+			}
+			lst.Append(pv)
+		}
+	default:
+		pv, ok := r.responseValue(fd, value)
+		if !ok {
+			return nil, false
+		}
+		holder.Set(fd, pv)
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	entry := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(b)), uint64(storedAt.UnixNano()))
+	return append(entry, b...), true
+}
+
+// responseValue converts a value produced by handleValue back to a protobuf value.
+func (r *Runtime) responseValue(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, bool) {
+	switch v := v.(type) {
+	case protoreflect.Message:
+		return protoreflect.ValueOfMessage(v), fd.Kind() == protoreflect.MessageKind
+	case string:
+		if fd.Kind() == protoreflect.EnumKind {
+			ev := r.protoEnumValue(fd.Enum(), v)
+			if ev == nil {
+				return protoreflect.Value{}, false
+			}
+			return protoreflect.ValueOfEnum(ev.Number()), true
+		}
+		return protoreflect.ValueOfString(v), true
+	case int32:
+		if fd.Kind() == protoreflect.EnumKind {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), true
+		}
+		return protoreflect.ValueOfInt32(v), true
+	case bool, int64, uint32, uint64, float32, float64, []byte:
+		return protoreflect.ValueOf(v), true
+	}
+	return protoreflect.Value{}, false
+}
+
+// reportCacheStatus adds the counts of one group to extensions.cache, keyed by
+// field coordinate.
+func reportCacheStatus(ctx context.Context, coordinate string, counts map[string]int) {
+	executor.ExtensionsFromContext(ctx).Update(cacheExtension, func(prev any) any {
+		prevByField, _ := prev.(map[string]any)
+		byField := make(map[string]any, len(prevByField)+1)
+		for k, v := range prevByField {
+			byField[k] = v
+		}
+		merged := map[string]int{}
+		if prevCounts, ok := prevByField[coordinate].(map[string]int); ok {
+			for k, n := range prevCounts {
+				merged[k] = n
+			}
+		}
+		for k, n := range counts {
+			merged[k] += n
+		}
+		byField[coordinate] = merged
+		return byField
+	})
+}
@@ -0,0 +1,26 @@
+package grpcrt
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// EncodeGlobalID returns the opaque Relay global ID for the node of typeName
+// identified by id. The encoding is base64("<typeName>:<id>").
+func EncodeGlobalID(typeName, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(typeName + ":" + id))
+}
+
+// DecodeGlobalID splits a global ID produced by EncodeGlobalID into its type name
+// and raw id. ok is false when globalID is not a well-formed global ID.
+func DecodeGlobalID(globalID string) (typeName, id string, ok bool) {
+	raw, err := base64.StdEncoding.DecodeString(globalID)
+	if err != nil {
+		return "", "", false
+	}
+	typeName, id, ok = strings.Cut(string(raw), ":")
+	if !ok || typeName == "" {
+		return "", "", false
+	}
+	return typeName, id, true
+}
@@ -0,0 +1,85 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+)
+
+// buildDogSource builds DogSource{ string name = 1 }.
+func buildDogSource(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("any_envelope.proto"),
+		Package: protoString("pets"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("DogSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name: protoString("name"), JsonName: protoString("name"), Number: protoInt32(1),
+				Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("any_envelope.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("DogSource")
+}
+
+// anyEnvelope packs msg the way a backend would, read back as a dynamic message
+// like every other response field.
+func anyEnvelope(t *testing.T, typeURL string, msg proto.Message) protoreflect.Message {
+	t.Helper()
+	value, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	b, err := proto.Marshal(&anypb.Any{TypeUrl: typeURL, Value: value})
+	require.NoError(t, err)
+	out := dynamicpb.NewMessage((&anypb.Any{}).ProtoReflect().Descriptor())
+	require.NoError(t, proto.Unmarshal(b, out))
+	return out
+}
+
+func TestAnyEnvelope_ResolvesTypeFromTypeURL(t *testing.T) {
+	dogDesc := buildDogSource(t)
+	dog := dynamicpb.NewMessage(dogDesc)
+	dog.Set(dogDesc.Fields().ByName("name"), protoreflect.ValueOfString("rex"))
+	rt := NewRuntime(NewMockRegistry().RegisterSourceMessage("Dog", dogDesc), nil)
+	ctx := context.Background()
+
+	for _, unwrap := range []func(context.Context, string, any) (any, error){rt.ResolveInterfaceConcreteValue, rt.ResolveUnionConcreteValue} {
+		v, err := unwrap(ctx, "Pet", anyEnvelope(t, "type.googleapis.com/pets.DogSource", dog))
+		require.NoError(t, err)
+		msg := v.(protoreflect.Message)
+		require.Equal(t, "rex", msg.Get(dogDesc.Fields().ByName("name")).String())
+
+		typ, err := rt.ResolveType(ctx, "Pet", msg)
+		require.NoError(t, err)
+		require.Equal(t, "Dog", typ)
+	}
+}
+
+func TestAnyEnvelope_EmptyIsNull(t *testing.T) {
+	rt := NewRuntime(NewMockRegistry(), nil)
+	v, err := rt.ResolveInterfaceConcreteValue(context.Background(), "Pet", dynamicpb.NewMessage((&anypb.Any{}).ProtoReflect().Descriptor()))
+	require.NoError(t, err)
+	require.Nil(t, v)
+}
+
+func TestAnyEnvelope_UnknownTypeIsDownstreamError(t *testing.T) {
+	dogDesc := buildDogSource(t)
+	rt := NewRuntime(NewMockRegistry().RegisterSourceMessage("Dog", dogDesc), nil)
+	_, err := rt.ResolveUnionConcreteValue(context.Background(), "SearchResult", anyEnvelope(t, "type.googleapis.com/pets.CatSource", dynamicpb.NewMessage(dogDesc)))
+	require.Error(t, err)
+	require.Equal(t, errcode.DownstreamServiceError, errcode.Of(err))
+}
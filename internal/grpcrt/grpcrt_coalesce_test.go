@@ -0,0 +1,190 @@
+package grpcrt
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/respheader"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+// echoTransport answers batch calls with the data of every request entry.
+type echoTransport struct{ calls atomic.Int32 }
+
+func (e *echoTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	e.calls.Add(1)
+	in := req.Get(md.Input().Fields().ByName("batches")).List()
+	data := make([]string, in.Len())
+	for i := range data {
+		data[i] = in.Get(i).Message().Get(in.Get(i).Message().Descriptor().Fields().ByName("data")).String()
+	}
+	return batchResponse(md, data...), nil
+}
+
+func TestBatchResolveAsync_CoalescesConcurrentBatchLoaderCalls(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().RegisterBatchLoader("Query", "item", md)
+	transport := &echoTransport{}
+	rt := NewRuntime(reg, transport, WithCoalescing(50*time.Millisecond, 0))
+
+	resolve := func(ctx context.Context, data ...string) []any {
+		tasks := make([]executor.AsyncResolveTask, len(data))
+		for i, d := range data {
+			tasks[i] = executor.AsyncResolveTask{ObjectType: "Query", Field: "item", Args: map[string]any{"data": d}}
+		}
+		var values []any
+		for _, res := range rt.BatchResolveAsync(ctx, tasks) {
+			values = append(values, res.Value)
+		}
+		return values
+	}
+
+	var wg sync.WaitGroup
+	var a, b []any
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a = resolve(metadata.AppendToOutgoingContext(context.Background(), "graphql-request-id", "1"), "a1")
+	}()
+	go func() {
+		defer wg.Done()
+		b = resolve(metadata.AppendToOutgoingContext(context.Background(), "graphql-request-id", "2"), "b1", "b2")
+	}()
+	wg.Wait()
+	require.Equal(t, []any{"a1"}, a)
+	require.Equal(t, []any{"b1", "b2"}, b)
+	require.EqualValues(t, 1, transport.calls.Load())
+
+	// Calls with different credentials are never merged
+	users := []string{"alice", "bob"}
+	got := make([][]any, len(users))
+	wg.Add(len(users))
+	for i, user := range users {
+		go func() {
+			defer wg.Done()
+			got[i] = resolve(metadata.AppendToOutgoingContext(context.Background(), "authorization", user), user)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, [][]any{{"alice"}, {"bob"}}, got)
+	require.EqualValues(t, 3, transport.calls.Load())
+
+	// nor are calls of different tenants, whose backends may differ
+	tenants := []string{"acme", "globex"}
+	wg.Add(len(tenants))
+	for i, tenant := range tenants {
+		go func() {
+			defer wg.Done()
+			got[i] = resolve(protographctx.WithTenant(context.Background(), tenant), tenant)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, [][]any{{"acme"}, {"globex"}}, got)
+	require.EqualValues(t, 5, transport.calls.Load())
+}
+
+// reversedKeyedTransport answers keyed loader calls with the entries of every
+// request but the one with id "skip", in reverse order.
+type reversedKeyedTransport struct{ calls atomic.Int32 }
+
+func (e *reversedKeyedTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	e.calls.Add(1)
+	in := req.Get(md.Input().Fields().ByName("batches")).List()
+	var idData []string
+	for i := in.Len() - 1; i >= 0; i-- {
+		id := in.Get(i).Message().Get(in.Get(i).Message().Descriptor().Fields().ByName("id")).String()
+		if id != "skip" {
+			idData = append(idData, id, "data-"+id)
+		}
+	}
+	return keyedResponse(md, idData...), nil
+}
+
+func TestBatchResolveAsync_CoalescesKeyedLoaderCallsByKey(t *testing.T) {
+	md := buildKeyedLoader(t)
+	reg := NewMockRegistry().RegisterBatchLoader("Obj", "byId", md).RegisterKeyedLoader(md)
+	transport := &reversedKeyedTransport{}
+	rt := NewRuntime(reg, transport, WithCoalescing(50*time.Millisecond, 0))
+
+	resolve := func(ids ...string) []any {
+		tasks := make([]executor.AsyncResolveTask, len(ids))
+		for i, id := range ids {
+			tasks[i] = executor.AsyncResolveTask{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": id}}
+		}
+		var values []any
+		for _, res := range rt.BatchResolveAsync(context.Background(), tasks) {
+			require.NoError(t, res.Error)
+			values = append(values, res.Value)
+		}
+		return values
+	}
+
+	var wg sync.WaitGroup
+	var a, b []any
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a = resolve("a1", "skip")
+	}()
+	go func() {
+		defer wg.Done()
+		b = resolve("b1", "b2")
+	}()
+	wg.Wait()
+	require.EqualValues(t, 1, transport.calls.Load())
+	require.Equal(t, []any{"data-a1", nil}, a)
+	require.Equal(t, []any{"data-b1", "data-b2"}, b)
+}
+
+// headerTransport echoes batch calls, recording the request IDs they carry and
+// returning a cache-control and a deprecation response header.
+type headerTransport struct {
+	echoTransport
+	requestIDs []string
+}
+
+func (h *headerTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	out, _ := metadata.FromOutgoingContext(ctx)
+	h.requestIDs = out.Get("graphql-request-id")
+	if c, ok := respheader.FromContext(ctx); ok {
+		c.Add(metadata.Pairs("cache-control", "max-age=60", "deprecation", "true"))
+	}
+	return h.echoTransport.Call(ctx, md, req)
+}
+
+func TestBatchResolveAsync_CoalescedCallsReachEveryCaller(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().RegisterBatchLoader("Query", "item", md)
+	transport := &headerTransport{}
+	rt := NewRuntime(reg, transport, WithCoalescing(50*time.Millisecond, 0))
+
+	names := [][]string{{"Cache-Control"}, {"Deprecation"}}
+	got := make([]http.Header, len(names))
+	var wg sync.WaitGroup
+	wg.Add(len(names))
+	for i := range names {
+		go func() {
+			defer wg.Done()
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "graphql-request-id", strconv.Itoa(i+1))
+			ctx, collector := respheader.NewContext(ctx, names[i])
+			res := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{{ObjectType: "Query", Field: "item", Args: map[string]any{"data": "d"}}})
+			require.NoError(t, res[0].Error)
+			got[i] = http.Header{}
+			collector.WriteTo(got[i])
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, transport.calls.Load())
+	require.ElementsMatch(t, []string{"1", "2"}, transport.requestIDs)
+	require.Equal(t, []http.Header{{"Cache-Control": {"max-age=60"}}, {"Deprecation": {"true"}}}, got)
+}
@@ -0,0 +1,105 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// buildPersonSource builds PersonSource{first_name, last_name, nickname, age}.
+func buildPersonSource(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name, json string, num int32, typ descriptorpb.FieldDescriptorProto_Type, optional bool) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:     protoString(name),
+			JsonName: protoString(json),
+			Number:   protoInt32(num),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if optional {
+			fd.Proto3Optional = new(bool)
+			*fd.Proto3Optional = true
+			fd.OneofIndex = protoInt32(0)
+		}
+		return fd
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("person.proto"),
+		Package: protoString("psvc"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("PersonSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("first_name", "firstName", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, false),
+				field("last_name", "lastName", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, false),
+				field("nickname", "nickname", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, true),
+				field("age", "age", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, false),
+			},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: protoString("_nickname")}},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+	files, err := protodesc.NewFiles(set)
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("person.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("PersonSource")
+}
+
+func TestResolveSync_ComputedField(t *testing.T) {
+	md := buildPersonSource(t)
+	fields := md.Fields()
+
+	reg := NewMockRegistry().
+		RegisterSourceField("Person", "firstName", fields.ByName("first_name")).
+		RegisterSourceField("Person", "lastName", fields.ByName("last_name")).
+		RegisterSourceField("Person", "nickname", fields.ByName("nickname")).
+		RegisterSourceField("Person", "age", fields.ByName("age")).
+		RegisterComputedField("Person", "fullName", `firstName + " " + lastName`).
+		RegisterComputedField("Person", "displayName", `coalesce(nickname, firstName)`).
+		RegisterComputedField("Person", "ageInMonths", `age * 12`).
+		RegisterComputedField("Person", "nicknameLength", `nickname + 1`).
+		RegisterComputedField("Person", "broken", `age / 0`)
+	rt := NewRuntime(reg, NewMockTransport())
+
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(fields.ByName("first_name"), protoreflect.ValueOfString("Ada"))
+	msg.Set(fields.ByName("last_name"), protoreflect.ValueOfString("Lovelace"))
+	msg.Set(fields.ByName("age"), protoreflect.ValueOfInt32(36))
+
+	ctx := context.Background()
+	resolve := func(field string) (any, error) {
+		return rt.ResolveSync(ctx, "Person", field, msg, nil)
+	}
+
+	v, err := resolve("fullName")
+	require.NoError(t, err)
+	require.Equal(t, "Ada Lovelace", v)
+
+	v, err = resolve("displayName")
+	require.NoError(t, err)
+	require.Equal(t, "Ada", v)
+
+	v, err = resolve("ageInMonths")
+	require.NoError(t, err)
+	require.Equal(t, int64(432), v)
+
+	// Unset optional siblings are null and propagate through operators
+	v, err = resolve("nicknameLength")
+	require.NoError(t, err)
+	require.Nil(t, v)
+
+	msg.Set(fields.ByName("nickname"), protoreflect.ValueOfString("Countess"))
+	v, err = resolve("displayName")
+	require.NoError(t, err)
+	require.Equal(t, "Countess", v)
+
+	_, err = resolve("broken")
+	require.ErrorContains(t, err, "division by zero")
+}
@@ -0,0 +1,204 @@
+package grpcrt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// buildConnectionFile builds the messages generated for `Blog.posts: [Post!]!
+// @connection`: the connection, edge and page info sources and
+// ResolveBlogPosts(offset, limit) returning the posts.
+func buildConnectionFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+		if typeName != "" {
+			f.TypeName = protoString(".conn." + typeName)
+		}
+		if repeated {
+			f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		return f
+	}
+	const (
+		str = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		i32 = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		bl  = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		msg = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: protoString(name), Field: fields}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("conn.proto"),
+		Package: protoString("conn"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("PostSource", field("id", 1, str, "", false)),
+			message("PostEdgeSource", field("node", 1, msg, "PostSource", false), field("cursor", 2, str, "", false)),
+			message("PageInfoSource",
+				field("hasNextPage", 1, bl, "", false), field("hasPreviousPage", 2, bl, "", false),
+				field("startCursor", 3, str, "", false), field("endCursor", 4, str, "", false)),
+			message("PostConnectionSource", field("edges", 1, msg, "PostEdgeSource", true), field("pageInfo", 2, msg, "PageInfoSource", false)),
+			message("ResolveBlogPostsRequest", field("id", 1, str, "", false), field("offset", 2, i32, "", false), field("limit", 3, i32, "", false)),
+			message("ResolveBlogPostsResponse", field("data", 1, msg, "PostSource", true)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: protoString("BlogService"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       protoString("ResolveBlogPosts"),
+				InputType:  protoString(".conn.ResolveBlogPostsRequest"),
+				OutputType: protoString(".conn.ResolveBlogPostsResponse"),
+			}},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("conn.proto")
+	require.NoError(t, err)
+	return fd
+}
+
+// cursorOf returns the cursor of post "p<offset>".
+func cursorOf(id string) string {
+	offset, _ := strconv.Atoi(strings.TrimPrefix(id, "p"))
+	return encodeCursor(offset)
+}
+
+func TestConnection_Pages(t *testing.T) {
+	file := buildConnectionFile(t)
+	msgs := file.Messages()
+	post, edge, pageInfo, conn := msgs.ByName("PostSource"), msgs.ByName("PostEdgeSource"), msgs.ByName("PageInfoSource"), msgs.ByName("PostConnectionSource")
+	md := file.Services().ByName("BlogService").Methods().ByName("ResolveBlogPosts")
+
+	reg := NewMockRegistry().
+		RegisterSingleResolver("Blog", "posts", md).
+		RegisterConnection("Blog", "posts", "PostConnection").
+		RegisterSourceMessage("PostConnection", conn).
+		RegisterMessageObjectType(edge.FullName(), "PostEdge").
+		RegisterMessageObjectType(pageInfo.FullName(), "PageInfo").
+		RegisterSourceField("Post", "id", post.Fields().ByName("id"))
+	for _, m := range []struct {
+		objectType string
+		desc       protoreflect.MessageDescriptor
+	}{{"PostConnection", conn}, {"PostEdge", edge}, {"PageInfo", pageInfo}} {
+		for i := 0; i < m.desc.Fields().Len(); i++ {
+			fd := m.desc.Fields().Get(i)
+			reg.RegisterSourceField(m.objectType, string(fd.Name()), fd)
+		}
+	}
+
+	// The backend serves posts p0 to p4 from offset on, at most limit of them
+	var calls []string
+	transport := transportFunc(func(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+		in := md.Input().Fields()
+		offset, limit := int(req.Get(in.ByName("offset")).Int()), int(req.Get(in.ByName("limit")).Int())
+		calls = append(calls, fmt.Sprintf("%d,%d", offset, limit))
+		resp := dynamicpb.NewMessage(md.Output())
+		data := resp.Mutable(md.Output().Fields().ByName("data")).List()
+		for i := offset; i < 5 && (limit == 0 || i < offset+limit); i++ {
+			p := dynamicpb.NewMessage(post)
+			p.Set(post.Fields().ByName("id"), protoreflect.ValueOfString(fmt.Sprintf("p%d", i)))
+			data.Append(protoreflect.ValueOfMessage(p))
+		}
+		return resp, nil
+	})
+	rt := NewRuntime(reg, transport).(*Runtime)
+	ctx := context.Background()
+
+	// describe renders a connection as its nodes, checking each cursor against
+	// the offset of its node, and the page info flags that are set
+	describe := func(v any) string {
+		t.Helper()
+		var ids []string
+		edges, err := rt.ResolveSync(ctx, "PostConnection", "edges", v, nil)
+		require.NoError(t, err)
+		for _, e := range edges.([]any) {
+			node, err := rt.ResolveSync(ctx, "PostEdge", "node", e, nil)
+			require.NoError(t, err)
+			id, err := rt.ResolveSync(ctx, "Post", "id", node, nil)
+			require.NoError(t, err)
+			cursor, err := rt.ResolveSync(ctx, "PostEdge", "cursor", e, nil)
+			require.NoError(t, err)
+			require.Equal(t, cursorOf(id.(string)), cursor)
+			ids = append(ids, id.(string))
+		}
+		info, err := rt.ResolveSync(ctx, "PostConnection", "pageInfo", v, nil)
+		require.NoError(t, err)
+		start, _ := rt.ResolveSync(ctx, "PageInfo", "startCursor", info, nil)
+		end, _ := rt.ResolveSync(ctx, "PageInfo", "endCursor", info, nil)
+		if len(ids) > 0 {
+			require.Equal(t, cursorOf(ids[0]), start)
+			require.Equal(t, cursorOf(ids[len(ids)-1]), end)
+		} else {
+			require.Nil(t, start)
+			require.Nil(t, end)
+		}
+		out := ids
+		for _, flag := range []string{"hasPreviousPage", "hasNextPage"} {
+			if set, _ := rt.ResolveSync(ctx, "PageInfo", flag, info, nil); set == true {
+				out = append(out, flag)
+			}
+		}
+		return strings.Join(out, " ")
+	}
+
+	for _, tc := range []struct {
+		args map[string]any
+		call string // offset,limit asked from the backend; empty for no call
+		want string
+	}{
+		{map[string]any{}, "0,0", "p0 p1 p2 p3 p4"},
+		{map[string]any{"first": 2}, "0,3", "p0 p1 hasNextPage"},
+		{map[string]any{"first": 2, "after": encodeCursor(1)}, "2,3", "p2 p3 hasNextPage"},
+		{map[string]any{"first": 2, "after": encodeCursor(3)}, "4,3", "p4"},
+		{map[string]any{"after": encodeCursor(2)}, "3,0", "p3 p4"},
+		{map[string]any{"last": 2, "before": encodeCursor(4)}, "2,2", "p2 p3 hasPreviousPage"},
+		{map[string]any{"last": 5, "before": encodeCursor(2)}, "0,2", "p0 p1"},
+		{map[string]any{"first": 3, "last": 1}, "0,4", "p2 hasPreviousPage hasNextPage"},
+		{map[string]any{"first": 1, "before": encodeCursor(3)}, "0,1", "p0 hasNextPage"},
+		{map[string]any{"first": 0}, "0,1", "hasNextPage"},
+		{map[string]any{"after": encodeCursor(1), "before": encodeCursor(2)}, "", ""},
+		// The largest page arguments still fit the int32 request fields
+		{map[string]any{"first": maxPage, "after": encodeCursor(maxPage - 1)}, fmt.Sprintf("%d,%d", maxPage, maxPage+1), ""},
+	} {
+		calls = nil
+		res := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{{ObjectType: "Blog", Field: "posts", Args: tc.args}})
+		require.NoError(t, res[0].Error, "%v", tc.args)
+		require.Equal(t, tc.want, describe(res[0].Value), "%v", tc.args)
+		if tc.call == "" {
+			require.Empty(t, calls, "%v", tc.args)
+		} else {
+			require.Equal(t, []string{tc.call}, calls, "%v", tc.args)
+		}
+	}
+
+	for _, args := range []map[string]any{
+		{"last": 1},
+		{"first": -1},
+		{"first": math.MaxInt32},
+		{"last": math.MaxInt32, "before": encodeCursor(1)},
+		{"after": "bogus"},
+		{"after": encodeCursor(math.MaxInt)},
+		{"first": 1, "after": encodeCursor(math.MaxInt32)},
+		{"before": encodeCursor(math.MaxInt32)},
+		{"before": EncodeGlobalID("Post", "1")},
+	} {
+		res := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{{ObjectType: "Blog", Field: "posts", Args: args}})
+		require.Error(t, res[0].Error, "%v", args)
+		require.Equal(t, errcode.BadUserInput, errcode.Of(res[0].Error), "%v", args)
+	}
+}
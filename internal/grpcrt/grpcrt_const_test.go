@@ -0,0 +1,43 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestResolveSync_ConstAndDefaultFields(t *testing.T) {
+	md := buildPersonSource(t)
+	fields := md.Fields()
+
+	reg := NewMockRegistry().
+		RegisterSourceField("Person", "nickname", fields.ByName("nickname")).
+		RegisterSourceFieldDefault("Person", "nickname", "anonymous").
+		RegisterConstField("Person", "kind", "person").
+		RegisterConstField("Query", "apiVersion", "v2")
+	rt := NewRuntime(reg, NewMockTransport())
+	ctx := context.Background()
+
+	// Const fields need no source message, even on root types
+	v, err := rt.ResolveSync(ctx, "Query", "apiVersion", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "v2", v)
+
+	msg := dynamicpb.NewMessage(md)
+	v, err = rt.ResolveSync(ctx, "Person", "kind", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "person", v)
+
+	// Defaults apply only while the source field is unset
+	v, err = rt.ResolveSync(ctx, "Person", "nickname", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "anonymous", v)
+
+	msg.Set(fields.ByName("nickname"), protoreflect.ValueOfString("Countess"))
+	v, err = rt.ResolveSync(ctx, "Person", "nickname", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Countess", v)
+}
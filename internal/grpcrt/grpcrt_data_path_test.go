@@ -0,0 +1,87 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/cache"
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// buildDataPathResolver builds Resolve(Req{}) -> Resp{ Result result = 1 { repeated string names = 1 } }
+func buildDataPathResolver(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("data_path.proto"),
+		Package: protoString("dp"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Req")},
+			{
+				Name:  protoString("Resp"),
+				Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("result"), JsonName: protoString("result"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".dp.Resp.Result")}},
+				NestedType: []*descriptorpb.DescriptorProto{{Name: protoString("Result"), Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: protoString("names"), JsonName: protoString("names"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				}}},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("S"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("Resolve"), InputType: protoString(".dp.Req"), OutputType: protoString(".dp.Resp")}}}},
+		Syntax:  protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("data_path.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("S").Methods().ByName("Resolve")
+}
+
+func dataPathRegistry(md protoreflect.MethodDescriptor) *MockRegistry {
+	resultField := md.Output().Fields().ByName("result")
+	return NewMockRegistry().
+		RegisterSingleResolver("Query", "names", md).
+		RegisterResponseDataPath(md.Output(), resultField, resultField.Message().Fields().ByName("names"))
+}
+
+func TestDataPath_ReadsResultFromNestedField(t *testing.T) {
+	md := buildDataPathResolver(t)
+	resultField := md.Output().Fields().ByName("result")
+	out := dynamicpb.NewMessage(md.Output())
+	names := out.Mutable(resultField).Message().Mutable(resultField.Message().Fields().ByName("names")).List()
+	names.Append(protoreflect.ValueOfString("ada"))
+	names.Append(protoreflect.ValueOfString("grace"))
+
+	// The second response leaves the result unset altogether
+	mt := NewMockTransport(out, dynamicpb.NewMessage(md.Output()))
+	rt := NewRuntime(dataPathRegistry(md), mt)
+
+	task := []executor.AsyncResolveTask{{ObjectType: "Query", Field: "names"}}
+	res := rt.BatchResolveAsync(context.Background(), task)
+	require.NoError(t, res[0].Error)
+	require.Equal(t, []any{"ada", "grace"}, res[0].Value)
+
+	res = rt.BatchResolveAsync(context.Background(), task)
+	require.NoError(t, res[0].Error)
+	require.Equal(t, []any{}, res[0].Value)
+}
+
+func TestDataPath_FieldCacheRoundTrips(t *testing.T) {
+	md := buildDataPathResolver(t)
+	resultField := md.Output().Fields().ByName("result")
+	out := dynamicpb.NewMessage(md.Output())
+	out.Mutable(resultField).Message().Mutable(resultField.Message().Fields().ByName("names")).List().Append(protoreflect.ValueOfString("ada"))
+
+	mt := NewMockTransport(out)
+	reg := dataPathRegistry(md).RegisterCachePolicy("Query", "names", CachePolicy{TTL: time.Minute})
+	rt := NewRuntime(reg, mt, WithFieldCache(cache.NewMemory(16)))
+
+	task := []executor.AsyncResolveTask{{ObjectType: "Query", Field: "names"}}
+	require.Equal(t, []any{"ada"}, rt.BatchResolveAsync(context.Background(), task)[0].Value)
+	require.Equal(t, []any{"ada"}, rt.BatchResolveAsync(context.Background(), task)[0].Value)
+	require.Len(t, mt.Calls(), 1)
+}
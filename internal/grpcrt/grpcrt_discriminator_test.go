@@ -0,0 +1,83 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// buildFeedItem builds VideoSource{title, seconds} and the flattened
+// FeedItemSource{kind, title, seconds} of a @discriminator union.
+func buildFeedItem(t *testing.T) (video, item protoreflect.MessageDescriptor) {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name: protoString(name), JsonName: protoString(name), Number: protoInt32(number), Type: typ.Enum(),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("feed.proto"),
+		Package: protoString("feed"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("VideoSource"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("title", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("seconds", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			}},
+			{Name: protoString("FeedItemSource"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("kind", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("seconds", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("title", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}},
+		},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("feed.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("VideoSource"), fd.Messages().ByName("FeedItemSource")
+}
+
+func TestDiscriminator_MembersReadFlattenedMessage(t *testing.T) {
+	video, item := buildFeedItem(t)
+	reg := NewMockRegistry().
+		RegisterSourceMessage("Video", video).
+		RegisterSourceField("Video", "title", video.Fields().ByName("title")).
+		RegisterSourceField("Video", "seconds", video.Fields().ByName("seconds")).
+		RegisterTypeDiscriminator("FeedItem", TypeDiscriminator{Field: "kind", Types: map[string]string{"POST": "Post", "VIDEO": "Video"}})
+	rt := NewRuntime(reg, nil).(*Runtime)
+	ctx := context.Background()
+
+	msg := dynamicpb.NewMessage(item)
+	msg.Set(item.Fields().ByName("kind"), protoreflect.ValueOfString("VIDEO"))
+	msg.Set(item.Fields().ByName("title"), protoreflect.ValueOfString("Intro"))
+
+	// The flattened message is its own concrete value
+	v, err := rt.ResolveUnionConcreteValue(ctx, "FeedItem", msg)
+	require.NoError(t, err)
+	typ, err := rt.ResolveType(ctx, "FeedItem", v)
+	require.NoError(t, err)
+	require.Equal(t, "Video", typ)
+
+	// Member fields are read by name from the flattened message
+	title, err := rt.ResolveSync(ctx, "Video", "title", v, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Intro", title)
+	seconds, err := rt.ResolveSync(ctx, "Video", "seconds", v, nil)
+	require.NoError(t, err)
+	require.Nil(t, seconds)
+
+	got, ok := rt.CompleteLeafObject(ctx, "Video", v, []executor.LeafField{
+		{ResponseName: "title", Field: "title"},
+		{ResponseName: "seconds", Field: "seconds"},
+	})
+	require.True(t, ok)
+	require.JSONEq(t, `{"title":"Intro","seconds":null}`, string(got))
+}
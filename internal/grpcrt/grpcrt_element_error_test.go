@@ -0,0 +1,119 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// buildElementErrorLoader builds BatchLoad(BatchReq{ batches: Item{id} }) ->
+// BatchResp{ batches: ItemOut{data, Error error} }
+func buildElementErrorLoader(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("element_error.proto"),
+		Package: protoString("ee"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Item"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("id"), JsonName: protoString("id"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()}}},
+			{
+				Name: protoString("ItemOut"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: protoString("data"), JsonName: protoString("data"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+					{Name: protoString("error"), JsonName: protoString("error"), Number: protoInt32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".ee.ItemOut.Error")},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{Name: protoString("Error"), Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: protoString("code"), JsonName: protoString("code"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()},
+					{Name: protoString("message"), JsonName: protoString("message"), Number: protoInt32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				}}},
+			},
+			{Name: protoString("BatchReq"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".ee.Item")}}},
+			{Name: protoString("BatchResp"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".ee.ItemOut")}}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("LoaderService"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("BatchLoad"), InputType: protoString(".ee.BatchReq"), OutputType: protoString(".ee.BatchResp")}}}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("element_error.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("LoaderService").Methods().ByName("BatchLoad")
+}
+
+// elementResult is one element of a batch response: data, or an error when code or message is set.
+type elementResult struct {
+	data    string
+	code    codes.Code
+	message string
+}
+
+func elementResponse(md protoreflect.MethodDescriptor, elems ...elementResult) protoreflect.Message {
+	out := dynamicpb.NewMessage(md.Output())
+	of := md.Output().Fields().ByName("batches")
+	itemDesc := of.Message()
+	errField := itemDesc.Fields().ByName("error")
+	lst := out.Mutable(of).List()
+	for _, e := range elems {
+		it := dynamicpb.NewMessage(itemDesc)
+		if e.code != codes.OK || e.message != "" {
+			errMsg := it.Mutable(errField).Message()
+			errMsg.Set(errField.Message().Fields().ByName("code"), protoreflect.ValueOfInt32(int32(e.code)))
+			errMsg.Set(errField.Message().Fields().ByName("message"), protoreflect.ValueOfString(e.message))
+		} else {
+			it.Set(itemDesc.Fields().ByName("data"), protoreflect.ValueOfString(e.data))
+		}
+		lst.Append(protoreflect.ValueOfMessage(it))
+	}
+	return out
+}
+
+func TestBatchElementError_FailsOnlyThatElement(t *testing.T) {
+	md := buildElementErrorLoader(t)
+	mt := NewMockTransport(elementResponse(md,
+		elementResult{data: "A"},
+		elementResult{code: codes.PermissionDenied, message: "u2 is private"},
+		elementResult{message: "u3 is corrupt"},
+	))
+	rt := NewRuntime(NewMockRegistry().RegisterBatchLoader("Obj", "byId", md), mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u1"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u2"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u3"}},
+	})
+	require.NoError(t, res[0].Error)
+	require.Equal(t, "A", res[0].Value)
+
+	require.Error(t, res[1].Error)
+	require.Contains(t, res[1].Error.Error(), "u2 is private")
+	require.Equal(t, errcode.Forbidden, errcode.Of(res[1].Error))
+
+	require.Error(t, res[2].Error)
+	require.Contains(t, res[2].Error.Error(), "u3 is corrupt")
+	require.Equal(t, errcode.DownstreamServiceError, errcode.Of(res[2].Error))
+}
+
+func TestBatchElementError_OnErrorPolicyApplies(t *testing.T) {
+	md := buildElementErrorLoader(t)
+	mt := NewMockTransport(elementResponse(md, elementResult{data: "A"}, elementResult{code: codes.NotFound, message: "gone"}))
+	reg := NewMockRegistry().
+		RegisterBatchLoader("Obj", "byId", md).
+		RegisterErrorPolicy("Obj", "byId", ErrorPolicy{Action: ErrorDefault, Value: "fallback"})
+	rt := NewRuntime(reg, mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u1"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u2"}},
+	})
+	require.Equal(t, "A", res[0].Value)
+	require.NoError(t, res[1].Error)
+	require.Equal(t, "fallback", res[1].Value)
+}
@@ -0,0 +1,45 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+func TestEnumValues_MappedBothWays(t *testing.T) {
+	md := buildLeafMessage(t)
+	color := md.Fields().ByName("color")
+	red := color.Enum().Values().ByName("RED")
+	reg := NewMockRegistry().
+		RegisterSourceField("Item", "color", color).
+		RegisterEnumValue(red, "CRIMSON")
+	rt := NewRuntime(reg, nil).(*Runtime)
+	ctx := context.Background()
+
+	// Responses carry the GraphQL name
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(color, protoreflect.ValueOfEnum(red.Number()))
+	v, err := rt.ResolveSync(ctx, "Item", "color", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "CRIMSON", v)
+	got, ok := rt.CompleteLeafObject(ctx, "Item", msg, []executor.LeafField{{ResponseName: "color", Field: "color", Type: "Color"}})
+	require.True(t, ok)
+	require.JSONEq(t, `{"color":"CRIMSON"}`, string(got))
+
+	// Requests are built from it
+	req := dynamicpb.NewMessage(md)
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"color": "CRIMSON"}))
+	require.Equal(t, red.Number(), req.Get(color).Enum())
+
+	// Values without a mapping keep their proto name
+	req = dynamicpb.NewMessage(md)
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"color": "RED"}))
+	require.Equal(t, red.Number(), req.Get(color).Enum())
+	msg.Set(color, protoreflect.ValueOfEnum(0))
+	require.Equal(t, "COLOR_UNSPECIFIED", rt.handleValue(color, msg.Get(color)))
+}
@@ -0,0 +1,104 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+func TestBatchResolveAsync_FallbackServesFailedTasks(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "price", md).
+		RegisterFallbackResolver("Query", "price", md).
+		RegisterFallbackResolver("Query", "price", md)
+	transport := NewMockTransportWithErrors(
+		[]protoreflect.Message{nil, nil, batchResponse(md, "from primary")},
+		[]error{status.Error(codes.Unavailable, "cache down"), status.Error(codes.Unavailable, "still down")},
+	)
+	rt := NewRuntime(reg, transport)
+	x := &executor.ResponseExtensions{}
+	ctx := executor.ContextWithExtensions(context.Background(), x)
+
+	results := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "price", Args: map[string]any{"data": "a"}},
+	})
+	require.NoError(t, results[0].Error)
+	require.Equal(t, "from primary", results[0].Value)
+	require.Len(t, transport.Calls(), 3)
+	require.Equal(t, []any{map[string]any{
+		"field":  "Query.price",
+		"method": string(md.FullName()),
+		"served": 1,
+	}}, x.Get("fallbacks"))
+}
+
+func TestBatchResolveAsync_FallbackSkipsCallerErrors(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "price", md).
+		RegisterFallbackResolver("Query", "price", md)
+	transport := NewMockTransportWithErrors(nil, []error{status.Error(codes.InvalidArgument, "bad currency")})
+	rt := NewRuntime(reg, transport)
+
+	results := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "price", Args: map[string]any{"data": "a"}},
+	})
+	require.Error(t, results[0].Error)
+	require.Equal(t, errcode.BadUserInput, errcode.Of(results[0].Error))
+	require.Len(t, transport.Calls(), 1)
+}
+
+func TestBatchResolveAsync_FallbackRetriesBackendFailuresOnly(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "price", md).
+		RegisterFallbackResolver("Query", "price", md)
+
+	for _, tc := range []struct {
+		code  codes.Code
+		retry bool
+	}{
+		{codes.Unavailable, true},
+		{codes.ResourceExhausted, true},
+		{codes.DeadlineExceeded, true},
+		{codes.NotFound, false},
+		{codes.AlreadyExists, false},
+		{codes.FailedPrecondition, false},
+		{codes.Aborted, false},
+		{codes.Internal, false},
+	} {
+		transport := NewMockTransportWithErrors(
+			[]protoreflect.Message{nil, batchResponse(md, "from fallback")},
+			[]error{status.Error(tc.code, "failed")},
+		)
+		rt := NewRuntime(reg, transport)
+		results := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+			{ObjectType: "Query", Field: "price", Args: map[string]any{"data": "a"}},
+		})
+		if tc.retry {
+			require.NoError(t, results[0].Error, tc.code.String())
+			require.Len(t, transport.Calls(), 2, tc.code.String())
+		} else {
+			require.Error(t, results[0].Error, tc.code.String())
+			require.Len(t, transport.Calls(), 1, tc.code.String())
+		}
+	}
+
+	// Nor is a call timed out with the request, which has no time left
+	transport := NewMockTransportWithErrors(nil, []error{status.Error(codes.DeadlineExceeded, "timeout")})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := NewRuntime(reg, transport).BatchResolveAsync(ctx, []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "price", Args: map[string]any{"data": "a"}},
+	})
+	require.Error(t, results[0].Error)
+	require.Len(t, transport.Calls(), 1)
+}
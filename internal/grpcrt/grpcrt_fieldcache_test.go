@@ -0,0 +1,110 @@
+package grpcrt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/cache"
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// batchResponse builds a BatchResp whose batches carry the given data strings.
+func batchResponse(md protoreflect.MethodDescriptor, data ...string) protoreflect.Message {
+	resp := dynamicpb.NewMessage(md.Output())
+	bf := md.Output().Fields().ByName("batches")
+	lst := resp.Mutable(bf).List()
+	for _, d := range data {
+		item := dynamicpb.NewMessage(bf.Message())
+		item.Set(bf.Message().Fields().ByName("data"), protoreflect.ValueOfString(d))
+		lst.Append(protoreflect.ValueOfMessage(item))
+	}
+	return resp
+}
+
+func TestBatchResolveAsync_FieldCacheServesFreshAndStaleResults(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "rate", md).
+		RegisterCachePolicy("Query", "rate", CachePolicy{TTL: time.Minute, StaleWhileRevalidate: time.Hour})
+	transport := NewMockTransportWithErrors(
+		[]protoreflect.Message{batchResponse(md, "v1"), batchResponse(md, "v2"), nil, batchResponse(md, "v3")},
+		[]error{nil, nil, errors.New("backend down")},
+	)
+	rt := NewRuntime(reg, transport, WithFieldCache(cache.NewMemory(10))).(*Runtime)
+	now := time.Unix(1_700_000_000, 0)
+	rt.now = func() time.Time { return now }
+
+	resolve := func() (executor.AsyncResolveResult, any) {
+		x := &executor.ResponseExtensions{}
+		ctx := executor.ContextWithExtensions(context.Background(), x)
+		res := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{
+			{ObjectType: "Query", Field: "rate", Args: map[string]any{"data": "EUR"}},
+		})
+		return res[0], x.Get("cache")
+	}
+	status := func(s string) map[string]any { return map[string]any{"Query.rate": map[string]int{s: 1}} }
+
+	res, ext := resolve()
+	require.Equal(t, "v1", res.Value)
+	require.Equal(t, status(CacheMiss), ext)
+	require.Len(t, transport.Calls(), 1)
+
+	now = now.Add(30 * time.Second)
+	res, ext = resolve()
+	require.Equal(t, "v1", res.Value)
+	require.Equal(t, status(CacheHit), ext)
+	require.Len(t, transport.Calls(), 1)
+
+	// Past the TTL the stale value is served while a background call refreshes it
+	now = now.Add(time.Minute)
+	res, ext = resolve()
+	require.Equal(t, "v1", res.Value)
+	require.Equal(t, status(CacheStale), ext)
+	require.Eventually(t, func() bool {
+		v, _, ok := rt.readCache(context.Background(), md.Output().Fields().ByName("batches").Message(), rt.cacheKey(context.Background(), executor.AsyncResolveTask{ObjectType: "Query", Field: "rate", Args: map[string]any{"data": "EUR"}}))
+		return ok && v == "v2"
+	}, time.Second, time.Millisecond)
+
+	res, ext = resolve()
+	require.Equal(t, "v2", res.Value)
+	require.Equal(t, status(CacheHit), ext)
+
+	// Past the stale window the RPC is called again; errors are not cached
+	now = now.Add(2 * time.Hour)
+	res, ext = resolve()
+	require.EqualError(t, res.Error, "backend down")
+	require.Equal(t, status(CacheMiss), ext)
+	res, _ = resolve()
+	require.Equal(t, "v3", res.Value)
+	require.Len(t, transport.Calls(), 4)
+}
+
+func TestBatchResolveAsync_FieldCacheKeysByRequest(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "rate", md).
+		RegisterCachePolicy("Query", "rate", CachePolicy{TTL: time.Minute})
+	transport := NewMockTransport(batchResponse(md, "eur"), batchResponse(md, "usd"))
+	rt := NewRuntime(reg, transport, WithFieldCache(cache.NewMemory(10)))
+
+	task := func(currency string) executor.AsyncResolveTask {
+		return executor.AsyncResolveTask{ObjectType: "Query", Field: "rate", Args: map[string]any{"data": currency}}
+	}
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{task("EUR")})
+	require.Equal(t, "eur", res[0].Value)
+
+	// Only the uncached task is sent to the backend
+	res = rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{task("EUR"), task("USD")})
+	require.Equal(t, "eur", res[0].Value)
+	require.Equal(t, "usd", res[1].Value)
+	calls := transport.Calls()
+	require.Len(t, calls, 2)
+	batches := calls[1].Request.ProtoReflect()
+	require.Equal(t, 1, batches.Get(md.Input().Fields().ByName("batches")).List().Len())
+}
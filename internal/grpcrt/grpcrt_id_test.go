@@ -0,0 +1,115 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+// buildIDMessage builds ids.UserSource with an int64 id and a bytes uuid, and
+// the int64 visits and bytes avatar that are not IDs.
+func buildIDMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("ids.proto"),
+		Package: protoString("ids"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("UserSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("uuid", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				field("visits", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("avatar", 4, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+			},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("ids.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("UserSource")
+}
+
+func TestIDs_Int64(t *testing.T) {
+	md := buildIDMessage(t)
+	id := md.Fields().ByName("id")
+	reg := NewMockRegistry().
+		RegisterSourceField("User", "id", id).
+		RegisterIDType(IDInt64).
+		RegisterIDField(id.FullName())
+	rt := NewRuntime(reg, nil).(*Runtime)
+	ctx := context.Background()
+
+	req := dynamicpb.NewMessage(md)
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"id": "9007199254740993"}))
+	require.Equal(t, int64(9007199254740993), req.Get(id).Int())
+
+	err := rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), map[string]any{"id": "abc"})
+	require.Error(t, err)
+	require.Equal(t, errcode.BadUserInput, errcode.Of(err))
+
+	// Strings are not parsed into int64 fields holding no ID
+	err = rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), map[string]any{"visits": "12"})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "invalid ID")
+
+	v, err := rt.ResolveSync(ctx, "User", "id", req, nil)
+	require.NoError(t, err)
+	s, err := rt.SerializeLeafValue(ctx, "ID", v)
+	require.NoError(t, err)
+	require.Equal(t, "9007199254740993", s)
+
+	got, ok := rt.CompleteLeafObject(ctx, "User", req, []executor.LeafField{{ResponseName: "id", Field: "id", Type: "ID"}})
+	require.True(t, ok)
+	require.JSONEq(t, `{"id":"9007199254740993"}`, string(got))
+}
+
+func TestIDs_UUID(t *testing.T) {
+	md := buildIDMessage(t)
+	uuid := md.Fields().ByName("uuid")
+	reg := NewMockRegistry().
+		RegisterSourceField("User", "uuid", uuid).
+		RegisterIDType(IDUUID).
+		RegisterIDField(uuid.FullName())
+	rt := NewRuntime(reg, nil).(*Runtime)
+	ctx := context.Background()
+
+	const canonical = "123e4567-e89b-12d3-a456-426614174000"
+	req := dynamicpb.NewMessage(md)
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"uuid": "123E4567-E89B-12D3-A456-426614174000"}))
+	require.Equal(t, []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}, req.Get(uuid).Bytes())
+
+	err := rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), map[string]any{"uuid": "123e4567"})
+	require.Error(t, err)
+	require.Equal(t, errcode.BadUserInput, errcode.Of(err))
+
+	// Bytes fields holding no ID take base64, as they are written in responses
+	avatar := md.Fields().ByName("avatar")
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"avatar": "iVBORw=="}))
+	require.Equal(t, []byte{0x89, 'P', 'N', 'G'}, req.Get(avatar).Bytes())
+	err = rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), map[string]any{"avatar": canonical})
+	require.Error(t, err)
+	require.Equal(t, errcode.BadUserInput, errcode.Of(err))
+
+	got, ok := rt.CompleteLeafObject(ctx, "User", req, []executor.LeafField{{ResponseName: "id", Field: "uuid", Type: "ID"}})
+	require.True(t, ok)
+	require.JSONEq(t, `{"id":"`+canonical+`"}`, string(got))
+
+	// Global IDs encode the canonical string
+	reg.RegisterGlobalIDField("User", "uuid")
+	v, err := rt.ResolveSync(ctx, "User", "uuid", req, nil)
+	require.NoError(t, err)
+	require.Equal(t, EncodeGlobalID("User", canonical), v)
+}
@@ -0,0 +1,78 @@
+package grpcrt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+)
+
+// buildNumbersMessage builds nums.Req with a field of each numeric kind.
+func buildNumbersMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	ids := field("ids", 6, descriptorpb.FieldDescriptorProto_TYPE_INT64)
+	ids.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("nums.proto"),
+		Package: protoString("nums"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("Req"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("count", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("id", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("serial", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+				field("ratio", 4, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+				field("name", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				ids,
+			},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("nums.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("Req")
+}
+
+func TestRequestMapping_JSONNumber(t *testing.T) {
+	md := buildNumbersMessage(t)
+	fields := md.Fields()
+	rt := NewRuntime(NewMockRegistry(), nil).(*Runtime)
+
+	req := dynamicpb.NewMessage(md)
+	require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{
+		"count":  json.Number("-42"),
+		"id":     json.Number("9007199254740993"),
+		"serial": json.Number("18446744073709551615"),
+		"ratio":  json.Number("0.1"),
+		"ids":    []any{json.Number("9223372036854775807"), json.Number("-9007199254740993")},
+	}))
+	require.Equal(t, int32(-42), int32(req.Get(fields.ByName("count")).Int()))
+	require.Equal(t, int64(9007199254740993), req.Get(fields.ByName("id")).Int())
+	require.Equal(t, uint64(18446744073709551615), req.Get(fields.ByName("serial")).Uint())
+	require.Equal(t, 0.1, req.Get(fields.ByName("ratio")).Float())
+	ids := req.Get(fields.ByName("ids")).List()
+	require.Equal(t, int64(9223372036854775807), ids.Get(0).Int())
+	require.Equal(t, int64(-9007199254740993), ids.Get(1).Int())
+
+	for _, data := range []map[string]any{
+		{"count": json.Number("2147483648")},
+		{"id": json.Number("1.5")},
+		{"serial": json.Number("-1")},
+	} {
+		err := rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), data)
+		require.Error(t, err, "%v", data)
+		require.Equal(t, errcode.BadUserInput, errcode.Of(err))
+	}
+	require.Error(t, rt.setMessageFieldsByJSON(dynamicpb.NewMessage(md), map[string]any{"name": json.Number("1")}))
+}
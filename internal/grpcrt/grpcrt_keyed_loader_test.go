@@ -0,0 +1,126 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// buildKeyedLoader builds BatchLoad(BatchReq{ batches: Item{id} }) -> BatchResp{ batches: ItemOut{data, id} }
+func buildKeyedLoader(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("keyed_loader.proto"),
+		Package: protoString("k"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Item"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("id"), JsonName: protoString("id"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()}}},
+			{Name: protoString("ItemOut"), Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: protoString("data"), JsonName: protoString("data"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: protoString("id"), JsonName: protoString("id"), Number: protoInt32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			}},
+			{Name: protoString("BatchReq"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".k.Item")}}},
+			{Name: protoString("BatchResp"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".k.ItemOut")}}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("LoaderService"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("BatchLoad"), InputType: protoString(".k.BatchReq"), OutputType: protoString(".k.BatchResp")}}}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("keyed_loader.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("LoaderService").Methods().ByName("BatchLoad")
+}
+
+// keyedResponse answers with one item per id/data pair, in the given order.
+func keyedResponse(md protoreflect.MethodDescriptor, idData ...string) protoreflect.Message {
+	out := dynamicpb.NewMessage(md.Output())
+	of := md.Output().Fields().ByName("batches")
+	itemDesc := of.Message()
+	lst := out.Mutable(of).List()
+	for i := 0; i < len(idData); i += 2 {
+		it := dynamicpb.NewMessage(itemDesc)
+		it.Set(itemDesc.Fields().ByName("id"), protoreflect.ValueOfString(idData[i]))
+		it.Set(itemDesc.Fields().ByName("data"), protoreflect.ValueOfString(idData[i+1]))
+		lst.Append(protoreflect.ValueOfMessage(it))
+	}
+	return out
+}
+
+func TestKeyedLoader_JoinsUnorderedAndDeduplicatedResults(t *testing.T) {
+	md := buildKeyedLoader(t)
+	// u2 before u1, u1 answered once for two tasks, u3 left out
+	mt := NewMockTransport(keyedResponse(md, "u2", "B", "u1", "A"))
+	reg := NewMockRegistry().RegisterBatchLoader("Obj", "byId", md).RegisterKeyedLoader(md)
+	rt := NewRuntime(reg, mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u1"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u2"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u3"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u1"}},
+	})
+	require.Len(t, mt.Calls(), 1)
+	require.Equal(t, "A", res[0].Value)
+	require.Equal(t, "B", res[1].Value)
+	require.NoError(t, res[2].Error)
+	require.Nil(t, res[2].Value)
+	require.Equal(t, "A", res[3].Value)
+}
+
+func TestKeyedLoader_PositionalWithoutKeyedMode(t *testing.T) {
+	md := buildKeyedLoader(t)
+	mt := NewMockTransport(keyedResponse(md, "u2", "B", "u1", "A"))
+	rt := NewRuntime(NewMockRegistry().RegisterBatchLoader("Obj", "byId", md), mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u1"}},
+		{ObjectType: "Obj", Field: "byId", Args: map[string]any{"id": "u2"}},
+	})
+	require.Equal(t, "B", res[0].Value)
+	require.Equal(t, "A", res[1].Value)
+}
+
+func TestJoinKey_EncodesByKind(t *testing.T) {
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("join_key.proto"),
+		Package: protoString("jk"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Int"), Field: []*descriptorpb.FieldDescriptorProto{field("k", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32)}},
+			{Name: protoString("Long"), Field: []*descriptorpb.FieldDescriptorProto{field("k", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64)}},
+			{Name: protoString("Str"), Field: []*descriptorpb.FieldDescriptorProto{field("k", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)}},
+			{Name: protoString("Pair"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("a", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("b", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			}},
+		},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("join_key.proto")
+	require.NoError(t, err)
+	key := func(name string, values ...protoreflect.Value) string {
+		md := fd.Messages().ByName(protoreflect.Name(name))
+		msg := dynamicpb.NewMessage(md)
+		for i, v := range values {
+			msg.Set(md.Fields().Get(i), v)
+		}
+		return joinKey(msg, md.Fields())
+	}
+
+	require.Equal(t, key("Int", protoreflect.ValueOfInt32(1)), key("Long", protoreflect.ValueOfInt64(1)))
+	require.NotEqual(t, key("Int", protoreflect.ValueOfInt32(1)), key("Str", protoreflect.ValueOfString("1")))
+	require.NotEqual(t,
+		key("Pair", protoreflect.ValueOfString("a\x00"), protoreflect.ValueOfString("b")),
+		key("Pair", protoreflect.ValueOfString("a"), protoreflect.ValueOfString("\x00b")))
+}
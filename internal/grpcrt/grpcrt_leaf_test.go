@@ -0,0 +1,142 @@
+package grpcrt
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hanpama/protograph/internal/executor"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func buildLeafMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("leaf.proto"),
+		Package: protoString("leaf"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: protoString("Color"), Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: protoString("COLOR_UNSPECIFIED"), Number: protoInt32(0)},
+			{Name: protoString("RED"), Number: protoInt32(1)},
+		}}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("ItemSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("b", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+				field("i32", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("u64", 3, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+				field("f32", 4, descriptorpb.FieldDescriptorProto_TYPE_FLOAT),
+				field("f64", 5, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+				field("s", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("bs", 7, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+				{Name: protoString("color"), JsonName: protoString("color"), Number: protoInt32(8), Type: descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum(), TypeName: protoString(".leaf.Color")},
+				field("id", 9, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("unset", 10, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+	fd, err := files.FindFileByPath("leaf.proto")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	return fd.Messages().ByName("ItemSource")
+}
+
+// CompleteLeafObject must produce the JSON of ResolveSync + SerializeLeafValue.
+func Test_10_1_CompleteLeafObject_MatchesResolveSync(t *testing.T) {
+	md := buildLeafMessage(t)
+	msg := dynamicpb.NewMessage(md)
+	set := func(n string, v protoreflect.Value) { msg.Set(md.Fields().ByName(protoreflect.Name(n)), v) }
+	set("b", protoreflect.ValueOfBool(true))
+	set("i32", protoreflect.ValueOfInt32(-7))
+	set("u64", protoreflect.ValueOfUint64(math.MaxUint64))
+	set("f32", protoreflect.ValueOfFloat32(0.1))
+	set("f64", protoreflect.ValueOfFloat64(1e-7))
+	set("s", protoreflect.ValueOfString("<a href=\"x\">& \x01"))
+	set("bs", protoreflect.ValueOfBytes([]byte{0xff, 0x00}))
+	set("color", protoreflect.ValueOfEnum(1))
+	set("id", protoreflect.ValueOfString("42"))
+
+	reg := NewMockRegistry()
+	for _, name := range []string{"b", "i32", "u64", "f32", "f64", "s", "bs", "color", "id", "unset"} {
+		reg.RegisterSourceField("Item", name, md.Fields().ByName(protoreflect.Name(name)))
+	}
+	reg.RegisterGlobalIDField("Item", "id")
+	reg.RegisterConstField("Item", "kind", "ITEM")
+	reg.RegisterSourceFieldDefault("Item", "unset", "fallback")
+	rt := NewRuntime(reg, nil).(*Runtime)
+
+	fields := []executor.LeafField{{ResponseName: "__typename", Field: "__typename"}}
+	want := map[string]any{"__typename": "Item"}
+	// Sorted, as the executor passes fields in response name order
+	for _, name := range []string{"b", "bs", "color", "f32", "f64", "i32", "id", "kind", "s", "u64", "unset"} {
+		fields = append(fields, executor.LeafField{ResponseName: "a_" + name, Field: name})
+		v, err := rt.ResolveSync(context.Background(), "Item", name, msg, nil)
+		if err != nil {
+			t.Fatalf("ResolveSync %s: %v", name, err)
+		}
+		if want["a_"+name], err = rt.SerializeLeafValue(context.Background(), "", v); err != nil {
+			t.Fatalf("SerializeLeafValue %s: %v", name, err)
+		}
+	}
+	wantJSON, _ := json.Marshal(want)
+
+	got, ok := rt.CompleteLeafObject(context.Background(), "Item", msg, fields)
+	if !ok {
+		t.Fatal("CompleteLeafObject fell back")
+	}
+	if string(got) != string(wantJSON) {
+		t.Fatalf("got  %s\nwant %s", got, wantJSON)
+	}
+}
+
+func Test_10_2_CompleteLeafObject_UnsetNonNull_FallsBack(t *testing.T) {
+	md := buildLeafMessage(t)
+	msg := dynamicpb.NewMessage(md)
+	reg := NewMockRegistry().RegisterSourceField("Item", "s", md.Fields().ByName("s"))
+	rt := NewRuntime(reg, nil).(*Runtime)
+
+	got, ok := rt.CompleteLeafObject(context.Background(), "Item", msg, []executor.LeafField{{ResponseName: "s", Field: "s"}})
+	if !ok || string(got) != `{"s":null}` {
+		t.Fatalf("nullable: got %s, %v", got, ok)
+	}
+	if _, ok := rt.CompleteLeafObject(context.Background(), "Item", msg, []executor.LeafField{{ResponseName: "s", Field: "s", NonNull: true}}); ok {
+		t.Fatal("expected fallback for unset NonNull field")
+	}
+	if _, ok := rt.CompleteLeafObject(context.Background(), "Item", map[string]any{}, nil); ok {
+		t.Fatal("expected fallback for non-message source")
+	}
+}
+
+func Test_10_3_AppendJSON_MatchesEncodingJSON(t *testing.T) {
+	for _, s := range []string{"", "plain", "quote\" back\\slash", "<>&", "\n\r\t\b\f\x00\x1f", "  ", "bad\xffutf8", "한글 ✓"} {
+		want, _ := json.Marshal(s)
+		if got := appendJSONString(nil, s); string(got) != string(want) {
+			t.Errorf("string %q: got %s want %s", s, got, want)
+		}
+	}
+	for _, f := range []float64{0, 1, -1.5, 1e-7, 1e21, 123456789.125, math.SmallestNonzeroFloat64, math.MaxFloat64} {
+		want, _ := json.Marshal(f)
+		if got, ok := appendJSONFloat(nil, f, 64); !ok || string(got) != string(want) {
+			t.Errorf("float64 %v: got %s want %s", f, got, want)
+		}
+		want, _ = json.Marshal(float32(f))
+		if got, ok := appendJSONFloat(nil, float64(float32(f)), 32); !math.IsInf(float64(float32(f)), 0) && (!ok || string(got) != string(want)) {
+			t.Errorf("float32 %v: got %s want %s", f, got, want)
+		}
+	}
+	if _, ok := appendJSONFloat(nil, math.NaN(), 64); ok {
+		t.Error("NaN must not be encoded")
+	}
+}
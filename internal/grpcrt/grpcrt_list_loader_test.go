@@ -0,0 +1,96 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// buildListLoader builds BatchLoadPostsByAuthorId(BatchReq{ batches: Item{author_id} }) ->
+// BatchResp{ batches: ItemOut{repeated data, author_id} }
+func buildListLoader(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("list_loader.proto"),
+		Package: protoString("ll"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Item"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("author_id"), JsonName: protoString("authorId"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()}}},
+			{Name: protoString("ItemOut"), Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: protoString("data"), JsonName: protoString("data"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: protoString("author_id"), JsonName: protoString("authorId"), Number: protoInt32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			}},
+			{Name: protoString("BatchReq"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".ll.Item")}}},
+			{Name: protoString("BatchResp"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".ll.ItemOut")}}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("PostService"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("BatchLoadPostsByAuthorId"), InputType: protoString(".ll.BatchReq"), OutputType: protoString(".ll.BatchResp")}}}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("list_loader.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("PostService").Methods().ByName("BatchLoadPostsByAuthorId")
+}
+
+// listResponse answers one item per author with the given posts.
+func listResponse(md protoreflect.MethodDescriptor, postsByAuthor map[string][]string, authors ...string) protoreflect.Message {
+	out := dynamicpb.NewMessage(md.Output())
+	of := md.Output().Fields().ByName("batches")
+	itemDesc := of.Message()
+	lst := out.Mutable(of).List()
+	for _, author := range authors {
+		it := dynamicpb.NewMessage(itemDesc)
+		it.Set(itemDesc.Fields().ByName("author_id"), protoreflect.ValueOfString(author))
+		data := it.Mutable(itemDesc.Fields().ByName("data")).List()
+		for _, post := range postsByAuthor[author] {
+			data.Append(protoreflect.ValueOfString(post))
+		}
+		lst.Append(protoreflect.ValueOfMessage(it))
+	}
+	return out
+}
+
+func TestListLoader_MapsEachKeysListToItsTask(t *testing.T) {
+	md := buildListLoader(t)
+	posts := map[string][]string{"a1": {"p1", "p2"}, "a2": {}, "a3": {"p3"}}
+	mt := NewMockTransport(listResponse(md, posts, "a1", "a2", "a3"))
+	rt := NewRuntime(NewMockRegistry().RegisterBatchLoader("User", "posts", md), mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a1"}},
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a2"}},
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a3"}},
+	})
+	require.Len(t, mt.Calls(), 1)
+	require.Equal(t, []any{"p1", "p2"}, res[0].Value)
+	require.Equal(t, []any{}, res[1].Value)
+	require.Equal(t, []any{"p3"}, res[2].Value)
+}
+
+func TestListLoader_KeyedMissingKeysLoadEmptyLists(t *testing.T) {
+	md := buildListLoader(t)
+	posts := map[string][]string{"a1": {"p1", "p2"}}
+	mt := NewMockTransport(listResponse(md, posts, "a1"), listResponse(md, posts))
+	rt := NewRuntime(NewMockRegistry().RegisterBatchLoader("User", "posts", md).RegisterKeyedLoader(md), mt)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a2"}},
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a1"}},
+	})
+	require.Equal(t, []any{}, res[0].Value)
+	require.Equal(t, []any{"p1", "p2"}, res[1].Value)
+
+	// Nothing found for any key
+	res = rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "User", Field: "posts", Args: map[string]any{"authorId": "a2"}},
+	})
+	require.NoError(t, res[0].Error)
+	require.Equal(t, []any{}, res[0].Value)
+}
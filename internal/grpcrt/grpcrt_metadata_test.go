@@ -0,0 +1,40 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+func TestBatchResolveAsync_MetadataArgumentsPartitionCalls(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "rate", md).
+		RegisterMetadataArgument("Query", "rate", "tenant", "x-tenant-id")
+	transport := NewMockTransport(batchResponse(md, "a1", "a2"), batchResponse(md, "b1"), batchResponse(md, "n1"))
+	rt := NewRuntime(reg, transport)
+
+	res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "rate", Args: map[string]any{"tenant": "a", "data": "EUR"}},
+		{ObjectType: "Query", Field: "rate", Args: map[string]any{"tenant": "b", "data": "USD"}},
+		{ObjectType: "Query", Field: "rate", Args: map[string]any{"tenant": "a", "data": "JPY"}},
+		{ObjectType: "Query", Field: "rate", Args: map[string]any{"tenant": nil, "data": "KRW"}},
+	})
+	require.Equal(t, []any{"a1", "b1", "a2", "n1"}, []any{res[0].Value, res[1].Value, res[2].Value, res[3].Value})
+
+	calls := transport.Calls()
+	require.Len(t, calls, 3)
+	require.Equal(t, []string{"a"}, calls[0].Metadata.Get("x-tenant-id"))
+	require.Equal(t, []string{"b"}, calls[1].Metadata.Get("x-tenant-id"))
+	require.Empty(t, calls[2].Metadata.Get("x-tenant-id"))
+
+	// Metadata arguments are not request fields
+	batches := calls[0].Request.ProtoReflect().Get(md.Input().Fields().ByName("batches")).List()
+	require.Equal(t, 2, batches.Len())
+	data := batches.Get(1).Message().Get(md.Input().Fields().ByName("batches").Message().Fields().ByName("data"))
+	require.Equal(t, protoreflect.ValueOfString("JPY").Interface(), data.Interface())
+}
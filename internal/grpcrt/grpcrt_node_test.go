@@ -0,0 +1,140 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// buildNodeLoader builds UserSource and a BatchLoadUserById method keyed by id.
+func buildNodeLoader(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	idField := func() *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     protoString("id"),
+			JsonName: protoString("id"),
+			Number:   protoInt32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("node.proto"),
+		Package: protoString("nsvc"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("UserSource"), Field: []*descriptorpb.FieldDescriptorProto{idField()}},
+			{Name: protoString("LoadUserByIdRequest"), Field: []*descriptorpb.FieldDescriptorProto{idField()}},
+			{Name: protoString("LoadUserByIdResponse"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     protoString("data"),
+				JsonName: protoString("data"),
+				Number:   protoInt32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: protoString(".nsvc.UserSource"),
+			}}},
+			{Name: protoString("BatchLoadUserByIdRequest"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     protoString("batches"),
+				JsonName: protoString("batches"),
+				Number:   protoInt32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: protoString(".nsvc.LoadUserByIdRequest"),
+			}}},
+			{Name: protoString("BatchLoadUserByIdResponse"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     protoString("batches"),
+				JsonName: protoString("batches"),
+				Number:   protoInt32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: protoString(".nsvc.LoadUserByIdResponse"),
+			}}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: protoString("UserService"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       protoString("BatchLoadUserById"),
+				InputType:  protoString(".nsvc.BatchLoadUserByIdRequest"),
+				OutputType: protoString(".nsvc.BatchLoadUserByIdResponse"),
+			}},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+	files, err := protodesc.NewFiles(set)
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("node.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("UserService").Methods().ByName("BatchLoadUserById")
+}
+
+func TestGlobalIDRoundTrip(t *testing.T) {
+	gid := EncodeGlobalID("User", "u:1")
+	typeName, id, ok := DecodeGlobalID(gid)
+	require.True(t, ok)
+	require.Equal(t, "User", typeName)
+	require.Equal(t, "u:1", id)
+
+	_, _, ok = DecodeGlobalID("not base64!")
+	require.False(t, ok)
+	_, _, ok = DecodeGlobalID(EncodeGlobalID("", "1"))
+	require.False(t, ok)
+}
+
+func TestNodeField_RoutesToTypeLoader(t *testing.T) {
+	md := buildNodeLoader(t)
+	userDesc := md.Output().Fields().ByName("batches").Message().Fields().ByName("data").Message()
+
+	out := dynamicpb.NewMessage(md.Output())
+	of := md.Output().Fields().ByName("batches")
+	item := dynamicpb.NewMessage(of.Message())
+	user := dynamicpb.NewMessage(userDesc)
+	user.Set(userDesc.Fields().ByName("id"), protoreflect.ValueOfString("1"))
+	item.Set(of.Message().Fields().ByName("data"), protoreflect.ValueOfMessage(user))
+	list := out.Mutable(of).List()
+	list.Append(protoreflect.ValueOfMessage(item))
+
+	reg := NewMockRegistry().
+		RegisterNodeField("Query", "node").
+		RegisterBatchNodeLoader("User", md).
+		RegisterGlobalIDField("User", "id").
+		RegisterSourceField("User", "id", userDesc.Fields().ByName("id"))
+	mt := NewMockTransport(out)
+	rt := NewRuntime(reg, mt)
+
+	tasks := []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "node", Args: map[string]any{"id": EncodeGlobalID("User", "1")}},
+		{ObjectType: "Query", Field: "node", Args: map[string]any{"id": EncodeGlobalID("Unknown", "1")}},
+		{ObjectType: "Query", Field: "node", Args: map[string]any{"id": "garbage"}},
+	}
+	res := rt.BatchResolveAsync(context.Background(), tasks)
+	require.Len(t, res, 3)
+
+	// Loader receives the raw id, not the global ID
+	calls := mt.Calls()
+	require.Len(t, calls, 1)
+	req := calls[0].Request.ProtoReflect()
+	batches := req.Get(md.Input().Fields().ByName("batches")).List()
+	require.Equal(t, 1, batches.Len())
+	require.Equal(t, "1", batches.Get(0).Message().Get(md.Input().Fields().ByName("batches").Message().Fields().ByName("id")).String())
+
+	require.NoError(t, res[0].Error)
+	got, ok := res[0].Value.(protoreflect.Message)
+	require.True(t, ok)
+
+	// Types without a loader resolve to null; malformed IDs are errors
+	require.NoError(t, res[1].Error)
+	require.Nil(t, res[1].Value)
+	require.Error(t, res[2].Error)
+
+	// The node's id is exposed as a global ID again
+	v, err := rt.ResolveSync(context.Background(), "User", "id", got, nil)
+	require.NoError(t, err)
+	require.Equal(t, EncodeGlobalID("User", "1"), v)
+}
@@ -0,0 +1,62 @@
+package grpcrt
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// orderTransport echoes batch calls and records the data of each in call order.
+type orderTransport struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (o *orderTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	in := req.Get(md.Input().Fields().ByName("batches")).List()
+	data := make([]string, in.Len())
+	for i := range data {
+		data[i] = in.Get(i).Message().Get(in.Get(i).Message().Descriptor().Fields().ByName("data")).String()
+	}
+	o.mu.Lock()
+	o.order = append(o.order, data...)
+	o.mu.Unlock()
+	return batchResponse(md, data...), nil
+}
+
+func TestBatchResolveAsync_PriorityLevelsRunInStages(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchLoader("Query", "slow", md).
+		RegisterBatchLoader("Query", "plain", md).
+		RegisterBatchLoader("Query", "fast", md).
+		RegisterBatchLoader("Query", "other", md).
+		RegisterPriority("Query", "slow", PriorityLow).
+		RegisterPriority("Query", "fast", PriorityHigh)
+	transport := &orderTransport{}
+	rt := NewRuntime(reg, transport)
+
+	tasks := []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "slow", Args: map[string]any{"data": "slow"}},
+		{ObjectType: "Query", Field: "plain", Args: map[string]any{"data": "plain"}},
+		{ObjectType: "Query", Field: "fast", Args: map[string]any{"data": "fast"}},
+		{ObjectType: "Query", Field: "other", Args: map[string]any{"data": "other"}},
+	}
+	results := rt.BatchResolveAsync(context.Background(), tasks)
+	require.Equal(t, "slow", results[0].Value)
+	require.Equal(t, "plain", results[1].Value)
+	require.Equal(t, "fast", results[2].Value)
+
+	require.Equal(t, "other", results[3].Value)
+
+	// HIGH calls return before NORMAL ones start, and NORMAL before LOW
+	require.Len(t, transport.order, 4)
+	require.Equal(t, "fast", transport.order[0])
+	require.ElementsMatch(t, []string{"plain", "other"}, transport.order[1:3])
+	require.Equal(t, "slow", transport.order[3])
+}
@@ -0,0 +1,38 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/cache"
+)
+
+func TestProjection_CachesLookups(t *testing.T) {
+	md, fd := buildTestMessage(t)
+	rt := NewRuntime(NewMockRegistry().RegisterSourceField("User", "title", fd), nil).(*Runtime)
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(fd, protoreflect.ValueOfString("t"))
+
+	for range 3 {
+		v, err := rt.ResolveSync(context.Background(), "User", "title", msg, nil)
+		require.NoError(t, err)
+		require.Equal(t, "t", v)
+	}
+	require.Equal(t, cache.Stats{Hits: 2, Misses: 1}, rt.ProjectionStats())
+
+	// Fields without a source path are cached too
+	require.Nil(t, rt.sourceFieldPath("User", "missing"))
+	require.Nil(t, rt.sourceFieldPath("User", "missing"))
+	require.Equal(t, cache.Stats{Hits: 3, Misses: 2}, rt.ProjectionStats())
+
+	for range 2 {
+		req := dynamicpb.NewMessage(md)
+		require.NoError(t, rt.setMessageFieldsByJSON(req, map[string]any{"title": "x", "unknown": 1}))
+		require.Equal(t, "x", req.Get(fd).String())
+	}
+	require.Equal(t, cache.Stats{Hits: 4, Misses: 3}, rt.ProjectionStats())
+}
@@ -0,0 +1,128 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// buildDefaultsMessage returns
+//
+//	message ItemSource {
+//	  bool b = 1; int32 i32 = 2; string s = 3; Color color = 4;
+//	  optional string opt = 5; repeated string tags = 6; ItemSource child = 7;
+//	  string def = 8;
+//	}
+func buildDefaultsMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	color := field("color", 4, descriptorpb.FieldDescriptorProto_TYPE_ENUM)
+	color.TypeName = protoString(".defaults.Color")
+	opt := field("opt", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	opt.OneofIndex, opt.Proto3Optional = protoInt32(0), proto.Bool(true)
+	tags := field("tags", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	tags.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	child := field("child", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	child.TypeName = protoString(".defaults.ItemSource")
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("defaults.proto"),
+		Package: protoString("defaults"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: protoString("Color"), Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: protoString("COLOR_UNSPECIFIED"), Number: protoInt32(0)},
+			{Name: protoString("RED"), Number: protoInt32(1)},
+		}}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("ItemSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("b", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+				field("i32", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("s", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				color, opt, tags, child,
+				field("def", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: protoString("_opt")}},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("defaults.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("ItemSource")
+}
+
+func newDefaultsRegistry(md protoreflect.MessageDescriptor) *MockRegistry {
+	fields := md.Fields()
+	reg := NewMockRegistry()
+	for _, name := range []protoreflect.Name{"b", "i32", "s", "color", "opt", "tags", "child", "def"} {
+		reg.RegisterSourceField("Item", string(name), fields.ByName(name))
+	}
+	reg.RegisterSourceFieldPath("Item", "childS", fields.ByName("child"), fields.ByName("s"))
+	reg.RegisterSourceFieldDefault("Item", "def", "fallback")
+	return reg
+}
+
+func Test_1_4_ResolveSync_ProtoDefaults_UnsetScalars(t *testing.T) {
+	md := buildDefaultsMessage(t)
+	msg := dynamicpb.NewMessage(md)
+	reg := newDefaultsRegistry(md)
+
+	resolve := func(rt executor.Runtime, msg protoreflect.Message) map[string]any {
+		out := map[string]any{}
+		for _, field := range []string{"b", "i32", "s", "color", "opt", "tags", "child", "def", "childS"} {
+			v, err := rt.ResolveSync(context.Background(), "Item", field, msg, nil)
+			require.NoError(t, err, field)
+			out[field] = v
+		}
+		return out
+	}
+
+	// Unset fields read as null by default; empty repeated fields as empty lists
+	require.Equal(t, map[string]any{
+		"b": nil, "i32": nil, "s": nil, "color": nil, "opt": nil,
+		"tags": []any{}, "child": nil, "def": "fallback", "childS": nil,
+	}, resolve(NewRuntime(reg, nil), msg))
+
+	// Scalars without presence read as their default; explicit presence,
+	// lists, messages, @default and unset @source parents are unchanged
+	rt := NewRuntime(reg, nil, WithProtoDefaults())
+	require.Equal(t, map[string]any{
+		"b": false, "i32": int32(0), "s": "", "color": "COLOR_UNSPECIFIED", "opt": nil,
+		"tags": []any{}, "child": nil, "def": "fallback", "childS": nil,
+	}, resolve(rt, msg))
+
+	msg.Set(md.Fields().ByName("child"), protoreflect.ValueOfMessage(dynamicpb.NewMessage(md)))
+	v, err := rt.ResolveSync(context.Background(), "Item", "childS", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "", v)
+}
+
+func Test_10_4_CompleteLeafObject_ProtoDefaults(t *testing.T) {
+	md := buildDefaultsMessage(t)
+	msg := dynamicpb.NewMessage(md)
+	fields := []executor.LeafField{
+		{ResponseName: "b", Field: "b", Type: "Boolean", NonNull: true},
+		{ResponseName: "i32", Field: "i32", Type: "Int"},
+		{ResponseName: "s", Field: "s", Type: "String", NonNull: true},
+		{ResponseName: "color", Field: "color", Type: "Color"},
+		{ResponseName: "opt", Field: "opt", Type: "String"},
+	}
+
+	rt := NewRuntime(newDefaultsRegistry(md), nil).(*Runtime)
+	_, ok := rt.CompleteLeafObject(context.Background(), "Item", msg, fields)
+	require.False(t, ok, "unset NonNull fields fall back without WithProtoDefaults")
+
+	rt = NewRuntime(newDefaultsRegistry(md), nil, WithProtoDefaults()).(*Runtime)
+	got, ok := rt.CompleteLeafObject(context.Background(), "Item", msg, fields)
+	require.True(t, ok)
+	require.JSONEq(t, `{"b":false,"i32":0,"s":"","color":"COLOR_UNSPECIFIED","opt":null}`, string(got))
+}
@@ -0,0 +1,128 @@
+package grpcrt
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// transportFunc adapts a func to Transport.
+type transportFunc func(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error)
+
+func (f transportFunc) Call(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
+	return f(ctx, method, request)
+}
+
+// buildBatchRequest returns BatchReq{ repeated Item batches = 1 } with n items.
+func buildBatchRequest(t *testing.T, n int) protoreflect.Message {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("quota_batch.proto"),
+		Package: protoString("qb"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Item")},
+			{Name: protoString("BatchReq"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("batches"), JsonName: protoString("batches"), Number: protoInt32(1), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(), TypeName: protoString(".qb.Item")}}},
+		},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("quota_batch.proto")
+	require.NoError(t, err)
+	md := fd.Messages().ByName("BatchReq")
+	req := dynamicpb.NewMessage(md)
+	list := req.Mutable(md.Fields().ByName("batches")).List()
+	for range n {
+		list.Append(protoreflect.ValueOfMessage(dynamicpb.NewMessage(md.Fields().ByName("batches").Message())))
+	}
+	return req
+}
+
+func TestMethodQuota_CapsCallsInFlight(t *testing.T) {
+	md := buildAuthorResolver(t)
+	var inFlight, peak, calls atomic.Int64
+	next := transportFunc(func(context.Context, protoreflect.MethodDescriptor, protoreflect.Message) (protoreflect.Message, error) {
+		n := inFlight.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		calls.Add(1)
+		return dynamicpb.NewMessage(md.Output()), nil
+	})
+	rt := NewRuntime(NewMockRegistry(), next, WithMethodQuotas(MethodQuota{Method: "rb.S/Resolve", Limit: 2})).(*Runtime)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := rt.transport.Call(context.Background(), md, dynamicpb.NewMessage(md.Input())); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(10), calls.Load())
+	require.Equal(t, int64(2), peak.Load())
+}
+
+func TestMethodQuota_QueuesInArrivalOrder(t *testing.T) {
+	q := &quota{MethodQuota: MethodQuota{Method: "s.S/M", Limit: 4, PerEntry: true}}
+	require.Equal(t, 3, q.weight(buildBatchRequest(t, 3)))
+	require.Equal(t, 4, q.weight(buildBatchRequest(t, 9)), "calls weigh at most the limit")
+	require.Equal(t, 1, q.weight(buildBatchRequest(t, 0)))
+
+	ctx := context.Background()
+	require.NoError(t, q.acquire(ctx, 3))
+
+	// A heavy call queues, and the light one behind it waits its turn even
+	// though it would fit
+	heavy, light := make(chan error, 1), make(chan error, 1)
+	go func() { heavy <- q.acquire(ctx, 4) }()
+	require.Eventually(t, func() bool { q.mu.Lock(); defer q.mu.Unlock(); return q.waiters.Len() == 1 }, time.Second, time.Millisecond)
+	go func() { light <- q.acquire(ctx, 1) }()
+	require.Eventually(t, func() bool { q.mu.Lock(); defer q.mu.Unlock(); return q.waiters.Len() == 2 }, time.Second, time.Millisecond)
+
+	// A caller giving up leaves the queue
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.Equal(t, codes.Canceled, status.Code(q.acquire(cctx, 1)))
+
+	q.release(3)
+	require.NoError(t, <-heavy)
+	select {
+	case <-light:
+		t.Fatal("light call admitted over the quota")
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.release(4)
+	require.NoError(t, <-light)
+	q.release(1)
+	require.Zero(t, q.used)
+}
+
+func TestParseMethodQuota(t *testing.T) {
+	q, err := ParseMethodQuota("search.SearchService/Search=4")
+	require.NoError(t, err)
+	require.Equal(t, MethodQuota{Method: "search.SearchService/Search", Limit: 4}, q)
+
+	q, err = ParseMethodQuota("blog.PostService/BatchGetPost=200,entries")
+	require.NoError(t, err)
+	require.Equal(t, MethodQuota{Method: "blog.PostService/BatchGetPost", Limit: 200, PerEntry: true}, q)
+
+	for _, spec := range []string{"search.SearchService=4", "s.S/M", "s.S/M=0", "s.S/M=x", "s.S/M=4,bytes"} {
+		_, err := ParseMethodQuota(spec)
+		require.Error(t, err, spec)
+	}
+}
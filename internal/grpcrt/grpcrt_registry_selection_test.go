@@ -170,3 +170,31 @@ func Test_2_2_Grouping_ByObjectAndField_BatchedPerGroup(t *testing.T) {
     require.Contains(t, methods[0]+methods[1], "/q.S/B1")
     require.Contains(t, methods[0]+methods[1], "/q.S/B2")
 }
+
+// docs §2.1
+func Test_2_1_RegistrySelection_DescribeMethod(t *testing.T) {
+    sres := buildMethod(t, "S", "Resolve", false)
+    bldr := buildMethod(t, "S", "BatchLoad", true)
+    reg := NewMockRegistry().
+        RegisterSingleResolver("Obj", "f", sres).
+        RegisterBatchLoader("Obj", "f", bldr).
+        RegisterBatchLoader("Obj", "g", bldr).
+        RegisterNodeField("Query", "node")
+    d := NewRuntime(reg, NewMockTransport()).(executor.MethodDescriber)
+
+    // Same precedence as BatchResolveAsync: the single resolver wins over the loader
+    method, batched, ok := d.DescribeMethod("Obj", "f")
+    require.Equal(t, "q.S.Resolve", method)
+    require.False(t, batched)
+    require.True(t, ok)
+
+    method, batched, _ = d.DescribeMethod("Obj", "g")
+    require.Equal(t, "q.S.BatchLoad", method)
+    require.True(t, batched)
+
+    method, _, _ = d.DescribeMethod("Query", "node")
+    require.Equal(t, "node", method)
+
+    _, _, ok = d.DescribeMethod("Obj", "missing")
+    require.False(t, ok)
+}
@@ -0,0 +1,84 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// buildRepeatedMessage returns
+//
+//	message Post { repeated string tags = 1; repeated Color colors = 2; repeated Post replies = 3; }
+func buildRepeatedMessage(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	repeated := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), Type: typ.Enum()}
+	}
+	colors := repeated("colors", 2, descriptorpb.FieldDescriptorProto_TYPE_ENUM)
+	colors.TypeName = protoString(".rep.Color")
+	replies := repeated("replies", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	replies.TypeName = protoString(".rep.Post")
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("repeated.proto"),
+		Package: protoString("rep"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{Name: protoString("Color"), Value: []*descriptorpb.EnumValueDescriptorProto{
+			{Name: protoString("COLOR_UNSPECIFIED"), Number: protoInt32(0)},
+			{Name: protoString("RED"), Number: protoInt32(1)},
+		}}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  protoString("Post"),
+			Field: []*descriptorpb.FieldDescriptorProto{repeated("tags", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING), colors, replies},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("repeated.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("Post")
+}
+
+// docs §1.3
+func Test_1_5_ResolveSync_RepeatedFields_ExpandToLists(t *testing.T) {
+	md := buildRepeatedMessage(t)
+	tags, colors, replies := md.Fields().ByName("tags"), md.Fields().ByName("colors"), md.Fields().ByName("replies")
+	reg := NewMockRegistry().
+		RegisterSourceField("Post", "tags", tags).
+		RegisterSourceField("Post", "colors", colors).
+		RegisterSourceField("Post", "replies", replies)
+	rt := NewRuntime(reg, nil)
+
+	reply := dynamicpb.NewMessage(md)
+	reply.Mutable(tags).List().Append(protoreflect.ValueOfString("re"))
+	msg := dynamicpb.NewMessage(md)
+	msg.Mutable(tags).List().Append(protoreflect.ValueOfString("a"))
+	msg.Mutable(tags).List().Append(protoreflect.ValueOfString("b"))
+	msg.Mutable(colors).List().Append(protoreflect.ValueOfEnum(1))
+	msg.Mutable(colors).List().Append(protoreflect.ValueOfEnum(7))
+	msg.Mutable(replies).List().Append(protoreflect.ValueOfMessage(reply))
+
+	resolve := func(msg protoreflect.Message, field string) any {
+		v, err := rt.ResolveSync(context.Background(), "Post", field, msg, nil)
+		require.NoError(t, err, field)
+		return v
+	}
+
+	require.Equal(t, []any{"a", "b"}, resolve(msg, "tags"))
+	// Enum elements map to names, unknown numbers stay numeric
+	require.Equal(t, []any{"RED", int32(7)}, resolve(msg, "colors"))
+	// Message elements pass through as sources for their object type
+	got := resolve(msg, "replies").([]any)
+	require.Len(t, got, 1)
+	require.Equal(t, []any{"re"}, resolve(got[0].(protoreflect.Message), "tags"))
+
+	// Empty repeated fields read as empty lists, not null
+	empty := dynamicpb.NewMessage(md)
+	for _, field := range []string{"tags", "colors", "replies"} {
+		require.Equal(t, []any{}, resolve(empty, field), field)
+	}
+}
@@ -0,0 +1,80 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/schema"
+)
+
+// buildAuthorResolver builds Resolve(Req{ authorId, limit }) -> Resp{ data }
+func buildAuthorResolver(t *testing.T) protoreflect.MethodDescriptor {
+	t.Helper()
+	field := func(name string, n int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: protoString(name), JsonName: protoString(name), Number: protoInt32(n), Type: typ.Enum()}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("req_builder.proto"),
+		Package: protoString("rb"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("Req"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("authorId", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("limit", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			}},
+			{Name: protoString("Resp"), Field: []*descriptorpb.FieldDescriptorProto{field("data", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("S"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("Resolve"), InputType: protoString(".rb.Req"), OutputType: protoString(".rb.Resp")}}}},
+		Syntax:  protoString("proto3"),
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("req_builder.proto")
+	require.NoError(t, err)
+	return fd.Services().ByName("S").Methods().ByName("Resolve")
+}
+
+func TestRequestBuilder_PrecompiledAtConstruction(t *testing.T) {
+	md := buildAuthorResolver(t)
+	srcDesc, fid, _ := buildSourceWithIDs(t)
+	reg := NewMockRegistry().
+		RegisterSingleResolver("Obj", "f", md).
+		// "other" is not a request field and is dropped when compiling
+		RegisterRequestSourceMap("Obj", "f", map[string]string{"authorId": "id", "other": "id"}).
+		RegisterSourceField("Obj", "id", fid)
+	sch := schema.NewSchema("")
+	sch.AddType(schema.NewType("Obj", schema.TypeKindObject, "").
+		AddField(schema.NewField("f", "", schema.NamedType("String")).SetAsync(true)))
+
+	out := dynamicpb.NewMessage(md.Output())
+	out.Set(md.Output().Fields().ByName("data"), protoreflect.ValueOfString("ok"))
+	mt := NewMockTransport(out, out)
+	rt := NewRuntime(reg, mt, WithPrecompiledRequests(sch)).(*Runtime)
+	compiled := rt.ProjectionStats().Misses
+	require.NotZero(t, compiled)
+
+	b := rt.requestBuilder("Obj", "f", md.Input())
+	require.Equal(t, []sourceArg{{dst: "authorId", path: []protoreflect.FieldDescriptor{fid}}}, b.sources)
+
+	src := dynamicpb.NewMessage(srcDesc)
+	src.Set(fid, protoreflect.ValueOfString("u1"))
+	for _, args := range []map[string]any{{"limit": 3}, {"limit": 3, "authorId": "u2"}} {
+		res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{{ObjectType: "Obj", Field: "f", Source: src, Args: args}})
+		require.NoError(t, res[0].Error)
+	}
+	require.Equal(t, compiled, rt.ProjectionStats().Misses, "requests built without compiling")
+
+	calls := mt.Calls()
+	require.Len(t, calls, 2)
+	authorID, limit := md.Input().Fields().ByName("authorId"), md.Input().Fields().ByName("limit")
+	require.Equal(t, "u1", calls[0].Request.ProtoReflect().Get(authorID).String())
+	require.Equal(t, int64(3), calls[0].Request.ProtoReflect().Get(limit).Int())
+	// Args take precedence over the parent source
+	require.Equal(t, "u2", calls[1].Request.ProtoReflect().Get(authorID).String())
+}
@@ -136,3 +136,49 @@ func TestRequestMapping_BatchLoader_UsesParentSource(t *testing.T) {
     require.Equal(t, "org-1", req.Get(bf).List().Get(0).Message().Get(idField).String())
 }
 
+
+func TestRequestMapping_Expression_ComposesParentFields(t *testing.T) {
+    // Build resolver: ResolveObjF(Request{ memberKey }) -> Response{ data: string }
+    file := &descriptorpb.FileDescriptorProto{
+        Name:    protoString("req_map_expr.proto"),
+        Package: protoString("e"),
+        MessageType: []*descriptorpb.DescriptorProto{
+            {Name: protoString("Req"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("memberKey"), JsonName: protoString("memberKey"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()}}},
+            {Name: protoString("Resp"), Field: []*descriptorpb.FieldDescriptorProto{{Name: protoString("data"), JsonName: protoString("data"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()}}},
+        },
+        Service: []*descriptorpb.ServiceDescriptorProto{{Name: protoString("S"), Method: []*descriptorpb.MethodDescriptorProto{{Name: protoString("Resolve"), InputType: protoString(".e.Req"), OutputType: protoString(".e.Resp")}}}},
+        Syntax: protoString("proto3"),
+    }
+    set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+    files, err := protodesc.NewFiles(set)
+    require.NoError(t, err)
+    fd, err := files.FindFileByPath("req_map_expr.proto")
+    require.NoError(t, err)
+    md := fd.Services().ByName("S").Methods().ByName("Resolve")
+
+    out := dynamicpb.NewMessage(md.Output())
+    out.Set(md.Output().Fields().ByName("data"), protoreflect.ValueOfString("ok"))
+
+    // Parent source with organizationId="org-1", id="u1"
+    srcMsgDesc, fid, forg := buildSourceWithIDs(t)
+    src := dynamicpb.NewMessage(srcMsgDesc)
+    src.Set(fid, protoreflect.ValueOfString("u1"))
+    src.Set(forg, protoreflect.ValueOfString("org-1"))
+
+    reg := NewMockRegistry().
+        RegisterSingleResolver("Obj", "f", md).
+        RegisterRequestSourceExpr("Obj", "f", "memberKey", `organizationId + ":" + id`).
+        RegisterSourceField("Obj", "id", fid).
+        RegisterSourceField("Obj", "organizationId", forg)
+    mt := NewMockTransport(out, out)
+    rt := NewRuntime(reg, mt)
+
+    // The key is composed from both parent fields; an explicit argument wins
+    _ = rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{{ObjectType: "Obj", Field: "f", Source: src, Args: map[string]any{}}})
+    _ = rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{{ObjectType: "Obj", Field: "f", Source: src, Args: map[string]any{"memberKey": "given"}}})
+    calls := mt.Calls()
+    require.Equal(t, 2, len(calls))
+    rf := md.Input().Fields().ByName("memberKey")
+    require.Equal(t, "org-1:u1", calls[0].Request.ProtoReflect().Get(rf).String())
+    require.Equal(t, "given", calls[1].Request.ProtoReflect().Get(rf).String())
+}
@@ -6,8 +6,11 @@ import (
 
     "github.com/stretchr/testify/require"
     "google.golang.org/protobuf/reflect/protodesc"
+    "google.golang.org/protobuf/reflect/protoreflect"
     "google.golang.org/protobuf/types/descriptorpb"
     "google.golang.org/protobuf/types/dynamicpb"
+
+    "github.com/hanpama/protograph/internal/errcode"
 )
 
 // docs §8.1
@@ -63,3 +66,58 @@ func Test_8_2_ResolveType_ValueNotMessage_Error(t *testing.T) {
     _, err := rt.ResolveType(context.Background(), "Any", 123)
     require.Error(t, err)
 }
+
+// buildAnimal builds a message shared by several object types: Animal{ string kind = 1 }
+func buildAnimal(t *testing.T) *dynamicpb.Message {
+    t.Helper()
+    file := &descriptorpb.FileDescriptorProto{
+        Name:    protoString("rt3.proto"),
+        Package: protoString("zoo"),
+        MessageType: []*descriptorpb.DescriptorProto{{
+            Name: protoString("Animal"),
+            Field: []*descriptorpb.FieldDescriptorProto{{
+                Name: protoString("kind"), JsonName: protoString("kind"), Number: protoInt32(1),
+                Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+            }},
+        }},
+        Syntax: protoString("proto3"),
+    }
+    set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+    files, err := protodesc.NewFiles(set)
+    require.NoError(t, err)
+    fd, err := files.FindFileByPath("rt3.proto")
+    require.NoError(t, err)
+    return dynamicpb.NewMessage(fd.Messages().ByName("Animal"))
+}
+
+func TestResolveType_RegistryMessageMapping(t *testing.T) {
+    msg := buildAnimal(t)
+    reg := NewMockRegistry().RegisterMessageObjectType("zoo.Animal", "Pet")
+    typ, err := NewRuntime(reg, nil).ResolveType(context.Background(), "Node", msg)
+    require.NoError(t, err)
+    require.Equal(t, "Pet", typ)
+}
+
+func TestResolveType_Discriminator(t *testing.T) {
+    msg := buildAnimal(t)
+    reg := NewMockRegistry().
+        RegisterMessageObjectType("zoo.Animal", "Pet").
+        RegisterTypeDiscriminator("Creature", TypeDiscriminator{Field: "kind", Types: map[string]string{"DOG": "Dog", "CAT": "Cat"}})
+    rt := NewRuntime(reg, nil)
+    kind := msg.Descriptor().Fields().ByName("kind")
+
+    msg.Set(kind, protoreflect.ValueOfString("CAT"))
+    typ, err := rt.ResolveType(context.Background(), "Creature", msg)
+    require.NoError(t, err)
+    require.Equal(t, "Cat", typ)
+
+    // Other abstract types of the same message keep the message mapping
+    typ, err = rt.ResolveType(context.Background(), "Node", msg)
+    require.NoError(t, err)
+    require.Equal(t, "Pet", typ)
+
+    msg.Set(kind, protoreflect.ValueOfString("BIRD"))
+    _, err = rt.ResolveType(context.Background(), "Creature", msg)
+    require.Error(t, err)
+    require.Equal(t, errcode.DownstreamServiceError, errcode.Of(err))
+}
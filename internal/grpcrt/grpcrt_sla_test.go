@@ -0,0 +1,66 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	executor "github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+// sleepingTransport echoes batch calls, after sleeping for those of "sleep".
+type sleepingTransport struct{ d time.Duration }
+
+func (s sleepingTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	item := req.Get(md.Input().Fields().ByName("batches")).List().Get(0).Message()
+	data := item.Get(item.Descriptor().Fields().ByName("data")).String()
+	if data == "sleep" {
+		time.Sleep(s.d)
+	}
+	return batchResponse(md, data), nil
+}
+
+func TestBatchResolveAsync_SLAExceededIsPublished(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchLoader("Query", "slow", md).
+		RegisterBatchLoader("Query", "fast", md).
+		RegisterBatchLoader("Query", "unbudgeted", md).
+		RegisterSLA("Query", "slow", time.Millisecond).
+		RegisterSLA("Query", "fast", time.Minute)
+	rt := NewRuntime(reg, sleepingTransport{d: 20 * time.Millisecond})
+
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	var got []events.SLAExceeded
+	unsubscribe := eventbus.Subscribe(func(_ context.Context, e events.SLAExceeded) { got = append(got, e) })
+	defer unsubscribe()
+
+	ctx := protographctx.WithOperation(context.Background(), protographctx.Operation{Name: "Feed", Type: "query"})
+	results := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "slow", Args: map[string]any{"data": "sleep"}},
+		{ObjectType: "Query", Field: "fast", Args: map[string]any{"data": "fast"}},
+		{ObjectType: "Query", Field: "unbudgeted", Args: map[string]any{"data": "sleep"}},
+	})
+	for _, res := range results {
+		require.NoError(t, res.Error)
+	}
+
+	require.Len(t, got, 1)
+	require.GreaterOrEqual(t, got[0].Duration, 20*time.Millisecond)
+	got[0].Duration = 0
+	require.Equal(t, events.SLAExceeded{
+		OperationName: "Feed",
+		ObjectType:    "Query",
+		Field:         "slow",
+		Method:        string(md.FullName()),
+		Tasks:         1,
+		Budget:        time.Millisecond,
+	}, got[0])
+}
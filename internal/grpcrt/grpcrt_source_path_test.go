@@ -0,0 +1,87 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// buildCustomerSource builds CustomerSource{display_name, address: AddressSource{city}}.
+func buildCustomerSource(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("customer.proto"),
+		Package: protoString("csvc"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protoString("AddressSource"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     protoString("city"),
+				JsonName: protoString("city"),
+				Number:   protoInt32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}}},
+			{Name: protoString("CustomerSource"), Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     protoString("display_name"),
+					JsonName: protoString("displayName"),
+					Number:   protoInt32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				},
+				{
+					Name:     protoString("address"),
+					JsonName: protoString("address"),
+					Number:   protoInt32(2),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: protoString(".csvc.AddressSource"),
+				},
+			}},
+		},
+		Syntax: protoString("proto3"),
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+	files, err := protodesc.NewFiles(set)
+	require.NoError(t, err)
+	fd, err := files.FindFileByPath("customer.proto")
+	require.NoError(t, err)
+	return fd.Messages().ByName("CustomerSource")
+}
+
+func TestResolveSync_SourcePath(t *testing.T) {
+	md := buildCustomerSource(t)
+	displayName := md.Fields().ByName("display_name")
+	address := md.Fields().ByName("address")
+	city := address.Message().Fields().ByName("city")
+
+	reg := NewMockRegistry().
+		RegisterSourceFieldPath("Customer", "name", displayName).
+		RegisterSourceFieldPath("Customer", "city", address, city).
+		RegisterSourceFieldDefault("Customer", "city", "unknown")
+	rt := NewRuntime(reg, NewMockTransport())
+	ctx := context.Background()
+
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(displayName, protoreflect.ValueOfString("Ada"))
+
+	v, err := rt.ResolveSync(ctx, "Customer", "name", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Ada", v)
+
+	// An unset intermediate message makes the whole path unset
+	v, err = rt.ResolveSync(ctx, "Customer", "city", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "unknown", v)
+
+	addr := dynamicpb.NewMessage(address.Message())
+	addr.Set(city, protoreflect.ValueOfString("London"))
+	msg.Set(address, protoreflect.ValueOfMessage(addr))
+	v, err = rt.ResolveSync(ctx, "Customer", "city", msg, nil)
+	require.NoError(t, err)
+	require.Equal(t, "London", v)
+}
@@ -0,0 +1,82 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+func requireMisconfiguration(t *testing.T, err error, objectType, field string) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, errcode.InternalServerError, errcode.Of(err))
+	var merr *MisconfigurationError
+	require.ErrorAs(t, err, &merr)
+	require.Equal(t, objectType, merr.ObjectType)
+	require.Equal(t, field, merr.Field)
+}
+
+func TestNonStrict_ResolveSyncFailsTheField(t *testing.T) {
+	rt := NewRuntime(NewMockRegistry(), nil, WithStrict(false))
+	msg := dynamicpb.NewMessage(buildSimpleMessage(t, "UserSource", "title"))
+
+	_, err := rt.ResolveSync(context.Background(), "User", "title", msg, nil)
+	requireMisconfiguration(t, err, "User", "title")
+	require.Contains(t, err.Error(), "missing FieldDescriptor for User.title")
+
+	_, err = rt.ResolveSync(context.Background(), "User", "title", "not a message", nil)
+	requireMisconfiguration(t, err, "User", "title")
+}
+
+func TestNonStrict_MissingMethodFailsOnlyItsGroup(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().RegisterBatchResolver("Query", "items", md)
+	tr := NewMockTransport(batchResponse(md, "a"))
+	rt := NewRuntime(reg, tr, WithStrict(false))
+
+	results := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "missing"},
+		{ObjectType: "Query", Field: "items"},
+		{ObjectType: "Query", Field: "missing"},
+	})
+	requireMisconfiguration(t, results[0].Error, "Query", "missing")
+	requireMisconfiguration(t, results[2].Error, "Query", "missing")
+	require.NoError(t, results[1].Error)
+	require.Equal(t, "a", results[1].Value)
+}
+
+func TestNonStrict_InterfaceEnvelopeOfUnknownType(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    protoString("strict_iface.proto"),
+		Package: protoString("strict"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: protoString("NodeSource"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: protoString("typename"), JsonName: protoString("typename"), Number: protoInt32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: protoString("payload"), JsonName: protoString("payload"), Number: protoInt32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum()},
+			},
+		}},
+		Syntax: protoString("proto3"),
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+	desc := fd.Messages().ByName("NodeSource")
+	msg := dynamicpb.NewMessage(desc)
+	msg.Set(desc.Fields().ByName("typename"), protoreflect.ValueOfString("Ghost"))
+	msg.Set(desc.Fields().ByName("payload"), protoreflect.ValueOfBytes([]byte{0x0a, 0x00}))
+
+	strict := NewRuntime(NewMockRegistry(), nil)
+	require.Panics(t, func() { _, _ = strict.ResolveInterfaceConcreteValue(context.Background(), "Node", msg) })
+
+	rt := NewRuntime(NewMockRegistry(), nil, WithStrict(false))
+	_, err = rt.ResolveInterfaceConcreteValue(context.Background(), "Node", msg)
+	requireMisconfiguration(t, err, "Node", "")
+}
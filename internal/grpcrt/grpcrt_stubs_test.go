@@ -0,0 +1,97 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/schema"
+)
+
+func stubSchema() *schema.Schema {
+	named := func(name string) *schema.TypeRef { return &schema.TypeRef{Kind: schema.TypeRefKindNamed, Named: name} }
+	nonNull := func(t *schema.TypeRef) *schema.TypeRef {
+		return &schema.TypeRef{Kind: schema.TypeRefKindNonNull, OfType: t}
+	}
+	list := func(t *schema.TypeRef) *schema.TypeRef {
+		return &schema.TypeRef{Kind: schema.TypeRefKindList, OfType: t}
+	}
+
+	sch := schema.NewSchema("")
+	sch.AddType(schema.NewType("Query", schema.TypeKindObject, "").
+		AddField(schema.NewField("title", "", nonNull(named("String")))).
+		AddField(schema.NewField("count", "", named("Int"))).
+		AddField(schema.NewField("tags", "", nonNull(list(named("String"))))).
+		AddField(schema.NewField("color", "", named("Color"))).
+		AddField(schema.NewField("item", "", nonNull(named("Item")))).
+		AddField(schema.NewField("search", "", named("Result"))))
+	sch.AddType(schema.NewType("Color", schema.TypeKindEnum, "").
+		AddEnumValue(schema.NewEnumValue("RED", "")).
+		AddEnumValue(schema.NewEnumValue("BLUE", "")))
+	sch.AddType(schema.NewType("Item", schema.TypeKindObject, ""))
+	sch.AddType(schema.NewType("Result", schema.TypeKindUnion, "").AddPossibleType("Item"))
+	return sch
+}
+
+func TestStubs_ServesPlaceholdersWithWarnings(t *testing.T) {
+	md := buildLeafMessage(t)
+	reg := NewMockRegistry().RegisterSourceMessage("Item", md)
+	rt := NewRuntime(reg, nil, WithStubs(stubSchema()))
+	x := &executor.ResponseExtensions{}
+	ctx := executor.ContextWithExtensions(context.Background(), x)
+
+	fields := []string{"title", "count", "tags", "color", "item", "search"}
+	tasks := make([]executor.AsyncResolveTask, 0, len(fields)+1)
+	for _, f := range fields {
+		tasks = append(tasks, executor.AsyncResolveTask{ObjectType: "Query", Field: f})
+	}
+	tasks = append(tasks, executor.AsyncResolveTask{ObjectType: "Query", Field: "title"})
+	results := rt.BatchResolveAsync(ctx, tasks)
+
+	require.Equal(t, "Query.title", results[0].Value)
+	require.Equal(t, int32(0), results[1].Value)
+	require.Equal(t, []any{}, results[2].Value)
+	require.Equal(t, "RED", results[3].Value)
+	msg, ok := results[4].Value.(protoreflect.Message)
+	require.True(t, ok)
+	require.Equal(t, md.FullName(), msg.Descriptor().FullName())
+	require.Nil(t, results[5].Value)
+	require.Equal(t, "Query.title", results[6].Value)
+	for _, res := range results {
+		require.NoError(t, res.Error)
+	}
+
+	// one warning per stubbed field, not per task
+	warnings, _ := x.Get("warnings").([]any)
+	require.Len(t, warnings, len(fields))
+	coordinates := map[string]bool{}
+	for _, w := range warnings {
+		coordinates[w.(map[string]any)["field"].(string)] = true
+	}
+	require.True(t, coordinates["Query.title"])
+	require.True(t, coordinates["Query.search"])
+}
+
+func TestStubs_RegisteredFieldsStillCallBackends(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().RegisterBatchResolver("Query", "title", md)
+	tr := NewMockTransportWithErrors(nil, []error{errcode.Errorf(errcode.DownstreamServiceError, "boom")})
+	rt := NewRuntime(reg, tr, WithStubs(stubSchema()))
+	x := &executor.ResponseExtensions{}
+	ctx := executor.ContextWithExtensions(context.Background(), x)
+
+	results := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{{ObjectType: "Query", Field: "title"}})
+	require.Error(t, results[0].Error)
+	require.Nil(t, x.Get("warnings"))
+}
+
+func TestStubs_PanicsWithoutOption(t *testing.T) {
+	rt := NewRuntime(NewMockRegistry(), nil)
+	require.Panics(t, func() {
+		rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{{ObjectType: "Query", Field: "title"}})
+	})
+}
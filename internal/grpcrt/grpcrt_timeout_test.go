@@ -0,0 +1,65 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/errcode"
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// hangingTransport echoes batch calls, except that calls of "hang" wait for their
+// context to end.
+type hangingTransport struct{}
+
+func (hangingTransport) Call(ctx context.Context, md protoreflect.MethodDescriptor, req protoreflect.Message) (protoreflect.Message, error) {
+	item := req.Get(md.Input().Fields().ByName("batches")).List().Get(0).Message()
+	data := item.Get(item.Descriptor().Fields().ByName("data")).String()
+	if data == "hang" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return batchResponse(md, data), nil
+}
+
+func TestBatchResolveAsync_TimeoutCutsSlowField(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchLoader("Query", "slow", md).
+		RegisterBatchLoader("Query", "fast", md).
+		RegisterTimeout("Query", "slow", 20*time.Millisecond).
+		RegisterTimeout("Query", "fast", 20*time.Millisecond)
+	rt := NewRuntime(reg, hangingTransport{})
+
+	start := time.Now()
+	results := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "slow", Args: map[string]any{"data": "hang"}},
+		{ObjectType: "Query", Field: "fast", Args: map[string]any{"data": "fast"}},
+	})
+	require.Less(t, time.Since(start), time.Second)
+
+	require.Error(t, results[0].Error)
+	require.Equal(t, errcode.DownstreamServiceError, errcode.Of(results[0].Error))
+	require.EqualError(t, results[0].Error, "Query.slow timed out after 20ms")
+	require.NoError(t, results[1].Error)
+	require.Equal(t, "fast", results[1].Value)
+}
+
+func TestBatchResolveAsync_RequestDeadlineIsNotReportedAsTimeout(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	reg := NewMockRegistry().
+		RegisterBatchLoader("Query", "slow", md).
+		RegisterTimeout("Query", "slow", time.Minute)
+	rt := NewRuntime(reg, hangingTransport{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results := rt.BatchResolveAsync(ctx, []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "slow", Args: map[string]any{"data": "hang"}},
+	})
+	require.ErrorIs(t, results[0].Error, context.DeadlineExceeded)
+}
@@ -11,7 +11,10 @@ import (
     "google.golang.org/protobuf/types/descriptorpb"
     "google.golang.org/protobuf/types/dynamicpb"
 
+    "github.com/hanpama/protograph/internal/errcode"
     executor "github.com/hanpama/protograph/internal/executor"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
 )
 
 // Build a proto file with a batch resolver method with the required shape.
@@ -308,3 +311,61 @@ func Test_5_3_Transport_ErrorPropagatesToGroupOrElement(t *testing.T) {
     require.NoError(t, resSingle[1].Error)
     require.Equal(t, "OK", resSingle[1].Value)
 }
+
+func Test_5_4_Transport_ErrorsCarryCodesFromGRPCStatus(t *testing.T) {
+    bmd := buildBatchResolverDescriptors(t)
+    for _, tc := range []struct {
+        err  error
+        want errcode.Code
+    }{
+        {status.Error(codes.Unauthenticated, "no token"), errcode.Unauthenticated},
+        {status.Error(codes.PermissionDenied, "denied"), errcode.Forbidden},
+        {status.Error(codes.InvalidArgument, "bad arg"), errcode.BadUserInput},
+        {status.Error(codes.Unavailable, "down"), errcode.DownstreamServiceError},
+        {errors.New("boom"), errcode.DownstreamServiceError},
+        {errcode.Errorf(errcode.Forbidden, "policy"), errcode.Forbidden},
+    } {
+        reg := NewMockRegistry().RegisterBatchResolver("User", "friends", bmd)
+        rt := NewRuntime(reg, NewMockTransportWithErrors(nil, []error{tc.err}))
+        res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+            {ObjectType: "User", Field: "friends", Args: map[string]any{"arg1": "x"}},
+        })
+        require.ErrorIs(t, res[0].Error, tc.err)
+        require.Equal(t, tc.want, errcode.Of(res[0].Error), tc.err.Error())
+    }
+}
+
+func Test_5_5_Transport_ErrorPoliciesRewriteFailedResults(t *testing.T) {
+    bmd := buildBatchResolverDescriptors(t)
+    backendErr := status.Error(codes.PermissionDenied, "secret backend detail")
+    for _, tc := range []struct {
+        name    string
+        policy  *ErrorPolicy
+        want    any
+        wantErr string
+    }{
+        {"no policy", nil, nil, backendErr.Error()},
+        {"propagate", &ErrorPolicy{Action: ErrorPropagate}, nil, backendErr.Error()},
+        {"null", &ErrorPolicy{Action: ErrorNull}, nil, "User.friends is unavailable"},
+        {"default", &ErrorPolicy{Action: ErrorDefault, Value: "fallback"}, "fallback", ""},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            reg := NewMockRegistry().RegisterBatchResolver("User", "friends", bmd)
+            if tc.policy != nil {
+                reg.RegisterErrorPolicy("User", "friends", *tc.policy)
+            }
+            rt := NewRuntime(reg, NewMockTransportWithErrors(nil, []error{backendErr}))
+            res := rt.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+                {ObjectType: "User", Field: "friends", Args: map[string]any{"arg1": "x"}},
+            })
+            require.Equal(t, tc.want, res[0].Value)
+            if tc.wantErr == "" {
+                require.NoError(t, res[0].Error)
+                return
+            }
+            require.EqualError(t, res[0].Error, tc.wantErr)
+            // The generic message keeps the code of the hidden error
+            require.Equal(t, errcode.Forbidden, errcode.Of(res[0].Error))
+        })
+    }
+}
@@ -0,0 +1,67 @@
+package grpcrt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/hanpama/protograph/internal/executor"
+)
+
+func TestUnknownEnum_Policies(t *testing.T) {
+	md := buildLeafMessage(t)
+	msg := dynamicpb.NewMessage(md)
+	color := md.Fields().ByName("color")
+	msg.Set(color, protoreflect.ValueOfEnum(7))
+
+	for _, tc := range []struct {
+		name     string
+		policy   *UnknownEnumPolicy
+		want     any
+		warnings int
+	}{
+		{name: "no policy", want: int32(7)},
+		{name: "null", policy: &UnknownEnumPolicy{Action: UnknownEnumNull}, want: nil, warnings: 1},
+		{name: "string", policy: &UnknownEnumPolicy{Action: UnknownEnumString}, want: "7"},
+		{name: "value", policy: &UnknownEnumPolicy{Action: UnknownEnumValue, Value: "UNKNOWN"}, want: "UNKNOWN"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := NewMockRegistry().RegisterSourceField("Item", "color", color)
+			if tc.policy != nil {
+				reg.RegisterUnknownEnumPolicy("Color", *tc.policy)
+			}
+			rt := NewRuntime(reg, nil).(*Runtime)
+			x := &executor.ResponseExtensions{}
+			ctx := executor.ContextWithExtensions(context.Background(), x)
+
+			v, err := rt.ResolveSync(ctx, "Item", "color", msg, nil)
+			require.NoError(t, err)
+			got, err := rt.SerializeLeafValue(ctx, "Color", v)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+
+			warnings, _ := x.Get("warnings").([]any)
+			require.Len(t, warnings, tc.warnings)
+
+			// Unknown numbers are never written by the leaf fast path
+			_, ok := rt.CompleteLeafObject(ctx, "Item", msg, []executor.LeafField{{ResponseName: "color", Field: "color", Type: "Color"}})
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestUnknownEnum_KnownValuesAndOtherScalars(t *testing.T) {
+	reg := NewMockRegistry().RegisterUnknownEnumPolicy("Color", UnknownEnumPolicy{Action: UnknownEnumNull})
+	rt := NewRuntime(reg, nil)
+	ctx := context.Background()
+
+	got, err := rt.SerializeLeafValue(ctx, "Color", "RED")
+	require.NoError(t, err)
+	require.Equal(t, "RED", got)
+	got, err = rt.SerializeLeafValue(ctx, "Int", int32(7))
+	require.NoError(t, err)
+	require.Equal(t, int32(7), got)
+}
@@ -0,0 +1,56 @@
+package grpcrt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hanpama/protograph/internal/schema"
+)
+
+func verifySchema() *schema.Schema {
+	named := func(name string) *schema.TypeRef { return &schema.TypeRef{Kind: schema.TypeRefKindNamed, Named: name} }
+
+	sch := schema.NewSchema("")
+	sch.AddType(schema.NewType("Query", schema.TypeKindObject, "").
+		AddField(schema.NewField("items", "", named("String")).SetAsync(true).
+			AddArgument(schema.NewInputValue("data", "", named("String"))).
+			AddArgument(schema.NewInputValue("limit", "", named("Int"))).
+			AddArgument(schema.NewInputValue("tenant", "", named("String")))).
+		AddField(schema.NewField("missing", "", named("String")).SetAsync(true)).
+		AddField(schema.NewField("version", "", named("String"))))
+	sch.AddType(schema.NewType("Item", schema.TypeKindObject, "").
+		AddField(schema.NewField("color", "", named("String"))).
+		AddField(schema.NewField("label", "", named("String"))).
+		AddField(schema.NewField("size", "", named("String"))))
+	return sch
+}
+
+func TestVerify_ReportsEveryGap(t *testing.T) {
+	md := buildBatchForResponseTests(t)
+	color := buildLeafMessage(t).Fields().ByName("color")
+	reg := NewMockRegistry().
+		RegisterBatchResolver("Query", "items", md).
+		RegisterMetadataArgument("Query", "items", "tenant", "x-tenant").
+		RegisterConstField("Query", "version", "v1").
+		RegisterSourceField("Item", "color", color).
+		RegisterComputedField("Item", "label", "color")
+
+	err := Verify(reg, verifySchema())
+	var verr *VerificationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, []string{
+		"Query.items: request rsvc.ItemOut has no field for argument limit",
+		"Query.missing: async field has no resolver or loader descriptor",
+		"Item.size: sync field has no source field descriptor",
+	}, verr.Problems)
+	require.Contains(t, err.Error(), "3 problems")
+}
+
+func TestVerify_CoveredSchema(t *testing.T) {
+	reg := NewMockRegistry().RegisterConstField("Query", "version", "v1")
+	sch := schema.NewSchema("")
+	sch.AddType(schema.NewType("Query", schema.TypeKindObject, "").
+		AddField(schema.NewField("version", "", &schema.TypeRef{Kind: schema.TypeRefKindNamed, Named: "String"})))
+	require.NoError(t, Verify(reg, sch))
+}
@@ -0,0 +1,66 @@
+package grpcrt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// IDType is the proto type GraphQL IDs are sent to backends as. IDs are strings
+// in GraphQL whichever it is.
+type IDType int
+
+const (
+	// IDString sends IDs as proto strings.
+	IDString IDType = iota
+	// IDInt64 sends IDs as int64, written in decimal in GraphQL.
+	IDInt64
+	// IDUUID sends IDs as 16 bytes, written as canonical UUID strings in GraphQL.
+	IDUUID
+)
+
+// formatID returns the GraphQL form of an ID value read from a backend: strings as
+// is, int64 in decimal and 16 bytes as a canonical UUID.
+func formatID(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case []byte:
+		if len(v) != 16 {
+			return "", false
+		}
+		buf := make([]byte, 36)
+		hex.Encode(buf[0:8], v[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], v[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], v[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], v[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:], v[10:])
+		return string(buf), true
+	}
+	return "", false
+}
+
+// parseUUID decodes a UUID in its canonical form, or as 32 hex digits, to its 16 bytes.
+func parseUUID(s string) ([]byte, error) {
+	digits := s
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, fmt.Errorf("invalid UUID %q", s)
+		}
+		digits = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(digits) != 32 {
+		return nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID %q", s)
+	}
+	return b, nil
+}
@@ -0,0 +1,234 @@
+package grpcrt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hanpama/protograph/internal/executor"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var _ executor.LeafObjectCompleter = (*Runtime)(nil)
+
+// CompleteLeafObject writes the selected fields of a source message straight to
+// JSON. Plain physical fields are read from protoreflect values without boxing
+// them, unset ones as their proto default with WithProtoDefaults; @const,
+// @compute, @source, @default and global ID fields go through ResolveSync. It
+// reports false, leaving the object to the executor, when the source is not a
+// message, a NonNull field is unset, a value has no JSON form, or an enum
+// number is unknown.
+func (r *Runtime) CompleteLeafObject(ctx context.Context, objectType string, source any, fields []executor.LeafField) (json.RawMessage, bool) {
+	msg, ok := source.(protoreflect.Message)
+	if !ok || msg == nil {
+		return nil, false
+	}
+	buf := make([]byte, 0, 2+24*len(fields))
+	buf = append(buf, '{')
+	for i, f := range fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, f.ResponseName)
+		buf = append(buf, ':')
+		if f.Field == "__typename" {
+			buf = appendJSONString(buf, objectType)
+			continue
+		}
+		if fd := r.plainSourceField(objectType, f.Field); fd != nil && (f.Type != "ID" || fd.Kind() == protoreflect.StringKind) {
+			if fd = messageField(msg, fd); fd == nil || (!msg.Has(fd) && !r.zeroValued(fd)) {
+				if f.NonNull {
+					return nil, false
+				}
+				buf = append(buf, "null"...)
+				continue
+			}
+			if buf, ok = r.appendProtoValue(buf, fd, msg.Get(fd)); !ok {
+				return nil, false
+			}
+			continue
+		}
+		v, err := r.ResolveSync(ctx, objectType, f.Field, msg, nil)
+		if err != nil || (v == nil && f.NonNull) {
+			return nil, false
+		}
+		if _, ok := v.(int32); ok {
+			if _, ok := r.reg.GetUnknownEnumPolicy(f.Type); ok {
+				return nil, false
+			}
+		}
+		if id, ok := formatID(v); ok && f.Type == "ID" {
+			v = id
+		}
+		if buf, ok = appendJSONValue(buf, v); !ok {
+			return nil, false
+		}
+	}
+	return append(buf, '}'), true
+}
+
+// plainSourceField returns the descriptor of a field read as is from the source
+// message, or nil when ResolveSync applies any mapping to it.
+func (r *Runtime) plainSourceField(objectType, field string) protoreflect.FieldDescriptor {
+	if _, ok := r.reg.GetConstField(objectType, field); ok {
+		return nil
+	}
+	if _, ok := r.reg.GetComputedField(objectType, field); ok {
+		return nil
+	}
+	if _, ok := r.reg.GetSourceFieldDefault(objectType, field); ok {
+		return nil
+	}
+	if len(r.reg.GetSourceFieldPath(objectType, field)) > 0 || r.reg.IsGlobalIDField(objectType, field) {
+		return nil
+	}
+	fd := r.reg.GetSourceFieldDescriptor(objectType, field)
+	if fd == nil || fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return nil
+	}
+	return fd
+}
+
+// appendProtoValue appends the JSON form of a scalar or enum field value, matching
+// handleValue followed by SerializeLeafValue and encoding/json.
+func (r *Runtime) appendProtoValue(buf []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) ([]byte, bool) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.AppendBool(buf, v.Bool()), true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.AppendInt(buf, v.Int(), 10), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.AppendUint(buf, v.Uint(), 10), true
+	case protoreflect.FloatKind:
+		return appendJSONFloat(buf, v.Float(), 32)
+	case protoreflect.DoubleKind:
+		return appendJSONFloat(buf, v.Float(), 64)
+	case protoreflect.StringKind:
+		return appendJSONString(buf, v.String()), true
+	case protoreflect.BytesKind:
+		return appendBase64(buf, v.Bytes()), true
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return appendJSONString(buf, r.graphQLEnumValue(ev)), true
+		}
+		// Unknown numbers are left to SerializeLeafValue, which applies @onUnknown
+		return buf, false
+	}
+	return buf, false
+}
+
+// appendJSONValue appends a value returned by ResolveSync as SerializeLeafValue
+// and encoding/json would.
+func appendJSONValue(buf []byte, v any) ([]byte, bool) {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...), true
+	case string:
+		return appendJSONString(buf, v), true
+	case bool:
+		return strconv.AppendBool(buf, v), true
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10), true
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10), true
+	case int64:
+		return strconv.AppendInt(buf, v, 10), true
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10), true
+	case uint64:
+		return strconv.AppendUint(buf, v, 10), true
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case []byte:
+		return appendBase64(buf, v), true
+	}
+	return buf, false
+}
+
+// appendJSONFloat formats f like encoding/json. NaN and infinities have no JSON form.
+func appendJSONFloat(buf []byte, f float64, bits int) ([]byte, bool) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return buf, false
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	return buf, true
+}
+
+func appendBase64(buf []byte, b []byte) []byte {
+	buf = append(buf, '"')
+	buf = base64.StdEncoding.AppendEncode(buf, b)
+	return append(buf, '"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString quotes s like encoding/json, including its HTML escaping.
+func appendJSONString(buf []byte, s string) []byte {
+	mark := len(buf)
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// Invalid UTF-8 is rare and encoded differently across encoding/json
+			// versions, so defer to it for the whole string.
+			quoted, _ := json.Marshal(s)
+			return append(buf[:mark], quoted...)
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,68 @@
+package grpcrt
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/hanpama/protograph/internal/cache"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// projections caches the descriptor lookups repeated for every task of a
+// batch: the fields of request messages by JSON name, the source paths of the
+// fields of object types and the request builders of fields. Both derive from the registry and the descriptors
+// alone, so entries never go stale.
+type projections struct {
+	// messages maps protoreflect.MessageDescriptor to its fields by JSON name
+	messages sync.Map
+	// sources maps [2]string{objectType, field} to its source path, nil for
+	// fields without one
+	sources sync.Map
+	// requests maps requestKey to its *requestBuilder
+	requests sync.Map
+
+	hits, misses atomic.Uint64
+}
+
+// ProjectionStats returns the lookups of the descriptor caches so far.
+func (r *Runtime) ProjectionStats() cache.Stats {
+	return cache.Stats{Hits: r.projections.hits.Load(), Misses: r.projections.misses.Load()}
+}
+
+// jsonFields returns the fields of desc by JSON name.
+func (r *Runtime) jsonFields(desc protoreflect.MessageDescriptor) map[string]protoreflect.FieldDescriptor {
+	if v, ok := r.projections.messages.Load(desc); ok {
+		r.projections.hits.Add(1)
+		return v.(map[string]protoreflect.FieldDescriptor)
+	}
+	r.projections.misses.Add(1)
+	fields := desc.Fields()
+	byJSON := make(map[string]protoreflect.FieldDescriptor, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		byJSON[string(f.JSONName())] = f
+	}
+	r.projections.messages.Store(desc, byJSON)
+	return byJSON
+}
+
+// sourceFieldPath returns the descriptors leading from the source message of objectType
+// to the value of field: the @source path when declared, otherwise the field itself.
+// Returns nil when the field is not read from the source message.
+func (r *Runtime) sourceFieldPath(objectType, field string) []protoreflect.FieldDescriptor {
+	key := [2]string{objectType, field}
+	if v, ok := r.projections.sources.Load(key); ok {
+		r.projections.hits.Add(1)
+		return v.([]protoreflect.FieldDescriptor)
+	}
+	r.projections.misses.Add(1)
+	path := r.reg.GetSourceFieldPath(objectType, field)
+	if len(path) == 0 {
+		path = nil
+		if fd := r.reg.GetSourceFieldDescriptor(objectType, field); fd != nil {
+			path = []protoreflect.FieldDescriptor{fd}
+		}
+	}
+	r.projections.sources.Store(key, path)
+	return path
+}
@@ -0,0 +1,161 @@
+package grpcrt
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MethodQuota caps the calls of a backend method in flight, so one field can't
+// saturate a fragile backend. Calls beyond it queue in arrival order until
+// enough of the quota is released, or their context ends.
+type MethodQuota struct {
+	// Method is "pkg.Service/Method".
+	Method string
+	// Limit is the capacity of the quota: calls in flight, or batch entries in
+	// flight with PerEntry.
+	Limit int
+	// PerEntry weighs each call by the entries of its batch request, so large
+	// batches take a bigger share of the quota. Calls weigh at least 1 and at
+	// most Limit.
+	PerEntry bool
+}
+
+// ParseMethodQuota parses "<method>=<limit>[,entries]", e.g.
+// "search.SearchService/Search=4" or "blog.PostService/BatchGetPost=200,entries".
+func ParseMethodQuota(s string) (MethodQuota, error) {
+	method, settings, ok := strings.Cut(s, "=")
+	q := MethodQuota{Method: strings.TrimSpace(method)}
+	if !ok || !strings.Contains(q.Method, "/") {
+		return MethodQuota{}, fmt.Errorf("grpcrt: invalid method quota %q", s)
+	}
+	limit, weight, _ := strings.Cut(settings, ",")
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n <= 0 {
+		return MethodQuota{}, fmt.Errorf("grpcrt: invalid limit in method quota %q", s)
+	}
+	q.Limit = n
+	switch strings.TrimSpace(weight) {
+	case "":
+	case "entries":
+		q.PerEntry = true
+	default:
+		return MethodQuota{}, fmt.Errorf("grpcrt: invalid weight %q in method quota %q", weight, s)
+	}
+	return q, nil
+}
+
+// WithMethodQuotas caps the calls in flight of each method of quotas across
+// every execution of the Runtime, coalesced calls included.
+func WithMethodQuotas(quotas ...MethodQuota) Option {
+	return func(r *Runtime) {
+		if r.quotas == nil {
+			r.quotas = map[string]*quota{}
+		}
+		for _, q := range quotas {
+			r.quotas[q.Method] = &quota{MethodQuota: q}
+		}
+	}
+}
+
+// quotaTransport holds the calls of methods with a quota until it admits them.
+type quotaTransport struct {
+	next   Transport
+	quotas map[string]*quota
+}
+
+// Call implements Transport.
+func (t quotaTransport) Call(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
+	q := t.quotas[string(method.Parent().FullName())+"/"+string(method.Name())]
+	if q == nil {
+		return t.next.Call(ctx, method, request)
+	}
+	n := q.weight(request)
+	if err := q.acquire(ctx, n); err != nil {
+		return nil, err
+	}
+	defer q.release(n)
+	return t.next.Call(ctx, method, request)
+}
+
+// quota is a weighted semaphore admitting waiters in FIFO order, so heavy
+// calls are not starved by light ones.
+type quota struct {
+	MethodQuota
+
+	mu      sync.Mutex
+	used    int
+	waiters list.List // of *quotaWaiter
+}
+
+type quotaWaiter struct {
+	n     int
+	ready chan struct{}
+}
+
+// weight returns the share of the quota taken by a call of request.
+func (q *quota) weight(request protoreflect.Message) int {
+	n := 1
+	if q.PerEntry {
+		if fd := request.Descriptor().Fields().ByName("batches"); fd != nil && fd.IsList() {
+			n = max(request.Get(fd).List().Len(), 1)
+		}
+	}
+	return min(n, q.Limit)
+}
+
+func (q *quota) acquire(ctx context.Context, n int) error {
+	q.mu.Lock()
+	if q.waiters.Len() == 0 && q.used+n <= q.Limit {
+		q.used += n
+		q.mu.Unlock()
+		return nil
+	}
+	w := &quotaWaiter{n: n, ready: make(chan struct{})}
+	elem := q.waiters.PushBack(w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	select {
+	case <-w.ready:
+		// Admitted meanwhile: give the share back
+		q.used -= n
+	default:
+		q.waiters.Remove(elem)
+	}
+	// Waiters held back by this one may fit now
+	q.admit()
+	q.mu.Unlock()
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+func (q *quota) release(n int) {
+	q.mu.Lock()
+	q.used -= n
+	q.admit()
+	q.mu.Unlock()
+}
+
+// admit wakes the waiters at the front of the queue fitting in the quota.
+func (q *quota) admit() {
+	for e := q.waiters.Front(); e != nil; e = q.waiters.Front() {
+		w := e.Value.(*quotaWaiter)
+		if q.used+w.n > q.Limit {
+			return
+		}
+		q.used += w.n
+		q.waiters.Remove(e)
+		close(w.ready)
+	}
+}
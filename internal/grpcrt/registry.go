@@ -1,30 +1,167 @@
 package grpcrt
 
-import "google.golang.org/protobuf/reflect/protoreflect"
+import (
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/compute"
+)
 
 type Registry interface {
 	// GetSourceFieldDescriptor returns the proto field descriptor for a GraphQL field in the given object type
 	// Returns nil if not found
 	GetSourceFieldDescriptor(objectType, graphqlField string) protoreflect.FieldDescriptor
 
+	// GetSourceFieldPath returns the chain of field descriptors leading from the source
+	// message of objectType to the value of a field projected with @source.
+	// Returns nil for fields read directly via GetSourceFieldDescriptor.
+	GetSourceFieldPath(objectType, graphqlField string) []protoreflect.FieldDescriptor
+
 	// GetSourceMessageDescriptor returns the proto message descriptor for a GraphQL object type.
 	GetSourceMessageDescriptor(objectType string) protoreflect.MessageDescriptor
+	// GetMessageObjectType returns the GraphQL object type whose source message is
+	// message, e.g. to resolve the type URL of a google.protobuf.Any envelope.
+	GetMessageObjectType(message protoreflect.FullName) (string, bool)
 
 	// Resolver methods
 	// GetSingleResolverDescriptor returns the method descriptor for a single resolver field
 	GetSingleResolverDescriptor(objectType, field string) protoreflect.MethodDescriptor
 	// GetBatchResolverDescriptor returns the method descriptor for a batch resolver field
 	GetBatchResolverDescriptor(objectType, field string) protoreflect.MethodDescriptor
+	// GetFallbackResolverDescriptors returns the methods tried in order for the tasks
+	// the resolver of (objectType, field) fails. They take the same requests as the
+	// resolver, single or batch.
+	GetFallbackResolverDescriptors(objectType, field string) []protoreflect.MethodDescriptor
 
 	// Loader methods
 	// GetSingleLoaderDescriptor returns the method descriptor for a single loader field
 	GetSingleLoaderDescriptor(objectType, field string) protoreflect.MethodDescriptor
 	// GetBatchLoaderDescriptor returns the method descriptor for a batch loader field
 	GetBatchLoaderDescriptor(objectType, field string) protoreflect.MethodDescriptor
+	// IsKeyedLoader reports whether the results of a batch loader method carry their
+	// keys (@loader(keyed: true)). They are joined to tasks by key instead of position,
+	// so the backend may answer in any order and once per distinct key.
+	IsKeyedLoader(method protoreflect.FullName) bool
+	// GetResponseDataPath returns the fields leading from a response message, or a
+	// batch element, to the result when it is not the top-level `data` field
+	// (e.g. @resolve(data: "result.user")). Returns nil for `data`.
+	GetResponseDataPath(response protoreflect.FullName) []protoreflect.FieldDescriptor
 
 	// GetRequestFieldSourceMapping returns a mapping for a resolver/loader input field name
 	// (destination) to the parent source GraphQL field name (source). This is used to populate
 	// request fields from the parent object (e.g., explicit @resolve(with: { authorId: "id" })).
 	// When nil, no additional mapping is applied beyond provided args.
 	GetRequestFieldSourceMapping(objectType, field string) map[string]string
+	// GetRequestFieldSourceExpressions returns the request fields computed from several
+	// parent source fields, keyed by request field (e.g. @load(with: { key: "orgId + ':' + userId" })).
+	// They are evaluated like @compute expressions and apply where args and the plain
+	// mapping leave the request field unset.
+	GetRequestFieldSourceExpressions(objectType, field string) map[string]*compute.Expr
+
+	// Node routing (@node)
+	// IsNodeField reports whether (objectType, field) decodes a global ID and dispatches
+	// to the id loader of the concrete type instead of calling a resolver.
+	IsNodeField(objectType, field string) bool
+	// IsGlobalIDField reports whether (objectType, field) is the id of a Node implementer,
+	// exposed to GraphQL as an encoded global ID.
+	IsGlobalIDField(objectType, field string) bool
+	// GetSingleNodeLoaderDescriptor returns the single id loader of a Node implementer
+	GetSingleNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor
+	// GetBatchNodeLoaderDescriptor returns the batch id loader of a Node implementer
+	GetBatchNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor
+
+	// Connections (@connection)
+	// GetConnectionType returns the connection type of a @connection field. Its
+	// resolver takes an offset and a limit for the pagination arguments and returns
+	// a list, which the runtime pages into the source message of that type.
+	GetConnectionType(objectType, field string) (string, bool)
+
+	// Computed fields (@compute)
+	// GetComputedField returns the expression of a computed field, evaluated in
+	// ResolveSync over sibling source fields without any RPC.
+	GetComputedField(objectType, field string) (*compute.Expr, bool)
+
+	// Literal fields (@const / @default)
+	// GetConstField returns the value of a field that always resolves to an SDL literal.
+	// Const fields need no source message and may live on root types.
+	GetConstField(objectType, field string) (any, bool)
+	// GetSourceFieldDefault returns the value served when the source field is unset.
+	GetSourceFieldDefault(objectType, field string) (any, bool)
+
+	// Error policies (@onError)
+	// GetErrorPolicy returns how errors resolving (objectType, field) are reported.
+	// Fields without a policy propagate errors verbatim.
+	GetErrorPolicy(objectType, field string) (ErrorPolicy, bool)
+
+	// Field caching (@cache)
+	// GetCachePolicy returns how long results of (objectType, field) may be served
+	// from the field cache.
+	GetCachePolicy(objectType, field string) (CachePolicy, bool)
+
+	// Outgoing metadata (@metadata)
+	// GetMetadataArguments maps the arguments of (objectType, field) that are sent
+	// as gRPC metadata instead of request fields to their metadata keys.
+	GetMetadataArguments(objectType, field string) map[string]string
+
+	// Enum values
+	// GetGraphQLEnumValue returns the GraphQL name of a proto enum value. Values
+	// without one keep their proto name.
+	GetGraphQLEnumValue(value protoreflect.FullName) (string, bool)
+	// GetProtoEnumValue returns the name of the value of the proto enum standing for
+	// graphqlValue.
+	GetProtoEnumValue(enum protoreflect.FullName, graphqlValue string) (protoreflect.Name, bool)
+
+	// ID mapping (extend scalar ID @mapScalar)
+	// GetIDType returns the proto type IDs are sent to backends as.
+	GetIDType() IDType
+	// IsIDField reports whether field, of a request or input message, holds an
+	// ID argument or input field, whose strings are parsed per GetIDType.
+	IsIDField(field protoreflect.FullName) bool
+
+	// Unknown enum values (@onUnknown)
+	// GetUnknownEnumPolicy returns how numbers of enumType missing from its
+	// descriptor are resolved. Enums without a policy pass them through as int32.
+	GetUnknownEnumPolicy(enumType string) (UnknownEnumPolicy, bool)
+
+	// Abstract type resolution
+	// GetTypeDiscriminator returns the field whose value names the concrete type of
+	// the values of abstractType, for backends answering several members with one
+	// shared message. ResolveType consults it before the message name.
+	GetTypeDiscriminator(abstractType string) (TypeDiscriminator, bool)
+
+	// Call ordering (@priority)
+	// GetPriority returns when the RPCs of (objectType, field) are made within a
+	// batch of async tasks.
+	GetPriority(objectType, field string) Priority
+
+	// Call deadlines (@timeout)
+	// GetTimeout returns the deadline of each RPC of (objectType, field), or 0 when
+	// its calls only have the request's.
+	GetTimeout(objectType, field string) time.Duration
+
+	// Latency budgets (@sla)
+	// GetSLA returns the latency budget of resolving (objectType, field), or 0 when
+	// it has none.
+	GetSLA(objectType, field string) time.Duration
+}
+
+// Priority orders the groups of a BatchResolveAsync call. High priority groups
+// are called alone, normal ones once they have returned and low priority ones
+// last, keeping expensive fields from competing with latency-critical ones.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+	PriorityLow
+)
+
+// TypeDiscriminator resolves the concrete type of an abstract value from one of
+// its fields rather than from its message.
+type TypeDiscriminator struct {
+	// Field is the scalar or enum field of the value's message holding the discriminator
+	Field protoreflect.Name
+	// Types maps field values, enum values by name, to object types
+	Types map[string]string
 }
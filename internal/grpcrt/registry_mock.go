@@ -1,7 +1,11 @@
 package grpcrt
 
 import (
+	"time"
+
 	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/compute"
 )
 
 // MockRegistry is a test helper that allows tests to register
@@ -9,24 +13,75 @@ import (
 // them via the grpcrt.Registry interface.
 type MockRegistry struct {
 	sourceFields    map[[2]string]protoreflect.FieldDescriptor
+	sourcePaths     map[[2]string][]protoreflect.FieldDescriptor
 	singleResolvers map[[2]string]protoreflect.MethodDescriptor
 	batchResolvers  map[[2]string]protoreflect.MethodDescriptor
+	fallbacks       map[[2]string][]protoreflect.MethodDescriptor
 	singleLoaders   map[[2]string]protoreflect.MethodDescriptor
 	batchLoaders    map[[2]string]protoreflect.MethodDescriptor
+	keyedLoaders    map[protoreflect.FullName]struct{}
+	dataPaths       map[protoreflect.FullName][]protoreflect.FieldDescriptor
 	requestMap      map[[2]string]map[string]string
+	requestExprs    map[[2]string]map[string]*compute.Expr
 	sourceMessages  map[string]protoreflect.MessageDescriptor
+	messageTypes    map[protoreflect.FullName]string
+	discriminators  map[string]TypeDiscriminator
+	unknownEnums    map[string]UnknownEnumPolicy
+	idType          IDType
+	idFields        map[protoreflect.FullName]struct{}
+	enumValues      map[protoreflect.FullName]string
+	protoEnumValues map[protoreflect.FullName]map[string]protoreflect.Name
+	nodeFields      map[[2]string]struct{}
+	globalIDFields  map[[2]string]struct{}
+	connections     map[[2]string]string
+	singleNodes     map[string]protoreflect.MethodDescriptor
+	batchNodes      map[string]protoreflect.MethodDescriptor
+	computed        map[[2]string]*compute.Expr
+	consts          map[[2]string]any
+	defaults        map[[2]string]any
+	errorPolicies   map[[2]string]ErrorPolicy
+	cachePolicies   map[[2]string]CachePolicy
+	metadataArgs    map[[2]string]map[string]string
+	priorities      map[[2]string]Priority
+	timeouts        map[[2]string]time.Duration
+	slas            map[[2]string]time.Duration
 }
 
 // NewMockRegistry creates an empty MockRegistry.
 func NewMockRegistry() *MockRegistry {
 	return &MockRegistry{
 		sourceFields:    map[[2]string]protoreflect.FieldDescriptor{},
+		sourcePaths:     map[[2]string][]protoreflect.FieldDescriptor{},
 		singleResolvers: map[[2]string]protoreflect.MethodDescriptor{},
 		batchResolvers:  map[[2]string]protoreflect.MethodDescriptor{},
+		fallbacks:       map[[2]string][]protoreflect.MethodDescriptor{},
 		singleLoaders:   map[[2]string]protoreflect.MethodDescriptor{},
 		batchLoaders:    map[[2]string]protoreflect.MethodDescriptor{},
+		keyedLoaders:    map[protoreflect.FullName]struct{}{},
+		dataPaths:       map[protoreflect.FullName][]protoreflect.FieldDescriptor{},
 		requestMap:      map[[2]string]map[string]string{},
+		requestExprs:    map[[2]string]map[string]*compute.Expr{},
 		sourceMessages:  map[string]protoreflect.MessageDescriptor{},
+		messageTypes:    map[protoreflect.FullName]string{},
+		discriminators:  map[string]TypeDiscriminator{},
+		unknownEnums:    map[string]UnknownEnumPolicy{},
+		enumValues:      map[protoreflect.FullName]string{},
+		protoEnumValues: map[protoreflect.FullName]map[string]protoreflect.Name{},
+		idFields:        map[protoreflect.FullName]struct{}{},
+		nodeFields:      map[[2]string]struct{}{},
+		globalIDFields:  map[[2]string]struct{}{},
+		connections:     map[[2]string]string{},
+		singleNodes:     map[string]protoreflect.MethodDescriptor{},
+		batchNodes:      map[string]protoreflect.MethodDescriptor{},
+		computed:        map[[2]string]*compute.Expr{},
+		consts:          map[[2]string]any{},
+		defaults:        map[[2]string]any{},
+		errorPolicies:   map[[2]string]ErrorPolicy{},
+		cachePolicies:   map[[2]string]CachePolicy{},
+		metadataArgs:    map[[2]string]map[string]string{},
+		priorities:      map[[2]string]Priority{},
+		timeouts:        map[[2]string]time.Duration{},
+		slas:            map[[2]string]time.Duration{},
 	}
 }
 
@@ -48,6 +103,14 @@ func (m *MockRegistry) RegisterBatchResolver(objectType, field string, md protor
 	return m
 }
 
+// RegisterFallbackResolver appends md to the methods tried when the resolver of
+// (objectType, field) fails.
+func (m *MockRegistry) RegisterFallbackResolver(objectType, field string, md protoreflect.MethodDescriptor) *MockRegistry {
+	key := [2]string{objectType, field}
+	m.fallbacks[key] = append(m.fallbacks[key], md)
+	return m
+}
+
 // RegisterSingleLoader maps (objectType, field) to a single loader method.
 func (m *MockRegistry) RegisterSingleLoader(objectType, field string, md protoreflect.MethodDescriptor) *MockRegistry {
 	m.singleLoaders[[2]string{objectType, field}] = md
@@ -66,6 +129,49 @@ func (m *MockRegistry) RegisterSourceMessage(objectType string, md protoreflect.
 	return m
 }
 
+// RegisterMessageObjectType resolves values of message to objectType, whatever
+// the message is named.
+func (m *MockRegistry) RegisterMessageObjectType(message protoreflect.FullName, objectType string) *MockRegistry {
+	m.messageTypes[message] = objectType
+	return m
+}
+
+// RegisterTypeDiscriminator resolves the values of abstractType by one of their fields.
+func (m *MockRegistry) RegisterTypeDiscriminator(abstractType string, d TypeDiscriminator) *MockRegistry {
+	m.discriminators[abstractType] = d
+	return m
+}
+
+// RegisterIDType sets the proto type IDs are sent to backends as.
+func (m *MockRegistry) RegisterIDType(t IDType) *MockRegistry {
+	m.idType = t
+	return m
+}
+
+// RegisterIDField marks a request or input message field as holding IDs.
+func (m *MockRegistry) RegisterIDField(field protoreflect.FullName) *MockRegistry {
+	m.idFields[field] = struct{}{}
+	return m
+}
+
+// RegisterUnknownEnumPolicy sets how unknown numbers of enumType are resolved.
+func (m *MockRegistry) RegisterUnknownEnumPolicy(enumType string, policy UnknownEnumPolicy) *MockRegistry {
+	m.unknownEnums[enumType] = policy
+	return m
+}
+
+// RegisterEnumValue names a proto enum value graphqlValue in GraphQL. Values not
+// registered keep their proto name.
+func (m *MockRegistry) RegisterEnumValue(value protoreflect.EnumValueDescriptor, graphqlValue string) *MockRegistry {
+	m.enumValues[value.FullName()] = graphqlValue
+	enum := value.Parent().FullName()
+	if m.protoEnumValues[enum] == nil {
+		m.protoEnumValues[enum] = map[string]protoreflect.Name{}
+	}
+	m.protoEnumValues[enum][graphqlValue] = value.Name()
+	return m
+}
+
 // RegisterRequestSourceMap maps (objectType, field) to a request field -> parent source field mapping.
 // Example: { "authorId": "id" } to copy parent.id into request.authorId when not provided via args.
 func (m *MockRegistry) RegisterRequestSourceMap(objectType, field string, mp map[string]string) *MockRegistry {
@@ -73,6 +179,132 @@ func (m *MockRegistry) RegisterRequestSourceMap(objectType, field string, mp map
 	return m
 }
 
+// RegisterRequestSourceExpr parses expr and computes request field reqField of
+// (objectType, field) from the parent source with it. It panics on an invalid expression.
+func (m *MockRegistry) RegisterRequestSourceExpr(objectType, field, reqField, expr string) *MockRegistry {
+	e, err := compute.Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	k := [2]string{objectType, field}
+	if m.requestExprs[k] == nil {
+		m.requestExprs[k] = map[string]*compute.Expr{}
+	}
+	m.requestExprs[k][reqField] = e
+	return m
+}
+
+// RegisterNodeField marks (objectType, field) as a @node field.
+func (m *MockRegistry) RegisterNodeField(objectType, field string) *MockRegistry {
+	m.nodeFields[[2]string{objectType, field}] = struct{}{}
+	return m
+}
+
+// RegisterConnection marks (objectType, field) as a @connection field of connType.
+func (m *MockRegistry) RegisterConnection(objectType, field, connType string) *MockRegistry {
+	m.connections[[2]string{objectType, field}] = connType
+	return m
+}
+
+// RegisterGlobalIDField marks (objectType, field) as a Node id exposed as a global ID.
+func (m *MockRegistry) RegisterGlobalIDField(objectType, field string) *MockRegistry {
+	m.globalIDFields[[2]string{objectType, field}] = struct{}{}
+	return m
+}
+
+// RegisterSingleNodeLoader maps a Node implementer to its single id loader.
+func (m *MockRegistry) RegisterSingleNodeLoader(typeName string, md protoreflect.MethodDescriptor) *MockRegistry {
+	m.singleNodes[typeName] = md
+	return m
+}
+
+// RegisterComputedField parses expr and registers it for (objectType, field).
+// It panics on an invalid expression.
+func (m *MockRegistry) RegisterComputedField(objectType, field, expr string) *MockRegistry {
+	e, err := compute.Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	m.computed[[2]string{objectType, field}] = e
+	return m
+}
+
+// RegisterSourceFieldPath maps (objectType, field) to a nested source message path.
+func (m *MockRegistry) RegisterSourceFieldPath(objectType, field string, path ...protoreflect.FieldDescriptor) *MockRegistry {
+	m.sourcePaths[[2]string{objectType, field}] = path
+	return m
+}
+
+// RegisterConstField makes (objectType, field) always resolve to value.
+func (m *MockRegistry) RegisterConstField(objectType, field string, value any) *MockRegistry {
+	m.consts[[2]string{objectType, field}] = value
+	return m
+}
+
+// RegisterSourceFieldDefault sets the value served when the source field is unset.
+func (m *MockRegistry) RegisterSourceFieldDefault(objectType, field string, value any) *MockRegistry {
+	m.defaults[[2]string{objectType, field}] = value
+	return m
+}
+
+// RegisterErrorPolicy sets how errors resolving (objectType, field) are reported.
+func (m *MockRegistry) RegisterErrorPolicy(objectType, field string, policy ErrorPolicy) *MockRegistry {
+	m.errorPolicies[[2]string{objectType, field}] = policy
+	return m
+}
+
+// RegisterMetadataArgument sends arg of (objectType, field) as the metadata key.
+func (m *MockRegistry) RegisterMetadataArgument(objectType, field, arg, key string) *MockRegistry {
+	k := [2]string{objectType, field}
+	if m.metadataArgs[k] == nil {
+		m.metadataArgs[k] = map[string]string{}
+	}
+	m.metadataArgs[k][arg] = key
+	return m
+}
+
+// RegisterPriority sets the call priority of (objectType, field).
+func (m *MockRegistry) RegisterPriority(objectType, field string, p Priority) *MockRegistry {
+	m.priorities[[2]string{objectType, field}] = p
+	return m
+}
+
+// RegisterTimeout sets the deadline of each RPC of (objectType, field).
+func (m *MockRegistry) RegisterTimeout(objectType, field string, d time.Duration) *MockRegistry {
+	m.timeouts[[2]string{objectType, field}] = d
+	return m
+}
+
+// RegisterSLA sets the latency budget of (objectType, field).
+func (m *MockRegistry) RegisterSLA(objectType, field string, d time.Duration) *MockRegistry {
+	m.slas[[2]string{objectType, field}] = d
+	return m
+}
+
+// RegisterCachePolicy makes results of (objectType, field) cacheable.
+func (m *MockRegistry) RegisterCachePolicy(objectType, field string, policy CachePolicy) *MockRegistry {
+	m.cachePolicies[[2]string{objectType, field}] = policy
+	return m
+}
+
+// RegisterKeyedLoader marks a batch loader method as joining its results by key.
+func (m *MockRegistry) RegisterKeyedLoader(md protoreflect.MethodDescriptor) *MockRegistry {
+	m.keyedLoaders[md.FullName()] = struct{}{}
+	return m
+}
+
+// RegisterResponseDataPath declares where the result is in a response message.
+func (m *MockRegistry) RegisterResponseDataPath(response protoreflect.MessageDescriptor, path ...protoreflect.FieldDescriptor) *MockRegistry {
+	m.dataPaths[response.FullName()] = path
+	return m
+}
+
+// RegisterBatchNodeLoader maps a Node implementer to its batch id loader.
+func (m *MockRegistry) RegisterBatchNodeLoader(typeName string, md protoreflect.MethodDescriptor) *MockRegistry {
+	m.batchNodes[typeName] = md
+	return m
+}
+
 // ---- grpcrt.Registry implementation ----
 
 func (m *MockRegistry) GetSourceFieldDescriptor(objectType, graphqlField string) protoreflect.FieldDescriptor {
@@ -87,6 +319,10 @@ func (m *MockRegistry) GetBatchResolverDescriptor(objectType, field string) prot
 	return m.batchResolvers[[2]string{objectType, field}]
 }
 
+func (m *MockRegistry) GetFallbackResolverDescriptors(objectType, field string) []protoreflect.MethodDescriptor {
+	return m.fallbacks[[2]string{objectType, field}]
+}
+
 func (m *MockRegistry) GetSingleLoaderDescriptor(objectType, field string) protoreflect.MethodDescriptor {
 	return m.singleLoaders[[2]string{objectType, field}]
 }
@@ -95,12 +331,134 @@ func (m *MockRegistry) GetBatchLoaderDescriptor(objectType, field string) protor
 	return m.batchLoaders[[2]string{objectType, field}]
 }
 
+func (m *MockRegistry) IsKeyedLoader(method protoreflect.FullName) bool {
+	_, ok := m.keyedLoaders[method]
+	return ok
+}
+
+func (m *MockRegistry) GetMessageObjectType(message protoreflect.FullName) (string, bool) {
+	if objectType, ok := m.messageTypes[message]; ok {
+		return objectType, true
+	}
+	for objectType, md := range m.sourceMessages {
+		if md.FullName() == message {
+			return objectType, true
+		}
+	}
+	return "", false
+}
+
+func (m *MockRegistry) GetTypeDiscriminator(abstractType string) (TypeDiscriminator, bool) {
+	d, ok := m.discriminators[abstractType]
+	return d, ok
+}
+
+func (m *MockRegistry) GetGraphQLEnumValue(value protoreflect.FullName) (string, bool) {
+	name, ok := m.enumValues[value]
+	return name, ok
+}
+
+func (m *MockRegistry) GetProtoEnumValue(enum protoreflect.FullName, graphqlValue string) (protoreflect.Name, bool) {
+	name, ok := m.protoEnumValues[enum][graphqlValue]
+	return name, ok
+}
+
+func (m *MockRegistry) GetIDType() IDType {
+	return m.idType
+}
+
+func (m *MockRegistry) IsIDField(field protoreflect.FullName) bool {
+	_, ok := m.idFields[field]
+	return ok
+}
+
+func (m *MockRegistry) GetUnknownEnumPolicy(enumType string) (UnknownEnumPolicy, bool) {
+	p, ok := m.unknownEnums[enumType]
+	return p, ok
+}
+
+func (m *MockRegistry) GetResponseDataPath(response protoreflect.FullName) []protoreflect.FieldDescriptor {
+	return m.dataPaths[response]
+}
+
 func (m *MockRegistry) GetRequestFieldSourceMapping(objectType, field string) map[string]string {
 	return m.requestMap[[2]string{objectType, field}]
 }
 
+func (m *MockRegistry) GetRequestFieldSourceExpressions(objectType, field string) map[string]*compute.Expr {
+	return m.requestExprs[[2]string{objectType, field}]
+}
+
 func (m *MockRegistry) GetSourceMessageDescriptor(objectType string) protoreflect.MessageDescriptor {
 	return m.sourceMessages[objectType]
 }
 
+func (m *MockRegistry) IsNodeField(objectType, field string) bool {
+	_, ok := m.nodeFields[[2]string{objectType, field}]
+	return ok
+}
+
+func (m *MockRegistry) GetConnectionType(objectType, field string) (string, bool) {
+	connType, ok := m.connections[[2]string{objectType, field}]
+	return connType, ok
+}
+
+func (m *MockRegistry) IsGlobalIDField(objectType, field string) bool {
+	_, ok := m.globalIDFields[[2]string{objectType, field}]
+	return ok
+}
+
+func (m *MockRegistry) GetSingleNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor {
+	return m.singleNodes[typeName]
+}
+
+func (m *MockRegistry) GetBatchNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor {
+	return m.batchNodes[typeName]
+}
+
+func (m *MockRegistry) GetComputedField(objectType, field string) (*compute.Expr, bool) {
+	e, ok := m.computed[[2]string{objectType, field}]
+	return e, ok
+}
+
+func (m *MockRegistry) GetSourceFieldPath(objectType, field string) []protoreflect.FieldDescriptor {
+	return m.sourcePaths[[2]string{objectType, field}]
+}
+
+func (m *MockRegistry) GetConstField(objectType, field string) (any, bool) {
+	v, ok := m.consts[[2]string{objectType, field}]
+	return v, ok
+}
+
+func (m *MockRegistry) GetSourceFieldDefault(objectType, field string) (any, bool) {
+	v, ok := m.defaults[[2]string{objectType, field}]
+	return v, ok
+}
+
+func (m *MockRegistry) GetErrorPolicy(objectType, field string) (ErrorPolicy, bool) {
+	p, ok := m.errorPolicies[[2]string{objectType, field}]
+	return p, ok
+}
+
+func (m *MockRegistry) GetCachePolicy(objectType, field string) (CachePolicy, bool) {
+	p, ok := m.cachePolicies[[2]string{objectType, field}]
+	return p, ok
+}
+
+func (m *MockRegistry) GetMetadataArguments(objectType, field string) map[string]string {
+	return m.metadataArgs[[2]string{objectType, field}]
+}
+
+func (m *MockRegistry) GetPriority(objectType, field string) Priority {
+	return m.priorities[[2]string{objectType, field}]
+}
+
+func (m *MockRegistry) GetTimeout(objectType, field string) time.Duration {
+	return m.timeouts[[2]string{objectType, field}]
+}
+
+func (m *MockRegistry) GetSLA(objectType, field string) time.Duration {
+	return m.slas[[2]string{objectType, field}]
+}
+
 var _ Registry = (*MockRegistry)(nil)
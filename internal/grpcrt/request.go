@@ -0,0 +1,220 @@
+package grpcrt
+
+import (
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/hanpama/protograph/internal/compute"
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/schema"
+)
+
+// requestBuilder builds the requests of a field for one request message, the
+// input of a single method or the batches element of a batch one. It is compiled
+// once from the registry, so building a request walks only what the field maps:
+// the request fields by JSON name, the source paths of the request fields copied
+// from the parent source and the expressions computing the others.
+type requestBuilder struct {
+	r                 *Runtime
+	objectType, field string
+
+	// fields are the request fields by JSON name; nil for builders without a
+	// request message, which merge every mapped field
+	fields  map[string]protoreflect.FieldDescriptor
+	sources []sourceArg
+	exprs   []exprArg
+}
+
+// sourceArg is a request field copied from the parent source.
+type sourceArg struct {
+	dst  string
+	path []protoreflect.FieldDescriptor
+}
+
+// exprArg is a request field computed over the parent source.
+type exprArg struct {
+	dst  string
+	expr *compute.Expr
+}
+
+type requestKey struct {
+	objectType, field string
+	input             protoreflect.MessageDescriptor
+}
+
+// WithPrecompiledRequests compiles the request builders of the async fields of
+// sch, for their methods and fallbacks, when the Runtime is built rather than
+// on their first call. Fields it misses, such as @node dispatches, are still
+// compiled on first use.
+func WithPrecompiledRequests(sch *schema.Schema) Option {
+	return func(r *Runtime) {
+		for _, name := range sch.GetOrderedTypeNames() {
+			t := sch.Types[name]
+			if t.Kind != schema.TypeKindObject || strings.HasPrefix(name, "__") {
+				continue
+			}
+			for fieldName, f := range t.Fields {
+				if f.Async {
+					r.precompileRequests(name, fieldName)
+				}
+			}
+		}
+	}
+}
+
+// precompileRequests compiles the builders of every method of objectType.field.
+func (r *Runtime) precompileRequests(objectType, field string) {
+	for _, m := range []struct {
+		md    protoreflect.MethodDescriptor
+		batch bool
+	}{
+		{r.reg.GetBatchResolverDescriptor(objectType, field), true},
+		{r.reg.GetSingleResolverDescriptor(objectType, field), false},
+		{r.reg.GetBatchLoaderDescriptor(objectType, field), true},
+		{r.reg.GetSingleLoaderDescriptor(objectType, field), false},
+	} {
+		if m.md == nil {
+			continue
+		}
+		if input := requestInput(m.md, m.batch); input != nil {
+			r.requestBuilder(objectType, field, input)
+		}
+	}
+	batch := r.reg.GetBatchResolverDescriptor(objectType, field) != nil
+	for _, md := range r.reg.GetFallbackResolverDescriptors(objectType, field) {
+		if input := requestInput(md, batch); input != nil {
+			r.requestBuilder(objectType, field, input)
+		}
+	}
+	if _, ok := r.reg.GetCachePolicy(objectType, field); ok {
+		r.requestBuilder(objectType, field, nil)
+	}
+}
+
+// requestInput returns the message holding the request fields of one task of
+// md: its input, or the element of its batches for batch methods. It returns
+// nil for batch methods without batches.
+func requestInput(md protoreflect.MethodDescriptor, batch bool) protoreflect.MessageDescriptor {
+	if !batch {
+		return md.Input()
+	}
+	if fd := md.Input().Fields().ByName("batches"); fd != nil {
+		return fd.Message()
+	}
+	return nil
+}
+
+// requestBuilder returns the builder of objectType.field for input, compiling
+// it on first use. A nil input merges the request fields without building a
+// message, as cache keys do.
+func (r *Runtime) requestBuilder(objectType, field string, input protoreflect.MessageDescriptor) *requestBuilder {
+	key := requestKey{objectType, field, input}
+	if v, ok := r.projections.requests.Load(key); ok {
+		r.projections.hits.Add(1)
+		return v.(*requestBuilder)
+	}
+	r.projections.misses.Add(1)
+	v, _ := r.projections.requests.LoadOrStore(key, r.compileRequest(objectType, field, input))
+	return v.(*requestBuilder)
+}
+
+func (r *Runtime) compileRequest(objectType, field string, input protoreflect.MessageDescriptor) *requestBuilder {
+	b := &requestBuilder{r: r, objectType: objectType, field: field}
+	if input != nil {
+		b.fields = r.jsonFields(input)
+	}
+	mp := r.reg.GetRequestFieldSourceMapping(objectType, field)
+	for _, dst := range sortedKeys(mp) {
+		if !b.accepts(dst) {
+			continue
+		}
+		// Read from parent source field using Registry (following @source paths)
+		if path := r.sourceFieldPath(objectType, mp[dst]); path != nil {
+			b.sources = append(b.sources, sourceArg{dst: dst, path: path})
+		}
+	}
+	exprs := r.reg.GetRequestFieldSourceExpressions(objectType, field)
+	for _, dst := range sortedKeys(exprs) {
+		if b.accepts(dst) {
+			b.exprs = append(b.exprs, exprArg{dst: dst, expr: exprs[dst]})
+		}
+	}
+	return b
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// accepts reports whether dst is a request field, to avoid accidental keys.
+// Builders of messages without fields accept any.
+func (b *requestBuilder) accepts(dst string) bool {
+	if len(b.fields) == 0 {
+		return true
+	}
+	_, ok := b.fields[dst]
+	return ok
+}
+
+// request returns the request fields of task by JSON name: its args, augmented
+// by the fields copied from the parent source and evaluated over it where the
+// args leave them unset.
+func (b *requestBuilder) request(task executor.AsyncResolveTask) map[string]any {
+	if len(b.sources) == 0 && len(b.exprs) == 0 {
+		return task.Args
+	}
+	out := make(map[string]any, len(task.Args)+len(b.sources)+len(b.exprs))
+	for k, v := range task.Args {
+		out[k] = v
+	}
+	srcMsg, ok := task.Source.(protoreflect.Message)
+	if !ok || srcMsg == nil {
+		return out
+	}
+	for _, s := range b.sources {
+		if _, exists := out[s.dst]; exists {
+			continue
+		}
+		// Go value; fill coerces it to the request field
+		if val, ok := b.r.readSourcePath(srcMsg, s.path); ok {
+			out[s.dst] = val
+		}
+	}
+	for _, e := range b.exprs {
+		if _, exists := out[e.dst]; exists {
+			continue
+		}
+		// A failing expression leaves the field unset, like an unreadable source field
+		if val, err := b.r.resolveComputed(b.objectType, b.field, e.expr, srcMsg); err == nil && val != nil {
+			out[e.dst] = val
+		}
+	}
+	return out
+}
+
+// hasNull reports whether any request field is present in args with a nil
+// value, which short-circuits loader calls.
+func (b *requestBuilder) hasNull(args map[string]any) bool {
+	for k, v := range args {
+		if _, ok := b.fields[k]; ok && v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fill sets the request fields of msg from args; keys naming no request field
+// are ignored.
+func (b *requestBuilder) fill(msg protoreflect.Message, args map[string]any) error {
+	if args == nil {
+		return nil
+	}
+	return b.r.setFields(msg, b.fields, args)
+}
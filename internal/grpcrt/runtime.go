@@ -3,10 +3,25 @@ package grpcrt
 import (
 	"context"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/hanpama/protograph/internal/cache"
+	"github.com/hanpama/protograph/internal/compute"
+	"github.com/hanpama/protograph/internal/errcode"
+	"github.com/hanpama/protograph/internal/eventbus"
+	"github.com/hanpama/protograph/internal/events"
 	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/schema"
+	"github.com/hanpama/protograph/protographctx"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
@@ -15,7 +30,9 @@ import (
 // Runtime implements executor.Runtime for the gRPC-backed bridge.
 // Invariants and boundaries:
 //   - Registry trust: When healthy, Registry returns valid descriptors. Missing
-//     descriptors indicate a programming/configuration error and cause panic.
+//     descriptors indicate a programming/configuration error and cause panic;
+//     Verify reports them before serving instead. WithStrict(false) fails the
+//     affected field with a *MisconfigurationError rather than panicking.
 //   - Source/value shape: For object fields, source must be a protoreflect.Message.
 //     Violations cause panic rather than being hidden behind recoverable errors.
 //   - Loader short-circuit: Only input message JSONName fields are inspected for
@@ -23,21 +40,88 @@ import (
 //   - Concurrency: BatchResolveAsync groups tasks by (objectType, field) and
 //     executes groups in parallel by default. Transports must be concurrency-safe.
 //   - Determinism: Results preserve input ordering; partial success is supported.
+//   - Field cache: @cache fields are served from the cache.Store when configured;
+//     stale entries are served while a background call refreshes them.
+//   - Error policies: failed results of fields with an @onError policy are replaced
+//     by null with a generic message, or by the fallback value, after each group.
+//   - Timeouts: calls of @timeout fields get their own deadline; tasks it cuts
+//     fail with a timeout error while the rest of the request goes on.
+//   - Latency budgets: groups of @sla fields resolved slower than their budget,
+//     fallbacks included, publish an events.SLAExceeded.
+//   - Fallbacks: tasks a resolver fails are retried with the fallback methods of
+//     the field in order; values they serve are reported in the extensions.
+//   - Stubs: with WithStubs, fields without a resolver or loader are served
+//     placeholders and a warning instead of panicking.
+//   - Connections: @connection fields ask their resolvers for the window of the
+//     list their pagination arguments select and page it into edges, offset
+//     cursors and page info.
+//   - Node routing: @node fields decode global IDs and reuse the id loader of the
+//     encoded type; Node ids read in ResolveSync are re-encoded as global IDs.
+//   - Projections: request fields by JSON name and source field paths are looked
+//     up once per message descriptor and object type field, then reused.
+//   - Request builders: each field compiles how its requests are filled from args
+//     and the parent source once per request message; see WithPrecompiledRequests.
+//   - Method quotas: with WithMethodQuotas, calls of a method beyond its quota
+//     queue until earlier calls finish.
 type Runtime struct {
 	reg       Registry
 	transport Transport
+
+	// fieldCache serves @cache fields; refreshing holds the keys of stale
+	// entries being revalidated in the background
+	fieldCache cache.Store
+	refreshing sync.Map
+	now        func() time.Time
+
+	// coalescer merges batch loader calls of concurrent executions
+	coalescer *coalescer
+
+	// stubs types the placeholders of fields without a method; nil panics instead
+	stubs *schema.Schema
+
+	// strict panics on misconfigurations and malformed envelopes instead of
+	// failing the field they affect
+	strict bool
+
+	// protoDefaults serves unset proto3 scalars as their default; see
+	// WithProtoDefaults
+	protoDefaults bool
+
+	// projections caches descriptor lookups; see ProjectionStats
+	projections projections
+
+	// quotas caps the calls in flight by "pkg.Service/Method"; see
+	// WithMethodQuotas
+	quotas map[string]*quota
 }
 
-var _ executor.Runtime = (*Runtime)(nil)
+var (
+	_ executor.Runtime         = (*Runtime)(nil)
+	_ executor.MethodDescriber = (*Runtime)(nil)
+)
+
+// Option configures a Runtime.
+type Option func(*Runtime)
 
-func NewRuntime(registry Registry, transport Transport) executor.Runtime {
-	return &Runtime{reg: registry, transport: transport}
+func NewRuntime(registry Registry, transport Transport, opts ...Option) executor.Runtime {
+	r := &Runtime{reg: registry, transport: transport, now: time.Now, strict: true}
+	for _, o := range opts {
+		o(r)
+	}
+	if len(r.quotas) > 0 && r.transport != nil {
+		r.transport = quotaTransport{next: r.transport, quotas: r.quotas}
+	}
+	return r
 }
 
 // ResolveSync resolves only physical fields from the parent source.
 // It NEVER performs network I/O. All resolvers/loaders (I/O) are handled in
-// BatchResolveAsync. If the field is not present on the source, return (nil, nil)
-// to produce a GraphQL null for nullable fields.
+// BatchResolveAsync. Repeated fields read as a []any of their converted
+// elements, empty when unset. If the field is not present on the source, return
+// its @default value when declared, then its proto default with
+// WithProtoDefaults, otherwise (nil, nil) to produce a GraphQL null for
+// nullable fields. @const fields return their literal without touching the
+// source, and @compute fields are evaluated over sibling source fields.
 //
 // Source contract: the executor feeds back whatever value the runtime returned
 // for a parent object. Here we expect source to be a protoreflect.Message for
@@ -47,19 +131,87 @@ func (r *Runtime) ResolveSync(ctx context.Context, objectType string, field stri
 	_ = ctx
 	_ = args
 
+	if v, ok := r.reg.GetConstField(objectType, field); ok {
+		return v, nil
+	}
+
 	msg, ok := source.(protoreflect.Message)
 	if !ok {
-		panic(fmt.Sprintf("ResolveSync: source for %s.%s must be protoreflect.Message, got %T", objectType, field, source))
+		return nil, r.fail(misconfiguration(objectType, field, "ResolveSync: source for %s.%s must be protoreflect.Message, got %T", objectType, field, source))
 	}
-	fd := r.reg.GetSourceFieldDescriptor(objectType, field)
-	if fd == nil {
-		panic(fmt.Sprintf("ResolveSync: missing FieldDescriptor for %s.%s", objectType, field))
+	if expr, ok := r.reg.GetComputedField(objectType, field); ok {
+		return r.resolveComputed(objectType, field, expr, msg)
 	}
-	if !msg.Has(fd) {
-		return nil, nil
+	path := r.sourceFieldPath(objectType, field)
+	if path == nil {
+		return nil, r.fail(misconfiguration(objectType, field, "ResolveSync: missing FieldDescriptor for %s.%s", objectType, field))
+	}
+	v, ok := r.readSourcePath(msg, path)
+	if !ok {
+		return r.unsetValue(objectType, field, msg, path), nil
+	}
+	if r.reg.IsGlobalIDField(objectType, field) {
+		if id, ok := formatID(v); ok {
+			return EncodeGlobalID(objectType, id), nil
+		}
+	}
+	return v, nil
+}
+
+// resolveComputed evaluates a @compute expression over sibling source fields of msg.
+// Unset siblings evaluate to their @default, or null; unknown siblings evaluate as null.
+func (r *Runtime) resolveComputed(objectType, field string, expr *compute.Expr, msg protoreflect.Message) (any, error) {
+	v, err := expr.Eval(func(name string) any {
+		path := r.sourceFieldPath(objectType, name)
+		if path == nil {
+			return nil
+		}
+		v, ok := r.readSourcePath(msg, path)
+		if !ok {
+			return r.unsetValue(objectType, name, msg, path)
+		}
+		return v
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", objectType, field, err)
 	}
-	v := msg.Get(fd)
-	return r.handleValue(fd, v), nil
+	return v, nil
+}
+
+// readSourcePath walks nested messages along path and converts the final value.
+// It reports false when any field along the path is unset.
+func (r *Runtime) readSourcePath(msg protoreflect.Message, path []protoreflect.FieldDescriptor) (any, bool) {
+	msg, fd := sourceField(msg, path)
+	if fd == nil || !msg.Has(fd) {
+		return nil, false
+	}
+	return r.fieldValue(fd, msg.Get(fd)), true
+}
+
+// sourceField walks path from msg and returns the last field of path with the
+// message holding it. The field is nil when a message along the path is unset
+// or lacks its field.
+func sourceField(msg protoreflect.Message, path []protoreflect.FieldDescriptor) (protoreflect.Message, protoreflect.FieldDescriptor) {
+	last := len(path) - 1
+	for _, fd := range path[:last] {
+		fd = messageField(msg, fd)
+		if fd == nil || !msg.Has(fd) {
+			return msg, nil
+		}
+		msg = msg.Get(fd).Message()
+	}
+	return msg, messageField(msg, path[last])
+}
+
+// messageField returns the field of msg matching fd, a field of the source message
+// of an object type. Members of a @discriminator union arrive as the union's
+// flattened message, which holds their fields under the same names. Returns nil
+// when msg has no such field.
+func messageField(msg protoreflect.Message, fd protoreflect.FieldDescriptor) protoreflect.FieldDescriptor {
+	if fd.ContainingMessage().FullName() == msg.Descriptor().FullName() {
+		return fd
+	}
+	return msg.Descriptor().Fields().ByName(fd.Name())
 }
 
 // BatchResolveAsync executes resolver/loader RPCs. All I/O happens here.
@@ -68,6 +220,7 @@ func (r *Runtime) ResolveSync(ctx context.Context, objectType string, field stri
 //
 // Concurrency and determinism:
 // - grpcrt groups tasks by (objectType, field) and executes those groups in parallel by default.
+// - @priority levels run one after another (HIGH, NORMAL, LOW); LOW groups delay the whole depth.
 // - Results are written into pre-determined slots to preserve input ordering per task.
 // - Transport implementations MUST be safe for concurrent use.
 func (r *Runtime) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
@@ -76,46 +229,46 @@ func (r *Runtime) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncR
 		return results
 	}
 	// Group by objectType and field
-	type groupKey struct {
-		objectType string
-		field      string
-	}
-	type group struct {
-		objectType string
-		field      string
-		idxs       []int
-	}
-	groups := []group{}
-	idxByKey := map[groupKey]int{}
-	for i, t := range tasks {
-		k := groupKey{objectType: t.ObjectType, field: t.Field}
-		if gi, ok := idxByKey[k]; ok {
-			groups[gi].idxs = append(groups[gi].idxs, i)
-		} else {
-			idxByKey[k] = len(groups)
-			groups = append(groups, group{objectType: t.ObjectType, field: t.Field, idxs: []int{i}})
-		}
+	var groups []group
+	for _, g := range executor.GroupTasks(tasks) {
+		groups = append(groups, group{objectType: g.ObjectType, field: g.Field, idxs: g.Indices})
 	}
 	run := func(g group) {
-		if md := r.reg.GetBatchResolverDescriptor(g.objectType, g.field); md != nil {
-			r.runBatchResolverGroup(ctx, md, tasks, g.idxs, results)
-			return
+		calls := g
+		cg := r.lookupCache(ctx, g.objectType, g.field, tasks, g.idxs, results)
+		if cg != nil {
+			calls.idxs = cg.misses
+			r.revalidate(ctx, g, cg, tasks)
+		}
+		if len(calls.idxs) > 0 {
+			r.dispatch(ctx, calls, tasks, results)
 		}
-		if md := r.reg.GetSingleResolverDescriptor(g.objectType, g.field); md != nil {
-			r.runSingleResolverGroup(ctx, md, tasks, g.idxs, results)
-			return
+		if cg != nil {
+			r.storeCache(ctx, cg, calls.idxs, results)
 		}
-		if md := r.reg.GetBatchLoaderDescriptor(g.objectType, g.field); md != nil {
-			r.runBatchLoaderGroup(ctx, md, tasks, g.idxs, results)
-			return
+		if policy, ok := r.reg.GetErrorPolicy(g.objectType, g.field); ok {
+			for _, idx := range g.idxs {
+				results[idx] = policy.apply(g.objectType, g.field, results[idx])
+			}
 		}
-		if md := r.reg.GetSingleLoaderDescriptor(g.objectType, g.field); md != nil {
-			r.runSingleLoaderGroup(ctx, md, tasks, g.idxs, results)
-			return
+	}
+
+	// Priority levels run in stages: high priority groups are called alone, then
+	// normal ones, then low priority ones, each stage once the previous returned
+	for _, p := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		var stage []group
+		for _, g := range groups {
+			if r.reg.GetPriority(g.objectType, g.field) == p {
+				stage = append(stage, g)
+			}
 		}
-		panic(fmt.Sprintf("BatchResolveAsync: no resolver/loader registered for %s.%s", g.objectType, g.field))
+		runAll(stage, run)
 	}
+	return results
+}
 
+// runAll runs groups in parallel, or inline when there is only one.
+func runAll(groups []group, run func(group)) {
 	if len(groups) > 1 {
 		var wg sync.WaitGroup
 		wg.Add(len(groups))
@@ -132,7 +285,173 @@ func (r *Runtime) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncR
 			run(g)
 		}
 	}
-	return results
+}
+
+// group is the tasks of one (objectType, field) within a batch.
+type group struct {
+	objectType string
+	field      string
+	idxs       []int
+}
+
+// dispatch calls the resolver or loader of a group and writes its results in place.
+// The resolvers of @connection fields are asked for pages of their lists.
+func (r *Runtime) dispatch(ctx context.Context, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	if connType, ok := r.reg.GetConnectionType(g.objectType, g.field); ok {
+		r.dispatchPages(ctx, connType, g, tasks, results)
+		return
+	}
+	r.dispatchMethods(ctx, g, tasks, results)
+}
+
+// dispatchMethods calls the resolver or loader of a group. Tasks of fields with
+// @metadata arguments are partitioned by their metadata values, each partition
+// called with its own outgoing metadata.
+func (r *Runtime) dispatchMethods(ctx context.Context, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	keys := r.reg.GetMetadataArguments(g.objectType, g.field)
+	if len(keys) == 0 {
+		r.call(ctx, g, tasks, results)
+		return
+	}
+	for _, p := range partitionByMetadata(keys, g, tasks) {
+		r.call(metadata.AppendToOutgoingContext(ctx, p.kv...), p.group, tasks, results)
+	}
+}
+
+// metadataPartition is the tasks of a group sharing the same outgoing metadata.
+type metadataPartition struct {
+	group
+	kv []string
+}
+
+// partitionByMetadata splits g by the values of its metadata arguments, in the
+// order of first appearance. Null and missing arguments send no metadata.
+func partitionByMetadata(keys map[string]string, g group, tasks []executor.AsyncResolveTask) []metadataPartition {
+	args := make([]string, 0, len(keys))
+	for arg := range keys {
+		args = append(args, arg)
+	}
+	sort.Strings(args)
+	var parts []metadataPartition
+	byKV := map[string]int{}
+	for _, idx := range g.idxs {
+		var kv []string
+		for _, arg := range args {
+			if v, ok := tasks[idx].Args[arg]; ok && v != nil {
+				kv = append(kv, keys[arg], fmt.Sprint(v))
+			}
+		}
+		id := strings.Join(kv, "\x00")
+		pi, ok := byKV[id]
+		if !ok {
+			pi = len(parts)
+			byKV[id] = pi
+			parts = append(parts, metadataPartition{group: group{objectType: g.objectType, field: g.field}, kv: kv})
+		}
+		parts[pi].idxs = append(parts[pi].idxs, idx)
+	}
+	return parts
+}
+
+// call routes a group to its method, then retries the tasks it failed with the
+// fallback resolvers of the field.
+func (r *Runtime) call(ctx context.Context, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	start := time.Now()
+	r.withTimeout(ctx, g, results, func(ctx context.Context) {
+		r.route(ctx, g, tasks, results)
+	})
+	r.runFallbacks(ctx, g, tasks, results)
+	r.checkSLA(ctx, g, time.Since(start))
+}
+
+// checkSLA reports a group resolved slower than the @sla of its field.
+func (r *Runtime) checkSLA(ctx context.Context, g group, d time.Duration) {
+	budget := r.reg.GetSLA(g.objectType, g.field)
+	if budget <= 0 || d <= budget {
+		return
+	}
+	method, _, _ := r.DescribeMethod(g.objectType, g.field)
+	e := events.SLAExceeded{ObjectType: g.objectType, Field: g.field, Method: method, Tasks: len(g.idxs), Budget: budget, Duration: d}
+	if op, ok := protographctx.OperationFromContext(ctx); ok {
+		e.OperationName = op.Name
+	}
+	eventbus.Publish(ctx, e)
+}
+
+// withTimeout runs the calls of fn for g within the @timeout of its field, if any.
+// Tasks failed by the expiry of that deadline, rather than the request's, report
+// the timeout.
+func (r *Runtime) withTimeout(ctx context.Context, g group, results []executor.AsyncResolveResult, fn func(context.Context)) {
+	d := r.reg.GetTimeout(g.objectType, g.field)
+	if d <= 0 {
+		fn(ctx)
+		return
+	}
+	callCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	fn(callCtx)
+	if callCtx.Err() != context.DeadlineExceeded || ctx.Err() != nil {
+		return
+	}
+	for _, idx := range g.idxs {
+		if results[idx].Error != nil {
+			results[idx] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.DownstreamServiceError, "%s.%s timed out after %s", g.objectType, g.field, d)}
+		}
+	}
+}
+
+// route calls the node, resolver or loader method of a group.
+func (r *Runtime) route(ctx context.Context, g group, tasks []executor.AsyncResolveTask, results []executor.AsyncResolveResult) {
+	if r.reg.IsNodeField(g.objectType, g.field) {
+		r.runNodeGroup(ctx, tasks, g.idxs, results)
+		return
+	}
+	if md := r.reg.GetBatchResolverDescriptor(g.objectType, g.field); md != nil {
+		r.runBatchResolverGroup(ctx, md, tasks, g.idxs, results)
+		return
+	}
+	if md := r.reg.GetSingleResolverDescriptor(g.objectType, g.field); md != nil {
+		r.runSingleResolverGroup(ctx, md, tasks, g.idxs, results)
+		return
+	}
+	if md := r.reg.GetBatchLoaderDescriptor(g.objectType, g.field); md != nil {
+		r.runBatchLoaderGroup(ctx, md, tasks, g.idxs, results)
+		return
+	}
+	if md := r.reg.GetSingleLoaderDescriptor(g.objectType, g.field); md != nil {
+		r.runSingleLoaderGroup(ctx, md, tasks, g.idxs, results)
+		return
+	}
+	if r.stubs != nil {
+		r.serveStubs(ctx, g, results)
+		return
+	}
+	err := r.fail(misconfiguration(g.objectType, g.field, "BatchResolveAsync: no resolver/loader registered for %s.%s", g.objectType, g.field))
+	for _, idx := range g.idxs {
+		results[idx] = executor.AsyncResolveResult{Error: err}
+	}
+}
+
+// DescribeMethod reports the RPC BatchResolveAsync routes objectType.field to,
+// following the same precedence. @node fields dispatch on the decoded ID and are
+// described as "node".
+func (r *Runtime) DescribeMethod(objectType, field string) (string, bool, bool) {
+	if r.reg.IsNodeField(objectType, field) {
+		return "node", true, true
+	}
+	if md := r.reg.GetBatchResolverDescriptor(objectType, field); md != nil {
+		return string(md.FullName()), true, true
+	}
+	if md := r.reg.GetSingleResolverDescriptor(objectType, field); md != nil {
+		return string(md.FullName()), false, true
+	}
+	if md := r.reg.GetBatchLoaderDescriptor(objectType, field); md != nil {
+		return string(md.FullName()), true, true
+	}
+	if md := r.reg.GetSingleLoaderDescriptor(objectType, field); md != nil {
+		return string(md.FullName()), false, true
+	}
+	return "", false, false
 }
 
 // runBatchResolverGroup executes one batch resolver group and writes results in-place.
@@ -146,7 +465,8 @@ func (r *Runtime) runBatchResolverGroup(ctx context.Context, md protoreflect.Met
 // runSingleResolverGroup executes single resolver calls for a group and writes results.
 func (r *Runtime) runSingleResolverGroup(ctx context.Context, md protoreflect.MethodDescriptor, tasks []executor.AsyncResolveTask, idxs []int, results []executor.AsyncResolveResult) {
 	for _, i := range idxs {
-		results[i] = r.executeSingle(ctx, md, tasks[i])
+		b := r.requestBuilder(tasks[i].ObjectType, tasks[i].Field, md.Input())
+		results[i] = r.executeSingle(ctx, md, b, tasks[i])
 	}
 }
 
@@ -165,6 +485,39 @@ func (r *Runtime) runSingleLoaderGroup(ctx context.Context, md protoreflect.Meth
 	}
 }
 
+// runNodeGroup decodes the global IDs of a @node group and dispatches each task to the
+// id loader of the type encoded in its ID. IDs of types without a loader resolve to null.
+func (r *Runtime) runNodeGroup(ctx context.Context, tasks []executor.AsyncResolveTask, idxs []int, results []executor.AsyncResolveResult) {
+	nodeTasks := make([]executor.AsyncResolveTask, len(tasks))
+	copy(nodeTasks, tasks)
+
+	var typeNames []string
+	idxsByType := map[string][]int{}
+	for _, i := range idxs {
+		gid, _ := tasks[i].Args["id"].(string)
+		typeName, id, ok := DecodeGlobalID(gid)
+		if !ok {
+			results[i] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.BadUserInput, "invalid global ID %q", gid)}
+			continue
+		}
+		nodeTasks[i].Args = map[string]any{"id": id}
+		if _, seen := idxsByType[typeName]; !seen {
+			typeNames = append(typeNames, typeName)
+		}
+		idxsByType[typeName] = append(idxsByType[typeName], i)
+	}
+
+	for _, typeName := range typeNames {
+		if md := r.reg.GetBatchNodeLoaderDescriptor(typeName); md != nil {
+			r.runBatchLoaderGroup(ctx, md, nodeTasks, idxsByType[typeName], results)
+			continue
+		}
+		if md := r.reg.GetSingleNodeLoaderDescriptor(typeName); md != nil {
+			r.runSingleLoaderGroup(ctx, md, nodeTasks, idxsByType[typeName], results)
+		}
+	}
+}
+
 // executeBatch builds and executes a batch RPC call and returns per-task results
 func (r *Runtime) executeBatch(ctx context.Context, md protoreflect.MethodDescriptor, tasks []executor.AsyncResolveTask, idxs []int) []executor.AsyncResolveResult {
 	res := make([]executor.AsyncResolveResult, len(idxs))
@@ -178,9 +531,8 @@ func (r *Runtime) executeBatch(ctx context.Context, md protoreflect.MethodDescri
 	included := make([]int, 0, len(idxs)) // positions within idxs slice
 	for pos, taskIdx := range idxs {
 		item := dynamicpb.NewMessage(itemDesc)
-		// Merge args with source-mapped fields if provided by Registry
-		merged := r.mergeArgsWithSource(tasks[taskIdx].ObjectType, tasks[taskIdx].Field, tasks[taskIdx].Source, tasks[taskIdx].Args, itemDesc)
-		if err := setMessageFieldsByJSON(item, merged); err != nil {
+		b := r.requestBuilder(tasks[taskIdx].ObjectType, tasks[taskIdx].Field, itemDesc)
+		if err := b.fill(item, b.request(tasks[taskIdx])); err != nil {
 			res[pos] = executor.AsyncResolveResult{Error: err}
 			continue
 		}
@@ -195,6 +547,7 @@ func (r *Runtime) executeBatch(ctx context.Context, md protoreflect.MethodDescri
 
 	respMsg, err := r.transport.Call(ctx, md, req)
 	if err != nil {
+		err = downstreamError(err)
 		for _, pos := range included {
 			res[pos] = executor.AsyncResolveResult{Error: err}
 		}
@@ -206,14 +559,14 @@ func (r *Runtime) executeBatch(ctx context.Context, md protoreflect.MethodDescri
 	bf := omd.Fields().ByName("batches")
 	if bf == nil {
 		for _, pos := range included {
-			res[pos] = executor.AsyncResolveResult{Error: fmt.Errorf("missing batches field in response")}
+			res[pos] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.DownstreamServiceError, "missing batches field in response")}
 		}
 		return res
 	}
 	batchesOut := respMsg.Get(bf).List()
 	for k, pos := range included {
 		if k >= batchesOut.Len() {
-			res[pos] = executor.AsyncResolveResult{Error: fmt.Errorf("missing batch element")}
+			res[pos] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.DownstreamServiceError, "missing batch element")}
 			continue
 		}
 		msg := batchesOut.Get(k).Message()
@@ -246,12 +599,13 @@ func (r *Runtime) executeBatchLoader(ctx context.Context, md protoreflect.Method
 	// Track included positions within idxs slice
 	included := make([]int, 0, len(idxs))
 	for pos, taskIdx := range idxs {
-		args := r.mergeArgsWithSource(tasks[taskIdx].ObjectType, tasks[taskIdx].Field, tasks[taskIdx].Source, tasks[taskIdx].Args, itemDesc)
-		if hasNilInputFields(itemDesc, args) {
+		b := r.requestBuilder(tasks[taskIdx].ObjectType, tasks[taskIdx].Field, itemDesc)
+		args := b.request(tasks[taskIdx])
+		if b.hasNull(args) {
 			continue // short-circuit
 		}
 		item := dynamicpb.NewMessage(itemDesc)
-		if err := setMessageFieldsByJSON(item, args); err != nil {
+		if err := b.fill(item, args); err != nil {
 			res[pos] = executor.AsyncResolveResult{Error: err}
 			continue
 		}
@@ -264,8 +618,9 @@ func (r *Runtime) executeBatchLoader(ctx context.Context, md protoreflect.Method
 		return res
 	}
 
-	respMsg, err := r.transport.Call(ctx, md, req)
+	respMsg, err := r.callBatch(ctx, md, req)
 	if err != nil {
+		err = downstreamError(err)
 		for _, pos := range included {
 			res[pos] = executor.AsyncResolveResult{Error: err}
 		}
@@ -277,14 +632,18 @@ func (r *Runtime) executeBatchLoader(ctx context.Context, md protoreflect.Method
 	of := omd.Fields().ByName("batches")
 	if of == nil {
 		for _, pos := range included {
-			res[pos] = executor.AsyncResolveResult{Error: fmt.Errorf("missing batches field in response")}
+			res[pos] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.DownstreamServiceError, "missing batches field in response")}
 		}
 		return res
 	}
 	batchesOut := respMsg.Get(of).List()
+	if r.reg.IsKeyedLoader(md.FullName()) {
+		r.joinKeyed(itemDesc.Fields(), list, batchesOut, included, res)
+		return res
+	}
 	for k, pos := range included {
 		if k >= batchesOut.Len() {
-			res[pos] = executor.AsyncResolveResult{Error: fmt.Errorf("missing batch element")}
+			res[pos] = executor.AsyncResolveResult{Error: errcode.Errorf(errcode.DownstreamServiceError, "missing batch element")}
 			continue
 		}
 		msg := batchesOut.Get(k).Message()
@@ -302,40 +661,112 @@ func (r *Runtime) executeBatchLoader(ctx context.Context, md protoreflect.Method
 	return res
 }
 
-// executeSingleLoader executes a single loader call or short-circuits when args contain nil.
-func (r *Runtime) executeSingleLoader(ctx context.Context, md protoreflect.MethodDescriptor, task executor.AsyncResolveTask) executor.AsyncResolveResult {
-	if hasNilInputFields(md.Input(), task.Args) {
-		return executor.AsyncResolveResult{Value: nil}
+// joinKeyed maps the results of a keyed loader to the requests with the same key
+// fields, whatever their order. Keys the backend left out resolve to null, like
+// a loader answering an empty result, or to an empty list for list loaders.
+func (r *Runtime) joinKeyed(keys protoreflect.FieldDescriptors, requests, batchesOut protoreflect.List, included []int, res []executor.AsyncResolveResult) {
+	byKey := make(map[string]protoreflect.Message, batchesOut.Len())
+	for k := 0; k < batchesOut.Len(); k++ {
+		msg := batchesOut.Get(k).Message()
+		byKey[joinKey(msg, keys)] = msg
+	}
+	for k, pos := range included {
+		msg, ok := byKey[joinKey(requests.Get(k).Message(), keys)]
+		if !ok {
+			if path := r.dataPath(batchesOut.NewElement().Message().Descriptor()); len(path) > 0 && path[len(path)-1].IsList() {
+				res[pos] = executor.AsyncResolveResult{Value: []any{}}
+			} else {
+				res[pos] = executor.AsyncResolveResult{Value: nil}
+			}
+			continue
+		}
+		val, herr := r.handleResponse(msg)
+		if herr != nil {
+			res[pos] = executor.AsyncResolveResult{Error: herr}
+		} else {
+			res[pos] = executor.AsyncResolveResult{Value: val}
+		}
 	}
-	return r.executeSingle(ctx, md, task)
 }
 
-// hasNilInputFields reports whether any of the input message's JSONName fields
-// are present in args with a nil value.
-func hasNilInputFields(inputDesc protoreflect.MessageDescriptor, args map[string]any) bool {
-	if len(args) == 0 {
-		return false
+// joinKey encodes the values of msg's fields named like keys, the fields of the
+// loader request. Values are encoded by proto kind, strings and bytes with their
+// length, so that distinct keys never collide: 1 and "1" differ, while an int32
+// and an int64 request and response field holding 1 match.
+func joinKey(msg protoreflect.Message, keys protoreflect.FieldDescriptors) string {
+	fields := msg.Descriptor().Fields()
+	var b []byte
+	for i := 0; i < keys.Len(); i++ {
+		fd := fields.ByName(keys.Get(i).Name())
+		if fd == nil {
+			b = append(b, 0)
+			continue
+		}
+		if !fd.IsList() {
+			b = appendKeyValue(b, fd, msg.Get(fd))
+			continue
+		}
+		list := msg.Get(fd).List()
+		b = append(b, 'l')
+		b = binary.AppendUvarint(b, uint64(list.Len()))
+		for j := 0; j < list.Len(); j++ {
+			b = appendKeyValue(b, fd, list.Get(j))
+		}
 	}
-	fields := inputDesc.Fields()
-	for i := 0; i < fields.Len(); i++ {
-		f := fields.Get(i)
-		if v, ok := args[string(f.JSONName())]; ok && v == nil {
-			return true
+	return string(b)
+}
+
+// appendKeyValue appends a value of fd to a join key, tagged with its kind.
+// Message keys are rejected when the schema is compiled, and only appear with
+// hand-written descriptors; they are encoded deterministically.
+func appendKeyValue(b []byte, fd protoreflect.FieldDescriptor, v protoreflect.Value) []byte {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if v.Bool() {
+			return append(b, 'b', 1)
 		}
+		return append(b, 'b', 0)
+	case protoreflect.EnumKind:
+		return binary.AppendVarint(append(b, 'e'), int64(v.Enum()))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return binary.AppendVarint(append(b, 'i'), v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return binary.AppendUvarint(append(b, 'u'), v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return binary.BigEndian.AppendUint64(append(b, 'f'), math.Float64bits(v.Float()))
+	case protoreflect.StringKind:
+		b = binary.AppendUvarint(append(b, 's'), uint64(len(v.String())))
+		return append(b, v.String()...)
+	case protoreflect.BytesKind:
+		b = binary.AppendUvarint(append(b, 'y'), uint64(len(v.Bytes())))
+		return append(b, v.Bytes()...)
+	default:
+		encoded, _ := proto.MarshalOptions{Deterministic: true}.Marshal(v.Message().Interface())
+		b = binary.AppendUvarint(append(b, 'm'), uint64(len(encoded)))
+		return append(b, encoded...)
+	}
+}
+
+// executeSingleLoader executes a single loader call or short-circuits when args contain nil.
+func (r *Runtime) executeSingleLoader(ctx context.Context, md protoreflect.MethodDescriptor, task executor.AsyncResolveTask) executor.AsyncResolveResult {
+	b := r.requestBuilder(task.ObjectType, task.Field, md.Input())
+	if b.hasNull(task.Args) {
+		return executor.AsyncResolveResult{Value: nil}
 	}
-	return false
+	return r.executeSingle(ctx, md, b, task)
 }
 
-// executeSingle executes a single RPC resolver call for one async task.
-func (r *Runtime) executeSingle(ctx context.Context, md protoreflect.MethodDescriptor, task executor.AsyncResolveTask) executor.AsyncResolveResult {
+// executeSingle executes a single RPC resolver call for one async task, building
+// its request with b.
+func (r *Runtime) executeSingle(ctx context.Context, md protoreflect.MethodDescriptor, b *requestBuilder, task executor.AsyncResolveTask) executor.AsyncResolveResult {
 	req := dynamicpb.NewMessage(md.Input())
-	merged := r.mergeArgsWithSource(task.ObjectType, task.Field, task.Source, task.Args, md.Input())
-	if err := setMessageFieldsByJSON(req, merged); err != nil {
+	if err := b.fill(req, b.request(task)); err != nil {
 		return executor.AsyncResolveResult{Error: err}
 	}
 	respMsg, err := r.transport.Call(ctx, md, req)
 	if err != nil {
-		return executor.AsyncResolveResult{Error: err}
+		return executor.AsyncResolveResult{Error: downstreamError(err)}
 	}
 	val, herr := r.handleResponse(respMsg)
 	if herr != nil {
@@ -344,74 +775,56 @@ func (r *Runtime) executeSingle(ctx context.Context, md protoreflect.MethodDescr
 	return executor.AsyncResolveResult{Value: val}
 }
 
-// mergeArgsWithSource augments args by copying fields from the parent source according to
-// Registry-provided mapping for (objectType, field). If inputDesc is provided, only keys that
-// exist in the input message are considered.
-func (r *Runtime) mergeArgsWithSource(objectType, field string, source any, args map[string]any, inputDesc protoreflect.MessageDescriptor) map[string]any {
-	if r == nil {
-		return args
-	}
-	mp := r.reg.GetRequestFieldSourceMapping(objectType, field)
-	if len(mp) == 0 {
-		return args
+// dataPath returns the fields leading from a response message to its result: the
+// path declared in the registry, or the top-level "data" field. It returns nil
+// when the message has neither.
+func (r *Runtime) dataPath(desc protoreflect.MessageDescriptor) []protoreflect.FieldDescriptor {
+	if path := r.reg.GetResponseDataPath(desc.FullName()); len(path) > 0 {
+		return path
 	}
-	out := make(map[string]any, len(args)+len(mp))
-	for k, v := range args {
-		out[k] = v
+	if fd := desc.Fields().ByName("data"); fd != nil {
+		return []protoreflect.FieldDescriptor{fd}
 	}
-	srcMsg, ok := source.(protoreflect.Message)
-	if !ok || srcMsg == nil {
-		return out
-	}
-	// Build a quick set of input field JSON names to avoid accidental keys
-	inputFields := map[string]struct{}{}
-	if inputDesc != nil {
-		fs := inputDesc.Fields()
-		for i := 0; i < fs.Len(); i++ {
-			inputFields[string(fs.Get(i).JSONName())] = struct{}{}
-		}
-	}
-	for dst, src := range mp {
-		if _, exists := out[dst]; exists {
-			continue
-		}
-		if _, ok := inputFields[dst]; !ok && len(inputFields) > 0 {
-			continue
-		}
-		// Read from parent source field using Registry
-		fd := r.reg.GetSourceFieldDescriptor(objectType, src)
-		if fd == nil || !srcMsg.Has(fd) {
-			continue
-		}
-		val := srcMsg.Get(fd)
-		// Convert to Go value; setMessageFieldsByJSON will coerce to dest type
-		out[dst] = r.handleValue(fd, val)
-	}
-	return out
+	return nil
 }
 
-// handleResponse extracts the top-level "data" field from a response message.
+// handleResponse extracts the result from a response message, the top-level "data"
+// field unless the registry declares another path, or the error a backend set on a
+// batch element instead. Unset messages along the path read as empty.
 func (r *Runtime) handleResponse(resp protoreflect.Message) (any, error) {
-	fd := resp.Descriptor().Fields().ByName("data")
-	if fd == nil {
-		return nil, fmt.Errorf("missing data field in response")
+	if err := elementError(resp); err != nil {
+		return nil, err
+	}
+	path := r.dataPath(resp.Descriptor())
+	if path == nil {
+		return nil, errcode.Errorf(errcode.DownstreamServiceError, "missing data field in response")
 	}
+	last := len(path) - 1
+	for _, fd := range path[:last] {
+		resp = resp.Get(fd).Message()
+	}
+	fd := path[last]
 	// If the singular message field is not present, treat as null (e.g., not found)
 	if fd.Cardinality() != protoreflect.Repeated && fd.Kind() == protoreflect.MessageKind {
 		if !resp.Has(fd) {
 			return nil, nil
 		}
 	}
-	v := resp.Get(fd)
-	if fd.Cardinality() == protoreflect.Repeated { // container
-		lst := v.List()
-		out := make([]any, 0, lst.Len())
-		for i := 0; i < lst.Len(); i++ {
-			out = append(out, r.handleValue(fd, lst.Get(i)))
-		}
-		return out, nil
+	return r.fieldValue(fd, resp.Get(fd)), nil
+}
+
+// fieldValue converts the value of fd, expanding repeated fields into a []any of
+// their converted elements.
+func (r *Runtime) fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if !fd.IsList() {
+		return r.handleValue(fd, v)
+	}
+	lst := v.List()
+	out := make([]any, lst.Len())
+	for i := range out {
+		out[i] = r.handleValue(fd, lst.Get(i))
 	}
-	return r.handleValue(fd, v), nil
+	return out
 }
 
 // handleValue converts a protobuf field value to a Go value for executor consumption.
@@ -437,7 +850,7 @@ func (r *Runtime) handleValue(fd protoreflect.FieldDescriptor, v protoreflect.Va
 		return []byte(v.Bytes())
 	case protoreflect.EnumKind:
 		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
-			return string(ev.Name())
+			return r.graphQLEnumValue(ev)
 		}
 		return int32(v.Enum())
 	case protoreflect.MessageKind:
@@ -448,12 +861,23 @@ func (r *Runtime) handleValue(fd protoreflect.FieldDescriptor, v protoreflect.Va
 }
 
 // ResolveType resolves the concrete type of an abstract GraphQL type based on the value.
-// It is used to determine the actual GraphQL object type to execute for a given value.
+// It is used to determine the actual GraphQL object type to execute for a given value:
+// the registry's discriminator field of abstractType when declared, then the object
+// type the registry maps the message to, and finally the message name without its
+// "Source" suffix.
 func (r *Runtime) ResolveType(ctx context.Context, abstractType string, value any) (string, error) {
 	msg, ok := value.(protoreflect.Message)
 	if !ok || msg == nil {
 		return "", fmt.Errorf("ResolveType expects protoreflect.Message, got %T", value)
 	}
+	if r.reg != nil {
+		if d, ok := r.reg.GetTypeDiscriminator(abstractType); ok {
+			return r.resolveDiscriminatedType(abstractType, d, msg)
+		}
+		if typeName, ok := r.reg.GetMessageObjectType(msg.Descriptor().FullName()); ok {
+			return typeName, nil
+		}
+	}
 	name := string(msg.Descriptor().Name())
 	if len(name) > 6 && name[len(name)-6:] == "Source" {
 		return name[:len(name)-6], nil
@@ -461,6 +885,21 @@ func (r *Runtime) ResolveType(ctx context.Context, abstractType string, value an
 	return "", fmt.Errorf("cannot infer concrete type from message %s", name)
 }
 
+// resolveDiscriminatedType reads the discriminator field of msg and maps its value
+// to an object type. Values are set by backends, so unknown ones are downstream errors.
+func (r *Runtime) resolveDiscriminatedType(abstractType string, d TypeDiscriminator, msg protoreflect.Message) (string, error) {
+	fd := msg.Descriptor().Fields().ByName(d.Field)
+	if fd == nil {
+		return "", errcode.Errorf(errcode.DownstreamServiceError, "message %s of %s has no discriminator field %s", msg.Descriptor().FullName(), abstractType, d.Field)
+	}
+	value := fmt.Sprint(r.handleValue(fd, msg.Get(fd)))
+	typeName, ok := d.Types[value]
+	if !ok {
+		return "", errcode.Errorf(errcode.DownstreamServiceError, "unknown %s discriminator %s %q", abstractType, d.Field, value)
+	}
+	return typeName, nil
+}
+
 // ResolveUnionConcreteValue unwraps the union envelope into the concrete message, if applicable.
 func (r *Runtime) ResolveUnionConcreteValue(ctx context.Context, unionTypeName string, value any) (any, error) {
 	if value == nil {
@@ -470,7 +909,14 @@ func (r *Runtime) ResolveUnionConcreteValue(ctx context.Context, unionTypeName s
 	if !ok || msg == nil {
 		return nil, fmt.Errorf("ResolveUnionConcreteValue expects protoreflect.Message, got %T", value)
 	}
-	if decoded := r.unwrapUnionEnvelope(msg); decoded != nil {
+	if isAnyMessage(msg) {
+		return r.unwrapAnyEnvelope(msg)
+	}
+	decoded, err := r.unwrapUnionEnvelope(unionTypeName, msg)
+	if err != nil {
+		return nil, err
+	}
+	if decoded != nil {
 		return decoded, nil
 	}
 	return msg, nil
@@ -485,7 +931,14 @@ func (r *Runtime) ResolveInterfaceConcreteValue(ctx context.Context, interfaceTy
 	if !ok || msg == nil {
 		return nil, fmt.Errorf("ResolveInterfaceConcreteValue expects protoreflect.Message, got %T", value)
 	}
-	if decoded := r.unwrapInterfaceEnvelope(msg); decoded != nil {
+	if isAnyMessage(msg) {
+		return r.unwrapAnyEnvelope(msg)
+	}
+	decoded, err := r.unwrapInterfaceEnvelope(interfaceTypeName, msg)
+	if err != nil {
+		return nil, err
+	}
+	if decoded != nil {
 		return decoded, nil
 	}
 	return msg, nil
@@ -493,7 +946,19 @@ func (r *Runtime) ResolveInterfaceConcreteValue(ctx context.Context, interfaceTy
 
 // SerializeLeafValue serializes a scalar or enum value for transport over the wire.
 // It handles nil values, basic types, and byte slices (which are base64-encoded).
+// Enum numbers missing from the descriptor follow the enum's @onUnknown policy, and
+// IDs held as int64 or UUID bytes are written as strings.
 func (r *Runtime) SerializeLeafValue(ctx context.Context, scalarOrEnumTypeName string, value any) (any, error) {
+	if scalarOrEnumTypeName == "ID" {
+		if id, ok := formatID(value); ok {
+			return id, nil
+		}
+	}
+	if n, ok := value.(int32); ok && r.reg != nil {
+		if p, ok := r.reg.GetUnknownEnumPolicy(scalarOrEnumTypeName); ok {
+			return p.apply(ctx, scalarOrEnumTypeName, n), nil
+		}
+	}
 	switch v := value.(type) {
 	case nil:
 		return nil, nil
@@ -508,74 +973,126 @@ func (r *Runtime) SerializeLeafValue(ctx context.Context, scalarOrEnumTypeName s
 
 // ----------------- helpers -----------------
 
-func (r *Runtime) unwrapInterfaceEnvelope(msg protoreflect.Message) protoreflect.Message {
+// unwrapInterfaceEnvelope decodes the payload of an interface envelope into the
+// source message its typename names. It returns nil when msg is not an envelope.
+func (r *Runtime) unwrapInterfaceEnvelope(interfaceType string, msg protoreflect.Message) (protoreflect.Message, error) {
 	if r == nil || r.reg == nil || msg == nil {
-		return nil
+		return nil, nil
 	}
 	fields := msg.Descriptor().Fields()
 	typenameField := fields.ByName("typename")
 	payloadField := fields.ByName("payload")
 	if typenameField == nil || payloadField == nil {
-		return nil
+		return nil, nil
 	}
 	if typenameField.Kind() != protoreflect.StringKind || payloadField.Kind() != protoreflect.BytesKind {
-		return nil
+		return nil, nil
 	}
 	if !msg.Has(typenameField) {
-		return nil
+		return nil, nil
 	}
 	if !msg.Has(payloadField) {
-		panic(fmt.Sprintf("grpcrt: interface envelope %s missing payload", msg.Descriptor().FullName()))
+		return nil, r.fail(errcode.Errorf(errcode.DownstreamServiceError, "grpcrt: interface envelope %s missing payload", msg.Descriptor().FullName()))
 	}
 	typeName := msg.Get(typenameField).String()
 	desc := r.reg.GetSourceMessageDescriptor(typeName)
 	if desc == nil {
-		panic(fmt.Sprintf("grpcrt: missing source message descriptor for %s", typeName))
+		return nil, r.fail(misconfiguration(interfaceType, "", "grpcrt: missing source message descriptor for %s", typeName))
 	}
 	payload := msg.Get(payloadField).Bytes()
 	out := dynamicpb.NewMessage(desc)
 	if err := proto.Unmarshal(payload, out.Interface()); err != nil {
-		panic(fmt.Sprintf("grpcrt: failed to unmarshal payload for %s: %v", typeName, err))
+		return nil, r.fail(errcode.Errorf(errcode.DownstreamServiceError, "grpcrt: failed to unmarshal payload for %s: %v", typeName, err))
 	}
-	return out
+	return out, nil
 }
 
-func (r *Runtime) unwrapUnionEnvelope(msg protoreflect.Message) protoreflect.Message {
+// graphQLEnumValue returns the GraphQL name of a proto enum value.
+func (r *Runtime) graphQLEnumValue(ev protoreflect.EnumValueDescriptor) string {
+	if r.reg != nil {
+		if name, ok := r.reg.GetGraphQLEnumValue(ev.FullName()); ok {
+			return name
+		}
+	}
+	return string(ev.Name())
+}
+
+// protoEnumValue returns the value of enum standing for the GraphQL value name, or
+// nil when there is none.
+func (r *Runtime) protoEnumValue(enum protoreflect.EnumDescriptor, name string) protoreflect.EnumValueDescriptor {
+	if r.reg != nil {
+		if protoName, ok := r.reg.GetProtoEnumValue(enum.FullName(), name); ok {
+			return enum.Values().ByName(protoName)
+		}
+	}
+	return enum.Values().ByName(protoreflect.Name(name))
+}
+
+// isAnyMessage reports whether msg is a google.protobuf.Any envelope (@envelope(kind: ANY)).
+func isAnyMessage(msg protoreflect.Message) bool {
+	return msg.Descriptor().FullName() == anyFullName
+}
+
+const anyFullName protoreflect.FullName = "google.protobuf.Any"
+
+// unwrapAnyEnvelope decodes a google.protobuf.Any into the source message of the
+// object type its type URL names. The URL comes from the backend, so an unknown
+// type is reported as a downstream error. An empty Any is null.
+func (r *Runtime) unwrapAnyEnvelope(msg protoreflect.Message) (any, error) {
+	fields := msg.Descriptor().Fields()
+	typeURL := msg.Get(fields.ByName("type_url")).String()
+	if typeURL == "" {
+		return nil, nil
+	}
+	name := protoreflect.FullName(typeURL[strings.LastIndexByte(typeURL, '/')+1:])
+	typeName, ok := r.reg.GetMessageObjectType(name)
+	if !ok {
+		return nil, errcode.Errorf(errcode.DownstreamServiceError, "unknown message %s in google.protobuf.Any", name)
+	}
+	out := dynamicpb.NewMessage(r.reg.GetSourceMessageDescriptor(typeName))
+	if err := proto.Unmarshal(msg.Get(fields.ByName("value")).Bytes(), out.Interface()); err != nil {
+		return nil, errcode.Errorf(errcode.DownstreamServiceError, "invalid %s in google.protobuf.Any: %v", name, err)
+	}
+	return out, nil
+}
+
+// unwrapUnionEnvelope returns the member message set in the value oneof of a union
+// envelope. It returns nil when msg is not an envelope or holds no member.
+func (r *Runtime) unwrapUnionEnvelope(unionType string, msg protoreflect.Message) (protoreflect.Message, error) {
 	if msg == nil {
-		return nil
+		return nil, nil
 	}
 	desc := msg.Descriptor()
 	if desc == nil || desc.Oneofs().Len() != 1 {
-		return nil
+		return nil, nil
 	}
 	oneofDesc := desc.Oneofs().Get(0)
 	if oneofDesc == nil || string(oneofDesc.Name()) != "value" {
-		return nil
+		return nil, nil
 	}
 	fd := msg.WhichOneof(oneofDesc)
 	if fd == nil {
-		return nil
+		return nil, nil
 	}
 	if fd.Kind() != protoreflect.MessageKind {
-		panic(fmt.Sprintf("grpcrt: union envelope %s has non-message variant %s", desc.FullName(), fd.FullName()))
+		return nil, r.fail(misconfiguration(unionType, "", "grpcrt: union envelope %s has non-message variant %s", desc.FullName(), fd.FullName()))
 	}
 	if !msg.Has(fd) {
-		return nil
+		return nil, nil
 	}
-	return msg.Get(fd).Message()
+	return msg.Get(fd).Message(), nil
 }
 
-func setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error {
+func (r *Runtime) setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error {
 	if data == nil {
 		return nil
 	}
-	fields := msg.Descriptor().Fields()
-	// Cache JSONName -> FieldDescriptor to avoid O(n*m) scans
-	byJSON := make(map[string]protoreflect.FieldDescriptor, fields.Len())
-	for i := 0; i < fields.Len(); i++ {
-		f := fields.Get(i)
-		byJSON[string(f.JSONName())] = f
-	}
+	return r.setFields(msg, r.jsonFields(msg.Descriptor()), data)
+}
+
+// setFields sets the fields of msg named in data, coercing each value to its
+// field; byJSON holds the fields of msg by JSON name.
+func (r *Runtime) setFields(msg protoreflect.Message, byJSON map[string]protoreflect.FieldDescriptor, data map[string]any) error {
 	for k, v := range data {
 		// Find field by JSON name (GraphQL arg name)
 		fd := byJSON[k]
@@ -587,7 +1104,7 @@ func setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error
 			switch vv := v.(type) {
 			case []any:
 				for _, it := range vv {
-					pv, err := toProtoScalarOrMessage(fd, it)
+					pv, err := r.toProtoScalarOrMessage(fd, it)
 					if err != nil {
 						return err
 					}
@@ -595,7 +1112,11 @@ func setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error
 				}
 			case []string:
 				for _, s := range vv {
-					list.Append(protoreflect.ValueOfString(s))
+					pv, err := r.toProtoScalarOrMessage(fd, s)
+					if err != nil {
+						return err
+					}
+					list.Append(pv)
 				}
 			case []int:
 				for _, n := range vv {
@@ -627,7 +1148,7 @@ func setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error
 			msg.Set(fd, protoreflect.ValueOfList(list))
 			continue
 		}
-		val, err := toProtoScalarOrMessage(fd, v)
+		val, err := r.toProtoScalarOrMessage(fd, v)
 		if err != nil {
 			return err
 		}
@@ -636,7 +1157,10 @@ func setMessageFieldsByJSON(msg protoreflect.Message, data map[string]any) error
 	return nil
 }
 
-func toProtoScalarOrMessage(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, error) {
+func (r *Runtime) toProtoScalarOrMessage(fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, error) {
+	if n, ok := v.(json.Number); ok {
+		return numberValue(fd, n)
+	}
 	switch fd.Kind() {
 	case protoreflect.BoolKind:
 		if b, ok := v.(bool); ok {
@@ -656,6 +1180,13 @@ func toProtoScalarOrMessage(fd protoreflect.FieldDescriptor, v any) (protoreflec
 		if n, ok := v.(int); ok {
 			return protoreflect.ValueOfInt64(int64(n)), nil
 		}
+		if s, ok := v.(string); ok && r.isIDField(fd) { // ID mapped to int64
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return protoreflect.Value{}, errcode.Errorf(errcode.BadUserInput, "invalid ID %q for %s: not an int64", s, fd.JSONName())
+			}
+			return protoreflect.ValueOfInt64(n), nil
+		}
 	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
 		if n, ok := v.(uint32); ok {
 			return protoreflect.ValueOfUint32(n), nil
@@ -683,18 +1214,31 @@ func toProtoScalarOrMessage(fd protoreflect.FieldDescriptor, v any) (protoreflec
 		if b, ok := v.([]byte); ok {
 			return protoreflect.ValueOfBytes(b), nil
 		}
+		if s, ok := v.(string); ok && r.isIDField(fd) && r.reg.GetIDType() == IDUUID {
+			b, err := parseUUID(s)
+			if err != nil {
+				return protoreflect.Value{}, errcode.Errorf(errcode.BadUserInput, "invalid ID for %s: %v", fd.JSONName(), err)
+			}
+			return protoreflect.ValueOfBytes(b), nil
+		}
+		if s, ok := v.(string); ok { // base64, as bytes are written in responses
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return protoreflect.Value{}, errcode.Errorf(errcode.BadUserInput, "invalid bytes for %s: not base64", fd.JSONName())
+			}
+			return protoreflect.ValueOfBytes(b), nil
+		}
 	case protoreflect.EnumKind:
 		// Minimal: accept string enum name
 		if s, ok := v.(string); ok {
-			val := fd.Enum().Values().ByName(protoreflect.Name(s))
-			if val != nil {
+			if val := r.protoEnumValue(fd.Enum(), s); val != nil {
 				return protoreflect.ValueOfEnum(val.Number()), nil
 			}
 		}
 	case protoreflect.MessageKind:
 		if mv, ok := v.(map[string]any); ok {
 			msg := dynamicpb.NewMessage(fd.Message())
-			if err := setMessageFieldsByJSON(msg, mv); err != nil {
+			if err := r.setMessageFieldsByJSON(msg, mv); err != nil {
 				return protoreflect.Value{}, err
 			}
 			return protoreflect.ValueOfMessage(msg), nil
@@ -702,3 +1246,49 @@ func toProtoScalarOrMessage(fd protoreflect.FieldDescriptor, v any) (protoreflec
 	}
 	return protoreflect.Value{}, fmt.Errorf("unsupported arg type for %s", fd.JSONName())
 }
+
+// isIDField reports whether fd holds a GraphQL ID, rather than a value of
+// another type sharing its proto kind.
+func (r *Runtime) isIDField(fd protoreflect.FieldDescriptor) bool {
+	return r.reg != nil && r.reg.IsIDField(fd.FullName())
+}
+
+// numberValue parses a number of a variable decoded as json.Number straight
+// into the numeric kind of fd, so 64-bit integers keep their precision.
+func numberValue(fd protoreflect.FieldDescriptor, n json.Number) (protoreflect.Value, error) {
+	s := string(n)
+	var v protoreflect.Value
+	var err error
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(i))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 64)
+		v = protoreflect.ValueOfInt64(i)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(u))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, 64)
+		v = protoreflect.ValueOfUint64(u)
+	case protoreflect.FloatKind:
+		var f float64
+		f, err = strconv.ParseFloat(s, 32)
+		v = protoreflect.ValueOfFloat32(float32(f))
+	case protoreflect.DoubleKind:
+		var f float64
+		f, err = strconv.ParseFloat(s, 64)
+		v = protoreflect.ValueOfFloat64(f)
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported arg type for %s", fd.JSONName())
+	}
+	if err != nil {
+		return protoreflect.Value{}, errcode.Errorf(errcode.BadUserInput, "invalid number %s for %s: not a %s", s, fd.JSONName(), fd.Kind())
+	}
+	return v, nil
+}
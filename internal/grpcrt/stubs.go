@@ -0,0 +1,87 @@
+package grpcrt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/internal/schema"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// stubExtension is the response extensions key of stubbed field warnings, shared
+// with the unknown enum warnings.
+const stubExtension = unknownEnumExtension
+
+// WithStubs serves async fields without a registered resolver or loader with
+// placeholder values typed after their field in sch, instead of panicking, and
+// reports each stubbed field as a warning in the response extensions. It is meant
+// for exploring partially implemented backends during development.
+func WithStubs(sch *schema.Schema) Option {
+	return func(r *Runtime) { r.stubs = sch }
+}
+
+// serveStubs fills the results of a group without a method with placeholders.
+func (r *Runtime) serveStubs(ctx context.Context, g group, results []executor.AsyncResolveResult) {
+	var value any
+	if t := r.stubs.Types[g.objectType]; t != nil {
+		if f := t.Fields[g.field]; f != nil {
+			value = r.stubValue(g.objectType+"."+g.field, f.Type)
+		}
+	}
+	for _, idx := range g.idxs {
+		results[idx] = executor.AsyncResolveResult{Value: value}
+	}
+	warning := map[string]any{
+		"message": fmt.Sprintf("%s.%s has no resolver; served a placeholder", g.objectType, g.field),
+		"field":   g.objectType + "." + g.field,
+	}
+	executor.ExtensionsFromContext(ctx).Update(stubExtension, func(prev any) any {
+		prevWarnings, _ := prev.([]any)
+		warnings := make([]any, len(prevWarnings), len(prevWarnings)+1)
+		copy(warnings, prevWarnings)
+		return append(warnings, warning)
+	})
+}
+
+// stubValue returns the placeholder of a value of type t: the field coordinate for
+// strings, IDs and custom scalars, zero for numbers, false, the first enum value,
+// an empty list, or an empty source message whose own fields resolve as usual.
+// Abstract types have no message to pick, so they are stubbed as null.
+func (r *Runtime) stubValue(coordinate string, t *schema.TypeRef) any {
+	switch t.Kind {
+	case schema.TypeRefKindNonNull:
+		return r.stubValue(coordinate, t.OfType)
+	case schema.TypeRefKindList:
+		return []any{}
+	}
+	switch t.Named {
+	case "Int":
+		return int32(0)
+	case "Float":
+		return float64(0)
+	case "Boolean":
+		return false
+	case "String", "ID":
+		return coordinate
+	}
+	named := r.stubs.Types[t.Named]
+	if named == nil {
+		return nil
+	}
+	switch named.Kind {
+	case schema.TypeKindObject:
+		if desc := r.reg.GetSourceMessageDescriptor(named.Name); desc != nil {
+			return dynamicpb.NewMessage(desc)
+		}
+		return nil
+	case schema.TypeKindEnum:
+		if len(named.EnumValues) == 0 {
+			return nil
+		}
+		return named.EnumValues[0].Name
+	case schema.TypeKindScalar:
+		return coordinate
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
     "fmt"
     "sync"
 
+    "google.golang.org/grpc/metadata"
     "google.golang.org/protobuf/proto"
     "google.golang.org/protobuf/reflect/protoreflect"
 )
@@ -17,6 +18,8 @@ type CallRecord struct {
     FullMethod string
     // Request is a deep-cloned proto message snapshot of the input.
     Request proto.Message
+    // Metadata is the outgoing gRPC metadata of the call context.
+    Metadata metadata.MD
 }
 
 // MockTransport implements Transport and returns pre-seeded responses
@@ -51,7 +54,7 @@ func NewMockTransportWithErrors(responses []protoreflect.Message, errs []error)
 // Call records the invocation and returns the next queued response.
 // If responses are exhausted, it returns an error.
 func (m *MockTransport) Call(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
-    _ = ctx
+    md, _ := metadata.FromOutgoingContext(ctx)
     m.mu.Lock()
     defer m.mu.Unlock()
 
@@ -64,7 +67,7 @@ func (m *MockTransport) Call(ctx context.Context, method protoreflect.MethodDesc
     if method != nil {
         full = fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
     }
-    m.calls = append(m.calls, CallRecord{Method: method, FullMethod: full, Request: reqClone})
+    m.calls = append(m.calls, CallRecord{Method: method, FullMethod: full, Request: reqClone, Metadata: md})
 
     if m.idx >= len(m.responses) && m.idx >= len(m.errs) {
         return nil, fmt.Errorf("mock transport: no more responses")
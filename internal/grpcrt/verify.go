@@ -0,0 +1,115 @@
+package grpcrt
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hanpama/protograph/internal/schema"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// VerificationError lists the fields of a schema a registry cannot serve.
+type VerificationError struct {
+	Problems []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("registry does not cover the schema (%d problems):\n  %s", len(e.Problems), strings.Join(e.Problems, "\n  "))
+}
+
+// Verify cross-checks sch against reg before serving it, so that gaps surface at
+// startup rather than as panics mid-request: every async field must route to a
+// node dispatch, resolver or loader, whose request has a field for each argument
+// not sent as metadata or, for @connection fields, as a page, and every sync field must be a @const, a @compute or
+// read from a source field. It returns a *VerificationError listing every gap.
+func Verify(reg Registry, sch *schema.Schema) error {
+	var problems []string
+	for _, name := range sch.GetOrderedTypeNames() {
+		t := sch.Types[name]
+		if t.Kind != schema.TypeKindObject || strings.HasPrefix(name, "__") {
+			continue
+		}
+		fieldNames := make([]string, 0, len(t.Fields))
+		for fieldName := range t.Fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+		for _, fieldName := range fieldNames {
+			f := t.Fields[fieldName]
+			coordinate := name + "." + fieldName
+			if f.Async {
+				problems = append(problems, verifyAsyncField(reg, name, f, coordinate)...)
+				continue
+			}
+			if _, ok := reg.GetConstField(name, fieldName); ok {
+				continue
+			}
+			if _, ok := reg.GetComputedField(name, fieldName); ok {
+				continue
+			}
+			if len(reg.GetSourceFieldPath(name, fieldName)) == 0 && reg.GetSourceFieldDescriptor(name, fieldName) == nil {
+				problems = append(problems, fmt.Sprintf("%s: sync field has no source field descriptor", coordinate))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &VerificationError{Problems: problems}
+	}
+	return nil
+}
+
+// verifyAsyncField checks the method BatchResolveAsync routes an async field to.
+func verifyAsyncField(reg Registry, objectType string, f *schema.Field, coordinate string) []string {
+	if reg.IsNodeField(objectType, f.Name) {
+		return nil
+	}
+	var request protoreflect.MessageDescriptor
+	if md := reg.GetBatchResolverDescriptor(objectType, f.Name); md != nil {
+		request = batchItem(md)
+	} else if md := reg.GetSingleResolverDescriptor(objectType, f.Name); md != nil {
+		request = md.Input()
+	} else if md := reg.GetBatchLoaderDescriptor(objectType, f.Name); md != nil {
+		request = batchItem(md)
+	} else if md := reg.GetSingleLoaderDescriptor(objectType, f.Name); md != nil {
+		request = md.Input()
+	} else {
+		return []string{fmt.Sprintf("%s: async field has no resolver or loader descriptor", coordinate)}
+	}
+	if request == nil {
+		return []string{fmt.Sprintf("%s: batch request has no batches message field", coordinate)}
+	}
+	metadataArgs := reg.GetMetadataArguments(objectType, f.Name)
+	argNames := make([]string, 0, len(f.Arguments))
+	for argName := range f.Arguments {
+		if _, ok := metadataArgs[argName]; !ok {
+			argNames = append(argNames, argName)
+		}
+	}
+	var problems []string
+	if connType, ok := reg.GetConnectionType(objectType, f.Name); ok {
+		// Pagination arguments are sent as the page they select
+		argNames = slices.DeleteFunc(argNames, func(name string) bool { return slices.Contains(connectionArgs, name) })
+		argNames = append(argNames, offsetArg, limitArg)
+		if reg.GetSourceMessageDescriptor(connType) == nil {
+			problems = append(problems, fmt.Sprintf("%s: connection %s has no source message descriptor", coordinate, connType))
+		}
+	}
+	sort.Strings(argNames)
+	for _, argName := range argNames {
+		if request.Fields().ByJSONName(argName) == nil {
+			problems = append(problems, fmt.Sprintf("%s: request %s has no field for argument %s", coordinate, request.FullName(), argName))
+		}
+	}
+	return problems
+}
+
+// batchItem returns the message of the batches of a batch method's request.
+func batchItem(md protoreflect.MethodDescriptor) protoreflect.MessageDescriptor {
+	fd := md.Input().Fields().ByName("batches")
+	if fd == nil || fd.Message() == nil {
+		return nil
+	}
+	return fd.Message()
+}
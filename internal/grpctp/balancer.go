@@ -0,0 +1,200 @@
+package grpctp
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Balancer picks the endpoint of each call among the endpoints of its service.
+// Implementations must be safe for concurrent use.
+type Balancer interface {
+	// Pick returns one of endpoints, which is never empty, and a func called
+	// once the call to it has finished.
+	Pick(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message, endpoints []string) (endpoint string, done func())
+}
+
+// Balancing policy names accepted by NewBalancer.
+const (
+	PolicyRandom           = "random"
+	PolicyRoundRobin       = "round_robin"
+	PolicyLeastOutstanding = "least_outstanding"
+	PolicyWeighted         = "weighted"
+	PolicyConsistentHash   = "consistent_hash"
+)
+
+// NewBalancer returns the balancer of a policy name. weights are used by the
+// weighted policy only.
+func NewBalancer(policy string, weights map[string]int) (Balancer, error) {
+	switch policy {
+	case "", PolicyRandom:
+		return Random(), nil
+	case PolicyRoundRobin:
+		return RoundRobin(), nil
+	case PolicyLeastOutstanding:
+		return LeastOutstanding(), nil
+	case PolicyWeighted:
+		return Weighted(weights), nil
+	case PolicyConsistentHash:
+		return ConsistentHash(nil), nil
+	}
+	return nil, fmt.Errorf("grpctp: unknown balancing policy %q", policy)
+}
+
+func noop() {}
+
+type randomBalancer struct{}
+
+// Random picks endpoints uniformly at random. It is the default.
+func Random() Balancer { return randomBalancer{} }
+
+func (randomBalancer) Pick(_ context.Context, _ protoreflect.MethodDescriptor, _ protoreflect.Message, endpoints []string) (string, func()) {
+	return endpoints[rand.Intn(len(endpoints))], noop
+}
+
+type roundRobinBalancer struct {
+	next sync.Map // service -> *atomic.Uint64
+}
+
+// RoundRobin cycles through the endpoints of each service in order.
+func RoundRobin() Balancer { return &roundRobinBalancer{} }
+
+func (b *roundRobinBalancer) Pick(_ context.Context, method protoreflect.MethodDescriptor, _ protoreflect.Message, endpoints []string) (string, func()) {
+	v, _ := b.next.LoadOrStore(method.Parent().FullName(), new(atomic.Uint64))
+	n := v.(*atomic.Uint64).Add(1) - 1
+	return endpoints[n%uint64(len(endpoints))], noop
+}
+
+type leastOutstandingBalancer struct {
+	mu          sync.Mutex
+	outstanding map[string]int
+}
+
+// LeastOutstanding picks the endpoint with the fewest calls in flight from this
+// transport, breaking ties at random.
+func LeastOutstanding() Balancer {
+	return &leastOutstandingBalancer{outstanding: map[string]int{}}
+}
+
+func (b *leastOutstandingBalancer) Pick(_ context.Context, _ protoreflect.MethodDescriptor, _ protoreflect.Message, endpoints []string) (string, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	best, ties := endpoints[0], 1
+	for _, ep := range endpoints[1:] {
+		switch n := b.outstanding[ep]; {
+		case n < b.outstanding[best]:
+			best, ties = ep, 1
+		case n == b.outstanding[best]:
+			// Reservoir sampling keeps each tied endpoint with equal probability
+			if ties++; rand.Intn(ties) == 0 {
+				best = ep
+			}
+		}
+	}
+	b.outstanding[best]++
+	return best, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.outstanding[best]--; b.outstanding[best] <= 0 {
+			delete(b.outstanding, best)
+		}
+	}
+}
+
+type weightedBalancer struct {
+	weights map[string]int
+
+	mu      sync.Mutex
+	current map[string]int
+}
+
+// Weighted spreads calls in proportion to the weights of endpoints, interleaving
+// them smoothly. Endpoints without a positive weight have weight 1.
+func Weighted(weights map[string]int) Balancer {
+	cp := make(map[string]int, len(weights))
+	for ep, w := range weights {
+		cp[ep] = w
+	}
+	return &weightedBalancer{weights: cp, current: map[string]int{}}
+}
+
+func (b *weightedBalancer) weight(ep string) int {
+	if w := b.weights[ep]; w > 0 {
+		return w
+	}
+	return 1
+}
+
+// Pick implements smooth weighted round robin: every endpoint gains its weight,
+// and the one with the highest total is picked and loses the sum of weights.
+func (b *weightedBalancer) Pick(_ context.Context, _ protoreflect.MethodDescriptor, _ protoreflect.Message, endpoints []string) (string, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	best := ""
+	for _, ep := range endpoints {
+		w := b.weight(ep)
+		total += w
+		b.current[ep] += w
+		if best == "" || b.current[ep] > b.current[best] {
+			best = ep
+		}
+	}
+	b.current[best] -= total
+	return best, noop
+}
+
+// KeyFunc returns the key a call is hashed on.
+type KeyFunc func(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) string
+
+type consistentHashBalancer struct {
+	key KeyFunc
+}
+
+// ConsistentHash sends calls with the same key to the same endpoint, so backends
+// can keep per-key caches warm. Endpoints are chosen by rendezvous hashing: when
+// one is added or removed, only the keys it owns move. With a nil key, calls are
+// keyed by their method and deterministically encoded request, so the same tasks
+// reach the same endpoint.
+func ConsistentHash(key KeyFunc) Balancer {
+	if key == nil {
+		key = requestKey
+	}
+	return &consistentHashBalancer{key: key}
+}
+
+func requestKey(_ context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) string {
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(request.Interface())
+	return string(method.FullName()) + "\x00" + string(b)
+}
+
+func (b *consistentHashBalancer) Pick(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message, endpoints []string) (string, func()) {
+	key := b.key(ctx, method, request)
+	best, bestScore := "", uint64(0)
+	for _, ep := range endpoints {
+		h := fnv.New64a()
+		h.Write([]byte(ep))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		if score := mix(h.Sum64()); best == "" || score > bestScore {
+			best, bestScore = ep, score
+		}
+	}
+	return best, noop
+}
+
+// mix is the splitmix64 finalizer, spreading FNV hashes that differ only in a few
+// bits over the whole range.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
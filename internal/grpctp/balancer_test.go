@@ -0,0 +1,164 @@
+package grpctp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testMethod returns the method Get of service t.<service>, taking and
+// returning an empty message.
+func testMethod(t *testing.T, service string) protoreflect.MethodDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(strings.ToLower(service) + ".proto"),
+		Package:     proto.String("t"),
+		MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("M")}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String(service),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".t.M"),
+				OutputType: proto.String(".t.M"),
+			}},
+		}},
+		Syntax: proto.String("proto3"),
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+	return fd.Services().Get(0).Methods().Get(0)
+}
+
+// pick picks an endpoint of eps and releases it at once.
+func pick(b Balancer, md protoreflect.MethodDescriptor, req protoreflect.Message, eps []string) string {
+	ep, done := b.Pick(context.Background(), md, req, eps)
+	done()
+	return ep
+}
+
+func TestNewBalancer(t *testing.T) {
+	for _, policy := range []string{"", PolicyRandom, PolicyRoundRobin, PolicyLeastOutstanding, PolicyWeighted, PolicyConsistentHash} {
+		b, err := NewBalancer(policy, nil)
+		require.NoError(t, err, policy)
+		require.NotNil(t, b, policy)
+	}
+	_, err := NewBalancer("fastest", nil)
+	require.ErrorContains(t, err, `unknown balancing policy "fastest"`)
+}
+
+func TestRandom_PicksEveryEndpoint(t *testing.T) {
+	md := testMethod(t, "A")
+	eps := []string{"a", "b", "c"}
+	counts := map[string]int{}
+	for range 3000 {
+		counts[pick(Random(), md, nil, eps)]++
+	}
+	for _, ep := range eps {
+		require.InDelta(t, 1000, counts[ep], 200, ep)
+	}
+}
+
+func TestRoundRobin_CyclesEachService(t *testing.T) {
+	a, b := testMethod(t, "A"), testMethod(t, "B")
+	eps := []string{"x", "y", "z"}
+	rr := RoundRobin()
+	var got []string
+	for _, md := range []protoreflect.MethodDescriptor{a, a, b, a, b, a} {
+		got = append(got, pick(rr, md, nil, eps))
+	}
+	// Services keep their own positions
+	require.Equal(t, []string{"x", "y", "x", "z", "y", "x"}, got)
+}
+
+func TestLeastOutstanding_PicksFewestInFlight(t *testing.T) {
+	md := testMethod(t, "A")
+	eps := []string{"a", "b", "c"}
+	lo := LeastOutstanding()
+
+	// Held calls spread over every endpoint before any gets a second one
+	held := map[string]func(){}
+	for range 3 {
+		ep, done := lo.Pick(context.Background(), md, nil, eps)
+		require.NotContains(t, held, ep)
+		held[ep] = done
+	}
+	// Once b and c finish, a keeps the only call in flight
+	held["b"]()
+	held["c"]()
+	for range 10 {
+		require.Contains(t, []string{"b", "c"}, pick(lo, md, nil, eps))
+	}
+	held["a"]()
+
+	// Ties break at random rather than by order
+	counts := map[string]int{}
+	for range 3000 {
+		counts[pick(lo, md, nil, eps)]++
+	}
+	for _, ep := range eps {
+		require.InDelta(t, 1000, counts[ep], 200, ep)
+	}
+}
+
+func TestWeighted_InterleavesByWeight(t *testing.T) {
+	md := testMethod(t, "A")
+	w := Weighted(map[string]int{"a": 5, "b": 1})
+	var got []string
+	for range 7 {
+		got = append(got, pick(w, md, nil, []string{"a", "b", "c"}))
+	}
+	// c has no weight and counts as 1; a is spread over the cycle
+	require.Equal(t, []string{"a", "a", "b", "a", "c", "a", "a"}, got)
+}
+
+func TestConsistentHash_StickyAndStable(t *testing.T) {
+	md := testMethod(t, "A")
+	ch := ConsistentHash(nil)
+	four := []string{"a", "b", "c", "d"}
+	owners := map[string]string{}
+	counts := map[string]int{}
+	for i := range 1000 {
+		key := fmt.Sprint(i)
+		ep := pick(ch, md, wrapperspb.String(key).ProtoReflect(), four)
+		owners[key] = ep
+		counts[ep]++
+		// The same request always reaches the same endpoint
+		require.Equal(t, ep, pick(ch, md, wrapperspb.String(key).ProtoReflect(), four))
+	}
+	for _, ep := range four {
+		require.InDelta(t, 250, counts[ep], 80, ep)
+	}
+
+	// Removing d moves only the keys it owned
+	three := []string{"c", "a", "b"}
+	for key, owner := range owners {
+		ep := pick(ch, md, wrapperspb.String(key).ProtoReflect(), three)
+		if owner != "d" {
+			require.Equal(t, owner, ep, key)
+		}
+	}
+
+	// A key function overrides the request
+	byTenant := ConsistentHash(func(ctx context.Context, _ protoreflect.MethodDescriptor, _ protoreflect.Message) string {
+		return "acme"
+	})
+	first := pick(byTenant, md, wrapperspb.String("1").ProtoReflect(), four)
+	for i := range 10 {
+		require.Equal(t, first, pick(byTenant, md, wrapperspb.String(fmt.Sprint(i)).ProtoReflect(), four))
+	}
+}
+
+func TestServiceBalancer(t *testing.T) {
+	rr := RoundRobin()
+	tr := New(WithBalancer(Random()), WithServiceBalancer("t.A", rr))
+	require.Same(t, rr, tr.opts.balancer("t.A"))
+	require.Equal(t, Random(), tr.opts.balancer("t.B"))
+}
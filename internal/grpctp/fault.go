@@ -0,0 +1,104 @@
+package grpctp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hanpama/protograph/internal/grpcrt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Fault describes the chaos injected into matching calls.
+type Fault struct {
+	// Method matches calls by "pkg.Service/Method", by "pkg.Service" or, with "*",
+	// every call.
+	Method string
+	// Latency delays every matching call before it is sent.
+	Latency time.Duration
+	// ErrorRate is the share of matching calls, from 0 to 1, failing with Code
+	// instead of being sent.
+	ErrorRate float64
+	// Code of injected errors (default: Unavailable).
+	Code codes.Code
+}
+
+func (f Fault) matches(method protoreflect.MethodDescriptor) bool {
+	service := string(method.Parent().FullName())
+	return f.Method == "*" || f.Method == service || f.Method == service+"/"+string(method.Name())
+}
+
+// ParseFault parses "<method>=<setting>,..." where settings are latency:<duration>,
+// error:<rate> and code:<gRPC code name>, e.g.
+// "blog.PostService=latency:200ms,error:0.1,code:UNAVAILABLE".
+func ParseFault(s string) (Fault, error) {
+	method, settings, ok := strings.Cut(s, "=")
+	f := Fault{Method: strings.TrimSpace(method), Code: codes.Unavailable}
+	if !ok || f.Method == "" {
+		return Fault{}, fmt.Errorf("grpctp: invalid fault %q", s)
+	}
+	for _, setting := range strings.Split(settings, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(setting), ":")
+		var err error
+		switch name {
+		case "latency":
+			f.Latency, err = time.ParseDuration(value)
+		case "error":
+			f.ErrorRate, err = strconv.ParseFloat(value, 64)
+			if err == nil && (f.ErrorRate < 0 || f.ErrorRate > 1) {
+				err = fmt.Errorf("rate out of [0, 1]")
+			}
+		case "code":
+			err = f.Code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(value))))
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return Fault{}, fmt.Errorf("grpctp: invalid fault setting %q in %q: %v", setting, s, err)
+		}
+	}
+	return f, nil
+}
+
+// FaultInjector is a transport injecting latency and errors into the calls of
+// another, to test how the gateway behaves when backends are slow or failing.
+// The first fault matching a call applies.
+type FaultInjector struct {
+	next   grpcrt.Transport
+	faults []Fault
+}
+
+// NewFaultInjector returns a transport applying faults to the calls of next.
+func NewFaultInjector(next grpcrt.Transport, faults ...Fault) *FaultInjector {
+	return &FaultInjector{next: next, faults: faults}
+}
+
+var _ grpcrt.Transport = (*FaultInjector)(nil)
+
+// Call implements grpcrt.Transport.
+func (t *FaultInjector) Call(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
+	for _, f := range t.faults {
+		if !f.matches(method) {
+			continue
+		}
+		if f.Latency > 0 {
+			timer := time.NewTimer(f.Latency)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+		if f.ErrorRate > 0 && rand.Float64() < f.ErrorRate {
+			return nil, status.Errorf(f.Code, "grpctp: injected fault in /%s/%s", method.Parent().FullName(), method.Name())
+		}
+		break
+	}
+	return t.next.Call(ctx, method, request)
+}
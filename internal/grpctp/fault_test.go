@@ -0,0 +1,118 @@
+package grpctp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// countingTransport answers every call with its request, counting them.
+type countingTransport struct{ calls atomic.Int32 }
+
+func (c *countingTransport) Call(_ context.Context, _ protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
+	c.calls.Add(1)
+	return request, nil
+}
+
+func TestParseFault(t *testing.T) {
+	f, err := ParseFault("t.A/Get=latency:200ms, error:0.1, code:not_found")
+	require.NoError(t, err)
+	require.Equal(t, Fault{Method: "t.A/Get", Latency: 200 * time.Millisecond, ErrorRate: 0.1, Code: codes.NotFound}, f)
+
+	f, err = ParseFault("*=error:1")
+	require.NoError(t, err)
+	require.Equal(t, Fault{Method: "*", ErrorRate: 1, Code: codes.Unavailable}, f)
+
+	for _, s := range []string{
+		"t.A",
+		"=error:1",
+		"t.A=error:1.5",
+		"t.A=error:-0.1",
+		"t.A=latency:soon",
+		"t.A=code:NOPE",
+		"t.A=retries:3",
+	} {
+		_, err := ParseFault(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestFault_Matches(t *testing.T) {
+	md := testMethod(t, "A")
+	for method, want := range map[string]bool{
+		"*":       true,
+		"t.A":     true,
+		"t.A/Get": true,
+		"t.A/Put": false,
+		"t.B":     false,
+		"A":       false,
+	} {
+		require.Equal(t, want, Fault{Method: method}.matches(md), method)
+	}
+}
+
+func TestFaultInjector_ErrorRate(t *testing.T) {
+	a, b := testMethod(t, "A"), testMethod(t, "B")
+	for _, tc := range []struct {
+		rate     float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{0.3, 1300, 1700},
+		{1, 5000, 5000},
+	} {
+		next := &countingTransport{}
+		inj := NewFaultInjector(next, Fault{Method: "t.A", ErrorRate: tc.rate, Code: codes.ResourceExhausted})
+		failed := 0
+		for range 5000 {
+			if _, err := inj.Call(context.Background(), a, nil); err != nil {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+				failed++
+			}
+		}
+		require.GreaterOrEqual(t, failed, tc.min, "rate %v", tc.rate)
+		require.LessOrEqual(t, failed, tc.max, "rate %v", tc.rate)
+		// Failed calls never reach the backend
+		require.EqualValues(t, 5000-failed, next.calls.Load(), "rate %v", tc.rate)
+
+		// Calls of other services pass through
+		_, err := inj.Call(context.Background(), b, nil)
+		require.NoError(t, err)
+	}
+}
+
+func TestFaultInjector_FirstMatchApplies(t *testing.T) {
+	a, b := testMethod(t, "A"), testMethod(t, "B")
+	next := &countingTransport{}
+	inj := NewFaultInjector(next, Fault{Method: "t.A/Get"}, Fault{Method: "*", ErrorRate: 1, Code: codes.Unavailable})
+	for range 10 {
+		_, err := inj.Call(context.Background(), a, nil)
+		require.NoError(t, err)
+	}
+	_, err := inj.Call(context.Background(), b, nil)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestFaultInjector_Latency(t *testing.T) {
+	md := testMethod(t, "A")
+	next := &countingTransport{}
+	inj := NewFaultInjector(next, Fault{Method: "*", Latency: 30 * time.Millisecond})
+
+	start := time.Now()
+	_, err := inj.Call(context.Background(), md, nil)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// The delay ends with the call's deadline, without reaching the backend
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = inj.Call(ctx, md, nil)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.EqualValues(t, 1, next.calls.Load())
+}
@@ -0,0 +1,77 @@
+package grpctp
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/hanpama/protograph/internal/grpcrt"
+	respheader "github.com/hanpama/protograph/internal/respheader"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MirrorHeader is the metadata key marking mirrored calls, so shadow backends
+// can tell them from user traffic.
+const MirrorHeader = "x-protograph-mirror"
+
+// MirrorOptions configures a Mirror.
+type MirrorOptions struct {
+	// Percent of calls, from 0 to 100, also sent to the shadow transport.
+	Percent float64
+	// Timeout bounds each mirrored call (default: 3s).
+	Timeout time.Duration
+	// MaxInFlight bounds the mirrored calls in flight; calls beyond it are not
+	// mirrored, so a slow shadow backend never piles up work (default: 100).
+	MaxInFlight int
+}
+
+// Mirror is a transport sending every call to a primary transport and a share of
+// them to a shadow transport too, for canarying new backend versions with live
+// traffic. Shadow calls run in the background; their responses and errors are
+// discarded and never affect the primary call.
+type Mirror struct {
+	primary grpcrt.Transport
+	shadow  grpcrt.Transport
+	opts    MirrorOptions
+	slots   chan struct{}
+}
+
+// NewMirror returns a transport mirroring calls of primary to shadow.
+func NewMirror(primary, shadow grpcrt.Transport, opts MirrorOptions) *Mirror {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 3 * time.Second
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 100
+	}
+	return &Mirror{primary: primary, shadow: shadow, opts: opts, slots: make(chan struct{}, opts.MaxInFlight)}
+}
+
+var _ grpcrt.Transport = (*Mirror)(nil)
+
+// Call implements grpcrt.Transport.
+func (m *Mirror) Call(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) (protoreflect.Message, error) {
+	if m.opts.Percent > 0 && rand.Float64()*100 < m.opts.Percent {
+		m.mirror(ctx, method, request)
+	}
+	return m.primary.Call(ctx, method, request)
+}
+
+func (m *Mirror) mirror(ctx context.Context, method protoreflect.MethodDescriptor, request protoreflect.Message) {
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		return
+	}
+	// The request is cloned as grpcrt owns it once the primary call returns
+	req := proto.Clone(request.Interface()).ProtoReflect()
+	ctx, cancel := context.WithTimeout(respheader.Detach(context.WithoutCancel(ctx)), m.opts.Timeout)
+	ctx = metadata.AppendToOutgoingContext(ctx, MirrorHeader, "1")
+	go func() {
+		defer func() { <-m.slots }()
+		defer cancel()
+		_, _ = m.shadow.Call(ctx, method, req)
+	}()
+}
@@ -1,6 +1,7 @@
 package grpctp
 
 import (
+	"log"
 	"time"
 
 	"google.golang.org/grpc"
@@ -10,8 +11,12 @@ import (
 //
 // Defaults:
 // - MaxConnsPerEndpoint: 2
+// - MaxStreamsPerConn:   0 (each connection carries one call at a time)
 // - RPCTimeout:          3s (used only if incoming context has no deadline)
 // - DialOptions:         insecure credentials
+// - SlowCallThreshold:   0 (slow-call log disabled)
+// - Balancer:            Random, for services without one in Balancers
+// - Keepalive, IdleTimeout: gRPC defaults (no keepalive pings, 30m idle timeout)
 //
 // EndpointProvider must be provided (use StaticEndpoints or a custom implementation).
 // If Provider is nil, the transport will error on calls.
@@ -24,7 +29,37 @@ type Options struct {
 	MaxConnsPerEndpoint int
 	RPCTimeout          time.Duration
 
+	// MaxStreamsPerConn shares each pooled connection among up to this many
+	// concurrent calls, and caps a pool at MaxConnsPerEndpoint connections:
+	// calls beyond wait for a stream. 0 lends a connection to one call at a
+	// time, dialing extra connections for the calls finding none idle.
+	MaxStreamsPerConn int
+	// ConnsOnDemand makes pools sharing connections dial a new one only when
+	// every open one is at MaxStreamsPerConn, instead of opening
+	// MaxConnsPerEndpoint connections before sharing any.
+	ConnsOnDemand bool
+
 	DialOptions []grpc.DialOption
+
+	// Balancer picks the endpoint of each call when a service has several.
+	// Balancers overrides it for the services they name, by full name such as
+	// blog.PostService.
+	Balancer  Balancer
+	Balancers map[string]Balancer
+
+	// KeepaliveTime pings idle connections this often, so connections behind NATs
+	// and load balancers are not silently dropped. KeepaliveTimeout bounds the wait
+	// for the ping ack before the connection is closed. 0 leaves them off.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// IdleTimeout moves connections without calls for this long to idle, closing
+	// their transports until the next call. 0 keeps the gRPC default.
+	IdleTimeout time.Duration
+
+	// SlowCallThreshold logs every call taking at least this long to SlowCallLog,
+	// or to the standard logger when SlowCallLog is nil.
+	SlowCallThreshold time.Duration
+	SlowCallLog       *log.Logger
 }
 
 // Option mutates Options
@@ -37,6 +72,7 @@ func defaultOptions() *Options {
 	return &Options{
 		MaxConnsPerEndpoint: 2,
 		RPCTimeout:          3 * time.Second,
+		Balancer:            Random(),
 	}
 }
 
@@ -46,3 +82,44 @@ func WithRPCTimeout(d time.Duration) Option  { return func(o *Options) { o.RPCTi
 func WithDialOptions(opts ...grpc.DialOption) Option {
 	return func(o *Options) { o.DialOptions = opts }
 }
+func WithBalancer(b Balancer) Option { return func(o *Options) { o.Balancer = b } }
+
+// WithServiceBalancer balances the calls of one service, by full name, with b
+// instead of Options.Balancer.
+func WithServiceBalancer(service string, b Balancer) Option {
+	return func(o *Options) {
+		if o.Balancers == nil {
+			o.Balancers = map[string]Balancer{}
+		}
+		o.Balancers[service] = b
+	}
+}
+
+// balancer returns the balancer of the calls of service.
+func (o *Options) balancer(service string) Balancer {
+	if b, ok := o.Balancers[service]; ok {
+		return b
+	}
+	return o.Balancer
+}
+
+// WithMaxStreamsPerConn shares each connection among up to n concurrent calls;
+// see Options.MaxStreamsPerConn.
+func WithMaxStreamsPerConn(n int) Option { return func(o *Options) { o.MaxStreamsPerConn = n } }
+
+// WithConnsOnDemand grows pools sharing connections one connection at a time,
+// as their open ones fill up.
+func WithConnsOnDemand() Option { return func(o *Options) { o.ConnsOnDemand = true } }
+
+// WithKeepalive pings idle connections every interval, closing them when a ping
+// is not acknowledged within timeout.
+func WithKeepalive(interval, timeout time.Duration) Option {
+	return func(o *Options) { o.KeepaliveTime, o.KeepaliveTimeout = interval, timeout }
+}
+func WithIdleTimeout(d time.Duration) Option { return func(o *Options) { o.IdleTimeout = d } }
+
+// WithSlowCallLog logs calls taking at least threshold to logger, or to the
+// standard logger when logger is nil.
+func WithSlowCallLog(threshold time.Duration, logger *log.Logger) Option {
+	return func(o *Options) { o.SlowCallThreshold, o.SlowCallLog = threshold, logger }
+}
@@ -2,13 +2,17 @@ package grpctp
 
 import (
 	"context"
+	"fmt"
 	"sync"
+
+	"github.com/hanpama/protograph/protographctx"
 )
 
 // EndpointProvider provides a list of reachable endpoints (host:port) for a given
 // fully-qualified gRPC service name (e.g. "graphql.UserService").
 // Implementations may integrate with service discovery/registry systems.
-// Return at least one endpoint or an error.
+// ctx is the context of the call, carrying the request it is made for, e.g. its
+// tenant (see TenantEndpoints). Return at least one endpoint or an error.
 // Implementations should be safe for concurrent use.
 
 type EndpointProvider interface {
@@ -45,3 +49,49 @@ func (s *StaticEndpoints) Endpoints(ctx context.Context, service string) ([]stri
 	copy(out, arr)
 	return out, nil
 }
+
+// TenantEndpoints routes the calls of each tenant, read with
+// protographctx.Tenant, to its own provider. Calls without a tenant, or for a
+// tenant without a provider, use the fallback; with a nil fallback they fail.
+type TenantEndpoints struct {
+	tenants  map[string]EndpointProvider
+	fallback EndpointProvider
+}
+
+func NewTenantEndpoints(tenants map[string]EndpointProvider, fallback EndpointProvider) *TenantEndpoints {
+	cp := make(map[string]EndpointProvider, len(tenants))
+	for k, v := range tenants {
+		cp[k] = v
+	}
+	return &TenantEndpoints{tenants: cp, fallback: fallback}
+}
+
+func (t *TenantEndpoints) Endpoints(ctx context.Context, service string) ([]string, error) {
+	p, err := t.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.Endpoints(ctx, service)
+}
+
+// AllEndpoints returns the endpoints of service for the tenant of ctx, for
+// warming up.
+func (t *TenantEndpoints) AllEndpoints(ctx context.Context, service string) ([]string, error) {
+	p, err := t.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return allEndpoints(ctx, p, service)
+}
+
+// provider returns the provider of the tenant of ctx.
+func (t *TenantEndpoints) provider(ctx context.Context) (EndpointProvider, error) {
+	tenant, _ := protographctx.Tenant(ctx)
+	if p, ok := t.tenants[tenant]; ok {
+		return p, nil
+	}
+	if t.fallback == nil {
+		return nil, fmt.Errorf("%w: unknown tenant %q", ErrNoEndpoints, tenant)
+	}
+	return t.fallback, nil
+}
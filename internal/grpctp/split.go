@@ -0,0 +1,105 @@
+package grpctp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	reqid "github.com/hanpama/protograph/internal/reqid"
+)
+
+// EndpointGroup is one version of a service's backend, e.g. the stable or the
+// canary deployment, receiving Weight percent of the requests.
+type EndpointGroup struct {
+	Name      string
+	Endpoints []string
+	Weight    int
+}
+
+// SplitEndpoints splits the traffic of services between endpoint groups by
+// weight, for gradual rollouts of a new backend version. The group is picked
+// from the request ID, so every call of one request reaches the same version of
+// every split service; calls without a request ID pick at random. Services
+// without groups are served by the base provider.
+type SplitEndpoints struct {
+	base   EndpointProvider
+	splits map[string][]EndpointGroup
+}
+
+// NewSplitEndpoints validates that the weights of each service are positive and
+// add up to 100.
+func NewSplitEndpoints(base EndpointProvider, splits map[string][]EndpointGroup) (*SplitEndpoints, error) {
+	cp := make(map[string][]EndpointGroup, len(splits))
+	for service, groups := range splits {
+		total := 0
+		for _, g := range groups {
+			if g.Weight <= 0 || len(g.Endpoints) == 0 {
+				return nil, fmt.Errorf("grpctp: split of %s: group %s needs endpoints and a positive weight", service, g.Name)
+			}
+			total += g.Weight
+		}
+		if total != 100 {
+			return nil, fmt.Errorf("grpctp: split of %s: weights add up to %d%%, want 100%%", service, total)
+		}
+		cp[service] = append([]EndpointGroup(nil), groups...)
+	}
+	return &SplitEndpoints{base: base, splits: cp}, nil
+}
+
+func (s *SplitEndpoints) Endpoints(ctx context.Context, service string) ([]string, error) {
+	groups, ok := s.splits[service]
+	if !ok {
+		if s.base == nil {
+			return nil, ErrNoEndpoints
+		}
+		return s.base.Endpoints(ctx, service)
+	}
+	return append([]string(nil), pickGroup(ctx, groups).Endpoints...), nil
+}
+
+// pickGroup returns the group the request of ctx is assigned to.
+func pickGroup(ctx context.Context, groups []EndpointGroup) EndpointGroup {
+	var n int
+	if rid, ok := reqid.FromContext(ctx); ok {
+		n = int(uint64(rid) % 100)
+	} else {
+		n = rand.Intn(100)
+	}
+	for _, g := range groups {
+		if n < g.Weight {
+			return g
+		}
+		n -= g.Weight
+	}
+	return groups[len(groups)-1]
+}
+
+// AllEndpoints returns the endpoints of every group of service, for warming up.
+func (s *SplitEndpoints) AllEndpoints(ctx context.Context, service string) ([]string, error) {
+	groups, ok := s.splits[service]
+	if !ok {
+		if s.base == nil {
+			return nil, ErrNoEndpoints
+		}
+		return allEndpoints(ctx, s.base, service)
+	}
+	var out []string
+	for _, g := range groups {
+		out = append(out, g.Endpoints...)
+	}
+	return out, nil
+}
+
+// endpointLister is implemented by providers returning only some endpoints of
+// a service per call.
+type endpointLister interface {
+	AllEndpoints(ctx context.Context, service string) ([]string, error)
+}
+
+// allEndpoints returns every endpoint p may return for service.
+func allEndpoints(ctx context.Context, p EndpointProvider, service string) ([]string, error) {
+	if l, ok := p.(endpointLister); ok {
+		return l.AllEndpoints(ctx, service)
+	}
+	return p.Endpoints(ctx, service)
+}
@@ -0,0 +1,117 @@
+package grpctp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	reqid "github.com/hanpama/protograph/internal/reqid"
+)
+
+func TestNewSplitEndpoints_Validates(t *testing.T) {
+	for name, groups := range map[string][]EndpointGroup{
+		"weights below 100": {{Name: "stable", Endpoints: []string{"s:1"}, Weight: 90}},
+		"weights above 100": {{Name: "stable", Endpoints: []string{"s:1"}, Weight: 90}, {Name: "canary", Endpoints: []string{"c:1"}, Weight: 20}},
+		"zero weight":       {{Name: "stable", Endpoints: []string{"s:1"}, Weight: 100}, {Name: "canary", Endpoints: []string{"c:1"}}},
+		"no endpoints":      {{Name: "stable", Endpoints: []string{"s:1"}, Weight: 90}, {Name: "canary", Weight: 10}},
+	} {
+		_, err := NewSplitEndpoints(nil, map[string][]EndpointGroup{"t.A": groups})
+		require.Error(t, err, name)
+	}
+}
+
+func TestSplitEndpoints_SplitsRequestsByWeight(t *testing.T) {
+	base := NewStaticEndpoints(map[string][]string{"t.B": {"b:1"}})
+	s, err := NewSplitEndpoints(base, map[string][]EndpointGroup{"t.A": {
+		{Name: "stable", Endpoints: []string{"s:1", "s:2"}, Weight: 90},
+		{Name: "canary", Endpoints: []string{"c:1"}, Weight: 10},
+	}})
+	require.NoError(t, err)
+
+	canary := 0
+	for range 2000 {
+		ctx, rid := reqid.NewContext(context.Background())
+		want := []string{"s:1", "s:2"}
+		if rid%100 >= 90 {
+			want = []string{"c:1"}
+			canary++
+		}
+		// Every call of a request reaches the same group
+		for range 3 {
+			eps, err := s.Endpoints(ctx, "t.A")
+			require.NoError(t, err)
+			require.Equal(t, want, eps)
+		}
+	}
+	require.InDelta(t, 200, canary, 60)
+
+	// Calls without a request ID are split as well
+	canary = 0
+	for range 2000 {
+		if eps, _ := s.Endpoints(context.Background(), "t.A"); eps[0] == "c:1" {
+			canary++
+		}
+	}
+	require.InDelta(t, 200, canary, 60)
+
+	eps, err := s.Endpoints(context.Background(), "t.B")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b:1"}, eps)
+	all, err := s.AllEndpoints(context.Background(), "t.A")
+	require.NoError(t, err)
+	require.Equal(t, []string{"s:1", "s:2", "c:1"}, all)
+
+	unsplit, err := NewSplitEndpoints(nil, nil)
+	require.NoError(t, err)
+	_, err = unsplit.Endpoints(context.Background(), "t.B")
+	require.ErrorIs(t, err, ErrNoEndpoints)
+}
+
+func TestWarmUp_FillsThePoolsOfEveryGroup(t *testing.T) {
+	listeners := map[string]*bufconn.Listener{}
+	for _, ep := range []string{"stable", "canary"} {
+		lis := bufconn.Listen(1 << 20)
+		srv := grpc.NewServer()
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+		listeners[ep] = lis
+	}
+	split, err := NewSplitEndpoints(nil, map[string][]EndpointGroup{"t.A": {
+		{Name: "stable", Endpoints: []string{"stable"}, Weight: 50},
+		{Name: "canary", Endpoints: []string{"canary"}, Weight: 50},
+	}})
+	require.NoError(t, err)
+
+	for _, streams := range []int{0, 4} {
+		tr := New(
+			WithProvider(split),
+			WithMaxConnsPerEndpoint(3),
+			WithMaxStreamsPerConn(streams),
+			WithDialOptions(
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+					return listeners[addr].DialContext(ctx)
+				}),
+			),
+		)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		require.NoError(t, tr.WarmUp(ctx, "t.A"), "streams %d", streams)
+		cancel()
+		stats := tr.Stats()
+		require.Len(t, stats, 2, "streams %d", streams)
+		for _, s := range stats {
+			require.Equal(t, 3, s.IdleConns, "%s, streams %d", s.Endpoint, streams)
+		}
+		require.NoError(t, tr.Close())
+	}
+
+	// Warming up fails on unknown services
+	tr := New(WithProvider(split))
+	require.Error(t, tr.WarmUp(context.Background(), "t.B"))
+}
@@ -0,0 +1,122 @@
+package grpctp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EndpointStats reports the connection pool of an endpoint for one tenant and
+// the outcome of the calls made through it.
+type EndpointStats struct {
+	Endpoint string
+	// Tenant is empty for the pool shared by requests without a tenant.
+	Tenant string
+	// IdleConns are the pooled connections waiting for a call, out of MaxConns,
+	// and OpenConns every connection not yet closed, including those in calls.
+	IdleConns int
+	OpenConns int64
+	MaxConns  int
+	// ActiveCalls are the calls in progress, ActiveStreams the calls holding a
+	// stream on a connection and WaitingCalls those waiting for one, with
+	// Options.MaxStreamsPerConn.
+	ActiveCalls   int64
+	ActiveStreams int64
+	WaitingCalls  int64
+	// Calls counts the calls made and Errors those that failed, including
+	// failures to dial.
+	Calls  uint64
+	Errors uint64
+	// DialFailures counts the failed attempts to connect to the endpoint, and
+	// Reconnects the connections that became ready again after losing their
+	// transport or going idle.
+	DialFailures uint64
+	Reconnects   uint64
+	// ConsecutiveFailures counts the calls that failed to reach the endpoint
+	// (dial errors and UNAVAILABLE) since its last successful call.
+	ConsecutiveFailures uint64
+	// LastError is the message of the last failed call, at LastErrorAt.
+	LastError   string
+	LastErrorAt time.Time
+}
+
+// Healthy reports whether the last calls reached the endpoint. Endpoints yet to
+// be called are healthy.
+func (s EndpointStats) Healthy() bool { return s.ConsecutiveFailures == 0 }
+
+// Stats returns the stats of every connection pool, by endpoint then tenant.
+func (t *Transport) Stats() []EndpointStats {
+	t.mu.RLock()
+	out := make([]EndpointStats, 0, len(t.pools))
+	for key, p := range t.pools {
+		out = append(out, p.snapshot(key))
+	}
+	t.mu.RUnlock()
+	slices.SortFunc(out, func(a, b EndpointStats) int {
+		if c := strings.Compare(a.Endpoint, b.Endpoint); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Tenant, b.Tenant)
+	})
+	return out
+}
+
+// poolStats counts the calls and connections of a pool.
+type poolStats struct {
+	active, streams, waiting atomic.Int64
+	calls, errors            atomic.Uint64
+	consecutiveFailures      atomic.Uint64
+	open                     atomic.Int64
+	dialFailures, reconnects atomic.Uint64
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// record counts a call ending with err.
+func (s *poolStats) record(err error) {
+	s.calls.Add(1)
+	if err == nil {
+		s.consecutiveFailures.Store(0)
+		return
+	}
+	s.errors.Add(1)
+	if st, ok := status.FromError(err); !ok || st.Code() == codes.Unavailable {
+		// Errors without a status come from dialing
+		s.consecutiveFailures.Add(1)
+	} else {
+		s.consecutiveFailures.Store(0)
+	}
+	s.mu.Lock()
+	s.lastError, s.lastErrorAt = err.Error(), time.Now()
+	s.mu.Unlock()
+}
+
+func (p *connPool) snapshot(key poolKey) EndpointStats {
+	s := &p.stats
+	out := EndpointStats{
+		Endpoint:            key.endpoint,
+		Tenant:              key.tenant,
+		IdleConns:           p.idle(),
+		OpenConns:           s.open.Load(),
+		MaxConns:            p.maxConns(),
+		ActiveCalls:         s.active.Load(),
+		ActiveStreams:       s.streams.Load(),
+		WaitingCalls:        s.waiting.Load(),
+		Calls:               s.calls.Load(),
+		Errors:              s.errors.Load(),
+		DialFailures:        s.dialFailures.Load(),
+		Reconnects:          s.reconnects.Load(),
+		ConsecutiveFailures: s.consecutiveFailures.Load(),
+	}
+	s.mu.Lock()
+	out.LastError, out.LastErrorAt = s.lastError, s.lastErrorAt
+	s.mu.Unlock()
+	return out
+}
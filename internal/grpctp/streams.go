@@ -0,0 +1,124 @@
+package grpctp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// sharedConns multiplexes the calls of a pool over its connections, each
+// carrying up to Options.MaxStreamsPerConn concurrent calls, instead of lending
+// a connection to one call at a time. Calls wait for a stream once every
+// connection is at its cap and the pool has MaxConnsPerEndpoint of them.
+type sharedConns struct {
+	pool *connPool
+
+	mu     sync.Mutex
+	conns  []*sharedConn
+	closed bool
+	// released is closed, then replaced, whenever a stream is released
+	released chan struct{}
+}
+
+// sharedConn is a connection with the number of calls it carries.
+type sharedConn struct {
+	cc      *grpc.ClientConn
+	streams int
+}
+
+func newSharedConns(pool *connPool) *sharedConns {
+	return &sharedConns{pool: pool, released: make(chan struct{})}
+}
+
+// acquire returns a connection with a free stream, dialing one when the pool
+// may grow, and the func releasing the stream. Unless ConnsOnDemand is set,
+// the pool dials its connections up to the max before sharing any.
+func (s *sharedConns) acquire(ctx context.Context) (*grpc.ClientConn, func(), error) {
+	opts := s.pool.opts
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, nil, fmt.Errorf("grpctp: pool closed")
+		}
+		var best *sharedConn
+		for _, c := range s.conns {
+			if c.streams < opts.MaxStreamsPerConn && (best == nil || c.streams < best.streams) {
+				best = c
+			}
+		}
+		if len(s.conns) < s.pool.maxConns() && (best == nil || !opts.ConnsOnDemand) {
+			cc, err := s.pool.dial(ctx)
+			if err != nil {
+				s.mu.Unlock()
+				return nil, nil, err
+			}
+			best = &sharedConn{cc: cc}
+			s.conns = append(s.conns, best)
+		}
+		if best != nil {
+			best.streams++
+			s.mu.Unlock()
+			return best.cc, func() { s.release(best) }, nil
+		}
+		wait := s.released
+		s.mu.Unlock()
+		s.pool.stats.waiting.Add(1)
+		select {
+		case <-wait:
+			s.pool.stats.waiting.Add(-1)
+		case <-ctx.Done():
+			s.pool.stats.waiting.Add(-1)
+			// A status error, so waiting does not count as failing to reach the endpoint
+			return nil, nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func (s *sharedConns) release(c *sharedConn) {
+	s.mu.Lock()
+	c.streams--
+	close(s.released)
+	s.released = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// add pools a connection dialed ahead of calls; it reports false when the pool
+// is full or closed.
+func (s *sharedConns) add(cc *grpc.ClientConn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.conns) >= s.pool.maxConns() {
+		return false
+	}
+	s.conns = append(s.conns, &sharedConn{cc: cc})
+	return true
+}
+
+// counts returns the number of connections and of those without calls.
+func (s *sharedConns) counts() (conns, idle int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		if c.streams == 0 {
+			idle++
+		}
+	}
+	return len(s.conns), idle
+}
+
+// close closes every connection; calls in progress fail.
+func (s *sharedConns) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, c := range s.conns {
+		_ = c.cc.Close()
+	}
+	s.conns = nil
+	close(s.released)
+	s.released = make(chan struct{})
+}
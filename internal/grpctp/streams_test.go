@@ -0,0 +1,121 @@
+package grpctp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// newSharedPool returns a pool of up to 2 connections carrying 2 calls each.
+// Connections are never used, so dialing an unreachable endpoint succeeds.
+func newSharedPool(t *testing.T, onDemand bool) *connPool {
+	t.Helper()
+	o := defaultOptions()
+	o.MaxConnsPerEndpoint, o.MaxStreamsPerConn, o.ConnsOnDemand = 2, 2, onDemand
+	o.DialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	p := newConnPool("localhost:1", o)
+	t.Cleanup(p.close)
+	return p
+}
+
+// streamsOf returns the number of calls carried by each connection of p.
+func streamsOf(p *connPool) map[*grpc.ClientConn]int {
+	p.shared.mu.Lock()
+	defer p.shared.mu.Unlock()
+	out := map[*grpc.ClientConn]int{}
+	for _, c := range p.shared.conns {
+		out[c.cc] = c.streams
+	}
+	return out
+}
+
+func TestSharedConns_DialsUpToMaxThenShares(t *testing.T) {
+	p := newSharedPool(t, false)
+	ctx := context.Background()
+
+	var releases []func()
+	acquire := func() *grpc.ClientConn {
+		cc, release, err := p.acquire(ctx)
+		require.NoError(t, err)
+		releases = append(releases, release)
+		return cc
+	}
+	a, b := acquire(), acquire()
+	require.NotSame(t, a, b)
+	// Then the least loaded connection carries each call
+	c, d := acquire(), acquire()
+	require.NotSame(t, c, d)
+	require.Equal(t, map[*grpc.ClientConn]int{a: 2, b: 2}, streamsOf(p))
+	require.EqualValues(t, 4, p.stats.streams.Load())
+
+	// Every stream is taken: calls wait until their deadline
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err := p.acquire(short)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Zero(t, p.stats.waiting.Load())
+
+	// or until a call releases its stream
+	got := make(chan *grpc.ClientConn)
+	go func() {
+		cc, _, err := p.acquire(ctx)
+		require.NoError(t, err)
+		got <- cc
+	}()
+	require.Eventually(t, func() bool { return p.stats.waiting.Load() == 1 }, time.Second, time.Millisecond)
+	releases[1]()
+	require.Same(t, b, <-got)
+	require.Equal(t, map[*grpc.ClientConn]int{a: 2, b: 2}, streamsOf(p))
+	conns, idle := p.shared.counts()
+	require.Equal(t, 2, conns)
+	require.Zero(t, idle)
+}
+
+func TestSharedConns_DialsOnDemand(t *testing.T) {
+	p := newSharedPool(t, true)
+	ctx := context.Background()
+
+	a, releaseA, err := p.acquire(ctx)
+	require.NoError(t, err)
+	b, _, err := p.acquire(ctx)
+	require.NoError(t, err)
+	// The open connection is shared up to its cap before another is dialed
+	require.Same(t, a, b)
+	c, _, err := p.acquire(ctx)
+	require.NoError(t, err)
+	require.NotSame(t, a, c)
+	require.Equal(t, map[*grpc.ClientConn]int{a: 2, c: 1}, streamsOf(p))
+
+	// Ties go to the first connection
+	releaseA()
+	d, _, err := p.acquire(ctx)
+	require.NoError(t, err)
+	require.Same(t, a, d)
+	require.Equal(t, map[*grpc.ClientConn]int{a: 2, c: 1}, streamsOf(p))
+}
+
+func TestSharedConns_Close(t *testing.T) {
+	p := newSharedPool(t, false)
+	ctx := context.Background()
+	for range 4 {
+		_, _, err := p.acquire(ctx)
+		require.NoError(t, err)
+	}
+	// A waiting call fails once the pool closes
+	failed := make(chan error)
+	go func() {
+		_, _, err := p.acquire(ctx)
+		failed <- err
+	}()
+	require.Eventually(t, func() bool { return p.stats.waiting.Load() == 1 }, time.Second, time.Millisecond)
+	p.close()
+	require.ErrorContains(t, <-failed, "pool closed")
+	_, _, err := p.acquire(ctx)
+	require.ErrorContains(t, err, "pool closed")
+}
@@ -3,16 +3,21 @@ package grpctp
 import (
 	"context"
 	"fmt"
-	"math/rand"
+	"log"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	eventbus "github.com/hanpama/protograph/internal/eventbus"
 	events "github.com/hanpama/protograph/internal/events"
+	reqid "github.com/hanpama/protograph/internal/reqid"
+	respheader "github.com/hanpama/protograph/internal/respheader"
+	"github.com/hanpama/protograph/protographctx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
@@ -23,12 +28,14 @@ import (
 
 // Transport is a real gRPC transport with connection pooling and deadline
 // propagation. It integrates with an EndpointProvider for service discovery.
+// Each tenant (protographctx.Tenant) has its own pool per endpoint, so tenants
+// routed to a shared endpoint never share connections.
 
 type Transport struct {
 	opts *Options
 
 	mu     sync.RWMutex
-	pools  map[string]*connPool // key: endpoint
+	pools  map[poolKey]*connPool
 	closed atomic.Bool
 }
 
@@ -43,12 +50,34 @@ func New(opts ...Option) *Transport {
 			grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.DefaultConfig}),
 		}
 	}
+	if o.KeepaliveTime > 0 {
+		o.DialOptions = append(o.DialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                o.KeepaliveTime,
+			Timeout:             o.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if o.IdleTimeout > 0 {
+		o.DialOptions = append(o.DialOptions, grpc.WithIdleTimeout(o.IdleTimeout))
+	}
 	return &Transport{
 		opts:  o,
-		pools: make(map[string]*connPool),
+		pools: make(map[poolKey]*connPool),
 	}
 }
 
+// poolKey identifies the connection pool of an endpoint for one tenant.
+type poolKey struct {
+	tenant   string
+	endpoint string
+}
+
+// poolKeyOf returns the key of the pool serving endpoint for the tenant of ctx.
+func poolKeyOf(ctx context.Context, endpoint string) poolKey {
+	tenant, _ := protographctx.Tenant(ctx)
+	return poolKey{tenant: tenant, endpoint: endpoint}
+}
+
 // Ensure we satisfy grpcrt.Transport
 var _ grpcrt.Transport = (*Transport)(nil)
 
@@ -82,30 +111,92 @@ func (t *Transport) Call(ctx context.Context, method protoreflect.MethodDescript
 	if err != nil {
 		return
 	}
-	// pick one with shuffle
-	idx := rand.Intn(len(endpoints))
-	endpoint := endpoints[idx]
+	if len(endpoints) == 0 {
+		err = ErrNoEndpoints
+		return
+	}
+	endpoint, done := t.opts.balancer(service).Pick(ctx, method, request, endpoints)
+	defer done()
 
-	cc, err := t.getConn(ctx, endpoint)
+	pool := t.pool(poolKeyOf(ctx, endpoint))
+	cc, release, err := pool.acquire(ctx)
 	if err != nil {
+		pool.stats.record(err)
 		return
 	}
-	defer t.returnConn(endpoint, cc)
+	defer release()
 
 	start := time.Now()
-	eventbus.Publish(ctx, events.GRPCClientStart{Service: service, Method: string(method.Name()), Target: endpoint})
+	size := batchSize(request)
+	eventbus.Publish(ctx, events.GRPCClientStart{Service: service, Method: string(method.Name()), Target: endpoint, BatchSize: size})
+	pool.stats.active.Add(1)
 	resp, err = t.invoke(ctx, cc, mthFull, request, method)
-	eventbus.Publish(ctx, events.GRPCClientFinish{
-		Service:  service,
-		Method:   string(method.Name()),
-		Target:   endpoint,
-		Code:     status.Code(err),
-		Err:      err,
-		Duration: time.Since(start),
-	})
+	pool.stats.active.Add(-1)
+	pool.stats.record(err)
+	finish := events.GRPCClientFinish{
+		Service:   service,
+		Method:    string(method.Name()),
+		Target:    endpoint,
+		BatchSize: size,
+		Code:      status.Code(err),
+		Err:       err,
+		Duration:  time.Since(start),
+	}
+	eventbus.Publish(ctx, finish)
+	if t.opts.SlowCallThreshold > 0 && finish.Duration >= t.opts.SlowCallThreshold {
+		t.logSlowCall(ctx, finish)
+	}
 	return
 }
 
+// batchSize returns the number of entries of a batch request, or 1 for a single
+// request.
+func batchSize(request protoreflect.Message) int {
+	fd := request.Descriptor().Fields().ByName("batches")
+	if fd == nil || !fd.IsList() {
+		return 1
+	}
+	return request.Get(fd).List().Len()
+}
+
+func (t *Transport) logSlowCall(ctx context.Context, e events.GRPCClientFinish) {
+	logf := log.Printf
+	if t.opts.SlowCallLog != nil {
+		logf = t.opts.SlowCallLog.Printf
+	}
+	rid, _ := reqid.FromContext(ctx)
+	logf("slow grpc call method=/%s/%s endpoint=%s batch=%d duration=%s code=%s request_id=%d",
+		e.Service, e.Method, e.Target, e.BatchSize, e.Duration, e.Code, rid)
+}
+
+// WarmUp fills the connection pools of every endpoint of services and waits for
+// the connections to be ready, so the first calls don't pay for connection
+// setup, including every group of a SplitEndpoints. It warms the pools of the
+// tenant of ctx, if any. It returns the first error, after trying every endpoint.
+func (t *Transport) WarmUp(ctx context.Context, services ...string) error {
+	if t.opts.Provider == nil {
+		return fmt.Errorf("grpctp: provider not configured")
+	}
+	seen := map[string]bool{}
+	var first error
+	for _, service := range services {
+		endpoints, err := allEndpoints(ctx, t.opts.Provider, service)
+		if err != nil {
+			return fmt.Errorf("grpctp: endpoints of %s: %w", service, err)
+		}
+		for _, endpoint := range endpoints {
+			if seen[endpoint] {
+				continue
+			}
+			seen[endpoint] = true
+			if err := t.pool(poolKeyOf(ctx, endpoint)).warm(ctx); err != nil && first == nil {
+				first = fmt.Errorf("grpctp: warm up %s: %w", endpoint, err)
+			}
+		}
+	}
+	return first
+}
+
 func (t *Transport) Close() error {
 	if t.closed.Swap(true) {
 		return nil
@@ -115,7 +206,7 @@ func (t *Transport) Close() error {
 	for _, p := range t.pools {
 		p.close()
 	}
-	t.pools = map[string]*connPool{}
+	t.pools = map[poolKey]*connPool{}
 	return nil
 }
 
@@ -124,38 +215,135 @@ func (t *Transport) Close() error {
 type connPool struct {
 	endpoint string
 	opts     *Options
-	conns    chan *grpc.ClientConn
-	once     sync.Once
-	closed   atomic.Bool
+	// conns holds the idle connections lent to one call at a time; shared
+	// replaces it with MaxStreamsPerConn
+	conns  chan *grpc.ClientConn
+	shared *sharedConns
+	closed atomic.Bool
+	stats  poolStats
 }
 
 func newConnPool(endpoint string, opts *Options) *connPool {
-	n := opts.MaxConnsPerEndpoint
-	if n <= 0 {
-		n = 2
+	p := &connPool{endpoint: endpoint, opts: opts}
+	if opts.MaxStreamsPerConn > 0 {
+		p.shared = newSharedConns(p)
+	} else {
+		p.conns = make(chan *grpc.ClientConn, p.maxConns())
 	}
-	return &connPool{
-		endpoint: endpoint,
-		opts:     opts,
-		conns:    make(chan *grpc.ClientConn, n),
+	return p
+}
+
+func (p *connPool) maxConns() int {
+	if n := p.opts.MaxConnsPerEndpoint; n > 0 {
+		return n
 	}
+	return 2
 }
 
-func (p *connPool) get(ctx context.Context) (*grpc.ClientConn, error) {
+// acquire returns a connection for one call and the func to call when the call
+// is done.
+func (p *connPool) acquire(ctx context.Context) (*grpc.ClientConn, func(), error) {
 	if p.closed.Load() {
-		return nil, fmt.Errorf("grpctp: pool closed")
+		return nil, nil, fmt.Errorf("grpctp: pool closed")
+	}
+	var (
+		cc      *grpc.ClientConn
+		release func()
+		err     error
+	)
+	if p.shared != nil {
+		cc, release, err = p.shared.acquire(ctx)
+	} else {
+		cc, err = p.get(ctx)
+		release = func() { p.put(cc) }
+	}
+	if err != nil {
+		return nil, nil, err
 	}
+	p.stats.streams.Add(1)
+	return cc, func() {
+		p.stats.streams.Add(-1)
+		release()
+	}, nil
+}
+
+func (p *connPool) get(ctx context.Context) (*grpc.ClientConn, error) {
 	select {
 	case cc := <-p.conns:
 		return cc, nil
 	default:
 		// create new
-		cc, err := grpc.DialContext(ctx, p.endpoint, p.opts.DialOptions...)
+		return p.dial(ctx)
+	}
+}
+
+// dial opens a connection to the endpoint, counting its failures and
+// reconnects in the stats of the pool.
+func (p *connPool) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	cc, err := grpc.DialContext(ctx, p.endpoint, p.opts.DialOptions...)
+	if err != nil {
+		p.stats.dialFailures.Add(1)
+		return nil, err
+	}
+	p.stats.open.Add(1)
+	go p.watch(cc)
+	return cc, nil
+}
+
+// watch follows the state of cc until it is closed: failing to connect counts
+// as a dial failure, and becoming ready again as a reconnect.
+func (p *connPool) watch(cc *grpc.ClientConn) {
+	ready := false
+	for state := cc.GetState(); state != connectivity.Shutdown; state = cc.GetState() {
+		switch state {
+		case connectivity.Ready:
+			if ready {
+				p.stats.reconnects.Add(1)
+			}
+			ready = true
+		case connectivity.TransientFailure:
+			p.stats.dialFailures.Add(1)
+		}
+		cc.WaitForStateChange(context.Background(), state)
+	}
+	p.stats.open.Add(-1)
+}
+
+// warm dials connections until the pool is full and waits for them to be ready.
+func (p *connPool) warm(ctx context.Context) error {
+	for p.idle() < p.maxConns() {
+		if p.closed.Load() {
+			return fmt.Errorf("grpctp: pool closed")
+		}
+		cc, err := p.dial(ctx)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		return cc, nil
+		cc.Connect()
+		for state := cc.GetState(); state != connectivity.Ready; state = cc.GetState() {
+			if !cc.WaitForStateChange(ctx, state) {
+				_ = cc.Close()
+				return ctx.Err()
+			}
+		}
+		if p.shared == nil {
+			p.put(cc)
+		} else if !p.shared.add(cc) {
+			// Calls filled the pool meanwhile
+			_ = cc.Close()
+			return nil
+		}
+	}
+	return nil
+}
+
+// idle returns the number of pooled connections without calls.
+func (p *connPool) idle() int {
+	if p.shared != nil {
+		_, idle := p.shared.counts()
+		return idle
 	}
+	return len(p.conns)
 }
 
 func (p *connPool) put(cc *grpc.ClientConn) {
@@ -176,44 +364,50 @@ func (p *connPool) close() {
 	if p.closed.Swap(true) {
 		return
 	}
+	if p.shared != nil {
+		p.shared.close()
+		return
+	}
 	close(p.conns)
 	for cc := range p.conns {
 		_ = cc.Close()
 	}
 }
 
-func (t *Transport) getConn(ctx context.Context, endpoint string) (*grpc.ClientConn, error) {
+// pool returns the connection pool of key, creating it on first use.
+func (t *Transport) pool(key poolKey) *connPool {
 	t.mu.RLock()
-	pool := t.pools[endpoint]
+	pool := t.pools[key]
 	t.mu.RUnlock()
 	if pool == nil {
 		t.mu.Lock()
-		pool = t.pools[endpoint]
+		pool = t.pools[key]
 		if pool == nil {
-			pool = newConnPool(endpoint, t.opts)
-			t.pools[endpoint] = pool
+			pool = newConnPool(key.endpoint, t.opts)
+			t.pools[key] = pool
 		}
 		t.mu.Unlock()
 	}
-	return pool.get(ctx)
-}
-
-func (t *Transport) returnConn(endpoint string, cc *grpc.ClientConn) {
-	t.mu.RLock()
-	pool := t.pools[endpoint]
-	t.mu.RUnlock()
-	if pool != nil {
-		pool.put(cc)
-		return
-	}
-	_ = cc.Close()
+	return pool
 }
 
 func (t *Transport) invoke(ctx context.Context, cc *grpc.ClientConn, fullMethod string, req protoreflect.Message, md protoreflect.MethodDescriptor) (protoreflect.Message, error) {
 	// Use dynamicpb to construct response
 	resp := dynamicpb.NewMessage(md.Output())
 	// We can use the low-level ClientConn.Invoke
-	if err := cc.Invoke(ctx, fullMethod, req, resp); err != nil {
+	c, collect := respheader.FromContext(ctx)
+	if !collect {
+		if err := cc.Invoke(ctx, fullMethod, req, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	// Response metadata is surfaced even when the call fails
+	var header, trailer metadata.MD
+	err := cc.Invoke(ctx, fullMethod, req, resp, grpc.Header(&header), grpc.Trailer(&trailer))
+	c.Add(header)
+	c.Add(trailer)
+	if err != nil {
 		return nil, err
 	}
 	return resp, nil
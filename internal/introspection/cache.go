@@ -0,0 +1,98 @@
+package introspection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	cache "github.com/hanpama/protograph/internal/cache"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// DefaultResponseCacheSize is the number of introspection responses Wrap keeps.
+const DefaultResponseCacheSize = 100
+
+// responseTTL is long as responses only change with the schema, which is part
+// of their key; expiry merely recomputes unused entries.
+const responseTTL = 24 * time.Hour
+
+// ResponseCache keeps the JSON responses of introspection-only operations. Their
+// responses depend on nothing but the schema, so an operation sent again with the
+// same variables is answered with the response encoded the first time, instead of
+// resolving every type and nested ofType chain again.
+type ResponseCache struct {
+	version string
+	store   *cache.Memory
+}
+
+// NewResponseCache returns a cache of at most capacity responses for sch.
+func NewResponseCache(sch *schema.Schema, capacity int) *ResponseCache {
+	sum := sha256.Sum256([]byte(schema.Render(sch)))
+	return &ResponseCache{version: hex.EncodeToString(sum[:8]), store: cache.NewMemory(capacity)}
+}
+
+// Version identifies the schema the responses were computed for.
+func (c *ResponseCache) Version() string { return c.version }
+
+// Key returns the cache key of an operation, or false when it selects anything
+// but __schema, __type and __typename at its root, or is not a query.
+func (c *ResponseCache) Key(doc *language.QueryDocument, query, operationName string, variables map[string]any) (string, bool) {
+	op := doc.Operations.ForName(operationName)
+	if op == nil && operationName == "" && len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	if op == nil || op.Operation != language.Query || !introspectionOnly(doc, op.SelectionSet, map[string]bool{}) {
+		return "", false
+	}
+	vars, err := json.Marshal(variables)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.New()
+	for _, part := range []string{query, operationName, string(vars)} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return c.version + ":" + hex.EncodeToString(sum.Sum(nil)), true
+}
+
+// Get returns the response stored under key.
+func (c *ResponseCache) Get(key string) (json.RawMessage, bool) {
+	v, ok := c.store.Get(context.Background(), key)
+	return v, ok
+}
+
+// Set stores the encoded response of the operation with key.
+func (c *ResponseCache) Set(key string, response json.RawMessage) {
+	c.store.Set(context.Background(), key, response, responseTTL)
+}
+
+// introspectionOnly reports whether a root selection set only selects
+// introspection fields, through fragments too.
+func introspectionOnly(doc *language.QueryDocument, set language.SelectionSet, visited map[string]bool) bool {
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *language.Field:
+			if sel.Name != "__schema" && sel.Name != "__type" && sel.Name != "__typename" {
+				return false
+			}
+		case *language.InlineFragment:
+			if !introspectionOnly(doc, sel.SelectionSet, visited) {
+				return false
+			}
+		case *language.FragmentSpread:
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			def := doc.Fragments.ForName(sel.Name)
+			if def == nil || !introspectionOnly(doc, def.SelectionSet, visited) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package introspection
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Option configures the runtime returned by Wrap.
+type Option func(*runtime)
+
+// WithMaxDepth bounds the __Type values nested along one path of a response,
+// such as types { fields { type { ofType } } }, to n. Fields returning a type
+// deeper than that fail, while wrapper levels a type doesn't have resolve to
+// null as usual and don't count. 0 is unlimited.
+func WithMaxDepth(n int) Option {
+	return func(r *runtime) { r.maxDepth = n }
+}
+
+// WithDisabledFields makes the named introspection fields fail instead of
+// resolving, for policies that allow partial introspection only. Fields are
+// named by coordinate, e.g. "__Type.fields", or "__schema" and "__type" for
+// the root fields. __typename stays enabled.
+func WithDisabledFields(coordinates ...string) Option {
+	return func(r *runtime) {
+		if r.disabled == nil {
+			r.disabled = map[string]bool{}
+		}
+		for _, c := range coordinates {
+			r.disabled[c] = true
+		}
+	}
+}
+
+// coordinate names an introspection field the way WithDisabledFields does.
+func (r *runtime) coordinate(objectType, field string) string {
+	if objectType == r.schema.QueryType {
+		return field
+	}
+	return objectType + "." + field
+}
+
+// checkDisabled fails when a disabled coordinate names no introspection field.
+func (r *runtime) checkDisabled() error {
+	for c := range r.disabled {
+		typeName, field, ok := strings.Cut(c, ".")
+		if !ok && (c == "__schema" || c == "__type") {
+			continue
+		}
+		if t := r.schema.Types[typeName]; ok && strings.HasPrefix(typeName, "__") && t != nil && t.Field(field) != nil {
+			continue
+		}
+		names := []string{"__schema", "__type"}
+		for name, t := range r.schema.Types {
+			if strings.HasPrefix(name, "__") {
+				for field := range t.Fields {
+					names = append(names, name+"."+field)
+				}
+			}
+		}
+		slices.Sort(names)
+		return fmt.Errorf("introspection: cannot disable unknown field %q (want one of %s)", c, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// node is an introspection value with the number of __Type values above it.
+type node struct {
+	value any
+	depth int
+}
+
+// track wraps the introspection objects of v, read at depth, into nodes. Types
+// are one level deeper than their parent and fail past the limit.
+func (r *runtime) track(v any, depth int) (any, error) {
+	switch v := v.(type) {
+	case *schema.Type:
+		if v == nil {
+			return nil, nil
+		}
+		return r.nestType(v, depth)
+	case *schema.TypeRef:
+		if v == nil {
+			return nil, nil
+		}
+		return r.nestType(v, depth)
+	case []*schema.Type:
+		if v == nil {
+			return nil, nil
+		}
+		out := make([]any, len(v))
+		for i, t := range v {
+			n, err := r.nestType(t, depth)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return out, nil
+	case *schema.Schema, *schema.Field, *schema.InputValue, *schema.EnumValue, *schema.Directive:
+		return node{value: v, depth: depth}, nil
+	case []*schema.Field:
+		return nodes(v, depth), nil
+	case []*schema.InputValue:
+		return nodes(v, depth), nil
+	case []*schema.EnumValue:
+		return nodes(v, depth), nil
+	case []*schema.Directive:
+		return nodes(v, depth), nil
+	}
+	return v, nil
+}
+
+func (r *runtime) nestType(t any, depth int) (any, error) {
+	if depth+1 > r.maxDepth {
+		return nil, fmt.Errorf("introspection depth limit exceeded: more than %d nested types", r.maxDepth)
+	}
+	return node{value: t, depth: depth + 1}, nil
+}
+
+// nodes wraps values into nodes at depth, keeping nil lists null.
+func nodes[T any](values []T, depth int) []any {
+	if values == nil {
+		return nil
+	}
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = node{value: v, depth: depth}
+	}
+	return out
+}
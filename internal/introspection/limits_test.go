@@ -0,0 +1,78 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+func executeIntrospection(t *testing.T, sch *schema.Schema, query string, opts ...Option) *executor.ExecutionResult {
+	t.Helper()
+	wrapper, err := Wrap(noopRuntime{}, sch, opts...)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	doc, err := language.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return executor.NewExecutor(wrapper.Runtime, wrapper.Schema).ExecuteRequest(context.Background(), doc, "", nil, nil)
+}
+
+func TestMaxDepth(t *testing.T) {
+	// Query.hello nests the most types: __schema.types, then [String!]! down to String
+	sch, err := schema.BuildFromSDL(`type Query { hello: [String!]! }`)
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+
+	full := executeIntrospection(t, sch, Query)
+	limited := executeIntrospection(t, sch, Query, WithMaxDepth(5))
+	if len(full.Errors) > 0 || len(limited.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v %v", full.Errors, limited.Errors)
+	}
+	want, _ := json.Marshal(full.Data)
+	got, _ := json.Marshal(limited.Data)
+	if string(got) != string(want) {
+		t.Fatalf("limited introspection differs within the limit:\n%s\n%s", got, want)
+	}
+
+	res := executeIntrospection(t, sch, Query, WithMaxDepth(4))
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "more than 4 nested types") {
+		t.Fatalf("expected a depth error, got %v", res.Errors)
+	}
+
+	// Cycles through fields and their types are cut at the limit
+	res = executeIntrospection(t, sch, `{ __type(name: "Query") { fields { type { fields { name } } } } }`, WithMaxDepth(1))
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "more than 1 nested types") {
+		t.Fatalf("expected a depth error, got %v", res.Errors)
+	}
+}
+
+func TestDisabledFields(t *testing.T) {
+	sch := buildSchema(t)
+	res := executeIntrospection(t, sch, `{ __typename __type(name: "Query") { name fields { name } } }`, WithDisabledFields("__Type.fields"))
+	if len(res.Errors) != 1 || res.Errors[0].Message != "introspection field __Type.fields is disabled" {
+		t.Fatalf("expected the disabled field error, got %v", res.Errors)
+	}
+	data := res.Data.(map[string]any)
+	if data["__typename"] != "Query" || data["__type"].(map[string]any)["name"] != "Query" {
+		t.Fatalf("unexpected data: %v", data)
+	}
+
+	res = executeIntrospection(t, sch, `{ __typename __schema { description } }`, WithDisabledFields("__schema"))
+	if len(res.Errors) != 1 || res.Errors[0].Message != "introspection field __schema is disabled" {
+		t.Fatalf("expected the disabled field error, got %v", res.Errors)
+	}
+
+	for _, c := range []string{"__Type.nope", "Query.hello", "__typename"} {
+		if _, err := Wrap(noopRuntime{}, sch, WithDisabledFields(c)); err == nil {
+			t.Fatalf("expected an error disabling %s", c)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package introspection
+
+import (
+	"context"
+	"fmt"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// Query is the full introspection query sent by GraphQL tooling such as
+// graphql-codegen and IDE plugins, including descriptions, specifiedByURL,
+// repeatable directives, oneOf inputs and deprecated input values.
+const Query = `query IntrospectionQuery {
+  __schema {
+    description
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      isRepeatable
+      locations
+      args(includeDeprecated: true) { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  specifiedByURL
+  isOneOf
+  fields(includeDeprecated: true) {
+    name
+    description
+    args(includeDeprecated: true) { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields(includeDeprecated: true) { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+  isDeprecated
+  deprecationReason
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// Introspect runs Query against sch without a backend and returns the result in
+// the shape clients receive from the gateway.
+func Introspect(ctx context.Context, sch *schema.Schema) (*executor.ExecutionResult, error) {
+	doc, err := language.ParseQuery(Query)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := Wrap(offlineRuntime{}, sch)
+	if err != nil {
+		return nil, err
+	}
+	res := executor.NewExecutor(wrapper.Runtime, wrapper.Schema).ExecuteRequest(ctx, doc, "", nil, nil)
+	if len(res.Errors) > 0 {
+		return nil, fmt.Errorf("introspection: %s", res.Errors[0].Message)
+	}
+	return res, nil
+}
+
+// offlineRuntime backs Introspect, where only introspection fields are selected.
+type offlineRuntime struct{}
+
+func (offlineRuntime) ResolveSync(_ context.Context, objectType, field string, _ any, _ map[string]any) (any, error) {
+	return nil, fmt.Errorf("%s.%s cannot be resolved without a backend", objectType, field)
+}
+
+func (offlineRuntime) BatchResolveAsync(_ context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	results := make([]executor.AsyncResolveResult, len(tasks))
+	for i := range results {
+		results[i].Error = fmt.Errorf("async fields cannot be resolved without a backend")
+	}
+	return results
+}
+
+func (offlineRuntime) ResolveType(_ context.Context, abstractType string, _ any) (string, error) {
+	return "", fmt.Errorf("cannot resolve %s without a backend", abstractType)
+}
+
+func (offlineRuntime) ResolveUnionConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (offlineRuntime) ResolveInterfaceConcreteValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
+
+func (offlineRuntime) SerializeLeafValue(_ context.Context, _ string, value any) (any, error) {
+	return value, nil
+}
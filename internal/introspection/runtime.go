@@ -2,8 +2,10 @@ package introspection
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	executor "github.com/hanpama/protograph/internal/executor"
 	schema "github.com/hanpama/protograph/internal/schema"
@@ -13,77 +15,184 @@ import (
 type IntrospectionWrapper struct {
 	Runtime executor.Runtime
 	Schema  *schema.Schema
+	// Responses caches the responses of introspection-only operations; see
+	// server.WithResponseCache.
+	Responses *ResponseCache
 }
 
 // Wrap returns a Runtime that handles GraphQL introspection fields.
-// It extends the schema with introspection types and fields.
-func Wrap(base executor.Runtime, sch *schema.Schema) *IntrospectionWrapper {
-	// Create a copy of the schema to avoid modifying the original
-	extendedSchema := extendSchemaWithIntrospection(sch)
+// It extends the schema with introspection types and fields, and fails if the
+// extended schema does not validate or an option names an unknown field.
+func Wrap(base executor.Runtime, sch *schema.Schema, opts ...Option) (*IntrospectionWrapper, error) {
+	// Work on frozen deep copies so the caller's schema is never modified and
+	// neither copy can be mutated while requests are served.
+	original := sch.Clone().Freeze()
+	extendedSchema, err := extendSchemaWithIntrospection(original)
+	if err != nil {
+		return nil, fmt.Errorf("introspection schema:\n%w", err)
+	}
 	runtime := &runtime{
 		base:           base,
 		schema:         extendedSchema,
-		originalSchema: sch,
+		originalSchema: original,
 	}
-	return &IntrospectionWrapper{
-		Runtime: runtime,
-		Schema:  extendedSchema,
+	for _, o := range opts {
+		o(runtime)
+	}
+	if err := runtime.checkDisabled(); err != nil {
+		return nil, err
 	}
+	return &IntrospectionWrapper{
+		Runtime:   runtime,
+		Schema:    extendedSchema,
+		Responses: NewResponseCache(original, DefaultResponseCacheSize),
+	}, nil
 }
 
 type runtime struct {
 	base           executor.Runtime
 	schema         *schema.Schema // Extended schema with introspection types
 	originalSchema *schema.Schema // Original schema for introspection queries
+
+	// maxDepth bounds the __Type values nested along one path; 0 is unlimited
+	maxDepth int
+	// disabled holds the introspection fields failing instead of resolving
+	disabled map[string]bool
 }
 
+// ResolveSync answers introspection fields and forwards everything else to the
+// base runtime the way the executor would: fields the schema marks async go
+// through BatchResolveAsync, as gRPC runtimes only resolve those in batches.
 func (r *runtime) ResolveSync(ctx context.Context, objectType, field string, source any, args map[string]any) (any, error) {
+	if v, ok, err := r.resolveIntrospection(objectType, field, source, args); ok {
+		return v, err
+	}
+	if t := r.schema.Types[objectType]; t != nil && t.Field(field) != nil && t.Field(field).Async {
+		task := executor.AsyncResolveTask{ObjectType: objectType, Field: field, Source: source, Args: args}
+		results := r.base.BatchResolveAsync(ctx, []executor.AsyncResolveTask{task})
+		if len(results) != 1 {
+			return nil, fmt.Errorf("%s.%s: runtime returned %d results for 1 task", objectType, field, len(results))
+		}
+		return results[0].Value, results[0].Error
+	}
+	return r.base.ResolveSync(ctx, objectType, field, source, args)
+}
+
+// BatchResolveAsync forwards tasks to the base runtime. Introspection fields,
+// which the base runtime does not know, are answered here if a schema marks them
+// async.
+func (r *runtime) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	var local []int
+	for i, t := range tasks {
+		if r.isIntrospectionField(t.ObjectType, t.Field) {
+			local = append(local, i)
+		}
+	}
+	if len(local) == 0 {
+		return r.base.BatchResolveAsync(ctx, tasks)
+	}
+	results := make([]executor.AsyncResolveResult, len(tasks))
+	forwarded := make([]executor.AsyncResolveTask, 0, len(tasks)-len(local))
+	indexes := make([]int, 0, len(tasks)-len(local))
+	for i, t := range tasks {
+		if len(local) > 0 && local[0] == i {
+			local = local[1:]
+			results[i].Value, _, results[i].Error = r.resolveIntrospection(t.ObjectType, t.Field, t.Source, t.Args)
+			continue
+		}
+		forwarded = append(forwarded, t)
+		indexes = append(indexes, i)
+	}
+	if len(forwarded) > 0 {
+		for j, res := range r.base.BatchResolveAsync(ctx, forwarded) {
+			if j < len(indexes) {
+				results[indexes[j]] = res
+			}
+		}
+	}
+	return results
+}
+
+// isIntrospectionField reports whether objectType.field is answered by this
+// wrapper rather than the base runtime.
+func (r *runtime) isIntrospectionField(objectType, field string) bool {
+	if strings.HasPrefix(objectType, "__") {
+		return true
+	}
+	return objectType == r.schema.QueryType && (field == "__schema" || field == "__type")
+}
 
+// resolveIntrospection resolves fields of introspection types and the
+// introspection root fields, failing disabled ones and those nesting types
+// deeper than the limit.
+func (r *runtime) resolveIntrospection(objectType, field string, source any, args map[string]any) (any, bool, error) {
+	depth := 0
+	if n, ok := source.(node); ok {
+		source, depth = n.value, n.depth
+	}
+	v, ok := r.resolveIntrospectionValue(objectType, field, source, args)
+	if !ok {
+		return nil, false, nil
+	}
+	if coordinate := r.coordinate(objectType, field); r.disabled[coordinate] {
+		return nil, true, fmt.Errorf("introspection field %s is disabled", coordinate)
+	}
+	if r.maxDepth == 0 {
+		return v, true, nil
+	}
+	v, err := r.track(v, depth)
+	return v, true, err
+}
+
+// resolveIntrospectionValue resolves fields of introspection types and the
+// introspection root fields.
+func (r *runtime) resolveIntrospectionValue(objectType, field string, source any, args map[string]any) (any, bool) {
 	switch src := source.(type) {
 	case *schema.Schema:
-		if v, ok := resolveSchemaField(src, field); ok {
-			return v, nil
-		}
+		return resolveSchemaField(src, field)
 	case *schema.Type:
-		if v, ok := resolveTypeField(r.originalSchema, src, field, args); ok {
-			return v, nil
-		}
+		return resolveTypeField(r.originalSchema, src, field, args)
 	case *schema.TypeRef:
-		if v, ok := resolveTypeRefField(r.originalSchema, src, field, args); ok {
-			return v, nil
-		}
+		return resolveTypeRefField(r.originalSchema, src, field, args)
 	case *schema.Field:
-		if v, ok := resolveFieldField(src, field, args); ok {
-			return v, nil
-		}
+		return resolveFieldField(src, field, args)
 	case *schema.InputValue:
-		if v, ok := resolveInputValueField(src, field); ok {
-			return v, nil
-		}
+		return resolveInputValueField(r.originalSchema, src, field)
 	case *schema.EnumValue:
-		if v, ok := resolveEnumValueField(src, field); ok {
-			return v, nil
-		}
+		return resolveEnumValueField(src, field)
 	case *schema.Directive:
-		if v, ok := resolveDirectiveField(src, field, args); ok {
-			return v, nil
-		}
+		return resolveDirectiveField(src, field, args)
 	}
 
-	if objectType == "Query" {
+	if objectType == r.schema.QueryType {
 		switch field {
 		case "__schema":
-			return r.originalSchema, nil
+			return r.originalSchema, true
 		case "__type":
-			return r.resolveTypeQuery(args), nil
+			return r.resolveTypeQuery(args), true
 		}
 	}
+	return nil, false
+}
 
-	return r.base.ResolveSync(ctx, objectType, field, source, args)
+// DescribeMethod forwards to the wrapped runtime so that explain output keeps the
+// backend methods.
+func (r *runtime) DescribeMethod(objectType, field string) (string, bool, bool) {
+	if d, ok := r.base.(executor.MethodDescriber); ok {
+		return d.DescribeMethod(objectType, field)
+	}
+	return "", false, false
 }
 
-func (r *runtime) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
-	return r.base.BatchResolveAsync(ctx, tasks)
+// CompleteLeafObject forwards to the wrapped runtime for non-introspection types.
+func (r *runtime) CompleteLeafObject(ctx context.Context, objectType string, source any, fields []executor.LeafField) (json.RawMessage, bool) {
+	if strings.HasPrefix(objectType, "__") {
+		return nil, false
+	}
+	if c, ok := r.base.(executor.LeafObjectCompleter); ok {
+		return c.CompleteLeafObject(ctx, objectType, source, fields)
+	}
+	return nil, false
 }
 
 func (r *runtime) ResolveType(ctx context.Context, abstractType string, value any) (string, error) {
@@ -232,9 +341,9 @@ func resolveFieldDeprecationReason(f *schema.Field) *string {
 	return nil
 }
 
-func resolveInputValueDefaultValue(a *schema.InputValue) *string {
+func resolveInputValueDefaultValue(sch *schema.Schema, a *schema.InputValue) *string {
 	if a.DefaultValue != nil {
-		value := fmt.Sprintf("%v", a.DefaultValue)
+		value := schema.RenderValue(sch, a.Type, a.DefaultValue)
 		return &value
 	}
 	return nil
@@ -326,6 +435,12 @@ func resolveTypeField(sch *schema.Schema, t *schema.Type, field string, args map
 func resolveTypeRefField(sch *schema.Schema, tr *schema.TypeRef, field string, args map[string]any) (any, bool) {
 	switch field {
 	case "kind":
+		if tr.Kind == schema.TypeRefKindNamed {
+			// Named references report the kind of the type they name
+			if def := sch.Types[tr.Named]; def != nil {
+				return def.Kind, true
+			}
+		}
 		return tr.Kind, true
 	case "name":
 		if schema.IsNonNull(tr) || schema.IsList(tr) {
@@ -365,7 +480,7 @@ func resolveFieldField(f *schema.Field, field string, args map[string]any) (any,
 	return nil, false
 }
 
-func resolveInputValueField(a *schema.InputValue, field string) (any, bool) {
+func resolveInputValueField(sch *schema.Schema, a *schema.InputValue, field string) (any, bool) {
 	switch field {
 	case "name":
 		return a.Name, true
@@ -374,7 +489,7 @@ func resolveInputValueField(a *schema.InputValue, field string) (any, bool) {
 	case "type":
 		return a.Type, true
 	case "defaultValue":
-		return resolveInputValueDefaultValue(a), true
+		return resolveInputValueDefaultValue(sch, a), true
 	case "isDeprecated":
 		return a.IsDeprecated, true
 	case "deprecationReason":
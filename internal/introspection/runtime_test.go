@@ -2,6 +2,7 @@ package introspection
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	executor "github.com/hanpama/protograph/internal/executor"
@@ -49,7 +50,10 @@ func buildSchema(t *testing.T) *schema.Schema {
 func TestIntrospectionEnabled(t *testing.T) {
 	sch := buildSchema(t)
 	// Wrap with introspection enabled
-	wrapper := Wrap(noopRuntime{}, sch)
+	wrapper, err := Wrap(noopRuntime{}, sch)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
 	exec := executor.NewExecutor(wrapper.Runtime, wrapper.Schema)
 	doc, err := language.ParseQuery("{__schema{queryType{name}}}")
 	if err != nil {
@@ -67,6 +71,26 @@ func TestIntrospectionEnabled(t *testing.T) {
 	}
 }
 
+func TestWrapCopiesSchema(t *testing.T) {
+	sch := buildSchema(t).Clone()
+	wrapper, err := Wrap(noopRuntime{}, sch)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+
+	if sch.GetQueryType().Field("__schema") != nil || sch.Types["__Type"] != nil {
+		t.Fatalf("caller schema was extended with introspection definitions")
+	}
+	if wrapper.Schema.GetQueryType().Field("hello") == sch.GetQueryType().Field("hello") {
+		t.Fatalf("wrapped schema shares field definitions with the caller schema")
+	}
+	// The caller's schema stays modifiable
+	sch.GetQueryType().Field("hello").Deprecate("use greeting")
+	if wrapper.Schema.GetQueryType().Field("hello").IsDeprecated {
+		t.Fatalf("mutation of the caller schema leaked into the wrapped schema")
+	}
+}
+
 func TestTypenameField(t *testing.T) {
 	sch := buildSchema(t)
 	// __typename should work without introspection wrapper
@@ -85,3 +109,170 @@ func TestTypenameField(t *testing.T) {
 		t.Fatalf("expected __typename to be Query, got %v", data["__typename"])
 	}
 }
+
+func TestIntrospect(t *testing.T) {
+	sch, err := schema.BuildFromSDL(`
+type Query {
+  "Greets someone."
+  hello(name: String = "world"): [String!] @deprecated(reason: "use greet")
+}`)
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	res, err := Introspect(context.Background(), sch)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	// Decode the JSON document as tooling would
+	raw, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var doc struct {
+		Data struct {
+			Schema map[string]any `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	s := doc.Data.Schema
+	if s["queryType"].(map[string]any)["name"] != "Query" {
+		t.Fatalf("queryType = %v", s["queryType"])
+	}
+	var query map[string]any
+	for _, typ := range s["types"].([]any) {
+		if typ.(map[string]any)["name"] == "Query" {
+			query = typ.(map[string]any)
+		}
+	}
+	if query == nil {
+		t.Fatalf("Query type missing from %v", s["types"])
+	}
+	hello := query["fields"].([]any)[0].(map[string]any)
+	if hello["description"] != "Greets someone." || hello["deprecationReason"] != "use greet" {
+		t.Fatalf("hello = %v", hello)
+	}
+	arg := hello["args"].([]any)[0].(map[string]any)
+	if arg["defaultValue"] != `"world"` {
+		t.Fatalf("defaultValue = %v", arg["defaultValue"])
+	}
+	typ := hello["type"].(map[string]any)
+	if typ["kind"] != "LIST" || typ["ofType"].(map[string]any)["kind"] != "NON_NULL" {
+		t.Fatalf("type = %v", typ)
+	}
+	// Named references carry the kind of the named type
+	if named := typ["ofType"].(map[string]any)["ofType"].(map[string]any); named["kind"] != "SCALAR" || named["name"] != "String" {
+		t.Fatalf("named type = %v", named)
+	}
+}
+
+// asyncRootRuntime resolves async fields in batches and, like the gRPC runtime,
+// refuses to resolve them synchronously.
+type asyncRootRuntime struct {
+	noopRuntime
+	batches [][]executor.AsyncResolveTask
+}
+
+func (r *asyncRootRuntime) ResolveSync(_ context.Context, objectType, field string, _ any, _ map[string]any) (any, error) {
+	panic("sync resolution of " + objectType + "." + field)
+}
+
+func (r *asyncRootRuntime) BatchResolveAsync(_ context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	r.batches = append(r.batches, tasks)
+	results := make([]executor.AsyncResolveResult, len(tasks))
+	for i, t := range tasks {
+		results[i].Value = "async " + t.Field
+	}
+	return results
+}
+
+func TestAsyncRootFieldsWithIntrospection(t *testing.T) {
+	sch := buildSchema(t).Clone()
+	sch.GetQueryType().Field("hello").SetAsync(true)
+	base := &asyncRootRuntime{}
+	wrapper, err := Wrap(base, sch)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	exec := executor.NewExecutor(wrapper.Runtime, wrapper.Schema)
+	doc, err := language.ParseQuery(`{ hello __typename __type(name: "Query") { name } }`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	res := exec.ExecuteRequest(context.Background(), doc, "", nil, nil)
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	data := res.Data.(map[string]any)
+	if data["hello"] != "async hello" || data["__type"].(map[string]any)["name"] != "Query" {
+		t.Fatalf("data = %v", data)
+	}
+
+	// Direct sync resolution of an async field goes through a batch of one
+	v, err := wrapper.Runtime.ResolveSync(context.Background(), "Query", "hello", nil, nil)
+	if err != nil || v != "async hello" {
+		t.Fatalf("ResolveSync = %v, %v", v, err)
+	}
+
+	// Introspection tasks in a batch are answered by the wrapper
+	base.batches = nil
+	results := wrapper.Runtime.BatchResolveAsync(context.Background(), []executor.AsyncResolveTask{
+		{ObjectType: "Query", Field: "__schema"},
+		{ObjectType: "Query", Field: "hello"},
+	})
+	if got, ok := results[0].Value.(*schema.Schema); !ok || got.QueryType != sch.QueryType || results[1].Value != "async hello" {
+		t.Fatalf("results = %v", results)
+	}
+	if len(base.batches) != 1 || len(base.batches[0]) != 1 || base.batches[0][0].Field != "hello" {
+		t.Fatalf("forwarded batches = %v", base.batches)
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	sch := buildSchema(t)
+	wrapper, err := Wrap(noopRuntime{}, sch)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	c := wrapper.Responses
+	key := func(query, operationName string, variables map[string]any) (string, bool) {
+		doc, err := language.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		return c.Key(doc, query, operationName, variables)
+	}
+
+	full, ok := key(Query, "", nil)
+	if !ok {
+		t.Fatalf("standard introspection query is not cacheable")
+	}
+	if again, _ := key(Query, "", nil); again != full {
+		t.Fatalf("keys differ for the same operation: %s, %s", full, again)
+	}
+	if withVars, _ := key(Query, "", map[string]any{"x": 1}); withVars == full {
+		t.Fatalf("variables are not part of the key")
+	}
+	if _, ok := key(`{ ...F __typename } fragment F on Query { __type(name: "Query") { name } }`, "", nil); !ok {
+		t.Fatalf("introspection through a fragment is not cacheable")
+	}
+	for _, query := range []string{`{ __schema { types { name } } hello }`, `{ ...F } fragment F on Query { hello }`} {
+		if _, ok := key(query, "", nil); ok {
+			t.Fatalf("%s: operation selecting data is cacheable", query)
+		}
+	}
+
+	// Responses of another schema are kept apart
+	otherSchema, err := schema.BuildFromSDL(`type Query { hello: String greet: String }`)
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	other, err := Wrap(noopRuntime{}, otherSchema)
+	if err != nil {
+		t.Fatalf("wrap: %v", err)
+	}
+	if other.Responses.Version() == c.Version() {
+		t.Fatalf("schemas share version %s", c.Version())
+	}
+}
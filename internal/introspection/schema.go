@@ -4,60 +4,56 @@ import (
 	schema "github.com/hanpama/protograph/internal/schema"
 )
 
-// extendSchemaWithIntrospection creates a copy of the schema and adds introspection types and fields
-func extendSchemaWithIntrospection(original *schema.Schema) *schema.Schema {
-	extended := schema.NewSchema(original.Description).
+// extendSchemaWithIntrospection builds a frozen copy of the schema with the
+// introspection types and the __schema and __type root fields added.
+func extendSchemaWithIntrospection(original *schema.Schema) (*schema.Schema, error) {
+	b := schema.NewSchemaBuilder(original.Description).
 		SetQueryType(original.QueryType).
 		SetMutationType(original.MutationType).
-		SetSubscriptionType(original.SubscriptionType)
+		SetSubscriptionType(original.SubscriptionType).
+		AllowReservedNames()
 
-	// Share existing directives snapshot (immutable in practice)
-	extended.Directives = original.Directives
-
-	for _, typ := range original.Types {
-		extended.AddType(typ)
-	}
-
-	addIntrospectionTypes(extended)
-
-	if queryType := extended.GetQueryType(); queryType != nil {
-		queryTypeCopy := schema.NewType(queryType.Name, queryType.Kind, queryType.Description)
-		for _, iface := range queryType.Interfaces {
-			queryTypeCopy.AddInterface(iface)
-		}
-		for _, field := range queryType.GetOrderedFields() {
-			queryTypeCopy.AddField(cloneField(field))
+	for _, name := range original.GetOrderedTypeNames() {
+		typ := original.Types[name].Clone()
+		if name == original.QueryType {
+			addRootFields(typ)
 		}
+		b.AddType(typ)
+	}
+	for _, name := range original.GetOrderedDirectiveNames() {
+		b.AddDirective(original.Directives[name].Clone())
+	}
+	addIntrospectionTypes(b)
 
-		queryTypeCopy.AddField(schema.NewField(
-			"__schema",
-			"Access the current type schema of this server.",
-			schema.NonNullType(schema.NamedType("__Schema")),
-		))
-
-		typeField := schema.NewField(
-			"__type",
-			"Request the type information of a single type.",
-			schema.NamedType("__Type"),
-		)
-		typeField.AddArgument(
-			schema.NewInputValue(
-				"name",
-				"The name of the type to look up.",
-				schema.NonNullType(schema.NamedType("String")),
-			),
-		)
-		queryTypeCopy.AddField(typeField)
+	return b.Build()
+}
 
-		extended.AddType(queryTypeCopy)
-	}
+// addRootFields adds the __schema and __type fields to the query type.
+func addRootFields(queryType *schema.Type) {
+	queryType.AddField(schema.NewField(
+		"__schema",
+		"Access the current type schema of this server.",
+		schema.NonNullType(schema.NamedType("__Schema")),
+	))
 
-	return extended
+	typeField := schema.NewField(
+		"__type",
+		"Request the type information of a single type.",
+		schema.NamedType("__Type"),
+	)
+	typeField.AddArgument(
+		schema.NewInputValue(
+			"name",
+			"The name of the type to look up.",
+			schema.NonNullType(schema.NamedType("String")),
+		),
+	)
+	queryType.AddField(typeField)
 }
 
 // addIntrospectionTypes adds the introspection types to the schema
-func addIntrospectionTypes(sch *schema.Schema) {
-	sch.AddType(schemaType()).
+func addIntrospectionTypes(b *schema.SchemaBuilder) {
+	b.AddType(schemaType()).
 		AddType(typeType()).
 		AddType(fieldType()).
 		AddType(inputValueType()).
@@ -400,29 +396,3 @@ func directiveLocationEnum() *schema.Type {
 	}
 	return t
 }
-
-func cloneField(src *schema.Field) *schema.Field {
-	if src == nil {
-		return nil
-	}
-	cloned := schema.NewField(src.Name, src.Description, src.Type).
-		SetAsync(src.Async)
-	if src.IsDeprecated {
-		cloned.Deprecate(src.DeprecationReason)
-	}
-	for _, arg := range src.GetOrderedArguments() {
-		cloned.AddArgument(cloneInputValue(arg))
-	}
-	cloned.Index = src.Index
-	return cloned
-}
-
-func cloneInputValue(src *schema.InputValue) *schema.InputValue {
-	cloned := schema.NewInputValue(src.Name, src.Description, src.Type).
-		SetDefault(src.DefaultValue)
-	if src.IsDeprecated {
-		cloned.Deprecate(src.DeprecationReason)
-	}
-	cloned.Index = src.Index
-	return cloned
-}
@@ -2,8 +2,10 @@ package ir
 
 import (
 	"context"
+	"slices"
 
 	language "github.com/hanpama/protograph/internal/language"
+	"github.com/hanpama/protograph/internal/parallel"
 )
 
 type builder struct {
@@ -15,9 +17,16 @@ type builder struct {
 	Resolvers   map[ResolverID]*ResolverDefinition
 
 	fields      map[[2]string]*FieldDefinition
+	generated   map[string]struct{} // definitions synthesized by the builder rather than declared in SDL
 	violations  []*Violation
 	discovery   Discovery
 	serviceDocs map[ServiceID]*language.SchemaDocument
+	// fallbackServices maps fallback services, by package-qualified name, to the
+	// service whose resolvers they serve
+	fallbackServices map[string]ServiceID
+	// keyedLoaders are the positions of keyed @loader directives, whose keys are
+	// checked once every scalar is mapped
+	keyedLoaders map[LoaderID]*language.Position
 }
 
 func Build(ctx context.Context, disc Discovery) (*Project, error) {
@@ -29,9 +38,13 @@ func Build(ctx context.Context, disc Discovery) (*Project, error) {
 		Loaders:     make(map[LoaderID]*LoaderDefinition),
 		Resolvers:   make(map[ResolverID]*ResolverDefinition),
 		fields:      make(map[[2]string]*FieldDefinition),
+		generated:   make(map[string]struct{}),
 		violations:  nil,
 		discovery:   disc,
 		serviceDocs: make(map[ServiceID]*language.SchemaDocument),
+
+		fallbackServices: make(map[string]ServiceID),
+		keyedLoaders:     make(map[LoaderID]*language.Position),
 	}
 
 	if err := b.build(ctx); err != nil {
@@ -67,25 +80,34 @@ func (b *builder) build(ctx context.Context) (err error) {
 		b.Services[s.ID] = s
 	}
 
-	// Parse service SDL files
-	for svcId, svcMeta := range b.Services {
-		sdl, err := b.discovery.ReadServiceSDL(ctx, svcId)
-		if err != nil {
-			return err
-		}
-		document, err := language.ParseSchema(svcMeta.FilePath, sdl)
+	// Parse service SDL files concurrently, in service ID order so the first
+	// failing file is reported regardless of scheduling
+	ids := make([]ServiceID, 0, len(b.Services))
+	for id := range b.Services {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	docs := make([]*language.SchemaDocument, len(ids))
+	err = parallel.Do(len(ids), func(i int) error {
+		sdl, err := b.discovery.ReadServiceSDL(ctx, ids[i])
 		if err != nil {
 			return err
 		}
-		b.serviceDocs[svcId] = document
+		docs[i], err = language.ParseSchema(b.Services[ids[i]].FilePath, sdl)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		b.serviceDocs[id] = docs[i]
 	}
 
-	// Load built-in scalars
-	b.Definitions["String"] = &Definition{Scalar: StringType}
-	b.Definitions["Int"] = &Definition{Scalar: IntType}
-	b.Definitions["Float"] = &Definition{Scalar: FloatType}
-	b.Definitions["Boolean"] = &Definition{Scalar: BooleanType}
-	b.Definitions["ID"] = &Definition{Scalar: IDType}
+	// Load built-in scalars, copied as `extend scalar ID` may map ID to another proto type
+	for _, scalar := range []*ScalarDefinition{StringType, IntType, FloatType, BooleanType, IDType} {
+		builtin := *scalar
+		b.Definitions[scalar.Name] = &Definition{Scalar: &builtin}
+	}
 
 	// Populate definitions
 	if err = b.populateDefinitions(); err != nil {
@@ -116,6 +138,11 @@ func (b *builder) build(ctx context.Context) (err error) {
 		return err
 	}
 
+	// Expand @connection fields into Relay connection types
+	if err = b.populateConnections(); err != nil {
+		return err
+	}
+
 	if err = b.setFieldResolution(); err != nil {
 		return err
 	}
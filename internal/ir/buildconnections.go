@@ -0,0 +1,261 @@
+package ir
+
+import (
+	"sort"
+
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// pageInfoTypeName is the shared Relay PageInfo object used by every connection.
+const pageInfoTypeName = "PageInfo"
+
+// connectionArgs are the Relay cursor pagination arguments added to @connection fields.
+var connectionArgs = []struct {
+	name        string
+	typ         string
+	description string
+}{
+	{"first", "Int", "Returns the first n elements from the list."},
+	{"after", "String", "Returns the elements in the list that come after the specified cursor."},
+	{"last", "Int", "Returns the last n elements from the list."},
+	{"before", "String", "Returns the elements in the list that come before the specified cursor."},
+}
+
+// pageArgs replace the pagination arguments in the request of a @connection
+// field's resolver: the runtime decodes the cursors into the window of the list
+// it asks the backend for.
+var pageArgs = []struct {
+	name        string
+	description string
+}{
+	{"offset", "Skips this many elements of the list."},
+	{"limit", "Returns at most this many elements, or all of them when 0."},
+}
+
+// populateConnections expands @connection fields into Relay cursor connections.
+// The list return type `[T]` is replaced with `TConnection!`, pagination arguments
+// are appended to the field, and the TConnection, TEdge and PageInfo objects are
+// synthesized as plain source-resolved definitions owned by the service of T.
+// Resolvers keep returning the list; the runtime pages it into the connection.
+func (b *builder) populateConnections() error {
+	owner := make(map[string]ServiceID)
+	for sid, svc := range b.Services {
+		for _, defName := range svc.Definitions {
+			owner[defName] = sid
+		}
+	}
+
+	// Visit services in a stable order so shared types are attributed deterministically
+	svcIDs := make([]ServiceID, 0, len(b.serviceDocs))
+	for sid := range b.serviceDocs {
+		svcIDs = append(svcIDs, sid)
+	}
+	sort.Slice(svcIDs, func(i, j int) bool { return svcIDs[i] < svcIDs[j] })
+
+	for _, sid := range svcIDs {
+		doc := b.serviceDocs[sid]
+		for _, node := range doc.Definitions {
+			b.processObjectConnectionDirectives(sid, owner, node)
+		}
+		for _, node := range doc.Extensions {
+			b.processObjectConnectionDirectives(sid, owner, node)
+		}
+	}
+
+	if len(b.violations) > 0 {
+		return ValidationError(b.violations)
+	}
+	return nil
+}
+
+func (b *builder) processObjectConnectionDirectives(sid ServiceID, owner map[string]ServiceID, node *language.Definition) {
+	if node.Kind != language.Object {
+		return
+	}
+	obj := b.Definitions[node.Name].Object
+	for _, fieldNode := range node.Fields {
+		for _, dir := range fieldNode.Directives {
+			if dir.Name == "connection" {
+				b.handleConnectionDirective(sid, owner, obj.Fields[fieldNode.Name], dir, fieldNode)
+			}
+		}
+	}
+}
+
+func (b *builder) handleConnectionDirective(sid ServiceID, owner map[string]ServiceID, field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition) {
+	b.checkNoDirectiveArguments(dir)
+
+	listType := field.Type
+	if listType.Kind == TypeExprKindNonNull {
+		listType = listType.OfType
+	}
+	if listType.Kind != TypeExprKindList {
+		b.addViolation(violationConnectionFieldNotList(fieldNode.Name, field.Type.String(), fieldNode.Position))
+		return
+	}
+	nodeType := listType.OfType
+	if nodeType.Kind == TypeExprKindNonNull {
+		if nodeType.OfType.Kind != TypeExprKindNamed {
+			b.addViolation(violationConnectionFieldNotList(fieldNode.Name, field.Type.String(), fieldNode.Position))
+			return
+		}
+	} else if nodeType.Kind != TypeExprKindNamed {
+		b.addViolation(violationConnectionFieldNotList(fieldNode.Name, field.Type.String(), fieldNode.Position))
+		return
+	}
+
+	for _, ca := range connectionArgs {
+		if _, exists := field.Args[ca.name]; exists {
+			b.addViolation(violationConnectionArgumentConflict(ca.name, fieldNode.Name, fieldNode.Position))
+			return
+		}
+	}
+	for _, pa := range pageArgs {
+		if _, exists := field.Args[pa.name]; exists {
+			b.addViolation(violationConnectionArgumentConflict(pa.name, fieldNode.Name, fieldNode.Position))
+			return
+		}
+	}
+
+	// Connection types live next to the node type; scalars and enums from other
+	// packages fall back to the service declaring the field.
+	nodeName := nodeType.unwrap()
+	typeOwner, ok := owner[nodeName]
+	if !ok {
+		typeOwner = sid
+	}
+	if !b.ensurePageInfo(typeOwner, fieldNode.Position) {
+		return
+	}
+	connName, ok := b.ensureConnectionTypes(typeOwner, nodeName, nodeType, fieldNode.Position)
+	if !ok {
+		return
+	}
+
+	field.ConnectionOf = field.Type
+	field.Type = &TypeExpr{Kind: TypeExprKindNonNull, OfType: &TypeExpr{Kind: TypeExprKindNamed, Named: connName}}
+	for _, ca := range connectionArgs {
+		field.Args[ca.name] = &ArgumentDefinition{
+			Name:        ca.name,
+			Description: ca.description,
+			Index:       len(field.Args),
+			Type:        &TypeExpr{Kind: TypeExprKindNamed, Named: ca.typ},
+		}
+	}
+}
+
+// addPageArgs adds the page arguments of a @connection field to the request
+// arguments of its resolver.
+func addPageArgs(field *FieldDefinition, args map[string]*MethodArg) {
+	if field.ConnectionOf == nil {
+		return
+	}
+	for _, pa := range pageArgs {
+		args[pa.name] = &MethodArg{Name: pa.name, Type: &TypeExpr{Kind: TypeExprKindNamed, Named: "Int"}, Index: len(args), Description: pa.description}
+	}
+}
+
+// resolverReturnType returns the type the resolver of field returns: the list of
+// a @connection field, its type otherwise.
+func resolverReturnType(field *FieldDefinition) *TypeExpr {
+	if field.ConnectionOf != nil {
+		return field.ConnectionOf
+	}
+	return field.Type
+}
+
+// ensurePageInfo makes sure the shared PageInfo object exists. A user-declared
+// PageInfo object is reused as is.
+func (b *builder) ensurePageInfo(sid ServiceID, pos *language.Position) bool {
+	if def, exists := b.Definitions[pageInfoTypeName]; exists {
+		if def.Object == nil {
+			b.addViolation(violationConnectionTypeConflict(pageInfoTypeName, pos))
+			return false
+		}
+		return true
+	}
+	b.addGeneratedObject(sid, &ObjectDefinition{
+		Name:        pageInfoTypeName,
+		Description: "Information about pagination in a connection.",
+		Fields: generatedFields(
+			generatedField("hasNextPage", "When paginating forwards, are there more items?", nonNullNamed("Boolean")),
+			generatedField("hasPreviousPage", "When paginating backwards, are there more items?", nonNullNamed("Boolean")),
+			generatedField("startCursor", "When paginating backwards, the cursor to continue.", &TypeExpr{Kind: TypeExprKindNamed, Named: "String"}),
+			generatedField("endCursor", "When paginating forwards, the cursor to continue.", &TypeExpr{Kind: TypeExprKindNamed, Named: "String"}),
+		),
+	})
+	return true
+}
+
+// ensureConnectionTypes synthesizes `<Node>Connection` and `<Node>Edge` once per
+// node type and returns the connection type name.
+func (b *builder) ensureConnectionTypes(sid ServiceID, nodeName string, nodeType *TypeExpr, pos *language.Position) (string, bool) {
+	connName := nodeName + "Connection"
+	edgeName := nodeName + "Edge"
+	_, connGenerated := b.generated[connName]
+	_, edgeGenerated := b.generated[edgeName]
+	if connGenerated && edgeGenerated {
+		return connName, true
+	}
+	for _, name := range []string{connName, edgeName} {
+		if _, exists := b.Definitions[name]; exists {
+			b.addViolation(violationConnectionTypeConflict(name, pos))
+			return "", false
+		}
+	}
+
+	b.addGeneratedObject(sid, &ObjectDefinition{
+		Name:        edgeName,
+		Description: "An edge in a " + nodeName + " connection.",
+		Fields: generatedFields(
+			generatedField("node", "The item at the end of the edge.", nodeType),
+			generatedField("cursor", "A cursor for use in pagination.", nonNullNamed("String")),
+		),
+	})
+	b.addGeneratedObject(sid, &ObjectDefinition{
+		Name:        connName,
+		Description: "A connection to a list of " + nodeName + " items.",
+		Fields: generatedFields(
+			generatedField("edges", "A list of edges.", &TypeExpr{
+				Kind: TypeExprKindNonNull,
+				OfType: &TypeExpr{
+					Kind:   TypeExprKindList,
+					OfType: nonNullNamed(edgeName),
+				},
+			}),
+			generatedField("pageInfo", "Information to aid in pagination.", nonNullNamed(pageInfoTypeName)),
+		),
+	})
+	return connName, true
+}
+
+func (b *builder) addGeneratedObject(sid ServiceID, obj *ObjectDefinition) {
+	b.Definitions[obj.Name] = &Definition{Object: obj}
+	b.Services[sid].Definitions = append(b.Services[sid].Definitions, obj.Name)
+	b.generated[obj.Name] = struct{}{}
+}
+
+// generatedFields indexes fields in declaration order.
+func generatedFields(fields ...*FieldDefinition) map[string]*FieldDefinition {
+	out := make(map[string]*FieldDefinition, len(fields))
+	for i, f := range fields {
+		f.Index = i
+		out[f.Name] = f
+	}
+	return out
+}
+
+// generatedField returns a field resolved from the source message field of the same name.
+func generatedField(name, description string, typ *TypeExpr) *FieldDefinition {
+	return &FieldDefinition{
+		Name:            name,
+		Description:     description,
+		Args:            make(map[string]*ArgumentDefinition),
+		Type:            typ,
+		ResolveBySource: &FieldResolveBySource{SourceField: name},
+	}
+}
+
+func nonNullNamed(name string) *TypeExpr {
+	return &TypeExpr{Kind: TypeExprKindNonNull, OfType: &TypeExpr{Kind: TypeExprKindNamed, Named: name}}
+}
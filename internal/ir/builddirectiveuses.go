@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -41,15 +42,16 @@ func (b *builder) populateDirectiveUses() error {
 			case language.Object:
 				b.processObjectTypeDirectives(svc, def.Object, node)
 			case language.Interface:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processInterfaceTypeDirectives(def.Interface, node)
 			case language.Union:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processUnionTypeDirectives(def.Union, node)
 			case language.Scalar:
 				b.processScalarTypeDirectives(def.Scalar, node)
 			case language.Enum:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processEnumTypeDirectives(def.Enum, node)
 			case language.InputObject:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processTypeMetadata(&def.Input.Metadata, node)
+				b.processInputFieldMetadata(def.Input, node)
 			}
 		}
 		for _, node := range doc.Extensions {
@@ -59,18 +61,20 @@ func (b *builder) populateDirectiveUses() error {
 			case language.Object:
 				b.processObjectTypeDirectives(svc, def.Object, node)
 			case language.Interface:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processInterfaceTypeDirectives(def.Interface, node)
 			case language.Union:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processUnionTypeDirectives(def.Union, node)
 			case language.Scalar:
 				b.processScalarTypeDirectives(def.Scalar, node)
 			case language.Enum:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processEnumTypeDirectives(def.Enum, node)
 			case language.InputObject:
-				b.checkNoDefinitionDirectiveUses(node)
+				b.processTypeMetadata(&def.Input.Metadata, node)
+				b.processInputFieldMetadata(def.Input, node)
 			}
 		}
 	}
+	b.checkKeyedLoaderKeys()
 
 	if len(b.violations) > 0 {
 		return ValidationError(b.violations)
@@ -93,14 +97,32 @@ func (b *builder) processObjectFieldDirectives(obj *ObjectDefinition, node *lang
 			case "internal":
 				b.checkNoDirectiveArguments(dir)
 				obj.Fields[fieldNode.Name].IsInternal = true
+			case "optional":
+				b.checkNoDirectiveArguments(dir)
+				if !b.isNonNullType(obj.Fields[fieldNode.Name].Type) {
+					b.addViolation(violationOptionalFieldNotNonNull(fieldNode.Name, node.Name, dir.Position))
+				}
+				// The field is published nullable, which no Non-Null interface
+				// field accepts
+				for _, ifaceName := range node.Interfaces {
+					if def, ok := b.Definitions[ifaceName]; ok && def.Interface != nil {
+						if f, ok := def.Interface.Fields[fieldNode.Name]; ok && b.isNonNullType(f.Type) {
+							b.addViolation(violationOptionalFieldOfNonNullInterfaceField(fieldNode.Name, node.Name, ifaceName, dir.Position))
+						}
+					}
+				}
+				obj.Fields[fieldNode.Name].IsOptional = true
 			case "deprecated":
 				obj.Fields[fieldNode.Name].Deprecation = b.projectDeprecation(dir)
-			case "load", "resolve":
+			case "load", "resolve", "connection", "node", "compute", "const", "default", "source", "onError", "cache", "priority", "timeout", "sla":
 				// skip here. These will be processed in the next pass
 			default:
-				b.addViolation(violationUnknownDirectiveOnField(dir.Name, fieldNode.Name, node.Name, dir.Position))
+				if !b.projectMetadata(&obj.Fields[fieldNode.Name].Metadata, dir, locationFieldDefinition) {
+					b.addViolation(violationUnknownDirectiveOnField(dir.Name, fieldNode.Name, node.Name, dir.Position))
+				}
 			}
 		}
+		b.processArgumentMetadata(obj.Fields[fieldNode.Name], fieldNode)
 	}
 
 	// Apply implicit @id behavior if no explicit @id fields
@@ -138,9 +160,12 @@ func (b *builder) processInterfaceFieldDirectives(iface *InterfaceDefinition, no
 				iface.Fields[fieldNode.Name].Deprecation = b.projectDeprecation(dir)
 			default:
 				// Spec 5.4: Interface-declared fields cannot carry protograph directives
-				b.addViolation(violationInterfaceDirectiveNotAllowed(dir.Name, fieldNode.Name, fieldNode.Position))
+				if !b.projectMetadata(&iface.Fields[fieldNode.Name].Metadata, dir, locationFieldDefinition) {
+					b.addViolation(violationInterfaceDirectiveNotAllowed(dir.Name, fieldNode.Name, fieldNode.Position))
+				}
 			}
 		}
+		b.processArgumentMetadata(iface.Fields[fieldNode.Name], fieldNode)
 	}
 }
 
@@ -149,15 +174,167 @@ func (b *builder) processObjectTypeDirectives(svc *Service, def *ObjectDefinitio
 		switch dir.Name {
 		case "loader":
 			b.handleLoaderDirective(svc, def, dir, node)
+		case "onError":
+			// applied to the fields of this node once their resolution is settled
+		default:
+			if !b.projectMetadata(&def.Metadata, dir, string(node.Kind)) {
+				b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			}
+		}
+	}
+}
+
+func (b *builder) processInterfaceTypeDirectives(def *InterfaceDefinition, node *language.Definition) {
+	for _, dir := range node.Directives {
+		switch dir.Name {
+		case "envelope":
+			b.handleEnvelopeDirective(&def.Envelope, dir)
+		default:
+			if !b.projectMetadata(&def.Metadata, dir, string(node.Kind)) {
+				b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			}
+		}
+	}
+}
+
+func (b *builder) processUnionTypeDirectives(def *UnionDefinition, node *language.Definition) {
+	for _, dir := range node.Directives {
+		switch dir.Name {
+		case "envelope":
+			b.handleEnvelopeDirective(&def.Envelope, dir)
+		case "discriminator":
+			b.handleDiscriminatorDirective(def, dir)
+		default:
+			if !b.projectMetadata(&def.Metadata, dir, string(node.Kind)) {
+				b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			}
+		}
+	}
+}
+
+func (b *builder) processEnumTypeDirectives(def *EnumDefinition, node *language.Definition) {
+	for _, dir := range node.Directives {
+		switch dir.Name {
+		case "onUnknown":
+			b.handleOnUnknownDirective(def, dir)
+		default:
+			if !b.projectMetadata(&def.Metadata, dir, string(node.Kind)) {
+				b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			}
+		}
+	}
+	for _, valueNode := range node.EnumValues {
+		for _, dir := range valueNode.Directives {
+			if dir.Name == "mapValue" {
+				b.handleMapValueDirective(def, def.Values[valueNode.Name], dir)
+			}
+		}
+	}
+}
+
+// handleMapValueDirective records `@mapValue(toProtobuf: "USER_STATUS_ACTIVE")`, the
+// name of the proto value of an enum value, unique within the enum.
+func (b *builder) handleMapValueDirective(enum *EnumDefinition, value *EnumValueDefinition, dir *language.Directive) {
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "toProtobuf":
+			value.ProtoName = b.getStringValue(arg.Value)
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	if dir.Arguments.ForName("toProtobuf") == nil {
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "toProtobuf", dir.Position))
+		return
+	}
+	if IsWithExpression(value.ProtoName) {
+		b.addViolation(violationInvalidProtoEnumValueName(value.ProtoName, dir.Position))
+		value.ProtoName = ""
+		return
+	}
+	for _, other := range enum.Values {
+		if other != value && other.ProtoName == value.ProtoName {
+			b.addViolation(violationDuplicateProtoEnumValueName(value.ProtoName, other.Name, value.Name, dir.Position))
+			value.ProtoName = ""
+			return
+		}
+	}
+}
+
+// handleOnUnknownDirective records `@onUnknown(action: NULL | STRING | VALUE, value: X)`.
+// The value of VALUE must be one of the enum's own values.
+func (b *builder) handleOnUnknownDirective(enum *EnumDefinition, dir *language.Directive) {
+	policy := &UnknownEnumPolicy{}
+	var valueNode *language.Value
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "action":
+			if arg.Value.Kind != language.EnumValue {
+				b.addViolation(violationInvalidUnknownEnumAction(arg.Value.String(), arg.Value.Position))
+				return
+			}
+			policy.Action = UnknownEnumAction(arg.Value.Raw)
+		case "value":
+			valueNode = arg.Value
 		default:
-			b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	switch policy.Action {
+	case "":
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "action", dir.Position))
+		return
+	case UnknownEnumActionNull, UnknownEnumActionString:
+		if valueNode != nil {
+			b.addViolation(violationUnknownEnumValueWithoutValueAction(valueNode.Position))
+			return
+		}
+	case UnknownEnumActionValue:
+		if valueNode == nil {
+			b.addViolation(violationMissingValueArgument(dir.Name, dir.Position))
+			return
+		}
+		if _, ok := enum.Values[valueNode.Raw]; valueNode.Kind != language.EnumValue || !ok {
+			b.addViolation(violationUnknownEnumValueNotInEnum(valueNode.String(), enum.Name, valueNode.Position))
+			return
+		}
+		policy.Value = valueNode.Raw
+	default:
+		b.addViolation(violationInvalidUnknownEnumAction(string(policy.Action), dir.Position))
+		return
+	}
+	enum.OnUnknown = policy
+}
+
+// handleEnvelopeDirective records `@envelope(kind: ANY | DEFAULT)`. DEFAULT is the
+// typename+payload or oneof envelope and is not recorded.
+func (b *builder) handleEnvelopeDirective(envelope *Envelope, dir *language.Directive) {
+	for _, arg := range dir.Arguments {
+		if arg.Name != "kind" {
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
 		}
 	}
+	arg := dir.Arguments.ForName("kind")
+	if arg == nil {
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "kind", dir.Position))
+		return
+	}
+	switch kind := Envelope(arg.Value.Raw); {
+	case arg.Value.Kind != language.EnumValue:
+		b.addViolation(violationInvalidEnvelopeKind(arg.Value.String(), arg.Value.Position))
+	case kind == EnvelopeAny:
+		*envelope = kind
+	case kind != "DEFAULT":
+		b.addViolation(violationInvalidEnvelopeKind(arg.Value.Raw, arg.Value.Position))
+	}
 }
 
 func (b *builder) handleLoaderDirective(svc *Service, obj *ObjectDefinition, dir *language.Directive, node *language.Definition) {
 	var keyFields []string
 	batch := true
+	keyed := false
+	list := false
+	var dataArg *language.Argument
 	hasKey := false
 	hasKeys := false
 	args := make(map[string]*MethodArg)
@@ -173,6 +350,12 @@ func (b *builder) handleLoaderDirective(svc *Service, obj *ObjectDefinition, dir
 			keyFields = b.getStringListValue(arg.Value)
 		case "batch":
 			batch = b.getBoolValue(arg.Value)
+		case "keyed":
+			keyed = b.getBoolValue(arg.Value)
+		case "list":
+			list = b.getBoolValue(arg.Value)
+		case "data":
+			dataArg = arg
 		default:
 			b.addViolation(violationUnknownDirectiveArgument("loader", arg.Name, arg.Position))
 		}
@@ -181,6 +364,17 @@ func (b *builder) handleLoaderDirective(svc *Service, obj *ObjectDefinition, dir
 		b.addViolation(violationLoaderKeyConflict(dir.Position))
 		return
 	}
+	if keyed && !batch {
+		b.addViolation(violationLoaderKeyedWithoutBatch(dir.Position))
+		return
+	}
+	var dataPath string
+	if dataArg != nil {
+		var ok bool
+		if dataPath, ok = b.getDataPath("loader", dataArg, batch); !ok {
+			return
+		}
+	}
 
 	if len(keyFields) == 0 {
 		if len(obj.IDFields) > 0 {
@@ -216,6 +410,9 @@ func (b *builder) handleLoaderDirective(svc *Service, obj *ObjectDefinition, dir
 		TargetType: obj.Name,
 		KeyFields:  keyFields,
 		Batch:      batch,
+		Keyed:      keyed,
+		List:       list,
+		DataPath:   dataPath,
 		Args:       args,
 	}
 
@@ -226,15 +423,46 @@ func (b *builder) handleLoaderDirective(svc *Service, obj *ObjectDefinition, dir
 
 	b.Loaders[loaderDef.ID] = loaderDef
 	svc.Loaders = append(svc.Loaders, loaderDef.ID)
+	if keyed {
+		b.keyedLoaders[loaderDef.ID] = dir.Position
+	}
+}
+
+// checkKeyedLoaderKeys rejects keyed loaders with list keys or keys of scalars
+// mapped to proto messages, which have no stable encoding to join results by.
+func (b *builder) checkKeyedLoaderKeys() {
+	for id, pos := range b.keyedLoaders {
+		loader := b.Loaders[id]
+		for _, keyField := range loader.KeyFields {
+			typ := loader.Args[keyField].Type
+			isList := false
+			for t := typ; t != nil && t.Kind != TypeExprKindNamed; t = t.OfType {
+				isList = isList || t.Kind == TypeExprKindList
+			}
+			protoType := b.Definitions[typ.unwrap()].Scalar.MappedToProtoType
+			if isList || protoType == "message" || protoType == "group" {
+				b.addViolation(violationLoaderKeyedKeyNotJoinable(keyField, loader.TargetType, pos))
+			}
+		}
+	}
 }
 
 func (b *builder) processScalarTypeDirectives(def *ScalarDefinition, node *language.Definition) {
 	for _, dir := range node.Directives {
 		switch dir.Name {
 		case "mapScalar":
-			def.MappedToProtoType = b.projectMapScalar(dir)
+			protoType := b.projectMapScalar(dir)
+			if IsBuiltinScalar(def.Name) && (def.Name != IDType.Name || !slices.Contains(IDProtoTypes, protoType)) {
+				b.addViolation(violationInvalidBuiltinScalarMapping(def.Name, protoType, dir.Position))
+				continue
+			}
+			def.MappedToProtoType = protoType
+		case "specifiedBy":
+			def.SpecifiedByURL = b.projectSpecifiedBy(dir)
 		default:
-			b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			if !b.projectMetadata(&def.Metadata, dir, string(node.Kind)) {
+				b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+			}
 		}
 	}
 }
@@ -258,6 +486,22 @@ func (b *builder) projectMapScalar(dir *language.Directive) string {
 	return protoType
 }
 
+func (b *builder) projectSpecifiedBy(dir *language.Directive) string {
+	var url string
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "url":
+			url = b.getStringValue(arg.Value)
+		default:
+			b.addViolation(violationUnknownDirectiveArgument("specifiedBy", arg.Name, arg.Position))
+		}
+	}
+	if url == "" {
+		b.addViolation(violationMissingURLArgument(dir.Position))
+	}
+	return url
+}
+
 func (b *builder) projectDeprecation(dir *language.Directive) *Deprecation {
 	reason := "No longer supported"
 
@@ -275,13 +519,6 @@ func (b *builder) projectDeprecation(dir *language.Directive) *Deprecation {
 	}
 }
 
-func (b *builder) checkNoDefinitionDirectiveUses(node *language.Definition) {
-	for _, dir := range node.Directives {
-		violations := []*Violation{violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position)}
-		b.addViolation(violations...)
-	}
-}
-
 func (b *builder) checkNoDirectiveArguments(node *language.Directive) {
 	for _, arg := range node.Arguments {
 		violations := []*Violation{violationDirectiveNoArguments(node.Name, arg.Position)}
@@ -0,0 +1,114 @@
+package ir
+
+import (
+	"sort"
+
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// handleDiscriminatorDirective records `@discriminator(field: "kind", values: { User: "USER" })`
+// on a union. Members left out of values are discriminated by their name.
+func (b *builder) handleDiscriminatorDirective(union *UnionDefinition, dir *language.Directive) {
+	d := &Discriminator{Values: map[string]string{}}
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "field":
+			d.Field = b.getStringValue(arg.Value)
+		case "values":
+			for member, value := range b.getStringMapValue(arg.Value) {
+				if _, ok := union.Types[member]; !ok {
+					b.addViolation(violationDiscriminatorNotMember(member, union.Name, arg.Position))
+					continue
+				}
+				d.Values[member] = value
+			}
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	if dir.Arguments.ForName("field") == nil {
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "field", dir.Position))
+		return
+	}
+	if IsWithExpression(d.Field) {
+		b.addViolation(violationInvalidDiscriminatorField(d.Field, dir.Position))
+		return
+	}
+	members := map[string]string{}
+	for _, typ := range union.OrderedTypes() {
+		value, ok := d.Values[typ.Name]
+		if !ok {
+			value = typ.Name
+			d.Values[typ.Name] = value
+		}
+		if other, ok := members[value]; ok {
+			b.addViolation(violationDuplicateDiscriminatorValue(value, other, typ.Name, dir.Position))
+			return
+		}
+		members[value] = typ.Name
+	}
+	union.Discriminator = d
+}
+
+// checkDiscriminatedUnions validates the shared message of unions with a
+// @discriminator: it holds the source fields of every member, so members may only
+// share a field name with the same type, and none may be named like the
+// discriminator. Source fields are known once every field has been resolved.
+func (b *builder) checkDiscriminatedUnions() {
+	check := func(nodes []*language.Definition) {
+		for _, node := range nodes {
+			dir := node.Directives.ForName("discriminator")
+			if node.Kind != language.Union || dir == nil {
+				continue
+			}
+			union := b.Definitions[node.Name].Union
+			if union.Discriminator == nil {
+				continue
+			}
+			if union.Envelope == EnvelopeAny {
+				b.addViolation(violationDiscriminatorWithAnyEnvelope(union.Name, dir.Position))
+				continue
+			}
+			types := map[string]*TypeExpr{}
+			owners := map[string]string{}
+			for _, member := range union.OrderedTypes() {
+				for _, field := range b.Definitions[member.Name].Object.OrderedFields() {
+					if !IsSourceMessageField(field) {
+						continue
+					}
+					if field.Name == union.Discriminator.Field {
+						b.addViolation(violationDiscriminatorFieldConflict(field.Name, member.Name, dir.Position))
+						continue
+					}
+					if typ, ok := types[field.Name]; ok && !b.typesAreEqual(typ, field.Type) {
+						b.addViolation(violationDiscriminatedFieldTypeConflict(field.Name, owners[field.Name], member.Name, dir.Position))
+						continue
+					}
+					types[field.Name] = field.Type
+					owners[field.Name] = member.Name
+				}
+			}
+		}
+	}
+	for _, doc := range b.serviceDocs {
+		check(doc.Definitions)
+		check(doc.Extensions)
+	}
+}
+
+// IsSourceMessageField reports whether a field is held by the source message of its
+// object: fields read from the source as is, and @internal fields.
+func IsSourceMessageField(field *FieldDefinition) bool {
+	return field.IsInternal || (field.ResolveBySource != nil && field.ResolveBySource.SourceField == field.Name)
+}
+
+func (u *UnionDefinition) OrderedTypes() []*UnionTypeDefinition {
+	types := make([]*UnionTypeDefinition, 0, len(u.Types))
+	for _, typ := range u.Types {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Index < types[j].Index
+	})
+	return types
+}
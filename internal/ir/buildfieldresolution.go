@@ -2,9 +2,12 @@ package ir
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/hanpama/protograph/internal/compute"
 	language "github.com/hanpama/protograph/internal/language"
 )
 
@@ -21,6 +24,7 @@ func (b *builder) setFieldResolution() error {
 					field := obj.Fields[fieldNode.Name]
 					b.processFieldResolution(svc, field, fieldNode, obj, false)
 				}
+				b.applyTypeErrorPolicy(obj, node)
 			}
 		}
 		for _, node := range doc.Extensions {
@@ -31,9 +35,14 @@ func (b *builder) setFieldResolution() error {
 					field := obj.Fields[fieldNode.Name]
 					b.processFieldResolution(svc, field, fieldNode, obj, true)
 				}
+				b.applyTypeErrorPolicy(obj, node)
 			}
 		}
 	}
+	// @source paths, @compute references and @discriminator members can only be checked once every field has been resolved
+	b.checkSourcePaths()
+	b.checkComputedFieldRefs()
+	b.checkDiscriminatedUnions()
 	if len(b.violations) > 0 {
 		return ValidationError(b.violations)
 	}
@@ -44,6 +53,10 @@ func (b *builder) processFieldResolution(svc *Service, field *FieldDefinition, f
 	// Pre-scan for conflicting directives (@load + @resolve together)
 	hasLoad := false
 	hasResolve := false
+	hasNode := false
+	hasCompute := false
+	hasConst := false
+	hasSource := false
 	for _, dir := range fieldNode.Directives {
 		if dir.Name == "load" {
 			hasLoad = true
@@ -51,39 +64,316 @@ func (b *builder) processFieldResolution(svc *Service, field *FieldDefinition, f
 		if dir.Name == "resolve" {
 			hasResolve = true
 		}
+		if dir.Name == "node" {
+			hasNode = true
+		}
+		if dir.Name == "compute" {
+			hasCompute = true
+		}
+		if dir.Name == "const" {
+			hasConst = true
+		}
+		if dir.Name == "source" {
+			hasSource = true
+		}
 	}
 	if hasLoad && hasResolve {
 		b.addViolation(violationLoadResolveConflict(obj.Name, fieldNode.Name, fieldNode.Position))
 		return // abort further processing to avoid ambiguous resolution fallback
 	}
+	if hasNode && (hasLoad || hasResolve) {
+		b.addViolation(violationNodeResolutionConflict(obj.Name, fieldNode.Name, fieldNode.Position))
+		return
+	}
+	if hasCompute && (hasLoad || hasResolve || hasNode) {
+		b.addViolation(violationComputeResolutionConflict(obj.Name, fieldNode.Name, fieldNode.Position))
+		return
+	}
+	if hasConst && (hasLoad || hasResolve || hasNode || hasCompute) {
+		b.addViolation(violationConstResolutionConflict(obj.Name, fieldNode.Name, fieldNode.Position))
+		return
+	}
+	if hasSource && (hasLoad || hasResolve || hasNode || hasCompute || hasConst) {
+		b.addViolation(violationSourceResolutionConflict(obj.Name, fieldNode.Name, fieldNode.Position))
+		return
+	}
 
-	// Check for @load and @resolve directives
+	// Check for @load, @resolve, @node, @compute, @const and @source directives
 	for _, dir := range fieldNode.Directives {
 		switch dir.Name {
 		case "load":
 			b.handleLoadDirective(field, dir, fieldNode, obj)
 		case "resolve":
 			b.handleResolveDirective(svc, obj, field, dir, fieldNode)
+		case "node":
+			b.handleNodeDirective(field, dir, fieldNode, obj)
+		case "compute":
+			b.handleComputeDirective(field, dir, fieldNode, obj)
+		case "const":
+			b.handleConstDirective(field, dir, fieldNode)
+		case "source":
+			b.handleSourceDirective(field, dir, fieldNode, obj)
 		}
 	}
 
-	isRoot := b.isRootObject(obj.Name)
-	// Implicit resolver conditions:
-	// 1. No explicit @resolve / @load already applied
-	// 2. Either field has arguments OR parent object is a root (schema-configured)
-	if field.ResolveByResolver == nil && field.ResolveByLoader == nil && (len(fieldNode.Arguments) > 0 || isRoot) || isExt {
-		b.handleImplicitResolver(svc, obj, fieldNode, field)
+	// @node fields are dispatched to per-type loaders while @compute, @const and @source
+	// fields are served by the gateway; none of them has a resolver method of its own
+	if !hasNode && !hasCompute && !hasConst && !hasSource {
+		isRoot := b.isRootObject(obj.Name)
+		// Implicit resolver conditions:
+		// 1. No explicit @resolve / @load already applied
+		// 2. Either field has arguments OR parent object is a root (schema-configured)
+		if field.ResolveByResolver == nil && field.ResolveByLoader == nil && (len(field.Args) > 0 || isRoot) || isExt {
+			b.handleImplicitResolver(svc, obj, fieldNode, field)
+		}
+
+		// If still unresolved and not a root object, resolve by source
+		if field.ResolveByResolver == nil && field.ResolveByLoader == nil && !isRoot {
+			field.ResolveBySource = &FieldResolveBySource{SourceField: fieldNode.Name}
+		}
 	}
 
-	// If still unresolved and not a root object, resolve by source
-	if field.ResolveByResolver == nil && field.ResolveByLoader == nil && !isRoot {
-		field.ResolveBySource = &FieldResolveBySource{SourceField: fieldNode.Name}
+	b.checkMetadataArguments(field, fieldNode, obj)
+
+	// @default, @onError, @cache, @priority, @timeout and @sla decorate the resolution, so they are applied once it is settled
+	for _, dir := range fieldNode.Directives {
+		switch dir.Name {
+		case "default":
+			b.handleDefaultDirective(field, dir, fieldNode, obj)
+		case "onError":
+			b.handleOnErrorDirective(field, dir, fieldNode, obj)
+		case "cache":
+			b.handleCacheDirective(field, dir, fieldNode, obj)
+		case "priority":
+			b.handlePriorityDirective(field, dir, fieldNode, obj)
+		case "timeout":
+			b.handleTimeoutDirective(field, dir, fieldNode, obj)
+		case "sla":
+			b.handleSLADirective(field, dir, fieldNode, obj)
+		}
+	}
+}
+
+// checkMetadataArguments validates the @metadata arguments of a field: they are
+// sent with the resolver call, so the field needs a resolver, and their values
+// must be scalars or enums to render as metadata text.
+func (b *builder) checkMetadataArguments(field *FieldDefinition, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	for _, argNode := range fieldNode.Arguments {
+		arg := field.Args[argNode.Name]
+		if arg == nil || arg.MetadataKey == "" {
+			continue
+		}
+		pos := argNode.Directives.ForName("metadata").Position
+		if field.ResolveByResolver == nil {
+			b.addViolation(violationMetadataRequiresResolver(argNode.Name, fieldNode.Name, obj.Name, pos))
+			continue
+		}
+		named := arg.Type
+		if named.Kind == TypeExprKindNonNull {
+			named = named.OfType
+		}
+		if named.Kind != TypeExprKindNamed || (b.Definitions[named.Named].Scalar == nil && b.Definitions[named.Named].Enum == nil) {
+			b.addViolation(violationMetadataArgumentType(argNode.Name, arg.Type.String(), pos))
+		}
 	}
 }
 
+// handleCacheDirective records `@cache(ttl: ..., staleWhileRevalidate: ...)` on a field
+// resolved by a resolver or loader.
+func (b *builder) handleCacheDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if field.ResolveByResolver == nil && field.ResolveByLoader == nil {
+		b.addViolation(violationCacheRequiresRemoteField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	policy := &CachePolicy{}
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "ttl":
+			policy.TTL = b.getDurationValue(dir.Name, arg)
+		case "staleWhileRevalidate":
+			policy.StaleWhileRevalidate = b.getDurationValue(dir.Name, arg)
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	if dir.Arguments.ForName("ttl") == nil {
+		b.addViolation(violationMissingTTLArgument(dir.Position))
+		return
+	}
+	field.Cache = policy
+}
+
+// handlePriorityDirective records `@priority(level: HIGH | NORMAL | LOW)` on a field
+// resolved over RPC. NORMAL is the default and is not recorded.
+func (b *builder) handlePriorityDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if !isRemoteField(field) {
+		b.addViolation(violationPriorityRequiresRemoteField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	for _, arg := range dir.Arguments {
+		if arg.Name != "level" {
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	arg := dir.Arguments.ForName("level")
+	if arg == nil {
+		b.addViolation(violationMissingLevelArgument(dir.Position))
+		return
+	}
+	switch level := Priority(arg.Value.Raw); {
+	case arg.Value.Kind != language.EnumValue:
+		b.addViolation(violationInvalidPriorityLevel(arg.Value.String(), arg.Value.Position))
+	case level == PriorityHigh || level == PriorityLow:
+		field.Priority = level
+	case level != "NORMAL":
+		b.addViolation(violationInvalidPriorityLevel(arg.Value.Raw, arg.Value.Position))
+	}
+}
+
+// handleTimeoutDirective records `@timeout(ms: 500)` on a field resolved over RPC:
+// its calls get a deadline of their own, tighter than the request's.
+func (b *builder) handleTimeoutDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if !isRemoteField(field) {
+		b.addViolation(violationTimeoutRequiresRemoteField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	for _, arg := range dir.Arguments {
+		if arg.Name != "ms" {
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	arg := dir.Arguments.ForName("ms")
+	if arg == nil {
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "ms", dir.Position))
+		return
+	}
+	ms, err := strconv.Atoi(arg.Value.Raw)
+	if arg.Value.Kind != language.IntValue || err != nil || ms <= 0 {
+		b.addViolation(violationInvalidTimeout(arg.Value.String(), arg.Value.Position))
+		return
+	}
+	field.TimeoutMs = ms
+}
+
+// handleSLADirective records `@sla(ms: 200)` on a field resolved over RPC: the
+// latency budget its calls are reported against, without bounding them.
+func (b *builder) handleSLADirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if !isRemoteField(field) {
+		b.addViolation(violationSLARequiresRemoteField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	for _, arg := range dir.Arguments {
+		if arg.Name != "ms" {
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	arg := dir.Arguments.ForName("ms")
+	if arg == nil {
+		b.addViolation(violationMissingDirectiveArgument(dir.Name, "ms", dir.Position))
+		return
+	}
+	ms, err := strconv.Atoi(arg.Value.Raw)
+	if arg.Value.Kind != language.IntValue || err != nil || ms <= 0 {
+		b.addViolation(violationInvalidSLA(arg.Value.String(), arg.Value.Position))
+		return
+	}
+	field.SLAMs = ms
+}
+
+// handleOnErrorDirective records the error policy of a field resolved over RPC.
+func (b *builder) handleOnErrorDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if !isRemoteField(field) {
+		b.addViolation(violationOnErrorRequiresRemoteField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	policy, valueNode, ok := b.projectErrorPolicy(dir)
+	if !ok {
+		return
+	}
+	if policy.Action == ErrorActionDefault {
+		value, ok := b.fieldLiteral(dir.Name, field, fieldNode, valueNode)
+		if !ok {
+			return
+		}
+		policy.Value = value
+	}
+	field.OnError = policy
+}
+
+// applyTypeErrorPolicy applies a type-level @onError to the RPC fields declared in
+// node that have no policy of their own. Declared on an extension, it therefore
+// covers only the fields contributed by that service.
+func (b *builder) applyTypeErrorPolicy(obj *ObjectDefinition, node *language.Definition) {
+	for _, dir := range node.Directives {
+		if dir.Name != "onError" {
+			continue
+		}
+		// a fallback value only fits fields of one type, so DEFAULT is declared per field
+		if arg := dir.Arguments.ForName("action"); arg != nil && arg.Value.Raw == string(ErrorActionDefault) {
+			b.addViolation(violationErrorDefaultOnType(obj.Name, dir.Position))
+			continue
+		}
+		policy, _, ok := b.projectErrorPolicy(dir)
+		if !ok {
+			continue
+		}
+		for _, fieldNode := range node.Fields {
+			field := obj.Fields[fieldNode.Name]
+			if field.OnError == nil && isRemoteField(field) {
+				field.OnError = &ErrorPolicy{Action: policy.Action}
+			}
+		}
+	}
+}
+
+// projectErrorPolicy reads the arguments of @onError. The `value` literal is returned
+// unconverted, as only fields know the type it must conform to.
+func (b *builder) projectErrorPolicy(dir *language.Directive) (*ErrorPolicy, *language.Value, bool) {
+	var action string
+	var valueNode *language.Value
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "action":
+			if arg.Value.Kind != language.EnumValue {
+				b.addViolation(violationInvalidErrorAction(arg.Value.String(), arg.Value.Position))
+				return nil, nil, false
+			}
+			action = arg.Value.Raw
+		case "value":
+			valueNode = arg.Value
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	switch ErrorAction(action) {
+	case "":
+		b.addViolation(violationMissingActionArgument(dir.Position))
+		return nil, nil, false
+	case ErrorActionPropagate, ErrorActionNull:
+		if valueNode != nil {
+			b.addViolation(violationErrorValueWithoutDefault(valueNode.Position))
+			return nil, nil, false
+		}
+	case ErrorActionDefault:
+		if valueNode == nil {
+			b.addViolation(violationMissingValueArgument(dir.Name, dir.Position))
+			return nil, nil, false
+		}
+	default:
+		b.addViolation(violationInvalidErrorAction(action, dir.Position))
+		return nil, nil, false
+	}
+	return &ErrorPolicy{Action: ErrorAction(action)}, valueNode, true
+}
+
+// isRemoteField reports whether resolving field calls a backend.
+func isRemoteField(field *FieldDefinition) bool {
+	return field.ResolveByResolver != nil || field.ResolveByLoader != nil || field.ResolveByNode != nil
+}
+
 func (b *builder) handleLoadDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
-	// Spec: @load fields must not define arguments
-	if len(fieldNode.Arguments) > 0 {
+	// Spec: @load fields must not define arguments (including @connection pagination arguments)
+	if len(field.Args) > 0 {
 		b.addViolation(violationFieldArgsNotAllowedWithLoad(fieldNode.Position))
 		return
 	}
@@ -122,12 +412,28 @@ func (b *builder) handleLoadDirective(field *FieldDefinition, dir *language.Dire
 		return
 	}
 
+	// List fields load through list loaders, which answer every key with a list
+	if isLoadedList := isListOfNamed(field.Type); isLoadedList != loaderDef.List {
+		if isLoadedList {
+			b.addViolation(violationLoadListNeedsListLoader(fieldNode.Name, string(loaderID), fieldNode.Position))
+		} else {
+			b.addViolation(violationLoadListLoaderNeedsList(fieldNode.Name, string(loaderID), fieldNode.Position))
+		}
+		return
+	}
+
 	// Validate each parent source field exists and types are (optionally) compatible
-	for _, parentFieldName := range withMapping {
-		if _, exists := obj.Fields[parentFieldName]; !exists {
-			b.addViolation(violationLoadMappingUnknownParentField(parentFieldName, obj.Name, fieldNode.Position))
+	for key, value := range withMapping {
+		refs, ok := b.withMappingRefs("load", key, value, dir.Position)
+		if !ok {
 			return
 		}
+		for _, parentFieldName := range refs {
+			if _, exists := obj.Fields[parentFieldName]; !exists {
+				b.addViolation(violationLoadMappingUnknownParentField(parentFieldName, obj.Name, fieldNode.Position))
+				return
+			}
+		}
 	}
 
 	// Ensure mapping keys match exactly the loader key set (already sorted)
@@ -148,6 +454,9 @@ func (b *builder) handleLoadDirective(field *FieldDefinition, dir *language.Dire
 	targetObj := def.Object
 	for _, k := range keyFields {
 		parentFieldName := withMapping[k]
+		if IsWithExpression(parentFieldName) {
+			continue // the value is coerced to the key type when the request is built
+		}
 		srcField := obj.Fields[parentFieldName]
 		tgtField := targetObj.Fields[k]
 		if !b.areTypesAssignableForLoad(srcField.Type, tgtField.Type) {
@@ -164,6 +473,8 @@ func (b *builder) handleResolveDirective(svc *Service, def *ObjectDefinition, fi
 	var withMapping map[string]string
 	batch := false // default
 	var hasWithArg bool
+	var dataArg *language.Argument
+	var fallbacks []string
 
 	for _, arg := range dir.Arguments {
 		switch arg.Name {
@@ -172,16 +483,38 @@ func (b *builder) handleResolveDirective(svc *Service, def *ObjectDefinition, fi
 			withMapping = b.getStringMapValue(arg.Value)
 		case "batch":
 			batch = b.getBoolValue(arg.Value)
+		case "data":
+			dataArg = arg
+		case "fallback":
+			if b.Schema != nil && b.Schema.MutationType == def.Name {
+				// A write timed out on one service may have applied, and would
+				// apply twice on another
+				violations = append(violations, violationFallbackOnMutation(def.Name, fieldNode.Name, arg.Position))
+				continue
+			}
+			fallbacks = b.getFallbackServices(svc, arg)
 		default:
 			violations = append(violations, violationUnknownDirectiveArgument("resolve", arg.Name, arg.Position))
 		}
 	}
 
+	var dataPath string
+	if dataArg != nil {
+		var ok bool
+		if dataPath, ok = b.getDataPath("resolve", dataArg, batch); !ok {
+			return
+		}
+	}
+
 	// Build args: start with declared GraphQL arguments
 	args := make(map[string]*MethodArg)
-	for _, arg := range field.Args {
+	for _, arg := range field.OrderedArgs() {
+		if arg.MetadataKey != "" || field.IsConnectionArg(arg.Name) {
+			continue // sent as metadata or as page arguments
+		}
 		args[arg.Name] = &MethodArg{Name: arg.Name, Type: arg.Type, Index: len(args), Description: arg.Description}
 	}
+	addPageArgs(field, args)
 
 	// Default mapping when `with` is omitted: include all @id fields (reqField == parentField)
 	if !hasWithArg {
@@ -191,11 +524,25 @@ func (b *builder) handleResolveDirective(svc *Service, def *ObjectDefinition, fi
 		}
 	}
 
-	// Validate mapping: key=request field name, value=parent field name
+	// Validate mapping: key=request field name, value=parent field name or expression
 	for reqField, parentField := range withMapping {
 		if _, exists := args[reqField]; exists {
 			violations = append(violations, violationResolveWithKeyConflictsArg(reqField, fieldNode.Position))
 		}
+		if IsWithExpression(parentField) {
+			refs, ok := b.withMappingRefs("resolve", reqField, parentField, dir.Position)
+			if !ok {
+				continue
+			}
+			for _, ref := range refs {
+				if _, ok := def.Fields[ref]; !ok {
+					violations = append(violations, violationResolveMappingUnknownParentField(ref, def.Name, fieldNode.Position))
+				}
+			}
+			// composite keys are rendered as text
+			args[reqField] = &MethodArg{Name: reqField, Type: &TypeExpr{Kind: TypeExprKindNamed, Named: "String"}, Index: len(args)}
+			continue
+		}
 		parentObjField, ok := def.Fields[parentField]
 		if !ok {
 			violations = append(violations, violationResolveMappingUnknownParentField(parentField, def.Name, fieldNode.Position))
@@ -217,7 +564,9 @@ func (b *builder) handleResolveDirective(svc *Service, def *ObjectDefinition, fi
 		Field:       fieldNode.Name,
 		Args:        args,
 		Batch:       batch,
-		ReturnType:  field.Type,
+		ReturnType:  resolverReturnType(field),
+		DataPath:    dataPath,
+		Fallbacks:   fallbacks,
 	}
 	resolverUse := &FieldResolveByResolver{ResolverID: resolverDef.ID, With: withMapping}
 
@@ -226,14 +575,86 @@ func (b *builder) handleResolveDirective(svc *Service, def *ObjectDefinition, fi
 	field.ResolveByResolver = resolverUse
 }
 
+// getFallbackServices validates the `fallback` argument of @resolve: services tried
+// in order when the resolver fails. They are generated next to svc, in its package,
+// so their names must not be taken by another service there.
+func (b *builder) getFallbackServices(svc *Service, arg *language.Argument) []string {
+	names := b.getStringListValue(arg.Value)
+	seen := map[string]bool{}
+	for _, name := range names {
+		qualified := strings.Join(append(append([]string{}, svc.PackagePath...), name), ".")
+		switch {
+		case name == "" || IsWithExpression(name):
+			b.addViolation(violationInvalidFallbackService(name, arg.Position))
+			return nil
+		case seen[name] || name == svc.Name:
+			b.addViolation(violationDuplicateFallbackService(name, arg.Position))
+			return nil
+		}
+		seen[name] = true
+		for _, other := range b.Services {
+			if other.Name == name && slices.Equal(other.PackagePath, svc.PackagePath) {
+				b.addViolation(violationFallbackServiceConflict(name, string(other.ID), arg.Position))
+				return nil
+			}
+		}
+		if owner, ok := b.fallbackServices[qualified]; ok && owner != svc.ID {
+			b.addViolation(violationFallbackServiceConflict(name, string(owner), arg.Position))
+			return nil
+		}
+		b.fallbackServices[qualified] = svc.ID
+	}
+	return names
+}
+
+// getDataPath validates the `data` argument of @resolve or @loader: the response
+// field holding the result, or a dot-separated path through nested messages.
+// Batch elements reserve `error` for failing an element.
+func (b *builder) getDataPath(directiveName string, arg *language.Argument, batch bool) (string, bool) {
+	path := b.getStringValue(arg.Value)
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" || IsWithExpression(segment) {
+			b.addViolation(violationInvalidDataPath(directiveName, path, arg.Position))
+			return "", false
+		}
+	}
+	if batch && segments[0] == "error" {
+		b.addViolation(violationDataPathReserved(directiveName, path, arg.Position))
+		return "", false
+	}
+	if path == "data" {
+		return "", true
+	}
+	return path, true
+}
+
+// withMappingRefs returns the parent fields a With mapping value reads: the value
+// itself when it names a field, or the fields referenced by its expression.
+func (b *builder) withMappingRefs(directiveName, key, value string, pos *language.Position) ([]string, bool) {
+	if !IsWithExpression(value) {
+		return []string{value}, true
+	}
+	expr, err := compute.Parse(value)
+	if err != nil {
+		b.addViolation(violationWithInvalidExpression(directiveName, key, err, pos))
+		return nil, false
+	}
+	return expr.Refs(), true
+}
+
 func (b *builder) handleImplicitResolver(svc *Service, obj *ObjectDefinition, fieldNode *language.FieldDefinition, field *FieldDefinition) {
 	var violations []*Violation
 	resolverID := ResolverID(fmt.Sprintf("%s:%s", obj.Name, fieldNode.Name))
 
 	args := make(map[string]*MethodArg)
-	for _, arg := range field.Args { // existing GraphQL args
+	for _, arg := range field.OrderedArgs() { // existing GraphQL args
+		if arg.MetadataKey != "" || field.IsConnectionArg(arg.Name) {
+			continue // sent as metadata or as page arguments
+		}
 		args[arg.Name] = &MethodArg{Name: arg.Name, Type: arg.Type, Index: len(args), Description: arg.Description}
 	}
+	addPageArgs(field, args)
 
 	withMapping := make(map[string]string)
 	for _, idFn := range obj.IDFields { // add all @id fields to args & mapping
@@ -261,7 +682,7 @@ func (b *builder) handleImplicitResolver(svc *Service, obj *ObjectDefinition, fi
 		Field:       fieldNode.Name,
 		Args:        args,
 		Batch:       false,
-		ReturnType:  field.Type,
+		ReturnType:  resolverReturnType(field),
 	}
 	resolverUse := &FieldResolveByResolver{ResolverID: resolverDef.ID, With: withMapping}
 
@@ -270,6 +691,303 @@ func (b *builder) handleImplicitResolver(svc *Service, obj *ObjectDefinition, fi
 	field.ResolveByResolver = resolverUse
 }
 
+// handleNodeDirective validates a Relay `node(id: ID!): Node` root field and records
+// the `id` loader of every implementer so the runtime can route decoded global IDs.
+func (b *builder) handleNodeDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	b.checkNoDirectiveArguments(dir)
+
+	if !b.isRootObject(obj.Name) {
+		b.addViolation(violationNodeFieldNotRoot(fieldNode.Name, obj.Name, fieldNode.Position))
+		return
+	}
+
+	idArg, ok := field.Args["id"]
+	if len(field.Args) != 1 || !ok || idArg.Type.String() != "ID!" {
+		b.addViolation(violationNodeFieldArguments(fieldNode.Name, fieldNode.Position))
+		return
+	}
+
+	retType := field.Type
+	if retType.Kind == TypeExprKindNonNull {
+		retType = retType.OfType
+	}
+	if retType.Kind != TypeExprKindNamed || b.Definitions[retType.Named].Interface == nil {
+		b.addViolation(violationNodeFieldNotInterface(fieldNode.Name, field.Type.String(), fieldNode.Position))
+		return
+	}
+	iface := b.Definitions[retType.Named].Interface
+	if idField, ok := iface.Fields["id"]; !ok || idField.Type.String() != "ID!" {
+		b.addViolation(violationNodeInterfaceMissingID(iface.Name, fieldNode.Position))
+		return
+	}
+
+	loaders := make(map[string]LoaderID, len(iface.PossibleTypes))
+	for _, typeName := range iface.PossibleTypes {
+		loaderID := LoaderID(typeName + ":id")
+		if loader, exists := b.Loaders[loaderID]; !exists || loader.List {
+			b.addViolation(violationNodeImplementerWithoutLoader(typeName, iface.Name, fieldNode.Position))
+			continue
+		}
+		loaders[typeName] = loaderID
+	}
+
+	field.ResolveByNode = &FieldResolveByNode{Interface: iface.Name, Loaders: loaders}
+}
+
+// handleComputeDirective validates `@compute(expr: "...")` and records the parsed
+// expression. Field references are checked later by checkComputedFieldRefs.
+func (b *builder) handleComputeDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	var src string
+	var hasExprArg bool
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "expr":
+			hasExprArg = true
+			src = b.getStringValue(arg.Value)
+		default:
+			b.addViolation(violationUnknownDirectiveArgument("compute", arg.Name, arg.Position))
+		}
+	}
+	if !hasExprArg {
+		b.addViolation(violationMissingExprArgument(dir.Position))
+		return
+	}
+
+	if b.isRootObject(obj.Name) {
+		b.addViolation(violationComputeFieldNotAllowed(fieldNode.Name, obj.Name, "cannot be declared on a root type", fieldNode.Position))
+		return
+	}
+	if len(field.Args) > 0 {
+		b.addViolation(violationComputeFieldNotAllowed(fieldNode.Name, obj.Name, "must not define arguments", fieldNode.Position))
+		return
+	}
+	retType := field.Type
+	if retType.Kind == TypeExprKindNonNull {
+		retType = retType.OfType
+	}
+	if retType.Kind != TypeExprKindNamed || !b.isScalarType(retType.Named) {
+		b.addViolation(violationComputeFieldNotAllowed(fieldNode.Name, obj.Name, "must return a scalar type", fieldNode.Position))
+		return
+	}
+
+	expr, err := compute.Parse(src)
+	if err != nil {
+		b.addViolation(violationComputeInvalidExpression(fieldNode.Name, err, dir.Position))
+		return
+	}
+	field.ResolveByCompute = &FieldResolveByCompute{Expr: expr.String(), Refs: expr.Refs()}
+}
+
+// handleSourceDirective records `@source(field: "a.b")`. The field is read from the
+// source message by following the path instead of a source field of its own name.
+// The path is validated by checkSourcePaths once every field is resolved.
+func (b *builder) handleSourceDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	var path string
+	var hasFieldArg bool
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "field":
+			hasFieldArg = true
+			path = b.getStringValue(arg.Value)
+		default:
+			b.addViolation(violationUnknownDirectiveArgument("source", arg.Name, arg.Position))
+		}
+	}
+	if !hasFieldArg {
+		b.addViolation(violationMissingFieldArgument(dir.Position))
+		return
+	}
+
+	if b.isRootObject(obj.Name) {
+		b.addViolation(violationSourceFieldNotAllowed(fieldNode.Name, obj.Name, "cannot be declared on a root type", fieldNode.Position))
+		return
+	}
+	if len(field.Args) > 0 {
+		b.addViolation(violationSourceFieldNotAllowed(fieldNode.Name, obj.Name, "must not define arguments", fieldNode.Position))
+		return
+	}
+	for _, seg := range strings.Split(path, ".") {
+		if seg == "" {
+			b.addViolation(violationSourcePathInvalid(path, fieldNode.Name, "must be a dot-separated list of field names", dir.Position))
+			return
+		}
+	}
+	field.ResolveBySource = &FieldResolveBySource{SourceField: path}
+}
+
+// checkSourcePaths validates every @source path: each segment must be a field kept in
+// the source message, intermediate segments must be singular objects, and the final
+// segment must have the type of the projected field.
+func (b *builder) checkSourcePaths() {
+	check := func(nodes []*language.Definition) {
+		for _, node := range nodes {
+			if node.Kind != language.Object {
+				continue
+			}
+			obj := b.Definitions[node.Name].Object
+			for _, fieldNode := range node.Fields {
+				field := obj.Fields[fieldNode.Name]
+				if field.ResolveBySource == nil || field.ResolveBySource.SourceField == field.Name {
+					continue
+				}
+				b.checkSourcePath(obj, field, fieldNode)
+			}
+		}
+	}
+	for _, doc := range b.serviceDocs {
+		check(doc.Definitions)
+		check(doc.Extensions)
+	}
+}
+
+func (b *builder) checkSourcePath(obj *ObjectDefinition, field *FieldDefinition, fieldNode *language.FieldDefinition) {
+	path := field.ResolveBySource.SourceField
+	segments := strings.Split(path, ".")
+	cur := obj
+	nonNull := true
+	var leaf *FieldDefinition
+	for i, seg := range segments {
+		step, ok := cur.Fields[seg]
+		if !ok || step.ResolveBySource == nil || step.ResolveBySource.SourceField != seg {
+			b.addViolation(violationSourcePathInvalid(path, fieldNode.Name, fmt.Sprintf("references %q, which is not a source field of %s", seg, cur.Name), fieldNode.Position))
+			return
+		}
+		nonNull = nonNull && step.Type.Kind == TypeExprKindNonNull
+		if i == len(segments)-1 {
+			leaf = step
+			break
+		}
+		named := step.Type
+		if named.Kind == TypeExprKindNonNull {
+			named = named.OfType
+		}
+		if named.Kind != TypeExprKindNamed || b.Definitions[named.Named].Object == nil {
+			b.addViolation(violationSourcePathInvalid(path, fieldNode.Name, fmt.Sprintf("traverses %q, which is not a singular object field", seg), fieldNode.Position))
+			return
+		}
+		cur = b.Definitions[named.Named].Object
+	}
+
+	// Nullability aside, the projected value must have the declared type; a non-null
+	// field additionally requires every step of the path to be non-null
+	pathType := leaf.Type
+	if !nonNull && pathType.Kind == TypeExprKindNonNull {
+		pathType = pathType.OfType // a nullable step makes the whole path nullable
+	}
+	sameShape := strings.ReplaceAll(pathType.String(), "!", "") == strings.ReplaceAll(field.Type.String(), "!", "")
+	if !sameShape || (field.Type.Kind == TypeExprKindNonNull && pathType.Kind != TypeExprKindNonNull) {
+		b.addViolation(violationSourceTypeMismatch(path, pathType.String(), fieldNode.Name, field.Type.String(), fieldNode.Position))
+	}
+}
+
+// handleConstDirective records `@const(value: ...)`; the field always resolves to the
+// literal without reading the source message, so it may also be declared on root types.
+func (b *builder) handleConstDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition) {
+	value, ok := b.fieldLiteralArgument(field, dir, fieldNode)
+	if !ok {
+		return
+	}
+	field.ResolveByConst = &FieldResolveByConst{Value: value}
+}
+
+// handleDefaultDirective records `@default(value: ...)` on a source-resolved field.
+func (b *builder) handleDefaultDirective(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition, obj *ObjectDefinition) {
+	if field.ResolveBySource == nil {
+		b.addViolation(violationDefaultRequiresSourceField(fieldNode.Name, obj.Name, dir.Position))
+		return
+	}
+	value, ok := b.fieldLiteralArgument(field, dir, fieldNode)
+	if !ok {
+		return
+	}
+	field.ResolveBySource.Default = value
+}
+
+// fieldLiteralArgument reads the `value` argument of @const / @default and checks it
+// against the scalar or enum return type of the field.
+func (b *builder) fieldLiteralArgument(field *FieldDefinition, dir *language.Directive, fieldNode *language.FieldDefinition) (Value, bool) {
+	var valueNode *language.Value
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "value":
+			valueNode = arg.Value
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	if valueNode == nil {
+		b.addViolation(violationMissingValueArgument(dir.Name, dir.Position))
+		return nil, false
+	}
+	if len(field.Args) > 0 {
+		b.addViolation(violationLiteralFieldNotAllowed(dir.Name, fieldNode.Name, "must not define arguments", fieldNode.Position))
+		return nil, false
+	}
+	return b.fieldLiteral(dir.Name, field, fieldNode, valueNode)
+}
+
+// fieldLiteral converts valueNode to a value of the scalar or enum return type of field.
+func (b *builder) fieldLiteral(directiveName string, field *FieldDefinition, fieldNode *language.FieldDefinition, valueNode *language.Value) (Value, bool) {
+	named := field.Type
+	if named.Kind == TypeExprKindNonNull {
+		named = named.OfType
+	}
+	if named.Kind != TypeExprKindNamed || (b.Definitions[named.Named].Scalar == nil && b.Definitions[named.Named].Enum == nil) {
+		b.addViolation(violationLiteralFieldNotAllowed(directiveName, fieldNode.Name, "must return a scalar or enum type", fieldNode.Position))
+		return nil, false
+	}
+	value, ok := b.getLiteralValue(field.Type, valueNode)
+	if !ok {
+		b.addViolation(violationLiteralTypeMismatch(directiveName, valueNode.String(), field.Type.String(), valueNode.Position))
+		return nil, false
+	}
+	return value, true
+}
+
+// checkComputedFieldRefs ensures every field referenced by a @compute expression is a
+// sibling resolved from the source message, so evaluation never needs an RPC.
+func (b *builder) checkComputedFieldRefs() {
+	check := func(nodes []*language.Definition) {
+		for _, node := range nodes {
+			if node.Kind != language.Object {
+				continue
+			}
+			obj := b.Definitions[node.Name].Object
+			for _, fieldNode := range node.Fields {
+				field := obj.Fields[fieldNode.Name]
+				if field.ResolveByCompute == nil {
+					continue
+				}
+				for _, ref := range field.ResolveByCompute.Refs {
+					if sibling, ok := obj.Fields[ref]; !ok || sibling.ResolveBySource == nil {
+						b.addViolation(violationComputeUnknownSourceField(ref, fieldNode.Name, obj.Name, fieldNode.Position))
+					}
+				}
+			}
+		}
+	}
+	for _, doc := range b.serviceDocs {
+		check(doc.Definitions)
+		check(doc.Extensions)
+	}
+}
+
+// isListOfNamed reports whether t is a list of a named type, ignoring Non-Null on
+// either level.
+func isListOfNamed(t *TypeExpr) bool {
+	if t.Kind == TypeExprKindNonNull {
+		t = t.OfType
+	}
+	if t.Kind != TypeExprKindList {
+		return false
+	}
+	t = t.OfType
+	if t.Kind == TypeExprKindNonNull {
+		t = t.OfType
+	}
+	return t.Kind == TypeExprKindNamed
+}
+
 // areTypesAssignableForLoad checks if a source value type can be assigned to a target key type for @load.
 // Current rule: unwrap Non-Null on both sides; both must be NAMED types with identical base names.
 func (b *builder) areTypesAssignableForLoad(src, tgt *TypeExpr) bool {
@@ -130,10 +130,49 @@ func (b *builder) projectArgumentDefinition(index int, node *language.ArgumentDe
 		}
 		def.DefaultValue = defaultValue
 	}
+	for _, dir := range node.Directives {
+		if dir.Name == "metadata" {
+			def.MetadataKey = b.projectMetadataKey(dir)
+		}
+	}
 
 	return def
 }
 
+// projectMetadataKey reads the key of `@metadata(key: "x-tenant-id")`. Keys are
+// lowercase ASCII metadata names; reserved grpc- and binary -bin keys are rejected.
+func (b *builder) projectMetadataKey(dir *language.Directive) string {
+	var key string
+	for _, arg := range dir.Arguments {
+		switch arg.Name {
+		case "key":
+			key = b.getStringValue(arg.Value)
+			if !validMetadataKey(key) {
+				b.addViolation(violationInvalidMetadataKey(key, arg.Position))
+				return ""
+			}
+		default:
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+		}
+	}
+	if dir.Arguments.ForName("key") == nil {
+		b.addViolation(violationMissingKeyArgument(dir.Name, dir.Position))
+	}
+	return key
+}
+
+func validMetadataKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "grpc-") || strings.HasSuffix(key, "-bin") {
+		return false
+	}
+	for _, c := range key {
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *builder) projectInputValueDefinition(index int, node *language.FieldDefinition) *InputValueDefinition {
 	def := &InputValueDefinition{
 		Name:         node.Name,
@@ -0,0 +1,105 @@
+package ir
+
+import (
+	"slices"
+	"sort"
+
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// Directive locations metadata is recorded at. Type definitions use their
+// definition kind, which is spelled like its location.
+const (
+	locationFieldDefinition      = "FIELD_DEFINITION"
+	locationArgumentDefinition   = "ARGUMENT_DEFINITION"
+	locationInputFieldDefinition = "INPUT_FIELD_DEFINITION"
+)
+
+// projectMetadata records a use of a directive declared in SDL into md. It reports
+// false when dir names no declared directive, leaving the caller to reject it.
+// Protograph's own directives are handled by the callers before they get here.
+func (b *builder) projectMetadata(md *Metadata, dir *language.Directive, location string) bool {
+	def := b.Directives[dir.Name]
+	if def == nil {
+		return false
+	}
+	if !slices.Contains(def.Locations, location) {
+		b.addViolation(violationDirectiveLocation(dir.Name, location, dir.Position))
+		return true
+	}
+	if !def.Repeatable && len((*md)[dir.Name]) > 0 {
+		b.addViolation(violationDirectiveNotRepeatable(dir.Name, dir.Position))
+		return true
+	}
+
+	args := make(map[string]Value, len(dir.Arguments))
+	for _, arg := range dir.Arguments {
+		argDef := def.Args[arg.Name]
+		if argDef == nil {
+			b.addViolation(violationUnknownDirectiveArgument(dir.Name, arg.Name, arg.Position))
+			continue
+		}
+		value, ok := b.getInputValue(argDef.Type, arg.Value)
+		if !ok {
+			b.addViolation(violationLiteralTypeMismatch(dir.Name, arg.Value.String(), argDef.Type.String(), arg.Value.Position))
+			continue
+		}
+		args[arg.Name] = value
+	}
+	argDefs := make([]*ArgumentDefinition, 0, len(def.Args))
+	for _, argDef := range def.Args {
+		argDefs = append(argDefs, argDef)
+	}
+	sort.Slice(argDefs, func(i, j int) bool { return argDefs[i].Index < argDefs[j].Index })
+	for _, argDef := range argDefs {
+		if argDef.Type.Kind == TypeExprKindNonNull && argDef.DefaultValue == nil && dir.Arguments.ForName(argDef.Name) == nil {
+			b.addViolation(violationMissingDirectiveArgument(dir.Name, argDef.Name, dir.Position))
+		}
+	}
+
+	if *md == nil {
+		*md = Metadata{}
+	}
+	(*md)[dir.Name] = append((*md)[dir.Name], args)
+	return true
+}
+
+// processTypeMetadata records the directives on an interface, union, enum or input
+// definition, which only carry metadata.
+func (b *builder) processTypeMetadata(md *Metadata, node *language.Definition) {
+	for _, dir := range node.Directives {
+		if !b.projectMetadata(md, dir, string(node.Kind)) {
+			b.addViolation(violationUnknownDirectiveOnType(dir.Name, node.Kind, node.Name, dir.Position))
+		}
+	}
+}
+
+// processArgumentMetadata records the declared directives on the arguments of a
+// field. Other directives there are ignored, as they always were.
+func (b *builder) processArgumentMetadata(field *FieldDefinition, fieldNode *language.FieldDefinition) {
+	for _, argNode := range fieldNode.Arguments {
+		arg := field.Args[argNode.Name]
+		if arg == nil {
+			continue
+		}
+		for _, dir := range argNode.Directives {
+			if dir.Name != "metadata" {
+				b.projectMetadata(&arg.Metadata, dir, locationArgumentDefinition)
+			}
+		}
+	}
+}
+
+// processInputFieldMetadata records the declared directives on the fields of an
+// input definition.
+func (b *builder) processInputFieldMetadata(input *InputDefinition, node *language.Definition) {
+	for _, fieldNode := range node.Fields {
+		v := input.InputValues[fieldNode.Name]
+		if v == nil {
+			continue
+		}
+		for _, dir := range fieldNode.Directives {
+			b.projectMetadata(&v.Metadata, dir, locationInputFieldDefinition)
+		}
+	}
+}
@@ -11,7 +11,7 @@ func (b *builder) processSchemaDefinitions() error {
 				b.addViolation(violationSchemaAlreadyDefined(schemaDef.Position))
 				continue
 			}
-			b.Schema = &Schema{}
+			b.Schema = &Schema{Description: schemaDef.Description}
 			for _, opType := range schemaDef.OperationTypes {
 				switch opType.Operation {
 				case language.Query:
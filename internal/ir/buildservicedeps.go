@@ -71,7 +71,21 @@ func (b *builder) buildServiceDependencies() error {
 			}
 		}
 
-		// 2) Interface/Union owned by this service: depend on all member types' owners
+		// 2) Definitions synthesized by the builder have no SDL node: consider all of their fields
+		for _, defName := range svc.Definitions {
+			if _, ok := b.generated[defName]; !ok {
+				continue
+			}
+			for _, fd := range b.Definitions[defName].Object.Fields {
+				if base := fd.Type.unwrap(); base != "" {
+					if o, ok := owner[base]; ok && o != svc.ID {
+						depSet[o] = struct{}{}
+					}
+				}
+			}
+		}
+
+		// 3) Interface/Union owned by this service: depend on all member types' owners
 		for _, defName := range svc.Definitions {
 			def := b.Definitions[defName]
 			if def.Interface != nil {
@@ -90,7 +104,7 @@ func (b *builder) buildServiceDependencies() error {
 			}
 		}
 
-		// 3) Resolvers/Loaders owned by this service: consider arg and return types
+		// 4) Resolvers/Loaders owned by this service: consider arg and return types
 		for _, rid := range svc.Resolvers {
 			res := b.Resolvers[rid]
 			for _, a := range res.OrderedArgs() {
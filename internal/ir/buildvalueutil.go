@@ -1,6 +1,9 @@
 package ir
 
 import (
+	"strconv"
+	"time"
+
 	language "github.com/hanpama/protograph/internal/language"
 )
 
@@ -36,6 +39,16 @@ func (b *builder) getStringMapValue(node *language.Value) map[string]string {
 	return result
 }
 
+// getDurationValue reads a positive Go duration string such as "30s" from a directive argument.
+func (b *builder) getDurationValue(directiveName string, arg *language.Argument) string {
+	raw := b.getStringValue(arg.Value)
+	if d, err := time.ParseDuration(raw); arg.Value.Kind == language.StringValue && (err != nil || d <= 0) {
+		b.addViolation(violationInvalidDuration(directiveName, arg.Name, raw, arg.Value.Position))
+		return ""
+	}
+	return raw
+}
+
 func (b *builder) getBoolValue(node *language.Value) bool {
 	if node.Kind != language.BooleanValue {
 		b.addViolation(violationExpectedBoolean(node.Position))
@@ -43,3 +56,86 @@ func (b *builder) getBoolValue(node *language.Value) bool {
 	}
 	return node.Raw == "true"
 }
+
+// getLiteralValue converts a literal to the value of a scalar or enum type. Values are
+// kept JSON-native (numbers are float64) so the IR round-trips through its JSON form.
+// It reports false when the literal is not a valid value of typ.
+func (b *builder) getLiteralValue(typ *TypeExpr, node *language.Value) (Value, bool) {
+	if node.Kind == language.NullValue {
+		return nil, typ.Kind != TypeExprKindNonNull
+	}
+	if typ.Kind == TypeExprKindNonNull {
+		typ = typ.OfType
+	}
+	def := b.Definitions[typ.Named]
+	if def.Enum != nil {
+		_, ok := def.Enum.Values[node.Raw]
+		return node.Raw, ok && node.Kind == language.EnumValue
+	}
+	switch typ.Named {
+	case "Int":
+		i, err := strconv.ParseInt(node.Raw, 10, 32)
+		return float64(i), err == nil && node.Kind == language.IntValue
+	case "Float":
+		f, err := strconv.ParseFloat(node.Raw, 64)
+		return f, err == nil && (node.Kind == language.IntValue || node.Kind == language.FloatValue)
+	case "Boolean":
+		return node.Raw == "true", node.Kind == language.BooleanValue
+	case "ID":
+		return node.Raw, node.Kind == language.StringValue || node.Kind == language.IntValue
+	default:
+		return node.Raw, node.Kind == language.StringValue || node.Kind == language.BlockValue
+	}
+}
+
+// getInputValue converts a literal to a value of the input type typ, lists and input
+// objects included, in the JSON-native form of getLiteralValue. A single item is
+// accepted for a list, as in GraphQL input coercion.
+func (b *builder) getInputValue(typ *TypeExpr, node *language.Value) (Value, bool) {
+	if node.Kind == language.NullValue {
+		return nil, typ.Kind != TypeExprKindNonNull
+	}
+	if typ.Kind == TypeExprKindNonNull {
+		typ = typ.OfType
+	}
+	if typ.Kind == TypeExprKindList {
+		if node.Kind != language.ListValue {
+			item, ok := b.getInputValue(typ.OfType, node)
+			return []Value{item}, ok
+		}
+		list := make([]Value, 0, len(node.Children))
+		for _, child := range node.Children {
+			item, ok := b.getInputValue(typ.OfType, child.Value)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, item)
+		}
+		return list, true
+	}
+	input := b.Definitions[typ.Named].Input
+	if input == nil {
+		return b.getLiteralValue(typ, node)
+	}
+	if node.Kind != language.ObjectValue {
+		return nil, false
+	}
+	obj := make(map[string]Value, len(node.Children))
+	for _, child := range node.Children {
+		field := input.InputValues[child.Name]
+		if field == nil {
+			return nil, false
+		}
+		value, ok := b.getInputValue(field.Type, child.Value)
+		if !ok {
+			return nil, false
+		}
+		obj[child.Name] = value
+	}
+	for name, field := range input.InputValues {
+		if _, ok := obj[name]; !ok && field.Type.Kind == TypeExprKindNonNull && field.DefaultValue == nil {
+			return nil, false
+		}
+	}
+	return obj, true
+}
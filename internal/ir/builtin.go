@@ -34,3 +34,17 @@ var IDType = &ScalarDefinition{
 	MappedToProtoType: "string",
 	SpecifiedByURL:    "https://spec.graphql.org/October2021/#sec-ID",
 }
+
+// IDProtoTypes are the proto types ID may be mapped to with
+// `extend scalar ID @mapScalar(toProtobuf: ...)`. A "uuid" is 16 bytes, written as
+// its canonical string in GraphQL.
+var IDProtoTypes = []string{"string", "int64", "uuid"}
+
+// IsBuiltinScalar reports whether name is one of the scalars of the GraphQL spec.
+func IsBuiltinScalar(name string) bool {
+	switch name {
+	case StringType.Name, IntType.Name, FloatType.Name, BooleanType.Name, IDType.Name:
+		return true
+	}
+	return false
+}
@@ -11,6 +11,8 @@ type ServiceMetadata struct {
 	FilePath string
 }
 
+// Discovery lists the services of a project and reads their SDL.
+// ReadServiceSDL is called concurrently.
 type Discovery interface {
 	ListMetadata(ctx context.Context) ([]*ServiceMetadata, error)
 	ReadServiceSDL(ctx context.Context, id ServiceID) (string, error)
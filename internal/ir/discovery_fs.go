@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -14,47 +16,74 @@ type FileSystemDiscovery struct {
 	svcMetas     map[ServiceID]*ServiceMetadata
 }
 
+// LoadOptions selects the SDL files of a project under its root directory. The
+// zero value selects every .graphql file.
+//
+// Patterns are slash-separated paths relative to the root directory. Each
+// element is matched with path.Match and "**" matches any number of
+// directories. Patterns without a slash match the base name at any depth.
+type LoadOptions struct {
+	// Extensions are the extensions of SDL files, e.g. ".graphqls"
+	// (default: ".graphql"). The extension is not part of the service name.
+	Extensions []string
+	// Include, when set, restricts the SDL files to those matching one of the
+	// patterns.
+	Include []string
+	// Exclude skips the files and directories matching one of the patterns,
+	// e.g. "testdata" or "legacy/**/*.graphql".
+	Exclude []string
+	// Files, when set, lists the SDL files of the project relative to the root
+	// directory instead of walking it; the other options are ignored.
+	Files []string
+}
+
 // NewFileSystemDiscovery creates a new FileSystemDiscovery for the given root directory
 func NewFileSystemDiscovery(ctx context.Context, rootDir string, rootPackage string) (*FileSystemDiscovery, error) {
-	if rootPackage == "" {
-		return nil, fmt.Errorf("root package cannot be empty")
+	return NewFileSystemDiscoveryWithOptions(ctx, rootDir, rootPackage, LoadOptions{})
+}
+
+// NewFileSystemDiscoveryWithOptions creates a FileSystemDiscovery for the SDL
+// files of rootDir selected by opts.
+func NewFileSystemDiscoveryWithOptions(ctx context.Context, rootDir string, rootPackage string, opts LoadOptions) (*FileSystemDiscovery, error) {
+	sel, err := newSDLSelector(rootPackage, opts)
+	if err != nil {
+		return nil, err
 	}
 	discovery := &FileSystemDiscovery{
 		svcFilePaths: make(map[string]string),
 		svcMetas:     make(map[ServiceID]*ServiceMetadata),
 	}
 
-	err := filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+	if len(opts.Files) > 0 {
+		for _, file := range opts.Files {
+			fp := filepath.Join(rootDir, filepath.FromSlash(file))
+			if _, err := os.Stat(fp); err != nil {
+				return nil, fmt.Errorf("failed to stat SDL file %q: %w", file, err)
+			}
+			if err := discovery.add(rootDir, rootPackage, fp, filepath.Ext(fp)); err != nil {
+				return nil, err
+			}
+		}
+		return discovery, nil
+	}
+
+	err = filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if d.IsDir() {
-			return nil
-		}
-		if filepath.Ext(d.Name()) != ".graphql" {
-			return nil
-		}
-
 		relPath, err := filepath.Rel(rootDir, path)
 		if err != nil {
 			return fmt.Errorf("failed to get relative path for %q: %w", path, err)
 		}
-
-		pkgPath := filepath.Dir(relPath)
-		pkgParts := strings.Split(rootPackage, ".")
-		if pkgPath != "." {
-			pkgParts = append(pkgParts, filepath.SplitList(pkgPath)...)
+		rel := filepath.ToSlash(relPath)
+		if d.IsDir() {
+			if rel != "." && sel.skips(rel) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-
-		svcName := strings.TrimSuffix(d.Name(), ".graphql")
-		svcID := ServiceID(svcName)
-
-		discovery.svcFilePaths[string(svcID)] = path
-		discovery.svcMetas[svcID] = &ServiceMetadata{
-			ID:       svcID,
-			Name:     svcName,
-			PkgPath:  pkgParts,
-			FilePath: relPath,
+		if ext, ok := sel.selects(rel); ok {
+			return discovery.add(rootDir, rootPackage, path, ext)
 		}
 		return nil
 	})
@@ -64,6 +93,121 @@ func NewFileSystemDiscovery(ctx context.Context, rootDir string, rootPackage str
 	return discovery, nil
 }
 
+// add registers the SDL file at path, named after the file without ext.
+func (d *FileSystemDiscovery) add(rootDir, rootPackage, path, ext string) error {
+	relPath, err := filepath.Rel(rootDir, path)
+	if err != nil {
+		return fmt.Errorf("failed to get relative path for %q: %w", path, err)
+	}
+
+	meta := newServiceMetadata(rootPackage, relPath, ext)
+	if prev, ok := d.svcMetas[meta.ID]; ok {
+		return fmt.Errorf("service %q is defined by both %q and %q", meta.Name, prev.FilePath, relPath)
+	}
+	d.svcFilePaths[string(meta.ID)] = path
+	d.svcMetas[meta.ID] = meta
+	return nil
+}
+
+// newServiceMetadata describes the SDL file at relPath, relative to the root
+// of the project, named after the file without ext.
+func newServiceMetadata(rootPackage, relPath, ext string) *ServiceMetadata {
+	pkgPath := filepath.Dir(relPath)
+	pkgParts := strings.Split(rootPackage, ".")
+	if pkgPath != "." {
+		pkgParts = append(pkgParts, filepath.SplitList(pkgPath)...)
+	}
+	svcName := strings.TrimSuffix(filepath.Base(relPath), ext)
+	return &ServiceMetadata{
+		ID:       ServiceID(svcName),
+		Name:     svcName,
+		PkgPath:  pkgParts,
+		FilePath: relPath,
+	}
+}
+
+// sdlSelector selects the SDL files of a project by their slash-separated
+// paths relative to its root, as LoadOptions describe.
+type sdlSelector struct {
+	opts LoadOptions
+	exts []string
+}
+
+func newSDLSelector(rootPackage string, opts LoadOptions) (sdlSelector, error) {
+	if rootPackage == "" {
+		return sdlSelector{}, fmt.Errorf("root package cannot be empty")
+	}
+	for _, pattern := range append(append([]string(nil), opts.Include...), opts.Exclude...) {
+		for _, elem := range strings.Split(pattern, "/") {
+			if _, err := path.Match(elem, ""); err != nil {
+				return sdlSelector{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	exts := opts.Extensions
+	if len(exts) == 0 {
+		exts = []string{".graphql"}
+	}
+	return sdlSelector{opts: opts, exts: exts}, nil
+}
+
+// skips reports whether the directory or file at rel is excluded.
+func (s sdlSelector) skips(rel string) bool {
+	return matchAny(s.opts.Exclude, rel)
+}
+
+// selects reports whether the file at rel is an SDL file of the project, and
+// returns its extension.
+func (s sdlSelector) selects(rel string) (string, bool) {
+	ext := path.Ext(rel)
+	if s.skips(rel) || !slices.Contains(s.exts, ext) {
+		return "", false
+	}
+	if len(s.opts.Include) > 0 && !matchAny(s.opts.Include, rel) {
+		return "", false
+	}
+	return ext, true
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether the slash-separated rel matches pattern.
+// Patterns are validated by newSDLSelector.
+func matchGlob(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchElems(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchElems(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
 // ListPackages returns the list of packages discovered in the filesystem
 func (d *FileSystemDiscovery) ListMetadata(ctx context.Context) ([]*ServiceMetadata, error) {
 	pkgs := make([]*ServiceMetadata, 0, len(d.svcMetas))
@@ -94,3 +238,12 @@ func Load(rootDir string, rootPackage string) (*Project, error) {
 	}
 	return Build(context.Background(), discovery)
 }
+
+// LoadWithOptions is Load for the SDL files of rootDir selected by opts.
+func LoadWithOptions(rootDir string, rootPackage string, opts LoadOptions) (*Project, error) {
+	discovery, err := NewFileSystemDiscoveryWithOptions(context.Background(), rootDir, rootPackage, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Build(context.Background(), discovery)
+}
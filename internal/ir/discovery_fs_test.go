@@ -0,0 +1,112 @@
+package ir_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/ir"
+)
+
+func writeSDLFiles(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		fp := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fp, []byte("type Query { hello: String }\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func discoveredFiles(t *testing.T, root string, opts ir.LoadOptions) []string {
+	t.Helper()
+	disc, err := ir.NewFileSystemDiscoveryWithOptions(t.Context(), root, "app", opts)
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	metas, err := disc.ListMetadata(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, m := range metas {
+		out = append(out, m.Name+"="+filepath.ToSlash(m.FilePath))
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestFileSystemDiscovery_Options(t *testing.T) {
+	root := writeSDLFiles(t,
+		"users.graphql",
+		"orders.graphqls",
+		"notes.txt",
+		"testdata/fixture.graphql",
+		"billing/testdata/invoice.graphqls",
+		"billing/payments.graphqls",
+		"legacy/v1/old.graphql",
+	)
+
+	for _, tc := range []struct {
+		name string
+		opts ir.LoadOptions
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{"fixture=testdata/fixture.graphql", "old=legacy/v1/old.graphql", "users=users.graphql"},
+		},
+		{
+			name: "extensions and exclude by name",
+			opts: ir.LoadOptions{Extensions: []string{".graphql", ".graphqls"}, Exclude: []string{"testdata"}},
+			want: []string{"old=legacy/v1/old.graphql", "orders=orders.graphqls", "payments=billing/payments.graphqls", "users=users.graphql"},
+		},
+		{
+			name: "include and exclude with double star",
+			opts: ir.LoadOptions{Extensions: []string{".graphqls"}, Include: []string{"billing/**"}, Exclude: []string{"**/testdata/**"}},
+			want: []string{"payments=billing/payments.graphqls"},
+		},
+		{
+			name: "exclude path",
+			opts: ir.LoadOptions{Exclude: []string{"legacy/*/*.graphql", "*/fixture.graphql"}},
+			want: []string{"users=users.graphql"},
+		},
+		{
+			name: "explicit files",
+			opts: ir.LoadOptions{Files: []string{"orders.graphqls", "legacy/v1/old.graphql"}, Exclude: []string{"legacy"}},
+			want: []string{"old=legacy/v1/old.graphql", "orders=orders.graphqls"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, discoveredFiles(t, root, tc.opts)); diff != "" {
+				t.Fatalf("files (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileSystemDiscovery_Errors(t *testing.T) {
+	root := writeSDLFiles(t, "users.graphql", "v2/users.graphqls")
+
+	for _, tc := range []struct {
+		opts ir.LoadOptions
+		want string
+	}{
+		{ir.LoadOptions{Extensions: []string{".graphql", ".graphqls"}}, "defined by both"},
+		{ir.LoadOptions{Exclude: []string{"[a-"}}, "invalid pattern"},
+		{ir.LoadOptions{Files: []string{"missing.graphql"}}, "missing.graphql"},
+	} {
+		_, err := ir.NewFileSystemDiscoveryWithOptions(t.Context(), root, "app", tc.opts)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: err = %v, want %q", tc.opts, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package ir
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FSDiscovery implements Discovery for the SDL files of an fs.FS, such as an
+// embed.FS shipping the schema in the gateway binary, or of an archive.
+// Selection and naming follow FileSystemDiscovery, with paths relative to the
+// root of the file system or archive.
+type FSDiscovery struct {
+	read     func(name string) ([]byte, error)
+	svcPaths map[ServiceID]string
+	svcMetas map[ServiceID]*ServiceMetadata
+}
+
+// NewFSDiscovery creates an FSDiscovery for the SDL files of fsys selected by
+// opts.
+func NewFSDiscovery(fsys fs.FS, rootPackage string, opts LoadOptions) (*FSDiscovery, error) {
+	sel, err := newSDLSelector(rootPackage, opts)
+	if err != nil {
+		return nil, err
+	}
+	d := newFSDiscovery(func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) })
+	if len(opts.Files) > 0 {
+		for _, file := range opts.Files {
+			if _, err := fs.Stat(fsys, file); err != nil {
+				return nil, fmt.Errorf("failed to stat SDL file %q: %w", file, err)
+			}
+			if err := d.add(rootPackage, file, path.Ext(file)); err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	}
+	err = fs.WalkDir(fsys, ".", func(name string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if name != "." && sel.skips(name) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ext, ok := sel.selects(name); ok {
+			return d.add(rootPackage, name, ext)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk SDL files: %w", err)
+	}
+	return d, nil
+}
+
+// NewArchiveDiscovery creates an FSDiscovery for the SDL files of the tar
+// archive read from r, gzip-compressed or not, selected by opts. The archive
+// is read into memory.
+func NewArchiveDiscovery(r io.Reader, rootPackage string, opts LoadOptions) (*FSDiscovery, error) {
+	sel, err := newSDLSelector(rootPackage, opts)
+	if err != nil {
+		return nil, err
+	}
+	files, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	d := newFSDiscovery(func(name string) ([]byte, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return content, nil
+	})
+	if len(opts.Files) > 0 {
+		for _, file := range opts.Files {
+			if _, ok := files[path.Clean(file)]; !ok {
+				return nil, fmt.Errorf("SDL file %q not found in archive", file)
+			}
+			if err := d.add(rootPackage, path.Clean(file), path.Ext(file)); err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if skipsDir(sel, path.Dir(name)) {
+			continue
+		}
+		if ext, ok := sel.selects(name); ok {
+			if err := d.add(rootPackage, name, ext); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
+
+// NewURLDiscovery fetches a tar archive of SDL files, such as a snapshot of a
+// schema registry, from url with the given request headers and reads it like
+// NewArchiveDiscovery.
+func NewURLDiscovery(ctx context.Context, url string, header http.Header, rootPackage string, opts LoadOptions) (*FSDiscovery, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch schema archive: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("fetch schema archive %s: %s", url, resp.Status)
+	}
+	d, err := NewArchiveDiscovery(resp.Body, rootPackage, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	return d, nil
+}
+
+// readArchive returns the regular files of a tar archive by their cleaned,
+// slash-separated paths.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	br := bufio.NewReader(r)
+	r = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("read archive: invalid path %q", hdr.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read archive %s: %w", hdr.Name, err)
+		}
+		files[name] = content
+	}
+}
+
+// skipsDir reports whether dir or one of its parents is excluded.
+func skipsDir(sel sdlSelector, dir string) bool {
+	for ; dir != "."; dir = path.Dir(dir) {
+		if sel.skips(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func newFSDiscovery(read func(name string) ([]byte, error)) *FSDiscovery {
+	return &FSDiscovery{
+		read:     read,
+		svcPaths: make(map[ServiceID]string),
+		svcMetas: make(map[ServiceID]*ServiceMetadata),
+	}
+}
+
+// add registers the SDL file at the slash-separated name.
+func (d *FSDiscovery) add(rootPackage, name, ext string) error {
+	meta := newServiceMetadata(rootPackage, filepath.FromSlash(name), ext)
+	if prev, ok := d.svcMetas[meta.ID]; ok {
+		return fmt.Errorf("service %q is defined by both %q and %q", meta.Name, prev.FilePath, meta.FilePath)
+	}
+	d.svcPaths[meta.ID] = name
+	d.svcMetas[meta.ID] = meta
+	return nil
+}
+
+// ListMetadata implements Discovery interface
+func (d *FSDiscovery) ListMetadata(ctx context.Context) ([]*ServiceMetadata, error) {
+	metas := make([]*ServiceMetadata, 0, len(d.svcMetas))
+	for _, meta := range d.svcMetas {
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+// ReadServiceSDL implements Discovery interface
+func (d *FSDiscovery) ReadServiceSDL(ctx context.Context, serviceID ServiceID) (string, error) {
+	name, ok := d.svcPaths[serviceID]
+	if !ok {
+		return "", fmt.Errorf("service %q not found", serviceID)
+	}
+	content, err := d.read(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service SDL for %q: %w", serviceID, err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,124 @@
+package ir_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hanpama/protograph/internal/ir"
+)
+
+func listedFiles(t *testing.T, disc ir.Discovery) []string {
+	t.Helper()
+	metas, err := disc.ListMetadata(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, m := range metas {
+		sdl, err := disc.ReadServiceSDL(t.Context(), m.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m.Name+"="+filepath.ToSlash(m.FilePath)+" "+strings.Join(m.PkgPath, ".")+" "+strings.TrimSpace(sdl))
+	}
+	slices.Sort(out)
+	return out
+}
+
+// tarball archives the files under root, gzip-compressed when compress is set.
+func tarball(t *testing.T, root string, compress bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	}
+	if err := tw.AddFS(os.DirFS(root)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestFSDiscovery_MatchesFileSystem(t *testing.T) {
+	root := writeSDLFiles(t,
+		"users.graphql",
+		"orders.graphqls",
+		"notes.txt",
+		"testdata/fixture.graphql",
+		"billing/testdata/invoice.graphqls",
+		"billing/payments.graphqls",
+		"legacy/v1/old.graphql",
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write(tarball(t, root, true))
+	}))
+	defer srv.Close()
+
+	for _, opts := range []ir.LoadOptions{
+		{},
+		{Extensions: []string{".graphql", ".graphqls"}, Exclude: []string{"testdata"}},
+		{Extensions: []string{".graphqls"}, Include: []string{"billing/**"}, Exclude: []string{"**/testdata/**"}},
+		{Exclude: []string{"legacy/*/*.graphql", "*/fixture.graphql"}},
+		{Files: []string{"orders.graphqls", "legacy/v1/old.graphql"}, Exclude: []string{"legacy"}},
+	} {
+		dir, err := ir.NewFileSystemDiscoveryWithOptions(t.Context(), root, "app", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := listedFiles(t, dir)
+
+		fsys, err := ir.NewFSDiscovery(os.DirFS(root), "app", opts)
+		if err != nil {
+			t.Fatalf("%+v: fs: %v", opts, err)
+		}
+		if diff := cmp.Diff(want, listedFiles(t, fsys)); diff != "" {
+			t.Errorf("%+v: fs (-want +got):\n%s", opts, diff)
+		}
+		for _, compress := range []bool{false, true} {
+			archive, err := ir.NewArchiveDiscovery(bytes.NewReader(tarball(t, root, compress)), "app", opts)
+			if err != nil {
+				t.Fatalf("%+v: archive: %v", opts, err)
+			}
+			if diff := cmp.Diff(want, listedFiles(t, archive)); diff != "" {
+				t.Errorf("%+v: archive (-want +got):\n%s", opts, diff)
+			}
+		}
+		remote, err := ir.NewURLDiscovery(t.Context(), srv.URL, http.Header{"Authorization": {"Bearer t"}}, "app", opts)
+		if err != nil {
+			t.Fatalf("%+v: url: %v", opts, err)
+		}
+		if diff := cmp.Diff(want, listedFiles(t, remote)); diff != "" {
+			t.Errorf("%+v: url (-want +got):\n%s", opts, diff)
+		}
+	}
+
+	if _, err := ir.NewURLDiscovery(t.Context(), srv.URL, nil, "app", ir.LoadOptions{}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("unauthorized fetch: %v", err)
+	}
+	if _, err := ir.NewArchiveDiscovery(strings.NewReader("not a tarball"), "app", ir.LoadOptions{}); err == nil {
+		t.Errorf("invalid archive read")
+	}
+}
@@ -41,6 +41,28 @@ func TestGoodSnapshot(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:     "loader_keyed",
+			snapshot: "testdata/good/loader_keyed.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/loader_keyed.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "loader_list",
+			snapshot: "testdata/good/loader_list.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/loader_list.graphql"),
+				},
+			}),
+		},
 		{
 			name:     "loader_default_ids",
 			snapshot: "testdata/good/loader_default_ids.json",
@@ -96,6 +118,17 @@ func TestGoodSnapshot(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:     "resolve_fallback",
+			snapshot: "testdata/good/resolve_fallback.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/resolve_fallback.graphql"),
+				},
+			}),
+		},
 		{
 			name:     "map_scalar",
 			snapshot: "testdata/good/map_scalar.json",
@@ -107,6 +140,105 @@ func TestGoodSnapshot(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:     "map_id",
+			snapshot: "testdata/good/map_id.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/map_id.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "schema_metadata",
+			snapshot: "testdata/good/schema_metadata.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/schema_metadata.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "annotations",
+			snapshot: "testdata/good/annotations.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/annotations.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "with_expressions",
+			snapshot: "testdata/good/with_expressions.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/with_expressions.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "data_path",
+			snapshot: "testdata/good/data_path.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/data_path.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "envelope_any",
+			snapshot: "testdata/good/envelope_any.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/envelope_any.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "discriminator",
+			snapshot: "testdata/good/discriminator.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/discriminator.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "on_unknown",
+			snapshot: "testdata/good/on_unknown.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/on_unknown.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "map_value",
+			snapshot: "testdata/good/map_value.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/map_value.graphql"),
+				},
+			}),
+		},
 		{
 			name:     "mutation",
 			snapshot: "testdata/good/mutation.json",
@@ -129,6 +261,138 @@ func TestGoodSnapshot(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name:     "connection",
+			snapshot: "testdata/good/connection.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/connection.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "node",
+			snapshot: "testdata/good/node.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/node.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "compute",
+			snapshot: "testdata/good/compute.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/compute.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "const_default",
+			snapshot: "testdata/good/const_default.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/const_default.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "cache",
+			snapshot: "testdata/good/cache.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/cache.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "priority",
+			snapshot: "testdata/good/priority.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/priority.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "timeout",
+			snapshot: "testdata/good/timeout.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/timeout.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "sla",
+			snapshot: "testdata/good/sla.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/sla.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "optional",
+			snapshot: "testdata/good/optional.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/optional.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "metadata",
+			snapshot: "testdata/good/metadata.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/metadata.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "on_error",
+			snapshot: "testdata/good/on_error.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/on_error.graphql"),
+				},
+			}),
+		},
+		{
+			name:     "source_path",
+			snapshot: "testdata/good/source_path.json",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/source_path.graphql"),
+				},
+			}),
+		},
 		{
 			name:     "types",
 			snapshot: "testdata/good/deps.json",
@@ -204,6 +468,105 @@ func TestBadSnapshot(t *testing.T) {
 			}),
 			wantErr: "has no @id fields or 'id' field",
 		},
+		{
+			name: "loader_keyed_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/loader_keyed_errors.graphql"),
+				},
+			}),
+			wantErr: "@loader 'keyed' requires 'batch'",
+		},
+		{
+			name: "loader_keyed_key_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/loader_keyed_key_errors.graphql"),
+				},
+			}),
+			wantErr: "@loader 'keyed' key 'tags' on type User must be a single scalar",
+		},
+		{
+			name: "loader_list_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/loader_list_errors.graphql"),
+				},
+			}),
+			wantErr: "@load field posts is a list; loader 'Post:authorId' must be declared with list: true",
+		},
+		{
+			name: "data_path_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/data_path_errors.graphql"),
+				},
+			}),
+			wantErr: `@resolve data path "result..user" must be dot-separated field names`,
+		},
+		{
+			name: "envelope_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/envelope_errors.graphql"),
+				},
+			}),
+			wantErr: "@envelope kind JSON is not one of ANY or DEFAULT",
+		},
+		{
+			name: "discriminator_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/discriminator_errors.graphql"),
+				},
+			}),
+			wantErr: "@discriminator members Post and Video declare source field title with different types",
+		},
+		{
+			name: "on_unknown_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/on_unknown_errors.graphql"),
+				},
+			}),
+			wantErr: "@onUnknown value LOST is not a value of enum Status",
+		},
+		{
+			name: "map_value_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/map_value_errors.graphql"),
+				},
+			}),
+			wantErr: `@mapValue toProtobuf "ACTIVE" is used by both ACTIVE and ENABLED`,
+		},
+		{
+			name: "map_id_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/map_id_errors.graphql"),
+				},
+			}),
+			wantErr: "@mapScalar cannot map ID to double; use one of string, int64, uuid",
+		},
 		{
 			name: "load_type_mismatch",
 			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
@@ -226,6 +589,44 @@ func TestBadSnapshot(t *testing.T) {
 			}),
 			wantErr: "references unknown parent field",
 		},
+		{
+			name: "resolve_fallback_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/resolve_fallback_errors.graphql"),
+				},
+			}),
+			wantErr: "@resolve fallback TestService repeats a service of the chain",
+		},
+		{
+			name: "resolve_fallback_mutation",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/resolve_fallback_mutation.graphql"),
+				},
+			}),
+			wantErr: "@resolve fallback is not allowed on mutation field Mutation.placeOrder",
+		},
+		{
+			name: "resolve_fallback_service_conflict",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/good/resolve_fallback.graphql"),
+				},
+				{
+					Package: "testpackage",
+					Name:    "PricePrimary",
+					Content: "type Price { amount: Int! }",
+				},
+			}),
+			wantErr: "@resolve fallback PricePrimary is already taken by service PricePrimary of its package",
+		},
 		{
 			name: "resolve_errors",
 			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
@@ -292,6 +693,193 @@ func TestBadSnapshot(t *testing.T) {
 			}),
 			wantErr: "must also implement interface",
 		},
+		{
+			name: "connection_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/connection_errors.graphql"),
+				},
+			}),
+			wantErr: "must return a list of named types",
+		},
+		{
+			name: "connection_page_arg_conflict",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/connection_page_arg_conflict.graphql"),
+				},
+			}),
+			wantErr: `Argument "offset" in field "posts" conflicts with @connection pagination argument`,
+		},
+		{
+			name: "node_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/node_errors.graphql"),
+				},
+			}),
+			wantErr: "has no @loader keyed by 'id' for @node",
+		},
+		{
+			name: "compute_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/compute_errors.graphql"),
+				},
+			}),
+			wantErr: `references "posts", which is not a source field of User`,
+		},
+		{
+			name: "const_default_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/const_default_errors.graphql"),
+				},
+			}),
+			wantErr: "@const value 2 is not a valid String!",
+		},
+		{
+			name: "source_path_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/source_path_errors.graphql"),
+				},
+			}),
+			wantErr: `@source path "address.country" of field "country" references "country", which is not a source field of Address`,
+		},
+		{
+			name: "cache_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/cache_errors.graphql"),
+				},
+			}),
+			wantErr: "@cache ttl \"soon\" is not a positive duration such as \"30s\"",
+		},
+		{
+			name: "priority_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/priority_errors.graphql"),
+				},
+			}),
+			wantErr: "@priority level URGENT is not one of HIGH, NORMAL or LOW",
+		},
+		{
+			name: "timeout_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/timeout_errors.graphql"),
+				},
+			}),
+			wantErr: "@timeout ms 0 is not a positive number of milliseconds",
+		},
+		{
+			name: "sla_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/sla_errors.graphql"),
+				},
+			}),
+			wantErr: "@sla ms -5 is not a positive number of milliseconds",
+		},
+		{
+			name: "optional_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/optional_errors.graphql"),
+				},
+			}),
+			wantErr: "@optional field 'nickname' on type User must be Non-Null",
+		},
+		{
+			name: "optional_interface_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/optional_interface_errors.graphql"),
+				},
+			}),
+			wantErr: "@optional field 'rating' on type User is published nullable, but interface Rated declares it Non-Null",
+		},
+		{
+			name: "metadata_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/metadata_errors.graphql"),
+				},
+			}),
+			wantErr: `@metadata key "X-Tenant" must be lowercase letters`,
+		},
+		{
+			name: "annotation_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/annotation_errors.graphql"),
+				},
+			}),
+			wantErr: "Directive @tag may not be used on FIELD_DEFINITION",
+		},
+		{
+			name: "with_expression_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/with_expression_errors.graphql"),
+				},
+			}),
+			wantErr: "@load mapping references unknown parent field 'teamId' on type Membership",
+		},
+		{
+			name: "on_error_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/on_error_errors.graphql"),
+				},
+			}),
+			wantErr: "@onError on field \"name\" of User requires a field resolved by a resolver, loader or @node",
+		},
+		{
+			name: "specified_by_errors",
+			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{
+					Package: "testpackage",
+					Name:    "TestService",
+					Content: mustReadData("testdata/bad/specified_by_errors.graphql"),
+				},
+			}),
+			wantErr: "Directive @specifiedBy requires 'url' parameter",
+		},
 		{
 			name: "cyclic_services",
 			discovery: ir.NewInMemoryDiscovery([]ir.InMemoryService{
@@ -15,6 +15,7 @@ type Project struct {
 }
 
 type Schema struct {
+	Description      string `json:"description,omitempty"`
 	QueryType        string `json:"queryType,omitempty"`
 	MutationType     string `json:"mutationType,omitempty"`
 	SubscriptionType string `json:"subscriptionType,omitempty"`
@@ -52,6 +53,7 @@ type ObjectDefinition struct {
 	Fields      map[string]*FieldDefinition `json:"fields"`
 	Interfaces  map[string]*InterfaceImpl   `json:"interfaces"`
 	IDFields    []string                    `json:"idFields"`
+	Metadata    Metadata                    `json:"metadata,omitempty"`
 }
 
 type InterfaceDefinition struct {
@@ -60,14 +62,37 @@ type InterfaceDefinition struct {
 	Fields        map[string]*FieldDefinition `json:"fields"`
 	Interfaces    map[string]*InterfaceImpl   `json:"interfaces"`
 	PossibleTypes []string                    `json:"possibleTypes"`
+	Envelope      Envelope                    `json:"envelope,omitempty"`
+	Metadata      Metadata                    `json:"metadata,omitempty"`
 }
 
 type UnionDefinition struct {
-	Name        string                          `json:"name"`
-	Description string                          `json:"description,omitempty"`
-	Types       map[string]*UnionTypeDefinition `json:"types"`
+	Name          string                          `json:"name"`
+	Description   string                          `json:"description,omitempty"`
+	Types         map[string]*UnionTypeDefinition `json:"types"`
+	Envelope      Envelope                        `json:"envelope,omitempty"`
+	Discriminator *Discriminator                  `json:"discriminator,omitempty"`
+	Metadata      Metadata                        `json:"metadata,omitempty"`
+}
+
+// Discriminator resolves the members of a union from a scalar field of one shared
+// message instead of an envelope (@discriminator). The message holds the field and
+// the source fields of every member.
+type Discriminator struct {
+	Field  string            `json:"field"`
+	Values map[string]string `json:"values"` // member -> field value, the member name by default
 }
 
+// Envelope is how values of an interface or union travel in protobuf (@envelope).
+// By default interfaces use a typename and payload bytes, and unions a oneof of
+// their members.
+type Envelope string
+
+const (
+	// EnvelopeAny carries values as google.protobuf.Any, the concrete type named by its type URL
+	EnvelopeAny Envelope = "ANY"
+)
+
 type UnionTypeDefinition struct {
 	Name  string `json:"name"`
 	Index int    `json:"index"`
@@ -78,26 +103,47 @@ type InputDefinition struct {
 	Description string                           `json:"description,omitempty"`
 	InputValues map[string]*InputValueDefinition `json:"inputValues"`
 	OneOf       bool                             `json:"oneOf,omitempty"`
+	Metadata    Metadata                         `json:"metadata,omitempty"`
 }
 
 type EnumDefinition struct {
 	Name        string                          `json:"name"`
 	Description string                          `json:"description,omitempty"`
 	Values      map[string]*EnumValueDefinition `json:"values"`
+	OnUnknown   *UnknownEnumPolicy              `json:"onUnknown,omitempty"`
+	Metadata    Metadata                        `json:"metadata,omitempty"`
+}
+
+// UnknownEnumPolicy decides how enum numbers a backend returns but the schema does
+// not declare are resolved (@onUnknown). Without one they pass through as numbers.
+type UnknownEnumPolicy struct {
+	Action UnknownEnumAction `json:"action"`
+	Value  string            `json:"value,omitempty"` // the enum value of VALUE
 }
 
+type UnknownEnumAction string
+
+const (
+	UnknownEnumActionNull   UnknownEnumAction = "NULL"   // resolve to null with a warning in the response extensions
+	UnknownEnumActionString UnknownEnumAction = "STRING" // pass the number through as a string
+	UnknownEnumActionValue  UnknownEnumAction = "VALUE"  // resolve to Value
+)
+
 type EnumValueDefinition struct {
 	Name        string       `json:"name"`
 	Description string       `json:"description,omitempty"`
 	Index       int          `json:"index"`
 	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// ProtoName names the proto enum value (@mapValue) in place of <ENUM>_<NAME>
+	ProtoName string `json:"protoName,omitempty"`
 }
 
 type ScalarDefinition struct {
-	Name              string `json:"name"`
-	Description       string `json:"description,omitempty"`
-	MappedToProtoType string `json:"mappedToProtoType,omitempty"`
-	SpecifiedByURL    string `json:"specifiedByURL,omitempty"`
+	Name              string   `json:"name"`
+	Description       string   `json:"description,omitempty"`
+	MappedToProtoType string   `json:"mappedToProtoType,omitempty"`
+	SpecifiedByURL    string   `json:"specifiedByURL,omitempty"`
+	Metadata          Metadata `json:"metadata,omitempty"`
 }
 
 type DirectiveDefinition struct {
@@ -119,17 +165,34 @@ type FieldDefinition struct {
 	Index             int                            `json:"index"`
 	Args              map[string]*ArgumentDefinition `json:"args"`
 	Type              *TypeExpr                      `json:"fieldType"`
+	ConnectionOf      *TypeExpr                      `json:"connectionOf,omitempty"` // @connection: list type returned by the resolver, paged into Type at runtime
 	IsInternal        bool                           `json:"isInternal,omitempty"`
+	IsOptional        bool                           `json:"isOptional,omitempty"` // @optional: null without bubbling when it fails
 	Deprecation       *Deprecation                   `json:"deprecation,omitempty"`
 	ResolveBySource   *FieldResolveBySource          `json:"bySource,omitempty"`
 	ResolveByResolver *FieldResolveByResolver        `json:"byResolver,omitempty"`
 	ResolveByLoader   *FieldResolveByLoader          `json:"byLoader,omitempty"`
+	ResolveByNode     *FieldResolveByNode            `json:"byNode,omitempty"`
+	ResolveByCompute  *FieldResolveByCompute         `json:"byCompute,omitempty"`
+	ResolveByConst    *FieldResolveByConst           `json:"byConst,omitempty"`
+	OnError           *ErrorPolicy                   `json:"onError,omitempty"`
+	Cache             *CachePolicy                   `json:"cache,omitempty"`
+	Priority          Priority                       `json:"priority,omitempty"`
+	TimeoutMs         int                            `json:"timeoutMs,omitempty"`
+	SLAMs             int                            `json:"slaMs,omitempty"`
+	Metadata          Metadata                       `json:"metadata,omitempty"`
 }
 
 type FieldResolveBySource struct {
+	// SourceField is the field name in the source message, or a dot-separated path
+	// through nested source messages when declared with @source
 	SourceField string `json:"sourceField"`
+	Default     Value  `json:"default,omitempty"` // served when the source field is unset (@default)
 }
 
+// With mappings copy parent fields into the request of a resolver or loader, keyed by
+// request field. A value names a parent field, or is an expression over parent fields
+// in @compute syntax, e.g. `orgId + ":" + userId`, for keys composed of several.
 type FieldResolveByResolver struct {
 	ResolverID ResolverID        `json:"resolverId"`
 	With       map[string]string `json:"with"`
@@ -140,6 +203,64 @@ type FieldResolveByLoader struct {
 	With     map[string]string `json:"with"`
 }
 
+// IsWithExpression reports whether a With mapping value is an expression rather than
+// the name of a parent field.
+func IsWithExpression(value string) bool {
+	for i, c := range value {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			return true
+		}
+	}
+	return value == ""
+}
+
+// FieldResolveByNode routes a global ID to the `id` loader of the concrete type it encodes.
+type FieldResolveByNode struct {
+	Interface string              `json:"interface"`
+	Loaders   map[string]LoaderID `json:"loaders"` // concrete type -> loader keyed by id
+}
+
+// FieldResolveByCompute evaluates a pure expression over sibling source fields.
+type FieldResolveByCompute struct {
+	Expr string   `json:"expr"`
+	Refs []string `json:"refs"` // sibling fields referenced by Expr, sorted
+}
+
+// FieldResolveByConst serves a literal value declared in SDL (@const).
+type FieldResolveByConst struct {
+	Value Value `json:"value"`
+}
+
+// ErrorPolicy decides what clients see when the RPC resolving a field fails (@onError).
+type ErrorPolicy struct {
+	Action ErrorAction `json:"action"`
+	Value  Value       `json:"value,omitempty"` // served instead of the error when Action is DEFAULT
+}
+
+type ErrorAction string
+
+const (
+	ErrorActionPropagate ErrorAction = "PROPAGATE" // report the backend error verbatim
+	ErrorActionNull      ErrorAction = "NULL"      // resolve to null with a generic message
+	ErrorActionDefault   ErrorAction = "DEFAULT"   // resolve to Value without an error
+)
+
+// CachePolicy caches the results of a field resolved over RPC (@cache). Durations are
+// kept as written in SDL, e.g. "30s"; they are validated by the builder.
+type CachePolicy struct {
+	TTL                  string `json:"ttl"`
+	StaleWhileRevalidate string `json:"staleWhileRevalidate,omitempty"`
+}
+
+// Priority orders the RPCs of a field among the others of its depth (@priority).
+// Fields without one have normal priority.
+type Priority string
+
+const (
+	PriorityHigh Priority = "HIGH" // called before the other calls of the depth
+	PriorityLow  Priority = "LOW"  // called once the other calls of the depth are done
+)
+
 type ArgumentDefinition struct {
 	Name         string       `json:"name"`
 	Description  string       `json:"description,omitempty"`
@@ -147,6 +268,10 @@ type ArgumentDefinition struct {
 	DefaultValue Value        `json:"defaultValue,omitempty"`
 	Type         *TypeExpr    `json:"type"`
 	Deprecation  *Deprecation `json:"deprecation,omitempty"`
+	// MetadataKey is the gRPC metadata key the argument is sent under instead of
+	// a request field (@metadata)
+	MetadataKey string   `json:"metadataKey,omitempty"`
+	Metadata    Metadata `json:"metadata,omitempty"`
 }
 
 type InputValueDefinition struct {
@@ -156,6 +281,7 @@ type InputValueDefinition struct {
 	DefaultValue Value        `json:"defaultValue,omitempty"`
 	Type         *TypeExpr    `json:"type"`
 	Deprecation  *Deprecation `json:"deprecation,omitempty"`
+	Metadata     Metadata     `json:"metadata,omitempty"`
 }
 
 type Argument struct {
@@ -165,16 +291,24 @@ type Argument struct {
 
 type Value = any
 
+// Metadata records the uses of directives declared in SDL on a definition, keyed by
+// directive name, each use with the argument values it sets. Protograph does not
+// interpret them; they are carried into the schema for runtimes and middleware.
+type Metadata map[string][]map[string]Value
+
 type Deprecation struct {
 	Reason string `json:"reason,omitempty"`
 }
 
 type LoaderDefinition struct {
 	ID         LoaderID              `json:"id"`
-	TargetType string                `json:"targetType"`      // The type this loader loads (e.g., "User", "Post")
-	KeyFields  []string              `json:"keyFields"`       // Field names used as keys (e.g., ["id"] or ["userId", "postId"])
-	Batch      bool                  `json:"batch,omitempty"` // true to generate BatchLoad*, false for Load*
-	Args       map[string]*MethodArg `json:"args"`            // Arguments for the loader
+	TargetType string                `json:"targetType"`         // The type this loader loads (e.g., "User", "Post")
+	KeyFields  []string              `json:"keyFields"`          // Field names used as keys (e.g., ["id"] or ["userId", "postId"])
+	Batch      bool                  `json:"batch,omitempty"`    // true to generate BatchLoad*, false for Load*
+	Keyed      bool                  `json:"keyed,omitempty"`    // BatchLoad* results carry their keys and are joined by key, in any order
+	List       bool                  `json:"list,omitempty"`     // each key loads a list of TargetType, e.g. the posts of an author
+	DataPath   string                `json:"dataPath,omitempty"` // response field holding the result, "data" when empty
+	Args       map[string]*MethodArg `json:"args"`               // Arguments for the loader
 }
 
 // LoaderID is a unique identifier for a loader.
@@ -189,6 +323,12 @@ type ResolverDefinition struct {
 	Batch       bool                  `json:"batch,omitempty"`
 	ReturnType  *TypeExpr             `json:"returnType"`
 	Description string                `json:"description,omitempty"`
+	// DataPath is the response field holding the result, or a dot-separated path
+	// through nested response messages; "data" when empty
+	DataPath string `json:"dataPath,omitempty"`
+	// Fallbacks name services declared next to the resolver's own, in its package,
+	// that expose the same method and are tried in order when it fails
+	Fallbacks []string `json:"fallbacks,omitempty"`
 }
 
 type MethodArg struct {
@@ -259,6 +399,31 @@ func (e *ObjectDefinition) OrderedFields() []*FieldDefinition {
 	return fields
 }
 
+func (f *FieldDefinition) OrderedArgs() []*ArgumentDefinition {
+	args := make([]*ArgumentDefinition, 0, len(f.Args))
+	for _, arg := range f.Args {
+		args = append(args, arg)
+	}
+	sort.Slice(args, func(i, j int) bool {
+		return args[i].Index < args[j].Index
+	})
+	return args
+}
+
+// IsConnectionArg reports whether name is one of the pagination arguments
+// @connection added to the field.
+func (f *FieldDefinition) IsConnectionArg(name string) bool {
+	if f.ConnectionOf == nil {
+		return false
+	}
+	for _, ca := range connectionArgs {
+		if ca.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *EnumDefinition) OrderedValues() []*EnumValueDefinition {
 	values := make([]*EnumValueDefinition, 0, len(e.Values))
 	for _, val := range e.Values {
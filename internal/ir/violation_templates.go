@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"strings"
 
 	language "github.com/hanpama/protograph/internal/language"
 )
@@ -157,6 +158,20 @@ func violationIdFieldNotNonNull(fieldName, typeName string, pos *language.Positi
 	)
 }
 
+func violationOptionalFieldNotNonNull(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@optional field '%s' on type %s must be Non-Null", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationOptionalFieldOfNonNullInterfaceField(fieldName, typeName, interfaceName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@optional field '%s' on type %s is published nullable, but interface %s declares it Non-Null", fieldName, typeName, interfaceName),
+		pos,
+	)
+}
+
 func violationIdFieldNotScalar(fieldName, typeName string, pos *language.Position) *Violation {
 	return violationWithPosition(
 		fmt.Sprintf("@id field '%s' on type %s must be a scalar type", fieldName, typeName),
@@ -171,6 +186,20 @@ func violationLoaderKeyConflict(pos *language.Position) *Violation {
 	)
 }
 
+func violationLoaderKeyedWithoutBatch(pos *language.Position) *Violation {
+	return violationWithPosition(
+		"@loader 'keyed' requires 'batch'; single loaders have no results to join",
+		pos,
+	)
+}
+
+func violationLoaderKeyedKeyNotJoinable(keyField, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@loader 'keyed' key '%s' on type %s must be a single scalar, not a list or a proto message, to join results by", keyField, typeName),
+		pos,
+	)
+}
+
 func violationLoaderMappingKeysMismatch(pos *language.Position) *Violation {
 	return violationWithPosition(
 		"@load mapping keys do not match loader key set",
@@ -199,6 +228,34 @@ func violationLoadTypeMismatch(objName, parentField, srcType, targetType, key st
 	)
 }
 
+func violationInvalidDataPath(directiveName, path string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@%s data path %q must be dot-separated field names", directiveName, path),
+		pos,
+	)
+}
+
+func violationDataPathReserved(directiveName, path string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@%s data path %q may not start with 'error', which batch elements reserve for errors", directiveName, path),
+		pos,
+	)
+}
+
+func violationLoadListNeedsListLoader(fieldName, loaderID string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@load field %s is a list; loader '%s' must be declared with list: true", fieldName, loaderID),
+		pos,
+	)
+}
+
+func violationLoadListLoaderNeedsList(fieldName, loaderID string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@load field %s must be a list to use list loader '%s'", fieldName, loaderID),
+		pos,
+	)
+}
+
 func violationResolveWithKeyConflictsArg(reqField string, pos *language.Position) *Violation {
 	return violationWithPosition(
 		fmt.Sprintf("@resolve 'with' key '%s' conflicts with argument name '%s'", reqField, reqField),
@@ -319,3 +376,362 @@ func violationRootTypeNotObject(kind, typeName string) *Violation {
 		Message: fmt.Sprintf("%s type %q must be an Object type", kind, typeName),
 	}
 }
+
+func violationConnectionFieldNotList(fieldName, typ string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@connection field %q must return a list of named types, got %s", fieldName, typ),
+		pos,
+	)
+}
+
+func violationConnectionArgumentConflict(argName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Argument %q in field %q conflicts with @connection pagination argument", argName, fieldName),
+		pos,
+	)
+}
+
+func violationConnectionTypeConflict(typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@connection cannot generate type %q: a definition with that name already exists", typeName),
+		pos,
+	)
+}
+
+func violationNodeResolutionConflict(typeName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Field %s on type %s cannot combine @node with @load or @resolve", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationNodeFieldNotRoot(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@node field %q must be declared on a root type, found on %s", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationNodeFieldArguments(fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@node field %q must take exactly one argument 'id: ID!'", fieldName),
+		pos,
+	)
+}
+
+func violationNodeFieldNotInterface(fieldName, typ string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@node field %q must return an interface type, got %s", fieldName, typ),
+		pos,
+	)
+}
+
+func violationNodeInterfaceMissingID(interfaceName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Interface %s used by @node must declare field 'id: ID!'", interfaceName),
+		pos,
+	)
+}
+
+func violationNodeImplementerWithoutLoader(typeName, interfaceName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Type %s implements %s but has no @loader keyed by 'id' for @node", typeName, interfaceName),
+		pos,
+	)
+}
+
+func violationComputeResolutionConflict(typeName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Field %s on type %s cannot combine @compute with @load, @resolve or @node", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationComputeFieldNotAllowed(fieldName, typeName, reason string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@compute field %q on type %s %s", fieldName, typeName, reason),
+		pos,
+	)
+}
+
+func violationComputeInvalidExpression(fieldName string, err error, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Invalid @compute expression on field %q: %v", fieldName, err),
+		pos,
+	)
+}
+
+func violationComputeUnknownSourceField(ref, fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@compute field %q references %q, which is not a source field of %s", fieldName, ref, typeName),
+		pos,
+	)
+}
+
+func violationMissingExprArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @compute requires 'expr' parameter", pos)
+}
+
+func violationConstResolutionConflict(typeName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Field %s on type %s cannot combine @const with @load, @resolve, @node or @compute", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationMissingURLArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @specifiedBy requires 'url' parameter", pos)
+}
+
+func violationMissingValueArgument(directiveName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Directive @%s requires 'value' parameter", directiveName), pos)
+}
+
+func violationLiteralFieldNotAllowed(directiveName, fieldName, reason string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@%s field %q %s", directiveName, fieldName, reason),
+		pos,
+	)
+}
+
+func violationLiteralTypeMismatch(directiveName, value, typ string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@%s value %s is not a valid %s", directiveName, value, typ),
+		pos,
+	)
+}
+
+func violationDefaultRequiresSourceField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@default on field %q of %s requires a field resolved from the source message", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationSourceResolutionConflict(typeName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("Field %s on type %s cannot combine @source with @load, @resolve, @node, @compute or @const", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationMissingFieldArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @source requires 'field' parameter", pos)
+}
+
+func violationSourceFieldNotAllowed(fieldName, typeName, reason string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@source field %q on type %s %s", fieldName, typeName, reason),
+		pos,
+	)
+}
+
+func violationSourcePathInvalid(path, fieldName, reason string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@source path %q of field %q %s", path, fieldName, reason),
+		pos,
+	)
+}
+
+func violationSourceTypeMismatch(path, pathType, fieldName, fieldType string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@source path %q has type %s, which cannot be served as %s for field %q", path, pathType, fieldType, fieldName),
+		pos,
+	)
+}
+
+func violationMissingActionArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @onError requires 'action' parameter", pos)
+}
+
+func violationInvalidErrorAction(action string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@onError action %s is not one of NULL, PROPAGATE or DEFAULT", action),
+		pos,
+	)
+}
+
+func violationErrorValueWithoutDefault(pos *language.Position) *Violation {
+	return violationWithPosition("@onError 'value' parameter is only allowed with action DEFAULT", pos)
+}
+
+func violationErrorDefaultOnType(typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@onError(action: DEFAULT) on type %s is not allowed; declare it on each field", typeName),
+		pos,
+	)
+}
+
+func violationOnErrorRequiresRemoteField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@onError on field %q of %s requires a field resolved by a resolver, loader or @node", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationMissingTTLArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @cache requires 'ttl' parameter", pos)
+}
+
+func violationInvalidDuration(directiveName, argName, value string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@%s %s %q is not a positive duration such as \"30s\"", directiveName, argName, value),
+		pos,
+	)
+}
+
+func violationCacheRequiresRemoteField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@cache on field %q of %s requires a field resolved by a resolver or loader", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationPriorityRequiresRemoteField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@priority on field %q of %s requires a field resolved by a resolver, loader or @node", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationMissingLevelArgument(pos *language.Position) *Violation {
+	return violationWithPosition("Directive @priority requires 'level' parameter", pos)
+}
+
+func violationInvalidPriorityLevel(level string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@priority level %s is not one of HIGH, NORMAL or LOW", level), pos)
+}
+
+func violationTimeoutRequiresRemoteField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@timeout on field %q of %s requires a field resolved by a resolver, loader or @node", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationInvalidTimeout(ms string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@timeout ms %s is not a positive number of milliseconds", ms), pos)
+}
+
+func violationSLARequiresRemoteField(fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@sla on field %q of %s requires a field resolved by a resolver, loader or @node", fieldName, typeName),
+		pos,
+	)
+}
+
+func violationInvalidSLA(ms string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@sla ms %s is not a positive number of milliseconds", ms), pos)
+}
+
+func violationInvalidFallbackService(name string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@resolve fallback %q is not a valid service name", name), pos)
+}
+
+func violationDuplicateFallbackService(name string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@resolve fallback %s repeats a service of the chain", name), pos)
+}
+
+func violationFallbackServiceConflict(name, service string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@resolve fallback %s is already taken by service %s of its package", name, service), pos)
+}
+
+func violationFallbackOnMutation(typeName, fieldName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@resolve fallback is not allowed on mutation field %s.%s", typeName, fieldName), pos)
+}
+
+func violationInvalidEnvelopeKind(kind string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@envelope kind %s is not one of ANY or DEFAULT", kind), pos)
+}
+
+func violationDiscriminatorNotMember(member, unionName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator values: %s is not a member of union %s", member, unionName), pos)
+}
+
+func violationInvalidDiscriminatorField(field string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator field %q must be a field name", field), pos)
+}
+
+func violationDuplicateDiscriminatorValue(value, member, other string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator value %q is used by both %s and %s", value, member, other), pos)
+}
+
+func violationDiscriminatorWithAnyEnvelope(unionName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator cannot be used with @envelope(kind: ANY) on union %s", unionName), pos)
+}
+
+func violationDiscriminatorFieldConflict(field, member string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator field %s conflicts with source field %s.%s", field, member, field), pos)
+}
+
+func violationDiscriminatedFieldTypeConflict(field, member, other string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@discriminator members %s and %s declare source field %s with different types", member, other, field), pos)
+}
+
+func violationInvalidUnknownEnumAction(action string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@onUnknown action %s is not one of NULL, STRING or VALUE", action), pos)
+}
+
+func violationUnknownEnumValueWithoutValueAction(pos *language.Position) *Violation {
+	return violationWithPosition("@onUnknown value is only allowed with action VALUE", pos)
+}
+
+func violationUnknownEnumValueNotInEnum(value, enumName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@onUnknown value %s is not a value of enum %s", value, enumName), pos)
+}
+
+func violationInvalidProtoEnumValueName(name string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@mapValue toProtobuf %q is not a valid proto enum value name", name), pos)
+}
+
+func violationDuplicateProtoEnumValueName(name, value, other string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("@mapValue toProtobuf %q is used by both %s and %s", name, value, other), pos)
+}
+
+func violationInvalidBuiltinScalarMapping(scalar, protoType string, pos *language.Position) *Violation {
+	if scalar == IDType.Name {
+		return violationWithPosition(fmt.Sprintf("@mapScalar cannot map ID to %s; use one of %s", protoType, strings.Join(IDProtoTypes, ", ")), pos)
+	}
+	return violationWithPosition(fmt.Sprintf("@mapScalar cannot map built-in scalar %s", scalar), pos)
+}
+
+func violationMissingKeyArgument(directiveName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Directive @%s requires 'key' parameter", directiveName), pos)
+}
+
+func violationInvalidMetadataKey(key string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@metadata key %q must be lowercase letters, digits, '-', '_' or '.', and must not start with \"grpc-\" or end with \"-bin\"", key),
+		pos,
+	)
+}
+
+func violationMetadataRequiresResolver(argName, fieldName, typeName string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@metadata on argument %q of %s.%s requires a field resolved by a resolver", argName, typeName, fieldName),
+		pos,
+	)
+}
+
+func violationMetadataArgumentType(argName, typ string, pos *language.Position) *Violation {
+	return violationWithPosition(
+		fmt.Sprintf("@metadata argument %q must be a scalar or enum, got %s", argName, typ),
+		pos,
+	)
+}
+
+func violationDirectiveLocation(directiveName, location string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Directive @%s may not be used on %s", directiveName, location), pos)
+}
+
+func violationDirectiveNotRepeatable(directiveName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Directive @%s is not repeatable and may be used only once here", directiveName), pos)
+}
+
+func violationMissingDirectiveArgument(directiveName, argName string, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Directive @%s requires '%s' parameter", directiveName, argName), pos)
+}
+
+func violationWithInvalidExpression(directiveName, key string, err error, pos *language.Position) *Violation {
+	return violationWithPosition(fmt.Sprintf("Invalid @%s mapping expression for %q: %v", directiveName, key, err), pos)
+}
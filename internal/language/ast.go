@@ -17,6 +17,7 @@ type (
 	ArgumentList        = ast.ArgumentList
 	Argument            = ast.Argument
 	Value               = ast.Value
+	ChildValue          = ast.ChildValue
 	FieldDefinition     = ast.FieldDefinition
 	ArgumentDefinition  = ast.ArgumentDefinition
 	EnumValueDefinition = ast.EnumValueDefinition
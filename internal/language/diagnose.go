@@ -0,0 +1,572 @@
+package language
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// maxSyntaxErrors bounds the errors reported for one document.
+const maxSyntaxErrors = 20
+
+// diagnoseQuery checks the syntax of an executable document, reporting every
+// error it finds ordered by location. Unlike the parser it recovers from
+// errors: the lexer ends an unterminated string at its line and reads ".." as
+// "...", and the parser skips what it cannot read up to the next token that
+// fits, reporting a single error per skipped run.
+func diagnoseQuery(source string) ErrorList {
+	p := &diagParser{lex: &lexer{src: source, line: 1, col: 1}}
+	p.lex.report = p.lexError
+	p.advance()
+	p.parseDocument()
+	sort.SliceStable(p.errs, func(i, j int) bool {
+		a, b := p.errs[i].Locations[0], p.errs[j].Locations[0]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	if len(p.errs) > maxSyntaxErrors {
+		p.errs = p.errs[:maxSyntaxErrors]
+	}
+	return p.errs
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+	tokBlockString
+)
+
+var tokenKindNames = [...]string{
+	tokEOF:         "<EOF>",
+	tokPunct:       "Punctuator",
+	tokName:        "Name",
+	tokInt:         "Int",
+	tokFloat:       "Float",
+	tokString:      "String",
+	tokBlockString: "BlockString",
+}
+
+type token struct {
+	kind      tokenKind
+	value     string
+	pos       int
+	line, col int
+	// bad marks a token the lexer reported an error for
+	bad bool
+}
+
+// kind names the token as "found" in expectation errors.
+func (t token) kindName() string {
+	if t.kind == tokPunct {
+		return t.value
+	}
+	return tokenKindNames[t.kind]
+}
+
+// String describes the token in unexpected-token errors.
+func (t token) String() string {
+	switch t.kind {
+	case tokPunct, tokEOF:
+		return t.kindName()
+	case tokString, tokBlockString:
+		return t.kindName()
+	}
+	return fmt.Sprintf("%s %q", t.kindName(), t.value)
+}
+
+func (t token) is(punct string) bool {
+	return t.kind == tokPunct && t.value == punct
+}
+
+func (t token) isName(name string) bool {
+	return t.kind == tokName && t.value == name
+}
+
+// isCloser reports whether t ends a list, object, argument list or selection
+// set, so that a nested construct missing its own closer stops there.
+func (t token) isCloser() bool {
+	return t.is(")") || t.is("]") || t.is("}")
+}
+
+type lexer struct {
+	src       string
+	pos       int
+	line, col int
+	report    func(line, col int, format string, args ...any)
+	bad       bool
+}
+
+func (l *lexer) errorf(line, col int, format string, args ...any) {
+	l.bad = true
+	l.report(line, col, format, args...)
+}
+
+func (l *lexer) peekRune(offset int) rune {
+	pos := l.pos
+	for ; offset > 0 && pos < len(l.src); offset-- {
+		_, size := utf8.DecodeRuneInString(l.src[pos:])
+		pos += size
+	}
+	if pos >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[pos:])
+	return r
+}
+
+func (l *lexer) readRune() rune {
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.pos += size
+	switch {
+	case r == '\n':
+		l.line++
+		l.col = 1
+	case r == '\r':
+		if l.peekRune(0) != '\n' {
+			l.line++
+			l.col = 1
+		}
+	default:
+		l.col++
+	}
+	return r
+}
+
+// next reads the next token. Characters that cannot start a token are
+// reported and skipped, marking the token after them bad.
+func (l *lexer) next() token {
+	l.bad = false
+	for {
+		if t, ok := l.scan(); ok {
+			t.bad = l.bad
+			return t
+		}
+	}
+}
+
+func (l *lexer) scan() (token, bool) {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos, line: l.line, col: l.col}, true
+	}
+	pos, line, col := l.pos, l.line, l.col
+	r := l.peekRune(0)
+	switch {
+	case strings.ContainsRune("!$&():=@[]{|}", r):
+		l.readRune()
+		return token{kind: tokPunct, value: string(r), pos: pos, line: line, col: col}, true
+	case r == '.':
+		dots := 0
+		for dots < 3 && l.peekRune(0) == '.' {
+			l.readRune()
+			dots++
+		}
+		if dots < 3 {
+			l.errorf(line, col, "Unexpected %q, did you mean \"...\"?", strings.Repeat(".", dots))
+		}
+		return token{kind: tokPunct, value: "...", pos: pos, line: line, col: col}, true
+	case r == '_' || isLetter(r):
+		start := l.pos
+		for r := l.peekRune(0); r == '_' || isLetter(r) || isDigit(r); r = l.peekRune(0) {
+			l.readRune()
+		}
+		return token{kind: tokName, value: l.src[start:l.pos], pos: pos, line: line, col: col}, true
+	case r == '-' || isDigit(r):
+		return l.readNumber(line, col), true
+	case r == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.readBlockString(line, col), true
+		}
+		return l.readString(line, col), true
+	}
+	l.readRune()
+	l.errorf(line, col, "Unexpected character %q", r)
+	return token{}, false
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch r := l.peekRune(0); r {
+		case '\uFEFF', ' ', '\t', '\n', '\r', ',':
+			l.readRune()
+		case '#':
+			for r := l.peekRune(0); r != -1 && r != '\n' && r != '\r'; r = l.peekRune(0) {
+				l.readRune()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) readNumber(line, col int) token {
+	start := l.pos
+	kind := tokInt
+	if l.peekRune(0) == '-' {
+		l.readRune()
+	}
+	if l.peekRune(0) == '0' {
+		l.readRune()
+		if isDigit(l.peekRune(0)) {
+			l.errorf(l.line, l.col, "Invalid number, unexpected digit after 0: %q", l.peekRune(0))
+			l.readDigits()
+		}
+	} else {
+		l.readDigits()
+	}
+	if l.peekRune(0) == '.' {
+		kind = tokFloat
+		l.readRune()
+		l.readDigits()
+	}
+	if r := l.peekRune(0); r == 'e' || r == 'E' {
+		kind = tokFloat
+		l.readRune()
+		if r := l.peekRune(0); r == '+' || r == '-' {
+			l.readRune()
+		}
+		l.readDigits()
+	}
+	return token{kind: kind, value: l.src[start:l.pos], pos: start, line: line, col: col}
+}
+
+// readDigits reads one or more digits, reporting their absence.
+func (l *lexer) readDigits() {
+	if !isDigit(l.peekRune(0)) {
+		l.errorf(l.line, l.col, "Invalid number, expected digit but got: %s", describeRune(l.peekRune(0)))
+		return
+	}
+	for isDigit(l.peekRune(0)) {
+		l.readRune()
+	}
+}
+
+// readString reads a string, ended at its line when unterminated.
+func (l *lexer) readString(line, col int) token {
+	pos := l.pos
+	l.readRune()
+	start := l.pos
+	for {
+		switch r := l.peekRune(0); r {
+		case '"':
+			value := l.src[start:l.pos]
+			l.readRune()
+			return token{kind: tokString, value: value, pos: pos, line: line, col: col}
+		case -1, '\n', '\r':
+			l.errorf(line, col, "Unterminated string.")
+			return token{kind: tokString, value: l.src[start:l.pos], pos: pos, line: line, col: col}
+		case '\\':
+			escLine, escCol := l.line, l.col
+			l.readRune()
+			esc := l.peekRune(0)
+			switch {
+			case strings.ContainsRune(`"\/bfnrt`, esc):
+				l.readRune()
+			case esc == 'u':
+				l.readRune()
+				for i := 0; i < 4; i++ {
+					if !isHexDigit(l.peekRune(0)) {
+						l.errorf(escLine, escCol, "Invalid Unicode escape sequence in string.")
+						break
+					}
+					l.readRune()
+				}
+			default:
+				l.errorf(escLine, escCol, "Invalid character escape sequence: \\%s.", string(esc))
+			}
+		default:
+			l.readRune()
+		}
+	}
+}
+
+func (l *lexer) readBlockString(line, col int) token {
+	pos := l.pos
+	for i := 0; i < 3; i++ {
+		l.readRune()
+	}
+	start := l.pos
+	for l.pos < len(l.src) {
+		if strings.HasPrefix(l.src[l.pos:], `\"""`) {
+			for i := 0; i < 4; i++ {
+				l.readRune()
+			}
+			continue
+		}
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			value := l.src[start:l.pos]
+			for i := 0; i < 3; i++ {
+				l.readRune()
+			}
+			return token{kind: tokBlockString, value: value, pos: pos, line: line, col: col}
+		}
+		l.readRune()
+	}
+	l.errorf(line, col, "Unterminated block string.")
+	return token{kind: tokBlockString, value: l.src[start:], pos: pos, line: line, col: col}
+}
+
+func isLetter(r rune) bool { return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
+func isHexDigit(r rune) bool { return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F' }
+
+func describeRune(r rune) string {
+	if r == -1 {
+		return "<EOF>"
+	}
+	return fmt.Sprintf("%q", r)
+}
+
+// diagParser reads an executable document for its errors only. After an
+// error it is recovering: further errors are not reported until it matches an
+// expected punctuator again, so one mistake is not reported once per token
+// after it.
+type diagParser struct {
+	lex        *lexer
+	tok        token
+	errs       ErrorList
+	recovering bool
+}
+
+func (p *diagParser) lexError(line, col int, format string, args ...any) {
+	p.errs = append(p.errs, gqlerror.ErrorLocf("", line, col, format, args...))
+	p.recovering = true
+}
+
+func (p *diagParser) errorf(t token, format string, args ...any) {
+	if p.recovering {
+		return
+	}
+	p.errs = append(p.errs, gqlerror.ErrorLocf("", t.line, t.col, format, args...))
+	p.recovering = true
+}
+
+func (p *diagParser) advance() {
+	p.tok = p.lex.next()
+}
+
+func (p *diagParser) unexpected() {
+	p.errorf(p.tok, "Unexpected %s", p.tok)
+}
+
+// expect reads the punctuator v, ending recovery unless v or the token after
+// it is bad.
+func (p *diagParser) expect(v string) bool {
+	if !p.tok.is(v) {
+		p.errorf(p.tok, "Expected %s, found %s", v, p.tok.kindName())
+		return false
+	}
+	bad := p.tok.bad
+	p.advance()
+	p.recovering = bad || p.tok.bad
+	return true
+}
+
+// expectName reads a name.
+func (p *diagParser) expectName() bool {
+	if p.tok.kind != tokName {
+		p.errorf(p.tok, "Expected Name, found %s", p.tok.kindName())
+		return false
+	}
+	p.advance()
+	return true
+}
+
+// until calls item until the closer, skipping the tokens item does not read.
+// It stops early at other closers and at the stop punctuators, so that the
+// construct missing its closer is reported and not the one enclosing it.
+func (p *diagParser) until(closer string, stop string, item func()) {
+	for !p.tok.is(closer) && p.tok.kind != tokEOF {
+		if p.tok.isCloser() || p.tok.kind == tokPunct && strings.Contains(stop, p.tok.value) {
+			break
+		}
+		before := p.tok.pos
+		item()
+		if p.tok.pos == before {
+			p.unexpected()
+			p.advance()
+		}
+	}
+	p.expect(closer)
+}
+
+func (p *diagParser) parseDocument() {
+	for p.tok.kind != tokEOF && len(p.errs) <= maxSyntaxErrors {
+		before := p.tok.pos
+		p.parseDefinition()
+		if p.tok.pos == before {
+			p.unexpected()
+			p.advance()
+		}
+	}
+}
+
+func (p *diagParser) parseDefinition() {
+	switch {
+	case p.tok.is("{"):
+		p.parseSelectionSet()
+	case p.tok.isName("query"), p.tok.isName("mutation"), p.tok.isName("subscription"):
+		p.advance()
+		if p.tok.kind == tokName {
+			p.advance()
+		}
+		if p.tok.is("(") {
+			p.advance()
+			if p.tok.is(")") {
+				p.errorf(p.tok, "Expected $, found )")
+			}
+			p.until(")", "{@", p.parseVariableDefinition)
+		}
+		p.parseDirectives(false)
+		p.parseSelectionSet()
+	case p.tok.isName("fragment"):
+		p.advance()
+		if p.tok.isName("on") {
+			p.unexpected()
+		}
+		p.expectName()
+		if p.tok.isName("on") {
+			p.advance()
+		} else {
+			p.errorf(p.tok, "Expected \"on\", found %s", p.tok.kindName())
+		}
+		p.expectName()
+		p.parseDirectives(false)
+		p.parseSelectionSet()
+	}
+}
+
+func (p *diagParser) parseVariableDefinition() {
+	if !p.expect("$") {
+		return
+	}
+	p.expectName()
+	p.expect(":")
+	p.parseType()
+	if p.tok.is("=") {
+		p.advance()
+		p.parseValue(true)
+	}
+	p.parseDirectives(true)
+}
+
+func (p *diagParser) parseType() {
+	if p.tok.is("[") {
+		p.advance()
+		p.parseType()
+		p.expect("]")
+	} else {
+		p.expectName()
+	}
+	if p.tok.is("!") {
+		p.advance()
+	}
+}
+
+func (p *diagParser) parseSelectionSet() {
+	if !p.expect("{") {
+		return
+	}
+	if p.tok.is("}") {
+		p.errorf(p.tok, "Expected Name, found }")
+	}
+	p.until("}", "", p.parseSelection)
+}
+
+func (p *diagParser) parseSelection() {
+	if p.tok.is("...") {
+		p.advance()
+		switch {
+		case p.tok.isName("on"):
+			p.advance()
+			p.expectName()
+		case p.tok.kind == tokName:
+			// Fragment spread
+			p.advance()
+			p.parseDirectives(false)
+			return
+		}
+		p.parseDirectives(false)
+		p.parseSelectionSet()
+		return
+	}
+	if p.tok.kind != tokName {
+		return
+	}
+	p.advance()
+	if p.tok.is(":") {
+		p.advance()
+		p.expectName()
+	}
+	p.parseArguments(false)
+	p.parseDirectives(false)
+	if p.tok.is("{") {
+		p.parseSelectionSet()
+	}
+}
+
+// parseArguments stops at "{" and "@", which cannot start an argument, so
+// that a missing ")" is reported before the selection set or directive.
+func (p *diagParser) parseArguments(isConst bool) {
+	if !p.tok.is("(") {
+		return
+	}
+	p.advance()
+	if p.tok.is(")") {
+		p.errorf(p.tok, "Expected Name, found )")
+	}
+	p.until(")", "{@", func() {
+		if p.tok.kind != tokName {
+			return
+		}
+		p.advance()
+		p.expect(":")
+		p.parseValue(isConst)
+	})
+}
+
+func (p *diagParser) parseDirectives(isConst bool) {
+	for p.tok.is("@") {
+		p.advance()
+		p.expectName()
+		p.parseArguments(isConst)
+	}
+}
+
+func (p *diagParser) parseValue(isConst bool) {
+	switch {
+	case p.tok.is("$"):
+		if isConst {
+			p.unexpected()
+		}
+		p.advance()
+		p.expectName()
+	case p.tok.kind == tokInt, p.tok.kind == tokFloat, p.tok.kind == tokString, p.tok.kind == tokBlockString, p.tok.kind == tokName:
+		p.advance()
+	case p.tok.is("["):
+		p.advance()
+		p.until("]", "", func() { p.parseValue(isConst) })
+	case p.tok.is("{"):
+		p.advance()
+		p.until("}", "", func() {
+			if p.tok.kind != tokName {
+				return
+			}
+			p.advance()
+			p.expect(":")
+			p.parseValue(isConst)
+		})
+	default:
+		p.unexpected()
+	}
+}
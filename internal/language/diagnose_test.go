@@ -0,0 +1,39 @@
+package language
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"{ a(x: 1,) b, }", nil},
+		{"{ a(x: [0{b: 1}]) }", nil},
+		{"{ a", []string{"1:4 Expected }, found <EOF>"}},
+		{"{ a(x: ) b { c(: 2) } }", []string{"1:8 Unexpected )", "1:16 Unexpected :"}},
+		{"query Q($v: Int = ) { a }\nfragment F on { b }", []string{"1:19 Unexpected )", "2:15 Expected Name, found {"}},
+		{"{ a(x: 01) ^ b }", []string{"1:9 Invalid number, unexpected digit after 0: '1'", "1:12 Unexpected character '^'"}},
+		{"{\n  a(x: \"abc)\n  b\n  c(y: 1 }\n}", []string{"2:8 Unterminated string.", "4:10 Expected ), found }", "5:1 Unexpected }"}},
+		{`{ a(x: """abc) }`, []string{"1:8 Unterminated block string."}},
+	} {
+		_, err := ParseQuery(tc.query)
+		var got []string
+		if err != nil {
+			errs, ok := err.(ErrorList)
+			if !ok {
+				t.Fatalf("%q: error %T, want ErrorList", tc.query, err)
+			}
+			for _, e := range errs {
+				got = append(got, fmt.Sprintf("%d:%d %s", e.Locations[0].Line, e.Locations[0].Column, e.Message))
+			}
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("%q errors (-want +got):\n%s", tc.query, diff)
+		}
+	}
+}
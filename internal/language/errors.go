@@ -3,3 +3,6 @@ package language
 import "github.com/vektah/gqlparser/v2/gqlerror"
 
 type Error = gqlerror.Error
+
+// ErrorList is the error of a document with several syntax errors.
+type ErrorList = gqlerror.List
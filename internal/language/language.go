@@ -1,13 +1,25 @@
 package language
 
 import (
+	"errors"
+
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/parser"
 )
 
+// ParseQuery parses an executable document. A document with syntax errors
+// fails with an ErrorList of all of them, ordered by location.
 func ParseQuery(source string) (*QueryDocument, error) {
 	doc, err := parser.ParseQuery(&ast.Source{Input: source})
 	if err != nil {
+		if errs := diagnoseQuery(source); len(errs) > 0 {
+			return nil, errs
+		}
+		// The parser is authoritative when the diagnosis misses its error
+		var ge *Error
+		if errors.As(err, &ge) {
+			return nil, ErrorList{ge}
+		}
 		return nil, err
 	}
 	return doc, nil
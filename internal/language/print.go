@@ -0,0 +1,259 @@
+package language
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PrintOption configures Print.
+type PrintOption func(*printOptions)
+
+type printOptions struct {
+	sortArguments bool
+	compact       bool
+}
+
+// WithSortedArguments orders the arguments of fields and directives and the
+// fields of input objects by name instead of as written.
+func WithSortedArguments() PrintOption {
+	return func(o *printOptions) { o.sortArguments = true }
+}
+
+// WithCompact renders the document on a single line, for logs and hashing.
+func WithCompact() PrintOption {
+	return func(o *printOptions) { o.compact = true }
+}
+
+// Print renders doc in canonical form: operations then fragments, each in
+// document order, indented by two spaces, with one space after commas and
+// colons and without comments. Block strings are rendered as strings, and a
+// query without name, variables or directives in its shorthand form.
+func Print(doc *QueryDocument, opts ...PrintOption) string {
+	p := &printer{}
+	for _, opt := range opts {
+		opt(&p.printOptions)
+	}
+	for _, op := range doc.Operations {
+		p.definition()
+		p.operation(op)
+	}
+	for _, f := range doc.Fragments {
+		p.definition()
+		p.fragment(f)
+	}
+	if !p.compact && p.b.Len() > 0 {
+		p.b.WriteByte('\n')
+	}
+	return p.b.String()
+}
+
+// NormalizedHash is the hex SHA-256 of doc printed compact with sorted
+// arguments. Documents differing only in formatting, comments and the order of
+// arguments hash alike.
+func NormalizedHash(doc *QueryDocument) string {
+	sum := sha256.Sum256([]byte(Print(doc, WithCompact(), WithSortedArguments())))
+	return hex.EncodeToString(sum[:])
+}
+
+type printer struct {
+	printOptions
+	b     strings.Builder
+	depth int
+}
+
+// definition separates a definition from the previous one.
+func (p *printer) definition() {
+	switch {
+	case p.b.Len() == 0:
+	case p.compact:
+		p.b.WriteByte(' ')
+	default:
+		p.b.WriteString("\n\n")
+	}
+}
+
+// newline starts a line at the current depth.
+func (p *printer) newline() {
+	if p.compact {
+		p.b.WriteByte(' ')
+		return
+	}
+	p.b.WriteByte('\n')
+	for range p.depth {
+		p.b.WriteString("  ")
+	}
+}
+
+func (p *printer) operation(op *OperationDefinition) {
+	if op.Operation == Query && op.Name == "" && len(op.VariableDefinitions) == 0 && len(op.Directives) == 0 {
+		p.selectionSet(op.SelectionSet)
+		return
+	}
+	p.b.WriteString(string(op.Operation))
+	if op.Name != "" {
+		p.b.WriteString(" " + op.Name)
+	}
+	if len(op.VariableDefinitions) > 0 {
+		p.b.WriteByte('(')
+		for i, v := range op.VariableDefinitions {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+			p.b.WriteString("$" + v.Variable + ": " + v.Type.String())
+			if v.DefaultValue != nil {
+				p.b.WriteString(" = ")
+				p.value(v.DefaultValue)
+			}
+			p.directives(v.Directives)
+		}
+		p.b.WriteByte(')')
+	}
+	p.directives(op.Directives)
+	p.b.WriteByte(' ')
+	p.selectionSet(op.SelectionSet)
+}
+
+func (p *printer) fragment(f *FragmentDefinition) {
+	p.b.WriteString("fragment " + f.Name + " on " + f.TypeCondition)
+	p.directives(f.Directives)
+	p.b.WriteByte(' ')
+	p.selectionSet(f.SelectionSet)
+}
+
+func (p *printer) selectionSet(set SelectionSet) {
+	p.b.WriteByte('{')
+	p.depth++
+	for _, sel := range set {
+		p.newline()
+		p.selection(sel)
+	}
+	p.depth--
+	p.newline()
+	p.b.WriteByte('}')
+}
+
+func (p *printer) selection(sel Selection) {
+	switch sel := sel.(type) {
+	case *Field:
+		if sel.Alias != "" && sel.Alias != sel.Name {
+			p.b.WriteString(sel.Alias + ": ")
+		}
+		p.b.WriteString(sel.Name)
+		p.arguments(sel.Arguments)
+		p.directives(sel.Directives)
+		if len(sel.SelectionSet) > 0 {
+			p.b.WriteByte(' ')
+			p.selectionSet(sel.SelectionSet)
+		}
+	case *FragmentSpread:
+		p.b.WriteString("..." + sel.Name)
+		p.directives(sel.Directives)
+	case *InlineFragment:
+		p.b.WriteString("...")
+		if sel.TypeCondition != "" {
+			p.b.WriteString(" on " + sel.TypeCondition)
+		}
+		p.directives(sel.Directives)
+		p.b.WriteByte(' ')
+		p.selectionSet(sel.SelectionSet)
+	default:
+		panic(fmt.Sprintf("unexpected selection %T", sel))
+	}
+}
+
+func (p *printer) directives(list DirectiveList) {
+	for _, d := range list {
+		p.b.WriteString(" @" + d.Name)
+		p.arguments(d.Arguments)
+	}
+}
+
+func (p *printer) arguments(args ArgumentList) {
+	if len(args) == 0 {
+		return
+	}
+	if p.sortArguments {
+		args = slices.Clone(args)
+		slices.SortStableFunc(args, func(a, b *Argument) int { return strings.Compare(a.Name, b.Name) })
+	}
+	p.b.WriteByte('(')
+	for i, a := range args {
+		if i > 0 {
+			p.b.WriteString(", ")
+		}
+		p.b.WriteString(a.Name + ": ")
+		p.value(a.Value)
+	}
+	p.b.WriteByte(')')
+}
+
+func (p *printer) value(v *Value) {
+	switch v.Kind {
+	case Variable:
+		p.b.WriteString("$" + v.Raw)
+	case StringValue, BlockValue:
+		p.b.WriteString(quoteString(v.Raw))
+	case ListValue:
+		p.b.WriteByte('[')
+		for i, c := range v.Children {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+			p.value(c.Value)
+		}
+		p.b.WriteByte(']')
+	case ObjectValue:
+		fields := v.Children
+		if p.sortArguments {
+			fields = slices.Clone(fields)
+			slices.SortStableFunc(fields, func(a, b *ChildValue) int { return strings.Compare(a.Name, b.Name) })
+		}
+		p.b.WriteByte('{')
+		for i, c := range fields {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+			p.b.WriteString(c.Name + ": ")
+			p.value(c.Value)
+		}
+		p.b.WriteByte('}')
+	default:
+		p.b.WriteString(v.Raw)
+	}
+}
+
+// quoteString renders s as a GraphQL string, escaping quotes, backslashes and
+// control characters.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
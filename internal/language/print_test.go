@@ -0,0 +1,83 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const printQuery = `# comments are dropped
+query Q($id: ID!, $n: [Int!] = [1,2]) @op(b: 1, a: "x\"y\n") {
+  u: user(id: $id, filter: {z: ENUM, a: null, s: """block"""}) { ...F
+    ... on User @include(if: true) { name }
+    ... { id } }
+}
+{ a }
+fragment F on User { id }`
+
+func mustParse(t *testing.T, query string) *QueryDocument {
+	t.Helper()
+	doc, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+	return doc
+}
+
+func TestPrint(t *testing.T) {
+	doc := mustParse(t, printQuery)
+
+	want := `query Q($id: ID!, $n: [Int!] = [1, 2]) @op(b: 1, a: "x\"y\n") {
+  u: user(id: $id, filter: {z: ENUM, a: null, s: "block"}) {
+    ...F
+    ... on User @include(if: true) {
+      name
+    }
+    ... {
+      id
+    }
+  }
+}
+
+{
+  a
+}
+
+fragment F on User {
+  id
+}
+`
+	got := Print(doc)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Print (-want +got):\n%s", diff)
+	}
+	if again := Print(mustParse(t, got)); again != got {
+		t.Errorf("printed document prints differently:\n%s", again)
+	}
+
+	want = `query Q($id: ID!, $n: [Int!] = [1, 2]) @op(a: "x\"y\n", b: 1) { u: user(filter: {a: null, s: "block", z: ENUM}, id: $id) { ...F ... on User @include(if: true) { name } ... { id } } } { a } fragment F on User { id }`
+	if diff := cmp.Diff(want, Print(doc, WithCompact(), WithSortedArguments())); diff != "" {
+		t.Errorf("Print compact sorted (-want +got):\n%s", diff)
+	}
+}
+
+func TestNormalizedHash(t *testing.T) {
+	hash := NormalizedHash(mustParse(t, `query Q { user(id: 1, name: "a") { id ...on User { name } } }`))
+	for _, query := range []string{
+		"query Q {\n  user(name: \"a\", id: 1) {\n    id\n    ... on User { name }\n  }\n}\n",
+		`query Q { user(id: 1, name: """a""") { id, ... on User { name } } } # comment`,
+	} {
+		if got := NormalizedHash(mustParse(t, query)); got != hash {
+			t.Errorf("%q hashes to %s, want %s", query, got, hash)
+		}
+	}
+	for _, query := range []string{
+		`query Q { user(id: 1, name: "a") { ... on User { name } id } }`,
+		`query Q { user(id: 2, name: "a") { id ... on User { name } } }`,
+		`query R { user(id: 1, name: "a") { id ... on User { name } } }`,
+	} {
+		if NormalizedHash(mustParse(t, query)) == hash {
+			t.Errorf("%q hashes like a different document", query)
+		}
+	}
+}
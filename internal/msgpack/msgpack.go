@@ -0,0 +1,182 @@
+// Package msgpack encodes generic values in MessagePack, a binary equivalent of
+// JSON, for clients that trade readability for smaller responses.
+package msgpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"slices"
+)
+
+// ContentType is the media type of MessagePack.
+const ContentType = "application/msgpack"
+
+// Object is a map written with its entries in the order All yields them, such as
+// a response object in query order.
+type Object interface {
+	Len() int
+	All() iter.Seq2[string, any]
+}
+
+// Encode writes v, built of nil, bool, string, integers, float32, float64, []any,
+// map[string]any and Object, to w. Integers use their smallest encoding and map
+// keys are sorted, so equal values encode to equal bytes.
+func Encode(w io.Writer, v any) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+	if err := e.value(v); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	buf [9]byte
+}
+
+func (e *encoder) value(v any) error {
+	switch v := v.(type) {
+	case nil:
+		e.w.WriteByte(0xc0)
+	case bool:
+		if v {
+			e.w.WriteByte(0xc3)
+		} else {
+			e.w.WriteByte(0xc2)
+		}
+	case string:
+		e.str(v)
+	case int:
+		e.int(int64(v))
+	case int32:
+		e.int(int64(v))
+	case int64:
+		e.int(v)
+	case uint32:
+		e.uint(uint64(v))
+	case uint64:
+		e.uint(v)
+	case float32:
+		e.buf[0] = 0xca
+		binary.BigEndian.PutUint32(e.buf[1:], math.Float32bits(v))
+		e.w.Write(e.buf[:5])
+	case float64:
+		e.buf[0] = 0xcb
+		binary.BigEndian.PutUint64(e.buf[1:], math.Float64bits(v))
+		e.w.Write(e.buf[:9])
+	case []any:
+		e.header(len(v), 0x90, 0xdc)
+		for _, item := range v {
+			if err := e.value(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		e.header(len(keys), 0x80, 0xde)
+		for _, k := range keys {
+			e.str(k)
+			if err := e.value(v[k]); err != nil {
+				return err
+			}
+		}
+	case Object:
+		e.header(v.Len(), 0x80, 0xde)
+		for k, item := range v.All() {
+			e.str(k)
+			if err := e.value(item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", v)
+	}
+	return nil
+}
+
+func (e *encoder) int(n int64) {
+	switch {
+	case n >= 0:
+		e.uint(uint64(n))
+	case n >= -32:
+		e.w.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		e.w.Write([]byte{0xd0, byte(n)})
+	case n >= math.MinInt16:
+		e.buf[0] = 0xd1
+		binary.BigEndian.PutUint16(e.buf[1:], uint16(n))
+		e.w.Write(e.buf[:3])
+	case n >= math.MinInt32:
+		e.buf[0] = 0xd2
+		binary.BigEndian.PutUint32(e.buf[1:], uint32(n))
+		e.w.Write(e.buf[:5])
+	default:
+		e.buf[0] = 0xd3
+		binary.BigEndian.PutUint64(e.buf[1:], uint64(n))
+		e.w.Write(e.buf[:9])
+	}
+}
+
+func (e *encoder) uint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.w.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		e.w.Write([]byte{0xcc, byte(n)})
+	case n <= math.MaxUint16:
+		e.buf[0] = 0xcd
+		binary.BigEndian.PutUint16(e.buf[1:], uint16(n))
+		e.w.Write(e.buf[:3])
+	case n <= math.MaxUint32:
+		e.buf[0] = 0xce
+		binary.BigEndian.PutUint32(e.buf[1:], uint32(n))
+		e.w.Write(e.buf[:5])
+	default:
+		e.buf[0] = 0xcf
+		binary.BigEndian.PutUint64(e.buf[1:], n)
+		e.w.Write(e.buf[:9])
+	}
+}
+
+func (e *encoder) str(s string) {
+	switch n := len(s); {
+	case n < 32:
+		e.w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.w.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		e.buf[0] = 0xda
+		binary.BigEndian.PutUint16(e.buf[1:], uint16(n))
+		e.w.Write(e.buf[:3])
+	default:
+		e.buf[0] = 0xdb
+		binary.BigEndian.PutUint32(e.buf[1:], uint32(n))
+		e.w.Write(e.buf[:5])
+	}
+	e.w.WriteString(s)
+}
+
+// header writes the length of an array or map: fix is the tag of lengths below
+// 16, and tag16 that of 16-bit lengths, followed by the 32-bit one.
+func (e *encoder) header(n int, fix, tag16 byte) {
+	switch {
+	case n < 16:
+		e.w.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		e.buf[0] = tag16
+		binary.BigEndian.PutUint16(e.buf[1:], uint16(n))
+		e.w.Write(e.buf[:3])
+	default:
+		e.buf[0] = tag16 + 1
+		binary.BigEndian.PutUint32(e.buf[1:], uint32(n))
+		e.w.Write(e.buf[:5])
+	}
+}
@@ -0,0 +1,76 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"iter"
+	"math"
+	"strings"
+	"testing"
+)
+
+// object is an Object of ordered entries.
+type object []struct {
+	key   string
+	value any
+}
+
+func (o object) Len() int { return len(o) }
+
+func (o object) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, e := range o {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{int64(0), "00"},
+		{int64(127), "7f"},
+		{int64(128), "cc80"},
+		{int64(65535), "cdffff"},
+		{int64(65536), "ce00010000"},
+		{int64(9007199254740993), "cf0020000000000001"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{int64(-1), "ff"},
+		{int64(-32), "e0"},
+		{int64(-33), "d0df"},
+		{int64(-129), "d1ff7f"},
+		{int64(-32769), "d2ffff7fff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"", "a0"},
+		{"hello", "a568656c6c6f"},
+		{strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+		{[]any{int64(1), "a", nil}, "9301a161c0"},
+		{map[string]any{"b": int64(2), "a": []any{}}, "82a16190a16202"},
+		{object{{"b", int64(2)}, {"a", []any{}}}, "82a16202a16190"},
+	} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, tc.value); err != nil {
+			t.Fatalf("%#v: %v", tc.value, err)
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != tc.want {
+			t.Errorf("%#v: got %s, want %s", tc.value, got, tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, make([]any, 16)); err != nil || !bytes.HasPrefix(buf.Bytes(), []byte{0xdc, 0x00, 0x10}) {
+		t.Errorf("array of 16: % x, %v", buf.Bytes()[:3], err)
+	}
+	if err := Encode(&buf, []any{struct{}{}}); err == nil {
+		t.Errorf("unsupported value encoded")
+	}
+}
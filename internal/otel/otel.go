@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/semconv/v1.17.0"
@@ -20,6 +21,11 @@ import (
 
 // Setup configures OpenTelemetry and attaches eventbus subscribers.
 // If endpoint is empty, no telemetry is configured.
+//
+// Latency budgets exceeded (events.SLAExceeded) are recorded as "sla.exceeded"
+// events of the operation span and counted by the protograph.sla.exceeded
+// counter of the global MeterProvider, which programs embedding the gateway
+// may set.
 func Setup(endpoint, service string) (func(context.Context) error, error) {
 	if endpoint == "" {
 		return func(context.Context) error { return nil }, nil
@@ -40,6 +46,11 @@ func Setup(endpoint, service string) (func(context.Context) error, error) {
 	otel.SetTracerProvider(tp)
 
 	sub := &subscriber{tracer: otel.Tracer("protograph")}
+	sub.slaExceeded, err = otel.Meter("protograph").Int64Counter("protograph.sla.exceeded",
+		metric.WithDescription("Fields and operations resolved slower than their latency budget"))
+	if err != nil {
+		return nil, err
+	}
 	sub.register()
 
 	return tp.Shutdown, nil
@@ -50,6 +61,8 @@ type subscriber struct {
 	httpSpans sync.Map // rid -> trace.Span
 	gqlSpans  sync.Map // rid -> trace.Span
 	grpcSpans sync.Map // rid -> trace.Span
+
+	slaExceeded metric.Int64Counter
 }
 
 func (s *subscriber) register() {
@@ -112,6 +125,7 @@ func (s *subscriber) register() {
 			semconv.RPCServiceKey.String(e.Service),
 			semconv.RPCMethodKey.String(e.Method),
 			attribute.String("net.peer.name", e.Target),
+			attribute.Int("rpc.batch_size", e.BatchSize),
 		)
 		s.grpcSpans.Store(rid, span)
 	})
@@ -129,4 +143,28 @@ func (s *subscriber) register() {
 		}
 		span.End()
 	})
+
+	eventbus.Subscribe(func(ctx context.Context, e events.SLAExceeded) {
+		attrs := []attribute.KeyValue{attribute.String("graphql.operation.name", e.OperationName)}
+		if e.Field != "" {
+			attrs = append(attrs,
+				attribute.String("graphql.field", e.ObjectType+"."+e.Field),
+				semconv.RPCMethodKey.String(e.Method),
+			)
+		}
+		s.slaExceeded.Add(ctx, 1, metric.WithAttributes(attrs...))
+
+		rid, _ := reqid.FromContext(ctx)
+		v, ok := s.gqlSpans.Load(rid)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		span.SetAttributes(attribute.Bool("protograph.sla.exceeded", true))
+		span.AddEvent("sla.exceeded", trace.WithAttributes(append(attrs,
+			attribute.Int("graphql.tasks", e.Tasks),
+			attribute.Int64("sla.budget_ms", e.Budget.Milliseconds()),
+			attribute.Int64("sla.duration_ms", e.Duration.Milliseconds()),
+		)...))
+	})
 }
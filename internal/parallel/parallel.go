@@ -0,0 +1,38 @@
+// Package parallel runs independent steps of a build concurrently.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Do calls f for every index in [0, n) on at most GOMAXPROCS goroutines and
+// waits for all calls. It returns the error of the lowest failing index, so the
+// result does not depend on scheduling; f must store its results by index for
+// the same reason.
+func Do(n int, f func(i int) error) error {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	errs := make([]error, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = f(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	var calls atomic.Int32
+	out := make([]int, 100)
+	err := Do(len(out), func(i int) error {
+		calls.Add(1)
+		out[i] = i * i
+		if i == 30 || i == 70 {
+			return fmt.Errorf("item %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "item 30" {
+		t.Fatalf("err = %v, want the error of the lowest index", err)
+	}
+	if calls.Load() != 100 {
+		t.Fatalf("%d calls, want 100", calls.Load())
+	}
+	for i, v := range out {
+		if v != i*i {
+			t.Fatalf("out[%d] = %d", i, v)
+		}
+	}
+	if err := Do(0, func(int) error { return errors.New("called") }); err != nil {
+		t.Fatalf("no items: %v", err)
+	}
+}
@@ -1,13 +1,33 @@
 package protoreg
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hanpama/protograph/internal/compute"
+	"github.com/hanpama/protograph/internal/grpcrt"
 	"github.com/hanpama/protograph/internal/ir"
+	"github.com/hanpama/protograph/internal/parallel"
 	"github.com/jhump/protoreflect/v2/protobuilder"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 // Build converts an ir project to a grpcrt.Registry implementation
 func Build(p *ir.Project) (*Registry, error) {
+	return build(p, nil)
+}
+
+// BuildWithFiles is Build reusing the file descriptors in files instead of
+// generating them, e.g. those of a build cache. files must hold the
+// FileDescriptorSet of a registry built from the same project.
+func BuildWithFiles(p *ir.Project, files *protoregistry.Files) (*Registry, error) {
+	return build(p, files)
+}
+
+func build(p *ir.Project, files *protoregistry.Files) (*Registry, error) {
 	// reg := newRegistry()
 
 	b := &builder{
@@ -19,12 +39,16 @@ func Build(p *ir.Project) (*Registry, error) {
 		scalarMapping:             make(map[string]string),
 		protoGQLTypeMap:           make(map[protoreflect.Name]string),
 		protoGQLFieldMap:          make(map[[2]protoreflect.Name][2]string),
+		protoGQLEnumValueMap:      make(map[[2]protoreflect.Name]string),
 
 		singleResolverMethods:   make(map[[2]string][2]string),
 		batchResolverMethods:    make(map[[2]string][2]string),
 		singleLoaderMethodsByID: make(map[ir.LoaderID][2]string),
 		batchLoaderMethodsByID:  make(map[ir.LoaderID][2]string),
 		fieldLoaderIDs:          make(map[[2]string]ir.LoaderID),
+
+		fallbackServiceBuilders: make(map[[2]string]*protobuilder.ServiceBuilder),
+		fallbackResolverMethods: make(map[[2]string][][2]string),
 	}
 
 	// Pass 1: create file builders for each service
@@ -57,9 +81,13 @@ func Build(p *ir.Project) (*Registry, error) {
 				}
 				b.addObjectSourceMessage(irSvc.ID, def.Object)
 			} else if def.Interface != nil {
-				b.addInterfaceSourceMessage(irSvc.ID, def.Interface)
+				if def.Interface.Envelope != ir.EnvelopeAny {
+					b.addInterfaceSourceMessage(irSvc.ID, def.Interface)
+				}
 			} else if def.Union != nil {
-				b.addUnionSourceMessage(irSvc.ID, def.Union)
+				if def.Union.Envelope != ir.EnvelopeAny {
+					b.addUnionSourceMessage(irSvc.ID, def.Union)
+				}
 			} else if def.Input != nil {
 				b.addInputObjectMessage(irSvc.ID, def.Input)
 			} else if def.Enum != nil {
@@ -73,9 +101,9 @@ func Build(p *ir.Project) (*Registry, error) {
 	for _, def := range p.Definitions {
 		if def.Object != nil {
 			b.addObjectSourceMessageFields(def.Object)
-		} else if def.Interface != nil {
+		} else if def.Interface != nil && def.Interface.Envelope != ir.EnvelopeAny {
 			b.addInterfaceSourceMessageFields(def.Interface)
-		} else if def.Union != nil {
+		} else if def.Union != nil && def.Union.Envelope != ir.EnvelopeAny {
 			b.addUnionSourceMessageFields(def.Union)
 		} else if def.Input != nil {
 			b.addInputObjectMessageFields(def.Input)
@@ -90,20 +118,63 @@ func Build(p *ir.Project) (*Registry, error) {
 	reg := &Registry{
 		fileDescriptors:           []protoreflect.FileDescriptor{},
 		sourceFieldDescriptors:    map[[2]string]protoreflect.FieldDescriptor{},
+		sourceFieldPaths:          map[[2]string][]protoreflect.FieldDescriptor{},
 		singleResolverDescriptors: map[[2]string]protoreflect.MethodDescriptor{},
 		batchResolverDescriptors:  map[[2]string]protoreflect.MethodDescriptor{},
 		singleLoaderDescriptors:   map[[2]string]protoreflect.MethodDescriptor{},
 		batchLoaderDescriptors:    map[[2]string]protoreflect.MethodDescriptor{},
+		keyedLoaders:              map[protoreflect.FullName]struct{}{},
+		dataPaths:                 map[protoreflect.FullName][]protoreflect.FieldDescriptor{},
 		requestFieldSourceMap:     map[[2]string]map[string]string{},
+		requestFieldSourceExprs:   map[[2]string]map[string]*compute.Expr{},
 		sourceMessageDescriptors:  map[string]protoreflect.MessageDescriptor{},
+		messageObjectTypes:        map[protoreflect.FullName]string{},
+		discriminators:            map[string]grpcrt.TypeDiscriminator{},
+		unknownEnums:              map[string]grpcrt.UnknownEnumPolicy{},
+		graphqlEnumValues:         map[protoreflect.FullName]string{},
+		protoEnumValues:           map[protoreflect.FullName]map[string]protoreflect.Name{},
+		idFields:                  map[protoreflect.FullName]struct{}{},
+
+		fallbackResolverDescriptors: map[[2]string][]protoreflect.MethodDescriptor{},
+		nodeFields:                  map[[2]string]struct{}{},
+		globalIDFields:              map[[2]string]struct{}{},
+		singleNodeLoaderDescriptors: map[string]protoreflect.MethodDescriptor{},
+		batchNodeLoaderDescriptors:  map[string]protoreflect.MethodDescriptor{},
+		connections:                 map[[2]string]string{},
+
+		computedFields:      map[[2]string]*compute.Expr{},
+		constFields:         map[[2]string]any{},
+		sourceFieldDefaults: map[[2]string]any{},
+		errorPolicies:       map[[2]string]grpcrt.ErrorPolicy{},
+		cachePolicies:       map[[2]string]grpcrt.CachePolicy{},
+		metadataArgs:        map[[2]string]map[string]string{},
+		priorities:          map[[2]string]grpcrt.Priority{},
+		timeouts:            map[[2]string]time.Duration{},
+		slas:                map[[2]string]time.Duration{},
 	}
 
-	// Build file descriptors and populate registry
-	for _, fb := range b.serviceFileBuilders {
-		fd, err := fb.Build()
-		if err != nil {
-			return nil, err
+	// Build file descriptors concurrently, in service ID order, and populate
+	// registry
+	ids := make([]ir.ServiceID, 0, len(b.serviceFileBuilders))
+	for id := range b.serviceFileBuilders {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	fds := make([]protoreflect.FileDescriptor, len(ids))
+	err := parallel.Do(len(ids), func(i int) error {
+		fb := b.serviceFileBuilders[ids[i]]
+		var err error
+		if files != nil {
+			fds[i], err = files.FindFileByPath(fb.Path())
+		} else {
+			fds[i], err = fb.Build()
 		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fds {
 		reg.fileDescriptors = append(reg.fileDescriptors, fd)
 
 		// Populate source field descriptors
@@ -114,6 +185,9 @@ func Build(p *ir.Project) (*Registry, error) {
 			gqlType := b.protoGQLTypeMap[msg.Name()]
 			if gqlType != "" {
 				reg.sourceMessageDescriptors[gqlType] = msg
+				if p.Definitions[gqlType].Object != nil {
+					reg.messageObjectTypes[msg.FullName()] = gqlType
+				}
 				fields := msg.Fields()
 				for j := 0; j < fields.Len(); j++ {
 					field := fields.Get(j)
@@ -124,6 +198,23 @@ func Build(p *ir.Project) (*Registry, error) {
 			}
 		}
 
+		// Map enum values between their proto and GraphQL names
+		enums := fd.Enums()
+		for i := 0; i < enums.Len(); i++ {
+			enum := enums.Get(i)
+			values := enum.Values()
+			for j := 0; j < values.Len(); j++ {
+				value := values.Get(j)
+				if gqlValue, ok := b.protoGQLEnumValueMap[[2]protoreflect.Name{enum.Name(), value.Name()}]; ok {
+					reg.graphqlEnumValues[value.FullName()] = gqlValue
+					if reg.protoEnumValues[enum.FullName()] == nil {
+						reg.protoEnumValues[enum.FullName()] = map[string]protoreflect.Name{}
+					}
+					reg.protoEnumValues[enum.FullName()][gqlValue] = value.Name()
+				}
+			}
+		}
+
 		// Populate method descriptors using stored mappings
 		services := fd.Services()
 		for i := 0; i < services.Len(); i++ {
@@ -141,12 +232,7 @@ func Build(p *ir.Project) (*Registry, error) {
 					// Populate request field source mapping from IR
 					if def, ok := b.project.Definitions[gqlNames[0]]; ok && def.Object != nil {
 						if fld, ok := def.Object.Fields[gqlNames[1]]; ok && fld.ResolveByResolver != nil && len(fld.ResolveByResolver.With) > 0 {
-							// copy map
-							mp := make(map[string]string, len(fld.ResolveByResolver.With))
-							for k, v := range fld.ResolveByResolver.With {
-								mp[k] = v
-							}
-							reg.requestFieldSourceMap[gqlNames] = mp
+							reg.setRequestMapping(gqlNames, fld.ResolveByResolver.With)
 						}
 					}
 				}
@@ -157,11 +243,7 @@ func Build(p *ir.Project) (*Registry, error) {
 					// Populate request field source mapping from IR (batch resolver uses same request args shape)
 					if def, ok := b.project.Definitions[gqlNames[0]]; ok && def.Object != nil {
 						if fld, ok := def.Object.Fields[gqlNames[1]]; ok && fld.ResolveByResolver != nil && len(fld.ResolveByResolver.With) > 0 {
-							mp := make(map[string]string, len(fld.ResolveByResolver.With))
-							for k, v := range fld.ResolveByResolver.With {
-								mp[k] = v
-							}
-							reg.requestFieldSourceMap[gqlNames] = mp
+							reg.setRequestMapping(gqlNames, fld.ResolveByResolver.With)
 						}
 					}
 				}
@@ -214,20 +296,269 @@ func Build(p *ir.Project) (*Registry, error) {
 				// Populate request field source mapping for loader fields from IR
 				if def, ok := b.project.Definitions[gqlField[0]]; ok && def.Object != nil {
 					if fld, ok := def.Object.Fields[gqlField[1]]; ok && fld.ResolveByLoader != nil && len(fld.ResolveByLoader.With) > 0 {
-						mp := make(map[string]string, len(fld.ResolveByLoader.With))
-						for k, v := range fld.ResolveByLoader.With {
-							mp[k] = v
-						}
-						reg.requestFieldSourceMap[gqlField] = mp
+						reg.setRequestMapping(gqlField, fld.ResolveByLoader.With)
 					}
 				}
 			}
 		}
 	}
 
+	for loaderID, irl := range p.Loaders {
+		if svcMethod, ok := b.batchLoaderMethodsByID[loaderID]; ok && irl.Keyed {
+			reg.keyedLoaders[findMethodDescriptor(reg.fileDescriptors, svcMethod).FullName()] = struct{}{}
+		}
+		if irl.DataPath == "" {
+			continue
+		}
+		if svcMethod, ok := b.batchLoaderMethodsByID[loaderID]; ok {
+			reg.setDataPath(findMethodDescriptor(reg.fileDescriptors, svcMethod), true, irl.DataPath)
+		}
+		if svcMethod, ok := b.singleLoaderMethodsByID[loaderID]; ok {
+			reg.setDataPath(findMethodDescriptor(reg.fileDescriptors, svcMethod), false, irl.DataPath)
+		}
+	}
+	for field, methods := range b.fallbackResolverMethods {
+		for _, svcMethod := range methods {
+			reg.fallbackResolverDescriptors[field] = append(reg.fallbackResolverDescriptors[field], findMethodDescriptor(reg.fileDescriptors, svcMethod))
+		}
+	}
+	for _, irr := range p.Resolvers {
+		if irr.DataPath == "" {
+			continue
+		}
+		key := [2]string{irr.Parent, irr.Field}
+		if md := reg.batchResolverDescriptors[key]; md != nil {
+			reg.setDataPath(md, true, irr.DataPath)
+		}
+		if md := reg.singleResolverDescriptors[key]; md != nil {
+			reg.setDataPath(md, false, irr.DataPath)
+		}
+	}
+
+	// Route @node fields: every implementer is dispatched to its `id` loader
+	for _, def := range p.Definitions {
+		if def.Object == nil {
+			continue
+		}
+		for _, fld := range def.Object.Fields {
+			if fld.ResolveByNode == nil {
+				continue
+			}
+			reg.nodeFields[[2]string{def.Object.Name, fld.Name}] = struct{}{}
+			for typeName, loaderID := range fld.ResolveByNode.Loaders {
+				reg.globalIDFields[[2]string{typeName, "id"}] = struct{}{}
+				if svcMethod, ok := b.singleLoaderMethodsByID[loaderID]; ok {
+					reg.singleNodeLoaderDescriptors[typeName] = findMethodDescriptor(reg.fileDescriptors, svcMethod)
+				}
+				if svcMethod, ok := b.batchLoaderMethodsByID[loaderID]; ok {
+					reg.batchNodeLoaderDescriptors[typeName] = findMethodDescriptor(reg.fileDescriptors, svcMethod)
+				}
+			}
+		}
+	}
+
+	// Unions flattened into one message are resolved from its discriminator field
+	for _, def := range p.Definitions {
+		if def.Union == nil || def.Union.Discriminator == nil {
+			continue
+		}
+		types := make(map[string]string, len(def.Union.Discriminator.Values))
+		for member, value := range def.Union.Discriminator.Values {
+			types[value] = member
+		}
+		reg.SetTypeDiscriminator(def.Union.Name, grpcrt.TypeDiscriminator{
+			Field: nameProtoField(def.Union.Discriminator.Field),
+			Types: types,
+		})
+	}
+
+	if def, ok := p.Definitions[ir.IDType.Name]; ok {
+		switch def.Scalar.MappedToProtoType {
+		case "int64":
+			reg.idType = grpcrt.IDInt64
+		case "uuid":
+			reg.idType = grpcrt.IDUUID
+		}
+	}
+	for _, fb := range b.idFields {
+		reg.idFields[protobuilder.FullName(fb)] = struct{}{}
+	}
+
+	for _, def := range p.Definitions {
+		if def.Enum != nil && def.Enum.OnUnknown != nil {
+			reg.unknownEnums[def.Enum.Name] = unknownEnumPolicy(def.Enum.OnUnknown)
+		}
+	}
+
+	// Fields served by the gateway: @source paths, literals and computed expressions, all validated by the IR builder
+	for _, def := range p.Definitions {
+		if def.Object == nil {
+			continue
+		}
+		for _, fld := range def.Object.Fields {
+			key := [2]string{def.Object.Name, fld.Name}
+			if fld.ResolveBySource != nil && fld.ResolveBySource.SourceField != fld.Name {
+				reg.sourceFieldPaths[key] = sourcePathDescriptors(p, reg, def.Object.Name, fld.ResolveBySource.SourceField)
+			}
+			if fld.ResolveByConst != nil {
+				reg.constFields[key] = fld.ResolveByConst.Value
+			}
+			if fld.ConnectionOf != nil {
+				reg.connections[key] = fld.Type.OfType.Named // TConnection!
+			}
+			if fld.ResolveBySource != nil && fld.ResolveBySource.Default != nil {
+				reg.sourceFieldDefaults[key] = fld.ResolveBySource.Default
+			}
+			if fld.OnError != nil {
+				reg.errorPolicies[key] = errorPolicy(fld.OnError)
+			}
+			if fld.Cache != nil {
+				reg.cachePolicies[key] = cachePolicy(fld.Cache)
+			}
+			switch fld.Priority {
+			case ir.PriorityHigh:
+				reg.priorities[key] = grpcrt.PriorityHigh
+			case ir.PriorityLow:
+				reg.priorities[key] = grpcrt.PriorityLow
+			}
+			if fld.TimeoutMs > 0 {
+				reg.timeouts[key] = time.Duration(fld.TimeoutMs) * time.Millisecond
+			}
+			if fld.SLAMs > 0 {
+				reg.slas[key] = time.Duration(fld.SLAMs) * time.Millisecond
+			}
+			for _, arg := range fld.Args {
+				if arg.MetadataKey == "" {
+					continue
+				}
+				if reg.metadataArgs[key] == nil {
+					reg.metadataArgs[key] = map[string]string{}
+				}
+				reg.metadataArgs[key][arg.Name] = arg.MetadataKey
+			}
+			if fld.ResolveByCompute == nil {
+				continue
+			}
+			expr, err := compute.Parse(fld.ResolveByCompute.Expr)
+			if err != nil {
+				panic(fmt.Sprintf("protoreg: invalid @compute expression on %s.%s: %v", def.Object.Name, fld.Name, err))
+			}
+			reg.computedFields[key] = expr
+		}
+	}
+
 	return reg, nil
 }
 
+// setRequestMapping records the With mapping of a resolver or loader field: plain
+// entries copy a parent field, the others are parsed as expressions over them.
+func (r *Registry) setRequestMapping(key [2]string, with map[string]string) {
+	mp := make(map[string]string, len(with))
+	exprs := map[string]*compute.Expr{}
+	for reqField, value := range with {
+		if !ir.IsWithExpression(value) {
+			mp[reqField] = value
+			continue
+		}
+		expr, err := compute.Parse(value)
+		if err != nil {
+			panic(fmt.Sprintf("protoreg: invalid mapping expression for %s on %s.%s: %v", reqField, key[0], key[1], err))
+		}
+		exprs[reqField] = expr
+	}
+	r.requestFieldSourceMap[key] = mp
+	if len(exprs) > 0 {
+		r.requestFieldSourceExprs[key] = exprs
+	}
+}
+
+// setDataPath records the fields leading from the responses of md, or from the
+// elements of its batches, to the result declared with a `data` path.
+func (r *Registry) setDataPath(md protoreflect.MethodDescriptor, batch bool, dataPath string) {
+	msg := md.Output()
+	if batch {
+		msg = msg.Fields().ByName("batches").Message()
+	}
+	var path []protoreflect.FieldDescriptor
+	desc := msg
+	for _, segment := range strings.Split(dataPath, ".") {
+		fd := desc.Fields().ByName(nameProtoField(segment))
+		path = append(path, fd)
+		desc = fd.Message()
+	}
+	r.dataPaths[msg.FullName()] = path
+}
+
+// unknownEnumPolicy converts an IR @onUnknown policy for the runtime.
+func unknownEnumPolicy(p *ir.UnknownEnumPolicy) grpcrt.UnknownEnumPolicy {
+	switch p.Action {
+	case ir.UnknownEnumActionString:
+		return grpcrt.UnknownEnumPolicy{Action: grpcrt.UnknownEnumString}
+	case ir.UnknownEnumActionValue:
+		return grpcrt.UnknownEnumPolicy{Action: grpcrt.UnknownEnumValue, Value: p.Value}
+	}
+	return grpcrt.UnknownEnumPolicy{Action: grpcrt.UnknownEnumNull}
+}
+
+// errorPolicy converts an IR @onError policy for the runtime.
+func errorPolicy(p *ir.ErrorPolicy) grpcrt.ErrorPolicy {
+	switch p.Action {
+	case ir.ErrorActionNull:
+		return grpcrt.ErrorPolicy{Action: grpcrt.ErrorNull}
+	case ir.ErrorActionDefault:
+		return grpcrt.ErrorPolicy{Action: grpcrt.ErrorDefault, Value: p.Value}
+	}
+	return grpcrt.ErrorPolicy{Action: grpcrt.ErrorPropagate}
+}
+
+// cachePolicy converts an IR @cache policy, whose durations the IR builder validated.
+func cachePolicy(p *ir.CachePolicy) grpcrt.CachePolicy {
+	var policy grpcrt.CachePolicy
+	policy.TTL, _ = time.ParseDuration(p.TTL)
+	if p.StaleWhileRevalidate != "" {
+		policy.StaleWhileRevalidate, _ = time.ParseDuration(p.StaleWhileRevalidate)
+	}
+	return policy
+}
+
+// sourcePathDescriptors resolves a dot-separated @source path starting at objectType.
+// Every intermediate segment is a singular object field, as checked by the IR builder.
+func sourcePathDescriptors(p *ir.Project, reg *Registry, objectType, path string) []protoreflect.FieldDescriptor {
+	var out []protoreflect.FieldDescriptor
+	cur := objectType
+	for _, seg := range strings.Split(path, ".") {
+		fd := reg.sourceFieldDescriptors[[2]string{cur, seg}]
+		if fd == nil {
+			panic(fmt.Sprintf("protoreg: missing source field %s.%s for @source path %q", cur, seg, path))
+		}
+		out = append(out, fd)
+		typ := p.Definitions[cur].Object.Fields[seg].Type
+		if typ.Kind == ir.TypeExprKindNonNull {
+			typ = typ.OfType
+		}
+		cur = typ.Named
+	}
+	return out
+}
+
+// findMethodDescriptor looks up a built method by [serviceName, methodName].
+// The pair comes from the builder's own bookkeeping, so it always exists.
+func findMethodDescriptor(fds []protoreflect.FileDescriptor, svcMethod [2]string) protoreflect.MethodDescriptor {
+	for _, fd := range fds {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			svc := services.Get(i)
+			if string(svc.Name()) != svcMethod[0] {
+				continue
+			}
+			if md := svc.Methods().ByName(protoreflect.Name(svcMethod[1])); md != nil {
+				return md
+			}
+		}
+	}
+	panic("protoreg: method not built: " + svcMethod[0] + "." + svcMethod[1])
+}
+
 type builder struct {
 	project *ir.Project
 
@@ -238,6 +569,7 @@ type builder struct {
 	scalarMapping             map[string]string
 	protoGQLTypeMap           map[protoreflect.Name]string
 	protoGQLFieldMap          map[[2]protoreflect.Name][2]string
+	protoGQLEnumValueMap      map[[2]protoreflect.Name]string
 
 	// Method mappings for resolvers: [serviceName, methodName] -> [objectType, field]
 	singleResolverMethods map[[2]string][2]string
@@ -249,4 +581,12 @@ type builder struct {
 
 	// Field to loader mappings: [objectType, field] -> LoaderID
 	fieldLoaderIDs map[[2]string]ir.LoaderID
+
+	// Fallback resolvers: services by [ServiceID, name], and the methods of each
+	// field in order: [objectType, field] -> [serviceName, methodName]...
+	fallbackServiceBuilders map[[2]string]*protobuilder.ServiceBuilder
+	fallbackResolverMethods map[[2]string][][2]string
+
+	// idFields are the request and input message fields holding IDs
+	idFields []*protobuilder.FieldBuilder
 }
@@ -75,23 +75,33 @@ func (b *builder) addEnum(irSvcID ir.ServiceID, irEnum *ir.EnumDefinition) {
 	evbs := make([]*protobuilder.EnumValueBuilder, 0, len(irEnum.Values))
 	for _, v := range irEnum.OrderedValues() {
 		name := strings.ToUpper(v.Name)
-		if name == "UNSPECIFIED" {
+		if name == "UNSPECIFIED" && v.ProtoName == "" {
+			b.protoGQLEnumValueMap[[2]protoreflect.Name{enumName, zeroName}] = v.Name
 			continue
 		}
 		// nameProtoEnumValue(irEnum.Name, v.Name)
 		prefix := strings.ToUpper(snakeCase(irEnum.Name))
+		valueName := protoreflect.Name(prefix + "_" + name)
+		if v.ProtoName != "" {
+			valueName = protoreflect.Name(v.ProtoName)
+		}
 
-		evb := protobuilder.NewEnumValue(protoreflect.Name(prefix + "_" + name))
+		evb := protobuilder.NewEnumValue(valueName)
 		evb.SetComments(comment(v.Description))
 		eb.AddValue(evb)
 		evbs = append(evbs, evb)
+		b.protoGQLEnumValueMap[[2]protoreflect.Name{enumName, valueName}] = v.Name
 	}
 	allocateEnumValueNumbers(evbs)
 
 	b.serviceFileBuilders[irSvcID].AddEnum(eb)
 }
 func (b *builder) addScalar(irScalar *ir.ScalarDefinition) {
-	b.scalarMapping[irScalar.Name] = irScalar.MappedToProtoType
+	protoType := irScalar.MappedToProtoType
+	if irScalar.Name == ir.IDType.Name && protoType == "uuid" {
+		protoType = protoreflect.BytesKind.String() // 16 bytes, converted by the runtime
+	}
+	b.scalarMapping[irScalar.Name] = protoType
 	b.protoGQLTypeMap[protoreflect.Name(irScalar.Name)] = irScalar.Name
 }
 
@@ -100,7 +110,8 @@ func (b *builder) addObjectSourceMessageFields(irObj *ir.ObjectDefinition) {
 
 	messageFields := make([]*ir.FieldDefinition, 0, len(irObj.Fields))
 	for _, field := range irObj.OrderedFields() {
-		if field.IsInternal || field.ResolveBySource != nil {
+		// Fields projected with @source are read through another path and get no message field
+		if ir.IsSourceMessageField(field) {
 			messageFields = append(messageFields, field)
 		}
 	}
@@ -144,12 +155,16 @@ func (b *builder) addInterfaceSourceMessageFields(irIface *ir.InterfaceDefinitio
 
 func (b *builder) addUnionSourceMessageFields(irUnion *ir.UnionDefinition) {
 	mb := b.definitionMessageBuilders[irUnion.Name]
+	if irUnion.Discriminator != nil {
+		b.addDiscriminatedUnionFields(mb, irUnion)
+		return
+	}
 
 	oneOfBuilder := protobuilder.NewOneof(protoreflect.Name("value"))
 	mb.AddOneOf(oneOfBuilder)
 
 	fieldBuilders := make([]*protobuilder.FieldBuilder, 0, len(irUnion.Types))
-	for _, typ := range irUnion.Types {
+	for _, typ := range irUnion.OrderedTypes() {
 		fb := protobuilder.NewField(protoreflect.Name(typ.Name), protobuilder.FieldTypeMessage(b.definitionMessageBuilders[typ.Name]))
 		fieldBuilders = append(fieldBuilders, fb)
 		oneOfBuilder.AddChoice(fb)
@@ -157,6 +172,38 @@ func (b *builder) addUnionSourceMessageFields(irUnion *ir.UnionDefinition) {
 	allocateFieldNumbers(fieldBuilders)
 }
 
+// addDiscriminatedUnionFields flattens the members of a @discriminator union into one
+// message: the string discriminator followed by the source fields of every member,
+// which the IR builder checked to agree on their types.
+func (b *builder) addDiscriminatedUnionFields(mb *protobuilder.MessageBuilder, irUnion *ir.UnionDefinition) {
+	fb := protobuilder.NewField(nameProtoField(irUnion.Discriminator.Field), protobuilder.FieldTypeString())
+	mb.AddField(fb)
+	fieldBuilders := []*protobuilder.FieldBuilder{fb}
+
+	seen := map[string]bool{}
+	for _, typ := range irUnion.OrderedTypes() {
+		for _, field := range b.project.Definitions[typ.Name].Object.OrderedFields() {
+			if !ir.IsSourceMessageField(field) || seen[field.Name] {
+				continue
+			}
+			seen[field.Name] = true
+			rt := b.resolveTypeExpr(field.Type)
+
+			fb := protobuilder.NewField(nameProtoField(field.Name), rt.fieldType)
+			fb.SetComments(comment(field.Description))
+			if rt.isOptional {
+				fb.SetOptional()
+			}
+			if rt.isRepeated {
+				fb.SetRepeated()
+			}
+			mb.AddField(fb)
+			fieldBuilders = append(fieldBuilders, fb)
+		}
+	}
+	allocateFieldNumbers(fieldBuilders)
+}
+
 func (b *builder) addInputObjectMessageFields(irInputObj *ir.InputDefinition) {
 	mb := b.definitionMessageBuilders[irInputObj.Name]
 
@@ -178,6 +225,9 @@ func (b *builder) addInputObjectMessageFields(irInputObj *ir.InputDefinition) {
 		if rt.isRepeated {
 			fb.SetRepeated()
 		}
+		if rt.isID {
+			b.idFields = append(b.idFields, fb)
+		}
 		mb.AddField(fb)
 		fieldBuilders = append(fieldBuilders, fb)
 		b.protoGQLFieldMap[[2]protoreflect.Name{mb.Name(), fb.Name()}] = [2]string{irInputObj.Name, field.Name}
@@ -1,6 +1,8 @@
 package protoreg
 
 import (
+	"strings"
+
 	"github.com/hanpama/protograph/internal/ir"
 	"github.com/jhump/protoreflect/v2/protobuilder"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -32,9 +34,11 @@ func (b *builder) addResolver(irs *ir.Service, irr *ir.ResolverDefinition) {
 	requestMB := b.createSingleMethodRequest(requestName, irr.OrderedArgs())
 
 	responseName := nameSingleResolverResponse(irr.Parent, irr.Field)
-	responseMB := b.createSingleMethodResponse(responseName, irr.ReturnType)
+	responseMB := b.createSingleMethodResponse(responseName, irr.ReturnType, irr.DataPath)
 
 	if irr.Batch {
+		addResponseError(responseMB, 2)
+
 		batchRequestName := nameBatchResolverRequest(irr.Parent, irr.Field)
 		batchRequestMB := b.createBatchMethodRequest(batchRequestName, requestMB)
 
@@ -56,6 +60,7 @@ func (b *builder) addResolver(irs *ir.Service, irr *ir.ResolverDefinition) {
 
 		// Store mapping: [serviceName, methodName] -> [objectType, field]
 		b.batchResolverMethods[[2]string{string(serviceBuilder.Name()), string(resolverName)}] = [2]string{irr.Parent, irr.Field}
+		b.addFallbackResolvers(irs, irr, resolverName, batchRequestMB, batchResponseMB)
 	} else {
 		resolverName := nameSingleResolverMethod(irr.Parent, irr.Field)
 		methodBuilder := protobuilder.NewMethod(
@@ -70,29 +75,69 @@ func (b *builder) addResolver(irs *ir.Service, irr *ir.ResolverDefinition) {
 
 		// Store mapping: [serviceName, methodName] -> [objectType, field]
 		b.singleResolverMethods[[2]string{string(serviceBuilder.Name()), string(resolverName)}] = [2]string{irr.Parent, irr.Field}
+		b.addFallbackResolvers(irs, irr, resolverName, requestMB, responseMB)
+	}
+}
+
+// addFallbackResolvers adds the resolver method, sharing its messages, to each
+// fallback service of irr. The services are declared in the file of irs.
+func (b *builder) addFallbackResolvers(irs *ir.Service, irr *ir.ResolverDefinition, name protoreflect.Name, requestMB, responseMB *protobuilder.MessageBuilder) {
+	for _, fallback := range irr.Fallbacks {
+		key := [2]string{string(irs.ID), fallback}
+		sb, ok := b.fallbackServiceBuilders[key]
+		if !ok {
+			sb = protobuilder.NewService(nameService(fallback))
+			b.fallbackServiceBuilders[key] = sb
+			b.serviceFileBuilders[irs.ID].AddService(sb)
+		}
+		methodBuilder := protobuilder.NewMethod(
+			name,
+			protobuilder.RpcTypeMessage(requestMB, false),
+			protobuilder.RpcTypeMessage(responseMB, false),
+		)
+		methodBuilder.SetComments(comment(irr.Description))
+		sb.AddMethod(methodBuilder)
+
+		field := [2]string{irr.Parent, irr.Field}
+		b.fallbackResolverMethods[field] = append(b.fallbackResolverMethods[field], [2]string{string(sb.Name()), string(name)})
 	}
 }
 
 func (b *builder) addLoader(irSvc *ir.Service, irl *ir.LoaderDefinition) {
 	serviceBuilder := b.getOrCreateService(irSvc)
 
-	requestName := nameSingleLoaderRequest(irl.TargetType, irl.KeyFields)
+	// List loaders are named after what they load: BatchLoadPostsByAuthorId
+	target := irl.TargetType
+	returnType := &ir.TypeExpr{Kind: ir.TypeExprKindNamed, Named: irl.TargetType}
+	if irl.List {
+		target = namePlural(target)
+		returnType = &ir.TypeExpr{Kind: ir.TypeExprKindList, OfType: &ir.TypeExpr{Kind: ir.TypeExprKindNonNull, OfType: returnType}}
+	}
+
+	requestName := nameSingleLoaderRequest(target, irl.KeyFields)
 	requestMB := b.createSingleMethodRequest(requestName, irl.OrderedArgs())
 
-	responseName := nameSingleLoaderResponse(irl.TargetType, irl.KeyFields)
-	responseMB := b.createSingleMethodResponse(responseName, &ir.TypeExpr{
-		Kind:  ir.TypeExprKindNamed,
-		Named: irl.TargetType,
-	})
+	responseName := nameSingleLoaderResponse(target, irl.KeyFields)
+	responseMB := b.createSingleMethodResponse(responseName, returnType, irl.DataPath)
+
+	if irl.Keyed {
+		b.addResponseKeyFields(responseMB, irl.OrderedArgs())
+	}
 
 	if irl.Batch {
-		batchRequestName := nameBatchLoaderRequest(irl.TargetType, irl.KeyFields)
+		errorNumber := 2
+		if irl.Keyed {
+			errorNumber += len(irl.Args)
+		}
+		addResponseError(responseMB, errorNumber)
+
+		batchRequestName := nameBatchLoaderRequest(target, irl.KeyFields)
 		batchRequestMB := b.createBatchMethodRequest(batchRequestName, requestMB)
 
-		batchResponseName := nameBatchLoaderResponse(irl.TargetType, irl.KeyFields)
+		batchResponseName := nameBatchLoaderResponse(target, irl.KeyFields)
 		batchResponseMB := b.createBatchMethodResponse(batchResponseName, responseMB)
 
-		loaderName := nameBatchLoaderMethod(irl.TargetType, irl.KeyFields)
+		loaderName := nameBatchLoaderMethod(target, irl.KeyFields)
 		methodBuilder := protobuilder.NewMethod(
 			loaderName,
 			protobuilder.RpcTypeMessage(batchRequestMB, false),
@@ -104,12 +149,10 @@ func (b *builder) addLoader(irSvc *ir.Service, irl *ir.LoaderDefinition) {
 		b.serviceFileBuilders[irSvc.ID].AddMessage(requestMB)
 		b.serviceFileBuilders[irSvc.ID].AddMessage(responseMB)
 
-		methodBuilder.Build()
-
 		// Store mapping: LoaderID -> [serviceName, methodName]
 		b.batchLoaderMethodsByID[irl.ID] = [2]string{string(serviceBuilder.Name()), string(loaderName)}
 	} else {
-		loaderName := nameSingleLoaderMethod(irl.TargetType, irl.KeyFields)
+		loaderName := nameSingleLoaderMethod(target, irl.KeyFields)
 		methodBuilder := protobuilder.NewMethod(
 			loaderName,
 			protobuilder.RpcTypeMessage(requestMB, false),
@@ -137,6 +180,9 @@ func (b *builder) createSingleMethodRequest(requestName protoreflect.Name, args
 		if rt.isRepeated {
 			fb.SetRepeated()
 		}
+		if rt.isID {
+			b.idFields = append(b.idFields, fb)
+		}
 		requestMB.AddField(fb)
 		requestFields = append(requestFields, fb)
 	}
@@ -144,10 +190,27 @@ func (b *builder) createSingleMethodRequest(requestName protoreflect.Name, args
 	return requestMB
 }
 
-func (b *builder) createSingleMethodResponse(responseName protoreflect.Name, returnType *ir.TypeExpr) *protobuilder.MessageBuilder {
+// createSingleMethodResponse creates a response holding the result in `data`, or
+// along dataPath, each segment but the last a nested message named after it:
+// "result.user" answers in `Result result = 1`, holding `user = 1`.
+func (b *builder) createSingleMethodResponse(responseName protoreflect.Name, returnType *ir.TypeExpr, dataPath string) *protobuilder.MessageBuilder {
 	responseMB := protobuilder.NewMessage(responseName)
+	if dataPath == "" {
+		dataPath = "data"
+	}
+	segments := strings.Split(dataPath, ".")
+	last := len(segments) - 1
+	mb := responseMB
+	for _, segment := range segments[:last] {
+		nested := protobuilder.NewMessage(protoreflect.Name(capitalize(segment)))
+		mb.AddNestedMessage(nested)
+		fb := protobuilder.NewField(nameProtoField(segment), protobuilder.FieldTypeMessage(nested))
+		fb.SetNumber(protoreflect.FieldNumber(1))
+		mb.AddField(fb)
+		mb = nested
+	}
 	rt := b.resolveTypeExpr(returnType)
-	fb := protobuilder.NewField(nameProtoField("data"), rt.fieldType)
+	fb := protobuilder.NewField(nameProtoField(segments[last]), rt.fieldType)
 	fb.SetNumber(protoreflect.FieldNumber(1))
 	if rt.isOptional {
 		fb.SetOptional()
@@ -155,10 +218,38 @@ func (b *builder) createSingleMethodResponse(responseName protoreflect.Name, ret
 	if rt.isRepeated {
 		fb.SetRepeated()
 	}
-	responseMB.AddField(fb)
+	mb.AddField(fb)
 	return responseMB
 }
 
+// addResponseKeyFields echoes the keys of a keyed loader in its response, after
+// `data`, so results can be joined to requests regardless of their order.
+func (b *builder) addResponseKeyFields(responseMB *protobuilder.MessageBuilder, keys []*ir.MethodArg) {
+	for i, key := range keys {
+		rt := b.resolveTypeExpr(key.Type)
+		fb := protobuilder.NewField(nameProtoField(key.Name), rt.fieldType)
+		fb.SetNumber(protoreflect.FieldNumber(i + 2))
+		responseMB.AddField(fb)
+	}
+}
+
+// addResponseError adds the optional `error` of batch response elements, a nested
+// `Error { code, message }` shaped like google.rpc.Status. A backend sets it to
+// fail one element while answering the others; code is a gRPC status code.
+func addResponseError(responseMB *protobuilder.MessageBuilder, number int) {
+	errorMB := protobuilder.NewMessage("Error")
+	code := protobuilder.NewField("code", protobuilder.FieldTypeInt32())
+	code.SetNumber(1)
+	message := protobuilder.NewField("message", protobuilder.FieldTypeString())
+	message.SetNumber(2)
+	errorMB.AddField(code).AddField(message)
+	responseMB.AddNestedMessage(errorMB)
+
+	fb := protobuilder.NewField(nameProtoField("error"), protobuilder.FieldTypeMessage(errorMB))
+	fb.SetNumber(protoreflect.FieldNumber(number))
+	responseMB.AddField(fb)
+}
+
 func (b *builder) createBatchMethodRequest(requestName protoreflect.Name, singleRequestMB *protobuilder.MessageBuilder) *protobuilder.MessageBuilder {
 	batchRequestMB := protobuilder.NewMessage(requestName)
 	batchRequestField := protobuilder.NewField(nameProtoField("batches"), protobuilder.FieldTypeMessage(singleRequestMB))
@@ -71,6 +71,18 @@ func nameBatchLoaderRequest(targetType string, keyFields []string) protoreflect.
 	return protoreflect.Name(string(nameBatchLoaderMethod(targetType, keyFields)) + "Request")
 }
 
+// namePlural is the English plural of a type name, good enough for method names.
+func namePlural(typeName string) string {
+	switch {
+	case strings.HasSuffix(typeName, "s"), strings.HasSuffix(typeName, "x"), strings.HasSuffix(typeName, "z"),
+		strings.HasSuffix(typeName, "ch"), strings.HasSuffix(typeName, "sh"):
+		return typeName + "es"
+	case len(typeName) > 1 && strings.HasSuffix(typeName, "y") && !strings.ContainsRune("aeiouAEIOU", rune(typeName[len(typeName)-2])):
+		return typeName[:len(typeName)-1] + "ies"
+	}
+	return typeName + "s"
+}
+
 func capitalize(s string) string {
 	if s == "" {
 		return s
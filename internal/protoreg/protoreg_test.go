@@ -4,12 +4,15 @@ import (
 	"context"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/hanpama/protograph/internal/grpcrt"
 	"github.com/hanpama/protograph/internal/ir"
 	"github.com/hanpama/protograph/internal/protoreg"
+	"github.com/hanpama/protograph/internal/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 func buildTestRegistry(t *testing.T) grpcrt.Registry {
@@ -98,6 +101,27 @@ func TestGetSourceFieldDescriptor(t *testing.T) {
 			shouldExist:  true,
 			fieldName:    "author_id",
 		},
+		{
+			name:         "PostConnection edges field",
+			objectType:   "PostConnection",
+			graphqlField: "edges",
+			shouldExist:  true,
+			fieldName:    "edges",
+		},
+		{
+			name:         "PostEdge cursor field",
+			objectType:   "PostEdge",
+			graphqlField: "cursor",
+			shouldExist:  true,
+			fieldName:    "cursor",
+		},
+		{
+			name:         "PageInfo hasNextPage field",
+			objectType:   "PageInfo",
+			graphqlField: "hasNextPage",
+			shouldExist:  true,
+			fieldName:    "has_next_page",
+		},
 		{
 			name:         "Non-existent field",
 			objectType:   "User",
@@ -390,3 +414,529 @@ func TestGetRequestFieldSourceMapping(t *testing.T) {
 		require.Len(t, mp2, 0)
 	}
 }
+
+func TestGetComputedField(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	expr, ok := reg.GetComputedField("Post", "byline")
+	require.True(t, ok, "Post.byline should be a computed field")
+	assert.Equal(t, []string{"authorId", "title"}, expr.Refs())
+
+	// Computed fields are evaluated in the gateway and not projected into the source message
+	assert.Nil(t, reg.GetSourceFieldDescriptor("Post", "byline"))
+
+	_, ok = reg.GetComputedField("Post", "title")
+	assert.False(t, ok)
+}
+
+func TestGetConstAndDefaultFields(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	v, ok := reg.GetConstField("User", "kind")
+	require.True(t, ok, "User.kind should be a const field")
+	assert.Equal(t, "person", v)
+	assert.Nil(t, reg.GetSourceFieldDescriptor("User", "kind"))
+
+	v, ok = reg.GetSourceFieldDefault("User", "role")
+	require.True(t, ok, "User.role should have a default")
+	assert.Equal(t, "GUEST", v)
+	assert.NotNil(t, reg.GetSourceFieldDescriptor("User", "role"))
+
+	_, ok = reg.GetSourceFieldDefault("User", "name")
+	assert.False(t, ok)
+}
+
+func TestGetErrorPolicy(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	p, ok := reg.GetErrorPolicy("Post", "likeCount")
+	require.True(t, ok, "Post.likeCount should have an error policy")
+	assert.Equal(t, grpcrt.ErrorPolicy{Action: grpcrt.ErrorDefault, Value: float64(0)}, p)
+
+	// Type-level policies cover the RPC fields declared by the extension
+	p, ok = reg.GetErrorPolicy("Query", "getUser")
+	require.True(t, ok, "Query.getUser should inherit the type-level policy")
+	assert.Equal(t, grpcrt.ErrorNull, p.Action)
+
+	_, ok = reg.GetErrorPolicy("Post", "author")
+	assert.False(t, ok)
+}
+
+func TestGetCachePolicy(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	p, ok := reg.GetCachePolicy("Query", "getUser")
+	require.True(t, ok, "Query.getUser should be cached")
+	assert.Equal(t, grpcrt.CachePolicy{TTL: 30 * time.Second, StaleWhileRevalidate: 5 * time.Minute}, p)
+
+	_, ok = reg.GetCachePolicy("Post", "likeCount")
+	assert.False(t, ok)
+}
+
+func TestGetMetadataArguments(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	assert.Equal(t, map[string]string{"tenant": "x-tenant-id"}, reg.GetMetadataArguments("Query", "getUser"))
+	assert.Nil(t, reg.GetMetadataArguments("Query", "searchPosts"))
+}
+
+func TestGetPriority(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	assert.Equal(t, grpcrt.PriorityHigh, reg.GetPriority("Query", "searchPosts"))
+	assert.Equal(t, grpcrt.PriorityNormal, reg.GetPriority("Query", "getUser"))
+}
+
+func TestGetTimeout(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	assert.Equal(t, 250*time.Millisecond, reg.GetTimeout("Query", "searchPosts"))
+	assert.Zero(t, reg.GetTimeout("Query", "getUser"))
+}
+
+func TestGetSLA(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	assert.Equal(t, 100*time.Millisecond, reg.GetSLA("Query", "searchPosts"))
+	assert.Zero(t, reg.GetSLA("Query", "getUser"))
+}
+
+func TestGetConnectionType(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	connType, ok := reg.GetConnectionType("Query", "searchPosts")
+	assert.True(t, ok)
+	assert.Equal(t, "PostConnection", connType)
+	_, ok = reg.GetConnectionType("Query", "getUser")
+	assert.False(t, ok)
+
+	// The resolver takes the page and returns the posts
+	md := reg.GetSingleResolverDescriptor("Query", "searchPosts")
+	require.NotNil(t, md)
+	for _, name := range []protoreflect.Name{"term", "offset", "limit"} {
+		assert.NotNil(t, md.Input().Fields().ByName(name), name)
+	}
+	assert.Nil(t, md.Input().Fields().ByName("first"))
+	data := md.Output().Fields().ByName("data")
+	assert.True(t, data.IsList())
+	assert.Equal(t, protoreflect.FullName("testdata.proto.PostSource"), data.Message().FullName())
+}
+
+func TestGetSourceFieldPath(t *testing.T) {
+	reg := buildTestRegistry(t)
+
+	path := reg.GetSourceFieldPath("Post", "viewCount")
+	require.Len(t, path, 2)
+	assert.Equal(t, "stats", string(path[0].Name()))
+	assert.Equal(t, "views", string(path[1].Name()))
+	assert.Equal(t, path[1], reg.GetSourceFieldDescriptor("PostStats", "views"))
+
+	// Projected fields have no message field of their own
+	assert.Nil(t, reg.GetSourceFieldDescriptor("Post", "viewCount"))
+	assert.Nil(t, reg.GetSourceFieldPath("Post", "title"))
+}
+
+func TestCustomRootTypes(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "shop", Name: "Shop", Content: `
+schema { query: RootQuery mutation: RootMutation }
+type RootQuery { user(id: ID!): User }
+type RootMutation { rename(id: ID!, name: String!): User }
+type User @loader { id: ID! name: String! }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	query := reg.GetSingleResolverDescriptor("RootQuery", "user")
+	require.NotNil(t, query)
+	assert.Equal(t, "ResolveRootQueryUser", string(query.Name()))
+	mutation := reg.GetSingleResolverDescriptor("RootMutation", "rename")
+	require.NotNil(t, mutation)
+	assert.Equal(t, "ResolveRootMutationRename", string(mutation.Name()))
+
+	// Root types have no source message
+	assert.Nil(t, reg.GetSourceMessageDescriptor("RootQuery"))
+	assert.NotNil(t, reg.GetSourceMessageDescriptor("User"))
+}
+
+func TestRequestFieldSourceExpressions(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "org", Name: "Org", Content: `
+schema { query: Query }
+type Query { membership(orgId: ID!, userId: ID!): Membership }
+type Membership {
+  orgId: ID!
+  userId: ID!
+  profile: Profile @load(with: { key: "orgId + ':' + userId" })
+  roles: [String!]! @resolve(with: { memberKey: "orgId + '/' + userId", org: "orgId" }, batch: true)
+}
+type Profile @loader(key: "key") { key: String! }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	exprs := reg.GetRequestFieldSourceExpressions("Membership", "profile")
+	require.Contains(t, exprs, "key")
+	assert.Equal(t, []string{"orgId", "userId"}, exprs["key"].Refs())
+	assert.Empty(t, reg.GetRequestFieldSourceMapping("Membership", "profile"))
+
+	// Plain entries stay in the source mapping
+	assert.Equal(t, map[string]string{"org": "orgId"}, reg.GetRequestFieldSourceMapping("Membership", "roles"))
+	require.Contains(t, reg.GetRequestFieldSourceExpressions("Membership", "roles"), "memberKey")
+	req := reg.GetBatchResolverDescriptor("Membership", "roles").Input()
+	assert.NotNil(t, req.Fields().ByName("batches").Message().Fields().ByName("member_key"))
+}
+
+func TestKeyedLoader(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "org", Name: "Org", Content: `
+schema { query: Query }
+type Query { member(orgId: ID!, userId: ID!): Member }
+type Member @loader(keys: ["orgId", "userId"], keyed: true) @loader(key: "email") {
+  orgId: ID!
+  userId: ID!
+  email: String!
+  team: Member @load(with: { orgId: "orgId", userId: "userId" })
+  byEmail: Member @load(with: { email: "email" })
+}
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	keyed := reg.GetBatchLoaderDescriptor("Member", "team")
+	require.NotNil(t, keyed)
+	assert.True(t, reg.IsKeyedLoader(keyed.FullName()))
+	item := keyed.Output().Fields().ByName("batches").Message().Fields()
+	assert.EqualValues(t, 1, item.ByName("data").Number())
+	assert.EqualValues(t, 2, item.ByName("org_id").Number())
+	assert.EqualValues(t, 3, item.ByName("user_id").Number())
+	assert.EqualValues(t, 4, item.ByName("error").Number())
+
+	plain := reg.GetBatchLoaderDescriptor("Member", "byEmail")
+	require.NotNil(t, plain)
+	assert.False(t, reg.IsKeyedLoader(plain.FullName()))
+	assert.Equal(t, 2, plain.Output().Fields().ByName("batches").Message().Fields().Len())
+}
+
+func TestListLoader(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "blog", Name: "Blog", Content: `
+schema { query: Query }
+type Query { user(id: ID!): User }
+type User @loader {
+  id: ID!
+  posts: [Post!]! @load(with: { authorId: "id" })
+  replies: [Reply!] @load(with: { authorId: "id" })
+}
+type Post @loader(key: "authorId", list: true) { authorId: ID! }
+type Reply @loader(key: "authorId", list: true, keyed: true) { authorId: ID! }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	posts := reg.GetBatchLoaderDescriptor("User", "posts")
+	require.NotNil(t, posts)
+	assert.Equal(t, "BatchLoadPostsByAuthorId", string(posts.Name()))
+	data := posts.Output().Fields().ByName("batches").Message().Fields().ByName("data")
+	assert.True(t, data.IsList())
+	assert.Equal(t, "PostSource", string(data.Message().Name()))
+
+	replies := reg.GetBatchLoaderDescriptor("User", "replies")
+	require.NotNil(t, replies)
+	assert.Equal(t, "BatchLoadRepliesByAuthorId", string(replies.Name()))
+	assert.True(t, reg.IsKeyedLoader(replies.FullName()))
+}
+
+func TestBatchResponseElementError(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "blog", Name: "Blog", Content: `
+schema { query: Query }
+type Query { user(id: ID!): User }
+type User @loader @loader(key: "email", batch: false) {
+  id: ID!
+  email: String!
+  score: Int! @resolve(batch: true)
+  rank: Int! @resolve
+  self: User @load(with: { id: "id" })
+  byEmail: User @load(with: { email: "email" })
+}
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	for _, md := range []protoreflect.MethodDescriptor{
+		reg.GetBatchResolverDescriptor("User", "score"),
+		reg.GetBatchLoaderDescriptor("User", "self"),
+	} {
+		require.NotNil(t, md)
+		errField := md.Output().Fields().ByName("batches").Message().Fields().ByName("error")
+		require.NotNil(t, errField, md.FullName())
+		assert.EqualValues(t, 2, errField.Number())
+		assert.Equal(t, protoreflect.Int32Kind, errField.Message().Fields().ByName("code").Kind())
+		assert.Equal(t, protoreflect.StringKind, errField.Message().Fields().ByName("message").Kind())
+	}
+
+	// Single methods answer with a status of their own
+	assert.Nil(t, reg.GetSingleResolverDescriptor("User", "rank").Output().Fields().ByName("error"))
+	assert.Nil(t, reg.GetSingleLoaderDescriptor("User", "byEmail").Output().Fields().ByName("error"))
+}
+
+func TestResponseDataPath(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "blog", Name: "Blog", Content: `
+schema { query: Query }
+type Query { user(id: ID!): User @resolve(data: "user") }
+type User @loader(key: "id", data: "result.user") {
+  id: ID!
+  friends: [User!]! @resolve(batch: true, data: "friends")
+  self: User @load(with: { id: "id" })
+}
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	resp := reg.GetSingleResolverDescriptor("Query", "user").Output()
+	assert.Nil(t, resp.Fields().ByName("data"))
+	path := reg.GetResponseDataPath(resp.FullName())
+	require.Len(t, path, 1)
+	assert.Equal(t, resp.Fields().ByName("user"), path[0])
+
+	// Batch paths start at each element of the batches
+	elem := reg.GetBatchResolverDescriptor("User", "friends").Output().Fields().ByName("batches").Message()
+	path = reg.GetResponseDataPath(elem.FullName())
+	require.Len(t, path, 1)
+	assert.Equal(t, protoreflect.Name("friends"), path[0].Name())
+	assert.True(t, path[0].IsList())
+
+	elem = reg.GetBatchLoaderDescriptor("User", "self").Output().Fields().ByName("batches").Message()
+	path = reg.GetResponseDataPath(elem.FullName())
+	require.Len(t, path, 2)
+	assert.Equal(t, protoreflect.Name("result"), path[0].Name())
+	assert.Equal(t, protoreflect.Name("Result"), path[0].Message().Name())
+	assert.Equal(t, protoreflect.Name("user"), path[1].Name())
+	assert.Equal(t, protoreflect.MessageKind, path[1].Kind())
+
+	assert.Nil(t, reg.GetResponseDataPath(reg.GetBatchResolverDescriptor("User", "friends").Output().FullName()))
+}
+
+func TestAnyEnvelope(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "pets", Name: "Pets", Content: `
+schema { query: Query }
+type Query {
+  pet(id: ID!): Pet
+  search(term: String!): [SearchResult!]!
+}
+interface Pet @envelope(kind: ANY) { id: ID! }
+union SearchResult @envelope(kind: ANY) = Dog
+type Dog implements Pet { id: ID! owner: Pet }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	// Fields of either type are google.protobuf.Any, and no envelope message is generated
+	data := reg.GetSingleResolverDescriptor("Query", "pet").Output().Fields().ByName("data")
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Any"), data.Message().FullName())
+	data = reg.GetSingleResolverDescriptor("Query", "search").Output().Fields().ByName("data")
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Any"), data.Message().FullName())
+	assert.True(t, data.IsList())
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Any"), reg.GetSourceFieldDescriptor("Dog", "owner").Message().FullName())
+	assert.Nil(t, reg.GetSourceMessageDescriptor("Pet"))
+	assert.Nil(t, reg.GetSourceMessageDescriptor("SearchResult"))
+
+	// Type URLs name source messages, mapped back to their object types
+	typeName, ok := reg.GetMessageObjectType(reg.GetSourceMessageDescriptor("Dog").FullName())
+	assert.True(t, ok)
+	assert.Equal(t, "Dog", typeName)
+	_, ok = reg.GetMessageObjectType("google.protobuf.Any")
+	assert.False(t, ok)
+}
+
+func TestMessageObjectTypeOverrides(t *testing.T) {
+	reg := buildTestRegistry(t).(*protoreg.Registry)
+	_, ok := reg.GetMessageObjectType("legacy.Account")
+	assert.False(t, ok)
+
+	reg.MapMessageObjectType("legacy.Account", "User")
+	typeName, ok := reg.GetMessageObjectType("legacy.Account")
+	assert.True(t, ok)
+	assert.Equal(t, "User", typeName)
+
+	reg.SetTypeDiscriminator("Node", grpcrt.TypeDiscriminator{Field: "kind", Types: map[string]string{"USER": "User"}})
+	d, ok := reg.GetTypeDiscriminator("Node")
+	assert.True(t, ok)
+	assert.Equal(t, protoreflect.Name("kind"), d.Field)
+}
+
+func TestDiscriminatedUnion(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "feed", Name: "Feed", Content: `
+schema { query: Query }
+type Query { feed: [FeedItem!]! }
+union FeedItem @discriminator(field: "kind", values: { Video: "VIDEO" }) = Post | Video
+type Post { id: ID! title: String! }
+type Video { id: ID! title: String! seconds: Int! }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	// One message holds the discriminator and the source fields of every member
+	fields := reg.GetSourceMessageDescriptor("FeedItem").Fields()
+	names := []protoreflect.Name{}
+	for i := 0; i < fields.Len(); i++ {
+		names = append(names, fields.Get(i).Name())
+	}
+	assert.ElementsMatch(t, []protoreflect.Name{"kind", "id", "title", "seconds"}, names)
+	assert.Equal(t, protoreflect.StringKind, fields.ByName("kind").Kind())
+	assert.Equal(t, 0, reg.GetSourceMessageDescriptor("FeedItem").Oneofs().Len())
+
+	d, ok := reg.GetTypeDiscriminator("FeedItem")
+	assert.True(t, ok)
+	assert.Equal(t, grpcrt.TypeDiscriminator{Field: "kind", Types: map[string]string{"Post": "Post", "VIDEO": "Video"}}, d)
+}
+
+func TestUnknownEnumPolicies(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "orders", Name: "Orders", Content: `
+schema { query: Query }
+type Query { status: Status channel: Channel mode: Mode }
+enum Status @onUnknown(action: VALUE, value: UNKNOWN) { PENDING UNKNOWN }
+enum Channel @onUnknown(action: STRING) { WEB }
+enum Mode { FAST }
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	p, ok := reg.GetUnknownEnumPolicy("Status")
+	assert.True(t, ok)
+	assert.Equal(t, grpcrt.UnknownEnumPolicy{Action: grpcrt.UnknownEnumValue, Value: "UNKNOWN"}, p)
+	p, ok = reg.GetUnknownEnumPolicy("Channel")
+	assert.True(t, ok)
+	assert.Equal(t, grpcrt.UnknownEnumPolicy{Action: grpcrt.UnknownEnumString}, p)
+	_, ok = reg.GetUnknownEnumPolicy("Mode")
+	assert.False(t, ok)
+}
+
+func TestEnumValueNames(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "users", Name: "Users", Content: `
+schema { query: Query }
+type Query { status: UserStatus }
+enum UserStatus {
+  UNSPECIFIED
+  ACTIVE
+  SUSPENDED @mapValue(toProtobuf: "SUSPENDED_USER")
+}
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	enum := reg.GetSingleResolverDescriptor("Query", "status").Output().Fields().ByName("data").Enum()
+	for protoName, gqlValue := range map[protoreflect.Name]string{
+		"USER_STATUS_UNSPECIFIED": "UNSPECIFIED",
+		"USER_STATUS_ACTIVE":      "ACTIVE",
+		"SUSPENDED_USER":          "SUSPENDED",
+	} {
+		value := enum.Values().ByName(protoName)
+		require.NotNil(t, value, protoName)
+		got, ok := reg.GetGraphQLEnumValue(value.FullName())
+		assert.True(t, ok)
+		assert.Equal(t, gqlValue, got)
+		name, ok := reg.GetProtoEnumValue(enum.FullName(), gqlValue)
+		assert.True(t, ok)
+		assert.Equal(t, protoName, name)
+	}
+	assert.Nil(t, enum.Values().ByName("USER_STATUS_SUSPENDED"))
+}
+
+func TestIDMapping(t *testing.T) {
+	for protoType, want := range map[string]struct {
+		kind   protoreflect.Kind
+		idType grpcrt.IDType
+	}{
+		"string": {protoreflect.StringKind, grpcrt.IDString},
+		"int64":  {protoreflect.Int64Kind, grpcrt.IDInt64},
+		"uuid":   {protoreflect.BytesKind, grpcrt.IDUUID},
+	} {
+		proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "users", Name: "Users", Content: `
+schema { query: Query }
+extend scalar ID @mapScalar(toProtobuf: "` + protoType + `")
+type Query { user(id: ID!, name: String): User }
+type User { id: ID! }
+`}}))
+		require.NoError(t, err)
+		reg, err := protoreg.Build(proj)
+		require.NoError(t, err)
+
+		assert.Equal(t, want.idType, reg.GetIDType(), protoType)
+		md := reg.GetSingleResolverDescriptor("Query", "user")
+		assert.Equal(t, want.kind, md.Input().Fields().ByName("id").Kind(), protoType)
+		assert.True(t, reg.IsIDField(md.Input().Fields().ByName("id").FullName()), protoType)
+		assert.False(t, reg.IsIDField(md.Input().Fields().ByName("name").FullName()), protoType)
+		assert.Equal(t, want.kind, reg.GetSourceFieldDescriptor("User", "id").Kind(), protoType)
+	}
+}
+
+func TestFallbackResolvers(t *testing.T) {
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{{Package: "shop", Name: "Catalog", Content: `
+schema { query: Query }
+type Query { product(id: ID!): Product @resolve(fallback: ["CatalogPrimary"]) }
+type Product {
+  id: ID!
+  price: Int! @resolve(batch: true, fallback: ["PricePrimary", "CatalogPrimary"])
+}
+`}}))
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+
+	var names []protoreflect.FullName
+	for _, md := range reg.GetFallbackResolverDescriptors("Product", "price") {
+		names = append(names, md.FullName())
+		assert.Equal(t, reg.GetBatchResolverDescriptor("Product", "price").Input(), md.Input())
+	}
+	assert.Equal(t, []protoreflect.FullName{
+		"shop.PricePrimaryService.BatchResolveProductPrice",
+		"shop.CatalogPrimaryService.BatchResolveProductPrice",
+	}, names)
+
+	fallbacks := reg.GetFallbackResolverDescriptors("Query", "product")
+	require.Len(t, fallbacks, 1)
+	assert.Equal(t, protoreflect.FullName("shop.CatalogPrimaryService.ResolveQueryProduct"), fallbacks[0].FullName())
+	assert.Nil(t, reg.GetFallbackResolverDescriptors("Product", "id"))
+}
+
+func TestVerifyCoversSchema(t *testing.T) {
+	discovery, err := ir.NewFileSystemDiscovery(context.Background(), path.Join("testdata", "schema"), "testdata.proto")
+	require.NoError(t, err)
+	proj, err := ir.Build(context.Background(), discovery)
+	require.NoError(t, err)
+	reg, err := protoreg.Build(proj)
+	require.NoError(t, err)
+	sch, err := schema.BuildFromIR(proj)
+	require.NoError(t, err)
+
+	require.NoError(t, grpcrt.Verify(reg, sch))
+}
+
+func TestBuild_FileOrder(t *testing.T) {
+	discovery, err := ir.NewFileSystemDiscovery(t.Context(), path.Join("testdata", "schema"), "testdata.proto")
+	require.NoError(t, err)
+	proj, err := ir.Build(t.Context(), discovery)
+	require.NoError(t, err)
+
+	var first []string
+	for range 5 {
+		reg, err := protoreg.Build(proj)
+		require.NoError(t, err)
+		var paths []string
+		for _, fd := range reg.GetAllServiceFiles() {
+			paths = append(paths, fd.Path())
+		}
+		if first == nil {
+			first = paths
+			require.Greater(t, len(first), 1)
+		}
+		assert.Equal(t, first, paths)
+	}
+}
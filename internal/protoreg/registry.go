@@ -1,21 +1,76 @@
 package protoreg
 
 import (
+	"sort"
+	"time"
+
+	"github.com/hanpama/protograph/internal/compute"
 	"github.com/hanpama/protograph/internal/grpcrt"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // Registry implements grpcrt.Registry
 type Registry struct {
 	fileDescriptors           []protoreflect.FileDescriptor
 	sourceFieldDescriptors    map[[2]string]protoreflect.FieldDescriptor
+	sourceFieldPaths          map[[2]string][]protoreflect.FieldDescriptor
 	singleResolverDescriptors map[[2]string]protoreflect.MethodDescriptor
 	batchResolverDescriptors  map[[2]string]protoreflect.MethodDescriptor
 	singleLoaderDescriptors   map[[2]string]protoreflect.MethodDescriptor
 	batchLoaderDescriptors    map[[2]string]protoreflect.MethodDescriptor
+	// fallbackResolverDescriptors are the methods tried in order when a resolver fails
+	fallbackResolverDescriptors map[[2]string][]protoreflect.MethodDescriptor
+	// keyedLoaders are batch loader methods whose results carry their keys
+	keyedLoaders map[protoreflect.FullName]struct{}
+	// dataPaths lead from response messages to results declared outside `data`
+	dataPaths map[protoreflect.FullName][]protoreflect.FieldDescriptor
 	// requestFieldSourceMap optionally maps (objectType, field) -> request field name -> parent source field name
-	requestFieldSourceMap    map[[2]string]map[string]string
+	requestFieldSourceMap map[[2]string]map[string]string
+	// requestFieldSourceExprs maps (objectType, field) -> request field name -> expression over parent source fields
+	requestFieldSourceExprs  map[[2]string]map[string]*compute.Expr
 	sourceMessageDescriptors map[string]protoreflect.MessageDescriptor
+	// messageObjectTypes map source messages back to their GraphQL object types
+	messageObjectTypes map[protoreflect.FullName]string
+	// discriminators resolve abstract types from a field of their values
+	discriminators map[string]grpcrt.TypeDiscriminator
+	// idType is the proto type IDs are mapped to, and idFields the request and
+	// input message fields holding IDs
+	idType   grpcrt.IDType
+	idFields map[protoreflect.FullName]struct{}
+	// unknownEnums hold @onUnknown policies of enums
+	unknownEnums map[string]grpcrt.UnknownEnumPolicy
+	// graphqlEnumValues and protoEnumValues map enum values between their proto and GraphQL names
+	graphqlEnumValues map[protoreflect.FullName]string
+	protoEnumValues   map[protoreflect.FullName]map[string]protoreflect.Name
+
+	// nodeFields are @node fields; globalIDFields are Node implementer ids exposed as global IDs
+	nodeFields                  map[[2]string]struct{}
+	globalIDFields              map[[2]string]struct{}
+	singleNodeLoaderDescriptors map[string]protoreflect.MethodDescriptor
+	batchNodeLoaderDescriptors  map[string]protoreflect.MethodDescriptor
+
+	// connections map @connection fields to their connection types
+	connections map[[2]string]string
+
+	// computedFields are @compute expressions keyed by (objectType, field)
+	computedFields map[[2]string]*compute.Expr
+	// constFields and sourceFieldDefaults hold @const / @default literals
+	constFields         map[[2]string]any
+	sourceFieldDefaults map[[2]string]any
+	// errorPolicies hold @onError policies of fields resolved over RPC
+	errorPolicies map[[2]string]grpcrt.ErrorPolicy
+	// cachePolicies hold @cache policies of fields resolved over RPC
+	cachePolicies map[[2]string]grpcrt.CachePolicy
+	// metadataArgs map @metadata arguments to their gRPC metadata keys
+	metadataArgs map[[2]string]map[string]string
+	// priorities hold @priority levels other than normal
+	priorities map[[2]string]grpcrt.Priority
+	// timeouts hold @timeout deadlines of fields resolved over RPC
+	timeouts map[[2]string]time.Duration
+	// slas hold @sla latency budgets of fields resolved over RPC
+	slas map[[2]string]time.Duration
 }
 
 // GetAllServiceFiles implements grpcrt.Registry.
@@ -23,16 +78,57 @@ func (r *Registry) GetAllServiceFiles() []protoreflect.FileDescriptor {
 	return r.fileDescriptors
 }
 
+// FileDescriptorSet returns the service files and the files they import,
+// dependencies first, so that BuildWithFiles can reuse them.
+func (r *Registry) FileDescriptorSet() *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	files := append([]protoreflect.FileDescriptor(nil), r.fileDescriptors...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path() < files[j].Path() })
+	for _, fd := range files {
+		add(fd)
+	}
+	return set
+}
+
 // GetBatchLoaderDescriptor implements grpcrt.Registry.
 func (r *Registry) GetBatchLoaderDescriptor(objectType string, field string) protoreflect.MethodDescriptor {
 	return r.batchLoaderDescriptors[[2]string{objectType, field}]
 }
 
+// IsKeyedLoader implements grpcrt.Registry.
+func (r *Registry) IsKeyedLoader(method protoreflect.FullName) bool {
+	_, ok := r.keyedLoaders[method]
+	return ok
+}
+
+// GetResponseDataPath implements grpcrt.Registry.
+func (r *Registry) GetResponseDataPath(response protoreflect.FullName) []protoreflect.FieldDescriptor {
+	return r.dataPaths[response]
+}
+
 // GetBatchResolverDescriptor implements grpcrt.Registry.
 func (r *Registry) GetBatchResolverDescriptor(objectType string, field string) protoreflect.MethodDescriptor {
 	return r.batchResolverDescriptors[[2]string{objectType, field}]
 }
 
+// GetFallbackResolverDescriptors implements grpcrt.Registry.
+func (r *Registry) GetFallbackResolverDescriptors(objectType, field string) []protoreflect.MethodDescriptor {
+	return r.fallbackResolverDescriptors[[2]string{objectType, field}]
+}
+
 // GetSingleLoaderDescriptor implements grpcrt.Registry.
 func (r *Registry) GetSingleLoaderDescriptor(objectType string, field string) protoreflect.MethodDescriptor {
 	return r.singleLoaderDescriptors[[2]string{objectType, field}]
@@ -48,6 +144,11 @@ func (r *Registry) GetSourceFieldDescriptor(objectType string, graphqlField stri
 	return r.sourceFieldDescriptors[[2]string{objectType, graphqlField}]
 }
 
+// GetSourceFieldPath implements grpcrt.Registry.
+func (r *Registry) GetSourceFieldPath(objectType string, graphqlField string) []protoreflect.FieldDescriptor {
+	return r.sourceFieldPaths[[2]string{objectType, graphqlField}]
+}
+
 // GetRequestFieldSourceMapping implements grpcrt.Registry.
 // For now, return nil unless the builder populates this in the future.
 func (r *Registry) GetRequestFieldSourceMapping(objectType, field string) map[string]string {
@@ -57,6 +158,11 @@ func (r *Registry) GetRequestFieldSourceMapping(objectType, field string) map[st
 	return r.requestFieldSourceMap[[2]string{objectType, field}]
 }
 
+// GetRequestFieldSourceExpressions implements grpcrt.Registry.
+func (r *Registry) GetRequestFieldSourceExpressions(objectType, field string) map[string]*compute.Expr {
+	return r.requestFieldSourceExprs[[2]string{objectType, field}]
+}
+
 // GetSourceMessageDescriptor implements grpcrt.Registry.
 func (r *Registry) GetSourceMessageDescriptor(objectType string) protoreflect.MessageDescriptor {
 	if r == nil {
@@ -65,4 +171,136 @@ func (r *Registry) GetSourceMessageDescriptor(objectType string) protoreflect.Me
 	return r.sourceMessageDescriptors[objectType]
 }
 
+// GetMessageObjectType implements grpcrt.Registry.
+func (r *Registry) GetMessageObjectType(message protoreflect.FullName) (string, bool) {
+	objectType, ok := r.messageObjectTypes[message]
+	return objectType, ok
+}
+
+// GetTypeDiscriminator implements grpcrt.Registry.
+func (r *Registry) GetTypeDiscriminator(abstractType string) (grpcrt.TypeDiscriminator, bool) {
+	d, ok := r.discriminators[abstractType]
+	return d, ok
+}
+
+// GetIDType implements grpcrt.Registry.
+func (r *Registry) GetIDType() grpcrt.IDType {
+	return r.idType
+}
+
+// IsIDField implements grpcrt.Registry.
+func (r *Registry) IsIDField(field protoreflect.FullName) bool {
+	_, ok := r.idFields[field]
+	return ok
+}
+
+// GetUnknownEnumPolicy implements grpcrt.Registry.
+func (r *Registry) GetUnknownEnumPolicy(enumType string) (grpcrt.UnknownEnumPolicy, bool) {
+	p, ok := r.unknownEnums[enumType]
+	return p, ok
+}
+
+// GetGraphQLEnumValue implements grpcrt.Registry.
+func (r *Registry) GetGraphQLEnumValue(value protoreflect.FullName) (string, bool) {
+	name, ok := r.graphqlEnumValues[value]
+	return name, ok
+}
+
+// GetProtoEnumValue implements grpcrt.Registry.
+func (r *Registry) GetProtoEnumValue(enum protoreflect.FullName, graphqlValue string) (protoreflect.Name, bool) {
+	name, ok := r.protoEnumValues[enum][graphqlValue]
+	return name, ok
+}
+
+// MapMessageObjectType resolves values of message to objectType, for runtimes
+// receiving messages other than the generated source messages. It must be called
+// before the registry is used.
+func (r *Registry) MapMessageObjectType(message protoreflect.FullName, objectType string) {
+	r.messageObjectTypes[message] = objectType
+}
+
+// SetTypeDiscriminator resolves the values of abstractType by one of their fields.
+// It must be called before the registry is used.
+func (r *Registry) SetTypeDiscriminator(abstractType string, d grpcrt.TypeDiscriminator) {
+	r.discriminators[abstractType] = d
+}
+
+// GetConnectionType implements grpcrt.Registry.
+func (r *Registry) GetConnectionType(objectType, field string) (string, bool) {
+	connType, ok := r.connections[[2]string{objectType, field}]
+	return connType, ok
+}
+
+// IsNodeField implements grpcrt.Registry.
+func (r *Registry) IsNodeField(objectType, field string) bool {
+	_, ok := r.nodeFields[[2]string{objectType, field}]
+	return ok
+}
+
+// IsGlobalIDField implements grpcrt.Registry.
+func (r *Registry) IsGlobalIDField(objectType, field string) bool {
+	_, ok := r.globalIDFields[[2]string{objectType, field}]
+	return ok
+}
+
+// GetSingleNodeLoaderDescriptor implements grpcrt.Registry.
+func (r *Registry) GetSingleNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor {
+	return r.singleNodeLoaderDescriptors[typeName]
+}
+
+// GetBatchNodeLoaderDescriptor implements grpcrt.Registry.
+func (r *Registry) GetBatchNodeLoaderDescriptor(typeName string) protoreflect.MethodDescriptor {
+	return r.batchNodeLoaderDescriptors[typeName]
+}
+
+// GetComputedField implements grpcrt.Registry.
+func (r *Registry) GetComputedField(objectType, field string) (*compute.Expr, bool) {
+	e, ok := r.computedFields[[2]string{objectType, field}]
+	return e, ok
+}
+
+// GetConstField implements grpcrt.Registry.
+func (r *Registry) GetConstField(objectType, field string) (any, bool) {
+	v, ok := r.constFields[[2]string{objectType, field}]
+	return v, ok
+}
+
+// GetSourceFieldDefault implements grpcrt.Registry.
+func (r *Registry) GetSourceFieldDefault(objectType, field string) (any, bool) {
+	v, ok := r.sourceFieldDefaults[[2]string{objectType, field}]
+	return v, ok
+}
+
+// GetErrorPolicy implements grpcrt.Registry.
+func (r *Registry) GetErrorPolicy(objectType, field string) (grpcrt.ErrorPolicy, bool) {
+	p, ok := r.errorPolicies[[2]string{objectType, field}]
+	return p, ok
+}
+
+// GetCachePolicy implements grpcrt.Registry.
+func (r *Registry) GetCachePolicy(objectType, field string) (grpcrt.CachePolicy, bool) {
+	p, ok := r.cachePolicies[[2]string{objectType, field}]
+	return p, ok
+}
+
+// GetMetadataArguments implements grpcrt.Registry.
+func (r *Registry) GetMetadataArguments(objectType, field string) map[string]string {
+	return r.metadataArgs[[2]string{objectType, field}]
+}
+
+// GetPriority implements grpcrt.Registry.
+func (r *Registry) GetPriority(objectType, field string) grpcrt.Priority {
+	return r.priorities[[2]string{objectType, field}]
+}
+
+// GetTimeout implements grpcrt.Registry.
+func (r *Registry) GetTimeout(objectType, field string) time.Duration {
+	return r.timeouts[[2]string{objectType, field}]
+}
+
+// GetSLA implements grpcrt.Registry.
+func (r *Registry) GetSLA(objectType, field string) time.Duration {
+	return r.slas[[2]string{objectType, field}]
+}
+
 var _ grpcrt.Registry = (*Registry)(nil)
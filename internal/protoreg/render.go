@@ -3,28 +3,39 @@ package protoreg
 import (
 	"os"
 	"path"
+	"strings"
 
 	"github.com/jhump/protoreflect/v2/protoprint"
 )
 
 // Render generates proto definitions based on the provided registry and outputs them to the specified directory.
 func Render(r *Registry, outDir string) error {
-	pp := protoprint.Printer{}
-
-	for _, fd := range r.GetAllServiceFiles() {
-		fp := path.Join(outDir, fd.Path())
+	files, err := RenderFiles(r)
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		fp := path.Join(outDir, name)
 		if err := os.MkdirAll(path.Dir(fp), 0755); err != nil {
 			return err
 		}
-		openedFile, err := os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-		if err != nil {
+		if err := os.WriteFile(fp, []byte(content), 0644); err != nil {
 			return err
 		}
-		defer openedFile.Close()
+	}
+	return nil
+}
 
-		if err = pp.PrintProtoFile(fd, openedFile); err != nil {
-			return err
+// RenderFiles returns the proto definitions of the registry keyed by file path.
+func RenderFiles(r *Registry) (map[string]string, error) {
+	pp := protoprint.Printer{}
+	files := map[string]string{}
+	for _, fd := range r.GetAllServiceFiles() {
+		var b strings.Builder
+		if err := pp.PrintProtoFile(fd, &b); err != nil {
+			return nil, err
 		}
+		files[fd.Path()] = b.String()
 	}
-	return nil
+	return files, nil
 }
@@ -4,12 +4,15 @@ import (
 	"github.com/hanpama/protograph/internal/ir"
 	"github.com/jhump/protoreflect/v2/protobuilder"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type resolvedType struct {
 	isRepeated bool
 	isOptional bool
-	fieldType  *protobuilder.FieldType
+	// isID is set for ID values, which the runtime parses per the ID mapping
+	isID      bool
+	fieldType *protobuilder.FieldType
 }
 
 func (b *builder) resolveTypeExpr(typeExpr *ir.TypeExpr) resolvedType {
@@ -18,6 +21,7 @@ func (b *builder) resolveTypeExpr(typeExpr *ir.TypeExpr) resolvedType {
 		return resolvedType{
 			isRepeated: false,
 			isOptional: true,
+			isID:       typeExpr.Named == ir.IDType.Name,
 			fieldType:  b.mapNamedType(typeExpr.Named),
 		}
 	case ir.TypeExprKindList:
@@ -25,6 +29,7 @@ func (b *builder) resolveTypeExpr(typeExpr *ir.TypeExpr) resolvedType {
 		return resolvedType{
 			isRepeated: true,
 			isOptional: false,
+			isID:       elemType.isID,
 			fieldType:  elemType.fieldType,
 		}
 	case ir.TypeExprKindNonNull:
@@ -32,6 +37,7 @@ func (b *builder) resolveTypeExpr(typeExpr *ir.TypeExpr) resolvedType {
 		return resolvedType{
 			isRepeated: innerType.isRepeated,
 			isOptional: false,
+			isID:       innerType.isID,
 			fieldType:  innerType.fieldType,
 		}
 	}
@@ -42,6 +48,9 @@ func (b *builder) mapNamedType(typeName string) *protobuilder.FieldType {
 	if protoType, ok := b.scalarMapping[typeName]; ok {
 		return protobuilder.FieldTypeScalar(scalars[protoType])
 	}
+	if b.isAnyEnvelope(typeName) {
+		return protobuilder.FieldTypeImportedMessage((&anypb.Any{}).ProtoReflect().Descriptor())
+	}
 	if mb, ok := b.definitionMessageBuilders[typeName]; ok {
 		return protobuilder.FieldTypeMessage(mb)
 	}
@@ -51,6 +60,14 @@ func (b *builder) mapNamedType(typeName string) *protobuilder.FieldType {
 	panic("unreachable: " + typeName)
 }
 
+// isAnyEnvelope reports whether values of an interface or union travel as
+// google.protobuf.Any (@envelope(kind: ANY)).
+func (b *builder) isAnyEnvelope(typeName string) bool {
+	def := b.project.Definitions[typeName]
+	return def.Interface != nil && def.Interface.Envelope == ir.EnvelopeAny ||
+		def.Union != nil && def.Union.Envelope == ir.EnvelopeAny
+}
+
 var scalars = map[string]protoreflect.Kind{
 	protoreflect.BoolKind.String():     protoreflect.BoolKind,
 	protoreflect.EnumKind.String():     protoreflect.EnumKind,
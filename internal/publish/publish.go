@@ -0,0 +1,225 @@
+// Package publish pushes a compiled gateway schema to a schema registry over HTTP so
+// that CI can track which schema version each gateway deployment serves.
+//
+// Three request formats are supported:
+//   - FormatJSON posts {"sdl", "version", "tag", "service", "metadata"} to the endpoint
+//     as is; any 2xx response is a success.
+//   - FormatHive sends the GraphQL Hive `schemaPublish` mutation.
+//   - FormatApollo sends the Apollo Studio `uploadSchema` mutation for a graph ref
+//     (`graph@variant`, variant defaulting to Tag).
+//
+// For the GraphQL formats a 2xx response that carries `errors` is reported as failure.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Format selects the request body sent to the registry.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatHive   Format = "hive"
+	FormatApollo Format = "apollo"
+)
+
+// ParseFormat converts a command-line value into a Format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatHive, FormatApollo:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown registry format %q (want json, hive or apollo)", s)
+}
+
+// Schema is a compiled schema and the version information recorded with it.
+type Schema struct {
+	SDL      string
+	Version  string            // e.g. a commit SHA or release number
+	Tag      string            // e.g. "production"; Apollo variant
+	Service  string            // service or graph name, when the registry tracks several
+	Metadata map[string]string // free-form annotations (JSON format only)
+}
+
+// Client publishes schemas to one registry endpoint.
+type Client struct {
+	endpoint string
+	format   Format
+	method   string
+	header   http.Header
+	http     *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHeader adds a request header, typically for authentication.
+func WithHeader(name, value string) Option {
+	return func(c *Client) { c.header.Add(name, value) }
+}
+
+// WithMethod overrides the HTTP method of the JSON format (default POST).
+func WithMethod(method string) Option {
+	return func(c *Client) { c.method = method }
+}
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// NewClient returns a client publishing to endpoint in the given format.
+func NewClient(endpoint string, format Format, opts ...Option) *Client {
+	c := &Client{
+		endpoint: endpoint,
+		format:   format,
+		method:   http.MethodPost,
+		header:   http.Header{},
+		http:     http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Body returns the request body that Publish would send, for dry runs.
+func (c *Client) Body(s Schema) ([]byte, error) {
+	switch c.format {
+	case FormatJSON:
+		return json.Marshal(jsonBody{SDL: s.SDL, Version: s.Version, Tag: s.Tag, Service: s.Service, Metadata: s.Metadata})
+	case FormatHive:
+		input := map[string]any{"sdl": s.SDL, "commit": s.Version, "author": "protograph"}
+		if s.Service != "" {
+			input["service"] = s.Service
+		}
+		return json.Marshal(graphqlBody{Query: hivePublishMutation, Variables: map[string]any{"input": input}})
+	case FormatApollo:
+		if s.Service == "" {
+			return nil, fmt.Errorf("apollo format requires a graph id (service)")
+		}
+		graph, variant, ok := strings.Cut(s.Service, "@")
+		if !ok {
+			variant = s.Tag
+		}
+		if variant == "" {
+			variant = "current"
+		}
+		vars := map[string]any{"id": graph, "tag": variant, "schemaDocument": s.SDL}
+		if s.Version != "" {
+			vars["gitContext"] = map[string]any{"commit": s.Version}
+		}
+		return json.Marshal(graphqlBody{Query: apolloUploadMutation, Variables: vars})
+	}
+	return nil, fmt.Errorf("unknown registry format %q", c.format)
+}
+
+// Publish sends the schema to the registry. Non-2xx responses and GraphQL errors
+// are returned as errors that include the registry's response.
+func (c *Client) Publish(ctx context.Context, s Schema) error {
+	body, err := c.Body(s)
+	if err != nil {
+		return err
+	}
+	method := c.method
+	if c.format != FormatJSON {
+		method = http.MethodPost // GraphQL APIs only accept POST mutations
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = c.header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish schema: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("publish schema: registry responded %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if c.format == FormatJSON {
+		return nil
+	}
+	return graphqlErrors(respBody)
+}
+
+type jsonBody struct {
+	SDL      string            `json:"sdl"`
+	Version  string            `json:"version,omitempty"`
+	Tag      string            `json:"tag,omitempty"`
+	Service  string            `json:"service,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type graphqlBody struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+const hivePublishMutation = `mutation schemaPublish($input: SchemaPublishInput!) {
+  schemaPublish(input: $input) {
+    __typename
+    ... on SchemaPublishError { valid }
+    ... on SchemaPublishSuccess { valid }
+  }
+}`
+
+const apolloUploadMutation = `mutation UploadSchema($id: ID!, $tag: String!, $schemaDocument: String!, $gitContext: GitContextInput) {
+  service(id: $id) {
+    uploadSchema(tag: $tag, schemaDocument: $schemaDocument, gitContext: $gitContext) {
+      code
+      success
+      message
+    }
+  }
+}`
+
+// graphqlErrors reports top-level GraphQL errors and unsuccessful publish payloads.
+func graphqlErrors(body []byte) error {
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Data struct {
+			SchemaPublish *struct {
+				Typename string `json:"__typename"`
+				Valid    bool   `json:"valid"`
+			} `json:"schemaPublish"`
+			Service *struct {
+				UploadSchema *struct {
+					Success bool   `json:"success"`
+					Message string `json:"message"`
+				} `json:"uploadSchema"`
+			} `json:"service"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("publish schema: invalid registry response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("publish schema: %s", strings.Join(msgs, "; "))
+	}
+	if p := resp.Data.SchemaPublish; p != nil && (strings.HasSuffix(p.Typename, "Error") || !p.Valid) {
+		return fmt.Errorf("publish schema: registry rejected the schema (%s)", p.Typename)
+	}
+	if s := resp.Data.Service; s != nil && s.UploadSchema != nil && !s.UploadSchema.Success {
+		return fmt.Errorf("publish schema: %s", s.UploadSchema.Message)
+	}
+	return nil
+}
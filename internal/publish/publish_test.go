@@ -0,0 +1,92 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sdl = "type Query {\n  hello: String\n}\n"
+
+// registry records the last request and replies with the given status and body.
+func registry(t *testing.T, status int, reply string) (*httptest.Server, *http.Request, *map[string]any) {
+	t.Helper()
+	var got http.Request
+	body := map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r
+		raw, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(raw, &body))
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got, &body
+}
+
+func TestPublishJSON(t *testing.T) {
+	srv, req, body := registry(t, http.StatusCreated, "")
+	c := NewClient(srv.URL+"/schemas", FormatJSON, WithMethod(http.MethodPut), WithHeader("Authorization", "Bearer secret"))
+
+	err := c.Publish(context.Background(), Schema{SDL: sdl, Version: "abc123", Tag: "prod", Metadata: map[string]string{"ci": "true"}})
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Equal(t, "/schemas", req.URL.Path)
+	require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+	require.Equal(t, map[string]any{
+		"sdl":      sdl,
+		"version":  "abc123",
+		"tag":      "prod",
+		"metadata": map[string]any{"ci": "true"},
+	}, *body)
+}
+
+func TestPublishHTTPError(t *testing.T) {
+	srv, _, _ := registry(t, http.StatusUnauthorized, "bad token\n")
+	err := NewClient(srv.URL, FormatJSON).Publish(context.Background(), Schema{SDL: sdl})
+	require.EqualError(t, err, "publish schema: registry responded 401 Unauthorized: bad token")
+}
+
+func TestPublishHive(t *testing.T) {
+	srv, req, body := registry(t, http.StatusOK, `{"data":{"schemaPublish":{"__typename":"SchemaPublishSuccess","valid":true}}}`)
+	c := NewClient(srv.URL, FormatHive, WithMethod(http.MethodPut))
+
+	require.NoError(t, c.Publish(context.Background(), Schema{SDL: sdl, Version: "abc123", Service: "gateway"}))
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Contains(t, (*body)["query"], "schemaPublish(input: $input)")
+	require.Equal(t, map[string]any{"input": map[string]any{
+		"sdl": sdl, "commit": "abc123", "author": "protograph", "service": "gateway",
+	}}, (*body)["variables"])
+
+	srv, _, _ = registry(t, http.StatusOK, `{"data":{"schemaPublish":{"__typename":"SchemaPublishError","valid":false}}}`)
+	err := NewClient(srv.URL, FormatHive).Publish(context.Background(), Schema{SDL: sdl})
+	require.EqualError(t, err, "publish schema: registry rejected the schema (SchemaPublishError)")
+}
+
+func TestPublishApollo(t *testing.T) {
+	srv, _, body := registry(t, http.StatusOK, `{"data":{"service":{"uploadSchema":{"success":true}}}}`)
+	c := NewClient(srv.URL, FormatApollo)
+
+	require.NoError(t, c.Publish(context.Background(), Schema{SDL: sdl, Version: "abc123", Tag: "staging", Service: "shop"}))
+	require.Equal(t, map[string]any{
+		"id": "shop", "tag": "staging", "schemaDocument": sdl,
+		"gitContext": map[string]any{"commit": "abc123"},
+	}, (*body)["variables"])
+
+	// An explicit variant in the graph ref wins over the tag
+	raw, err := c.Body(Schema{SDL: sdl, Tag: "staging", Service: "shop@prod"})
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"tag":"prod"`)
+
+	srv, _, _ = registry(t, http.StatusOK, `{"errors":[{"message":"invalid api key"}]}`)
+	err = NewClient(srv.URL, FormatApollo).Publish(context.Background(), Schema{SDL: sdl, Service: "shop"})
+	require.EqualError(t, err, "publish schema: invalid api key")
+
+	_, err = c.Body(Schema{SDL: sdl})
+	require.EqualError(t, err, "apollo format requires a graph id (service)")
+}
@@ -0,0 +1,405 @@
+// Package replay records the interactions between the executor and an
+// executor.Runtime and serves them back later, so integration tests and bug
+// reports can run deterministically without live backends.
+//
+// A Recorder wraps a runtime and writes one JSON line per call: the method, its
+// type and field, the source value, the arguments and the returned value or
+// error. A Replayer reads such a file and answers identical calls with the
+// recorded results.
+//
+// Runtime values that are not JSON literals (proto messages, maps, structs) are
+// recorded as opaque references {"$ref": n}. When the executor passes such a
+// value back as a source, the call is keyed by its reference, and on replay the
+// Replayer returns Ref values in its place. Lists are recorded element-wise.
+// Replay therefore needs every runtime call of the operation to be recorded,
+// including ResolveSync and SerializeLeafValue.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// Call names the runtime method of an Interaction.
+type Call string
+
+const (
+	CallResolveSync        Call = "resolveSync"
+	CallResolveAsync       Call = "resolveAsync" // one task of BatchResolveAsync
+	CallResolveType        Call = "resolveType"
+	CallUnionConcrete      Call = "unionConcreteValue"
+	CallInterfaceConcrete  Call = "interfaceConcreteValue"
+	CallSerializeLeafValue Call = "serializeLeafValue"
+)
+
+// Interaction is one recorded runtime call. Type holds the object type for field
+// resolution, the abstract type for type resolution and the scalar or enum name
+// for serialization.
+type Interaction struct {
+	Call   Call            `json:"call"`
+	Type   string          `json:"type"`
+	Field  string          `json:"field,omitempty"`
+	Source json.RawMessage `json:"source,omitempty"`
+	Args   json.RawMessage `json:"args,omitempty"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func (in Interaction) key() string {
+	return string(in.Call) + "\x00" + in.Type + "\x00" + in.Field + "\x00" + string(in.Source) + "\x00" + string(in.Args)
+}
+
+// Ref stands in for a recorded non-literal runtime value during replay.
+type Ref struct{ ID int }
+
+// refJSON is the recorded form of a Ref.
+type refJSON struct {
+	Ref int `json:"$ref"`
+}
+
+// Recorder is an executor.Runtime that forwards to a base runtime and records
+// every call. It is safe for concurrent use.
+type Recorder struct {
+	base executor.Runtime
+
+	mu   sync.Mutex
+	enc  *json.Encoder
+	err  error
+	refs map[any]int
+	live []any // keeps referenced values alive so their addresses are not reused
+}
+
+var _ executor.Runtime = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder writing JSON lines to w.
+func NewRecorder(base executor.Runtime, w io.Writer) *Recorder {
+	return &Recorder{base: base, enc: json.NewEncoder(w), refs: map[any]int{}}
+}
+
+// Err returns the first error writing the recording, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) ResolveSync(ctx context.Context, objectType, field string, source any, args map[string]any) (any, error) {
+	value, err := r.base.ResolveSync(ctx, objectType, field, source, args)
+	r.record(Interaction{Call: CallResolveSync, Type: objectType, Field: field}, source, args, value, err)
+	return value, err
+}
+
+func (r *Recorder) BatchResolveAsync(ctx context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	results := r.base.BatchResolveAsync(ctx, tasks)
+	for i, t := range tasks {
+		if i < len(results) {
+			r.record(Interaction{Call: CallResolveAsync, Type: t.ObjectType, Field: t.Field}, t.Source, t.Args, results[i].Value, results[i].Error)
+		}
+	}
+	return results
+}
+
+func (r *Recorder) ResolveType(ctx context.Context, abstractType string, value any) (string, error) {
+	name, err := r.base.ResolveType(ctx, abstractType, value)
+	r.record(Interaction{Call: CallResolveType, Type: abstractType}, value, nil, name, err)
+	return name, err
+}
+
+func (r *Recorder) ResolveUnionConcreteValue(ctx context.Context, unionTypeName string, value any) (any, error) {
+	out, err := r.base.ResolveUnionConcreteValue(ctx, unionTypeName, value)
+	r.record(Interaction{Call: CallUnionConcrete, Type: unionTypeName}, value, nil, out, err)
+	return out, err
+}
+
+func (r *Recorder) ResolveInterfaceConcreteValue(ctx context.Context, interfaceTypeName string, value any) (any, error) {
+	out, err := r.base.ResolveInterfaceConcreteValue(ctx, interfaceTypeName, value)
+	r.record(Interaction{Call: CallInterfaceConcrete, Type: interfaceTypeName}, value, nil, out, err)
+	return out, err
+}
+
+func (r *Recorder) SerializeLeafValue(ctx context.Context, scalarOrEnumTypeName string, value any) (any, error) {
+	out, err := r.base.SerializeLeafValue(ctx, scalarOrEnumTypeName, value)
+	r.record(Interaction{Call: CallSerializeLeafValue, Type: scalarOrEnumTypeName}, value, nil, out, err)
+	return out, err
+}
+
+// DescribeMethod forwards to the base runtime so explain output keeps the
+// backend methods while recording.
+func (r *Recorder) DescribeMethod(objectType, field string) (string, bool, bool) {
+	if d, ok := r.base.(executor.MethodDescriber); ok {
+		return d.DescribeMethod(objectType, field)
+	}
+	return "", false, false
+}
+
+func (r *Recorder) record(in Interaction, source any, args map[string]any, value any, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	in.Source = r.marshal(r.encodeInput(source))
+	if args != nil {
+		in.Args = r.marshal(args)
+	}
+	if err != nil {
+		in.Error = err.Error()
+	} else {
+		in.Value = r.marshal(r.encodeOutput(value))
+	}
+	if encErr := r.enc.Encode(in); encErr != nil && r.err == nil {
+		r.err = encErr
+	}
+}
+
+func (r *Recorder) marshal(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return json.RawMessage("null")
+	}
+	return b
+}
+
+// encodeInput encodes a value passed to the runtime, referring to values the
+// runtime returned earlier by their reference.
+func (r *Recorder) encodeInput(v any) any {
+	if isLiteral(v) {
+		return v
+	}
+	if ref, ok := v.(Ref); ok {
+		return refJSON{Ref: ref.ID}
+	}
+	if id, ok := identity(v); ok {
+		if n, ok := r.refs[id]; ok {
+			return refJSON{Ref: n}
+		}
+	}
+	if items, ok := listItems(v); ok {
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = r.encodeInput(item)
+		}
+		return out
+	}
+	return fmt.Sprintf("%T", v) // unknown to the recording; keyed by type only
+}
+
+// encodeOutput encodes a value returned by the runtime, assigning references to
+// non-literal values.
+func (r *Recorder) encodeOutput(v any) any {
+	if isLiteral(v) {
+		return v
+	}
+	if items, ok := listItems(v); ok {
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = r.encodeOutput(item)
+		}
+		return out
+	}
+	id, ok := identity(v)
+	if !ok {
+		return fmt.Sprintf("%T", v)
+	}
+	n, ok := r.refs[id]
+	if !ok {
+		n = len(r.refs) + 1
+		r.refs[id] = n
+		r.live = append(r.live, v)
+	}
+	return refJSON{Ref: n}
+}
+
+// Replayer is an executor.Runtime answering calls from a recording. Calls that
+// were recorded more than once are answered in recorded order, repeating the
+// last answer when exhausted. It is safe for concurrent use.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	next         map[string]int
+}
+
+var _ executor.Runtime = (*Replayer)(nil)
+
+// NewReplayer reads a recording written by a Recorder.
+func NewReplayer(rd io.Reader) (*Replayer, error) {
+	p := &Replayer{interactions: map[string][]Interaction{}, next: map[string]int{}}
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var in Interaction
+		if err := json.Unmarshal(sc.Bytes(), &in); err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+		k := in.key()
+		p.interactions[k] = append(p.interactions[k], in)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	return p, nil
+}
+
+func (p *Replayer) ResolveSync(_ context.Context, objectType, field string, source any, args map[string]any) (any, error) {
+	return p.answer(Interaction{Call: CallResolveSync, Type: objectType, Field: field}, source, args)
+}
+
+func (p *Replayer) BatchResolveAsync(_ context.Context, tasks []executor.AsyncResolveTask) []executor.AsyncResolveResult {
+	results := make([]executor.AsyncResolveResult, len(tasks))
+	for i, t := range tasks {
+		results[i].Value, results[i].Error = p.answer(Interaction{Call: CallResolveAsync, Type: t.ObjectType, Field: t.Field}, t.Source, t.Args)
+	}
+	return results
+}
+
+func (p *Replayer) ResolveType(_ context.Context, abstractType string, value any) (string, error) {
+	v, err := p.answer(Interaction{Call: CallResolveType, Type: abstractType}, value, nil)
+	if err != nil {
+		return "", err
+	}
+	name, _ := v.(string)
+	return name, nil
+}
+
+func (p *Replayer) ResolveUnionConcreteValue(_ context.Context, unionTypeName string, value any) (any, error) {
+	return p.answer(Interaction{Call: CallUnionConcrete, Type: unionTypeName}, value, nil)
+}
+
+func (p *Replayer) ResolveInterfaceConcreteValue(_ context.Context, interfaceTypeName string, value any) (any, error) {
+	return p.answer(Interaction{Call: CallInterfaceConcrete, Type: interfaceTypeName}, value, nil)
+}
+
+func (p *Replayer) SerializeLeafValue(_ context.Context, scalarOrEnumTypeName string, value any) (any, error) {
+	return p.answer(Interaction{Call: CallSerializeLeafValue, Type: scalarOrEnumTypeName}, value, nil)
+}
+
+func (p *Replayer) answer(in Interaction, source any, args map[string]any) (any, error) {
+	var err error
+	if in.Source, err = json.Marshal(replayInput(source)); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	if args != nil {
+		if in.Args, err = json.Marshal(args); err != nil {
+			return nil, fmt.Errorf("replay: %w", err)
+		}
+	}
+	k := in.key()
+
+	p.mu.Lock()
+	recorded := p.interactions[k]
+	i := p.next[k]
+	if i < len(recorded)-1 {
+		p.next[k] = i + 1
+	}
+	p.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("replay: no recorded %s for %s", in.Call, describe(in))
+	}
+	out := recorded[i]
+	if out.Error != "" {
+		return nil, errors.New(out.Error)
+	}
+	var v any
+	if err := json.Unmarshal(out.Value, &v); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	return decodeOutput(v), nil
+}
+
+func describe(in Interaction) string {
+	s := in.Type
+	if in.Field != "" {
+		s += "." + in.Field
+	}
+	return s + " (source " + string(in.Source) + ", args " + string(in.Args) + ")"
+}
+
+// replayInput mirrors Recorder.encodeInput for values produced by the Replayer.
+func replayInput(v any) any {
+	switch v := v.(type) {
+	case Ref:
+		return refJSON{Ref: v.ID}
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = replayInput(item)
+		}
+		return out
+	}
+	if isLiteral(v) {
+		return v
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// decodeOutput turns recorded references back into Ref values.
+func decodeOutput(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if n, ok := v["$ref"].(float64); ok && len(v) == 1 {
+			return Ref{ID: int(n)}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = decodeOutput(item)
+		}
+	}
+	return v
+}
+
+// isLiteral reports whether v is recorded as a JSON literal.
+func isLiteral(v any) bool {
+	switch v.(type) {
+	case nil, bool, string, []byte,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+// listItems returns the elements of slice values, which the executor completes
+// element by element.
+func listItems(v any) ([]any, bool) {
+	if items, ok := v.([]any); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+type pointerIdentity struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// identity returns a comparable key identifying v across calls.
+func identity(v any) (any, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Pointer, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return pointerIdentity{typ: rv.Type(), ptr: rv.Pointer()}, true
+	}
+	if rv.Comparable() {
+		return v, true
+	}
+	return nil, false
+}
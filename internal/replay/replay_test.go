@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+	"github.com/stretchr/testify/require"
+)
+
+const query = `{ posts(first: 2) { title tags } broken }`
+
+func execute(t *testing.T, rt executor.Runtime) *executor.ExecutionResult {
+	t.Helper()
+	sch, err := schema.BuildFromSDL(`
+type Query { posts(first: Int): [Post!]! broken: String }
+type Post { title: String! tags: [String!] }`)
+	require.NoError(t, err)
+	doc, err := language.ParseQuery(query)
+	require.NoError(t, err)
+	return executor.NewExecutor(rt, sch).ExecuteRequest(context.Background(), doc, "", nil, nil)
+}
+
+func TestRecordReplay(t *testing.T) {
+	base := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.posts": executor.NewMockValueResolver([]any{
+			map[string]any{"title": "first", "tags": []string{"go"}},
+			map[string]any{"title": "second"},
+		}),
+		"Query.broken": executor.NewMockErrorResolver(errors.New("backend down")),
+		"Post.title": func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(map[string]any)["title"], nil
+		},
+		"Post.tags": func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(map[string]any)["tags"], nil
+		},
+	})
+
+	var recording bytes.Buffer
+	rec := NewRecorder(base, &recording)
+	want := execute(t, rec)
+	require.NoError(t, rec.Err())
+	require.Len(t, want.Errors, 1)
+
+	rp, err := NewReplayer(bytes.NewReader(recording.Bytes()))
+	require.NoError(t, err)
+	got := execute(t, rp)
+	require.Equal(t, want, got)
+
+	// Replaying twice serves the same answers
+	require.Equal(t, want, execute(t, rp))
+}
+
+func TestReplayMissingInteraction(t *testing.T) {
+	rp, err := NewReplayer(bytes.NewReader(nil))
+	require.NoError(t, err)
+	res := execute(t, rp)
+	require.NotEmpty(t, res.Errors)
+	require.Contains(t, res.Errors[0].Message, "replay: no recorded resolveAsync for Query.posts")
+}
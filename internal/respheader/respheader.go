@@ -0,0 +1,115 @@
+// Package respheader collects selected response metadata of backend calls, such
+// as cache-control hints or deprecation warnings, so the server can surface them
+// as HTTP response headers.
+package respheader
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// key is the context key for the collector.
+type key struct{}
+
+// Collector gathers the allowed keys of backend response headers and trailers
+// for one HTTP request. It is safe for concurrent use.
+type Collector struct {
+	allowed map[string]struct{}
+
+	mu     sync.Mutex
+	header http.Header
+}
+
+// NewContext returns a copy of parent carrying a collector of the metadata keys
+// in names, which are case-insensitive.
+func NewContext(parent context.Context, names []string) (context.Context, *Collector) {
+	c := &Collector{allowed: make(map[string]struct{}, len(names)), header: http.Header{}}
+	for _, name := range names {
+		c.allowed[strings.ToLower(name)] = struct{}{}
+	}
+	return context.WithValue(parent, key{}, c), c
+}
+
+// FromContext extracts the collector from ctx.
+// It returns the collector and whether it was present.
+func FromContext(ctx context.Context) (*Collector, bool) {
+	c, _ := ctx.Value(key{}).(*Collector)
+	return c, c != nil
+}
+
+// Detach returns a copy of ctx without the collector, for background calls
+// whose responses must not reach the HTTP response.
+func Detach(ctx context.Context) context.Context {
+	return context.WithValue(ctx, key{}, (*Collector)(nil))
+}
+
+// WithCollector returns a copy of parent carrying c.
+func WithCollector(parent context.Context, c *Collector) context.Context {
+	return context.WithValue(parent, key{}, c)
+}
+
+// Merge returns a collector of the keys any of cs allows, for a call made on
+// behalf of several requests. CopyTo then hands each of their collectors its
+// share of what the call returned.
+func Merge(cs ...*Collector) *Collector {
+	m := &Collector{allowed: map[string]struct{}{}, header: http.Header{}}
+	for _, c := range cs {
+		for k := range c.allowed {
+			m.allowed[k] = struct{}{}
+		}
+	}
+	return m
+}
+
+// CopyTo adds the headers collected by c that dst allows to dst.
+func (c *Collector) CopyTo(dst *Collector) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	md := make(metadata.MD, len(c.header))
+	for name, vs := range c.header {
+		md[strings.ToLower(name)] = vs
+	}
+	dst.Add(md)
+}
+
+// Add records the allowed keys of md. Values repeated by several calls are kept
+// once.
+func (c *Collector) Add(md metadata.MD) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, vs := range md {
+		if _, ok := c.allowed[k]; !ok {
+			continue
+		}
+		name := http.CanonicalHeaderKey(k)
+		for _, v := range vs {
+			if !contains(c.header[name], v) {
+				c.header[name] = append(c.header[name], v)
+			}
+		}
+	}
+}
+
+// WriteTo adds the collected headers to h.
+func (c *Collector) WriteTo(h http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, vs := range c.header {
+		for _, v := range vs {
+			h.Add(name, v)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package respheader
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCollectorKeepsAllowedKeysOnce(t *testing.T) {
+	ctx, _ := NewContext(context.Background(), []string{"Cache-Control", "deprecation"})
+	c, ok := FromContext(ctx)
+	if !ok {
+		t.Fatalf("expected a collector in context")
+	}
+	c.Add(metadata.Pairs("cache-control", "max-age=60", "x-internal", "secret"))
+	c.Add(metadata.Pairs("cache-control", "max-age=60", "deprecation", "true"))
+
+	got := http.Header{}
+	c.WriteTo(got)
+	want := http.Header{"Cache-Control": {"max-age=60"}, "Deprecation": {"true"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("headers mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatalf("unexpected collector in empty context")
+	}
+	if _, ok := FromContext(Detach(ctx)); ok {
+		t.Fatalf("unexpected collector in detached context")
+	}
+}
+
+func TestMergeCopiesEachCollectorsShare(t *testing.T) {
+	_, a := NewContext(context.Background(), []string{"cache-control"})
+	_, b := NewContext(context.Background(), []string{"Deprecation"})
+	shared := Merge(a, b)
+	shared.Add(metadata.Pairs("cache-control", "max-age=60", "deprecation", "true", "x-internal", "secret"))
+	shared.CopyTo(a)
+	shared.CopyTo(b)
+
+	for _, tc := range []struct {
+		c    *Collector
+		want http.Header
+	}{
+		{a, http.Header{"Cache-Control": {"max-age=60"}}},
+		{b, http.Header{"Deprecation": {"true"}}},
+	} {
+		got := http.Header{}
+		tc.c.WriteTo(got)
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("headers mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
@@ -2,52 +2,122 @@ package schema
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"sort"
-	"strings"
 
 	"github.com/hanpama/protograph/internal/ir"
 )
 
+// BuildOption configures BuildFromIR.
+type BuildOption func(*buildOptions)
+
+type buildOptions struct {
+	inheritInterfaceFields bool
+}
+
+// WithInterfaceFieldInheritance copies interface fields omitted by an implementing
+// object or interface onto the implementer instead of reporting them as missing.
+func WithInterfaceFieldInheritance() BuildOption {
+	return func(o *buildOptions) { o.inheritInterfaceFields = true }
+}
+
 // BuildFromIR builds an executable GraphQL schema from the ir project.
 // It merges all extensions into their base definitions and strips protograph-specific
-// directives.
-func BuildFromIR(p *ir.Project) (*Schema, error) {
-	s := NewSchema("")
-	s.SetQueryType(p.Schema.QueryType).
+// directives; uses of directives declared in SDL are kept as Metadata. Interface implementations are verified so that incompatible field
+// signatures fail the build instead of surfacing at runtime, and the result is
+// checked with Validate and frozen; use Clone to derive a modifiable copy.
+func BuildFromIR(p *ir.Project, opts ...BuildOption) (*Schema, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	b := NewSchemaBuilder(p.Schema.Description).
+		SetQueryType(p.Schema.QueryType).
 		SetMutationType(p.Schema.MutationType).
 		SetSubscriptionType(p.Schema.SubscriptionType)
-	// Builtins
-	s.AddType(stringType).
-		AddType(intType).
-		AddType(floatType).
-		AddType(booleanType).
-		AddType(idType)
-	s.AddDirective(includeDirective).
+	// Builtins come first; a project carrying its own builtin scalar definitions
+	// takes their place.
+	for _, builtin := range []*Type{stringType, intType, floatType, booleanType, idType} {
+		if def := p.Definitions[builtin.Name]; def != nil && def.Scalar != nil {
+			b.AddType(buildScalar(def.Scalar))
+		} else {
+			b.AddType(builtin)
+		}
+	}
+	b.AddDirective(includeDirective).
 		AddDirective(skipDirective)
 
-	for _, def := range p.Definitions {
+	for _, name := range definitionOrder(p) {
+		def := p.Definitions[name]
+		if isBuiltinScalar(name) {
+			continue
+		}
 		if def.Object != nil {
-			s.AddType(buildObject(def.Object))
+			b.AddType(buildObject(def.Object))
 		} else if def.Interface != nil {
-			s.AddType(buildInterface(def.Interface))
+			b.AddType(buildInterface(def.Interface))
 		} else if def.Enum != nil {
-			s.AddType(buildEnum(def.Enum))
+			b.AddType(buildEnum(def.Enum))
 		} else if def.Input != nil {
-			s.AddType(buildInput(def.Input))
+			b.AddType(buildInput(def.Input))
 		} else if def.Union != nil {
-			s.AddType(buildUnion(def.Union))
+			b.AddType(buildUnion(def.Union))
 		} else if def.Scalar != nil {
-			s.AddType(buildScalar(def.Scalar))
+			b.AddType(buildScalar(def.Scalar))
 		}
 	}
-	for _, dir := range p.Directives {
-		s.AddDirective(buildDirective(dir))
+	for _, name := range directiveOrder(p) {
+		b.AddDirective(buildDirective(p.Directives[name]))
+	}
+	// Interface checks may copy inherited fields, so they run before Build freezes
+	// the schema.
+	if err := validateImplementations(b.schema, o.inheritInterfaceFields); err != nil {
+		return nil, fmt.Errorf("invalid interface implementations:\n%w", err)
+	}
+	s, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema:\n%w", err)
 	}
 	return s, nil
 }
 
+func isBuiltinScalar(name string) bool {
+	switch name {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+// definitionOrder lists definition names in source order: services sorted by ID,
+// then each service's definitions as declared.
+func definitionOrder(p *ir.Project) []string {
+	return sourceOrder(p, p.Definitions, func(svc *ir.Service) []string { return svc.Definitions })
+}
+
+// directiveOrder lists directive definition names in source order.
+func directiveOrder(p *ir.Project) []string {
+	return sourceOrder(p, p.Directives, func(svc *ir.Service) []string { return svc.Directives })
+}
+
+func sourceOrder[T any](p *ir.Project, defs map[string]T, names func(*ir.Service) []string) []string {
+	serviceIDs := make([]string, 0, len(p.Services))
+	for id := range p.Services {
+		serviceIDs = append(serviceIDs, string(id))
+	}
+	sort.Strings(serviceIDs)
+
+	var declared []string
+	for _, id := range serviceIDs {
+		declared = append(declared, names(p.Services[ir.ServiceID(id)])...)
+	}
+	return orderedNames(declared, defs)
+}
+
 func buildObject(def *ir.ObjectDefinition) *Type {
-	t := NewType(def.Name, TypeKindObject, def.Description)
+	t := NewType(def.Name, TypeKindObject, def.Description).SetMetadata(Metadata(def.Metadata))
 
 	var interfaceNames []string
 	for name := range def.Interfaces {
@@ -73,7 +143,7 @@ func buildObject(def *ir.ObjectDefinition) *Type {
 }
 
 func buildInterface(def *ir.InterfaceDefinition) *Type {
-	t := NewType(def.Name, TypeKindInterface, def.Description)
+	t := NewType(def.Name, TypeKindInterface, def.Description).SetMetadata(Metadata(def.Metadata))
 
 	var interfaceNames []string
 	for name := range def.Interfaces {
@@ -99,8 +169,16 @@ func buildInterface(def *ir.InterfaceDefinition) *Type {
 }
 
 func buildField(def *ir.FieldDefinition) *Field {
-	f := NewField(def.Name, def.Description, buildTypeRef(def.Type)).
-		SetAsync(def.ResolveBySource == nil)
+	typ := buildTypeRef(def.Type)
+	if def.IsOptional && typ.Kind == TypeRefKindNonNull {
+		// @optional fields may resolve to null, so clients see them nullable;
+		// only backends are held to the declared Non-Null type
+		typ = typ.OfType
+	}
+	f := NewField(def.Name, def.Description, typ).
+		SetAsync(def.ResolveBySource == nil && def.ResolveByCompute == nil && def.ResolveByConst == nil).
+		SetOptional(def.IsOptional).
+		SetMetadata(Metadata(def.Metadata))
 	if def.Deprecation != nil {
 		f.Deprecate(def.Deprecation.Reason)
 	}
@@ -116,7 +194,7 @@ func buildField(def *ir.FieldDefinition) *Field {
 }
 
 func buildEnum(def *ir.EnumDefinition) *Type {
-	t := NewType(def.Name, TypeKindEnum, def.Description)
+	t := NewType(def.Name, TypeKindEnum, def.Description).SetMetadata(Metadata(def.Metadata))
 
 	var valueNames []string
 	for name := range def.Values {
@@ -150,7 +228,7 @@ func buildTypeRef(t *ir.TypeExpr) *TypeRef {
 }
 
 func buildInputValue(v *ir.InputValueDefinition) *InputValue {
-	in := NewInputValue(v.Name, v.Description, buildTypeRef(v.Type)).SetDefault(v.DefaultValue)
+	in := NewInputValue(v.Name, v.Description, buildTypeRef(v.Type)).SetDefault(v.DefaultValue).SetMetadata(Metadata(v.Metadata))
 	if v.Deprecation != nil {
 		in.Deprecate(v.Deprecation.Reason)
 	}
@@ -158,7 +236,7 @@ func buildInputValue(v *ir.InputValueDefinition) *InputValue {
 }
 
 func buildArgumentAsInputValue(a *ir.ArgumentDefinition) *InputValue {
-	in := NewInputValue(a.Name, a.Description, buildTypeRef(a.Type)).SetDefault(a.DefaultValue)
+	in := NewInputValue(a.Name, a.Description, buildTypeRef(a.Type)).SetDefault(a.DefaultValue).SetMetadata(Metadata(a.Metadata))
 	if a.Deprecation != nil {
 		in.Deprecate(a.Deprecation.Reason)
 	}
@@ -166,7 +244,7 @@ func buildArgumentAsInputValue(a *ir.ArgumentDefinition) *InputValue {
 }
 
 func buildInput(def *ir.InputDefinition) *Type {
-	t := NewType(def.Name, TypeKindInputObject, def.Description).SetOneOf(def.OneOf)
+	t := NewType(def.Name, TypeKindInputObject, def.Description).SetOneOf(def.OneOf).SetMetadata(Metadata(def.Metadata))
 	values := make([]*ir.InputValueDefinition, 0, len(def.InputValues))
 	for _, v := range def.InputValues {
 		values = append(values, v)
@@ -179,7 +257,7 @@ func buildInput(def *ir.InputDefinition) *Type {
 }
 
 func buildUnion(def *ir.UnionDefinition) *Type {
-	t := NewType(def.Name, TypeKindUnion, def.Description)
+	t := NewType(def.Name, TypeKindUnion, def.Description).SetMetadata(Metadata(def.Metadata))
 
 	// Sort union type names for deterministic output
 	var typeNames []string
@@ -195,8 +273,7 @@ func buildUnion(def *ir.UnionDefinition) *Type {
 }
 
 func buildScalar(def *ir.ScalarDefinition) *Type {
-	t := NewType(def.Name, TypeKindScalar, def.Description)
-	return t
+	return NewType(def.Name, TypeKindScalar, def.Description).SetSpecifiedByURL(def.SpecifiedByURL).SetMetadata(Metadata(def.Metadata))
 }
 
 func buildDirective(dir *ir.DirectiveDefinition) *Directive {
@@ -213,10 +290,16 @@ func buildDirective(dir *ir.DirectiveDefinition) *Directive {
 	return d
 }
 
-// BuildFromSDL parses SDL string and returns the corresponding Schema.
+// schemaDefinition matches a schema definition in SDL, with or without
+// directives and however it is spaced.
+var schemaDefinition = regexp.MustCompile(`\bschema\s*[{@]`)
+
+// BuildFromSDL parses SDL string and returns the corresponding Schema. SDL
+// without a schema definition gets `schema { query: Query }`; root types with
+// other names need one.
 func BuildFromSDL(sdl string) (*Schema, error) {
 	// Add schema definition if missing
-	if !strings.Contains(sdl, "schema {") {
+	if !schemaDefinition.MatchString(sdl) {
 		sdl = "schema { query: Query }\n" + sdl
 	}
 
@@ -0,0 +1,136 @@
+package schema
+
+// Clone returns a deep copy of the schema. Types, fields, arguments, enum values,
+// directives, type references, default values and metadata are all copied, so the clone can
+// be extended or modified without affecting s. The clone is never frozen.
+func (s *Schema) Clone() *Schema {
+	if s == nil {
+		return nil
+	}
+	c := &Schema{
+		QueryType:        s.QueryType,
+		MutationType:     s.MutationType,
+		SubscriptionType: s.SubscriptionType,
+		Types:            make(map[string]*Type, len(s.Types)),
+		Directives:       make(map[string]*Directive, len(s.Directives)),
+		Description:      s.Description,
+		TypeOrder:        append([]string(nil), s.TypeOrder...),
+		DirectiveOrder:   append([]string(nil), s.DirectiveOrder...),
+	}
+	for name, t := range s.Types {
+		c.Types[name] = t.Clone()
+	}
+	for name, d := range s.Directives {
+		c.Directives[name] = d.Clone()
+	}
+	return c
+}
+
+// Clone returns a deep copy of the type.
+func (t *Type) Clone() *Type {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Interfaces = append([]string(nil), t.Interfaces...)
+	c.PossibleTypes = append([]string(nil), t.PossibleTypes...)
+	if t.Fields != nil {
+		c.Fields = make(map[string]*Field, len(t.Fields))
+		for name, f := range t.Fields {
+			c.Fields[name] = f.Clone()
+		}
+	}
+	if t.InputFields != nil {
+		c.InputFields = make(map[string]*InputValue, len(t.InputFields))
+		for name, v := range t.InputFields {
+			c.InputFields[name] = v.Clone()
+		}
+	}
+	if t.EnumValues != nil {
+		c.EnumValues = make([]*EnumValue, len(t.EnumValues))
+		for i, v := range t.EnumValues {
+			ev := *v
+			c.EnumValues[i] = &ev
+		}
+	}
+	if t.SpecifiedByURL != nil {
+		url := *t.SpecifiedByURL
+		c.SpecifiedByURL = &url
+	}
+	c.Metadata = t.Metadata.Clone()
+	return &c
+}
+
+// Clone returns a deep copy of the field, including its arguments.
+func (f *Field) Clone() *Field {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	c.Type = f.Type.Clone()
+	if f.Arguments != nil {
+		c.Arguments = make(map[string]*InputValue, len(f.Arguments))
+		for name, arg := range f.Arguments {
+			c.Arguments[name] = arg.Clone()
+		}
+	}
+	c.Metadata = f.Metadata.Clone()
+	return &c
+}
+
+// Clone returns a deep copy of the input value, including its default value.
+func (v *InputValue) Clone() *InputValue {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	c.Type = v.Type.Clone()
+	c.DefaultValue = cloneValue(v.DefaultValue)
+	c.Metadata = v.Metadata.Clone()
+	return &c
+}
+
+// Clone returns a deep copy of the directive definition.
+func (d *Directive) Clone() *Directive {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Locations = append([]string(nil), d.Locations...)
+	if d.Arguments != nil {
+		c.Arguments = make([]*InputValue, len(d.Arguments))
+		for i, arg := range d.Arguments {
+			c.Arguments[i] = arg.Clone()
+		}
+	}
+	return &c
+}
+
+// Clone returns a deep copy of the type reference.
+func (t *TypeRef) Clone() *TypeRef {
+	if t == nil {
+		return nil
+	}
+	return &TypeRef{Kind: t.Kind, OfType: t.OfType.Clone(), Named: t.Named}
+}
+
+// cloneValue copies the JSON-like values used for defaults: maps and lists are
+// copied recursively, everything else is immutable and shared.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for k, item := range v {
+			c[k] = cloneValue(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = cloneValue(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
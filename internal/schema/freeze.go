@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"weak"
+)
+
+// Freeze marks the schema and every type, field, argument, enum value and directive
+// reachable from it as read-only, and returns s. A frozen schema is safe to share
+// between goroutines and requests; use Clone to derive a modifiable copy.
+//
+// In development builds the mutating helpers (AddType, AddField, SetAsync, ...)
+// panic when called on a frozen value. Builds tagged protograph_release skip the
+// bookkeeping entirely. Direct writes to exported fields and maps are not detected.
+func (s *Schema) Freeze() *Schema {
+	if s == nil || !freezeChecks {
+		return s
+	}
+	markFrozen(s)
+	for _, t := range s.Types {
+		markFrozen(t)
+		for _, f := range t.Fields {
+			markFrozen(f)
+			for _, arg := range f.Arguments {
+				markFrozen(arg)
+			}
+		}
+		for _, v := range t.InputFields {
+			markFrozen(v)
+		}
+		for _, v := range t.EnumValues {
+			markFrozen(v)
+		}
+	}
+	for _, d := range s.Directives {
+		markFrozen(d)
+		for _, arg := range d.Arguments {
+			markFrozen(arg)
+		}
+	}
+	return s
+}
+
+// Frozen reports whether Freeze was called on the schema. It is always false in
+// builds tagged protograph_release.
+func (s *Schema) Frozen() bool { return isFrozen(s) }
+
+var (
+	// frozen holds weak pointers to frozen values; entries are dropped once the
+	// value is garbage collected so replaced schemas do not accumulate.
+	frozen    sync.Map
+	anyFrozen atomic.Bool
+)
+
+func markFrozen[T any](p *T) {
+	key := weak.Make(p)
+	if _, loaded := frozen.LoadOrStore(key, struct{}{}); !loaded {
+		anyFrozen.Store(true)
+		runtime.AddCleanup(p, func(k weak.Pointer[T]) { frozen.Delete(k) }, key)
+	}
+}
+
+func isFrozen[T any](p *T) bool {
+	if !freezeChecks || p == nil || !anyFrozen.Load() {
+		return false
+	}
+	_, ok := frozen.Load(weak.Make(p))
+	return ok
+}
+
+// mustBeMutable panics when p belongs to a frozen schema.
+func mustBeMutable[T any](p *T, what string) {
+	if isFrozen(p) {
+		panic("schema: mutation of frozen " + what)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !protograph_release
+
+package schema
+
+// freezeChecks enables the frozen-schema mutation panics in development builds.
+const freezeChecks = true
@@ -0,0 +1,6 @@
+//go:build protograph_release
+
+package schema
+
+// freezeChecks is disabled in release builds; Freeze is a no-op.
+const freezeChecks = false
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// validateImplementations checks that every object and interface implementing an
+// interface declares all of its fields with compatible signatures: arguments are
+// invariant, additional arguments are nullable and return types are covariant.
+// When inherit is set, omitted interface fields are copied onto the implementer
+// instead of being reported. All problems are reported together.
+func validateImplementations(s *Schema, inherit bool) error {
+	var errs []error
+	for _, name := range sortedTypeNames(s) {
+		t := s.Types[name]
+		if t.Kind != TypeKindObject && t.Kind != TypeKindInterface {
+			continue
+		}
+		for _, ifaceName := range t.Interfaces {
+			iface, ok := s.Types[ifaceName]
+			if !ok || iface.Kind != TypeKindInterface {
+				errs = append(errs, fmt.Errorf("type %s implements %s, which is not an interface", t.Name, ifaceName))
+				continue
+			}
+			errs = append(errs, implementationErrors(s, t, iface, inherit)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func implementationErrors(s *Schema, t, iface *Type, inherit bool) []error {
+	var errs []error
+	for _, transitive := range iface.Interfaces {
+		if !containsString(t.Interfaces, transitive) {
+			errs = append(errs, fmt.Errorf("type %s must also implement %s (required by %s)", t.Name, transitive, iface.Name))
+		}
+	}
+	for _, ifaceField := range iface.GetOrderedFields() {
+		field, ok := t.Fields[ifaceField.Name]
+		if !ok {
+			if inherit {
+				t.AddField(inheritField(ifaceField))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("type %s is missing field %q required by %s", t.Name, ifaceField.Name, iface.Name))
+			continue
+		}
+		errs = append(errs, fieldImplementationErrors(s, t, iface, field, ifaceField)...)
+	}
+	return errs
+}
+
+func fieldImplementationErrors(s *Schema, t, iface *Type, field, ifaceField *Field) []error {
+	var errs []error
+	for _, ifaceArg := range ifaceField.GetOrderedArguments() {
+		arg, ok := field.Arguments[ifaceArg.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %s.%s is missing argument %q required by %s", t.Name, field.Name, ifaceArg.Name, iface.Name))
+			continue
+		}
+		if !arg.Type.Equal(ifaceArg.Type) {
+			errs = append(errs, fmt.Errorf("argument %q of field %s.%s has type %s but %s expects %s",
+				arg.Name, t.Name, field.Name, arg.Type.String(), iface.Name, ifaceArg.Type.String()))
+		}
+	}
+	for _, arg := range field.GetOrderedArguments() {
+		if _, ok := ifaceField.Arguments[arg.Name]; !ok && arg.Type.IsNonNull() {
+			errs = append(errs, fmt.Errorf("additional argument %q of field %s.%s must be nullable (not declared by %s)",
+				arg.Name, t.Name, field.Name, iface.Name))
+		}
+	}
+	if !isSubtypeRef(s, field.Type, ifaceField.Type) {
+		errs = append(errs, fmt.Errorf("field %s.%s has type %s but %s expects %s (or a subtype)",
+			t.Name, field.Name, field.Type.String(), iface.Name, ifaceField.Type.String()))
+	}
+	return errs
+}
+
+// inheritField copies an interface field for an implementer that omits it. Like any
+// undecorated object field, the copy is read from the source message of the same name.
+func inheritField(f *Field) *Field {
+	out := NewField(f.Name, f.Description, f.Type).SetAsync(false)
+	if f.IsDeprecated {
+		out.Deprecate(f.DeprecationReason)
+	}
+	for _, arg := range f.GetOrderedArguments() {
+		cp := *arg
+		out.AddArgument(&cp)
+	}
+	return out
+}
+
+// isSubtypeRef reports whether sub is a valid implementation type for super.
+func isSubtypeRef(s *Schema, sub, super *TypeRef) bool {
+	if sub.Kind == TypeRefKindNonNull {
+		if super.Kind == TypeRefKindNonNull {
+			super = super.OfType
+		}
+		return isSubtypeRef(s, sub.OfType, super)
+	}
+	if super.Kind == TypeRefKindNonNull {
+		return false
+	}
+	if sub.Kind == TypeRefKindList || super.Kind == TypeRefKindList {
+		return sub.Kind == super.Kind && isSubtypeRef(s, sub.OfType, super.OfType)
+	}
+	if sub.Named == super.Named {
+		return true
+	}
+	subType, superType := s.Types[sub.Named], s.Types[super.Named]
+	if subType == nil || superType == nil {
+		return false
+	}
+	switch superType.Kind {
+	case TypeKindUnion:
+		return subType.Kind == TypeKindObject && containsString(superType.PossibleTypes, sub.Named)
+	case TypeKindInterface:
+		return (subType.Kind == TypeKindObject || subType.Kind == TypeKindInterface) && containsString(subType.Interfaces, super.Named)
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package schema
+
+// Metadata holds the annotations of a type, field or input value: the uses of
+// directives declared in SDL, keyed by directive name, each with the argument
+// values it sets. Protograph carries them without interpreting them, so runtimes
+// and middleware can read cost weights, cache hints or auth requirements off the
+// schema without a dedicated field for each.
+//
+// Values are JSON-like: numbers are float64, lists []any and input objects
+// map[string]any. Arguments left out of a use are absent rather than defaulted;
+// their defaults are on the directive definition.
+type Metadata map[string][]map[string]any
+
+// Has reports whether the directive is used.
+func (m Metadata) Has(directive string) bool {
+	return len(m[directive]) > 0
+}
+
+// Get returns the arguments of the first use of directive.
+func (m Metadata) Get(directive string) (map[string]any, bool) {
+	uses := m[directive]
+	if len(uses) == 0 {
+		return nil, false
+	}
+	return uses[0], true
+}
+
+// Value returns argument arg of the first use of directive.
+func (m Metadata) Value(directive, arg string) (any, bool) {
+	args, ok := m.Get(directive)
+	if !ok {
+		return nil, false
+	}
+	v, ok := args[arg]
+	return v, ok
+}
+
+// All returns the arguments of every use of directive, in SDL order. Only
+// repeatable directives have more than one.
+func (m Metadata) All(directive string) []map[string]any {
+	return m[directive]
+}
+
+// Clone returns a deep copy of the metadata.
+func (m Metadata) Clone() Metadata {
+	if m == nil {
+		return nil
+	}
+	c := make(Metadata, len(m))
+	for name, uses := range m {
+		cu := make([]map[string]any, len(uses))
+		for i, args := range uses {
+			cu[i] = cloneValue(args).(map[string]any)
+		}
+		c[name] = cu
+	}
+	return c
+}
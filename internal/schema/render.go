@@ -7,79 +7,176 @@ import (
 	"strings"
 )
 
+// RenderOption configures Render.
+type RenderOption func(*renderOptions)
+
+type renderOptions struct {
+	schema             *Schema // for rendering enum literals
+	sourceOrder        bool
+	omitDescriptions   bool
+	inlineDescriptions bool
+	asyncAnnotations   bool
+}
+
+// WithSourceOrder renders types and directives in the order they were registered
+// on the schema (source order for schemas built from IR) instead of by name.
+func WithSourceOrder() RenderOption {
+	return func(o *renderOptions) { o.sourceOrder = true }
+}
+
+// WithoutDescriptions omits all descriptions from the output.
+func WithoutDescriptions() RenderOption {
+	return func(o *renderOptions) { o.omitDescriptions = true }
+}
+
+// WithInlineDescriptions renders single-line descriptions as quoted strings
+// instead of block strings. Multi-line descriptions are always rendered as blocks.
+func WithInlineDescriptions() RenderOption {
+	return func(o *renderOptions) { o.inlineDescriptions = true }
+}
+
+// WithAsyncAnnotations marks fields resolved through an RPC with @async and
+// declares the directive, so that registries can diff resolution changes.
+func WithAsyncAnnotations() RenderOption {
+	return func(o *renderOptions) { o.asyncAnnotations = true }
+}
+
+var asyncDirective = &Directive{
+	Name:        "async",
+	Description: "The field is resolved through an RPC rather than read from its parent's source message.",
+	Locations:   []string{"FIELD_DEFINITION"},
+}
+
 // Render produces SDL from the Schema.
-// Deterministic ordering: type/directive names sorted lexicographically.
-func Render(s *Schema) string {
+// Deterministic ordering: type/directive names sorted lexicographically unless
+// WithSourceOrder is given. Fields, arguments and enum values keep their
+// definition order.
+func Render(s *Schema, opts ...RenderOption) string {
 	if s == nil {
 		return ""
 	}
+	o := renderOptions{schema: s}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	var b strings.Builder
 
-	// Collect and sort type names, excluding built-in scalars
-	typeNames := make([]string, 0, len(s.Types))
+	renderSchemaDefinition(&b, &o, s)
 
-	for name, typ := range s.Types {
-		switch typ {
+	// Collect type names, excluding built-in scalars
+	var typeNames []string
+	for _, name := range o.names(s.GetOrderedTypeNames()) {
+		switch s.Types[name] {
 		case stringType, intType, floatType, booleanType, idType:
 			continue
 		default:
 			typeNames = append(typeNames, name)
 		}
 	}
-	sort.Strings(typeNames)
 
 	for _, name := range typeNames {
 		typ := s.Types[name]
 		switch typ.Kind {
 		case TypeKindScalar:
-			renderScalar(&b, typ)
+			renderScalar(&b, &o, typ)
 		case TypeKindEnum:
-			renderEnum(&b, typ)
+			renderEnum(&b, &o, typ)
 		case TypeKindInputObject:
-			renderInputObject(&b, typ)
+			renderInputObject(&b, &o, typ)
 		case TypeKindObject:
-			renderObject(&b, typ)
+			renderObject(&b, &o, typ)
 		case TypeKindInterface:
-			renderInterface(&b, typ)
+			renderInterface(&b, &o, typ)
 		case TypeKindUnion:
-			renderUnion(&b, typ)
+			renderUnion(&b, &o, typ)
 		}
 	}
 
 	// Render directives
-	directiveNames := make([]string, 0, len(s.Directives))
-	for name, directive := range s.Directives {
-		switch directive {
+	var directiveNames []string
+	for _, name := range o.names(s.GetOrderedDirectiveNames()) {
+		switch s.Directives[name] {
 		case includeDirective, skipDirective:
 			continue
 		default:
 			directiveNames = append(directiveNames, name)
 		}
 	}
-	sort.Strings(directiveNames)
 	for _, name := range directiveNames {
-		renderDirective(&b, s.Directives[name])
+		renderDirective(&b, &o, s.Directives[name])
+	}
+	if _, ok := s.Directives[asyncDirective.Name]; o.asyncAnnotations && !ok {
+		renderDirective(&b, &o, asyncDirective)
 	}
 
 	out := strings.TrimRight(b.String(), "\n") + "\n"
 	return out
 }
 
+// names returns the registration-ordered names in the configured render order.
+func (o *renderOptions) names(ordered []string) []string {
+	if !o.sourceOrder {
+		sort.Strings(ordered)
+	}
+	return ordered
+}
+
 // ----- render helpers -----
 
-func renderDescription(b *strings.Builder, desc string) {
-	if desc == "" {
+// renderSchemaDefinition writes the schema block when the SDL would otherwise lose
+// information: a schema description or root types without their conventional names.
+func renderSchemaDefinition(b *strings.Builder, o *renderOptions, s *Schema) {
+	roots := []struct{ op, name, conventional string }{
+		{"query", s.QueryType, "Query"},
+		{"mutation", s.MutationType, "Mutation"},
+		{"subscription", s.SubscriptionType, "Subscription"},
+	}
+	needed := s.Description != "" && !o.omitDescriptions
+	for _, root := range roots {
+		if root.name != "" && root.name != root.conventional {
+			needed = true
+		}
+	}
+	if !needed {
+		return
+	}
+	renderDescription(b, o, s.Description, "")
+	b.WriteString("schema {\n")
+	for _, root := range roots {
+		if root.name != "" {
+			b.WriteString("  " + root.op + ": " + root.name + "\n")
+		}
+	}
+	b.WriteString("}\n\n")
+}
+
+func renderDescription(b *strings.Builder, o *renderOptions, desc, indent string) {
+	if desc == "" || o.omitDescriptions {
+		return
+	}
+	if o.inlineDescriptions && !strings.Contains(desc, "\n") {
+		b.WriteString(indent)
+		b.WriteString(strconv.Quote(desc))
+		b.WriteString("\n")
 		return
 	}
+	b.WriteString(indent)
+	b.WriteString("\"\"\"\n")
+	// Only a triple quote needs escaping inside a block string
+	escaped := strings.ReplaceAll(desc, `"""`, `\"""`)
+	for _, line := range strings.Split(escaped, "\n") {
+		if line != "" {
+			b.WriteString(indent)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString(indent)
 	b.WriteString("\"\"\"\n")
-	// Escape quotes in description
-	escaped := strings.ReplaceAll(desc, "\"", "\\\"")
-	b.WriteString(escaped)
-	b.WriteString("\n\"\"\"\n")
 }
 
-func renderScalar(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderScalar(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("scalar ")
 	b.WriteString(typ.Name)
 	if typ.SpecifiedByURL != nil {
@@ -90,13 +187,13 @@ func renderScalar(b *strings.Builder, typ *Type) {
 	b.WriteString("\n\n")
 }
 
-func renderEnum(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderEnum(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("enum ")
 	b.WriteString(typ.Name)
 	b.WriteString(" {\n")
 	for _, val := range typ.EnumValues {
-		renderDescription(b, val.Description)
+		renderDescription(b, o, val.Description, "  ")
 		b.WriteString("  ")
 		b.WriteString(val.Name)
 		if val.IsDeprecated {
@@ -112,8 +209,8 @@ func renderEnum(b *strings.Builder, typ *Type) {
 	b.WriteString("}\n\n")
 }
 
-func renderInputObject(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderInputObject(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("input ")
 	b.WriteString(typ.Name)
 	if typ.OneOf {
@@ -121,14 +218,14 @@ func renderInputObject(b *strings.Builder, typ *Type) {
 	}
 	b.WriteString(" {\n")
 	for _, field := range typ.GetOrderedInputFields() {
-		renderDescription(b, field.Description)
+		renderDescription(b, o, field.Description, "  ")
 		b.WriteString("  ")
 		b.WriteString(field.Name)
 		b.WriteString(": ")
-		b.WriteString(renderTypeRef(field.Type))
+		b.WriteString(field.Type.String())
 		if field.DefaultValue != nil {
 			b.WriteString(" = ")
-			b.WriteString(renderValue(field.DefaultValue))
+			b.WriteString(RenderValue(o.schema, field.Type, field.DefaultValue))
 		}
 		if field.IsDeprecated {
 			b.WriteString(" @deprecated")
@@ -143,8 +240,8 @@ func renderInputObject(b *strings.Builder, typ *Type) {
 	b.WriteString("}\n\n")
 }
 
-func renderObject(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderObject(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("type ")
 	b.WriteString(typ.Name)
 	if len(typ.Interfaces) > 0 {
@@ -158,13 +255,13 @@ func renderObject(b *strings.Builder, typ *Type) {
 	}
 	b.WriteString(" {\n")
 	for _, field := range typ.GetOrderedFields() {
-		renderField(b, field)
+		renderField(b, o, field, o.asyncAnnotations && field.Async)
 	}
 	b.WriteString("}\n\n")
 }
 
-func renderInterface(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderInterface(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("interface ")
 	b.WriteString(typ.Name)
 	if len(typ.Interfaces) > 0 {
@@ -178,13 +275,13 @@ func renderInterface(b *strings.Builder, typ *Type) {
 	}
 	b.WriteString(" {\n")
 	for _, field := range typ.GetOrderedFields() {
-		renderField(b, field)
+		renderField(b, o, field, false)
 	}
 	b.WriteString("}\n\n")
 }
 
-func renderUnion(b *strings.Builder, typ *Type) {
-	renderDescription(b, typ.Description)
+func renderUnion(b *strings.Builder, o *renderOptions, typ *Type) {
+	renderDescription(b, o, typ.Description, "")
 	b.WriteString("union ")
 	b.WriteString(typ.Name)
 	b.WriteString(" = ")
@@ -197,8 +294,8 @@ func renderUnion(b *strings.Builder, typ *Type) {
 	b.WriteString("\n\n")
 }
 
-func renderField(b *strings.Builder, field *Field) {
-	renderDescription(b, field.Description)
+func renderField(b *strings.Builder, o *renderOptions, field *Field, async bool) {
+	renderDescription(b, o, field.Description, "  ")
 	b.WriteString("  ")
 	b.WriteString(field.Name)
 	if len(field.Arguments) > 0 {
@@ -209,18 +306,21 @@ func renderField(b *strings.Builder, field *Field) {
 			}
 			b.WriteString(arg.Name)
 			b.WriteString(": ")
-			b.WriteString(renderTypeRef(arg.Type))
+			b.WriteString(arg.Type.String())
 			if arg.DefaultValue != nil {
 				b.WriteString(" = ")
-				b.WriteString(renderValue(arg.DefaultValue))
+				b.WriteString(RenderValue(o.schema, arg.Type, arg.DefaultValue))
 			}
 		}
 		b.WriteString(")")
 	}
 	b.WriteString(": ")
-	b.WriteString(renderTypeRef(field.Type))
+	b.WriteString(field.Type.String())
 
 	// Skip @resolve directive as it's protograph-internal
+	if async {
+		b.WriteString(" @async")
+	}
 	if field.IsDeprecated {
 		b.WriteString(" @deprecated")
 		if field.DeprecationReason != "" {
@@ -233,8 +333,8 @@ func renderField(b *strings.Builder, field *Field) {
 	b.WriteString("\n")
 }
 
-func renderDirective(b *strings.Builder, directive *Directive) {
-	renderDescription(b, directive.Description)
+func renderDirective(b *strings.Builder, o *renderOptions, directive *Directive) {
+	renderDescription(b, o, directive.Description, "")
 	b.WriteString("directive @")
 	b.WriteString(directive.Name)
 	if len(directive.Arguments) > 0 {
@@ -245,10 +345,10 @@ func renderDirective(b *strings.Builder, directive *Directive) {
 			}
 			b.WriteString(arg.Name)
 			b.WriteString(": ")
-			b.WriteString(renderTypeRef(arg.Type))
+			b.WriteString(arg.Type.String())
 			if arg.DefaultValue != nil {
 				b.WriteString(" = ")
-				b.WriteString(renderValue(arg.DefaultValue))
+				b.WriteString(RenderValue(o.schema, arg.Type, arg.DefaultValue))
 			}
 		}
 		b.WriteString(")")
@@ -266,21 +366,48 @@ func renderDirective(b *strings.Builder, directive *Directive) {
 	b.WriteString("\n\n")
 }
 
-func renderTypeRef(typeRef *TypeRef) string {
-	if typeRef == nil {
-		return ""
+// RenderValue renders value as a GraphQL literal of type typ. Enum values are held
+// as strings and are rendered unquoted; other values render as in renderValue.
+func RenderValue(s *Schema, typ *TypeRef, value any) string {
+	for typ != nil && typ.Kind == TypeRefKindNonNull {
+		typ = typ.OfType
 	}
-
-	switch typeRef.Kind {
-	case TypeRefKindNamed:
-		return typeRef.Named
-	case TypeRefKindList:
-		return "[" + renderTypeRef(typeRef.OfType) + "]"
-	case TypeRefKindNonNull:
-		return renderTypeRef(typeRef.OfType) + "!"
-	default:
-		return ""
+	if s == nil || typ == nil || value == nil {
+		return renderValue(value)
 	}
+	switch v := value.(type) {
+	case []any:
+		elem := typ
+		if typ.Kind == TypeRefKindList {
+			elem = typ.OfType
+		}
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = RenderValue(s, elem, item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]any:
+		t := s.Types[typ.Named]
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			var fieldType *TypeRef
+			if t != nil && t.InputFields[k] != nil {
+				fieldType = t.InputFields[k].Type
+			}
+			parts[i] = k + ": " + RenderValue(s, fieldType, v[k])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case string:
+		if t := s.Types[typ.Named]; typ.Kind == TypeRefKindNamed && t != nil && t.Kind == TypeKindEnum {
+			return v
+		}
+	}
+	return renderValue(value)
 }
 
 // renderValue renders a GraphQL value (for default values, directive arguments, etc.)
@@ -311,9 +438,14 @@ func renderValue(value any) string {
 		}
 		return "[" + strings.Join(parts, ", ") + "]"
 	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
 		var parts []string
-		for k, val := range v {
-			parts = append(parts, k+": "+renderValue(val))
+		for _, k := range keys {
+			parts = append(parts, k+": "+renderValue(v[k]))
 		}
 		return "{" + strings.Join(parts, ", ") + "}"
 	default:
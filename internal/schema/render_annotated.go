@@ -0,0 +1,638 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hanpama/protograph/internal/ir"
+)
+
+// RenderAnnotated produces SDL from an ir project, keeping the protograph directives
+// (@loader, @id, @internal, @load, @resolve, @node, @compute, @const, @default,
+// @source, @mapScalar, @mapValue, @envelope, @discriminator, @onUnknown) and custom
+// directive definitions with their uses, so that the output loads back through
+// ir.Load into an equivalent project. All definitions are emitted into a single
+// document; @connection fields are emitted as declared, without the connection and
+// edge types generated for them.
+func RenderAnnotated(p *ir.Project, opts ...RenderOption) string {
+	if p == nil {
+		return ""
+	}
+	var o renderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r := &annotatedRenderer{p: p, o: &o}
+	generated := connectionTypes(p)
+
+	r.renderSchemaDefinition()
+	for _, name := range o.names(definitionOrder(p)) {
+		if generated[name] {
+			continue
+		}
+		def := p.Definitions[name]
+		switch {
+		case def.Object != nil:
+			r.renderObject(def.Object)
+		case def.Interface != nil:
+			r.renderInterface(def.Interface)
+		case def.Union != nil:
+			r.renderUnion(def.Union)
+		case def.Input != nil:
+			r.renderInput(def.Input)
+		case def.Enum != nil:
+			r.renderEnum(def.Enum)
+		case def.Scalar != nil:
+			r.renderScalar(def.Scalar)
+		}
+	}
+	for _, name := range o.names(directiveOrder(p)) {
+		r.renderDirectiveDefinition(p.Directives[name])
+	}
+
+	return strings.TrimRight(r.b.String(), "\n") + "\n"
+}
+
+// connectionTypes returns the names of the connection and edge types generated
+// for @connection fields, which loading the fields generates again. PageInfo
+// may be declared by hand and is always rendered.
+func connectionTypes(p *ir.Project) map[string]bool {
+	names := map[string]bool{}
+	for _, def := range p.Definitions {
+		if def.Object == nil {
+			continue
+		}
+		for _, field := range def.Object.Fields {
+			if field.ConnectionOf == nil {
+				continue
+			}
+			names[namedType(field.Type)] = true
+			names[namedType(field.ConnectionOf)+"Edge"] = true
+		}
+	}
+	return names
+}
+
+// namedType returns the named type wrapped by t.
+func namedType(t *ir.TypeExpr) string {
+	for t.Kind != ir.TypeExprKindNamed {
+		t = t.OfType
+	}
+	return t.Named
+}
+
+type annotatedRenderer struct {
+	p *ir.Project
+	o *renderOptions
+	b strings.Builder
+}
+
+func (r *annotatedRenderer) renderSchemaDefinition() {
+	if r.p.Schema == nil {
+		return
+	}
+	renderDescription(&r.b, r.o, r.p.Schema.Description, "")
+	r.b.WriteString("schema {\n")
+	for _, root := range []struct{ op, name string }{
+		{"query", r.p.Schema.QueryType},
+		{"mutation", r.p.Schema.MutationType},
+		{"subscription", r.p.Schema.SubscriptionType},
+	} {
+		if root.name != "" {
+			r.b.WriteString("  " + root.op + ": " + root.name + "\n")
+		}
+	}
+	r.b.WriteString("}\n\n")
+}
+
+func (r *annotatedRenderer) renderObject(obj *ir.ObjectDefinition) {
+	renderDescription(&r.b, r.o, obj.Description, "")
+	r.b.WriteString("type ")
+	r.b.WriteString(obj.Name)
+	r.renderImplements(obj.Interfaces)
+	for _, loader := range r.loadersOf(obj.Name) {
+		r.b.WriteString(" ")
+		r.b.WriteString(r.loaderDirective(obj, loader))
+	}
+	r.renderMetadata(obj.Metadata)
+	r.b.WriteString(" {\n")
+	implicitID := len(obj.IDFields) == 1 && obj.IDFields[0] == "id"
+	for _, field := range obj.OrderedFields() {
+		renderDescription(&r.b, r.o, field.Description, "  ")
+		r.b.WriteString("  ")
+		r.renderFieldSignature(field)
+		if !implicitID && containsString(obj.IDFields, field.Name) {
+			r.b.WriteString(" @id")
+		}
+		if field.IsInternal {
+			r.b.WriteString(" @internal")
+		}
+		if field.IsOptional {
+			r.b.WriteString(" @optional")
+		}
+		for _, dir := range r.resolutionDirectives(obj, field) {
+			r.b.WriteString(" ")
+			r.b.WriteString(dir)
+		}
+		if field.OnError != nil {
+			r.b.WriteString(" ")
+			r.b.WriteString(r.onErrorDirective(field))
+		}
+		if field.Cache != nil {
+			r.b.WriteString(" ")
+			r.b.WriteString(cacheDirective(field.Cache))
+		}
+		if field.Priority != "" {
+			r.b.WriteString(" " + directiveUse("priority", []string{"level: " + string(field.Priority)}))
+		}
+		if field.TimeoutMs > 0 {
+			r.b.WriteString(" " + directiveUse("timeout", []string{"ms: " + strconv.Itoa(field.TimeoutMs)}))
+		}
+		if field.SLAMs > 0 {
+			r.b.WriteString(" " + directiveUse("sla", []string{"ms: " + strconv.Itoa(field.SLAMs)}))
+		}
+		r.renderMetadata(field.Metadata)
+		r.renderDeprecation(field.Deprecation)
+		r.b.WriteString("\n")
+	}
+	r.b.WriteString("}\n\n")
+}
+
+func (r *annotatedRenderer) renderInterface(iface *ir.InterfaceDefinition) {
+	renderDescription(&r.b, r.o, iface.Description, "")
+	r.b.WriteString("interface ")
+	r.b.WriteString(iface.Name)
+	r.renderImplements(iface.Interfaces)
+	r.renderEnvelope(iface.Envelope)
+	r.renderMetadata(iface.Metadata)
+	r.b.WriteString(" {\n")
+	fields := make([]*ir.FieldDefinition, 0, len(iface.Fields))
+	for _, field := range iface.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Index < fields[j].Index })
+	for _, field := range fields {
+		renderDescription(&r.b, r.o, field.Description, "  ")
+		r.b.WriteString("  ")
+		r.renderFieldSignature(field)
+		r.renderMetadata(field.Metadata)
+		r.renderDeprecation(field.Deprecation)
+		r.b.WriteString("\n")
+	}
+	r.b.WriteString("}\n\n")
+}
+
+func (r *annotatedRenderer) renderUnion(union *ir.UnionDefinition) {
+	renderDescription(&r.b, r.o, union.Description, "")
+	members := make([]*ir.UnionTypeDefinition, 0, len(union.Types))
+	for _, member := range union.Types {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Index < members[j].Index })
+	r.b.WriteString("union ")
+	r.b.WriteString(union.Name)
+	r.renderEnvelope(union.Envelope)
+	if d := union.Discriminator; d != nil {
+		r.b.WriteString(" " + directiveUse("discriminator", []string{"field: " + strconv.Quote(d.Field), "values: " + renderStringMap(d.Values)}))
+	}
+	r.renderMetadata(union.Metadata)
+	r.b.WriteString(" = ")
+	for i, member := range members {
+		if i > 0 {
+			r.b.WriteString(" | ")
+		}
+		r.b.WriteString(member.Name)
+	}
+	r.b.WriteString("\n\n")
+}
+
+func (r *annotatedRenderer) renderEnvelope(envelope ir.Envelope) {
+	if envelope != "" {
+		r.b.WriteString(" " + directiveUse("envelope", []string{"kind: " + string(envelope)}))
+	}
+}
+
+func (r *annotatedRenderer) renderInput(input *ir.InputDefinition) {
+	renderDescription(&r.b, r.o, input.Description, "")
+	r.b.WriteString("input ")
+	r.b.WriteString(input.Name)
+	r.renderMetadata(input.Metadata)
+	r.b.WriteString(" {\n")
+	for _, v := range input.OrderedInputValues() {
+		renderDescription(&r.b, r.o, v.Description, "  ")
+		r.b.WriteString("  ")
+		r.b.WriteString(v.Name)
+		r.b.WriteString(": ")
+		r.b.WriteString(v.Type.String())
+		r.renderDefault(v.Type, v.DefaultValue)
+		r.renderMetadata(v.Metadata)
+		r.renderDeprecation(v.Deprecation)
+		r.b.WriteString("\n")
+	}
+	r.b.WriteString("}\n\n")
+}
+
+func (r *annotatedRenderer) renderEnum(enum *ir.EnumDefinition) {
+	renderDescription(&r.b, r.o, enum.Description, "")
+	r.b.WriteString("enum ")
+	r.b.WriteString(enum.Name)
+	if p := enum.OnUnknown; p != nil {
+		args := []string{"action: " + string(p.Action)}
+		if p.Value != "" {
+			args = append(args, "value: "+p.Value)
+		}
+		r.b.WriteString(" " + directiveUse("onUnknown", args))
+	}
+	r.renderMetadata(enum.Metadata)
+	r.b.WriteString(" {\n")
+	for _, v := range enum.OrderedValues() {
+		renderDescription(&r.b, r.o, v.Description, "  ")
+		r.b.WriteString("  ")
+		r.b.WriteString(v.Name)
+		if v.ProtoName != "" {
+			r.b.WriteString(" " + directiveUse("mapValue", []string{"toProtobuf: " + strconv.Quote(v.ProtoName)}))
+		}
+		r.renderDeprecation(v.Deprecation)
+		r.b.WriteString("\n")
+	}
+	r.b.WriteString("}\n\n")
+}
+
+func (r *annotatedRenderer) renderScalar(scalar *ir.ScalarDefinition) {
+	if ir.IsBuiltinScalar(scalar.Name) {
+		if scalar.Name == ir.IDType.Name && scalar.MappedToProtoType != ir.IDType.MappedToProtoType {
+			r.b.WriteString("extend scalar ID ")
+			r.b.WriteString(directiveUse("mapScalar", []string{"toProtobuf: " + strconv.Quote(scalar.MappedToProtoType)}))
+			r.b.WriteString("\n\n")
+		}
+		return
+	}
+	renderDescription(&r.b, r.o, scalar.Description, "")
+	r.b.WriteString("scalar ")
+	r.b.WriteString(scalar.Name)
+	if scalar.MappedToProtoType != "" {
+		r.b.WriteString(" @mapScalar(toProtobuf: ")
+		r.b.WriteString(strconv.Quote(scalar.MappedToProtoType))
+		r.b.WriteString(")")
+	}
+	if scalar.SpecifiedByURL != "" {
+		r.b.WriteString(" @specifiedBy(url: ")
+		r.b.WriteString(strconv.Quote(scalar.SpecifiedByURL))
+		r.b.WriteString(")")
+	}
+	r.renderMetadata(scalar.Metadata)
+	r.b.WriteString("\n\n")
+}
+
+func (r *annotatedRenderer) renderDirectiveDefinition(dir *ir.DirectiveDefinition) {
+	renderDescription(&r.b, r.o, dir.Description, "")
+	r.b.WriteString("directive @")
+	r.b.WriteString(dir.Name)
+	r.renderArguments(dir.Args, "")
+	if dir.Repeatable {
+		r.b.WriteString(" repeatable")
+	}
+	r.b.WriteString(" on ")
+	r.b.WriteString(strings.Join(dir.Locations, " | "))
+	r.b.WriteString("\n\n")
+}
+
+func (r *annotatedRenderer) renderImplements(interfaces map[string]*ir.InterfaceImpl) {
+	if len(interfaces) == 0 {
+		return
+	}
+	impls := make([]*ir.InterfaceImpl, 0, len(interfaces))
+	for _, impl := range interfaces {
+		impls = append(impls, impl)
+	}
+	sort.Slice(impls, func(i, j int) bool { return impls[i].Index < impls[j].Index })
+	r.b.WriteString(" implements ")
+	for i, impl := range impls {
+		if i > 0 {
+			r.b.WriteString(" & ")
+		}
+		r.b.WriteString(impl.Interface)
+	}
+}
+
+func (r *annotatedRenderer) renderFieldSignature(field *ir.FieldDefinition) {
+	r.b.WriteString(field.Name)
+	if field.ConnectionOf == nil {
+		r.renderArguments(field.Args, "  ")
+		r.b.WriteString(": ")
+		r.b.WriteString(field.Type.String())
+		return
+	}
+	declared := make(map[string]*ir.ArgumentDefinition, len(field.Args))
+	for name, arg := range field.Args {
+		if !field.IsConnectionArg(name) {
+			declared[name] = arg
+		}
+	}
+	r.renderArguments(declared, "  ")
+	r.b.WriteString(": ")
+	r.b.WriteString(field.ConnectionOf.String())
+	r.b.WriteString(" @connection")
+}
+
+// renderArguments renders an argument list on one line, or one argument per line
+// when any argument carries a description.
+func (r *annotatedRenderer) renderArguments(args map[string]*ir.ArgumentDefinition, indent string) {
+	if len(args) == 0 {
+		return
+	}
+	ordered := make([]*ir.ArgumentDefinition, 0, len(args))
+	multiline := false
+	for _, arg := range args {
+		ordered = append(ordered, arg)
+		multiline = multiline || (arg.Description != "" && !r.o.omitDescriptions)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+	r.b.WriteString("(")
+	for i, arg := range ordered {
+		if multiline {
+			r.b.WriteString("\n")
+			renderDescription(&r.b, r.o, arg.Description, indent+"  ")
+			r.b.WriteString(indent + "  ")
+		} else if i > 0 {
+			r.b.WriteString(", ")
+		}
+		r.b.WriteString(arg.Name)
+		r.b.WriteString(": ")
+		r.b.WriteString(arg.Type.String())
+		r.renderDefault(arg.Type, arg.DefaultValue)
+		if arg.MetadataKey != "" {
+			r.b.WriteString(" " + directiveUse("metadata", []string{"key: " + strconv.Quote(arg.MetadataKey)}))
+		}
+		r.renderMetadata(arg.Metadata)
+		r.renderDeprecation(arg.Deprecation)
+	}
+	if multiline {
+		r.b.WriteString("\n" + indent)
+	}
+	r.b.WriteString(")")
+}
+
+func (r *annotatedRenderer) renderDefault(typ *ir.TypeExpr, value ir.Value) {
+	if value == nil {
+		return
+	}
+	r.b.WriteString(" = ")
+	r.b.WriteString(r.literal(typ, value))
+}
+
+// renderMetadata renders the uses of declared directives, ordered by directive
+// name; the uses of a repeatable directive keep their order.
+func (r *annotatedRenderer) renderMetadata(md ir.Metadata) {
+	names := make([]string, 0, len(md))
+	for name := range md {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := r.p.Directives[name]
+		for _, args := range md[name] {
+			keys := make([]string, 0, len(args))
+			for k := range args {
+				keys = append(keys, k)
+			}
+			sort.Slice(keys, func(i, j int) bool { return argIndex(def, keys[i]) < argIndex(def, keys[j]) })
+			parts := make([]string, len(keys))
+			for i, k := range keys {
+				var typ *ir.TypeExpr
+				if def != nil && def.Args[k] != nil {
+					typ = def.Args[k].Type
+				}
+				parts[i] = k + ": " + r.literal(typ, args[k])
+			}
+			r.b.WriteString(" " + directiveUse(name, parts))
+		}
+	}
+}
+
+func argIndex(def *ir.DirectiveDefinition, name string) int {
+	if def == nil || def.Args[name] == nil {
+		return 0
+	}
+	return def.Args[name].Index
+}
+
+func (r *annotatedRenderer) renderDeprecation(dep *ir.Deprecation) {
+	if dep == nil {
+		return
+	}
+	r.b.WriteString(" @deprecated(reason: ")
+	r.b.WriteString(strconv.Quote(dep.Reason))
+	r.b.WriteString(")")
+}
+
+// loadersOf returns the loaders declared on the named object, ordered by ID.
+func (r *annotatedRenderer) loadersOf(typeName string) []*ir.LoaderDefinition {
+	var loaders []*ir.LoaderDefinition
+	for _, loader := range r.p.Loaders {
+		if loader.TargetType == typeName {
+			loaders = append(loaders, loader)
+		}
+	}
+	sort.Slice(loaders, func(i, j int) bool { return loaders[i].ID < loaders[j].ID })
+	return loaders
+}
+
+func (r *annotatedRenderer) loaderDirective(obj *ir.ObjectDefinition, loader *ir.LoaderDefinition) string {
+	var args []string
+	ids := append([]string(nil), obj.IDFields...)
+	sort.Strings(ids)
+	if !equalStrings(ids, loader.KeyFields) {
+		keys := make([]string, len(loader.KeyFields))
+		for i, key := range loader.KeyFields {
+			keys[i] = strconv.Quote(key)
+		}
+		args = append(args, "keys: ["+strings.Join(keys, ", ")+"]")
+	}
+	if !loader.Batch {
+		args = append(args, "batch: false")
+	}
+	if loader.Keyed {
+		args = append(args, "keyed: true")
+	}
+	if loader.List {
+		args = append(args, "list: true")
+	}
+	if loader.DataPath != "" {
+		args = append(args, "data: "+strconv.Quote(loader.DataPath))
+	}
+	return directiveUse("loader", args)
+}
+
+// resolutionDirectives returns the directives reproducing the field's resolution.
+func (r *annotatedRenderer) resolutionDirectives(obj *ir.ObjectDefinition, field *ir.FieldDefinition) []string {
+	switch {
+	case field.ResolveByLoader != nil:
+		return []string{directiveUse("load", []string{"with: " + renderStringMap(field.ResolveByLoader.With)})}
+	case field.ResolveByResolver != nil:
+		if dir := r.resolveDirective(obj, field); dir != "" {
+			return []string{dir}
+		}
+	case field.ResolveByNode != nil:
+		return []string{"@node"}
+	case field.ResolveByCompute != nil:
+		return []string{directiveUse("compute", []string{"expr: " + strconv.Quote(field.ResolveByCompute.Expr)})}
+	case field.ResolveByConst != nil:
+		return []string{directiveUse("const", []string{"value: " + r.literal(field.Type, field.ResolveByConst.Value)})}
+	case field.ResolveBySource != nil:
+		var dirs []string
+		if field.ResolveBySource.SourceField != field.Name {
+			dirs = append(dirs, directiveUse("source", []string{"field: " + strconv.Quote(field.ResolveBySource.SourceField)}))
+		}
+		if field.ResolveBySource.Default != nil {
+			dirs = append(dirs, directiveUse("default", []string{"value: " + r.literal(field.Type, field.ResolveBySource.Default)}))
+		}
+		return dirs
+	}
+	return nil
+}
+
+// onErrorDirective renders the field's error policy. Type-level policies were
+// already spread over the fields, so they are rendered per field.
+func (r *annotatedRenderer) onErrorDirective(field *ir.FieldDefinition) string {
+	args := []string{"action: " + string(field.OnError.Action)}
+	if field.OnError.Action == ir.ErrorActionDefault {
+		args = append(args, "value: "+r.literal(field.Type, field.OnError.Value))
+	}
+	return directiveUse("onError", args)
+}
+
+func cacheDirective(policy *ir.CachePolicy) string {
+	args := []string{"ttl: " + strconv.Quote(policy.TTL)}
+	if policy.StaleWhileRevalidate != "" {
+		args = append(args, "staleWhileRevalidate: "+strconv.Quote(policy.StaleWhileRevalidate))
+	}
+	return directiveUse("cache", args)
+}
+
+// resolveDirective renders @resolve, or nothing when the field would receive the
+// same implicit resolver anyway: a root field or a field with arguments, mapping
+// every @id field, not batched and answering in `data`.
+func (r *annotatedRenderer) resolveDirective(obj *ir.ObjectDefinition, field *ir.FieldDefinition) string {
+	use := field.ResolveByResolver
+	batch := false
+	dataPath := ""
+	var fallbacks []string
+	if def := r.p.Resolvers[use.ResolverID]; def != nil {
+		batch = def.Batch
+		dataPath = def.DataPath
+		fallbacks = def.Fallbacks
+	}
+	defaultWith := len(use.With) == len(obj.IDFields)
+	for _, id := range obj.IDFields {
+		if use.With[id] != id {
+			defaultWith = false
+		}
+	}
+	if defaultWith && !batch && dataPath == "" && len(fallbacks) == 0 && (r.isRoot(obj.Name) || len(field.Args) > 0) {
+		return ""
+	}
+	var args []string
+	if !defaultWith {
+		args = append(args, "with: "+renderStringMap(use.With))
+	}
+	if batch {
+		args = append(args, "batch: true")
+	}
+	if dataPath != "" {
+		args = append(args, "data: "+strconv.Quote(dataPath))
+	}
+	if len(fallbacks) > 0 {
+		services := make([]string, len(fallbacks))
+		for i, name := range fallbacks {
+			services[i] = strconv.Quote(name)
+		}
+		args = append(args, "fallback: ["+strings.Join(services, ", ")+"]")
+	}
+	return directiveUse("resolve", args)
+}
+
+func (r *annotatedRenderer) isRoot(name string) bool {
+	s := r.p.Schema
+	return s != nil && (name == s.QueryType || name == s.MutationType || name == s.SubscriptionType)
+}
+
+// literal renders an IR value as a GraphQL literal of the given type. Enum values
+// are held as strings in the IR and are rendered unquoted.
+func (r *annotatedRenderer) literal(typ *ir.TypeExpr, value ir.Value) string {
+	for typ != nil && typ.Kind == ir.TypeExprKindNonNull {
+		typ = typ.OfType
+	}
+	if value == nil || typ == nil {
+		return renderValue(value)
+	}
+	switch v := value.(type) {
+	case []any:
+		elem := typ
+		if typ.Kind == ir.TypeExprKindList {
+			elem = typ.OfType
+		}
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = r.literal(elem, item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]any:
+		var input *ir.InputDefinition
+		if def := r.p.Definitions[typ.Named]; def != nil {
+			input = def.Input
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			var fieldType *ir.TypeExpr
+			if input != nil && input.InputValues[k] != nil {
+				fieldType = input.InputValues[k].Type
+			}
+			parts[i] = k + ": " + r.literal(fieldType, v[k])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case string:
+		if def := r.p.Definitions[typ.Named]; typ.Kind == ir.TypeExprKindNamed && def != nil && def.Enum != nil {
+			return v
+		}
+	}
+	return renderValue(value)
+}
+
+func directiveUse(name string, args []string) string {
+	if len(args) == 0 {
+		return "@" + name
+	}
+	return "@" + name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func renderStringMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + strconv.Quote(m[k])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
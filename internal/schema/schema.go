@@ -10,6 +10,10 @@ type Schema struct {
 	Types            map[string]*Type // All named types keyed by name
 	Directives       map[string]*Directive
 	Description      string
+	TypeOrder        []string // Type names in registration order
+	DirectiveOrder   []string // Directive names in registration order
+
+	typeRefs map[string]*TypeRef // canonical type references, keyed by SDL notation
 }
 
 // NewSchema constructs an empty schema with initialized maps.
@@ -32,34 +36,78 @@ func (s *Schema) GetSubscriptionType() *Type { return s.Types[s.SubscriptionType
 
 // SetQueryType sets the schema's root query type name.
 func (s *Schema) SetQueryType(name string) *Schema {
+	mustBeMutable(s, "schema")
 	s.QueryType = name
 	return s
 }
 
 // SetMutationType sets the schema's root mutation type name.
 func (s *Schema) SetMutationType(name string) *Schema {
+	mustBeMutable(s, "schema")
 	s.MutationType = name
 	return s
 }
 
 // SetSubscriptionType sets the schema's root subscription type name.
 func (s *Schema) SetSubscriptionType(name string) *Schema {
+	mustBeMutable(s, "schema")
 	s.SubscriptionType = name
 	return s
 }
 
 // AddType registers the given type on the schema, overriding by name.
+// The first registration of a name fixes its position in TypeOrder.
 func (s *Schema) AddType(t *Type) *Schema {
+	mustBeMutable(s, "schema")
+	if _, ok := s.Types[t.Name]; !ok {
+		s.TypeOrder = append(s.TypeOrder, t.Name)
+	}
 	s.Types[t.Name] = t
 	return s
 }
 
 // AddDirective registers the given directive on the schema, overriding by name.
+// The first registration of a name fixes its position in DirectiveOrder.
 func (s *Schema) AddDirective(d *Directive) *Schema {
+	mustBeMutable(s, "schema")
+	if _, ok := s.Directives[d.Name]; !ok {
+		s.DirectiveOrder = append(s.DirectiveOrder, d.Name)
+	}
 	s.Directives[d.Name] = d
 	return s
 }
 
+// GetOrderedTypeNames returns type names in registration order. Types placed in
+// the Types map directly are appended afterwards, sorted by name.
+func (s *Schema) GetOrderedTypeNames() []string {
+	return orderedNames(s.TypeOrder, s.Types)
+}
+
+// GetOrderedDirectiveNames returns directive names in registration order. Directives
+// placed in the Directives map directly are appended afterwards, sorted by name.
+func (s *Schema) GetOrderedDirectiveNames() []string {
+	return orderedNames(s.DirectiveOrder, s.Directives)
+}
+
+func orderedNames[T any](order []string, m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, name := range order {
+		if _, ok := m[name]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	var rest []string
+	for name := range m {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
 // Type is a named GraphQL type (object, interface, union, scalar, enum, input)
 type Type struct {
 	Name           string
@@ -72,6 +120,7 @@ type Type struct {
 	InputFields    map[string]*InputValue // For INPUT_OBJECT
 	SpecifiedByURL *string
 	OneOf          bool
+	Metadata       Metadata // annotations from SDL directive uses
 }
 
 // NewType constructs a type with initialized field and input-field maps.
@@ -87,18 +136,21 @@ func NewType(name string, kind TypeKind, description string) *Type {
 
 // SetDescription sets the type description.
 func (t *Type) SetDescription(description string) *Type {
+	mustBeMutable(t, "type")
 	t.Description = description
 	return t
 }
 
 // SetOneOf marks the type as a oneof input object.
 func (t *Type) SetOneOf(oneOf bool) *Type {
+	mustBeMutable(t, "type")
 	t.OneOf = oneOf
 	return t
 }
 
 // SetSpecifiedByURL sets or clears the specifiedBy URL for scalar types.
 func (t *Type) SetSpecifiedByURL(url string) *Type {
+	mustBeMutable(t, "type")
 	if url == "" {
 		t.SpecifiedByURL = nil
 		return t
@@ -107,8 +159,16 @@ func (t *Type) SetSpecifiedByURL(url string) *Type {
 	return t
 }
 
+// SetMetadata replaces the annotations of the type.
+func (t *Type) SetMetadata(md Metadata) *Type {
+	mustBeMutable(t, "type")
+	t.Metadata = md
+	return t
+}
+
 // AddInterface records that the type implements the provided interface.
 func (t *Type) AddInterface(name string) *Type {
+	mustBeMutable(t, "type")
 	for _, existing := range t.Interfaces {
 		if existing == name {
 			return t
@@ -120,6 +180,7 @@ func (t *Type) AddInterface(name string) *Type {
 
 // AddPossibleType records a possible type for interfaces or unions.
 func (t *Type) AddPossibleType(name string) *Type {
+	mustBeMutable(t, "type")
 	for _, existing := range t.PossibleTypes {
 		if existing == name {
 			return t
@@ -131,12 +192,15 @@ func (t *Type) AddPossibleType(name string) *Type {
 
 // AddEnumValue appends an enum value definition.
 func (t *Type) AddEnumValue(value *EnumValue) *Type {
+	mustBeMutable(t, "type")
 	t.EnumValues = append(t.EnumValues, value)
 	return t
 }
 
 // AddField registers a field on the type, auto-assigning an index when absent.
 func (t *Type) AddField(field *Field) *Type {
+	mustBeMutable(t, "type")
+	mustBeMutable(field, "field")
 	field.Index = nextFieldIndex(t.Fields)
 	t.Fields[field.Name] = field
 	return t
@@ -144,6 +208,8 @@ func (t *Type) AddField(field *Field) *Type {
 
 // AddInputField registers an input field on the type, auto-assigning an index when absent.
 func (t *Type) AddInputField(input *InputValue) *Type {
+	mustBeMutable(t, "type")
+	mustBeMutable(input, "input value")
 	input.Index = nextInputValueIndex(t.InputFields)
 	t.InputFields[input.Name] = input
 	return t
@@ -156,9 +222,11 @@ type Field struct {
 	Type              *TypeRef
 	Arguments         map[string]*InputValue
 	Async             bool
+	Optional          bool // resolves to null instead of nulling its parent when it fails
 	IsDeprecated      bool
 	DeprecationReason string
 	Index             int
+	Metadata          Metadata // annotations from SDL directive uses
 }
 
 // NewField constructs a field definition with the provided name, description, and type reference.
@@ -173,12 +241,29 @@ func NewField(name, description string, typeRef *TypeRef) *Field {
 
 // SetAsync marks whether the field resolves asynchronously.
 func (f *Field) SetAsync(async bool) *Field {
+	mustBeMutable(f, "field")
 	f.Async = async
 	return f
 }
 
+// SetOptional marks whether a failure of the Non-Null field resolves it to null
+// in place instead of propagating to the nearest nullable ancestor.
+func (f *Field) SetOptional(optional bool) *Field {
+	mustBeMutable(f, "field")
+	f.Optional = optional
+	return f
+}
+
+// SetMetadata replaces the annotations of the field.
+func (f *Field) SetMetadata(md Metadata) *Field {
+	mustBeMutable(f, "field")
+	f.Metadata = md
+	return f
+}
+
 // Deprecate marks the field as deprecated with an optional reason.
 func (f *Field) Deprecate(reason string) *Field {
+	mustBeMutable(f, "field")
 	f.IsDeprecated = true
 	f.DeprecationReason = reason
 	return f
@@ -186,6 +271,8 @@ func (f *Field) Deprecate(reason string) *Field {
 
 // AddArgument registers an argument definition for the field, assigning an index when absent.
 func (f *Field) AddArgument(arg *InputValue) *Field {
+	mustBeMutable(f, "field")
+	mustBeMutable(arg, "input value")
 	arg.Index = nextArgumentIndex(f.Arguments)
 	f.Arguments[arg.Name] = arg
 	return f
@@ -265,6 +352,7 @@ func NewEnumValue(name, description string) *EnumValue {
 
 // Deprecate marks the enum value as deprecated with an optional reason.
 func (e *EnumValue) Deprecate(reason string) *EnumValue {
+	mustBeMutable(e, "enum value")
 	e.IsDeprecated = true
 	e.DeprecationReason = reason
 	return e
@@ -278,6 +366,7 @@ type InputValue struct {
 	IsDeprecated      bool
 	DeprecationReason string
 	Index             int
+	Metadata          Metadata // annotations from SDL directive uses
 }
 
 // NewInputValue constructs an input value definition with the provided name, description, and type.
@@ -287,18 +376,28 @@ func NewInputValue(name, description string, typeRef *TypeRef) *InputValue {
 
 // SetDefault assigns the default value.
 func (v *InputValue) SetDefault(value any) *InputValue {
+	mustBeMutable(v, "input value")
 	v.DefaultValue = value
 	return v
 }
 
 // SetIndex sets the input value order index.
 func (v *InputValue) SetIndex(index int) *InputValue {
+	mustBeMutable(v, "input value")
 	v.Index = index
 	return v
 }
 
+// SetMetadata replaces the annotations of the input value.
+func (v *InputValue) SetMetadata(md Metadata) *InputValue {
+	mustBeMutable(v, "input value")
+	v.Metadata = md
+	return v
+}
+
 // Deprecate marks the input value as deprecated with an optional reason.
 func (v *InputValue) Deprecate(reason string) *InputValue {
+	mustBeMutable(v, "input value")
 	v.IsDeprecated = true
 	v.DeprecationReason = reason
 	return v
@@ -319,12 +418,14 @@ func NewDirective(name, description string) *Directive {
 
 // SetRepeatable marks whether the directive is repeatable.
 func (d *Directive) SetRepeatable(repeatable bool) *Directive {
+	mustBeMutable(d, "directive")
 	d.IsRepeatable = repeatable
 	return d
 }
 
 // AddLocation appends a directive location if not already present.
 func (d *Directive) AddLocation(location string) *Directive {
+	mustBeMutable(d, "directive")
 	for _, existing := range d.Locations {
 		if existing == location {
 			return d
@@ -336,6 +437,8 @@ func (d *Directive) AddLocation(location string) *Directive {
 
 // AddArgument appends an argument definition, maintaining insertion order.
 func (d *Directive) AddArgument(arg *InputValue) *Directive {
+	mustBeMutable(d, "directive")
+	mustBeMutable(arg, "input value")
 	arg.Index = len(d.Arguments)
 	d.Arguments = append(d.Arguments, arg)
 	return d
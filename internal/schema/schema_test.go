@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hanpama/protograph/internal/ir"
 	"github.com/stretchr/testify/require"
 )
@@ -57,8 +61,8 @@ func TestSchemaSnapshot(t *testing.T) {
 	var expected Schema
 	require.NoError(t, json.Unmarshal(expectedJSON, &expected), "failed to unmarshal snapshot schema")
 
-	// Compare snapshots
-	if diff := cmp.Diff(&expected, schema); diff != "" {
+	// Compare snapshots; the canonical type references are not part of them
+	if diff := cmp.Diff(&expected, schema, cmpopts.IgnoreUnexported(Schema{})); diff != "" {
 		t.Errorf("Schema snapshot mismatch (-want +got):\n%s", diff)
 	}
 }
@@ -110,9 +114,536 @@ func TestSchemaRenderSnapshot(t *testing.T) {
 	}
 }
 
+func TestRenderOptions(t *testing.T) {
+	s := NewSchema("").SetQueryType("Query")
+	s.AddType(stringType)
+	s.AddType(NewType("Query", TypeKindObject, "Entry point.").
+		AddField(NewField("user", "Looks up a user.\nUses the \"users\" service.", NamedType("User")).SetAsync(true)))
+	s.AddType(NewType("User", TypeKindObject, "").
+		AddField(NewField("name", "Display name.", NamedType("String"))))
+	s.AddType(NewType("Filter", TypeKindInputObject, "").
+		AddInputField(NewInputValue("term", "", NamedType("String")).SetDefault(map[string]any{"b": 1, "a": "x"})))
+
+	t.Run("default", func(t *testing.T) {
+		got := Render(s)
+		require.Less(t, strings.Index(got, "input Filter"), strings.Index(got, "type Query"))
+		require.Contains(t, got, "  \"\"\"\n  Looks up a user.\n  Uses the \"users\" service.\n  \"\"\"\n  user: User\n")
+		require.Contains(t, got, `term: String = {a: "x", b: 1}`)
+		require.NotContains(t, got, "@async")
+	})
+
+	t.Run("source order", func(t *testing.T) {
+		got := Render(s, WithSourceOrder())
+		require.Less(t, strings.Index(got, "type Query"), strings.Index(got, "type User"))
+		require.Less(t, strings.Index(got, "type User"), strings.Index(got, "input Filter"))
+	})
+
+	t.Run("descriptions", func(t *testing.T) {
+		require.NotContains(t, Render(s, WithoutDescriptions()), "Display name.")
+		got := Render(s, WithInlineDescriptions())
+		require.Contains(t, got, "\"Entry point.\"\ntype Query {\n")
+		require.Contains(t, got, "  \"Display name.\"\n  name: String\n")
+		require.Contains(t, got, "  \"\"\"\n  Looks up a user.\n")
+	})
+
+	t.Run("async annotations", func(t *testing.T) {
+		got := Render(s, WithAsyncAnnotations())
+		require.Contains(t, got, "  user: User @async\n")
+		require.Contains(t, got, "  name: String\n")
+		require.Contains(t, got, "directive @async on FIELD_DEFINITION\n")
+	})
+}
+
+func TestRenderAnnotatedRoundTrip(t *testing.T) {
+	const annotated = `
+schema { query: Query mutation: Mutation }
+
+interface Node { id: ID! }
+
+type Query {
+  node(id: ID!): Node @node
+  blog(id: ID!): Blog @cache(ttl: "30s", staleWhileRevalidate: "5m")
+  featured(tenant: String @metadata(key: "x-tenant-id")): [Post!]! @resolve(batch: true, data: "posts", fallback: ["FeaturedCache"])
+}
+
+type Mutation {
+  publish(postId: ID!, mode: Mode = DRAFT): Post
+}
+
+type Blog implements Node @loader @loader(keys: ["slug"], batch: false, data: "result.blog") {
+  id: ID!
+  slug: String!
+  ownerId: ID! @internal
+  owner: User @load(with: { id: "ownerId" }) @onError(action: NULL)
+  posts: [Post!]! @connection
+  followers: Int! @resolve(with: { blogId: "id" }, batch: true) @onError(action: DEFAULT, value: 0) @priority(level: LOW) @timeout(ms: 300) @sla(ms: 150)
+}
+
+type User implements Node @loader(keyed: true) {
+  id: ID!
+  firstName: String!
+  lastName: String! @optional
+  name: String! @compute(expr: "firstName + ' ' + lastName")
+  kind: String! @const(value: "person")
+  mode: Mode! @default(value: PUBLISHED)
+  city: String @source(field: "address.city")
+  address: Address @internal
+}
+
+type Address { city: String }
+
+union Publication @envelope(kind: ANY) = Blog | Post
+
+union Place @discriminator(field: "type", values: { Address: "ADDRESS" }) = Address | Blog
+
+type Post implements Node @loader(keys: ["id"]) @loader @loader(key: "tenant", list: true) {
+  id: ID! @id
+  tenant: String! @id
+  title: String! @experimental(since: "v2") @deprecated(reason: "use headline")
+  neighbors: [Post!]! @load(with: { tenant: "tenant" })
+}
+
+enum Mode @onUnknown(action: VALUE, value: DRAFT) @tag(name: "publishing") @tag(name: "public") { DRAFT PUBLISHED @mapValue(toProtobuf: "LIVE") }
+
+scalar Cursor @mapScalar(toProtobuf: "bytes")
+
+extend scalar ID @mapScalar(toProtobuf: "uuid")
+
+"Marks experimental fields."
+directive @experimental(since: String = "v1") on FIELD_DEFINITION
+
+directive @tag(name: String!) repeatable on ENUM
+`
+	for name, services := range map[string][]ir.InMemoryService{
+		"directives": {{Package: "test", Name: "annotated", Content: annotated}},
+		"extensions": {
+			{Package: "test", Name: "base", Content: mustReadFile(t, "testdata/base.graphql")},
+			{Package: "test", Name: "extensions", Content: mustReadFile(t, "testdata/extensions.graphql")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			orig, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery(services))
+			require.NoError(t, err)
+			sdl := RenderAnnotated(orig)
+
+			reloaded, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{
+				{Package: "test", Name: "compiled", Content: sdl},
+			}))
+			require.NoError(t, err, sdl)
+
+			// Service boundaries are not part of the SDL; everything else must survive
+			normalizeProject(orig)
+			normalizeProject(reloaded)
+			for _, pick := range []func(*ir.Project) any{
+				func(p *ir.Project) any { return p.Schema },
+				func(p *ir.Project) any { return p.Definitions },
+				func(p *ir.Project) any { return p.Directives },
+				func(p *ir.Project) any { return p.Loaders },
+				func(p *ir.Project) any { return p.Resolvers },
+			} {
+				want, err := json.Marshal(pick(orig))
+				require.NoError(t, err)
+				got, err := json.Marshal(pick(reloaded))
+				require.NoError(t, err)
+				var wantV, gotV any
+				require.NoError(t, json.Unmarshal(want, &wantV))
+				require.NoError(t, json.Unmarshal(got, &gotV))
+				if diff := cmp.Diff(wantV, gotV); diff != "" {
+					t.Fatalf("round trip mismatch (-want +got):\n%s\nSDL:\n%s", diff, sdl)
+				}
+			}
+			require.Equal(t, sdl, RenderAnnotated(reloaded))
+		})
+	}
+}
+
+// normalizeProject clears differences that carry no meaning: the order in which
+// implementers were discovered and nil versus empty interface maps.
+func normalizeProject(p *ir.Project) {
+	for _, def := range p.Definitions {
+		if def.Interface != nil {
+			sort.Strings(def.Interface.PossibleTypes)
+		}
+		if def.Object != nil && def.Object.Interfaces == nil {
+			def.Object.Interfaces = map[string]*ir.InterfaceImpl{}
+		}
+	}
+}
+
+func TestBuildFromIRInterfaceImplementations(t *testing.T) {
+	const sdl = `
+schema { query: Query }
+
+type Query { node(id: ID!): Node }
+
+interface Node {
+  id: ID!
+  label(upper: Boolean): String
+}
+
+type User implements Node @loader {
+  id: ID!
+  label(upper: Boolean): String
+  name: String!
+}
+`
+	build := func(t *testing.T) *ir.Project {
+		t.Helper()
+		proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{
+			{Package: "test", Name: "test", Content: sdl},
+		}))
+		require.NoError(t, err)
+		return proj
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		_, err := BuildFromIR(build(t))
+		require.NoError(t, err)
+	})
+
+	t.Run("reports every incompatible field", func(t *testing.T) {
+		proj := build(t)
+		user := proj.Definitions["User"].Object
+		delete(user.Fields, "label")
+		user.Fields["id"].Type = &ir.TypeExpr{Kind: ir.TypeExprKindNamed, Named: "String"}
+
+		_, err := BuildFromIR(proj)
+		require.Error(t, err)
+		require.ErrorContains(t, err, `type User is missing field "label" required by Node`)
+		require.ErrorContains(t, err, "field User.id has type String but Node expects ID! (or a subtype)")
+	})
+
+	t.Run("inherits omitted fields", func(t *testing.T) {
+		proj := build(t)
+		delete(proj.Definitions["User"].Object.Fields, "label")
+
+		s, err := BuildFromIR(proj, WithInterfaceFieldInheritance())
+		require.NoError(t, err)
+		label := s.Types["User"].Field("label")
+		require.NotNil(t, label)
+		require.False(t, label.Async)
+		require.NotNil(t, label.Argument("upper"))
+	})
+}
+
+func TestBuildFromIRSchemaMetadata(t *testing.T) {
+	const sdl = `
+"""
+Storefront API.
+"""
+schema { query: Query }
+
+directive @tag(name: String!) repeatable on FIELD_DEFINITION
+
+scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")
+
+scalar Cursor
+
+type Query { now: DateTime after: Cursor }
+`
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{
+		{Package: "test", Name: "test", Content: sdl},
+	}))
+	require.NoError(t, err)
+	s, err := BuildFromIR(proj)
+	require.NoError(t, err)
+
+	require.Equal(t, "Storefront API.", s.Description)
+	require.NotNil(t, s.Types["DateTime"].SpecifiedByURL)
+	require.Equal(t, "https://scalars.graphql.org/andimarek/date-time", *s.Types["DateTime"].SpecifiedByURL)
+	require.Nil(t, s.Types["Cursor"].SpecifiedByURL)
+	require.True(t, s.Directives["tag"].IsRepeatable)
+
+	sdlOut := Render(s)
+	require.Contains(t, sdlOut, "\"\"\"\nStorefront API.\n\"\"\"\nschema {\n  query: Query\n}\n")
+	require.Contains(t, sdlOut, `scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")`)
+	require.Contains(t, sdlOut, "directive @tag(name: String!) repeatable on FIELD_DEFINITION")
+	require.NotContains(t, Render(s, WithoutDescriptions()), "schema {")
+
+	annotated := RenderAnnotated(proj)
+	require.Contains(t, annotated, "\"\"\"\nStorefront API.\n\"\"\"\nschema {")
+	require.Contains(t, annotated, `scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")`)
+}
+
+func TestBuildFromIROptionalFieldsArePublishedNullable(t *testing.T) {
+	const sdl = `
+schema { query: Query }
+
+type Query { user(id: ID!): User! }
+
+type User @loader {
+  id: ID!
+  name: String!
+  avatarUrl: String! @resolve @optional
+}
+`
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{
+		{Package: "test", Name: "test", Content: sdl},
+	}))
+	require.NoError(t, err)
+	s, err := BuildFromIR(proj)
+	require.NoError(t, err)
+
+	user := s.Types["User"]
+	require.Equal(t, "String", user.Field("avatarUrl").Type.String())
+	require.True(t, user.Field("avatarUrl").Optional)
+	require.Equal(t, "String!", user.Field("name").Type.String())
+
+	require.Contains(t, Render(s), "  avatarUrl: String\n")
+	// The annotated SDL keeps the field as declared
+	require.Contains(t, RenderAnnotated(proj), "avatarUrl: String! @optional @resolve")
+}
+
+func TestCloneAndFreeze(t *testing.T) {
+	build := func() *Schema {
+		s := NewSchema("shop").SetQueryType("Query")
+		query := NewType("Query", TypeKindObject, "")
+		field := NewField("items", "", ListType(NamedType("String")))
+		field.AddArgument(NewInputValue("filter", "", NamedType("Filter")).SetDefault(map[string]any{"tags": []any{"new"}}))
+		query.AddField(field)
+		s.AddType(query).
+			AddType(NewType("Filter", TypeKindInputObject, "")).
+			AddType(NewType("Date", TypeKindScalar, "").SetSpecifiedByURL("https://example.com/date")).
+			AddDirective(NewDirective("tag", "").AddLocation("FIELD_DEFINITION").SetRepeatable(true))
+		return s
+	}
+
+	t.Run("clone is deep", func(t *testing.T) {
+		orig := build()
+		c := orig.Clone()
+		require.Equal(t, orig, c)
+
+		c.GetQueryType().Field("items").SetAsync(true)
+		c.GetQueryType().Field("items").Type.OfType.Named = "Int"
+		c.GetQueryType().Field("items").Argument("filter").DefaultValue.(map[string]any)["tags"].([]any)[0] = "old"
+		c.Types["Date"].SetSpecifiedByURL("")
+		c.Directives["tag"].AddLocation("OBJECT")
+		c.AddType(NewType("Extra", TypeKindScalar, ""))
+
+		require.Equal(t, build(), orig)
+	})
+
+	t.Run("frozen schema panics on mutation", func(t *testing.T) {
+		if !freezeChecks {
+			t.Skip("freeze checks disabled in release builds")
+		}
+		s := build().Freeze()
+		require.True(t, s.Frozen())
+		require.PanicsWithValue(t, "schema: mutation of frozen schema", func() { s.AddType(NewType("X", TypeKindScalar, "")) })
+		require.PanicsWithValue(t, "schema: mutation of frozen type", func() { s.GetQueryType().SetDescription("x") })
+		require.PanicsWithValue(t, "schema: mutation of frozen field", func() { s.GetQueryType().Field("items").SetAsync(true) })
+		require.PanicsWithValue(t, "schema: mutation of frozen input value", func() {
+			s.GetQueryType().Field("items").Argument("filter").SetDefault(nil)
+		})
+		require.PanicsWithValue(t, "schema: mutation of frozen directive", func() { s.Directives["tag"].AddLocation("OBJECT") })
+
+		c := s.Clone()
+		require.False(t, c.Frozen())
+		c.GetQueryType().Field("items").SetAsync(true)
+		c.AddType(NewType("X", TypeKindScalar, ""))
+	})
+}
+
+func TestSchemaBuilder(t *testing.T) {
+	query := func() *Type {
+		return NewType("Query", TypeKindObject, "").AddField(NewField("name", "", NamedType("String")))
+	}
+
+	t.Run("builds a frozen validated schema", func(t *testing.T) {
+		b := NewSchemaBuilder("api").SetQueryType("Query")
+		var wg sync.WaitGroup
+		for _, typ := range []*Type{query(), NewType("String", TypeKindScalar, ""), NewType("Date", TypeKindScalar, "")} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.AddType(typ)
+			}()
+		}
+		wg.Wait()
+		b.AddDirective(NewDirective("tag", "").AddLocation("FIELD_DEFINITION"))
+
+		s, err := b.Build()
+		require.NoError(t, err)
+		require.Equal(t, "api", s.Description)
+		require.ElementsMatch(t, []string{"Query", "String", "Date"}, s.TypeOrder)
+		require.Equal(t, []string{"tag"}, s.DirectiveOrder)
+		if freezeChecks {
+			require.True(t, s.Frozen())
+		}
+		require.PanicsWithValue(t, "schema: SchemaBuilder used after Build", func() { b.AddType(NewType("X", TypeKindScalar, "")) })
+	})
+
+	t.Run("reports duplicates and validation errors", func(t *testing.T) {
+		_, err := NewSchemaBuilder("").
+			SetQueryType("Query").
+			AddType(query()).
+			AddType(query()).
+			AddType(NewType("__Meta", TypeKindScalar, "")).
+			AddDirective(NewDirective("tag", "")).
+			AddDirective(NewDirective("tag", "")).
+			Build()
+		require.Error(t, err)
+		require.ErrorContains(t, err, "type Query is defined more than once")
+		require.ErrorContains(t, err, "directive @tag is defined more than once")
+		require.ErrorContains(t, err, "Query.name references undefined type String")
+		require.ErrorContains(t, err, `type name __Meta must not begin with "__"`)
+	})
+
+	t.Run("reserved names when allowed", func(t *testing.T) {
+		_, err := NewSchemaBuilder("").
+			SetQueryType("Query").
+			AllowReservedNames().
+			AddType(query()).
+			AddType(NewType("String", TypeKindScalar, "")).
+			AddType(NewType("__Meta", TypeKindScalar, "")).
+			Build()
+		require.NoError(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("snapshot schema is valid", func(t *testing.T) {
+		disc := ir.NewInMemoryDiscovery([]ir.InMemoryService{
+			{Package: "test", Name: "base", Content: mustReadFile(t, "testdata/base.graphql")},
+			{Package: "test", Name: "extensions", Content: mustReadFile(t, "testdata/extensions.graphql")},
+		})
+		proj, err := ir.Build(context.Background(), disc)
+		require.NoError(t, err)
+		s, err := BuildFromIR(proj)
+		require.NoError(t, err)
+		require.NoError(t, Validate(s))
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		s := NewSchema("").SetQueryType("Query").SetMutationType("Input")
+		s.AddType(stringType).AddType(idType)
+		s.AddType(NewType("Query", TypeKindObject, "").
+			AddField(NewField("a", "", NamedType("Input"))).
+			AddField(NewField("b", "", NamedType("Missing"))).
+			AddField(NewField("c", "", NamedType("String")).
+				AddArgument(NewInputValue("q", "", NamedType("Query")))))
+		s.AddType(NewType("Input", TypeKindInputObject, "").
+			AddInputField(NewInputValue("next", "", NonNullType(NamedType("Other")))))
+		s.AddType(NewType("Other", TypeKindInputObject, "").
+			AddInputField(NewInputValue("back", "", NonNullType(NamedType("Input")))).
+			AddInputField(NewInputValue("list", "", NonNullType(ListType(NonNullType(NamedType("Other")))))))
+		s.AddType(NewType("Result", TypeKindUnion, "").AddPossibleType("String"))
+		s.AddDirective(NewDirective("tag", "").AddArgument(NewInputValue("of", "", NamedType("Query"))))
+
+		err := Validate(s)
+		require.Error(t, err)
+		for _, want := range []string{
+			"mutation root type Input must be an object type, got INPUT_OBJECT",
+			"Query.a must be an output type, but Input is an input object",
+			"Query.b references undefined type Missing",
+			"Query.c(q:) must be an input type, but Query is object",
+			"union Result member String must be an object type",
+			"@tag(of:) must be an input type, but Query is object",
+			"input objects form an unbreakable non-null cycle: Input -> Other -> Input",
+		} {
+			require.ErrorContains(t, err, want)
+		}
+		require.NotContains(t, err.Error(), "Other -> Other", "list fields break input cycles")
+	})
+}
+
 func mustReadFile(t *testing.T, path string) string {
 	t.Helper()
 	content, err := os.ReadFile(path)
 	require.NoError(t, err, "failed to read file: %s", path)
 	return string(content)
 }
+
+func TestBuildFromIRCustomRootTypes(t *testing.T) {
+	const sdl = `
+schema { query: RootQuery mutation: RootMutation }
+
+type RootQuery { user(id: ID!): User }
+type RootMutation { rename(id: ID!, name: String!): User }
+type User @loader { id: ID! name: String! }
+`
+	proj, err := ir.Build(context.Background(), ir.NewInMemoryDiscovery([]ir.InMemoryService{
+		{Package: "test", Name: "test", Content: sdl},
+	}))
+	require.NoError(t, err)
+	s, err := BuildFromIR(proj)
+	require.NoError(t, err)
+
+	require.Equal(t, "RootQuery", s.GetQueryType().Name)
+	require.Equal(t, "RootMutation", s.GetMutationType().Name)
+	require.True(t, s.GetQueryType().Field("user").Async)
+	require.True(t, s.GetMutationType().Field("rename").Async)
+	require.Contains(t, Render(s), "schema {\n  query: RootQuery\n  mutation: RootMutation\n}\n")
+
+	// A compact schema definition is not mistaken for a missing one
+	s, err = BuildFromSDL("schema{query:Root}\ntype Root { version: String! }")
+	require.NoError(t, err)
+	require.Equal(t, "Root", s.QueryType)
+}
+
+func TestTypeRefInterning(t *testing.T) {
+	s, err := BuildFromSDL(`
+type Query {
+  a(ids: [ID!]!): [String!]!
+  b(tags: [String!]!): [String!]
+  c: String
+}
+input Filter { tags: [String!]! }
+`)
+	require.NoError(t, err)
+
+	q := s.GetQueryType()
+	a, b := q.Field("a").Type, q.Field("b").Type
+	require.Same(t, a.OfType, b, "[String!] shared between fields")
+	require.Same(t, a.OfType.OfType.OfType, q.Field("c").Type, "String shared at every depth")
+	require.Same(t, q.Field("b").Argument("tags").Type, s.Types["Filter"].InputFields["tags"].Type)
+	require.Same(t, a, s.CanonicalTypeRef(NonNullType(ListType(NonNullType(NamedType("String"))))))
+
+	other := NamedType("Unused")
+	require.Same(t, other, s.CanonicalTypeRef(other))
+
+	require.Equal(t, "[ID!]!", q.Field("a").Argument("ids").Type.String())
+	require.True(t, a.Equal(NonNullType(ListType(NonNullType(NamedType("String"))))))
+	require.False(t, a.Equal(b))
+
+	// Clones own their references
+	c := s.Clone()
+	require.NotSame(t, a, c.GetQueryType().Field("a").Type)
+	require.True(t, a.Equal(c.GetQueryType().Field("a").Type))
+}
+
+func TestBuildFromIRMetadata(t *testing.T) {
+	s, err := BuildFromSDL(`
+directive @cost(weight: Int!) on FIELD_DEFINITION | OBJECT
+directive @auth(requires: [String!]!) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+directive @tag(name: String!) repeatable on ENUM
+
+type Query @cost(weight: 2) {
+  users(org: ID @auth(requires: "org:read"), filter: Filter): [String!]! @cost(weight: 10)
+  role: Role
+}
+input Filter { owner: ID @auth(requires: ["admin", "self"]) }
+enum Role @tag(name: "iam") @tag(name: "public") { ADMIN MEMBER }
+`)
+	require.NoError(t, err)
+
+	q := s.GetQueryType()
+	weight, ok := q.Metadata.Value("cost", "weight")
+	require.True(t, ok)
+	require.Equal(t, float64(2), weight)
+	weight, _ = q.Field("users").Metadata.Value("cost", "weight")
+	require.Equal(t, float64(10), weight)
+	require.False(t, q.Field("role").Metadata.Has("cost"))
+
+	requires, _ := q.Field("users").Argument("org").Metadata.Value("auth", "requires")
+	require.Equal(t, []any{"org:read"}, requires)
+	requires, _ = s.Types["Filter"].InputField("owner").Metadata.Value("auth", "requires")
+	require.Equal(t, []any{"admin", "self"}, requires)
+	require.Equal(t, []map[string]any{{"name": "iam"}, {"name": "public"}}, s.Types["Role"].Metadata.All("tag"))
+
+	// Clones own their metadata
+	c := s.Clone()
+	c.GetQueryType().Metadata["cost"][0]["weight"] = float64(3)
+	weight, _ = q.Metadata.Value("cost", "weight")
+	require.Equal(t, float64(2), weight)
+}
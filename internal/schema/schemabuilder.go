@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SchemaBuilder accumulates types and directives and produces a validated, frozen
+// Schema. Unlike Schema.AddType and Schema.AddDirective, registering a name twice
+// is reported as an error instead of silently replacing the earlier definition.
+//
+// A SchemaBuilder is safe for concurrent use. Types registered concurrently are
+// ordered by arrival, so callers that need a deterministic TypeOrder should
+// register from a single goroutine.
+type SchemaBuilder struct {
+	mu            sync.Mutex
+	schema        *Schema
+	errs          []error
+	allowReserved bool
+	built         bool
+}
+
+// NewSchemaBuilder returns an empty builder for a schema with the given description.
+func NewSchemaBuilder(description string) *SchemaBuilder {
+	return &SchemaBuilder{schema: NewSchema(description)}
+}
+
+// SetQueryType sets the root query type name.
+func (b *SchemaBuilder) SetQueryType(name string) *SchemaBuilder {
+	return b.with(func(s *Schema) { s.SetQueryType(name) })
+}
+
+// SetMutationType sets the root mutation type name.
+func (b *SchemaBuilder) SetMutationType(name string) *SchemaBuilder {
+	return b.with(func(s *Schema) { s.SetMutationType(name) })
+}
+
+// SetSubscriptionType sets the root subscription type name.
+func (b *SchemaBuilder) SetSubscriptionType(name string) *SchemaBuilder {
+	return b.with(func(s *Schema) { s.SetSubscriptionType(name) })
+}
+
+// AllowReservedNames accepts types whose names begin with "__". Only the
+// introspection extension, which registers the __Schema family, needs it.
+func (b *SchemaBuilder) AllowReservedNames() *SchemaBuilder {
+	return b.with(func(*Schema) { b.allowReserved = true })
+}
+
+// AddType registers t. A second type with the same name is reported by Build.
+func (b *SchemaBuilder) AddType(t *Type) *SchemaBuilder {
+	return b.with(func(s *Schema) {
+		if _, ok := s.Types[t.Name]; ok {
+			b.errs = append(b.errs, fmt.Errorf("type %s is defined more than once", t.Name))
+			return
+		}
+		s.AddType(t)
+	})
+}
+
+// AddDirective registers d. A second directive with the same name is reported by Build.
+func (b *SchemaBuilder) AddDirective(d *Directive) *SchemaBuilder {
+	return b.with(func(s *Schema) {
+		if _, ok := s.Directives[d.Name]; ok {
+			b.errs = append(b.errs, fmt.Errorf("directive @%s is defined more than once", d.Name))
+			return
+		}
+		s.AddDirective(d)
+	})
+}
+
+// Build validates the accumulated definitions and returns the frozen schema. It
+// reports duplicate registrations together with every Validate error. The builder
+// must not be used after Build.
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkNotBuilt()
+	b.built = true
+
+	errs := append([]error(nil), b.errs...)
+	if err := validate(b.schema, b.allowReserved); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	b.schema.internTypeRefs()
+	return b.schema.Freeze(), nil
+}
+
+func (b *SchemaBuilder) with(fn func(*Schema)) *SchemaBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkNotBuilt()
+	fn(b.schema)
+	return b
+}
+
+func (b *SchemaBuilder) checkNotBuilt() {
+	if b.built {
+		panic("schema: SchemaBuilder used after Build")
+	}
+}
@@ -0,0 +1,75 @@
+package schema
+
+// Equal reports whether t and o reference the same wrapped type.
+func (t *TypeRef) Equal(o *TypeRef) bool {
+	if t == o {
+		return true
+	}
+	if t == nil || o == nil {
+		return false
+	}
+	return t.Kind == o.Kind && t.Named == o.Named && t.OfType.Equal(o.OfType)
+}
+
+// String returns the SDL notation of t, e.g. "[String!]!".
+func (t *TypeRef) String() string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case TypeRefKindNamed:
+		return t.Named
+	case TypeRefKindList:
+		return "[" + t.OfType.String() + "]"
+	case TypeRefKindNonNull:
+		return t.OfType.String() + "!"
+	}
+	return ""
+}
+
+// CanonicalTypeRef returns the schema's shared TypeRef equal to t, or t when the
+// schema holds none. Schemas returned by SchemaBuilder.Build share one TypeRef per
+// distinct type among all fields, arguments and input fields, wrappers included,
+// so equal references are pointer-equal and can key caches directly.
+func (s *Schema) CanonicalTypeRef(t *TypeRef) *TypeRef {
+	if c, ok := s.typeRefs[t.String()]; ok {
+		return c
+	}
+	return t
+}
+
+// internTypeRefs replaces every type reference of s with its canonical TypeRef.
+// It runs before s is frozen, as the replaced references are written in place.
+func (s *Schema) internTypeRefs() {
+	s.typeRefs = make(map[string]*TypeRef)
+	intern := func(args map[string]*InputValue) {
+		for _, arg := range args {
+			arg.Type = s.internTypeRef(arg.Type)
+		}
+	}
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			f.Type = s.internTypeRef(f.Type)
+			intern(f.Arguments)
+		}
+		intern(t.InputFields)
+	}
+	for _, d := range s.Directives {
+		for _, arg := range d.Arguments {
+			arg.Type = s.internTypeRef(arg.Type)
+		}
+	}
+}
+
+func (s *Schema) internTypeRef(t *TypeRef) *TypeRef {
+	if t == nil {
+		return nil
+	}
+	key := t.String()
+	if c, ok := s.typeRefs[key]; ok {
+		return c
+	}
+	c := &TypeRef{Kind: t.Kind, Named: t.Named, OfType: s.internTypeRef(t.OfType)}
+	s.typeRefs[key] = c
+	return c
+}
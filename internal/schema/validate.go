@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate checks the structural soundness of a built schema and reports every
+// problem found, joined into a single error:
+//   - types are registered under their own names and do not use the reserved "__" prefix
+//   - root operation types exist and are objects
+//   - every type reference resolves, with output types on fields and input types on
+//     arguments, input fields and directive arguments
+//   - union members are objects and implemented interfaces are interfaces
+//   - input objects do not form cycles through non-null, non-list fields, which no
+//     finite value could satisfy
+func Validate(s *Schema) error {
+	return validate(s, false)
+}
+
+func validate(s *Schema, allowReserved bool) error {
+	v := &validator{schema: s, allowReserved: allowReserved}
+	v.validateRoots()
+	for _, name := range sortedTypeNames(s) {
+		v.validateType(name, s.Types[name])
+	}
+	v.validateDirectives()
+	v.validateInputCycles()
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	schema        *Schema
+	allowReserved bool
+	errs          []error
+}
+
+func (v *validator) errorf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) validateRoots() {
+	if v.schema.QueryType == "" {
+		v.errorf("schema must define a query root type")
+	}
+	for _, root := range []struct{ op, name string }{
+		{"query", v.schema.QueryType},
+		{"mutation", v.schema.MutationType},
+		{"subscription", v.schema.SubscriptionType},
+	} {
+		if root.name == "" {
+			continue
+		}
+		t, ok := v.schema.Types[root.name]
+		if !ok {
+			v.errorf("%s root type %s is not defined", root.op, root.name)
+			continue
+		}
+		if t.Kind != TypeKindObject {
+			v.errorf("%s root type %s must be an object type, got %s", root.op, root.name, t.Kind)
+		}
+	}
+}
+
+func (v *validator) validateType(key string, t *Type) {
+	if t.Name != key {
+		v.errorf("type %s is registered under a different name %q", t.Name, key)
+	}
+	if strings.HasPrefix(t.Name, "__") && !v.allowReserved {
+		v.errorf("type name %s must not begin with \"__\", which is reserved for introspection", t.Name)
+	}
+
+	switch t.Kind {
+	case TypeKindObject, TypeKindInterface:
+		if len(t.Fields) == 0 {
+			v.errorf("%s type %s must define at least one field", strings.ToLower(string(t.Kind)), t.Name)
+		}
+		for _, name := range t.Interfaces {
+			if iface, ok := v.schema.Types[name]; !ok || iface.Kind != TypeKindInterface {
+				v.errorf("type %s implements %s, which is not an interface", t.Name, name)
+			}
+		}
+		for _, f := range t.GetOrderedFields() {
+			where := t.Name + "." + f.Name
+			v.validateTypeRef(where, f.Type, true)
+			for _, arg := range f.GetOrderedArguments() {
+				v.validateTypeRef(where+"("+arg.Name+":)", arg.Type, false)
+			}
+		}
+	case TypeKindUnion:
+		if len(t.PossibleTypes) == 0 {
+			v.errorf("union %s must have at least one member", t.Name)
+		}
+		for _, name := range t.PossibleTypes {
+			if member, ok := v.schema.Types[name]; !ok || member.Kind != TypeKindObject {
+				v.errorf("union %s member %s must be an object type", t.Name, name)
+			}
+		}
+	case TypeKindInputObject:
+		if len(t.InputFields) == 0 {
+			v.errorf("input %s must define at least one field", t.Name)
+		}
+		for _, f := range t.GetOrderedInputFields() {
+			v.validateTypeRef(t.Name+"."+f.Name, f.Type, false)
+		}
+	case TypeKindEnum:
+		if len(t.EnumValues) == 0 {
+			v.errorf("enum %s must define at least one value", t.Name)
+		}
+	}
+}
+
+func (v *validator) validateDirectives() {
+	names := make([]string, 0, len(v.schema.Directives))
+	for name := range v.schema.Directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, arg := range v.schema.Directives[name].Arguments {
+			v.validateTypeRef("@"+name+"("+arg.Name+":)", arg.Type, false)
+		}
+	}
+}
+
+// validateTypeRef checks that ref resolves to a type usable in output (fields) or
+// input (arguments, input fields) position.
+func (v *validator) validateTypeRef(where string, ref *TypeRef, output bool) {
+	name := ref.GetNamedType()
+	t, ok := v.schema.Types[name]
+	if !ok {
+		v.errorf("%s references undefined type %s", where, name)
+		return
+	}
+	switch {
+	case output && t.Kind == TypeKindInputObject:
+		v.errorf("%s must be an output type, but %s is an input object", where, name)
+	case !output && (t.Kind == TypeKindObject || t.Kind == TypeKindInterface || t.Kind == TypeKindUnion):
+		v.errorf("%s must be an input type, but %s is %s", where, name, strings.ToLower(string(t.Kind)))
+	}
+}
+
+// validateInputCycles reports input objects that reference themselves through a chain
+// of non-null, non-list input fields.
+func (v *validator) validateInputCycles() {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, f := range v.schema.Types[name].GetOrderedInputFields() {
+			if f.Type.Kind != TypeRefKindNonNull || f.Type.OfType.Kind != TypeRefKindNamed {
+				continue
+			}
+			next, ok := v.schema.Types[f.Type.OfType.Named]
+			if !ok || next.Kind != TypeKindInputObject {
+				continue
+			}
+			switch state[next.Name] {
+			case visiting:
+				start := 0
+				for path[start] != next.Name {
+					start++
+				}
+				cycle := append(append([]string{}, path[start:]...), next.Name)
+				v.errorf("input objects form an unbreakable non-null cycle: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				visit(next.Name)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, name := range sortedTypeNames(v.schema) {
+		if v.schema.Types[name].Kind == TypeKindInputObject && state[name] == unvisited {
+			visit(name)
+		}
+	}
+}
+
+func sortedTypeNames(s *Schema) []string {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+// QueryCacheStats reports the activity of the query cache.
+type QueryCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// HitRate returns the fraction of lookups served from the cache.
+func (s QueryCacheStats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+type queryKey struct {
+	hash          [sha256.Size]byte
+	operationName string
+}
+
+// cachedQuery is a parsed document with its selected operation. op is nil when
+// the executor could not prepare the operation; such requests still skip parsing
+// and report the error from execution.
+type cachedQuery struct {
+	key queryKey
+	doc *language.QueryDocument
+	op  *executor.PreparedOperation
+}
+
+// queryCache is an LRU of parsed and prepared operations keyed by the SHA-256 of
+// the query text and the operation name. Documents that fail to parse are not
+// cached.
+type queryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[queryKey]*list.Element
+	order    *list.List // front is most recently used
+
+	hits, misses, evictions atomic.Uint64
+}
+
+func newQueryCache(capacity int) *queryCache {
+	return &queryCache{capacity: capacity, entries: make(map[queryKey]*list.Element), order: list.New()}
+}
+
+func (c *queryCache) get(key queryKey) (*cachedQuery, bool) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return el.Value.(*cachedQuery), true
+}
+
+func (c *queryCache) add(q *cachedQuery) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[q.key]; ok {
+		// A concurrent miss prepared the same query first
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[q.key] = c.order.PushFront(q)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedQuery).key)
+		c.evictions.Add(1)
+	}
+}
+
+func (c *queryCache) stats() QueryCacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return QueryCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+		Capacity:  c.capacity,
+	}
+}
+
+// lookupQuery parses and prepares req, consulting the query cache when enabled.
+func (h *Handler) lookupQuery(req GraphQLRequest) (*cachedQuery, error) {
+	var key queryKey
+	if h.queries != nil {
+		key = queryKey{hash: sha256.Sum256([]byte(req.Query)), operationName: req.OperationName}
+		if q, ok := h.queries.get(key); ok {
+			return q, nil
+		}
+	}
+	doc, err := language.ParseQuery(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	q := &cachedQuery{key: key, doc: doc}
+	if h.queries != nil {
+		q.op, _ = h.exec.Prepare(doc, req.OperationName)
+		h.queries.add(q)
+	}
+	return q, nil
+}
+
+// QueryCacheStats returns the query cache counters, or zero values when the
+// cache is disabled.
+func (h *Handler) QueryCacheStats() QueryCacheStats {
+	if h.queries == nil {
+		return QueryCacheStats{}
+	}
+	return h.queries.stats()
+}
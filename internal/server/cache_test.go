@@ -0,0 +1,63 @@
+package server
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+func TestQueryCache_HitsAndEvictions(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	h := newTestHandler(t, rt, WithQueryCache(2))
+
+	post := func(body string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+		return w.Body.String()
+	}
+	for _, body := range []string{
+		`{"query":"{ hello }"}`,
+		`{"query":"{ hello }"}`,
+		`{"query":"query A { hello } query B { hello }","operationName":"A"}`,
+		`{"query":"query A { hello } query B { hello }","operationName":"B"}`,
+		`{"query":"{ hello }"}`,
+	} {
+		if got := post(body); got != "{\"data\":{\"hello\":\"world\"}}\n" {
+			t.Fatalf("%s: got %s", body, got)
+		}
+	}
+	if got := post(`{"query":"{ hello"}`); !bytes.Contains([]byte(got), []byte(`"errors"`)) {
+		t.Fatalf("parse error not reported: %s", got)
+	}
+	if got := post(`{"query":"query A { hello }","operationName":"C"}`); !bytes.Contains([]byte(got), []byte(`operation not found`)) {
+		t.Fatalf("unknown operation not reported: %s", got)
+	}
+
+	want := QueryCacheStats{Hits: 1, Misses: 6, Evictions: 3, Size: 2, Capacity: 2}
+	if got := h.QueryCacheStats(); got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if rate := want.HitRate(); rate != 1.0/7 {
+		t.Fatalf("hit rate = %v", rate)
+	}
+}
+
+func TestQueryCache_Disabled(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	h := newTestHandler(t, rt)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`)))
+	if w.Body.String() != "{\"data\":{\"hello\":\"world\"}}\n" {
+		t.Fatalf("got %s", w.Body.String())
+	}
+	if got := h.QueryCacheStats(); got != (QueryCacheStats{}) {
+		t.Fatalf("stats = %+v", got)
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// ResponseEncoder writes a response in a format other than JSON. It receives the
+// response in generic form: an Object with "data", and "errors" and
+// "extensions" when present, or a []any of them for batched requests. Values
+// below are nil, bool, string, int64, uint64, float64, []any and Object, shaped
+// as in the JSON response.
+type ResponseEncoder func(w io.Writer, response any) error
+
+// Object is an object of a response in generic form: its entries in the order
+// of the JSON response, data keys in query order.
+type Object []Field
+
+// Field is an entry of an Object.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Len returns the number of entries of o.
+func (o Object) Len() int { return len(o) }
+
+// All yields the entries of o in order.
+func (o Object) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for _, f := range o {
+			if !yield(f.Key, f.Value) {
+				return
+			}
+		}
+	}
+}
+
+// write writes v as JSON, or with the ResponseEncoder of a media type r
+// accepts.
+func (h *Handler) write(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if len(h.opt.ResponseEncoders) == 0 {
+		writeJSON(w, status, v, h.opt.Pretty)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	contentType, enc := h.responseEncoder(r.Header.Get("Accept"))
+	if enc == nil {
+		writeJSON(w, status, v, h.opt.Pretty)
+		return
+	}
+	generic, err := genericResponse(v)
+	if err != nil {
+		// Values without a generic form are still answered, in JSON
+		writeJSON(w, status, v, h.opt.Pretty)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = enc(w, generic)
+}
+
+// responseEncoder returns the encoder of the first media type listed in accept
+// that has one, unless JSON or */* is listed before it.
+func (h *Handler) responseEncoder(accept string) (string, ResponseEncoder) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if enc, ok := h.opt.ResponseEncoders[mediaType]; ok {
+			return mediaType, enc
+		}
+		switch mediaType {
+		case "application/json", "application/graphql-response+json", "*/*":
+			return "", nil
+		}
+	}
+	return "", nil
+}
+
+// genericResponse converts a response returned by executeOne to the form
+// ResponseEncoders receive.
+func genericResponse(v any) (any, error) {
+	switch r := v.(type) {
+	case []any:
+		out := make([]any, len(r))
+		for i, item := range r {
+			g, err := genericResponse(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = g
+		}
+		return out, nil
+	case *executor.ExecutionResult:
+		return genericEnvelope(r.KeyOrder, r.Data, r.Errors, len(r.Errors) > 0, r.Extensions)
+	case specResult:
+		return genericEnvelope(r.keyOrder, r.Data, r.Errors, len(r.Errors) > 0, r.Extensions)
+	default:
+		// Cached responses and execution plans
+		return genericJSON(v)
+	}
+}
+
+// genericEnvelope lists the entries of a response in the order streamJSON
+// writes them.
+func genericEnvelope(order *executor.KeyOrder, data, errors any, hasErrors bool, extensions map[string]any) (any, error) {
+	d, err := genericValue(order, data)
+	if err != nil {
+		return nil, err
+	}
+	out := Object{{Key: "data", Value: d}}
+	if hasErrors {
+		e, err := genericJSON(errors)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Field{Key: "errors", Value: e})
+	}
+	if len(extensions) > 0 {
+		e, err := genericJSON(extensions)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Field{Key: "extensions", Value: e})
+	}
+	return out, nil
+}
+
+// genericValue converts response data, walking objects, in the key order of
+// order, and lists as they are the bulk of a response, and any other value
+// through its JSON encoding.
+func genericValue(order *executor.KeyOrder, v any) (any, error) {
+	switch v := v.(type) {
+	case nil, bool, string, int64, uint64, float64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case float32:
+		// The shortest decimal of the float32, as JSON renders it
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+		return f, nil
+	case map[string]any:
+		if v == nil {
+			return nil, nil
+		}
+		out := make(Object, 0, len(v))
+		for k, item := range order.Fields(v) {
+			g, err := genericValue(order, item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Field{Key: k, Value: g})
+		}
+		return out, nil
+	case []any:
+		if v == nil {
+			return nil, nil
+		}
+		out := make([]any, len(v))
+		for i, item := range v {
+			g, err := genericValue(order, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = g
+		}
+		return out, nil
+	default:
+		// Leaf objects completed by the runtime and custom scalars
+		return genericJSON(v)
+	}
+}
+
+// genericJSON converts v through its JSON encoding, keeping integers exact and
+// objects in the order they are encoded.
+func genericJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return genericToken(dec)
+}
+
+// genericToken decodes the next value of dec.
+func genericToken(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			out := []any{}
+			for dec.More() {
+				item, err := genericToken(dec)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, item)
+			}
+			_, err := dec.Token()
+			return out, err
+		}
+		out := Object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			item, err := genericToken(dec)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Field{Key: key.(string), Value: item})
+		}
+		_, err := dec.Token()
+		return out, err
+	case json.Number:
+		if n, err := tok.Int64(); err == nil {
+			return n, nil
+		}
+		if n, err := strconv.ParseUint(string(tok), 10, 64); err == nil {
+			return n, nil
+		}
+		f, _ := tok.Float64()
+		return f, nil
+	default:
+		// nil, bool and string
+		return tok, nil
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+func TestResponseEncoders(t *testing.T) {
+	sch, err := schema.BuildFromSDL(`type Query { hello: String count: Int ratio: Float }`)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(context.Context, any, map[string]any) (any, error) { return "world", nil },
+		"Query.count": func(context.Context, any, map[string]any) (any, error) { return int32(7), nil },
+		"Query.ratio": func(context.Context, any, map[string]any) (any, error) { return float32(0.1), nil },
+	})
+	var got any
+	h, err := New(rt, sch, WithResponseEncoder("application/x-Test", func(w io.Writer, response any) error {
+		got = response
+		_, err := w.Write([]byte("encoded"))
+		return err
+	}))
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		accept string
+		body   string
+		want   any // nil for a JSON response
+	}{
+		{
+			name:   "data",
+			accept: "application/x-test",
+			body:   `{"query":"{ ratio hello count }"}`,
+			// Keys in query order
+			want: Object{{"data", Object{{"ratio", 0.1}, {"hello", "world"}, {"count", int64(7)}}}},
+		},
+		{
+			name:   "errors and batches",
+			accept: "text/plain, application/x-test;q=0.9",
+			body:   `[{"query":"{ hello }"},{"query":"{ hello("}]`,
+			want: []any{
+				Object{{"data", Object{{"hello", "world"}}}},
+				Object{{"data", nil}, {"errors", []any{Object{
+					{"message", "Expected ), found <EOF>"},
+					{"locations", []any{Object{{"line", int64(1)}, {"column", int64(9)}}}},
+					{"extensions", Object{{"code", "GRAPHQL_PARSE_FAILED"}}},
+				}}}},
+			},
+		},
+		{name: "JSON preferred", accept: "application/json, application/x-test", body: `{"query":"{ hello }"}`},
+		{name: "no accept", body: `{"query":"{ hello }"}`},
+	} {
+		got = nil
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.body))
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s: Vary %q", tc.name, w.Header().Get("Vary"))
+		}
+		if tc.want == nil {
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" || !json.Valid(w.Body.Bytes()) {
+				t.Errorf("%s: got %s %s, want JSON", tc.name, ct, w.Body.String())
+			}
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-test" || w.Body.String() != "encoded" {
+			t.Errorf("%s: got %s %q", tc.name, ct, w.Body.String())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: encoded %#v, want %#v", tc.name, got, tc.want)
+		}
+	}
+}
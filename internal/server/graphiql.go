@@ -1,6 +1,85 @@
 package server
 
-import _ "embed"
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
 
 //go:embed graphiql.html
-var graphiqlPage []byte
+var graphiqlSource string
+
+var graphiqlTemplate = template.Must(template.New("graphiql").Parse(graphiqlSource))
+
+// GraphiQLOptions configures the embedded GraphiQL page. Every setting can be
+// overridden when loading the page with the query parameters endpoint,
+// subscriptionUrl, headers (a JSON object) and theme (dark or light).
+type GraphiQLOptions struct {
+	// Endpoint is the path queries are sent to. Empty means the page's own path.
+	Endpoint string
+
+	// SubscriptionURL is the ws:// or wss:// URL used for subscriptions.
+	// Empty disables subscriptions in the IDE.
+	SubscriptionURL string
+
+	// Headers prefill the headers editor, e.g. an Authorization header for dev.
+	Headers map[string]string
+
+	// DarkMode forces the dark theme. Otherwise the IDE follows the browser.
+	DarkMode bool
+}
+
+// graphiqlConfig is the configuration handed to the page script.
+type graphiqlConfig struct {
+	Endpoint        string `json:"endpoint"`
+	SubscriptionURL string `json:"subscriptionUrl,omitempty"`
+	Headers         string `json:"headers,omitempty"`
+	Theme           string `json:"theme,omitempty"`
+}
+
+// graphiqlPageConfig resolves the page configuration for r from the server
+// options and the request's query parameters. Invalid parameters are ignored.
+func graphiqlPageConfig(r *http.Request, opt GraphiQLOptions) graphiqlConfig {
+	cfg := graphiqlConfig{Endpoint: opt.Endpoint, SubscriptionURL: opt.SubscriptionURL}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = r.URL.Path
+	}
+	if len(opt.Headers) > 0 {
+		b, _ := json.MarshalIndent(opt.Headers, "", "  ")
+		cfg.Headers = string(b)
+	}
+	if opt.DarkMode {
+		cfg.Theme = "dark"
+	}
+
+	q := r.URL.Query()
+	// Only same-origin paths, so a shared link cannot send the default headers elsewhere
+	if v := q.Get("endpoint"); strings.HasPrefix(v, "/") && !strings.HasPrefix(v, "//") {
+		cfg.Endpoint = v
+	}
+	if v := q.Get("subscriptionUrl"); v != "" {
+		if u, err := url.Parse(v); err == nil && (u.Scheme == "ws" || u.Scheme == "wss") {
+			cfg.SubscriptionURL = v
+		}
+	}
+	if v := q.Get("headers"); v != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(v), &headers); err == nil {
+			b, _ := json.MarshalIndent(headers, "", "  ")
+			cfg.Headers = string(b)
+		}
+	}
+	switch v := q.Get("theme"); v {
+	case "dark", "light":
+		cfg.Theme = v
+	}
+	return cfg
+}
+
+func serveGraphiQL(w http.ResponseWriter, r *http.Request, opt GraphiQLOptions) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = graphiqlTemplate.Execute(w, graphiqlPageConfig(r, opt))
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// LoadSheddingOptions bounds the GraphQL requests executing at once.
+type LoadSheddingOptions struct {
+	// MaxInFlight is the number of requests executing concurrently; a batch
+	// counts as one request. 0 disables the limit.
+	MaxInFlight int
+	// MaxQueue is the number of requests waiting for a slot. Requests arriving
+	// when the queue is full are shed at once.
+	MaxQueue int
+	// QueueTimeout sheds requests that waited this long without a slot
+	// (default: 1s).
+	QueueTimeout time.Duration
+	// RetryAfter is sent in the Retry-After header of shed requests, rounded up
+	// to seconds (default: 1s).
+	RetryAfter time.Duration
+}
+
+// limiter admits requests while fewer than MaxInFlight are executing, queueing
+// up to MaxQueue more.
+type limiter struct {
+	opts    LoadSheddingOptions
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+func newLimiter(opts LoadSheddingOptions) *limiter {
+	if opts.QueueTimeout <= 0 {
+		opts.QueueTimeout = time.Second
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Second
+	}
+	return &limiter{opts: opts, slots: make(chan struct{}, opts.MaxInFlight)}
+}
+
+// acquire waits for a slot, reporting false when the request is shed. release
+// must be called once the request is done.
+func (l *limiter) acquire(ctx context.Context) (release func(), ok bool) {
+	release = func() { <-l.slots }
+	select {
+	case l.slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	if l.waiting.Add(1) > int64(l.opts.MaxQueue) {
+		l.waiting.Add(-1)
+		return nil, false
+	}
+	defer l.waiting.Add(-1)
+	timer := time.NewTimer(l.opts.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return release, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return nil, false
+}
+
+// retryAfter is the Retry-After header value in whole seconds.
+func (l *limiter) retryAfter() string {
+	return strconv.Itoa(int((l.opts.RetryAfter + time.Second - 1) / time.Second))
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	errcode "github.com/hanpama/protograph/internal/errcode"
+	executor "github.com/hanpama/protograph/internal/executor"
+	"github.com/hanpama/protograph/protographctx"
+)
+
+// OperationSettings pins the handling of one known operation. Zero fields keep
+// the server defaults.
+type OperationSettings struct {
+	// Name is the operation name. An entry with a Hash and a Name matches only
+	// that operation of the document.
+	Name string
+	// Hash is the hex SHA-256 of the query text, as used by persisted queries.
+	Hash string
+	// Type is the operation type, "query", "mutation" or "subscription", when
+	// known. Only queries may set a CacheTTL.
+	Type string
+	// Timeout replaces Options.Timeout for the operation.
+	Timeout time.Duration
+	// SLA is the latency budget of the operation: executions taking longer
+	// publish an events.SLAExceeded. Responses served from a cache are not
+	// checked.
+	SLA time.Duration
+	// CacheTTL stores successful responses for this long, keyed by the query,
+	// variables and forwarded headers, and answers repeated requests from them.
+	// Only queries are cached: mutations and subscriptions always run.
+	CacheTTL time.Duration
+	// Limits overrides the response limits of Options.Limits: MaxResultNodes,
+	// MaxListItems and MaxResponseBytes (see executor.ContextWithLimits).
+	Limits executor.Limits
+	// Roles admits only requests listing one of them in the header named by
+	// Options.RoleHeader. Empty admits every request.
+	Roles []string
+}
+
+// OperationManifest is the registry of known operations. A request matches the
+// entry for the hash of its query and its operation name, then the entry for
+// the hash alone, then the entry for its operation name; operations matching
+// none run with the server defaults.
+type OperationManifest struct {
+	byHash map[operationKey]*OperationSettings
+	byName map[string]*OperationSettings
+}
+
+type operationKey struct {
+	hash [sha256.Size]byte
+	name string
+}
+
+// NewOperationManifest indexes entries, rejecting entries without a name or
+// hash, malformed hashes, duplicates, limits that cannot be overridden and
+// cache TTLs of operations other than queries.
+func NewOperationManifest(entries ...OperationSettings) (*OperationManifest, error) {
+	m := &OperationManifest{byHash: map[operationKey]*OperationSettings{}, byName: map[string]*OperationSettings{}}
+	for i := range entries {
+		e := &entries[i]
+		if e.Hash == "" && e.Name == "" {
+			return nil, fmt.Errorf("operation %d: name or hash is required", i)
+		}
+		if e.Limits.MaxFragmentSpreads > 0 || e.Limits.MaxAliases > 0 || e.Limits.MaxRootFields > 0 {
+			return nil, fmt.Errorf("operation %s: only result nodes, list items and response bytes can be limited per operation", e.label())
+		}
+		switch e.Type {
+		case "", "query":
+		case "mutation", "subscription":
+			if e.CacheTTL > 0 {
+				return nil, fmt.Errorf("operation %s: cacheTTL is only allowed on queries, not on a %s", e.label(), e.Type)
+			}
+		default:
+			return nil, fmt.Errorf("operation %s: unknown type %q", e.label(), e.Type)
+		}
+		if e.Hash == "" {
+			if _, ok := m.byName[e.Name]; ok {
+				return nil, fmt.Errorf("operation %s: listed twice", e.label())
+			}
+			m.byName[e.Name] = e
+			continue
+		}
+		b, err := hex.DecodeString(e.Hash)
+		if err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("operation %s: hash must be a hex SHA-256", e.label())
+		}
+		key := operationKey{hash: [sha256.Size]byte(b), name: e.Name}
+		if _, ok := m.byHash[key]; ok {
+			return nil, fmt.Errorf("operation %s: listed twice", e.label())
+		}
+		m.byHash[key] = e
+	}
+	return m, nil
+}
+
+// LoadOperationManifest reads a JSON manifest of the form
+//
+//	{"operations": [{"name": "GetUser", "hash": "<sha256>", "type": "query", "timeout": "30s",
+//	  "sla": "200ms", "cacheTTL": "1m", "limits": {"maxResultNodes": 5000}, "roles": ["admin"]}]}
+//
+// Durations use time.ParseDuration syntax.
+func LoadOperationManifest(r io.Reader) (*OperationManifest, error) {
+	var file struct {
+		Operations []struct {
+			Name     string          `json:"name"`
+			Hash     string          `json:"hash"`
+			Type     string          `json:"type"`
+			Timeout  string          `json:"timeout"`
+			SLA      string          `json:"sla"`
+			CacheTTL string          `json:"cacheTTL"`
+			Limits   executor.Limits `json:"limits"`
+			Roles    []string        `json:"roles"`
+		} `json:"operations"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode operation manifest: %w", err)
+	}
+	entries := make([]OperationSettings, len(file.Operations))
+	for i, op := range file.Operations {
+		entries[i] = OperationSettings{Name: op.Name, Hash: strings.ToLower(op.Hash), Type: op.Type, Limits: op.Limits, Roles: op.Roles}
+		var err error
+		if entries[i].Timeout, err = parseManifestDuration(op.Timeout); err != nil {
+			return nil, fmt.Errorf("operation %s: timeout: %w", entries[i].label(), err)
+		}
+		if entries[i].SLA, err = parseManifestDuration(op.SLA); err != nil {
+			return nil, fmt.Errorf("operation %s: sla: %w", entries[i].label(), err)
+		}
+		if entries[i].CacheTTL, err = parseManifestDuration(op.CacheTTL); err != nil {
+			return nil, fmt.Errorf("operation %s: cacheTTL: %w", entries[i].label(), err)
+		}
+	}
+	return NewOperationManifest(entries...)
+}
+
+func parseManifestDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// label names an entry in errors.
+func (s *OperationSettings) label() string {
+	if s.Name != "" {
+		return fmt.Sprintf("%q", s.Name)
+	}
+	return s.Hash
+}
+
+// Lookup returns the settings of the operation named operationName in query.
+func (m *OperationManifest) Lookup(query, operationName string) (*OperationSettings, bool) {
+	if m == nil {
+		return nil, false
+	}
+	if len(m.byHash) > 0 {
+		hash := sha256.Sum256([]byte(query))
+		if s, ok := m.byHash[operationKey{hash: hash, name: operationName}]; ok {
+			return s, true
+		}
+		if s, ok := m.byHash[operationKey{hash: hash}]; ok {
+			return s, true
+		}
+	}
+	if operationName == "" {
+		return nil, false
+	}
+	s, ok := m.byName[operationName]
+	return s, ok
+}
+
+// each calls fn with every entry.
+func (m *OperationManifest) each(fn func(*OperationSettings)) {
+	if m == nil {
+		return
+	}
+	for _, s := range m.byHash {
+		fn(s)
+	}
+	for _, s := range m.byName {
+		fn(s)
+	}
+}
+
+// authorize reports a Forbidden error when the request lists none of the roles
+// the operation requires.
+func (h *Handler) authorize(ctx context.Context, s *OperationSettings) error {
+	if len(s.Roles) == 0 {
+		return nil
+	}
+	header, _ := protographctx.Header(ctx)
+	for _, role := range requestRoles(header, h.opt.RoleHeader) {
+		if slices.Contains(s.Roles, role) {
+			return nil
+		}
+	}
+	return errcode.Errorf(errcode.Forbidden, "operation %s requires one of the roles %s", s.label(), strings.Join(s.Roles, ", "))
+}
+
+// requestRoles returns the comma-separated roles of every value of the header name.
+func requestRoles(header http.Header, name string) []string {
+	var roles []string
+	for _, v := range header.Values(name) {
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// operationCacheKey keys the cached response of req by everything a backend
+// may answer differently for: the query, operation, variables, tenant and the
+// headers forwarded as metadata or naming roles.
+func (h *Handler) operationCacheKey(ctx context.Context, req GraphQLRequest) (string, bool) {
+	vars, err := json.Marshal(req.Variables) // map keys are sorted
+	if err != nil {
+		return "", false
+	}
+	d := sha256.New()
+	d.Write([]byte(req.Query))
+	tenant, _ := protographctx.Tenant(ctx)
+	fmt.Fprintf(d, "\x00%s\x00%s\x00%s", req.OperationName, vars, tenant)
+	header, _ := protographctx.Header(ctx)
+	names := slices.Clone(h.opt.MetadataHeaders)
+	if h.opt.RoleHeader != "" {
+		names = append(names, h.opt.RoleHeader)
+	}
+	for i := range names {
+		names[i] = http.CanonicalHeaderKey(names[i])
+	}
+	slices.Sort(names)
+	for _, name := range slices.Compact(names) {
+		fmt.Fprintf(d, "\x00%s=%q", name, header.Values(name))
+	}
+	return "operation:" + hex.EncodeToString(d.Sum(nil)), true
+}
+
+// defaultOperationCacheSize is the capacity of the in-memory store holding the
+// responses of operations with a CacheTTL when Options.OperationCache is unset.
+const defaultOperationCacheSize = 1000
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestOperationManifest_Lookup(t *testing.T) {
+	const query = "query A { hello } query B { hello }"
+	m, err := LoadOperationManifest(strings.NewReader(`{"operations": [
+		{"name": "A", "timeout": "30s"},
+		{"hash": "` + hashQuery(query) + `", "cacheTTL": "1m"},
+		{"hash": "` + strings.ToUpper(hashQuery(query)) + `", "name": "B", "limits": {"maxResultNodes": 10}}
+	]}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for _, tc := range []struct {
+		query, name string
+		want        string
+	}{
+		{query, "A", "hash"},
+		{query, "B", "hash and name"},
+		{"query A { hello }", "A", "name"},
+		{"{ hello }", "", ""},
+	} {
+		s, ok := m.Lookup(tc.query, tc.name)
+		got := ""
+		switch {
+		case !ok:
+		case s.Limits.MaxResultNodes == 10:
+			got = "hash and name"
+		case s.CacheTTL == time.Minute:
+			got = "hash"
+		case s.Timeout == 30*time.Second:
+			got = "name"
+		}
+		if got != tc.want {
+			t.Errorf("%s %s: matched %q, want %q", tc.query, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOperationManifest_Invalid(t *testing.T) {
+	for manifest, want := range map[string]string{
+		`{"operations": [{"timeout": "1s"}]}`:                                   "name or hash is required",
+		`{"operations": [{"name": "A"}, {"name": "A"}]}`:                        "listed twice",
+		`{"operations": [{"hash": "abc"}]}`:                                     "hex SHA-256",
+		`{"operations": [{"name": "A", "timeout": "soon"}]}`:                    "timeout",
+		`{"operations": [{"name": "A", "sla": "200"}]}`:                         "sla",
+		`{"operations": [{"name": "A", "ttl": "1s"}]}`:                          "unknown field",
+		`{"operations": [{"name": "A", "limits": {"maxAliases": 1}}]}`:          "per operation",
+		`{"operations": [{"name": "A", "type": "mutation", "cacheTTL": "1m"}]}`: "only allowed on queries",
+		`{"operations": [{"name": "A", "type": "update"}]}`:                     "unknown type",
+	} {
+		_, err := LoadOperationManifest(strings.NewReader(manifest))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", manifest, err, want)
+		}
+	}
+}
+
+func TestOperations_Roles(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	m, err := NewOperationManifest(OperationSettings{Name: "Admin", Roles: []string{"admin", "support"}})
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if _, err := New(rt, nil, WithOperations(m)); err == nil {
+		t.Fatalf("roles accepted without a role header")
+	}
+	h := newTestHandler(t, rt, WithOperations(m), WithRoleHeader("X-Roles"))
+
+	for _, tc := range []struct {
+		body, roles string
+		want        string
+	}{
+		{`{"query":"query Admin { hello }"}`, "", `"code":"FORBIDDEN"`},
+		{`{"query":"query Admin { hello }"}`, "viewer, editor", `"code":"FORBIDDEN"`},
+		{`{"query":"query Admin { hello }"}`, "viewer, support", `{"data":{"hello":"world"}}`},
+		{`{"query":"query Other { hello }"}`, "", `{"data":{"hello":"world"}}`},
+	} {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.body))
+		if tc.roles != "" {
+			req.Header.Set("X-Roles", tc.roles)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if !strings.Contains(w.Body.String(), tc.want) {
+			t.Errorf("%s with roles %q: got %s, want %s", tc.body, tc.roles, w.Body.String(), tc.want)
+		}
+	}
+}
+
+func TestOperations_SLA(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			time.Sleep(20 * time.Millisecond)
+			return "world", nil
+		},
+	})
+	m, err := NewOperationManifest(
+		OperationSettings{Name: "Slow", SLA: time.Millisecond},
+		OperationSettings{Name: "Budgeted", SLA: time.Minute},
+	)
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	h := newTestHandler(t, rt, WithOperations(m))
+
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	var got []events.SLAExceeded
+	eventbus.Subscribe(func(_ context.Context, e events.SLAExceeded) { got = append(got, e) })
+
+	for _, body := range []string{
+		`{"query":"query Slow { hello }"}`,
+		`{"query":"query Budgeted { hello }"}`,
+		`{"query":"{ hello }"}`,
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+	}
+	if len(got) != 1 || got[0].OperationName != "Slow" || got[0].Budget != time.Millisecond || got[0].Duration < 20*time.Millisecond || got[0].Field != "" {
+		t.Fatalf("events = %+v, want one for Slow", got)
+	}
+}
+
+func TestOperations_CacheTTL(t *testing.T) {
+	calls := 0
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			calls++
+			return "world", nil
+		},
+	})
+	m, err := NewOperationManifest(OperationSettings{Name: "Cached", CacheTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	h := newTestHandler(t, rt, WithOperations(m), WithMetadataHeaders("Authorization"))
+
+	post := func(body, auth string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if got, want := strings.TrimSpace(w.Body.String()), `{"data":{"hello":"world"}}`; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+	post(`{"query":"query Cached { hello }"}`, "alice")
+	post(`{"query":"query Cached { hello }"}`, "alice")
+	if calls != 1 {
+		t.Fatalf("resolver called %d times, want 1", calls)
+	}
+	// Responses are not shared between callers or variables
+	post(`{"query":"query Cached { hello }"}`, "bob")
+	post(`{"query":"query Cached { hello }","variables":{"v":1}}`, "alice")
+	// nor cached for operations without a TTL
+	post(`{"query":"{ hello }"}`, "alice")
+	post(`{"query":"{ hello }"}`, "alice")
+	if calls != 5 {
+		t.Fatalf("resolver called %d times, want 5", calls)
+	}
+}
+
+func TestOperations_CacheTTL_Mutation(t *testing.T) {
+	calls := 0
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Mutation.bump": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			calls++
+			return calls, nil
+		},
+	})
+	sch, err := schema.BuildFromSDL(`schema { query: Query mutation: Mutation } type Query { hello: String } type Mutation { bump: Int }`)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	// An entry without a type cannot be rejected on load
+	m, err := NewOperationManifest(OperationSettings{Name: "Bump", CacheTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	h, err := New(rt, sch, WithOperations(m))
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"mutation Bump { bump }"}`)))
+		if got, want := strings.TrimSpace(w.Body.String()), fmt.Sprintf(`{"data":{"bump":%d}}`, i); got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestOperations_Timeout(t *testing.T) {
+	var remaining time.Duration
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			deadline, _ := ctx.Deadline()
+			remaining = time.Until(deadline)
+			return "world", nil
+		},
+	})
+	m, err := NewOperationManifest(OperationSettings{Name: "Slow", Timeout: time.Hour})
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	h := newTestHandler(t, rt, WithOperations(m))
+
+	for _, tc := range []struct {
+		body     string
+		min, max time.Duration
+	}{
+		{`{"query":"query Slow { hello }"}`, 59 * time.Minute, time.Hour},
+		{`{"query":"query Fast { hello }"}`, 9 * time.Second, 10 * time.Second},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.body)))
+		if remaining < tc.min || remaining > tc.max {
+			t.Errorf("%s: %v remaining, want between %v and %v", tc.body, remaining, tc.min, tc.max)
+		}
+	}
+}
+
+func TestOperations_Limits(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	m, err := NewOperationManifest(OperationSettings{Name: "Large", Limits: executor.Limits{MaxResponseBytes: 1000}})
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	h := newTestHandler(t, rt, WithOperations(m), WithLimits(executor.Limits{MaxResponseBytes: 5}))
+
+	for body, want := range map[string]string{
+		`{"query":"query Large { hello }"}`: `{"data":{"hello":"world"}}`,
+		`{"query":"query Small { hello }"}`: "execution limit exceeded",
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(body)))
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: got %s, want %s", body, w.Body.String(), want)
+		}
+	}
+}
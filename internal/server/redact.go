@@ -0,0 +1,209 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	language "github.com/hanpama/protograph/internal/language"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+// RedactedValue replaces the values of sensitive variables in events.
+const RedactedValue = "[REDACTED]"
+
+// SensitiveDirective marks arguments and input fields whose values are
+// redacted, once declared in SDL:
+//
+//	directive @sensitive on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+const SensitiveDirective = "sensitive"
+
+// redactor masks the sensitive values of request variables: variables, and
+// fields of input objects, named like one of the patterns, and variables passed
+// to arguments and input fields that are @sensitive or named like a pattern.
+type redactor struct {
+	schema   *schema.Schema
+	patterns []string // lower case
+}
+
+func newRedactor(s *schema.Schema, patterns []string) (*redactor, error) {
+	r := &redactor{schema: s}
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("redacted variable pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return r, nil
+}
+
+func (r *redactor) matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// variables returns a copy of the variables of op with sensitive values
+// replaced by RedactedValue.
+func (r *redactor) variables(doc *language.QueryDocument, op *language.OperationDefinition, vars map[string]any) map[string]any {
+	if len(vars) == 0 {
+		return nil
+	}
+	var sensitive map[string]bool
+	if op != nil {
+		sensitive = r.sensitiveVariables(doc, op)
+	}
+	out := make(map[string]any, len(vars))
+	for name, v := range vars {
+		if sensitive[name] || r.matches(name) {
+			out[name] = RedactedValue
+			continue
+		}
+		var typeName string
+		if op != nil {
+			if def := op.VariableDefinitions.ForName(name); def != nil {
+				typeName = def.Type.Name()
+			}
+		}
+		out[name] = r.value(typeName, v)
+	}
+	return out
+}
+
+// value copies v, a value of the input type named typeName, masking sensitive
+// input fields. Fields unknown to the schema are only matched by name.
+func (r *redactor) value(typeName string, v any) any {
+	switch v := v.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.value(typeName, item)
+		}
+		return out
+	case map[string]any:
+		t := r.schema.Types[typeName]
+		out := make(map[string]any, len(v))
+		for name, fv := range v {
+			var field *schema.InputValue
+			if t != nil {
+				field = t.InputFields[name]
+			}
+			if r.sensitive(name, field) {
+				out[name] = RedactedValue
+				continue
+			}
+			var fieldType string
+			if field != nil {
+				fieldType = field.Type.GetNamedType()
+			}
+			out[name] = r.value(fieldType, fv)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func (r *redactor) sensitive(name string, def *schema.InputValue) bool {
+	return def != nil && def.Metadata.Has(SensitiveDirective) || r.matches(name)
+}
+
+// sensitiveVariables names the variables op passes to sensitive arguments and
+// input fields, in its selections and the fragments they spread.
+func (r *redactor) sensitiveVariables(doc *language.QueryDocument, op *language.OperationDefinition) map[string]bool {
+	out := map[string]bool{}
+	visited := map[string]bool{}
+	var walk func(typeName string, set language.SelectionSet)
+	walk = func(typeName string, set language.SelectionSet) {
+		t := r.schema.Types[typeName]
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *language.Field:
+				var field *schema.Field
+				if t != nil {
+					field = t.Fields[sel.Name]
+				}
+				var fieldType string
+				if field != nil {
+					fieldType = field.Type.GetNamedType()
+				}
+				for _, arg := range sel.Arguments {
+					var def *schema.InputValue
+					if field != nil {
+						def = field.Arguments[arg.Name]
+					}
+					r.markValue(out, arg.Name, def, arg.Value)
+				}
+				walk(fieldType, sel.SelectionSet)
+			case *language.InlineFragment:
+				if sel.TypeCondition != "" {
+					walk(sel.TypeCondition, sel.SelectionSet)
+				} else {
+					walk(typeName, sel.SelectionSet)
+				}
+			case *language.FragmentSpread:
+				if visited[sel.Name] {
+					continue
+				}
+				visited[sel.Name] = true
+				if f := doc.Fragments.ForName(sel.Name); f != nil {
+					walk(f.TypeCondition, f.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(r.rootType(op.Operation), op.SelectionSet)
+	return out
+}
+
+// markValue marks the variables of v, the value given to the argument or input
+// field name defined by def.
+func (r *redactor) markValue(out map[string]bool, name string, def *schema.InputValue, v *language.Value) {
+	if r.sensitive(name, def) {
+		markVariables(out, v)
+		return
+	}
+	switch v.Kind {
+	case language.ListValue:
+		for _, item := range v.Children {
+			r.markValue(out, name, def, item.Value)
+		}
+	case language.ObjectValue:
+		var t *schema.Type
+		if def != nil {
+			t = r.schema.Types[def.Type.GetNamedType()]
+		}
+		for _, f := range v.Children {
+			var fieldDef *schema.InputValue
+			if t != nil {
+				fieldDef = t.InputFields[f.Name]
+			}
+			r.markValue(out, f.Name, fieldDef, f.Value)
+		}
+	}
+}
+
+func markVariables(out map[string]bool, v *language.Value) {
+	if v.Kind == language.Variable {
+		out[v.Raw] = true
+	}
+	for _, c := range v.Children {
+		markVariables(out, c.Value)
+	}
+}
+
+func (r *redactor) rootType(op language.Operation) string {
+	switch op {
+	case language.Mutation:
+		return r.schema.MutationType
+	case language.Subscription:
+		return r.schema.SubscriptionType
+	default:
+		return r.schema.QueryType
+	}
+}
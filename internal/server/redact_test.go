@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	eventbus "github.com/hanpama/protograph/internal/eventbus"
+	events "github.com/hanpama/protograph/internal/events"
+	executor "github.com/hanpama/protograph/internal/executor"
+	schema "github.com/hanpama/protograph/internal/schema"
+)
+
+const redactSDL = `schema { query: Query mutation: Mutation }
+directive @sensitive on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+type Query { hello: String }
+type Mutation {
+  login(user: String!, password: String! @sensitive): String
+  register(input: RegisterInput!): String
+  rotate(apiToken: String!): String
+}
+input RegisterInput { name: String!, recovery: [Recovery!] }
+input Recovery { question: String!, answer: String! @sensitive }
+`
+
+func TestRedactedVariables(t *testing.T) {
+	sch, err := schema.BuildFromSDL(redactSDL)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	h, err := New(executor.NewMockRuntime(nil), sch, WithRedactedVariables("*token*"))
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	eventbus.Use(eventbus.New())
+	t.Cleanup(func() { eventbus.Use(nil) })
+	var got map[string]any
+	eventbus.Subscribe(func(_ context.Context, e events.GraphQLStart) { got = e.Variables })
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want map[string]any
+	}{
+		{
+			name: "sensitive argument",
+			body: `{"query":"mutation($u: String!, $p: String!) { login(user: $u, password: $p) }","variables":{"u":"ann","p":"hunter2"}}`,
+			want: map[string]any{"u": "ann", "p": RedactedValue},
+		},
+		{
+			name: "sensitive input field",
+			body: `{"query":"mutation($in: RegisterInput!) { register(input: $in) }","variables":{"in":{"name":"ann","recovery":[{"question":"pet","answer":"rex"}]}}}`,
+			want: map[string]any{"in": map[string]any{"name": "ann", "recovery": []any{map[string]any{"question": "pet", "answer": RedactedValue}}}},
+		},
+		{
+			name: "variable in a sensitive input field literal",
+			body: `{"query":"mutation M($a: String!) { ...R } fragment R on Mutation { register(input: {name: \"ann\", recovery: [{question: \"pet\", answer: $a}]}) }","variables":{"a":"rex"}}`,
+			want: map[string]any{"a": RedactedValue},
+		},
+		{
+			name: "argument and variable patterns",
+			body: `{"query":"mutation($t: String!, $refreshToken: String) { rotate(apiToken: $t) }","variables":{"t":"abc","refreshToken":"def"}}`,
+			want: map[string]any{"t": RedactedValue, "refreshToken": RedactedValue},
+		},
+	} {
+		got = nil
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.body)))
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: variables %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := New(executor.NewMockRuntime(nil), sch, WithRedactedVariables("[")); err == nil {
+		t.Errorf("malformed pattern accepted")
+	}
+}
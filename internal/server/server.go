@@ -1,36 +1,52 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	cache "github.com/hanpama/protograph/internal/cache"
+	errcode "github.com/hanpama/protograph/internal/errcode"
 	eventbus "github.com/hanpama/protograph/internal/eventbus"
 	events "github.com/hanpama/protograph/internal/events"
 	executor "github.com/hanpama/protograph/internal/executor"
 	language "github.com/hanpama/protograph/internal/language"
 	reqid "github.com/hanpama/protograph/internal/reqid"
+	respheader "github.com/hanpama/protograph/internal/respheader"
 	schema "github.com/hanpama/protograph/internal/schema"
+	"github.com/hanpama/protograph/protographctx"
 	"google.golang.org/grpc/metadata"
 )
 
 // Handler is an http.Handler that serves a GraphQL endpoint.
 // It parses requests, runs the executor, and formats responses per GraphQL spec.
 type Handler struct {
-	exec *executor.Executor
-	opt  Options
+	exec     *executor.Executor
+	opt      Options
+	queries  *queryCache
+	limiter  *limiter
+	redactor *redactor
+
+	// inFlight counts the operations executing
+	inFlight atomic.Int64
 }
 
 type Options struct {
-	// Timeout sets a default timeout if the incoming request context has none.
-	// 0 means no default timeout.
+	// Timeout sets a default timeout for each operation if the incoming request
+	// context has none. 0 means no default timeout.
 	Timeout time.Duration
 
-	// Pretty enables indented JSON responses (useful for dev).
+	// Pretty enables indented JSON responses (useful for dev). Pretty responses
+	// are encoded in memory; compact ones are streamed to the client.
 	Pretty bool
 
 	// MaxBodyBytes limits the size of the request body. 0 means unlimited.
@@ -43,10 +59,97 @@ type Options struct {
 	// Header names are case-insensitive. Default is none.
 	MetadataHeaders []string
 
+	// ResponseHeaders lists backend response metadata keys, from headers and
+	// trailers, to copy into HTTP response headers. Values returned by several
+	// calls of a request are sent once each. Default is none.
+	ResponseHeaders []string
+
 	// GraphiQL enables the in-browser IDE when true.
 	GraphiQL bool
+
+	// GraphiQLConfig configures the IDE page served when GraphiQL is enabled.
+	GraphiQLConfig GraphiQLOptions
+
+	// Explain allows clients to request the execution plan instead of data, with
+	// the ExplainHeader header or an "explain": true request extension.
+	Explain bool
+
+	// LeafObjects enables the executor's leaf-object fast path, serializing
+	// objects that select only scalar fields in one runtime call when the runtime
+	// supports it.
+	LeafObjects bool
+
+	// CompletionWorkers completes large async batches on up to this many
+	// goroutines (see executor.WithParallelCompletion). 0 or 1 completes them
+	// sequentially.
+	CompletionWorkers int
+
+	// BatchTimeout bounds each depth's batch of async fields; fields of a slower
+	// batch become errors while earlier data is returned. 0 disables it.
+	BatchTimeout time.Duration
+
+	// Limits bounds the response built for each operation; see executor.Limits.
+	Limits executor.Limits
+
+	// QueryCacheSize is the number of parsed and prepared operations kept in an
+	// LRU keyed by query hash and operation name. 0 disables the cache.
+	QueryCacheSize int
+
+	// LoadShedding bounds concurrent executions, answering requests beyond them
+	// and the queue with 503 and Retry-After instead of fanning out more RPCs.
+	LoadShedding LoadSheddingOptions
+
+	// ResponseCache answers operations it has a key for with the response stored
+	// the first time they succeeded, without executing them. nil disables it.
+	ResponseCache ResponseCache
+
+	// Operations pins the timeout, response cache TTL, limits and allowed roles
+	// of known operations. nil runs every operation with the defaults.
+	Operations *OperationManifest
+
+	// OperationCache stores the responses of operations with a CacheTTL. When
+	// nil, an in-memory LRU of 1000 responses is used.
+	OperationCache cache.Store
+
+	// TenantHeader names the HTTP header carrying the tenant of a request, which
+	// routes its backend calls (see grpctp.TenantEndpoints). A tenant attached
+	// by middleware with protographctx.WithTenant takes precedence.
+	TenantHeader string
+
+	// RoleHeader names the HTTP header listing the caller's roles, separated by
+	// commas, that the Roles of Operations entries are checked against.
+	RoleHeader string
+
+	// RedactVariables lists case-insensitive path.Match patterns, such as
+	// "*password*", naming the variables and input object fields whose values
+	// the Variables of GraphQL events replace with RedactedValue. Values given
+	// to arguments and input fields named like a pattern, or annotated with
+	// @sensitive (see SensitiveDirective), are replaced as well.
+	RedactVariables []string
+
+	// UseJSONNumber decodes the numbers of variables as json.Number rather than
+	// float64, so integers beyond 2^53, such as 64-bit IDs, reach Int, ID and
+	// custom scalar arguments, and the backends, with every digit.
+	UseJSONNumber bool
+
+	// ResponseEncoders write responses in formats other than JSON, keyed by
+	// media type, for clients listing one in their Accept header before any
+	// JSON type.
+	ResponseEncoders map[string]ResponseEncoder
+}
+
+// ResponseCache serves the responses of operations depending on nothing but the
+// schema, such as introspection queries (see introspection.ResponseCache).
+type ResponseCache interface {
+	// Key returns the cache key of an operation, or false when it is not cacheable.
+	Key(doc *language.QueryDocument, query, operationName string, variables map[string]any) (string, bool)
+	Get(key string) (json.RawMessage, bool)
+	Set(key string, response json.RawMessage)
 }
 
+// ExplainHeader requests the execution plan when Options.Explain is set.
+const ExplainHeader = "X-Protograph-Explain"
+
 type Option func(*Options)
 
 func WithTimeout(d time.Duration) Option { return func(o *Options) { o.Timeout = d } }
@@ -58,6 +161,9 @@ func WithCORS(origins ...string) Option {
 func WithMetadataHeaders(headers ...string) Option {
 	return func(o *Options) { o.MetadataHeaders = headers }
 }
+func WithResponseHeaders(keys ...string) Option {
+	return func(o *Options) { o.ResponseHeaders = keys }
+}
 
 // CORSOptions holds simple CORS settings.
 type CORSOptions struct {
@@ -65,26 +171,104 @@ type CORSOptions struct {
 }
 
 func WithGraphiQL(enable bool) Option { return func(o *Options) { o.GraphiQL = enable } }
+func WithGraphiQLConfig(cfg GraphiQLOptions) Option {
+	return func(o *Options) { o.GraphiQLConfig = cfg }
+}
+func WithExplain(enable bool) Option { return func(o *Options) { o.Explain = enable } }
+func WithLeafObjects(enable bool) Option {
+	return func(o *Options) { o.LeafObjects = enable }
+}
+func WithQueryCache(size int) Option          { return func(o *Options) { o.QueryCacheSize = size } }
+func WithLimits(l executor.Limits) Option     { return func(o *Options) { o.Limits = l } }
+func WithBatchTimeout(d time.Duration) Option { return func(o *Options) { o.BatchTimeout = d } }
+func WithCompletionWorkers(n int) Option {
+	return func(o *Options) { o.CompletionWorkers = n }
+}
+func WithLoadShedding(l LoadSheddingOptions) Option {
+	return func(o *Options) { o.LoadShedding = l }
+}
+func WithResponseCache(c ResponseCache) Option {
+	return func(o *Options) { o.ResponseCache = c }
+}
+func WithOperations(m *OperationManifest) Option {
+	return func(o *Options) { o.Operations = m }
+}
+func WithOperationCache(s cache.Store) Option {
+	return func(o *Options) { o.OperationCache = s }
+}
+func WithRoleHeader(name string) Option   { return func(o *Options) { o.RoleHeader = name } }
+func WithTenantHeader(name string) Option { return func(o *Options) { o.TenantHeader = name } }
+func WithRedactedVariables(patterns ...string) Option {
+	return func(o *Options) { o.RedactVariables = patterns }
+}
+func WithJSONNumber(enable bool) Option { return func(o *Options) { o.UseJSONNumber = enable } }
+func WithResponseEncoder(contentType string, enc ResponseEncoder) Option {
+	return func(o *Options) {
+		if o.ResponseEncoders == nil {
+			o.ResponseEncoders = map[string]ResponseEncoder{}
+		}
+		o.ResponseEncoders[strings.ToLower(contentType)] = enc
+	}
+}
 
 // New creates a new GraphQL HTTP handler using the given runtime and schema.
 func New(runtime executor.Runtime, schema *schema.Schema, opts ...Option) (*Handler, error) {
-	exec := executor.NewExecutor(runtime, schema)
 	op := Options{Timeout: 10 * time.Second, GraphiQL: true}
 	for _, f := range opts {
 		f(&op)
 	}
-	return &Handler{exec: exec, opt: op}, nil
+	var needsRoles bool
+	op.Operations.each(func(s *OperationSettings) { needsRoles = needsRoles || len(s.Roles) > 0 })
+	if needsRoles && op.RoleHeader == "" {
+		return nil, fmt.Errorf("operation manifest restricts roles but no role header is configured")
+	}
+	if op.Operations != nil && op.OperationCache == nil {
+		op.OperationCache = cache.NewMemory(defaultOperationCacheSize)
+	}
+	// Responses list data keys in query order
+	execOpts := []executor.Option{executor.WithKeyOrder()}
+	if op.LeafObjects {
+		execOpts = append(execOpts, executor.WithLeafObjects())
+	}
+	if op.Limits != (executor.Limits{}) {
+		execOpts = append(execOpts, executor.WithLimits(op.Limits))
+	}
+	if op.BatchTimeout > 0 {
+		execOpts = append(execOpts, executor.WithBatchTimeout(op.BatchTimeout))
+	}
+	if op.CompletionWorkers > 1 {
+		execOpts = append(execOpts, executor.WithParallelCompletion(op.CompletionWorkers))
+	}
+	// Execution events are published on the event bus like the HTTP ones
+	execOpts = append(execOpts, executor.WithObserver(executor.ObserverFunc(publishExecutorEvent)))
+	redactor, err := newRedactor(schema, op.RedactVariables)
+	if err != nil {
+		return nil, err
+	}
+	exec := executor.NewExecutor(runtime, schema, execOpts...)
+	h := &Handler{exec: exec, opt: op, redactor: redactor}
+	if op.QueryCacheSize > 0 {
+		h.queries = newQueryCache(op.QueryCacheSize)
+	}
+	if op.LoadShedding.MaxInFlight > 0 {
+		h.limiter = newLimiter(op.LoadShedding)
+	}
+	return h, nil
 }
 
+// InFlight returns the number of operations executing, across requests.
+func (h *Handler) InFlight() int64 { return h.inFlight.Load() }
+
+// publishExecutorEvent publishes e under its concrete type, e.g. executor.BatchFinish.
+func publishExecutorEvent(ctx context.Context, e executor.Event) { eventbus.Publish(ctx, e) }
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if _, ok := ctx.Deadline(); !ok && h.opt.Timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, h.opt.Timeout)
-		defer cancel()
-	}
-
 	ctx, rid := reqid.NewContext(ctx)
+	ctx = protographctx.WithHTTPRequest(ctx, r)
+	if _, ok := protographctx.Tenant(ctx); !ok && h.opt.TenantHeader != "" {
+		ctx = protographctx.WithTenant(ctx, r.Header.Get(h.opt.TenantHeader))
+	}
 	status := http.StatusOK
 	start := time.Now()
 	eventbus.Publish(ctx, events.HTTPStart{Request: r})
@@ -103,14 +287,13 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodPost && r.Method != http.MethodGet {
 		status = http.StatusMethodNotAllowed
-		writeJSON(w, status, errorResponse(nil, &language.Error{Message: "method not allowed"}), h.opt.Pretty)
+		h.write(w, r, status, errorResponse(nil, errcode.BadUserInput, &language.Error{Message: "method not allowed"}))
 		return
 	}
 
 	// Serve GraphiQL IDE when enabled and the client expects HTML.
 	if r.Method == http.MethodGet && h.opt.GraphiQL && acceptsHTML(r.Header.Get("Accept")) && r.URL.Query().Get("query") == "" {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = w.Write(graphiqlPage)
+		serveGraphiQL(w, r, h.opt.GraphiQLConfig)
 		return
 	}
 
@@ -129,14 +312,18 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	md["graphql-request-id"] = []string{strconv.FormatInt(rid, 10)}
 	ctx = metadata.NewOutgoingContext(ctx, md)
+	var collector *respheader.Collector
+	if len(h.opt.ResponseHeaders) > 0 {
+		ctx, collector = respheader.NewContext(ctx, h.opt.ResponseHeaders)
+	}
 
-	req, batch, berr := parseRequest(r, h.opt.MaxBodyBytes)
+	req, batch, berr := parseRequest(r, h.opt.MaxBodyBytes, h.opt.UseJSONNumber)
 	if berr != nil {
 		status = http.StatusBadRequest
 		if berr.Message == errBodyTooLargeMessage {
 			status = http.StatusRequestEntityTooLarge
 		}
-		writeJSON(w, status, errorResponse(nil, berr), h.opt.Pretty)
+		h.write(w, r, status, errorResponse(nil, errcode.BadUserInput, berr))
 		return
 	}
 
@@ -144,58 +331,203 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		setCORSHeaders(w, r, h.opt.CORS)
 	}
 
+	if h.limiter != nil {
+		release, ok := h.limiter.acquire(ctx)
+		if !ok {
+			status = http.StatusServiceUnavailable
+			w.Header().Set("Retry-After", h.limiter.retryAfter())
+			h.write(w, r, status, errorResponse(nil, errcode.Overloaded, &language.Error{Message: "server overloaded, retry later"}))
+			return
+		}
+		defer release()
+	}
+
+	explainHeader := h.opt.Explain && isTruthy(r.Header.Get(ExplainHeader))
+
 	if batch != nil {
 		// Batched requests
 		op := make([]any, len(batch))
 		for i := range batch {
-			res := h.executeOne(ctx, batch[i])
+			res, release := h.executeOne(ctx, batch[i], explainHeader)
+			defer release()
 			op[i] = res
 		}
-		writeJSON(w, status, op, h.opt.Pretty)
+		if collector != nil {
+			collector.WriteTo(w.Header())
+		}
+		h.write(w, r, status, op)
 		return
 	}
 
-	res := h.executeOne(ctx, req)
-	writeJSON(w, status, res, h.opt.Pretty)
+	res, release := h.executeOne(ctx, req, explainHeader)
+	defer release()
+	if collector != nil {
+		collector.WriteTo(w.Header())
+	}
+	h.write(w, r, status, res)
 }
 
-func (h *Handler) executeOne(ctx context.Context, req GraphQLRequest) any {
-	// Parse query (syntax validation)
-	doc, err := language.ParseQuery(req.Query)
+// executeOne runs a single request. release returns the response objects to the
+// executor's pool and must be called after the result is written.
+func (h *Handler) executeOne(ctx context.Context, req GraphQLRequest, explain bool) (any, func()) {
+	h.inFlight.Add(1)
+	defer h.inFlight.Add(-1)
+	release := func() {}
+	// Parse query (syntax validation), or take it from the query cache
+	q, err := h.lookupQuery(req)
 	if err != nil {
-		if ge, ok := err.(*language.Error); ok {
-			return errorResponse(nil, ge)
+		if errs, ok := err.(language.ErrorList); ok {
+			return parseErrorResponse(errs), release
 		}
-		return errorResponse(nil, &language.Error{Message: err.Error()})
+		return errorResponse(nil, errcode.Of(err), &language.Error{Message: err.Error()}), release
 	}
 
+	doc := q.doc
 	opDef := doc.Operations.ForName(req.OperationName)
 	if opDef == nil && len(doc.Operations) == 1 {
 		opDef = doc.Operations[0]
 	}
 	opType := ""
+	opName := req.OperationName
 	if opDef != nil {
 		opType = string(opDef.Operation)
+		opName = opDef.Name
+	}
+	ctx = protographctx.WithOperation(ctx, protographctx.Operation{Name: opName, Type: opType, Query: req.Query})
+	settings, _ := h.opt.Operations.Lookup(req.Query, opName)
+	if settings == nil {
+		settings = &OperationSettings{}
+	}
+	if err := h.authorize(ctx, settings); err != nil {
+		return errorResponse(nil, errcode.Of(err), &language.Error{Message: err.Error()}), release
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := h.opt.Timeout
+		if settings.Timeout > 0 {
+			timeout = settings.Timeout
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	if settings.Limits != (executor.Limits{}) {
+		ctx = executor.ContextWithLimits(ctx, settings.Limits)
+	}
+
+	if explain || (h.opt.Explain && req.Extensions["explain"] == true) {
+		plan, err := h.exec.Explain(ctx, doc, req.OperationName, req.Variables)
+		if err != nil {
+			return errorResponse(nil, errcode.Of(err), &language.Error{Message: err.Error()}), release
+		}
+		return explainResult{Extensions: map[string]any{"explain": plan}}, release
 	}
 
 	start := time.Now()
-	eventbus.Publish(ctx, events.GraphQLStart{Query: req.Query, OperationName: req.OperationName, OperationType: opType})
-	result := h.exec.ExecuteRequest(ctx, doc, req.OperationName, req.Variables, nil)
+	variables := h.redactor.variables(doc, opDef, req.Variables)
+	eventbus.Publish(ctx, events.GraphQLStart{Query: req.Query, OperationName: req.OperationName, OperationType: opType, Variables: variables})
+	var cacheKey string
+	cacheable := false
+	if h.opt.ResponseCache != nil {
+		cacheKey, cacheable = h.opt.ResponseCache.Key(doc, req.Query, req.OperationName, req.Variables)
+	}
+	var operationKey string
+	// Mutations always reach the backends, whatever their manifest entry
+	if !cacheable && settings.CacheTTL > 0 && opType == "query" {
+		operationKey, _ = h.operationCacheKey(ctx, req)
+	}
+	if cacheable || operationKey != "" {
+		var cached json.RawMessage
+		var ok bool
+		if cacheable {
+			cached, ok = h.opt.ResponseCache.Get(cacheKey)
+		} else {
+			cached, ok = h.opt.OperationCache.Get(ctx, operationKey)
+		}
+		if ok {
+			eventbus.Publish(ctx, events.GraphQLFinish{
+				Query:         req.Query,
+				OperationName: req.OperationName,
+				OperationType: opType,
+				Variables:     variables,
+				RootFields:    rootFields(doc, opDef),
+				Duration:      time.Since(start),
+			})
+			return cached, release
+		}
+	}
+	var result *executor.ExecutionResult
+	if q.op != nil {
+		result, release = h.exec.ExecutePreparedPooled(ctx, q.op, req.Variables, nil)
+	} else {
+		result, release = h.exec.ExecuteRequestPooled(ctx, doc, req.OperationName, req.Variables, nil)
+	}
 	errs := make([]error, len(result.Errors))
 	for i := range result.Errors {
 		errs[i] = result.Errors[i]
 	}
+	duration := time.Since(start)
+	if settings.SLA > 0 && duration > settings.SLA {
+		eventbus.Publish(ctx, events.SLAExceeded{OperationName: opName, Budget: settings.SLA, Duration: duration})
+	}
 	eventbus.Publish(ctx, events.GraphQLFinish{
 		Query:         req.Query,
 		OperationName: req.OperationName,
 		OperationType: opType,
+		Variables:     variables,
+		RootFields:    rootFields(doc, opDef),
 		Errors:        errs,
-		Duration:      time.Since(start),
+		Duration:      duration,
 	})
 	if len(result.Errors) > 0 {
-		return toSpecResult(result)
+		return toSpecResult(result), release
 	}
-	return result
+	if cacheable || operationKey != "" {
+		// Encoded now, as the pooled result is released once written
+		if encoded, err := marshalJSON(result); err == nil {
+			if cacheable {
+				h.opt.ResponseCache.Set(cacheKey, encoded)
+			} else {
+				h.opt.OperationCache.Set(ctx, operationKey, encoded, settings.CacheTTL)
+			}
+			return json.RawMessage(encoded), release
+		}
+	}
+	return result, release
+}
+
+// rootFields names the top-level fields op selects, through the fragments it
+// spreads, once each.
+func rootFields(doc *language.QueryDocument, op *language.OperationDefinition) []string {
+	if op == nil {
+		return nil
+	}
+	var out []string
+	visited := map[string]bool{}
+	var walk func(set language.SelectionSet)
+	walk = func(set language.SelectionSet) {
+		for _, sel := range set {
+			switch sel := sel.(type) {
+			case *language.Field:
+				if !slices.Contains(out, sel.Name) {
+					out = append(out, sel.Name)
+				}
+			case *language.InlineFragment:
+				walk(sel.SelectionSet)
+			case *language.FragmentSpread:
+				if visited[sel.Name] {
+					continue
+				}
+				visited[sel.Name] = true
+				if f := doc.Fragments.ForName(sel.Name); f != nil {
+					walk(f.SelectionSet)
+				}
+			}
+		}
+	}
+	walk(op.SelectionSet)
+	return out
 }
 
 // ------------------ Request parsing ------------------
@@ -207,7 +539,7 @@ type GraphQLRequest struct {
 	Extensions    map[string]any `json:"extensions,omitempty"`
 }
 
-func parseRequest(r *http.Request, maxBody int64) (GraphQLRequest, []GraphQLRequest, *language.Error) {
+func parseRequest(r *http.Request, maxBody int64, useNumber bool) (GraphQLRequest, []GraphQLRequest, *language.Error) {
 	if r.Method == http.MethodGet {
 		q := r.URL.Query().Get("query")
 		if q == "" {
@@ -215,7 +547,7 @@ func parseRequest(r *http.Request, maxBody int64) (GraphQLRequest, []GraphQLRequ
 		}
 		vars := map[string]any{}
 		if v := r.URL.Query().Get("variables"); v != "" {
-			if err := json.Unmarshal([]byte(v), &vars); err != nil {
+			if err := unmarshalJSON([]byte(v), &vars, useNumber); err != nil {
 				return GraphQLRequest{}, nil, &language.Error{Message: "invalid 'variables' JSON"}
 			}
 		}
@@ -242,7 +574,7 @@ func parseRequest(r *http.Request, maxBody int64) (GraphQLRequest, []GraphQLRequ
 		// Try array (batch)
 		var arr []GraphQLRequest
 		if len(body) > 0 && body[0] == '[' {
-			if err := json.Unmarshal(body, &arr); err != nil {
+			if err := unmarshalJSON(body, &arr, useNumber); err != nil {
 				return GraphQLRequest{}, nil, &language.Error{Message: "invalid JSON"}
 			}
 			if len(arr) == 0 {
@@ -252,7 +584,7 @@ func parseRequest(r *http.Request, maxBody int64) (GraphQLRequest, []GraphQLRequ
 		}
 		// Single
 		var req GraphQLRequest
-		if err := json.Unmarshal(body, &req); err != nil {
+		if err := unmarshalJSON(body, &req, useNumber); err != nil {
 			return GraphQLRequest{}, nil, &language.Error{Message: "invalid JSON"}
 		}
 		if req.Query == "" {
@@ -267,6 +599,23 @@ func parseRequest(r *http.Request, maxBody int64) (GraphQLRequest, []GraphQLRequ
 	return GraphQLRequest{}, nil, &language.Error{Message: "unsupported Content-Type"}
 }
 
+// unmarshalJSON is json.Unmarshal, decoding numbers as json.Number when
+// useNumber is set.
+func unmarshalJSON(data []byte, v any, useNumber bool) error {
+	if !useNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
 // ------------------ Response formatting ------------------
 
 type specLocation struct {
@@ -282,17 +631,40 @@ type specError struct {
 }
 
 type specResult struct {
-	Data   any         `json:"data"`
-	Errors []specError `json:"errors,omitempty"`
+	Data       any            `json:"data"`
+	Errors     []specError    `json:"errors,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+	// keyOrder is the key order of Data
+	keyOrder *executor.KeyOrder
+}
+
+// explainResult carries an execution plan in place of data.
+type explainResult struct {
+	Data       any            `json:"data"`
+	Extensions map[string]any `json:"extensions"`
 }
 
-func errorResponse(data any, err *language.Error) specResult {
-	se := specError{Message: err.Message}
+// errorResponse reports a request-level error with code in extensions.code.
+func errorResponse(data any, code errcode.Code, err *language.Error) specResult {
+	se := specError{Message: err.Message, Extensions: errcode.Extensions(code)}
 	return specResult{Data: data, Errors: []specError{se}}
 }
 
+// parseErrorResponse reports every syntax error of a query at its location.
+func parseErrorResponse(errs language.ErrorList) specResult {
+	out := specResult{Errors: make([]specError, len(errs))}
+	for i, e := range errs {
+		se := specError{Message: e.Message, Extensions: errcode.Extensions(errcode.ParseFailed)}
+		for _, l := range e.Locations {
+			se.Locations = append(se.Locations, specLocation{Line: l.Line, Column: l.Column})
+		}
+		out.Errors[i] = se
+	}
+	return out
+}
+
 func toSpecResult(res *executor.ExecutionResult) specResult {
-	out := specResult{Data: res.Data}
+	out := specResult{Data: res.Data, Extensions: res.Extensions, keyOrder: res.KeyOrder}
 	if len(res.Errors) == 0 {
 		return out
 	}
@@ -322,11 +694,19 @@ func toSpecResult(res *executor.ExecutionResult) specResult {
 func writeJSON(w http.ResponseWriter, status int, v any, pretty bool) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	if pretty {
-		enc.SetIndent("", "  ")
+	if !pretty {
+		_ = streamJSON(w, v)
+		return
 	}
-	_ = enc.Encode(v)
+	var compact bytes.Buffer
+	if err := streamJSON(&compact, v); err != nil {
+		return
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		return
+	}
+	_, _ = indented.WriteTo(w)
 }
 
 func startsWith(s, prefix string) bool { return len(s) >= len(prefix) && s[:len(prefix)] == prefix }
@@ -372,6 +752,11 @@ func contains(list []string, s string) bool {
 	return false
 }
 
+func isTruthy(v string) bool {
+	b, err := strconv.ParseBool(v)
+	return err == nil && b
+}
+
 func acceptsHTML(accept string) bool {
 	if accept == "" {
 		return false
@@ -3,14 +3,21 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	errcode "github.com/hanpama/protograph/internal/errcode"
 	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
 	reqid "github.com/hanpama/protograph/internal/reqid"
+	respheader "github.com/hanpama/protograph/internal/respheader"
 	schema "github.com/hanpama/protograph/internal/schema"
+	"github.com/hanpama/protograph/protographctx"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -122,6 +129,59 @@ func TestMaxBodyBytes(t *testing.T) {
 	}
 }
 
+func TestErrorCodes(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockErrorResolver(errcode.Errorf(errcode.Forbidden, "denied")),
+	})
+	h := newTestHandler(t, rt)
+	for _, tc := range []struct {
+		body string
+		want errcode.Code
+	}{
+		{`{"query":"{ hello"}`, errcode.ParseFailed},
+		{`{"query":"{ missing }"}`, errcode.ValidationFailed},
+		{`{"query":"query A { hello }","operationName":"B"}`, errcode.ValidationFailed},
+		{`{"query":"query($n: Int!) { hello }"}`, errcode.BadUserInput},
+		{`{"query":`, errcode.BadUserInput},
+		{`{"query":"{ hello }"}`, errcode.Forbidden},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.body)))
+		want := `"extensions":{"code":"` + string(tc.want) + `"}`
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%s: got %s, want %s", tc.body, w.Body.String(), want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	h := newTestHandler(t, executor.NewMockRuntime(nil))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello(x: ) hello(: 1) }"}`)))
+	want := `{"data":null,"errors":[` +
+		`{"message":"Unexpected )","locations":[{"line":1,"column":12}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}},` +
+		`{"message":"Unexpected :","locations":[{"line":1,"column":20}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestResponseExtensions(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			executor.ExtensionsFromContext(ctx).Update("cache", func(any) any { return map[string]any{"Query.hello": "hit"} })
+			return "world", nil
+		},
+	})
+	h := newTestHandler(t, rt)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`)))
+	want := `{"data":{"hello":"world"},"extensions":{"cache":{"Query.hello":"hit"}}}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
 func TestRequestID(t *testing.T) {
 	rt := executor.NewMockRuntime(nil)
 	var capturedMD metadata.MD
@@ -147,3 +207,310 @@ func TestRequestID(t *testing.T) {
 		t.Fatalf("metadata mismatch: %v id %d", capturedMD, capturedID)
 	}
 }
+
+func TestRequestContextHelpers(t *testing.T) {
+	rt := executor.NewMockRuntime(nil)
+	var gotID int64
+	var gotHeader http.Header
+	var gotPrincipal any
+	var gotOp protographctx.Operation
+	rt.SetResolver("Query", "hello", func(ctx context.Context, src any, args map[string]any) (any, error) {
+		gotID, _ = protographctx.RequestID(ctx)
+		gotHeader, _ = protographctx.Header(ctx)
+		gotPrincipal, _ = protographctx.Principal(ctx)
+		gotOp, _ = protographctx.OperationFromContext(ctx)
+		return "world", nil
+	})
+	h := newTestHandler(t, rt)
+	auth := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(protographctx.WithPrincipal(r.Context(), "user-1")))
+	})
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"query Greet { hello }"}`))
+	req.Header.Set("X-Tenant", "acme")
+	w := httptest.NewRecorder()
+	auth.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	if gotID == 0 || gotHeader.Get("X-Tenant") != "acme" || gotPrincipal != "user-1" {
+		t.Fatalf("request id %d, header %v, principal %v", gotID, gotHeader, gotPrincipal)
+	}
+	if want := (protographctx.Operation{Name: "Greet", Type: "query", Query: "query Greet { hello }"}); gotOp != want {
+		t.Fatalf("operation %+v, want %+v", gotOp, want)
+	}
+}
+
+func TestTenantHeader(t *testing.T) {
+	rt := executor.NewMockRuntime(nil)
+	var gotTenant string
+	rt.SetResolver("Query", "hello", func(ctx context.Context, src any, args map[string]any) (any, error) {
+		gotTenant, _ = protographctx.Tenant(ctx)
+		return "world", nil
+	})
+	h := newTestHandler(t, rt, WithTenantHeader("X-Tenant"))
+	claims := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(protographctx.WithTenant(r.Context(), "from-claim")))
+	})
+
+	for _, tc := range []struct {
+		handler http.Handler
+		header  string
+		want    string
+	}{
+		{h, "acme", "acme"},
+		{h, "", ""},
+		{claims, "acme", "from-claim"},
+	} {
+		gotTenant = "unset"
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`))
+		if tc.header != "" {
+			req.Header.Set("X-Tenant", tc.header)
+		}
+		tc.handler.ServeHTTP(httptest.NewRecorder(), req)
+		if gotTenant != tc.want {
+			t.Errorf("header %q: tenant %q, want %q", tc.header, gotTenant, tc.want)
+		}
+	}
+}
+
+func TestResponseHeadersFromBackends(t *testing.T) {
+	rt := executor.NewMockRuntime(nil)
+	rt.SetResolver("Query", "hello", func(ctx context.Context, src any, args map[string]any) (any, error) {
+		// Stands in for the transport reporting a backend call's response metadata
+		if c, ok := respheader.FromContext(ctx); ok {
+			c.Add(metadata.Pairs("cache-control", "max-age=60", "x-backend-secret", "s3cr3t"))
+		}
+		return "world", nil
+	})
+	h := newTestHandler(t, rt, WithResponseHeaders("Cache-Control"))
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get("Cache-Control"); got != "max-age=60" {
+		t.Fatalf("Cache-Control %q", got)
+	}
+	if got := w.Header().Get("X-Backend-Secret"); got != "" {
+		t.Fatalf("unexpected X-Backend-Secret %q", got)
+	}
+}
+
+func TestLoadShedding(t *testing.T) {
+	started, unblock := make(chan struct{}), make(chan struct{})
+	rt := executor.NewMockRuntime(nil)
+	rt.SetResolver("Query", "hello", func(ctx context.Context, src any, args map[string]any) (any, error) {
+		started <- struct{}{}
+		<-unblock
+		return "world", nil
+	})
+	h := newTestHandler(t, rt, WithLoadShedding(LoadSheddingOptions{MaxInFlight: 1, RetryAfter: 1500 * time.Millisecond}))
+	query := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`)))
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- query() }()
+	<-started
+
+	// The only slot is taken and there is no queue
+	w := query()
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(w.Body.String(), string(errcode.Overloaded)) {
+		t.Fatalf("body %s", w.Body.String())
+	}
+
+	close(unblock)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("admitted request status %d", w.Code)
+	}
+	go func() { <-started }()
+	if w := query(); w.Code != http.StatusOK {
+		t.Fatalf("status %d after the slot was released", w.Code)
+	}
+}
+
+func TestGraphiQLConfig(t *testing.T) {
+	rt := executor.NewMockRuntime(nil)
+	h := newTestHandler(t, rt, WithGraphiQLConfig(GraphiQLOptions{
+		SubscriptionURL: "wss://example.com/graphql",
+		Headers:         map[string]string{"Authorization": "Bearer dev"},
+		DarkMode:        true,
+	}))
+
+	page := func(target string) string {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Fatalf("status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		return w.Body.String()
+	}
+
+	body := page("/api/graphql")
+	for _, want := range []string{
+		`"endpoint":"/api/graphql"`,
+		`"subscriptionUrl":"wss://example.com/graphql"`,
+		`"theme":"dark"`,
+		`Bearer dev`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("page missing %s", want)
+		}
+	}
+
+	// Query parameters override the server options; foreign endpoints are ignored
+	body = page(`/graphql?endpoint=/other&theme=light&headers={"X-Test":"abc"}`)
+	if !strings.Contains(body, `"endpoint":"/other"`) || !strings.Contains(body, `"theme":"light"`) || !strings.Contains(body, "X-Test") {
+		t.Fatalf("query parameters not applied")
+	}
+	body = page("/graphql?endpoint=//evil.example.com/graphql")
+	if !strings.Contains(body, `"endpoint":"/graphql"`) {
+		t.Fatalf("cross-origin endpoint should be ignored")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": executor.NewMockValueResolver("world"),
+	})
+	post := func(h *Handler, body string, header bool) string {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if header {
+			req.Header.Set(ExplainHeader, "1")
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	// Disabled by default: the query runs as usual
+	if got := post(newTestHandler(t, rt), `{"query":"{ hello }"}`, true); !strings.Contains(got, `"hello":"world"`) {
+		t.Fatalf("unexpected response %s", got)
+	}
+
+	h := newTestHandler(t, rt, WithExplain(true))
+	for _, tc := range []struct {
+		body   string
+		header bool
+	}{
+		{`{"query":"{ hello }"}`, true},
+		{`{"query":"{ hello }","extensions":{"explain":true}}`, false},
+	} {
+		got := post(h, tc.body, tc.header)
+		if !strings.Contains(got, `"data":null`) || !strings.Contains(got, `"groups":[{"objectType":"Query","field":"hello"`) {
+			t.Fatalf("unexpected explain response %s", got)
+		}
+	}
+}
+
+// mapResponseCache caches every operation by its query text.
+type mapResponseCache map[string]json.RawMessage
+
+func (c mapResponseCache) Key(_ *language.QueryDocument, query, _ string, _ map[string]any) (string, bool) {
+	return query, true
+}
+func (c mapResponseCache) Get(key string) (json.RawMessage, bool)   { v, ok := c[key]; return v, ok }
+func (c mapResponseCache) Set(key string, response json.RawMessage) { c[key] = response }
+
+func TestResponseCache(t *testing.T) {
+	calls := 0
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.hello": func(ctx context.Context, source any, args map[string]any) (any, error) {
+			calls++
+			return "world", nil
+		},
+	})
+	cache := mapResponseCache{}
+	h := newTestHandler(t, rt, WithResponseCache(cache))
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"{ hello }"}`)))
+		if got, want := strings.TrimSpace(w.Body.String()), `{"data":{"hello":"world"}}`; got != want {
+			t.Fatalf("request %d: got %s, want %s", i, got, want)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("resolver called %d times, want 1", calls)
+	}
+	if string(cache["{ hello }"]) != `{"data":{"hello":"world"}}` {
+		t.Fatalf("cached %s", cache["{ hello }"])
+	}
+}
+
+func TestJSONNumberVariables(t *testing.T) {
+	sch, err := schema.BuildFromSDL(`type Query { hello(id: ID!, n: Int!): String }`)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	rt := executor.NewMockRuntime(nil)
+	var got map[string]any
+	rt.SetResolver("Query", "hello", func(ctx context.Context, src any, args map[string]any) (any, error) {
+		got = args
+		return "world", nil
+	})
+	h, err := New(rt, sch, WithJSONNumber(true))
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	const query = `"query":"query($id: ID!, $n: Int!) { hello(id: $id, n: $n) }"`
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{`+query+`,"variables":{"id":9007199254740993,"n":7}}`)))
+	if w.Code != http.StatusOK || got["id"] != "9007199254740993" || got["n"] != 7 {
+		t.Fatalf("status %d, args %v: %s", w.Code, got, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewBufferString(`{`+query+`,"variables":{"id":1,"n":2}} {}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("trailing data: status %d, want 400", w.Code)
+	}
+}
+
+func TestResponseKeyOrder(t *testing.T) {
+	sch, err := schema.BuildFromSDL(`type Query { a: String b: String obj: Obj } type Obj { x: String y: String }`)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	rt := executor.NewMockRuntime(map[string]executor.MockResolver{
+		"Query.a":   executor.NewMockValueResolver("A"),
+		"Query.b":   executor.NewMockValueResolver("B"),
+		"Query.obj": executor.NewMockValueResolver(map[string]any{}),
+		"Obj.x":     executor.NewMockValueResolver("X"),
+		"Obj.y":     executor.NewMockValueResolver("Y"),
+	})
+	body := `{"query":"{ obj { y x } b a }"}`
+	want := `{"data":{"obj":{"y":"Y","x":"X"},"b":"B","a":"A"}}`
+	for name, opts := range map[string][]Option{
+		"streamed": nil,
+		"cached":   {WithResponseCache(mapResponseCache{})},
+		"pretty":   {WithPretty()},
+	} {
+		h, err := New(rt, sch, opts...)
+		if err != nil {
+			t.Fatalf("%s: handler: %v", name, err)
+		}
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+			var got bytes.Buffer
+			if err := json.Compact(&got, w.Body.Bytes()); err != nil {
+				t.Fatalf("%s: %v: %s", name, err, w.Body)
+			}
+			if got.String() != want {
+				t.Errorf("%s request %d: got %s, want %s", name, i, got.String(), want)
+			}
+		}
+	}
+}
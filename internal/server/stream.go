@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	executor "github.com/hanpama/protograph/internal/executor"
+)
+
+// streamBufferSize bounds the bytes held before they are written to the client.
+const streamBufferSize = 32 << 10
+
+// streamJSON writes v in the compact form json.Encoder.Encode produces, without
+// first building the whole document in memory. Response objects and lists are
+// walked node by node; any other value is encoded with encoding/json. When w is
+// an http.Flusher, the buffer is flushed after each top-level data field so the
+// client starts receiving large responses early.
+func streamJSON(w io.Writer, v any) error {
+	s := &jsonStream{w: bufio.NewWriterSize(w, streamBufferSize)}
+	s.flusher, _ = w.(http.Flusher)
+	s.response(v)
+	s.w.WriteByte('\n')
+	if s.err != nil {
+		return s.err
+	}
+	return s.w.Flush()
+}
+
+// marshalJSON is json.Marshal writing response data keys in query order.
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := streamJSON(&buf, v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+type jsonStream struct {
+	w       *bufio.Writer
+	flusher http.Flusher
+	err     error
+	// order is the key order of the data being written
+	order *executor.KeyOrder
+}
+
+// response writes the envelope types returned by executeOne and streams their data.
+func (s *jsonStream) response(v any) {
+	switch r := v.(type) {
+	case *executor.ExecutionResult:
+		s.order = r.KeyOrder
+		s.envelope(r.Data, r.Errors, len(r.Errors) > 0, r.Extensions)
+	case specResult:
+		s.order = r.keyOrder
+		s.envelope(r.Data, r.Errors, len(r.Errors) > 0, r.Extensions)
+	case []any:
+		// Batched requests
+		s.w.WriteByte('[')
+		for i, item := range r {
+			if i > 0 {
+				s.w.WriteByte(',')
+			}
+			s.response(item)
+		}
+		s.w.WriteByte(']')
+	default:
+		s.leaf(v)
+	}
+}
+
+func (s *jsonStream) envelope(data any, errors any, hasErrors bool, extensions map[string]any) {
+	s.w.WriteString(`{"data":`)
+	if obj, ok := data.(map[string]any); ok {
+		s.object(obj, true)
+	} else {
+		s.value(data)
+	}
+	if hasErrors {
+		s.w.WriteString(`,"errors":`)
+		s.leaf(errors)
+	}
+	if len(extensions) > 0 {
+		s.w.WriteString(`,"extensions":`)
+		s.leaf(extensions)
+	}
+	s.w.WriteByte('}')
+}
+
+func (s *jsonStream) value(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		s.object(v, false)
+	case []any:
+		if v == nil {
+			s.w.WriteString("null")
+			return
+		}
+		s.w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				s.w.WriteByte(',')
+			}
+			s.value(item)
+		}
+		s.w.WriteByte(']')
+	case json.RawMessage:
+		// Leaf objects completed by the runtime are already compact JSON
+		if s.err == nil {
+			s.w.Write(v)
+		}
+	default:
+		s.leaf(v)
+	}
+}
+
+// object writes m with its keys in query order, or sorted as encoding/json
+// does for objects the executor did not build.
+func (s *jsonStream) object(m map[string]any, top bool) {
+	if m == nil {
+		s.w.WriteString("null")
+		return
+	}
+	s.w.WriteByte('{')
+	first := true
+	for k, v := range s.order.Fields(m) {
+		if !first {
+			s.w.WriteByte(',')
+		}
+		first = false
+		s.leaf(k)
+		s.w.WriteByte(':')
+		s.value(v)
+		if top && s.flusher != nil && s.err == nil {
+			s.err = s.w.Flush()
+			s.flusher.Flush()
+		}
+	}
+	s.w.WriteByte('}')
+}
+
+func (s *jsonStream) leaf(v any) {
+	if s.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.w.Write(b)
+}
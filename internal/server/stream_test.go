@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	errcode "github.com/hanpama/protograph/internal/errcode"
+	executor "github.com/hanpama/protograph/internal/executor"
+	language "github.com/hanpama/protograph/internal/language"
+)
+
+func TestStreamJSONMatchesEncoder(t *testing.T) {
+	data := map[string]any{
+		"b":     []any{map[string]any{"z": 1, "a": "<html>&"}, nil, []any{1.5, true}},
+		"a":     nil,
+		"empty": map[string]any{},
+		"list":  []any{},
+		"typed": []string{"x", "y"},
+		"str":   "line\nbreak \u2028",
+	}
+	cases := map[string]any{
+		"result": &executor.ExecutionResult{Data: data, Errors: []executor.GraphQLError{}},
+		"errors": toSpecResult(&executor.ExecutionResult{Data: data, Errors: []executor.GraphQLError{
+			{Message: "boom", Path: executor.Path{"b", 0, "z"}},
+		}}),
+		"request error": errorResponse(nil, errcode.BadUserInput, &language.Error{Message: "missing 'query'"}),
+		"batch":         []any{&executor.ExecutionResult{Data: data}, errorResponse(nil, errcode.InternalServerError, &language.Error{Message: "x"})},
+		"explain":       explainResult{Extensions: map[string]any{"explain": map[string]any{"batches": []any{}}}},
+	}
+	for name, v := range cases {
+		var want bytes.Buffer
+		if err := json.NewEncoder(&want).Encode(v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got bytes.Buffer
+		if err := streamJSON(&got, v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%s:\n got %s\nwant %s", name, got.String(), want.String())
+		}
+	}
+}
+
+func TestStreamJSONFlushesTopLevelFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	res := &executor.ExecutionResult{Data: map[string]any{"a": 1, "b": 2}}
+	if err := streamJSON(w, res); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Flushed {
+		t.Fatal("response was not flushed")
+	}
+	if got := w.Body.String(); got != "{\"data\":{\"a\":1,\"b\":2}}\n" {
+		t.Fatalf("body = %q", got)
+	}
+}
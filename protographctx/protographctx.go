@@ -0,0 +1,99 @@
+// Package protographctx exposes the request the gateway is serving to custom
+// Runtime implementations and HTTP middleware, without importing the gateway's
+// internal packages.
+//
+// The GraphQL handler stores the HTTP request and the selected operation in the
+// context passed to the runtime. Authentication middleware wrapping the handler
+// attaches the principal with WithPrincipal:
+//
+//	next.ServeHTTP(w, r.WithContext(protographctx.WithPrincipal(r.Context(), user)))
+//
+// Middleware deriving the tenant from a token claim attaches it with WithTenant;
+// the gateway routes the backend calls of the request to that tenant's endpoints.
+package protographctx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hanpama/protograph/internal/reqid"
+)
+
+// Operation describes the GraphQL operation being executed.
+type Operation struct {
+	// Name is the operation name, empty for anonymous operations.
+	Name string
+	// Type is "query", "mutation" or "subscription".
+	Type string
+	// Query is the request document.
+	Query string
+}
+
+type (
+	requestKey   struct{}
+	principalKey struct{}
+	tenantKey    struct{}
+	operationKey struct{}
+)
+
+// RequestID returns the ID the gateway assigned to the HTTP request, also sent
+// to backends in the graphql-request-id metadata.
+func RequestID(ctx context.Context) (int64, bool) {
+	return reqid.FromContext(ctx)
+}
+
+// WithHTTPRequest returns a context carrying the HTTP request being served.
+func WithHTTPRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// HTTPRequest returns the HTTP request being served. Its body has already been
+// consumed; it must not be modified.
+func HTTPRequest(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestKey{}).(*http.Request)
+	return r, ok
+}
+
+// Header returns the incoming HTTP headers. It must not be modified.
+func Header(ctx context.Context) (http.Header, bool) {
+	r, ok := HTTPRequest(ctx)
+	if !ok {
+		return nil, false
+	}
+	return r.Header, true
+}
+
+// WithPrincipal returns a context carrying the authenticated principal, e.g. a
+// user or service account resolved by authentication middleware.
+func WithPrincipal(ctx context.Context, principal any) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the principal attached with WithPrincipal.
+func Principal(ctx context.Context) (any, bool) {
+	p := ctx.Value(principalKey{})
+	return p, p != nil
+}
+
+// WithTenant returns a context carrying the tenant the request is served for.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant attached with WithTenant, or by the GraphQL handler
+// from its tenant header.
+func Tenant(ctx context.Context) (string, bool) {
+	t, ok := ctx.Value(tenantKey{}).(string)
+	return t, ok && t != ""
+}
+
+// WithOperation returns a context carrying the operation being executed.
+func WithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationFromContext returns the operation being executed.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}